@@ -0,0 +1,98 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+)
+
+// instanceAlias returns the alias of the @index-th instance, suffixing @alias, or the hostname if it's empty, so
+// that the instances are distinct on the host and across the hosts.
+func instanceAlias(serverType string, alias string, index int) string {
+	if alias == "" {
+		hostname, err := os.Hostname()
+		if err != nil || hostname == "" {
+			hostname = serverType
+		}
+		alias = hostname
+	}
+	return fmt.Sprintf("%s-%d", alias, index)
+}
+
+// runInstances runs @count instances of the server as the child processes of the derived aliases. Each instance
+// binds an ephemeral port and keeps its scratch files under the subdirectory of its alias, and registers itself
+// independently. The signals are forwarded to the instances, it returns once all of them exit. The instances are
+// never run in this process, since the params of IndexNode are process-global, see roles.MilvusRoles.
+func runInstances(serverType string, alias string, count int) error {
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc,
+		syscall.SIGHUP,
+		syscall.SIGINT,
+		syscall.SIGTERM,
+		syscall.SIGQUIT)
+	defer signal.Stop(sc)
+
+	cmds := make([]*exec.Cmd, 0, count)
+	var wg sync.WaitGroup
+	for i := 1; i <= count; i++ {
+		instance := instanceAlias(serverType, alias, i)
+		cmd := exec.Command(os.Args[0], "run", serverType, "--alias="+instance)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(), "INDEX_NODE_PORT=0")
+		if err := cmd.Start(); err != nil {
+			signalInstances(cmds, syscall.SIGTERM)
+			wg.Wait()
+			return fmt.Errorf("start instance %s failed, error = %w", instance, err)
+		}
+		log.Info("start instance", zap.String("serverType", serverType), zap.String("alias", instance),
+			zap.Int("pid", cmd.Process.Pid))
+		cmds = append(cmds, cmd)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := cmd.Wait(); err != nil {
+				log.Warn("instance exited", zap.String("serverType", serverType), zap.String("alias", instance),
+					zap.Error(err))
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for {
+		select {
+		case sig := <-sc:
+			signalInstances(cmds, sig)
+		case <-done:
+			return nil
+		}
+	}
+}
+
+func signalInstances(cmds []*exec.Cmd, sig os.Signal) {
+	for _, cmd := range cmds {
+		_ = cmd.Process.Signal(sig)
+	}
+}
@@ -191,6 +191,9 @@ func main() {
 	var svrAlias string
 	flags.StringVar(&svrAlias, "alias", "", "set alias")
 
+	var instances int
+	flags.IntVar(&instances, "instances", 1, "set the number of the index node instances to run on this host")
+
 	var enableRootCoord, enableQueryCoord, enableIndexCoord, enableDataCoord bool
 	flags.BoolVar(&enableRootCoord, roleRootCoord, false, "enable root coordinator")
 	flags.BoolVar(&enableQueryCoord, roleQueryCoord, false, "enable query coordinator")
@@ -206,7 +209,7 @@ func main() {
 			})
 		default:
 			flags.VisitAll(func(f *flag.Flag) {
-				if f.Name != "alias" {
+				if f.Name != "alias" && (f.Name != "instances" || serverType != roleIndexNode) {
 					return
 				}
 				printUsage(flags.Output(), f)
@@ -217,6 +220,10 @@ func main() {
 	if err := flags.Parse(os.Args[3:]); err != nil {
 		os.Exit(-1)
 	}
+	if instances < 1 || (instances > 1 && serverType != roleIndexNode) {
+		fmt.Fprintf(os.Stderr, "Invalid instances = %d for server type = %s\n", instances, serverType)
+		os.Exit(-1)
+	}
 
 	var localMsg = false
 	role := roles.MilvusRoles{}
@@ -278,6 +285,13 @@ func main() {
 			panic(err)
 		}
 		defer removePidFile(fd)
+		if instances > 1 {
+			// stopping by the pid file of this process stops all the instances
+			if err := runInstances(serverType, svrAlias, instances); err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+			}
+			return
+		}
 		role.Run(localMsg, svrAlias)
 	case "stop":
 		if err := stopPid(filename, runtimeDir); err != nil {
@@ -13,6 +13,7 @@ package roles
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -20,6 +21,7 @@ import (
 	"path"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 
 	"github.com/milvus-io/milvus/internal/util/healthz"
@@ -277,7 +279,23 @@ func (mr *MilvusRoles) runIndexCoord(ctx context.Context, localMsg bool) *compon
 	return is
 }
 
+// indexNodeClaimed is set once an IndexNode runs in the process.
+var indexNodeClaimed int32
+
+// claimIndexNode rejects running another IndexNode in the process, since the params of IndexNode are process-global
+// and the instances would share the ports, the scratch paths and the node ID. The instances of a host are run as
+// the child processes by --instances instead.
+func claimIndexNode() error {
+	if !atomic.CompareAndSwapInt32(&indexNodeClaimed, 0, 1) {
+		return errors.New("only one IndexNode runs in a process, run the instances of a host by --instances")
+	}
+	return nil
+}
+
 func (mr *MilvusRoles) runIndexNode(ctx context.Context, localMsg bool, alias string) *components.IndexNode {
+	if err := claimIndexNode(); err != nil {
+		panic(err)
+	}
 	var in *components.IndexNode
 	var wg sync.WaitGroup
 
@@ -37,3 +37,11 @@ func TestRoles(t *testing.T) {
 	ss = strings.SplitN("adb=def", "=", 2)
 	assert.Equal(t, len(ss), 2)
 }
+
+func TestClaimIndexNode(t *testing.T) {
+	assert.Nil(t, claimIndexNode())
+	// the second IndexNode in the process is rejected
+	err := claimIndexNode()
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "--instances"))
+}
@@ -160,6 +160,9 @@ BinaryIVF::Train(const DatasetPtr& dataset_ptr, const Config& config) {
     faiss::IndexBinary* coarse_quantizer = new faiss::IndexBinaryFlat(dim, metric_type);
     auto index = std::make_shared<faiss::IndexBinaryIVF>(coarse_quantizer, dim, nlist, metric_type);
     index->own_fields = true;
+    if (config.contains(IndexParams::build_seed)) {
+        index->cp.seed = config[IndexParams::build_seed].get<int>();
+    }
     index->train(rows, static_cast<const uint8_t*>(p_data));
     index_ = index;
 }
@@ -103,8 +103,14 @@ IndexHNSW::Train(const DatasetPtr& dataset_ptr, const Config& config) {
         } else {
             KNOWHERE_THROW_MSG("Metric type not supported: " + metric_type);
         }
+        size_t random_seed = 100;
+        if (config.contains(IndexParams::build_seed)) {
+            random_seed = config[IndexParams::build_seed].get<int64_t>();
+            seeded_ = true;
+        }
         index_ = std::make_shared<hnswlib::HierarchicalNSW<float>>(space, rows, config[IndexParams::M].get<int64_t>(),
-                                                                   config[IndexParams::efConstruction].get<int64_t>());
+                                                                   config[IndexParams::efConstruction].get<int64_t>(),
+                                                                   random_seed);
         index_->stats_enable = (STATISTICS_LEVEL >= 3);
     } catch (std::exception& e) {
         KNOWHERE_THROW_MSG(e.what());
@@ -120,10 +126,18 @@ IndexHNSW::AddWithoutIds(const DatasetPtr& dataset_ptr, const Config& config) {
     GET_TENSOR_DATA(dataset_ptr)
 
     index_->addPoint(p_data, 0);
+    if (seeded_) {
+        // the levels are drawn in the order the points are added, and the links depend on it as well
+        for (int i = 1; i < rows; ++i) {
+            faiss::BuilderSuspend::check_wait();
+            index_->addPoint((reinterpret_cast<const float*>(p_data) + Dim() * i), i);
+        }
+    } else {
 #pragma omp parallel for
-    for (int i = 1; i < rows; ++i) {
-        faiss::BuilderSuspend::check_wait();
-        index_->addPoint((reinterpret_cast<const float*>(p_data) + Dim() * i), i);
+        for (int i = 1; i < rows; ++i) {
+            faiss::BuilderSuspend::check_wait();
+            index_->addPoint((reinterpret_cast<const float*>(p_data) + Dim() * i), i);
+        }
     }
     if (STATISTICS_LEVEL >= 3) {
         auto hnsw_stats = std::static_pointer_cast<LibHNSWStatistics>(stats);
@@ -134,6 +148,37 @@ IndexHNSW::AddWithoutIds(const DatasetPtr& dataset_ptr, const Config& config) {
     //     LOG_KNOWHERE_DEBUG_ << GetStatistics()->ToString();
 }
 
+void
+IndexHNSW::Append(const DatasetPtr& dataset_ptr, const Config& config) {
+    if (!index_) {
+        KNOWHERE_THROW_MSG("index not initialize or trained");
+    }
+
+    GET_TENSOR_DATA(dataset_ptr)
+
+    size_t base = index_->cur_element_count;
+    try {
+        index_->resizeIndex(base + rows);
+    } catch (std::exception& e) {
+        KNOWHERE_THROW_MSG(e.what());
+    }
+    auto data = reinterpret_cast<const float*>(p_data);
+    if (config.contains(IndexParams::build_seed)) {
+        // the loaded index has no seed, the levels of the appended points are drawn by the seed in order
+        index_->level_generator_.seed(config[IndexParams::build_seed].get<int64_t>());
+        for (int64_t i = 0; i < rows; ++i) {
+            faiss::BuilderSuspend::check_wait();
+            index_->addPoint(data + Dim() * i, base + i);
+        }
+    } else {
+#pragma omp parallel for
+        for (int64_t i = 0; i < rows; ++i) {
+            faiss::BuilderSuspend::check_wait();
+            index_->addPoint(data + Dim() * i, base + i);
+        }
+    }
+}
+
 DatasetPtr
 IndexHNSW::Query(const DatasetPtr& dataset_ptr, const Config& config, const faiss::BitsetView bitset) {
     if (!index_) {
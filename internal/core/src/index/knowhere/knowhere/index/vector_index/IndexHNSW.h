@@ -40,6 +40,10 @@ class IndexHNSW : public VecIndex {
     void
     AddWithoutIds(const DatasetPtr&, const Config&) override;
 
+    // Append adds the vectors to the built or loaded index, the labels of which follow the ones in it
+    void
+    Append(const DatasetPtr& dataset_ptr, const Config& config);
+
     DatasetPtr
     Query(const DatasetPtr& dataset_ptr, const Config& config, const faiss::BitsetView bitset) override;
 
@@ -57,6 +61,8 @@ class IndexHNSW : public VecIndex {
 
  private:
     std::shared_ptr<hnswlib::HierarchicalNSW<float>> index_;
+    // seeded_ adds the points in order, which makes the build reproducible by the seed
+    bool seeded_ = false;
 };
 
 }  // namespace knowhere
@@ -79,6 +79,9 @@ IVF::Train(const DatasetPtr& dataset_ptr, const Config& config) {
     faiss::Index* coarse_quantizer = new faiss::IndexFlat(dim, metric_type);
     auto index = std::make_shared<faiss::IndexIVFFlat>(coarse_quantizer, dim, nlist, metric_type);
     index->own_fields = true;
+    if (config.contains(IndexParams::build_seed)) {
+        index->cp.seed = config[IndexParams::build_seed].get<int>();
+    }
     index->train(rows, reinterpret_cast<const float*>(p_data));
     index_ = index;
 }
@@ -42,6 +42,11 @@ IVFPQ::Train(const DatasetPtr& dataset_ptr, const Config& config) {
                                                      config[IndexParams::m].get<int64_t>(),
                                                      config[IndexParams::nbits].get<int64_t>(), metric_type);
     index->own_fields = true;
+    if (config.contains(IndexParams::build_seed)) {
+        // the coarse centroids and the pq codebooks are trained by k-means each
+        index->cp.seed = config[IndexParams::build_seed].get<int>();
+        index->pq.cp.seed = index->cp.seed;
+    }
     index->train(rows, reinterpret_cast<const float*>(p_data));
     index_ = index;
 }
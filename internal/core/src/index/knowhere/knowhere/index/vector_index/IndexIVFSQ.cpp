@@ -41,6 +41,9 @@ IVFSQ::Train(const DatasetPtr& dataset_ptr, const Config& config) {
     auto index = std::make_shared<faiss::IndexIVFScalarQuantizer>(
         coarse_quantizer, dim, config[IndexParams::nlist].get<int64_t>(), faiss::QuantizerType::QT_8bit, metric_type);
     index->own_fields = true;
+    if (config.contains(IndexParams::build_seed)) {
+        index->cp.seed = config[IndexParams::build_seed].get<int>();
+    }
     index->train(rows, reinterpret_cast<const float*>(p_data));
     index_ = index;
 }
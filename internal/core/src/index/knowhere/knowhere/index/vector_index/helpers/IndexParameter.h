@@ -28,6 +28,9 @@ constexpr const char* DEVICEID = "gpu_id";
 };  // namespace meta
 
 namespace IndexParams {
+// Build Params
+constexpr const char* build_seed = "build_seed";  // seed of the randomized initialization, reproducible if set
+
 // Range Search Params
 constexpr const char* range_search_radius = "range_search_radius";
 constexpr const char* range_search_buffer_size = "range_search_buffer_size";
@@ -10,11 +10,15 @@
 // or implied. See the License for the specific language governing permissions and limitations under the License
 
 #include <map>
+#include <chrono>
+#include <ctime>
 #include <exception>
+#include <omp.h>
 #include <google/protobuf/text_format.h>
 
 #include "pb/index_cgo_msg.pb.h"
 #include "knowhere/index/vector_index/VecIndexFactory.h"
+#include "knowhere/index/vector_index/IndexHNSW.h"
 #include "knowhere/index/vector_index/helpers/IndexParameter.h"
 #include "exceptions/EasyAssert.h"
 #include "IndexWrapper.h"
@@ -68,6 +72,9 @@ IndexWrapper::parse_impl(const std::string& serialized_params_str, knowhere::Con
     check_parameter<int>(conf, milvus::knowhere::meta::DIM, stoi_closure, std::nullopt);
     check_parameter<int>(conf, milvus::knowhere::meta::TOPK, stoi_closure, std::nullopt);
 
+    /***************************** Build Params *******************************/
+    check_parameter<int>(conf, milvus::knowhere::IndexParams::build_seed, stoi_closure, std::nullopt);
+
     /***************************** IVF Params *******************************/
     check_parameter<int>(conf, milvus::knowhere::IndexParams::nprobe, stoi_closure, std::nullopt);
     check_parameter<int>(conf, milvus::knowhere::IndexParams::nlist, stoi_closure, std::nullopt);
@@ -147,6 +154,25 @@ IndexWrapper::get_config_by_name(std::string name) {
     return std::nullopt;
 }
 
+namespace {
+class PhaseTimer {
+ public:
+    PhaseTimer() : start_(std::chrono::steady_clock::now()), cpu_start_(std::clock()) {
+    }
+
+    // Stop adds the wall time and the cpu time since the timer started
+    void
+    Stop(double& wall_ms, double& cpu_ms) {
+        wall_ms += std::chrono::duration<double, std::milli>(std::chrono::steady_clock::now() - start_).count();
+        cpu_ms += 1000.0 * (std::clock() - cpu_start_) / CLOCKS_PER_SEC;
+    }
+
+ private:
+    std::chrono::steady_clock::time_point start_;
+    std::clock_t cpu_start_;
+};
+}  // namespace
+
 int64_t
 IndexWrapper::dim() {
     auto dimension = get_config_by_name<int64_t>(milvus::knowhere::meta::DIM);
@@ -178,7 +204,9 @@ IndexWrapper::BuildWithoutIds(const knowhere::DatasetPtr& dataset) {
     //     index_->Train(dataset, config_);
     //     index_->AddWithoutIds(dataset, config_);
     // }
+    PhaseTimer timer;
     index_->BuildAll(dataset, config_);
+    timer.Stop(build_ms_, cpu_ms_);
     rc.RecordSection("TrainAndAdd");
 
     if (is_in_nm_list(index_type)) {
@@ -188,6 +216,66 @@ IndexWrapper::BuildWithoutIds(const knowhere::DatasetPtr& dataset) {
     rc.ElapseFromBegin("Done");
 }
 
+void
+IndexWrapper::AddWithoutIds(const knowhere::DatasetPtr& dataset) {
+    auto index_type = get_index_type();
+    if (!is_in_incremental_add_list(index_type)) {
+        PanicInfo(std::string(index_type) + " doesn't support incremental add yet!");
+    }
+    if (!trained_) {
+        auto index_mode = get_index_mode();
+        config_[knowhere::meta::ROWS] = dataset->Get<int64_t>(knowhere::meta::ROWS);
+        auto conf_adapter = knowhere::AdapterMgr::GetInstance().GetAdapter(index_type);
+        AssertInfo(conf_adapter->CheckTrain(config_, index_mode), "something wrong in index parameters!");
+        PhaseTimer timer;
+        index_->Train(dataset, config_);
+        timer.Stop(train_ms_, cpu_ms_);
+        trained_ = true;
+    }
+    PhaseTimer timer;
+    index_->AddWithoutIds(dataset, config_);
+    timer.Stop(add_ms_, cpu_ms_);
+}
+
+void
+IndexWrapper::Append(const knowhere::DatasetPtr& dataset) {
+    auto index_type = get_index_type();
+    if (!is_in_append_list(index_type)) {
+        PanicInfo(std::string(index_type) + " doesn't support append yet!");
+    }
+    auto hnsw = std::dynamic_pointer_cast<knowhere::IndexHNSW>(index_);
+    AssertInfo(hnsw != nullptr, "the index to append to is not HNSW");
+    PhaseTimer timer;
+    hnsw->Append(dataset, config_);
+    timer.Stop(add_ms_, cpu_ms_);
+    trained_ = true;
+}
+
+std::string
+IndexWrapper::Statistics() {
+    milvus::json stats;
+    stats["index_type"] = get_index_type();
+    stats["threads"] = omp_get_max_threads();
+    if (trained_) {
+        stats["train_ms"] = train_ms_;
+        stats["add_ms"] = add_ms_;
+    } else {
+        stats["build_ms"] = build_ms_;
+    }
+    stats["cpu_ms"] = cpu_ms_;
+    // not every index supports the counters, the unsupported ones are absent
+    try {
+        stats["rows"] = index_->Count();
+        stats["dim"] = index_->Dim();
+    } catch (std::exception&) {
+    }
+    try {
+        stats["index_size"] = index_->Size();
+    } catch (std::exception&) {
+    }
+    return stats.dump();
+}
+
 void
 IndexWrapper::BuildWithIds(const knowhere::DatasetPtr& dataset) {
     AssertInfo(dataset->data().find(milvus::knowhere::meta::IDS) != dataset->data().end(),
@@ -235,22 +323,12 @@ IndexWrapper::StoreRawData(const knowhere::DatasetPtr& dataset) {
  */
 std::unique_ptr<IndexWrapper::Binary>
 IndexWrapper::Serialize() {
-    auto binarySet = index_->Serialize(config_);
-    auto index_type = get_index_type();
-    if (is_in_nm_list(index_type)) {
-        std::shared_ptr<uint8_t[]> raw_data(new uint8_t[raw_data_.size()], std::default_delete<uint8_t[]>());
-        memcpy(raw_data.get(), raw_data_.data(), raw_data_.size());
-        binarySet.Append(RAW_DATA, raw_data, raw_data_.size());
-        auto slice_size = get_index_file_slice_size();
-        // https://github.com/milvus-io/milvus/issues/6421
-        // Disassemble will only divide the raw vectors, other keys was already divided
-        knowhere::Disassemble(slice_size * 1024 * 1024, binarySet);
-    }
+    auto binaries = SerializeToBinaries();
 
     namespace indexcgo = milvus::proto::indexcgo;
     indexcgo::BinarySet ret;
 
-    for (auto [key, value] : binarySet.binary_map_) {
+    for (auto& [key, value] : *binaries) {
         auto binary = ret.add_datas();
         binary->set_key(key);
         binary->set_value(value->data.get(), value->size);
@@ -267,6 +345,23 @@ IndexWrapper::Serialize() {
     return binary;
 }
 
+std::unique_ptr<IndexWrapper::Binaries>
+IndexWrapper::SerializeToBinaries() {
+    auto binarySet = index_->Serialize(config_);
+    auto index_type = get_index_type();
+    if (is_in_nm_list(index_type)) {
+        std::shared_ptr<uint8_t[]> raw_data(new uint8_t[raw_data_.size()], std::default_delete<uint8_t[]>());
+        memcpy(raw_data.get(), raw_data_.data(), raw_data_.size());
+        binarySet.Append(RAW_DATA, raw_data, raw_data_.size());
+        auto slice_size = get_index_file_slice_size();
+        // https://github.com/milvus-io/milvus/issues/6421
+        // Disassemble will only divide the raw vectors, other keys was already divided
+        knowhere::Disassemble(slice_size * 1024 * 1024, binarySet);
+    }
+
+    return std::make_unique<Binaries>(binarySet.binary_map_.begin(), binarySet.binary_map_.end());
+}
+
 void
 IndexWrapper::Load(const char* serialized_sliced_blob_buffer, int32_t size) {
     namespace indexcgo = milvus::proto::indexcgo;
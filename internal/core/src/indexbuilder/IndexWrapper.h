@@ -29,6 +29,20 @@ class IndexWrapper {
     void
     BuildWithoutIds(const knowhere::DatasetPtr& dataset);
 
+    // AddWithoutIds feeds a chunk of the data to the index, the index is trained by the first chunk,
+    // only the index types in Incremental_Add_List are supported
+    void
+    AddWithoutIds(const knowhere::DatasetPtr& dataset);
+
+    // Append adds the data to the built or loaded index, whose ids follow the data already in it,
+    // only the index types in Append_List are supported
+    void
+    Append(const knowhere::DatasetPtr& dataset);
+
+    // Statistics returns the statistics of the build in json, the fields the index does not expose are absent
+    std::string
+    Statistics();
+
     struct Binary {
         std::vector<char> data;
     };
@@ -36,6 +50,13 @@ class IndexWrapper {
     std::unique_ptr<Binary>
     Serialize();
 
+    // Binaries are the index files of the binary set, which refer to the memory of the index engine
+    using Binaries = std::vector<std::pair<std::string, knowhere::BinaryPtr>>;
+
+    // SerializeToBinaries returns the index files without copying them into a serialized buffer as Serialize does
+    std::unique_ptr<Binaries>
+    SerializeToBinaries();
+
     void
     Load(const char* serialized_sliced_blob_buffer, int32_t size);
 
@@ -105,6 +126,13 @@ class IndexWrapper {
     knowhere::Config config_;
     std::vector<uint8_t> raw_data_;
     std::once_flag raw_data_loaded_;
+    bool trained_ = false;
+    // the wall time and the cpu time of the phases of the build in milliseconds, the cpu time is of the process,
+    // which includes the other builds running along with this one
+    double train_ms_ = 0;
+    double add_ms_ = 0;
+    double build_ms_ = 0;
+    double cpu_ms_ = 0;
 };
 
 }  // namespace indexbuilder
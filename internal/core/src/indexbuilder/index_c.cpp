@@ -84,6 +84,75 @@ BuildBinaryVecIndexWithoutIds(CIndex index, int64_t data_size, const uint8_t* ve
     return status;
 }
 
+CStatus
+AddFloatVecIndexWithoutIds(CIndex index, int64_t float_value_num, const float* vectors) {
+    auto status = CStatus();
+    try {
+        auto cIndex = (milvus::indexbuilder::IndexWrapper*)index;
+        auto dim = cIndex->dim();
+        auto row_nums = float_value_num / dim;
+        auto ds = milvus::knowhere::GenDataset(row_nums, dim, vectors);
+        cIndex->AddWithoutIds(ds);
+        status.error_code = Success;
+        status.error_msg = "";
+    } catch (std::exception& e) {
+        status.error_code = UnexpectedError;
+        status.error_msg = strdup(e.what());
+    }
+    return status;
+}
+
+CStatus
+AppendFloatVecIndex(CIndex index, int64_t float_value_num, const float* vectors) {
+    auto status = CStatus();
+    try {
+        auto cIndex = (milvus::indexbuilder::IndexWrapper*)index;
+        auto dim = cIndex->dim();
+        auto row_nums = float_value_num / dim;
+        auto ds = milvus::knowhere::GenDataset(row_nums, dim, vectors);
+        cIndex->Append(ds);
+        status.error_code = Success;
+        status.error_msg = "";
+    } catch (std::exception& e) {
+        status.error_code = UnexpectedError;
+        status.error_msg = strdup(e.what());
+    }
+    return status;
+}
+
+CStatus
+AddBinaryVecIndexWithoutIds(CIndex index, int64_t data_size, const uint8_t* vectors) {
+    auto status = CStatus();
+    try {
+        auto cIndex = (milvus::indexbuilder::IndexWrapper*)index;
+        auto dim = cIndex->dim();
+        auto row_nums = (data_size * 8) / dim;
+        auto ds = milvus::knowhere::GenDataset(row_nums, dim, vectors);
+        cIndex->AddWithoutIds(ds);
+        status.error_code = Success;
+        status.error_msg = "";
+    } catch (std::exception& e) {
+        status.error_code = UnexpectedError;
+        status.error_msg = strdup(e.what());
+    }
+    return status;
+}
+
+CStatus
+GetIndexStatistics(CIndex index, char** stats) {
+    auto status = CStatus();
+    try {
+        auto cIndex = (milvus::indexbuilder::IndexWrapper*)index;
+        *stats = strdup(cIndex->Statistics().c_str());
+        status.error_code = Success;
+        status.error_msg = "";
+    } catch (std::exception& e) {
+        status.error_code = UnexpectedError;
+        status.error_msg = strdup(e.what());
+    }
+    return status;
+}
+
 CStatus
 SerializeToSlicedBuffer(CIndex index, CBinary* c_binary) {
     auto status = CStatus();
@@ -119,6 +188,43 @@ DeleteCBinary(CBinary c_binary) {
     delete cBinary;
 }
 
+CStatus
+SerializeToBinarySet(CIndex index, CBinarySet* c_binary_set) {
+    auto status = CStatus();
+    try {
+        auto cIndex = (milvus::indexbuilder::IndexWrapper*)index;
+        auto binaries = cIndex->SerializeToBinaries();
+        *c_binary_set = binaries.release();
+        status.error_code = Success;
+        status.error_msg = "";
+    } catch (std::exception& e) {
+        status.error_code = UnexpectedError;
+        status.error_msg = strdup(e.what());
+    }
+    return status;
+}
+
+int64_t
+GetBinarySetFileNum(CBinarySet c_binary_set) {
+    auto binaries = (milvus::indexbuilder::IndexWrapper::Binaries*)c_binary_set;
+    return binaries->size();
+}
+
+void
+GetBinarySetFile(CBinarySet c_binary_set, int64_t idx, const char** key, const uint8_t** data, int64_t* size) {
+    auto binaries = (milvus::indexbuilder::IndexWrapper::Binaries*)c_binary_set;
+    auto& [name, binary] = binaries->at(idx);
+    *key = name.c_str();
+    *data = binary->data.get();
+    *size = binary->size;
+}
+
+void
+DeleteBinarySet(CBinarySet c_binary_set) {
+    auto binaries = (milvus::indexbuilder::IndexWrapper::Binaries*)c_binary_set;
+    delete binaries;
+}
+
 CStatus
 LoadFromSlicedBuffer(CIndex index, const char* serialized_sliced_blob_buffer, int32_t size) {
     auto status = CStatus();
@@ -33,6 +33,7 @@ extern "C" {
 typedef void* CIndex;
 typedef void* CIndexQueryResult;
 typedef void* CBinary;
+typedef void* CBinarySet;
 
 // TODO: how could we pass map between go and c++ more efficiently?
 // Solution: using protobuf instead of json, this way significantly increase programming efficiency
@@ -49,6 +50,23 @@ BuildFloatVecIndexWithoutIds(CIndex index, int64_t float_value_num, const float*
 CStatus
 BuildBinaryVecIndexWithoutIds(CIndex index, int64_t data_size, const uint8_t* vectors);
 
+// Add*VecIndexWithoutIds feeds a chunk of vectors to the index which supports incremental add,
+// the vectors of all the chunks are indexed in the order they are added
+CStatus
+AddFloatVecIndexWithoutIds(CIndex index, int64_t float_value_num, const float* vectors);
+
+CStatus
+AddBinaryVecIndexWithoutIds(CIndex index, int64_t data_size, const uint8_t* vectors);
+
+// AppendFloatVecIndex appends the vectors to the built or loaded index which supports append, e.g. HNSW,
+// the ids of the vectors follow the ones already in the index
+CStatus
+AppendFloatVecIndex(CIndex index, int64_t float_value_num, const float* vectors);
+
+// the statistics are in json, which must be freed by free
+CStatus
+GetIndexStatistics(CIndex index, char** stats);
+
 CStatus
 SerializeToSlicedBuffer(CIndex index, CBinary* c_binary);
 
@@ -62,6 +80,21 @@ GetCBinaryData(CBinary c_binary, void* data);
 void
 DeleteCBinary(CBinary c_binary);
 
+// SerializeToBinarySet serializes the index into the index files, which are read in place by GetBinarySetFile
+// instead of being copied into a sliced buffer
+CStatus
+SerializeToBinarySet(CIndex index, CBinarySet* c_binary_set);
+
+int64_t
+GetBinarySetFileNum(CBinarySet c_binary_set);
+
+// the key and the data refer to the binary set, which are valid until the binary set is deleted
+void
+GetBinarySetFile(CBinarySet c_binary_set, int64_t idx, const char** key, const uint8_t** data, int64_t* size);
+
+void
+DeleteBinarySet(CBinarySet c_binary_set);
+
 CStatus
 LoadFromSlicedBuffer(CIndex index, const char* serialized_sliced_blob_buffer, int32_t size);
 
@@ -58,6 +58,25 @@ Need_BuildAll_list() {
     return ret;
 }
 
+// the index types which can be trained without data and fed with the data chunk by chunk
+std::vector<std::string>
+Incremental_Add_List() {
+    static std::vector<std::string> ret{
+        milvus::knowhere::IndexEnum::INDEX_FAISS_IDMAP,
+        milvus::knowhere::IndexEnum::INDEX_FAISS_BIN_IDMAP,
+    };
+    return ret;
+}
+
+// the index types which can be loaded and appended with more data, the data appended follows the data in the index
+std::vector<std::string>
+Append_List() {
+    static std::vector<std::string> ret{
+        milvus::knowhere::IndexEnum::INDEX_HNSW,
+    };
+    return ret;
+}
+
 std::vector<std::tuple<std::string, std::string>>
 unsupported_index_combinations() {
     static std::vector<std::tuple<std::string, std::string>> ret{
@@ -88,6 +107,16 @@ is_in_need_build_all_list(const milvus::knowhere::IndexType& index_type) {
     return is_in_list<std::string>(index_type, Need_BuildAll_list);
 }
 
+bool
+is_in_incremental_add_list(const milvus::knowhere::IndexType& index_type) {
+    return is_in_list<std::string>(index_type, Incremental_Add_List);
+}
+
+bool
+is_in_append_list(const milvus::knowhere::IndexType& index_type) {
+    return is_in_list<std::string>(index_type, Append_List);
+}
+
 bool
 is_in_need_id_list(const milvus::knowhere::IndexType& index_type) {
     return is_in_list<std::string>(index_type, Need_ID_List);
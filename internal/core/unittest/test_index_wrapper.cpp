@@ -11,6 +11,7 @@
 
 #include <tuple>
 #include <map>
+#include <set>
 #include <gtest/gtest.h>
 #include <google/protobuf/text_format.h>
 
@@ -412,6 +413,28 @@ TEST_P(IndexWrapperTest, Codec) {
     }
 }
 
+TEST_P(IndexWrapperTest, SerializeToBinaries) {
+    auto index =
+        std::make_unique<milvus::indexbuilder::IndexWrapper>(type_params_str.c_str(), index_params_str.c_str());
+
+    ASSERT_NO_THROW(index->BuildWithoutIds(xb_dataset));
+
+    // the binaries are the index files of the serialized buffer
+    auto binary = index->Serialize();
+    indexcgo::BinarySet binary_set;
+    ASSERT_TRUE(binary_set.ParseFromArray(binary->data.data(), binary->data.size()));
+    auto binaries = index->SerializeToBinaries();
+    ASSERT_EQ(binaries->size(), binary_set.datas_size());
+    for (auto i = 0; i < binary_set.datas_size(); i++) {
+        auto& [key, value] = binaries->at(i);
+        ASSERT_EQ(key, binary_set.datas(i).key());
+        ASSERT_EQ(value->size, binary_set.datas(i).value().size());
+        if (!milvus::indexbuilder::is_in_nm_list(index_type)) {
+            ASSERT_EQ(0, memcmp(value->data.get(), binary_set.datas(i).value().data(), value->size));
+        }
+    }
+}
+
 TEST_P(IndexWrapperTest, Query) {
     auto index_wrapper =
         std::make_unique<milvus::indexbuilder::IndexWrapper>(type_params_str.c_str(), index_params_str.c_str());
@@ -424,3 +447,89 @@ TEST_P(IndexWrapperTest, Query) {
     ASSERT_EQ(query_result->distances.size(), query_result->topk * query_result->nq);
     ASSERT_EQ(query_result->ids.size(), query_result->topk * query_result->nq);
 }
+
+namespace {
+// recall returns the ratio of the ground truth found in the top K results of the queries on the base vectors,
+// the ground truth is searched by brute force in L2
+double
+recall(const milvus::indexbuilder::IndexWrapper::QueryResult& result,
+       const std::vector<float>& base,
+       const float* queries,
+       int64_t nq) {
+    auto nb = static_cast<int64_t>(base.size()) / DIM;
+    int64_t found = 0;
+    for (int64_t q = 0; q < nq; ++q) {
+        std::vector<std::pair<float, int64_t>> distances(nb);
+        for (int64_t i = 0; i < nb; ++i) {
+            float distance = 0;
+            for (int64_t d = 0; d < DIM; ++d) {
+                auto diff = base[i * DIM + d] - queries[q * DIM + d];
+                distance += diff * diff;
+            }
+            distances[i] = {distance, i};
+        }
+        std::partial_sort(distances.begin(), distances.begin() + K, distances.end());
+        std::set<int64_t> truth;
+        for (int64_t k = 0; k < K; ++k) {
+            truth.insert(distances[k].second);
+        }
+        for (int64_t k = 0; k < K; ++k) {
+            found += truth.count(result.ids[q * K + k]);
+        }
+    }
+    return static_cast<double>(found) / (nq * K);
+}
+}  // namespace
+
+TEST(HNSWWrapper, Append) {
+    auto index_type = milvus::knowhere::IndexEnum::INDEX_HNSW;
+    auto metric_type = milvus::knowhere::Metric::L2;
+    indexcgo::TypeParams type_params;
+    indexcgo::IndexParams index_params;
+    std::tie(type_params, index_params) = generate_params(index_type, metric_type);
+    std::string type_params_str, index_params_str;
+    bool ok = google::protobuf::TextFormat::PrintToString(type_params, &type_params_str);
+    assert(ok);
+    ok = google::protobuf::TextFormat::PrintToString(index_params, &index_params_str);
+    assert(ok);
+
+    auto dataset = GenDataset(NB, metric_type, false);
+    auto xb_data = dataset.get_col<float>(0);
+    auto base_rows = NB / 2;
+    // the queries are of both the base rows and the appended rows
+    auto xq_data = xb_data.data() + (base_rows - NQ / 2) * DIM;
+    auto xq_dataset = milvus::knowhere::GenDataset(NQ, DIM, xq_data);
+
+    auto full_index =
+        std::make_unique<milvus::indexbuilder::IndexWrapper>(type_params_str.c_str(), index_params_str.c_str());
+    ASSERT_NO_THROW(full_index->BuildWithoutIds(milvus::knowhere::GenDataset(NB, DIM, xb_data.data())));
+
+    auto base_index =
+        std::make_unique<milvus::indexbuilder::IndexWrapper>(type_params_str.c_str(), index_params_str.c_str());
+    ASSERT_NO_THROW(base_index->BuildWithoutIds(milvus::knowhere::GenDataset(base_rows, DIM, xb_data.data())));
+    auto binary = base_index->Serialize();
+    auto appended_index =
+        std::make_unique<milvus::indexbuilder::IndexWrapper>(type_params_str.c_str(), index_params_str.c_str());
+    ASSERT_NO_THROW(appended_index->Load(binary->data.data(), binary->data.size()));
+    ASSERT_NO_THROW(appended_index->Append(
+        milvus::knowhere::GenDataset(NB - base_rows, DIM, xb_data.data() + base_rows * DIM)));
+
+    auto full_result = full_index->Query(xq_dataset);
+    auto appended_result = appended_index->Query(xq_dataset);
+    auto full_recall = recall(*full_result, xb_data, xq_data, NQ);
+    auto appended_recall = recall(*appended_result, xb_data, xq_data, NQ);
+    ASSERT_GE(appended_recall, full_recall - 0.05);
+    ASSERT_GE(appended_recall, 0.9);
+
+    // the index types without append support reject it
+    std::tie(type_params, index_params) =
+        generate_params(milvus::knowhere::IndexEnum::INDEX_FAISS_IVFFLAT, metric_type);
+    ok = google::protobuf::TextFormat::PrintToString(type_params, &type_params_str);
+    assert(ok);
+    ok = google::protobuf::TextFormat::PrintToString(index_params, &index_params_str);
+    assert(ok);
+    auto ivf_index =
+        std::make_unique<milvus::indexbuilder::IndexWrapper>(type_params_str.c_str(), index_params_str.c_str());
+    ASSERT_NO_THROW(ivf_index->BuildWithoutIds(milvus::knowhere::GenDataset(base_rows, DIM, xb_data.data())));
+    ASSERT_ANY_THROW(ivf_index->Append(milvus::knowhere::GenDataset(NB - base_rows, DIM, xb_data.data())));
+}
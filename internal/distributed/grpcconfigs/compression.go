@@ -0,0 +1,114 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcconfigs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/golang/snappy"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+const (
+	// CompressionNone sends the messages uncompressed.
+	CompressionNone = ""
+
+	// CompressionGzip compresses the messages with gzip, which compresses the most at the highest cost of CPU.
+	CompressionGzip = gzip.Name
+
+	// CompressionSnappy compresses the messages with snappy, which compresses less than gzip at a much lower cost.
+	CompressionSnappy = "snappy"
+)
+
+// The compressors are registered globally, with which the grpc servers decompress the requests compressed by either
+// of them and compress the responses the same way, while the uncompressed requests are served as they are.
+func init() {
+	encoding.RegisterCompressor(&snappyCompressor{})
+}
+
+// ParseCompression parses the name of the compression of the grpc messages, "none" and the empty name disable it.
+func ParseCompression(s string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	switch name {
+	case "", "none":
+		return CompressionNone, nil
+	case CompressionGzip, CompressionSnappy:
+		return name, nil
+	default:
+		return "", fmt.Errorf("unknown grpc compression %q, expect none, %s or %s", s, CompressionGzip,
+			CompressionSnappy)
+	}
+}
+
+// snappyCompressor is the encoding.Compressor of snappy in the framing format, the writers and the readers are pooled
+// since they hold the buffers of the blocks.
+type snappyCompressor struct {
+	writers sync.Pool
+	readers sync.Pool
+}
+
+type snappyWriter struct {
+	*snappy.Writer
+	pool *sync.Pool
+}
+
+// Close flushes the compressed message and returns the writer to the pool.
+func (w *snappyWriter) Close() error {
+	defer w.pool.Put(w)
+	return w.Writer.Close()
+}
+
+type snappyReader struct {
+	*snappy.Reader
+	pool *sync.Pool
+	// done is set once the message is read up and the reader is returned to the pool, it's cleared on reusing
+	done bool
+}
+
+// Read returns the reader to the pool once the message is read up, the reads after that return io.EOF without
+// returning it again.
+func (r *snappyReader) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, io.EOF
+	}
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		r.done = true
+		r.pool.Put(r)
+	}
+	return n, err
+}
+
+func (c *snappyCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	if sw, ok := c.writers.Get().(*snappyWriter); ok {
+		sw.Reset(w)
+		return sw, nil
+	}
+	return &snappyWriter{Writer: snappy.NewBufferedWriter(w), pool: &c.writers}, nil
+}
+
+func (c *snappyCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	if sr, ok := c.readers.Get().(*snappyReader); ok {
+		sr.Reset(r)
+		sr.done = false
+		return sr, nil
+	}
+	return &snappyReader{Reader: snappy.NewReader(r), pool: &c.readers}, nil
+}
+
+func (c *snappyCompressor) Name() string {
+	return CompressionSnappy
+}
@@ -11,7 +11,10 @@
 
 package grpcconfigs
 
-import "math"
+import (
+	"math"
+	"time"
+)
 
 const (
 	// DefaultServerMaxSendSize defines the maximum size of data per grpc request can send by server side.
@@ -26,3 +29,23 @@ const (
 	// DefaultClientMaxRecvSize defines the maximum size of data per grpc request can receive by client side.
 	DefaultClientMaxRecvSize = 100 * 1024 * 1024
 )
+
+const (
+	// DefaultServerKeepAliveTime is the idle time after which the server pings the client, which keeps the idle
+	// connections alive through the firewalls.
+	DefaultServerKeepAliveTime = 60 * time.Second
+
+	// DefaultServerKeepAliveTimeout is the time the server waits for the ack of a ping before closing the connection.
+	DefaultServerKeepAliveTimeout = 20 * time.Second
+
+	// DefaultServerKeepAliveMinTime is the shortest interval of the pings of the clients tolerated by the server, the
+	// clients pinging more frequently are closed with GOAWAY.
+	DefaultServerKeepAliveMinTime = 10 * time.Second
+
+	// DefaultClientKeepAliveTime is the idle time after which the client pings the server, which is the shortest
+	// interval tolerated by the servers of grpc by default, so that the older servers never close the client.
+	DefaultClientKeepAliveTime = 5 * time.Minute
+
+	// DefaultClientKeepAliveTimeout is the time the client waits for the ack of a ping before closing the connection.
+	DefaultClientKeepAliveTimeout = 20 * time.Second
+)
@@ -0,0 +1,71 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcconfigs
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MinMsgSize is the smallest size of the grpc messages configurable, below which even the small requests fail.
+	MinMsgSize = 4 * 1024
+
+	// MaxMsgSize is the largest size of the grpc messages configurable, which is the limit of the protobuf messages.
+	MaxMsgSize = math.MaxInt32
+)
+
+// the suffixes of the sizes in the powers of 1024, the longer ones go first
+var sizeSuffixes = []struct {
+	suffix string
+	unit   int64
+}{
+	{"KB", 1 << 10},
+	{"MB", 1 << 20},
+	{"GB", 1 << 30},
+	{"K", 1 << 10},
+	{"M", 1 << 20},
+	{"G", 1 << 30},
+	{"B", 1},
+}
+
+// ParseMsgSize parses the size of the grpc messages in bytes, optionally with the suffix KB, MB or GB in the powers of
+// 1024, e.g. 512MB. The size must be within [MinMsgSize, MaxMsgSize].
+func ParseMsgSize(s string) (int, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, suffix := range sizeSuffixes {
+		if strings.HasSuffix(str, suffix.suffix) {
+			str = strings.TrimSpace(strings.TrimSuffix(str, suffix.suffix))
+			unit = suffix.unit
+			break
+		}
+	}
+	value, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	if value > MaxMsgSize {
+		return 0, fmt.Errorf("size %q is out of [%d, %d]", s, MinMsgSize, MaxMsgSize)
+	}
+	size := value * unit
+	if size == MaxMsgSize+1 {
+		// 2GB is taken as the largest size, which is 1 byte less
+		size = MaxMsgSize
+	}
+	if size < MinMsgSize || size > MaxMsgSize {
+		return 0, fmt.Errorf("size %q is out of [%d, %d]", s, MinMsgSize, MaxMsgSize)
+	}
+	return int(size), nil
+}
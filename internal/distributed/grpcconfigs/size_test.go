@@ -0,0 +1,42 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcconfigs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMsgSize(t *testing.T) {
+	for s, expected := range map[string]int{
+		"2147483647": MaxMsgSize,
+		"104857600":  100 * 1024 * 1024,
+		"64KB":       64 * 1024,
+		"512mb":      512 * 1024 * 1024,
+		" 16 MB ":    16 * 1024 * 1024,
+		"8M":         8 * 1024 * 1024,
+		"1GB":        1024 * 1024 * 1024,
+		"2GB":        MaxMsgSize,
+		"4096B":      MinMsgSize,
+	} {
+		size, err := ParseMsgSize(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, expected, size, s)
+	}
+
+	for _, s := range []string{"", "MB", "abc", "1.5MB", "-1", "-1MB", "1KB", "4095", "3GB", "2147483648", "1TB",
+		"99999999999999999999"} {
+		_, err := ParseMsgSize(s)
+		assert.NotNil(t, err, s)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcconfigs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// ClientTLS is the TLS of a grpc client, which dials in plaintext if it's not enabled.
+type ClientTLS struct {
+	// CACert is the file of the CA verifying the certificate of the server, the system roots are used if it's empty
+	CACert string
+	// Cert and Key are the files of the certificate the client presents, which the servers verifying the clients
+	// require, the client presents no certificate if they are empty
+	Cert string
+	Key  string
+	// ServerName overrides the name the certificate of the server is verified with, which is the host of the address
+	// dialed if it's empty
+	ServerName string
+}
+
+// Enabled returns whether the client dials with TLS.
+func (c ClientTLS) Enabled() bool {
+	return c.CACert != "" || c.Cert != "" || c.Key != "" || c.ServerName != ""
+}
+
+// Config returns the tls config of the client, the files are read every time so that the renewed ones are picked up
+// by the new connections.
+func (c ClientTLS) Config() (*tls.Config, error) {
+	if (c.Cert == "") != (c.Key == "") {
+		return nil, errors.New("both the client certificate and the key are required to present a certificate")
+	}
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.ServerName,
+	}
+	if c.CACert != "" {
+		content, err := ioutil.ReadFile(c.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the CA certificate %s: %w", c.CACert, err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(content) {
+			return nil, fmt.Errorf("failed to load the CA certificate %s: no certificate found", c.CACert)
+		}
+		cfg.RootCAs = roots
+	}
+	if c.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(c.Cert, c.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate %s with the key %s: %w", c.Cert, c.Key, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// DialOption returns the option of the transport credentials of the client, which is insecure if TLS is not enabled.
+func (c ClientTLS) DialOption() (grpc.DialOption, error) {
+	if !c.Enabled() {
+		return grpc.WithInsecure(), nil
+	}
+	cfg, err := c.Config()
+	if err != nil {
+		return nil, err
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(cfg)), nil
+}
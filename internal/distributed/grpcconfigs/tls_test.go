@@ -0,0 +1,50 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcconfigs
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientTLS(t *testing.T) {
+	c := ClientTLS{}
+	assert.False(t, c.Enabled())
+	opt, err := c.DialOption()
+	assert.Nil(t, err)
+	assert.NotNil(t, opt)
+
+	// the system roots verify the server if no CA is given
+	c = ClientTLS{ServerName: "indexnode"}
+	assert.True(t, c.Enabled())
+	cfg, err := c.Config()
+	assert.Nil(t, err)
+	assert.Nil(t, cfg.RootCAs)
+	assert.Equal(t, "indexnode", cfg.ServerName)
+	assert.Empty(t, cfg.Certificates)
+
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.crt")
+	assert.Nil(t, ioutil.WriteFile(invalid, []byte("not a certificate"), 0600))
+	for _, c := range []ClientTLS{
+		{CACert: filepath.Join(dir, "missing.crt")},
+		{CACert: invalid},
+		{Cert: invalid},
+		{Cert: invalid, Key: invalid},
+	} {
+		_, err = c.DialOption()
+		assert.NotNil(t, err, c)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	grpc_opentracing "github.com/grpc-ecosystem/go-grpc-middleware/tracing/opentracing"
 	"github.com/milvus-io/milvus/internal/log"
 	"github.com/milvus-io/milvus/internal/types"
+	"github.com/milvus-io/milvus/internal/util/grpcretry"
 	"github.com/milvus-io/milvus/internal/util/retry"
 	"github.com/milvus-io/milvus/internal/util/sessionutil"
 	"github.com/milvus-io/milvus/internal/util/trace"
@@ -143,16 +144,22 @@ func (c *Client) connect(retryOptions ...retry.Option) error {
 		}
 		opts := trace.GetInterceptorOpts()
 		log.Debug("IndexCoordClient try connect ", zap.String("address", c.addr))
+		// the certificate files are read again on reconnecting, which picks up the renewed ones
+		transport, err := Params.ClientTLS.DialOption()
+		if err != nil {
+			log.Warn("IndexCoordClient failed to load the TLS certificates", zap.Error(err))
+			return err
+		}
 		ctx, cancel := context.WithTimeout(c.ctx, 15*time.Second)
 		defer cancel()
 		conn, err := grpc.DialContext(ctx, c.addr,
-			grpc.WithInsecure(), grpc.WithBlock(),
+			transport, grpc.WithBlock(),
 			grpc.WithDefaultCallOptions(
 				grpc.MaxCallRecvMsgSize(Params.ClientMaxRecvSize),
 				grpc.MaxCallSendMsgSize(Params.ClientMaxSendSize)),
 			grpc.WithUnaryInterceptor(
 				grpc_middleware.ChainUnaryClient(
-					grpc_retry.UnaryClientInterceptor(grpc_retry.WithMax(3)),
+					grpcretry.UnaryClientInterceptor(retryConfig()),
 					grpc_opentracing.UnaryClientInterceptor(opts...),
 				)),
 			grpc.WithStreamInterceptor(
@@ -181,6 +188,27 @@ func (c *Client) connect(retryOptions ...retry.Option) error {
 	return nil
 }
 
+// retryConfig returns the retries of the calls to IndexCoord. BuildIndex is never retried, since the coordinator
+// may build the index twice if the retry arrives before the first request is recorded.
+func retryConfig() grpcretry.Config {
+	idempotent := grpcretry.Policy{Idempotent: true}
+	return grpcretry.Config{
+		MaxRetry:   Params.ClientMaxRetry,
+		Backoff:    Params.ClientBackoff,
+		MaxBackoff: grpcretry.DefaultMaxBackoff,
+		Policies: map[string]grpcretry.Policy{
+			// the health checks are polled anyway, and report the outage sooner without the retries
+			"GetComponentStates":   grpcretry.Budget(1),
+			"GetTimeTickChannel":   idempotent,
+			"GetStatisticsChannel": idempotent,
+			"DropIndex":            idempotent,
+			"GetIndexStates":       idempotent,
+			"GetIndexFilePaths":    idempotent,
+			"GetMetrics":           idempotent,
+		},
+	}
+}
+
 func (c *Client) recall(caller func() (interface{}, error)) (interface{}, error) {
 	ret, err := caller()
 	if err == nil {
@@ -197,6 +225,16 @@ func (c *Client) recall(caller func() (interface{}, error)) (interface{}, error)
 	return ret, err
 }
 
+// callOnce calls @caller without calling it again on the new connection once it fails, see recall.
+func (c *Client) callOnce(caller func() (interface{}, error)) (interface{}, error) {
+	ret, err := caller()
+	if err != nil {
+		log.Debug("IndexCoord Client grpc error", zap.Error(err))
+		c.resetConnection()
+	}
+	return ret, err
+}
+
 // Start starts IndexCoord's client service. But it does nothing here.
 func (c *Client) Start() error {
 	return nil
@@ -268,7 +306,7 @@ func (c *Client) GetStatisticsChannel(ctx context.Context) (*milvuspb.StringResp
 
 // BuildIndex sends the build index request to IndexCoord.
 func (c *Client) BuildIndex(ctx context.Context, req *indexpb.BuildIndexRequest) (*indexpb.BuildIndexResponse, error) {
-	ret, err := c.recall(func() (interface{}, error) {
+	ret, err := c.callOnce(func() (interface{}, error) {
 		client, err := c.getGrpcClient()
 		if err != nil {
 			return nil, err
@@ -13,10 +13,13 @@ package grpcindexcoordclient
 
 import (
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/milvus-io/milvus/internal/distributed/grpcconfigs"
 	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/util/grpcretry"
 	"go.uber.org/zap"
 
 	"github.com/milvus-io/milvus/internal/util/paramtable"
@@ -28,6 +31,14 @@ type ParamTable struct {
 
 	ClientMaxSendSize int
 	ClientMaxRecvSize int
+
+	// ClientMaxRetry and ClientBackoff are the number of the retries of the idempotent calls failed while IndexCoord
+	// is unavailable, and the backoff before the first retry, which doubles per retry.
+	ClientMaxRetry uint
+	ClientBackoff  time.Duration
+
+	// ClientTLS is the TLS the client dials IndexCoord with, which is plaintext if it's not enabled.
+	ClientTLS grpcconfigs.ClientTLS
 }
 
 // Params is an alias for ParamTable.
@@ -41,6 +52,8 @@ func (pt *ParamTable) Init() {
 
 		pt.initClientMaxSendSize()
 		pt.initClientMaxRecvSize()
+		pt.initClientRetry()
+		pt.initClientTLS()
 	})
 }
 
@@ -89,3 +102,57 @@ func (pt *ParamTable) initClientMaxRecvSize() {
 	log.Debug("initClientMaxRecvSize",
 		zap.Int("indexCoord.grpc.clientMaxRecvSize", pt.ClientMaxRecvSize))
 }
+
+func (pt *ParamTable) initClientRetry() {
+	maxRetryStr, err := pt.LoadWithDefault("indexCoord.grpc.client.maxRetry", strconv.Itoa(grpcretry.DefaultMaxRetry))
+	if err != nil {
+		panic(err)
+	}
+	maxRetry, err := strconv.ParseUint(maxRetryStr, 10, 32)
+	if err != nil {
+		log.Warn("Failed to parse indexCoord.grpc.client.maxRetry, set to default",
+			zap.String("indexCoord.grpc.client.maxRetry", maxRetryStr),
+			zap.Error(err))
+		maxRetry = grpcretry.DefaultMaxRetry
+	}
+	pt.ClientMaxRetry = uint(maxRetry)
+
+	defaultBackoff := int64(grpcretry.DefaultBackoff / time.Millisecond)
+	backoffStr, err := pt.LoadWithDefault("indexCoord.grpc.client.backoff", strconv.FormatInt(defaultBackoff, 10))
+	if err != nil {
+		panic(err)
+	}
+	backoff, err := strconv.ParseInt(backoffStr, 10, 64)
+	if err != nil || backoff <= 0 {
+		log.Warn("Failed to parse indexCoord.grpc.client.backoff, set to default",
+			zap.String("indexCoord.grpc.client.backoff", backoffStr),
+			zap.Int64("default", defaultBackoff),
+			zap.Error(err))
+		backoff = defaultBackoff
+	}
+	pt.ClientBackoff = time.Duration(backoff) * time.Millisecond
+
+	log.Debug("initClientRetry",
+		zap.Uint("indexCoord.grpc.client.maxRetry", pt.ClientMaxRetry),
+		zap.Duration("indexCoord.grpc.client.backoff", pt.ClientBackoff))
+}
+
+func (pt *ParamTable) initClientTLS() {
+	var err error
+	for key, value := range map[string]*string{
+		"indexCoord.grpc.client.caCert":     &pt.ClientTLS.CACert,
+		"indexCoord.grpc.client.cert":       &pt.ClientTLS.Cert,
+		"indexCoord.grpc.client.key":        &pt.ClientTLS.Key,
+		"indexCoord.grpc.client.serverName": &pt.ClientTLS.ServerName,
+	} {
+		*value, err = pt.LoadWithDefault(key, "")
+		if err != nil {
+			panic(err)
+		}
+		*value = strings.TrimSpace(*value)
+	}
+	log.Debug("initClientTLS", zap.Bool("enabled", pt.ClientTLS.Enabled()),
+		zap.String("indexCoord.grpc.client.caCert", pt.ClientTLS.CACert),
+		zap.String("indexCoord.grpc.client.cert", pt.ClientTLS.Cert),
+		zap.String("indexCoord.grpc.client.serverName", pt.ClientTLS.ServerName))
+}
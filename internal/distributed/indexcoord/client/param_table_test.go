@@ -13,9 +13,11 @@ package grpcindexcoordclient
 
 import (
 	"testing"
+	"time"
 
 	"github.com/milvus-io/milvus/internal/distributed/grpcconfigs"
 	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/util/grpcretry"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -33,4 +35,37 @@ func TestParamTable(t *testing.T) {
 	Params.Remove("indexCoord.grpc.clientMaxRecvSize")
 	Params.initClientMaxRecvSize()
 	assert.Equal(t, Params.ClientMaxRecvSize, grpcconfigs.DefaultClientMaxRecvSize)
+
+	assert.Equal(t, uint(grpcretry.DefaultMaxRetry), Params.ClientMaxRetry)
+	assert.Equal(t, grpcretry.DefaultBackoff, Params.ClientBackoff)
+	Params.Save("indexCoord.grpc.client.maxRetry", "0")
+	Params.Save("indexCoord.grpc.client.backoff", "50")
+	Params.initClientRetry()
+	assert.Equal(t, uint(0), Params.ClientMaxRetry)
+	assert.Equal(t, 50*time.Millisecond, Params.ClientBackoff)
+	Params.Save("indexCoord.grpc.client.maxRetry", "-1")
+	Params.Save("indexCoord.grpc.client.backoff", "0")
+	Params.initClientRetry()
+	assert.Equal(t, uint(grpcretry.DefaultMaxRetry), Params.ClientMaxRetry)
+	assert.Equal(t, grpcretry.DefaultBackoff, Params.ClientBackoff)
+
+	assert.False(t, Params.ClientTLS.Enabled())
+	Params.Save("indexCoord.grpc.client.caCert", " /etc/milvus/tls/ca.crt ")
+	Params.Save("indexCoord.grpc.client.cert", "/etc/milvus/tls/indexnode.crt")
+	Params.Save("indexCoord.grpc.client.key", "/etc/milvus/tls/indexnode.key")
+	Params.Save("indexCoord.grpc.client.serverName", "indexcoord")
+	Params.initClientTLS()
+	assert.Equal(t, grpcconfigs.ClientTLS{CACert: "/etc/milvus/tls/ca.crt", Cert: "/etc/milvus/tls/indexnode.crt",
+		Key: "/etc/milvus/tls/indexnode.key", ServerName: "indexcoord"}, Params.ClientTLS)
+	for _, key := range []string{"caCert", "cert", "key", "serverName"} {
+		Params.Remove("indexCoord.grpc.client." + key)
+	}
+	Params.initClientTLS()
+	assert.False(t, Params.ClientTLS.Enabled())
+
+	cfg := retryConfig()
+	assert.Equal(t, Params.ClientMaxRetry, cfg.MaxRetry)
+	assert.True(t, cfg.Policies["GetIndexStates"].Idempotent)
+	_, ok := cfg.Policies["BuildIndex"]
+	assert.False(t, ok)
 }
@@ -0,0 +1,66 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcindexnode
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	grpcindexnodeclient "github.com/milvus-io/milvus/internal/distributed/indexnode/client"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/tokenauth"
+)
+
+func TestServer_Auth(t *testing.T) {
+	Params.Init()
+	grpcindexnodeclient.Params.Init()
+	oldExempt := Params.AuthExemptMethods
+	oldToken, oldFile := grpcindexnodeclient.Params.AuthToken, grpcindexnodeclient.Params.AuthTokenFile
+	defer func() {
+		Params.AuthExemptMethods = oldExempt
+		grpcindexnodeclient.Params.AuthToken, grpcindexnodeclient.Params.AuthTokenFile = oldToken, oldFile
+	}()
+	Params.AuthExemptMethods = []string{"GetComponentStates"}
+	addr, stop := startServer(t, &Server{token: "secret"})
+	defer stop()
+
+	createIndex := func(token tokenauth.Token, file string) error {
+		grpcindexnodeclient.Params.AuthToken, grpcindexnodeclient.Params.AuthTokenFile = token, file
+		client, err := grpcindexnodeclient.NewClient(context.Background(), addr)
+		assert.Nil(t, err)
+		defer client.Stop()
+		_, err = client.CreateIndex(context.Background(), &indexpb.CreateIndexRequest{})
+		return err
+	}
+	assert.Nil(t, createIndex("secret", ""))
+	file := filepath.Join(t.TempDir(), "token")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("secret\n"), 0600))
+	assert.Nil(t, createIndex("", file))
+	err := createIndex("other", "")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	err = createIndex("", "")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// the probes are exempt
+	conn := dialIndexNode(t, addr, grpc.WithInsecure())
+	defer conn.Close()
+	assert.Nil(t, getComponentStates(conn))
+	_, err = indexpb.NewIndexNodeClient(conn).CreateIndex(context.Background(), &indexpb.CreateIndexRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -20,13 +20,17 @@ import (
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
 	grpc_opentracing "github.com/grpc-ecosystem/go-grpc-middleware/tracing/opentracing"
+	"github.com/milvus-io/milvus/internal/distributed/grpcconfigs"
 	"github.com/milvus-io/milvus/internal/log"
 	"github.com/milvus-io/milvus/internal/proto/milvuspb"
+	"github.com/milvus-io/milvus/internal/util/requestid"
 	"github.com/milvus-io/milvus/internal/util/retry"
+	"github.com/milvus-io/milvus/internal/util/tokenauth"
 	"github.com/milvus-io/milvus/internal/util/trace"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
 
 	"github.com/milvus-io/milvus/internal/proto/commonpb"
 	"github.com/milvus-io/milvus/internal/proto/indexpb"
@@ -114,15 +118,27 @@ func (c *Client) connect(retryOptions ...retry.Option) error {
 	connectGrpcFunc := func() error {
 		opts := trace.GetInterceptorOpts()
 		log.Debug("IndexNodeClient try connect ", zap.String("address", c.addr))
+		// the token file is read again on reconnecting, which picks up the rotated token
+		token, err := tokenauth.LoadToken(string(Params.AuthToken), Params.AuthTokenFile)
+		if err != nil {
+			log.Warn("IndexNodeClient failed to load the auth token", zap.Error(err))
+			return err
+		}
+		// the certificate files are read again on reconnecting as well, which picks up the renewed ones
+		transport, err := Params.ClientTLS.DialOption()
+		if err != nil {
+			log.Warn("IndexNodeClient failed to load the TLS certificates", zap.Error(err))
+			return err
+		}
 		ctx, cancel := context.WithTimeout(c.ctx, 15*time.Second)
 		defer cancel()
-		conn, err := grpc.DialContext(ctx, c.addr,
-			grpc.WithInsecure(), grpc.WithBlock(),
-			grpc.WithDefaultCallOptions(
-				grpc.MaxCallRecvMsgSize(Params.ClientMaxRecvSize),
-				grpc.MaxCallSendMsgSize(Params.ClientMaxSendSize)),
+		dialOpts := []grpc.DialOption{
+			transport, grpc.WithBlock(),
+			grpc.WithDefaultCallOptions(callOptions()...),
 			grpc.WithUnaryInterceptor(
 				grpc_middleware.ChainUnaryClient(
+					requestid.UnaryClientInterceptor(),
+					tokenauth.UnaryClientInterceptor(token),
 					grpc_retry.UnaryClientInterceptor(
 						grpc_retry.WithMax(3),
 						grpc_retry.WithCodes(codes.Aborted, codes.Unavailable),
@@ -131,13 +147,18 @@ func (c *Client) connect(retryOptions ...retry.Option) error {
 				)),
 			grpc.WithStreamInterceptor(
 				grpc_middleware.ChainStreamClient(
+					tokenauth.StreamClientInterceptor(token),
 					grpc_retry.StreamClientInterceptor(
 						grpc_retry.WithMax(3),
 						grpc_retry.WithCodes(codes.Aborted, codes.Unavailable),
 					),
 					grpc_opentracing.StreamClientInterceptor(opts...),
 				)),
-		)
+		}
+		if keepalive := keepaliveOption(); keepalive != nil {
+			dialOpts = append(dialOpts, keepalive)
+		}
+		conn, err := grpc.DialContext(ctx, c.addr, dialOpts...)
 		if err != nil {
 			return err
 		}
@@ -158,6 +179,32 @@ func (c *Client) connect(retryOptions ...retry.Option) error {
 	return nil
 }
 
+// callOptions returns the default options of the calls to IndexNode, which compress the requests with
+// Params.ClientCompression if it's set.
+func callOptions() []grpc.CallOption {
+	callOpts := []grpc.CallOption{
+		grpc.MaxCallRecvMsgSize(Params.ClientMaxRecvSize),
+		grpc.MaxCallSendMsgSize(Params.ClientMaxSendSize),
+	}
+	if Params.ClientCompression != grpcconfigs.CompressionNone {
+		callOpts = append(callOpts, grpc.UseCompressor(Params.ClientCompression))
+	}
+	return callOpts
+}
+
+// keepaliveOption returns the option pinging IndexNode once the connection is idle for Params.ClientKeepAliveTime,
+// it returns nil if the keepalive is disabled.
+func keepaliveOption() grpc.DialOption {
+	if Params.ClientKeepAliveTime <= 0 {
+		return nil
+	}
+	return grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                Params.ClientKeepAliveTime,
+		Timeout:             Params.ClientKeepAliveTimeout,
+		PermitWithoutStream: Params.ClientKeepAlivePermitWithoutStream,
+	})
+}
+
 func (c *Client) recall(caller func() (interface{}, error)) (interface{}, error) {
 	ret, err := caller()
 	if err == nil {
@@ -259,6 +306,102 @@ func (c *Client) CreateIndex(ctx context.Context, req *indexpb.CreateIndexReques
 	return ret.(*commonpb.Status), err
 }
 
+// DryRunCreateIndex sends the build index request to IndexNode to validate it without building the index.
+func (c *Client) DryRunCreateIndex(ctx context.Context, req *indexpb.CreateIndexRequest) (*indexpb.DryRunCreateIndexResponse, error) {
+	ret, err := c.recall(func() (interface{}, error) {
+		client, err := c.getGrpcClient()
+		if err != nil {
+			return nil, err
+		}
+
+		return client.DryRunCreateIndex(ctx, req)
+	})
+	if err != nil || ret == nil {
+		return nil, err
+	}
+	return ret.(*indexpb.DryRunCreateIndexResponse), err
+}
+
+// Activate switches the standby IndexNode to active.
+func (c *Client) Activate(ctx context.Context, req *indexpb.ActivateRequest) (*commonpb.Status, error) {
+	ret, err := c.recall(func() (interface{}, error) {
+		client, err := c.getGrpcClient()
+		if err != nil {
+			return nil, err
+		}
+
+		return client.Activate(ctx, req)
+	})
+	if err != nil || ret == nil {
+		return nil, err
+	}
+	return ret.(*commonpb.Status), err
+}
+
+// CaptureProfile captures a profile of IndexNode and uploads it to the object storage.
+func (c *Client) CaptureProfile(ctx context.Context, req *indexpb.CaptureProfileRequest) (*indexpb.CaptureProfileResponse, error) {
+	ret, err := c.recall(func() (interface{}, error) {
+		client, err := c.getGrpcClient()
+		if err != nil {
+			return nil, err
+		}
+
+		return client.CaptureProfile(ctx, req)
+	})
+	if err != nil || ret == nil {
+		return nil, err
+	}
+	return ret.(*indexpb.CaptureProfileResponse), err
+}
+
+// VerifyIndexManifest re-checks the index files of a build against the manifest saved along with them.
+func (c *Client) VerifyIndexManifest(ctx context.Context, req *indexpb.VerifyIndexManifestRequest) (*indexpb.VerifyIndexManifestResponse, error) {
+	ret, err := c.recall(func() (interface{}, error) {
+		client, err := c.getGrpcClient()
+		if err != nil {
+			return nil, err
+		}
+
+		return client.VerifyIndexManifest(ctx, req)
+	})
+	if err != nil || ret == nil {
+		return nil, err
+	}
+	return ret.(*indexpb.VerifyIndexManifestResponse), err
+}
+
+// RunBuildBenchmark builds an index of the synthetic vectors to measure the build throughput of IndexNode.
+func (c *Client) RunBuildBenchmark(ctx context.Context, req *indexpb.RunBuildBenchmarkRequest) (*indexpb.RunBuildBenchmarkResponse, error) {
+	ret, err := c.recall(func() (interface{}, error) {
+		client, err := c.getGrpcClient()
+		if err != nil {
+			return nil, err
+		}
+
+		return client.RunBuildBenchmark(ctx, req)
+	})
+	if err != nil || ret == nil {
+		return nil, err
+	}
+	return ret.(*indexpb.RunBuildBenchmarkResponse), err
+}
+
+// RebuildIncompatibleIndexes starts to rebuild the indexes built by IndexNode which are too old for the current engine.
+func (c *Client) RebuildIncompatibleIndexes(ctx context.Context, req *indexpb.RebuildIncompatibleIndexesRequest) (*commonpb.Status, error) {
+	ret, err := c.recall(func() (interface{}, error) {
+		client, err := c.getGrpcClient()
+		if err != nil {
+			return nil, err
+		}
+
+		return client.RebuildIncompatibleIndexes(ctx, req)
+	})
+	if err != nil || ret == nil {
+		return nil, err
+	}
+	return ret.(*commonpb.Status), err
+}
+
 // GetMetrics gets the metrics info of IndexNode.
 func (c *Client) GetMetrics(ctx context.Context, req *milvuspb.GetMetricsRequest) (*milvuspb.GetMetricsResponse, error) {
 	ret, err := c.recall(func() (interface{}, error) {
@@ -47,6 +47,30 @@ func (m *MockIndexNodeClient) CreateIndex(ctx context.Context, in *indexpb.Creat
 	return &commonpb.Status{}, m.err
 }
 
+func (m *MockIndexNodeClient) DryRunCreateIndex(ctx context.Context, in *indexpb.CreateIndexRequest, opts ...grpc.CallOption) (*indexpb.DryRunCreateIndexResponse, error) {
+	return &indexpb.DryRunCreateIndexResponse{}, m.err
+}
+
+func (m *MockIndexNodeClient) Activate(ctx context.Context, in *indexpb.ActivateRequest, opts ...grpc.CallOption) (*commonpb.Status, error) {
+	return &commonpb.Status{}, m.err
+}
+
+func (m *MockIndexNodeClient) CaptureProfile(ctx context.Context, in *indexpb.CaptureProfileRequest, opts ...grpc.CallOption) (*indexpb.CaptureProfileResponse, error) {
+	return &indexpb.CaptureProfileResponse{}, m.err
+}
+
+func (m *MockIndexNodeClient) VerifyIndexManifest(ctx context.Context, in *indexpb.VerifyIndexManifestRequest, opts ...grpc.CallOption) (*indexpb.VerifyIndexManifestResponse, error) {
+	return &indexpb.VerifyIndexManifestResponse{}, m.err
+}
+
+func (m *MockIndexNodeClient) RunBuildBenchmark(ctx context.Context, in *indexpb.RunBuildBenchmarkRequest, opts ...grpc.CallOption) (*indexpb.RunBuildBenchmarkResponse, error) {
+	return &indexpb.RunBuildBenchmarkResponse{}, m.err
+}
+
+func (m *MockIndexNodeClient) RebuildIncompatibleIndexes(ctx context.Context, in *indexpb.RebuildIncompatibleIndexesRequest, opts ...grpc.CallOption) (*commonpb.Status, error) {
+	return &commonpb.Status{}, m.err
+}
+
 func (m *MockIndexNodeClient) GetMetrics(ctx context.Context, in *milvuspb.GetMetricsRequest, opts ...grpc.CallOption) (*milvuspb.GetMetricsResponse, error) {
 	return &milvuspb.GetMetricsResponse{}, m.err
 }
@@ -97,6 +121,24 @@ func Test_NewClient(t *testing.T) {
 
 		r5, err := client.GetMetrics(ctx, nil)
 		retCheck(retNotNil, r5, err)
+
+		r6, err := client.DryRunCreateIndex(ctx, nil)
+		retCheck(retNotNil, r6, err)
+
+		r7, err := client.Activate(ctx, nil)
+		retCheck(retNotNil, r7, err)
+
+		r8, err := client.CaptureProfile(ctx, nil)
+		retCheck(retNotNil, r8, err)
+
+		r9, err := client.VerifyIndexManifest(ctx, nil)
+		retCheck(retNotNil, r9, err)
+
+		r10, err := client.RunBuildBenchmark(ctx, nil)
+		retCheck(retNotNil, r10, err)
+
+		r11, err := client.RebuildIncompatibleIndexes(ctx, nil)
+		retCheck(retNotNil, r11, err)
 	}
 
 	client.getGrpcClient = func() (indexpb.IndexNodeClient, error) {
@@ -171,6 +213,47 @@ func TestIndexNodeClient(t *testing.T) {
 		assert.Equal(t, commonpb.ErrorCode_Success, resp.ErrorCode)
 	})
 
+	t.Run("DryRunCreateIndex", func(t *testing.T) {
+		req := &indexpb.CreateIndexRequest{
+			IndexBuildID: 0,
+			IndexID:      0,
+		}
+		resp, err := inc.DryRunCreateIndex(ctx, req)
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+		assert.True(t, resp.Passed)
+	})
+
+	t.Run("Activate", func(t *testing.T) {
+		resp, err := inc.Activate(ctx, &indexpb.ActivateRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.ErrorCode)
+	})
+
+	t.Run("CaptureProfile", func(t *testing.T) {
+		resp, err := inc.CaptureProfile(ctx, &indexpb.CaptureProfileRequest{ProfileType: "heap"})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+	})
+
+	t.Run("VerifyIndexManifest", func(t *testing.T) {
+		resp, err := inc.VerifyIndexManifest(ctx, &indexpb.VerifyIndexManifestRequest{IndexBuildID: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+	})
+
+	t.Run("RunBuildBenchmark", func(t *testing.T) {
+		resp, err := inc.RunBuildBenchmark(ctx, &indexpb.RunBuildBenchmarkRequest{Rows: 1000, Dim: 8, IndexType: "FLAT"})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+	})
+
+	t.Run("RebuildIncompatibleIndexes", func(t *testing.T) {
+		resp, err := inc.RebuildIncompatibleIndexes(ctx, &indexpb.RebuildIncompatibleIndexesRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.ErrorCode)
+	})
+
 	t.Run("GetMetrics", func(t *testing.T) {
 		req := &milvuspb.GetMetricsRequest{}
 		resp, err := inc.GetMetrics(ctx, req)
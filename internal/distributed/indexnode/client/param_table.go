@@ -13,13 +13,16 @@ package grpcindexnodeclient
 
 import (
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/milvus-io/milvus/internal/distributed/grpcconfigs"
 	"github.com/milvus-io/milvus/internal/log"
 	"go.uber.org/zap"
 
 	"github.com/milvus-io/milvus/internal/util/paramtable"
+	"github.com/milvus-io/milvus/internal/util/tokenauth"
 )
 
 type ParamTable struct {
@@ -27,6 +30,26 @@ type ParamTable struct {
 
 	ClientMaxSendSize int
 	ClientMaxRecvSize int
+
+	// ClientKeepAliveTime and ClientKeepAliveTimeout are the idle time after which the client pings IndexNode, and
+	// the time waiting for the ack before closing the connection, the keepalive is disabled if the time is 0. The
+	// client pings without the in-flight rpcs as well if ClientKeepAlivePermitWithoutStream.
+	ClientKeepAliveTime                time.Duration
+	ClientKeepAliveTimeout             time.Duration
+	ClientKeepAlivePermitWithoutStream bool
+
+	// ClientCompression is the compressor of the requests to IndexNode, none if it's empty. The compression is
+	// negotiated per message, IndexNode responds the same way and the peers not supporting it keep working.
+	ClientCompression string
+
+	// ClientTLS is the TLS the client dials IndexNode with, which is plaintext if it's not enabled. It must be enabled
+	// if IndexNode serves TLS, and present a certificate if IndexNode verifies the clients.
+	ClientTLS grpcconfigs.ClientTLS
+
+	// AuthToken or the file of it AuthTokenFile is the token attached to the calls, which IndexNode requires if it's
+	// configured with the same indexNode.auth.token or indexNode.auth.tokenFile.
+	AuthToken     tokenauth.Token
+	AuthTokenFile string
 }
 
 var Params ParamTable
@@ -38,51 +61,118 @@ func (pt *ParamTable) Init() {
 
 		pt.initClientMaxSendSize()
 		pt.initClientMaxRecvSize()
+		pt.initClientKeepAlive()
+		pt.initClientCompression()
+		pt.initClientTLS()
+		pt.initAuth()
 	})
 }
 
 func (pt *ParamTable) initClientMaxSendSize() {
-	var err error
+	pt.ClientMaxSendSize = pt.parseMsgSize("indexNode.grpc.clientMaxSendSize", grpcconfigs.DefaultClientMaxSendSize)
+	log.Debug("initClientMaxSendSize",
+		zap.Int("indexNode.grpc.clientMaxSendSize", pt.ClientMaxSendSize))
+}
 
-	valueStr, err := pt.Load("indexNode.grpc.clientMaxSendSize")
-	if err != nil { // not set
-		pt.ClientMaxSendSize = grpcconfigs.DefaultClientMaxSendSize
+func (pt *ParamTable) initClientMaxRecvSize() {
+	pt.ClientMaxRecvSize = pt.parseMsgSize("indexNode.grpc.clientMaxRecvSize", grpcconfigs.DefaultClientMaxRecvSize)
+	log.Debug("initClientMaxRecvSize",
+		zap.Int("indexNode.grpc.clientMaxRecvSize", pt.ClientMaxRecvSize))
+}
+
+func (pt *ParamTable) initClientKeepAlive() {
+	pt.ClientKeepAliveTime = pt.parseSeconds("indexNode.grpc.clientKeepAliveTime", grpcconfigs.DefaultClientKeepAliveTime)
+	pt.ClientKeepAliveTimeout = pt.parseSeconds("indexNode.grpc.clientKeepAliveTimeout",
+		grpcconfigs.DefaultClientKeepAliveTimeout)
+	if pt.ClientKeepAliveTimeout == 0 {
+		pt.ClientKeepAliveTimeout = grpcconfigs.DefaultClientKeepAliveTimeout
 	}
+	pt.ClientKeepAlivePermitWithoutStream = pt.ParseBool("indexNode.grpc.clientKeepAlivePermitWithoutStream", false)
+}
 
-	value, err := strconv.Atoi(valueStr)
-	if err != nil { // not in valid format
-		log.Warn("Failed to parse indexNode.grpc.clientMaxSendSize, set to default",
-			zap.String("indexNode.grpc.clientMaxSendSize", valueStr),
+func (pt *ParamTable) initClientCompression() {
+	valueStr, err := pt.LoadWithDefault("indexNode.grpc.compression", grpcconfigs.CompressionNone)
+	if err != nil {
+		panic(err)
+	}
+	compression, err := grpcconfigs.ParseCompression(valueStr)
+	if err != nil {
+		log.Warn("Failed to parse indexNode.grpc.compression, set to none",
+			zap.String("indexNode.grpc.compression", valueStr),
 			zap.Error(err))
-
-		pt.ClientMaxSendSize = grpcconfigs.DefaultClientMaxSendSize
-	} else {
-		pt.ClientMaxSendSize = value
 	}
-
-	log.Debug("initClientMaxSendSize",
-		zap.Int("indexNode.grpc.clientMaxSendSize", pt.ClientMaxSendSize))
+	pt.ClientCompression = compression
+	log.Debug("initClientCompression",
+		zap.String("indexNode.grpc.compression", pt.ClientCompression))
 }
 
-func (pt *ParamTable) initClientMaxRecvSize() {
+func (pt *ParamTable) initClientTLS() {
 	var err error
+	for key, value := range map[string]*string{
+		"indexNode.grpc.clientCACert":     &pt.ClientTLS.CACert,
+		"indexNode.grpc.clientCert":       &pt.ClientTLS.Cert,
+		"indexNode.grpc.clientKey":        &pt.ClientTLS.Key,
+		"indexNode.grpc.clientServerName": &pt.ClientTLS.ServerName,
+	} {
+		*value, err = pt.LoadWithDefault(key, "")
+		if err != nil {
+			panic(err)
+		}
+		*value = strings.TrimSpace(*value)
+	}
+	log.Debug("initClientTLS", zap.Bool("enabled", pt.ClientTLS.Enabled()),
+		zap.String("indexNode.grpc.clientCACert", pt.ClientTLS.CACert),
+		zap.String("indexNode.grpc.clientCert", pt.ClientTLS.Cert),
+		zap.String("indexNode.grpc.clientServerName", pt.ClientTLS.ServerName))
+}
 
-	valueStr, err := pt.Load("indexNode.grpc.clientMaxRecvSize")
-	if err != nil { // not set
-		pt.ClientMaxRecvSize = grpcconfigs.DefaultClientMaxRecvSize
+func (pt *ParamTable) initAuth() {
+	token, err := pt.LoadWithDefault("indexNode.auth.token", "")
+	if err != nil {
+		panic(err)
 	}
+	pt.AuthToken = tokenauth.Token(strings.TrimSpace(token))
+	pt.AuthTokenFile, err = pt.LoadWithDefault("indexNode.auth.tokenFile", "")
+	if err != nil {
+		panic(err)
+	}
+	pt.AuthTokenFile = strings.TrimSpace(pt.AuthTokenFile)
+	log.Debug("initAuth", zap.Stringer("indexNode.auth.token", pt.AuthToken),
+		zap.String("indexNode.auth.tokenFile", pt.AuthTokenFile))
+}
 
-	value, err := strconv.Atoi(valueStr)
-	if err != nil { // not in valid format
-		log.Warn("Failed to parse indexNode.grpc.clientMaxRecvSize, set to default",
-			zap.String("indexNode.grpc.clientMaxRecvSize", valueStr),
+// parseSeconds parses the non-negative duration in seconds of @key, the invalid value is replaced by @defaultValue.
+func (pt *ParamTable) parseSeconds(key string, defaultValue time.Duration) time.Duration {
+	defaultSeconds := int64(defaultValue / time.Second)
+	valueStr, err := pt.LoadWithDefault(key, strconv.FormatInt(defaultSeconds, 10))
+	if err != nil {
+		panic(err)
+	}
+	seconds, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil || seconds < 0 {
+		log.Warn("Failed to parse "+key+", set to default",
+			zap.String(key, valueStr),
+			zap.Int64("default", defaultSeconds),
 			zap.Error(err))
-
-		pt.ClientMaxRecvSize = grpcconfigs.DefaultClientMaxRecvSize
-	} else {
-		pt.ClientMaxRecvSize = value
+		return defaultValue
 	}
+	return time.Duration(seconds) * time.Second
+}
 
-	log.Debug("initClientMaxRecvSize",
-		zap.Int("indexNode.grpc.clientMaxRecvSize", pt.ClientMaxRecvSize))
+// parseMsgSize parses the size of the grpc messages of @key, optionally with the suffix KB, MB or GB, the invalid
+// value or the one out of the bounds is replaced by @defaultValue.
+func (pt *ParamTable) parseMsgSize(key string, defaultValue int) int {
+	valueStr, err := pt.LoadWithDefault(key, strconv.Itoa(defaultValue))
+	if err != nil {
+		panic(err)
+	}
+	size, err := grpcconfigs.ParseMsgSize(valueStr)
+	if err != nil {
+		log.Warn("Failed to parse "+key+", set to default",
+			zap.String(key, valueStr),
+			zap.Int("default", defaultValue),
+			zap.Error(err))
+		return defaultValue
+	}
+	return size
 }
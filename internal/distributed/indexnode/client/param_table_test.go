@@ -13,9 +13,11 @@ package grpcindexnodeclient
 
 import (
 	"testing"
+	"time"
 
 	"github.com/milvus-io/milvus/internal/distributed/grpcconfigs"
 	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/util/tokenauth"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -33,4 +35,72 @@ func TestParamTable(t *testing.T) {
 	Params.Remove("indexNode.grpc.clientMaxRecvSize")
 	Params.initClientMaxRecvSize()
 	assert.Equal(t, Params.ClientMaxRecvSize, grpcconfigs.DefaultClientMaxRecvSize)
+
+	Params.Save("indexNode.grpc.clientMaxSendSize", "16MB")
+	Params.Save("indexNode.grpc.clientMaxRecvSize", "4GB")
+	Params.initClientMaxSendSize()
+	Params.initClientMaxRecvSize()
+	assert.Equal(t, 16*1024*1024, Params.ClientMaxSendSize)
+	assert.Equal(t, grpcconfigs.DefaultClientMaxRecvSize, Params.ClientMaxRecvSize)
+
+	Params.Remove("indexNode.grpc.clientKeepAliveTime")
+	Params.Remove("indexNode.grpc.clientKeepAliveTimeout")
+	Params.Remove("indexNode.grpc.clientKeepAlivePermitWithoutStream")
+	Params.initClientKeepAlive()
+	assert.Equal(t, grpcconfigs.DefaultClientKeepAliveTime, Params.ClientKeepAliveTime)
+	assert.Equal(t, grpcconfigs.DefaultClientKeepAliveTimeout, Params.ClientKeepAliveTimeout)
+	assert.False(t, Params.ClientKeepAlivePermitWithoutStream)
+	assert.NotNil(t, keepaliveOption())
+
+	// 0 disables the keepalive, while the timeout of 0 is replaced by the default
+	Params.Save("indexNode.grpc.clientKeepAliveTime", "0")
+	Params.Save("indexNode.grpc.clientKeepAliveTimeout", "0")
+	Params.Save("indexNode.grpc.clientKeepAlivePermitWithoutStream", "true")
+	Params.initClientKeepAlive()
+	assert.Equal(t, time.Duration(0), Params.ClientKeepAliveTime)
+	assert.Equal(t, grpcconfigs.DefaultClientKeepAliveTimeout, Params.ClientKeepAliveTimeout)
+	assert.True(t, Params.ClientKeepAlivePermitWithoutStream)
+	assert.Nil(t, keepaliveOption())
+
+	Params.Save("indexNode.grpc.clientKeepAliveTime", "-1")
+	Params.Save("indexNode.grpc.clientKeepAliveTimeout", "10")
+	Params.initClientKeepAlive()
+	assert.Equal(t, grpcconfigs.DefaultClientKeepAliveTime, Params.ClientKeepAliveTime)
+	assert.Equal(t, 10*time.Second, Params.ClientKeepAliveTimeout)
+	Params.Remove("indexNode.grpc.clientKeepAliveTime")
+	Params.Remove("indexNode.grpc.clientKeepAliveTimeout")
+	Params.Remove("indexNode.grpc.clientKeepAlivePermitWithoutStream")
+	Params.initClientKeepAlive()
+
+	assert.Equal(t, grpcconfigs.CompressionNone, Params.ClientCompression)
+	assert.Equal(t, 2, len(callOptions()))
+	Params.Save("indexNode.grpc.compression", "Snappy")
+	Params.initClientCompression()
+	assert.Equal(t, grpcconfigs.CompressionSnappy, Params.ClientCompression)
+	assert.Equal(t, 3, len(callOptions()))
+	Params.Save("indexNode.grpc.compression", "zstd")
+	Params.initClientCompression()
+	assert.Equal(t, grpcconfigs.CompressionNone, Params.ClientCompression)
+	Params.Save("indexNode.grpc.compression", "none")
+	Params.initClientCompression()
+
+	assert.False(t, Params.ClientTLS.Enabled())
+	Params.Save("indexNode.grpc.clientCACert", " /etc/milvus/ca.crt ")
+	Params.Save("indexNode.grpc.clientServerName", "indexnode")
+	Params.initClientTLS()
+	assert.Equal(t, grpcconfigs.ClientTLS{CACert: "/etc/milvus/ca.crt", ServerName: "indexnode"}, Params.ClientTLS)
+	assert.True(t, Params.ClientTLS.Enabled())
+	Params.Remove("indexNode.grpc.clientCACert")
+	Params.Remove("indexNode.grpc.clientServerName")
+	Params.initClientTLS()
+
+	assert.Equal(t, tokenauth.Token(""), Params.AuthToken)
+	Params.Save("indexNode.auth.token", " secret ")
+	Params.Save("indexNode.auth.tokenFile", " /etc/milvus/token ")
+	Params.initAuth()
+	assert.Equal(t, tokenauth.Token("secret"), Params.AuthToken)
+	assert.Equal(t, "/etc/milvus/token", Params.AuthTokenFile)
+	Params.Save("indexNode.auth.token", "")
+	Params.Save("indexNode.auth.tokenFile", "")
+	Params.initAuth()
 }
@@ -0,0 +1,110 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcindexnode
+
+import (
+	"context"
+	"crypto/x509"
+	"path"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/milvus-io/milvus/internal/log"
+)
+
+// identityExemptMethods are the probes, which the clients verified by the CA call whatever their identities are.
+var identityExemptMethods = map[string]bool{
+	"GetComponentStates": true,
+	"Check":              true,
+	"Watch":              true,
+}
+
+// certIdentities returns the identities of @cert, which are the common name and the DNS, URI and email SANs.
+func certIdentities(cert *x509.Certificate) []string {
+	var identities []string
+	if cert.Subject.CommonName != "" {
+		identities = append(identities, cert.Subject.CommonName)
+	}
+	identities = append(identities, cert.DNSNames...)
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	identities = append(identities, cert.EmailAddresses...)
+	return identities
+}
+
+// identityVerifier rejects the calls of the clients none of whose identities matches the patterns.
+type identityVerifier struct {
+	// patterns are matched by path.Match, e.g. "indexcoord" or "*.indexcoord.milvus.svc"
+	patterns []string
+}
+
+func (v *identityVerifier) allowed(identities []string) bool {
+	for _, identity := range identities {
+		for _, pattern := range v.patterns {
+			if matched, _ := path.Match(pattern, identity); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verify returns PermissionDenied if the client of @ctx has no verified certificate, or none of its identities is
+// allowed.
+func (v *identityVerifier) verify(ctx context.Context, fullMethod string) error {
+	if identityExemptMethods[path.Base(fullMethod)] {
+		return nil
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.PermissionDenied, "no client certificate")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.PeerCertificates) == 0 {
+		return status.Error(codes.PermissionDenied, "no verified client certificate")
+	}
+	cert := tlsInfo.State.PeerCertificates[0]
+	identities := certIdentities(cert)
+	if v.allowed(identities) {
+		return nil
+	}
+	log.Warn("IndexNode rejects the call of the client identity not allowed", zap.String("method", fullMethod),
+		zap.Stringer("peer", p.Addr), zap.Strings("identities", identities))
+	return status.Errorf(codes.PermissionDenied, "client identity %q is not allowed", cert.Subject.CommonName)
+}
+
+// UnaryServerInterceptor returns the interceptor rejecting the unary calls of the clients not allowed.
+func (v *identityVerifier) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		if err := v.verify(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the interceptor rejecting the streams of the clients not allowed.
+func (v *identityVerifier) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := v.verify(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcindexnode
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+)
+
+func TestServer_AllowedClientCNs(t *testing.T) {
+	Params.Init()
+	oldPatterns := Params.AllowedClientCNs
+	defer func() {
+		Params.AllowedClientCNs = oldPatterns
+	}()
+	Params.AllowedClientCNs = []string{"indexcoord", "*.indexcoord.milvus.svc"}
+	ca, clientCA := newTestCA(t, "ca"), newTestCA(t, "client-ca")
+	certs, err := newCertReloader(writeServerCerts(t, t.TempDir(), ca, clientCA))
+	assert.Nil(t, err)
+	addr, stop := startTestServer(t, certs)
+	defer stop()
+
+	createIndex := func(conn *grpc.ClientConn) error {
+		_, err := indexpb.NewIndexNodeClient(conn).CreateIndex(context.Background(), &indexpb.CreateIndexRequest{})
+		return err
+	}
+	allowed := dialIndexNode(t, addr, grpc.WithTransportCredentials(clientCreds(t, ca, clientCA)))
+	defer allowed.Close()
+	assert.Nil(t, createIndex(allowed))
+	// the SANs are matched as well as the common name
+	san := dialIndexNode(t, addr, grpc.WithTransportCredentials(
+		clientCredsOf(t, ca, clientCA, "pod", "indexcoord-0.indexcoord.milvus.svc")))
+	defer san.Close()
+	assert.Nil(t, createIndex(san))
+
+	// the client verified by the CA but not allowed calls the probes only
+	other := dialIndexNode(t, addr, grpc.WithTransportCredentials(
+		clientCredsOf(t, ca, clientCA, "datanode", "datanode-0.datanode.milvus.svc")))
+	defer other.Close()
+	err = createIndex(other)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Contains(t, err.Error(), "datanode")
+	assert.Nil(t, getComponentStates(other))
+}
+
+func TestClient_AllowedClientCNs(t *testing.T) {
+	Params.Init()
+	oldPatterns := Params.AllowedClientCNs
+	defer func() {
+		Params.AllowedClientCNs = oldPatterns
+	}()
+	Params.AllowedClientCNs = []string{"indexcoord"}
+	ca, clientCA := newTestCA(t, "ca"), newTestCA(t, "client-ca")
+	dir := t.TempDir()
+	certs, err := newCertReloader(writeServerCerts(t, dir, ca, clientCA))
+	assert.Nil(t, err)
+	addr, stop := startTestServer(t, certs)
+	defer stop()
+
+	createIndex := func(name string) error {
+		client, clean := newTLSClient(t, addr, writeClientCerts(t, dir, ca, clientCA, name), 10*time.Second)
+		defer clean()
+		_, err := client.CreateIndex(context.Background(), &indexpb.CreateIndexRequest{})
+		return err
+	}
+	// the client presents the certificate configured, which is verified against the allowed names
+	assert.Nil(t, createIndex("indexcoord"))
+	err = createIndex("datanode")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Contains(t, err.Error(), "datanode")
+}
+
+func TestIdentityVerifier(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "indexcoord"},
+		DNSNames:       []string{"indexcoord.milvus.svc"},
+		URIs:           []*url.URL{{Scheme: "spiffe", Host: "cluster.local", Path: "/ns/milvus/sa/indexcoord"}},
+		EmailAddresses: []string{"indexcoord@milvus.io"},
+	}
+	identities := certIdentities(cert)
+	assert.Equal(t, []string{"indexcoord", "indexcoord.milvus.svc", "spiffe://cluster.local/ns/milvus/sa/indexcoord",
+		"indexcoord@milvus.io"}, identities)
+
+	for _, pattern := range []string{"indexcoord", "*.milvus.svc", "spiffe://cluster.local/ns/milvus/sa/*",
+		"*@milvus.io"} {
+		assert.True(t, (&identityVerifier{patterns: []string{pattern}}).allowed(identities), pattern)
+	}
+	for _, pattern := range []string{"datanode", "*.other.svc", "index*.milvus", "spiffe://*"} {
+		assert.False(t, (&identityVerifier{patterns: []string{pattern}}).allowed(identities), pattern)
+	}
+
+	// the calls without a verified certificate are rejected
+	v := &identityVerifier{patterns: []string{"*"}}
+	err := v.verify(context.Background(), "/milvus.proto.index.IndexNode/CreateIndex")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Nil(t, v.verify(context.Background(), "/milvus.proto.index.IndexNode/GetComponentStates"))
+}
+
+func TestLoadServerCerts_allowedClientCNs(t *testing.T) {
+	Params.Init()
+	oldCert, oldKey, oldCA, oldPatterns := Params.ServerCert, Params.ServerKey, Params.CACert, Params.AllowedClientCNs
+	defer func() {
+		Params.ServerCert, Params.ServerKey, Params.CACert = oldCert, oldKey, oldCA
+		Params.AllowedClientCNs = oldPatterns
+	}()
+	certFile, keyFile, caFile := writeServerCerts(t, t.TempDir(), newTestCA(t, "ca"), newTestCA(t, "client-ca"))
+	Params.AllowedClientCNs = []string{"indexcoord"}
+
+	// the identities of the clients are never verified without the CA
+	Params.ServerCert, Params.ServerKey, Params.CACert = certFile, keyFile, ""
+	_, err := loadServerCerts()
+	assert.NotNil(t, err)
+	Params.CACert = caFile
+	certs, err := loadServerCerts()
+	assert.Nil(t, err)
+	assert.NotNil(t, certs)
+}
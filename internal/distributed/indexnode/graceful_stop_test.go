@@ -0,0 +1,151 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcindexnode
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/milvus-io/milvus/internal/indexnode"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/milvuspb"
+)
+
+// blockingMock is the Mock blocking GetMetrics until released, and recording whether it's stopped meanwhile, and
+// whether the loop context of the server is cancelled before it's stopped.
+type blockingMock struct {
+	indexnode.Mock
+	called   chan struct{}
+	released chan struct{}
+	stopped  chan struct{}
+	loopCtx  context.Context
+	loopErr  error
+}
+
+func newBlockingMock() *blockingMock {
+	return &blockingMock{
+		called:   make(chan struct{}, 1),
+		released: make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+func (m *blockingMock) GetMetrics(ctx context.Context, req *milvuspb.GetMetricsRequest) (*milvuspb.GetMetricsResponse, error) {
+	m.called <- struct{}{}
+	select {
+	case <-m.released:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return m.Mock.GetMetrics(ctx, req)
+}
+
+func (m *blockingMock) Stop() error {
+	m.loopErr = m.loopCtx.Err()
+	close(m.stopped)
+	return nil
+}
+
+// startStoppableServer starts the grpc server of @node, which is stopped by Server.Stop.
+func startStoppableServer(t *testing.T, node *blockingMock) (*Server, *grpc.ClientConn) {
+	Params.Init()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		loopCtx:     ctx,
+		loopCancel:  cancel,
+		indexnode:   node,
+		grpcErrChan: make(chan error),
+	}
+	node.loopCtx = ctx
+	s.loopWg.Add(1)
+	go s.startGrpcLoop([]net.Listener{lis})
+	assert.Nil(t, <-s.grpcErrChan)
+	return s, dialIndexNode(t, lis.Addr().String(), grpc.WithInsecure())
+}
+
+func getMetrics(conn *grpc.ClientConn) (*milvuspb.GetMetricsResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return indexpb.NewIndexNodeClient(conn).GetMetrics(ctx, &milvuspb.GetMetricsRequest{})
+}
+
+func TestServer_GracefulStop(t *testing.T) {
+	node := newBlockingMock()
+	s, conn := startStoppableServer(t, node)
+	defer conn.Close()
+	called := make(chan error, 1)
+	go func() {
+		_, err := getMetrics(conn)
+		called <- err
+	}()
+	<-node.called
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- s.Stop()
+	}()
+	// the in-flight call is served still after IndexNode is stopped
+	select {
+	case <-node.stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("IndexNode is not stopped")
+	}
+	// IndexNode drains the tasks before the loop context is cancelled
+	assert.Nil(t, node.loopErr)
+	select {
+	case <-stopped:
+		t.Fatal("the grpc server stops before the in-flight call finishes")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(node.released)
+	assert.Nil(t, <-called)
+	assert.Nil(t, <-stopped)
+
+	// the new calls are rejected once stopped
+	_, err := getMetrics(conn)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestServer_GracefulStopTimeout(t *testing.T) {
+	Params.Init()
+	oldTimeout := Params.GracefulStopTimeout
+	Params.GracefulStopTimeout = 100 * time.Millisecond
+	defer func() {
+		Params.GracefulStopTimeout = oldTimeout
+	}()
+	node := newBlockingMock()
+	s, conn := startStoppableServer(t, node)
+	defer conn.Close()
+
+	called := make(chan error, 1)
+	go func() {
+		_, err := getMetrics(conn)
+		called <- err
+	}()
+	<-node.called
+
+	// the connections are closed once the call is not finished in time
+	start := time.Now()
+	assert.Nil(t, s.Stop())
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	// the client sees the connection closed after the GOAWAY
+	assert.NotNil(t, <-called)
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcindexnode
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/milvus-io/milvus/internal/indexnode"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+)
+
+// indexNodeServiceName is the name of the grpc service of IndexNode, which the health service reports the status of
+// as well as the overall one of the empty name.
+const indexNodeServiceName = "milvus.proto.index.IndexNode"
+
+// healthWatchInterval is the interval of checking the readiness for the watchers of the health service.
+var healthWatchInterval = time.Second
+
+// readinessReporter is implemented by the IndexNode which reports the readiness served by the http probes.
+type readinessReporter interface {
+	Readiness() *indexnode.ProbeResult
+}
+
+// healthServer serves grpc.health.v1, the status of which is SERVING if IndexNode is ready.
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	s *Server
+}
+
+// ready returns whether IndexNode is ready, by the readiness of the http probes if it's reported, or by the state
+// code otherwise.
+func (h *healthServer) ready(ctx context.Context) bool {
+	if reporter, ok := h.s.indexnode.(readinessReporter); ok {
+		return reporter.Readiness().Passed
+	}
+	states, err := h.s.indexnode.GetComponentStates(ctx)
+	return err == nil && states.GetState().GetStateCode() == internalpb.StateCode_Healthy
+}
+
+func (h *healthServer) status(ctx context.Context, service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if service != "" && service != indexNodeServiceName {
+		return grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN
+	}
+	if h.ready(ctx) {
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+	return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+}
+
+// Check returns the status of the service, or NotFound if the service is unknown.
+func (h *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	servingStatus := h.status(ctx, req.Service)
+	if servingStatus == grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN {
+		return nil, status.Errorf(codes.NotFound, "unknown service %s", req.Service)
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: servingStatus}, nil
+}
+
+// Watch sends the status of the service at once and then whenever it changes, until the watcher leaves or the
+// server stops.
+func (h *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	ticker := time.NewTicker(healthWatchInterval)
+	defer ticker.Stop()
+	last := grpc_health_v1.HealthCheckResponse_ServingStatus(-1)
+	for {
+		servingStatus := h.status(stream.Context(), req.Service)
+		if servingStatus != last {
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: servingStatus}); err != nil {
+				return err
+			}
+			last = servingStatus
+		}
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-h.s.loopCtx.Done():
+			// the graceful stop waits for the streams, which end once the server stops
+			return status.Error(codes.Unavailable, "the server is stopping")
+		case <-ticker.C:
+		}
+	}
+}
+
+// registerServices registers the grpc service of IndexNode to @server, and the health and the reflection services
+// if they are enabled.
+func (s *Server) registerServices(server *grpc.Server) {
+	indexpb.RegisterIndexNodeServer(server, s)
+	if Params.HealthEnabled {
+		grpc_health_v1.RegisterHealthServer(server, &healthServer{s: s})
+	}
+	if Params.ReflectionEnabled {
+		reflection.Register(server)
+	}
+}
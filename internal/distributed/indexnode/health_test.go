@@ -0,0 +1,160 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcindexnode
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+
+	"github.com/milvus-io/milvus/internal/indexnode"
+)
+
+// readinessMock is the Mock reporting the readiness of ready.
+type readinessMock struct {
+	indexnode.Mock
+	ready int32
+}
+
+func (m *readinessMock) Readiness() *indexnode.ProbeResult {
+	return &indexnode.ProbeResult{Passed: atomic.LoadInt32(&m.ready) == 1}
+}
+
+// startServicesServer starts the grpc server of @node with the health and the reflection services enabled or not.
+func startServicesServer(t *testing.T, node *readinessMock, enabled bool) (*grpc.ClientConn, func()) {
+	Params.Init()
+	oldHealth, oldReflection := Params.HealthEnabled, Params.ReflectionEnabled
+	Params.HealthEnabled, Params.ReflectionEnabled = enabled, enabled
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, stop := startServer(t, &Server{loopCtx: ctx, loopCancel: cancel, indexnode: node})
+	Params.HealthEnabled, Params.ReflectionEnabled = oldHealth, oldReflection
+	conn := dialIndexNode(t, addr, grpc.WithInsecure())
+	return conn, func() {
+		conn.Close()
+		cancel()
+		stop()
+	}
+}
+
+func TestServer_Health(t *testing.T) {
+	oldInterval := healthWatchInterval
+	healthWatchInterval = 10 * time.Millisecond
+	defer func() {
+		healthWatchInterval = oldInterval
+	}()
+	node := &readinessMock{ready: 1}
+	conn, stop := startServicesServer(t, node, true)
+	defer stop()
+	client := grpc_health_v1.NewHealthClient(conn)
+	check := func(service string) (grpc_health_v1.HealthCheckResponse_ServingStatus, error) {
+		resp, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: service})
+		return resp.GetStatus(), err
+	}
+
+	for _, service := range []string{"", indexNodeServiceName} {
+		servingStatus, err := check(service)
+		assert.Nil(t, err)
+		assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, servingStatus)
+	}
+	_, err := check("unknown")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	watch, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	assert.Nil(t, err)
+	resp, err := watch.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+
+	// the status follows the readiness
+	atomic.StoreInt32(&node.ready, 0)
+	servingStatus, err := check("")
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, servingStatus)
+	resp, err = watch.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, resp.Status)
+	atomic.StoreInt32(&node.ready, 1)
+	resp, err = watch.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+
+	unknown, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "unknown"})
+	assert.Nil(t, err)
+	resp, err = unknown.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN, resp.Status)
+}
+
+func TestServer_Reflection(t *testing.T) {
+	conn, stop := startServicesServer(t, &readinessMock{ready: 1}, true)
+	defer stop()
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	assert.Nil(t, err)
+
+	assert.Nil(t, stream.Send(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	}))
+	resp, err := stream.Recv()
+	assert.Nil(t, err)
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.Name)
+	}
+	assert.Contains(t, services, indexNodeServiceName)
+	assert.Contains(t, services, "grpc.health.v1.Health")
+
+	// the descriptors of IndexNode are resolved, e.g. by grpcurl describing the methods
+	assert.Nil(t, stream.Send(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: indexNodeServiceName},
+	}))
+	resp, err = stream.Recv()
+	assert.Nil(t, err)
+	assert.Nil(t, resp.GetErrorResponse())
+	assert.NotEmpty(t, resp.GetFileDescriptorResponse().GetFileDescriptorProto())
+	assert.Nil(t, stream.CloseSend())
+}
+
+func TestServer_ServicesDisabled(t *testing.T) {
+	conn, stop := startServicesServer(t, &readinessMock{ready: 1}, false)
+	defer stop()
+	_, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	assert.Nil(t, err)
+	assert.Nil(t, stream.Send(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	}))
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// IndexNode is served still
+	assert.Nil(t, getComponentStates(conn))
+}
+
+func TestHealthServer_stateCode(t *testing.T) {
+	// the readiness falls back to the state code without the readiness reported
+	h := &healthServer{s: &Server{indexnode: &indexnode.Mock{}}}
+	assert.True(t, h.ready(context.Background()))
+	h.s.indexnode = &indexnode.Mock{Err: true}
+	assert.False(t, h.ready(context.Background()))
+}
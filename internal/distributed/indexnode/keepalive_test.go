@@ -0,0 +1,196 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcindexnode
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	grpcindexnodeclient "github.com/milvus-io/milvus/internal/distributed/indexnode/client"
+)
+
+// proxyFlow is a connection forwarded by blackholeProxy.
+type proxyFlow struct {
+	client  net.Conn
+	server  net.Conn
+	dropped int32
+	// serverClosed is closed once the server closes the connection
+	serverClosed chan struct{}
+}
+
+// blackholeProxy forwards the connections to the target. Once blackhole is called, the established connections
+// drop the bytes silently like a firewall dropping the idle flows, while the new connections are forwarded.
+type blackholeProxy struct {
+	lis    net.Listener
+	target string
+
+	mu    sync.Mutex
+	flows []*proxyFlow
+}
+
+func newBlackholeProxy(t *testing.T, target string) *blackholeProxy {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	p := &blackholeProxy{lis: lis, target: target}
+	go p.serve()
+	return p
+}
+
+func (p *blackholeProxy) addr() string {
+	return p.lis.Addr().String()
+}
+
+func (p *blackholeProxy) serve() {
+	for {
+		client, err := p.lis.Accept()
+		if err != nil {
+			return
+		}
+		server, err := net.Dial("tcp", p.target)
+		if err != nil {
+			_ = client.Close()
+			continue
+		}
+		flow := &proxyFlow{client: client, server: server, serverClosed: make(chan struct{})}
+		p.mu.Lock()
+		p.flows = append(p.flows, flow)
+		p.mu.Unlock()
+		go flow.pipe(server, client, nil)
+		go flow.pipe(client, server, flow.serverClosed)
+	}
+}
+
+// pipe forwards the bytes from @src to @dst, and closes @closed once @src is closed.
+func (f *proxyFlow) pipe(dst net.Conn, src net.Conn, closed chan struct{}) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if err != nil {
+			if closed != nil {
+				close(closed)
+			}
+			// the closing of the blackholed connection never reaches the other side either
+			if atomic.LoadInt32(&f.dropped) == 0 {
+				_ = dst.Close()
+			}
+			return
+		}
+		if atomic.LoadInt32(&f.dropped) == 1 {
+			continue
+		}
+		if _, err := dst.Write(buf[:n]); err != nil {
+			return
+		}
+	}
+}
+
+// blackhole drops the bytes of the established connections.
+func (p *blackholeProxy) blackhole() []*proxyFlow {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, flow := range p.flows {
+		atomic.StoreInt32(&flow.dropped, 1)
+	}
+	return p.flows
+}
+
+func (p *blackholeProxy) close() {
+	_ = p.lis.Close()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, flow := range p.flows {
+		_ = flow.client.Close()
+		_ = flow.server.Close()
+	}
+}
+
+func TestServer_KeepAlive(t *testing.T) {
+	Params.Init()
+	oldTime, oldTimeout := Params.ServerKeepAliveTime, Params.ServerKeepAliveTimeout
+	defer func() {
+		Params.ServerKeepAliveTime, Params.ServerKeepAliveTimeout = oldTime, oldTimeout
+	}()
+	Params.ServerKeepAliveTime, Params.ServerKeepAliveTimeout = 200*time.Millisecond, 200*time.Millisecond
+	addr, stop := startTestServer(t, nil)
+	defer stop()
+	proxy := newBlackholeProxy(t, addr)
+	defer proxy.close()
+
+	conn := dialIndexNode(t, proxy.addr(), grpc.WithInsecure())
+	defer conn.Close()
+	assert.Nil(t, getComponentStates(conn))
+	flows := proxy.blackhole()
+	assert.Equal(t, 1, len(flows))
+
+	// the server closes the connection once a ping is not acked, within the time and the timeout of the keepalive
+	select {
+	case <-flows[0].serverClosed:
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "the server never detects the broken connection")
+	}
+}
+
+func TestClient_KeepAlive(t *testing.T) {
+	Params.Init()
+	grpcindexnodeclient.Params.Init()
+	oldTime, oldTimeout, oldPermit := grpcindexnodeclient.Params.ClientKeepAliveTime,
+		grpcindexnodeclient.Params.ClientKeepAliveTimeout, grpcindexnodeclient.Params.ClientKeepAlivePermitWithoutStream
+	oldServerTime := Params.ServerKeepAliveTime
+	defer func() {
+		grpcindexnodeclient.Params.ClientKeepAliveTime, grpcindexnodeclient.Params.ClientKeepAliveTimeout,
+			grpcindexnodeclient.Params.ClientKeepAlivePermitWithoutStream = oldTime, oldTimeout, oldPermit
+		Params.ServerKeepAliveTime = oldServerTime
+	}()
+	// the server never pings within the test
+	Params.ServerKeepAliveTime = time.Hour
+	addr, stop := startTestServer(t, nil)
+	defer stop()
+	proxy := newBlackholeProxy(t, addr)
+	defer proxy.close()
+
+	connect := func() *grpcindexnodeclient.Client {
+		client, err := grpcindexnodeclient.NewClient(context.Background(), proxy.addr())
+		assert.Nil(t, err)
+		_, err = client.GetComponentStates(context.Background())
+		assert.Nil(t, err)
+		return client
+	}
+	getComponentStates := func(client *grpcindexnodeclient.Client) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_, err := client.GetComponentStates(ctx)
+		return err
+	}
+	grpcindexnodeclient.Params.ClientKeepAliveTime = 0
+	withoutKeepAlive := connect()
+	defer withoutKeepAlive.Stop()
+	// 10s is the shortest time of the keepalive of the grpc clients
+	grpcindexnodeclient.Params.ClientKeepAliveTime = 10 * time.Second
+	grpcindexnodeclient.Params.ClientKeepAliveTimeout = time.Second
+	grpcindexnodeclient.Params.ClientKeepAlivePermitWithoutStream = true
+	withKeepAlive := connect()
+	defer withKeepAlive.Stop()
+
+	assert.Equal(t, 2, len(proxy.blackhole()))
+	// the client with the keepalive finds the idle connection broken within the time and the timeout, and connects
+	// again, while the one without hangs on the broken connection
+	time.Sleep(12 * time.Second)
+	assert.Nil(t, getComponentStates(withKeepAlive))
+	assert.NotNil(t, getComponentStates(withoutKeepAlive))
+}
@@ -12,17 +12,27 @@
 package grpcindexnode
 
 import (
+	"os"
+	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/milvus-io/milvus/internal/distributed/grpcconfigs"
 	"github.com/milvus-io/milvus/internal/log"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"github.com/milvus-io/milvus/internal/util/funcutil"
 	"github.com/milvus-io/milvus/internal/util/paramtable"
+	"github.com/milvus-io/milvus/internal/util/tokenauth"
 )
 
+// defaultGracefulStopTimeout is long enough for the coordinator to finish querying the states of the tasks.
+const defaultGracefulStopTimeout = 30 * time.Second
+
 // ParamTable is used to record configuration items.
 type ParamTable struct {
 	paramtable.BaseTable
@@ -33,8 +43,60 @@ type ParamTable struct {
 	Port    int
 	Address string
 
+	// ListenTCP listens on Port, and UnixSocket is the path of the unix socket listened on if it's not empty, at
+	// least one of them is listened on. The unix socket address is advertised if the TCP one is not listened on.
+	ListenTCP  bool
+	UnixSocket string
+	// UnixSocketMode is the permission bits of UnixSocket, which limit the users able to connect.
+	UnixSocketMode os.FileMode
+
 	ServerMaxSendSize int
 	ServerMaxRecvSize int
+
+	// ServerKeepAliveTime and ServerKeepAliveTimeout are the idle time after which the server pings the client, and
+	// the time waiting for the ack before closing the connection.
+	ServerKeepAliveTime    time.Duration
+	ServerKeepAliveTimeout time.Duration
+	// ServerKeepAliveMinTime is the shortest interval of the pings of the clients tolerated, and the clients pinging
+	// without the in-flight rpcs are tolerated if ServerKeepAlivePermitWithoutStream.
+	ServerKeepAliveMinTime             time.Duration
+	ServerKeepAlivePermitWithoutStream bool
+
+	// GracefulStopTimeout is the longest time waiting for the in-flight calls on stopping, after which the
+	// connections are closed.
+	GracefulStopTimeout time.Duration
+
+	// HealthEnabled serves grpc.health.v1 driven by the readiness of IndexNode, and ReflectionEnabled serves the grpc
+	// reflection for the debugging tools like grpcurl.
+	HealthEnabled     bool
+	ReflectionEnabled bool
+
+	// HTTPPort is the port of the http listener serving probes, 0 means disabled.
+	HTTPPort int
+	// MetricsPort is the port of the http listener serving the prometheus metrics, 0 means disabled.
+	MetricsPort int
+
+	AccessLogEnabled bool
+	// AccessLogLevel is the level of the access log of the succeeded requests.
+	AccessLogLevel zapcore.Level
+	// AccessLogSampleRates logs 1 of every N succeeded calls of the methods.
+	AccessLogSampleRates map[string]int
+
+	// ServerCert and ServerKey are the files of the certificate the grpc server serves TLS with, the server is
+	// plaintext if they are empty.
+	ServerCert string
+	ServerKey  string
+	// CACert is the file of the CA verifying the certificates of the clients, which are not required if it's empty.
+	CACert string
+	// AllowedClientCNs are the patterns of path.Match, the calls of the clients none of whose common name and SANs
+	// matches them are rejected, if they are not empty. It requires CACert to verify the certificates of the clients.
+	AllowedClientCNs []string
+
+	// AuthToken is the token required by the calls, or AuthTokenFile is the file of it, the calls are not
+	// authenticated if both are empty. The calls of AuthExemptMethods, e.g. the probes, are never authenticated.
+	AuthToken         tokenauth.Token
+	AuthTokenFile     string
+	AuthExemptMethods []string
 }
 
 // Params is an alias for ParamTable.
@@ -49,6 +111,9 @@ func (pt *ParamTable) Init() {
 
 		pt.initServerMaxSendSize()
 		pt.initServerMaxRecvSize()
+		pt.initServerKeepAlive()
+		pt.initGracefulStopTimeout()
+		pt.initServices()
 
 		if !funcutil.CheckPortAvailable(pt.Port) {
 			pt.Port = funcutil.GetAvailablePort()
@@ -67,11 +132,27 @@ func (pt *ParamTable) LoadFromArgs() {
 // LoadFromEnv is used to initialize configuration items from env.
 func (pt *ParamTable) LoadFromEnv() {
 	Params.IP = funcutil.GetLocalIP()
+	// INDEX_NODE_PORT overrides indexNode.port, the instances spawned on the same host take 0 for ephemeral ports
+	if portStr := os.Getenv("INDEX_NODE_PORT"); portStr != "" {
+		port, err := strconv.Atoi(portStr)
+		if err != nil || port < 0 {
+			log.Warn("Failed to parse INDEX_NODE_PORT, use indexNode.port",
+				zap.String("INDEX_NODE_PORT", portStr), zap.Error(err))
+			return
+		}
+		pt.Port = port
+	}
 }
 
 func (pt *ParamTable) initParams() {
 	pt.initPort()
+	pt.initListen()
 	pt.initIndexCoordAddress()
+	pt.initHTTPPort()
+	pt.initMetricsPort()
+	pt.initAccessLog()
+	pt.initTLS()
+	pt.initAuth()
 }
 
 // todo remove and use load from env
@@ -88,48 +169,229 @@ func (pt *ParamTable) initPort() {
 	pt.Port = port
 }
 
-func (pt *ParamTable) initServerMaxSendSize() {
-	var err error
+func (pt *ParamTable) initListen() {
+	pt.ListenTCP = pt.ParseBool("indexNode.listen.tcp", true)
+	socket, err := pt.LoadWithDefault("indexNode.listen.unixSocket", "")
+	if err != nil {
+		panic(err)
+	}
+	pt.UnixSocket = strings.TrimSpace(socket)
+	if pt.UnixSocket != "" {
+		if pt.UnixSocket, err = filepath.Abs(pt.UnixSocket); err != nil {
+			panic(err)
+		}
+	}
+	if !pt.ListenTCP && pt.UnixSocket == "" {
+		log.Warn("IndexNode listens on TCP since indexNode.listen.unixSocket is not set")
+		pt.ListenTCP = true
+	}
 
-	valueStr, err := pt.Load("indexNode.grpc.serverMaxSendSize")
-	if err != nil { // not set
-		pt.ServerMaxSendSize = grpcconfigs.DefaultServerMaxSendSize
+	modeStr, err := pt.LoadWithDefault("indexNode.listen.unixSocketMode", strconv.FormatUint(uint64(defaultUnixSocketMode), 8))
+	if err != nil {
+		panic(err)
+	}
+	mode, err := strconv.ParseUint(strings.TrimSpace(modeStr), 8, 32)
+	if err != nil || mode > uint64(os.ModePerm) {
+		log.Warn("Failed to parse indexNode.listen.unixSocketMode, set to default",
+			zap.String("indexNode.listen.unixSocketMode", modeStr),
+			zap.Error(err))
+		mode = uint64(defaultUnixSocketMode)
 	}
+	pt.UnixSocketMode = os.FileMode(mode)
+}
 
-	value, err := strconv.Atoi(valueStr)
-	if err != nil { // not in valid format
-		log.Warn("Failed to parse indexNode.grpc.serverMaxSendSize, set to default",
-			zap.String("indexNode.grpc.serverMaxSendSize", valueStr),
+func (pt *ParamTable) initHTTPPort() {
+	valueStr, err := pt.LoadWithDefault("indexNode.http.port", "0")
+	if err != nil {
+		panic(err)
+	}
+	port, err := strconv.Atoi(valueStr)
+	if err != nil || port < 0 {
+		log.Warn("Failed to parse indexNode.http.port, the http listener is disabled",
+			zap.String("indexNode.http.port", valueStr),
 			zap.Error(err))
+		port = 0
+	}
+	pt.HTTPPort = port
+}
 
-		pt.ServerMaxSendSize = grpcconfigs.DefaultServerMaxSendSize
-	} else {
-		pt.ServerMaxSendSize = value
+func (pt *ParamTable) initMetricsPort() {
+	valueStr, err := pt.LoadWithDefault("indexNode.metrics.port", "0")
+	if err != nil {
+		panic(err)
 	}
+	port, err := strconv.Atoi(valueStr)
+	if err != nil || port < 0 {
+		log.Warn("Failed to parse indexNode.metrics.port, the metrics listener is disabled",
+			zap.String("indexNode.metrics.port", valueStr),
+			zap.Error(err))
+		port = 0
+	}
+	pt.MetricsPort = port
+}
 
-	log.Debug("initServerMaxSendSize",
-		zap.Int("indexNode.grpc.serverMaxSendSize", pt.ServerMaxSendSize))
+func (pt *ParamTable) initAccessLog() {
+	pt.AccessLogEnabled = pt.ParseBool("indexNode.accessLog.enabled", true)
+
+	levelStr, err := pt.LoadWithDefault("indexNode.accessLog.level", "info")
+	if err != nil {
+		panic(err)
+	}
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(levelStr)); err != nil {
+		log.Warn("Failed to parse indexNode.accessLog.level, set to info",
+			zap.String("indexNode.accessLog.level", levelStr),
+			zap.Error(err))
+		level = zapcore.InfoLevel
+	}
+	pt.AccessLogLevel = level
+
+	// in the form of "method:N,..."
+	ratesStr, err := pt.LoadWithDefault("indexNode.accessLog.sampleRates", "")
+	if err != nil {
+		panic(err)
+	}
+	pt.AccessLogSampleRates = make(map[string]int)
+	for _, item := range strings.Split(ratesStr, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pair := strings.Split(item, ":")
+		if len(pair) != 2 {
+			log.Warn("Failed to parse indexNode.accessLog.sampleRates, ignore the item", zap.String("item", item))
+			continue
+		}
+		rate, err := strconv.Atoi(strings.TrimSpace(pair[1]))
+		if err != nil || rate <= 0 {
+			log.Warn("Failed to parse indexNode.accessLog.sampleRates, ignore the item", zap.String("item", item))
+			continue
+		}
+		pt.AccessLogSampleRates[strings.TrimSpace(pair[0])] = rate
+	}
 }
 
-func (pt *ParamTable) initServerMaxRecvSize() {
+func (pt *ParamTable) initTLS() {
 	var err error
+	for key, value := range map[string]*string{
+		"indexNode.grpc.serverCert": &pt.ServerCert,
+		"indexNode.grpc.serverKey":  &pt.ServerKey,
+		"indexNode.grpc.caCert":     &pt.CACert,
+	} {
+		*value, err = pt.LoadWithDefault(key, "")
+		if err != nil {
+			panic(err)
+		}
+		*value = strings.TrimSpace(*value)
+	}
 
-	valueStr, err := pt.Load("indexNode.grpc.serverMaxRecvSize")
-	if err != nil { // not set
-		pt.ServerMaxRecvSize = grpcconfigs.DefaultServerMaxRecvSize
+	patterns, err := pt.LoadWithDefault("indexNode.grpc.allowedClientCNs", "")
+	if err != nil {
+		panic(err)
 	}
+	pt.AllowedClientCNs = nil
+	for _, pattern := range strings.Split(patterns, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			log.Warn("Failed to parse indexNode.grpc.allowedClientCNs, ignore the pattern",
+				zap.String("pattern", pattern), zap.Error(err))
+			continue
+		}
+		pt.AllowedClientCNs = append(pt.AllowedClientCNs, pattern)
+	}
+}
 
-	value, err := strconv.Atoi(valueStr)
-	if err != nil { // not in valid format
-		log.Warn("Failed to parse indexNode.grpc.serverMaxRecvSize, set to default",
-			zap.String("indexNode.grpc.serverMaxRecvSize", valueStr),
-			zap.Error(err))
+func (pt *ParamTable) initAuth() {
+	token, err := pt.LoadWithDefault("indexNode.auth.token", "")
+	if err != nil {
+		panic(err)
+	}
+	pt.AuthToken = tokenauth.Token(strings.TrimSpace(token))
+	pt.AuthTokenFile, err = pt.LoadWithDefault("indexNode.auth.tokenFile", "")
+	if err != nil {
+		panic(err)
+	}
+	pt.AuthTokenFile = strings.TrimSpace(pt.AuthTokenFile)
 
-		pt.ServerMaxRecvSize = grpcconfigs.DefaultServerMaxRecvSize
-	} else {
-		pt.ServerMaxRecvSize = value
+	methods, err := pt.LoadWithDefault("indexNode.auth.exemptMethods", "")
+	if err != nil {
+		panic(err)
 	}
+	pt.AuthExemptMethods = nil
+	for _, method := range strings.Split(methods, ",") {
+		if method = strings.TrimSpace(method); method != "" {
+			pt.AuthExemptMethods = append(pt.AuthExemptMethods, method)
+		}
+	}
+	log.Debug("initAuth", zap.Stringer("indexNode.auth.token", pt.AuthToken),
+		zap.String("indexNode.auth.tokenFile", pt.AuthTokenFile),
+		zap.Strings("indexNode.auth.exemptMethods", pt.AuthExemptMethods))
+}
 
+func (pt *ParamTable) initServerMaxSendSize() {
+	pt.ServerMaxSendSize = pt.parseMsgSize("indexNode.grpc.serverMaxSendSize", grpcconfigs.DefaultServerMaxSendSize)
+	log.Debug("initServerMaxSendSize",
+		zap.Int("indexNode.grpc.serverMaxSendSize", pt.ServerMaxSendSize))
+}
+
+func (pt *ParamTable) initServerMaxRecvSize() {
+	pt.ServerMaxRecvSize = pt.parseMsgSize("indexNode.grpc.serverMaxRecvSize", grpcconfigs.DefaultServerMaxRecvSize)
 	log.Debug("initServerMaxRecvSize",
 		zap.Int("indexNode.grpc.serverMaxRecvSize", pt.ServerMaxRecvSize))
 }
+
+func (pt *ParamTable) initServerKeepAlive() {
+	pt.ServerKeepAliveTime = pt.parseSeconds("indexNode.grpc.serverKeepAliveTime", grpcconfigs.DefaultServerKeepAliveTime)
+	pt.ServerKeepAliveTimeout = pt.parseSeconds("indexNode.grpc.serverKeepAliveTimeout",
+		grpcconfigs.DefaultServerKeepAliveTimeout)
+	pt.ServerKeepAliveMinTime = pt.parseSeconds("indexNode.grpc.serverKeepAliveMinTime",
+		grpcconfigs.DefaultServerKeepAliveMinTime)
+	pt.ServerKeepAlivePermitWithoutStream = pt.ParseBool("indexNode.grpc.serverKeepAlivePermitWithoutStream", true)
+}
+
+func (pt *ParamTable) initGracefulStopTimeout() {
+	pt.GracefulStopTimeout = pt.parseSeconds("indexNode.grpc.gracefulStopTimeout", defaultGracefulStopTimeout)
+}
+
+func (pt *ParamTable) initServices() {
+	pt.HealthEnabled = pt.ParseBool("indexNode.grpc.health.enabled", true)
+	pt.ReflectionEnabled = pt.ParseBool("indexNode.grpc.reflection.enabled", false)
+}
+
+// parseSeconds parses the positive duration in seconds of @key, the invalid value is replaced by @defaultValue.
+func (pt *ParamTable) parseSeconds(key string, defaultValue time.Duration) time.Duration {
+	defaultSeconds := int64(defaultValue / time.Second)
+	valueStr, err := pt.LoadWithDefault(key, strconv.FormatInt(defaultSeconds, 10))
+	if err != nil {
+		panic(err)
+	}
+	seconds, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil || seconds <= 0 {
+		log.Warn("Failed to parse "+key+", set to default",
+			zap.String(key, valueStr),
+			zap.Int64("default", defaultSeconds),
+			zap.Error(err))
+		return defaultValue
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// parseMsgSize parses the size of the grpc messages of @key, optionally with the suffix KB, MB or GB, the invalid
+// value or the one out of the bounds is replaced by @defaultValue.
+func (pt *ParamTable) parseMsgSize(key string, defaultValue int) int {
+	valueStr, err := pt.LoadWithDefault(key, strconv.Itoa(defaultValue))
+	if err != nil {
+		panic(err)
+	}
+	size, err := grpcconfigs.ParseMsgSize(valueStr)
+	if err != nil {
+		log.Warn("Failed to parse "+key+", set to default",
+			zap.String(key, valueStr),
+			zap.Int("default", defaultValue),
+			zap.Error(err))
+		return defaultValue
+	}
+	return size
+}
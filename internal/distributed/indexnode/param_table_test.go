@@ -12,12 +12,17 @@
 package grpcindexnode
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/milvus-io/milvus/internal/distributed/grpcconfigs"
 	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/util/tokenauth"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 func TestParamTable(t *testing.T) {
@@ -33,4 +38,150 @@ func TestParamTable(t *testing.T) {
 	Params.Remove("indexNode.grpc.ServerMaxRecvSize")
 	Params.initServerMaxRecvSize()
 	assert.Equal(t, Params.ServerMaxRecvSize, grpcconfigs.DefaultServerMaxRecvSize)
+
+	Params.Save("indexNode.grpc.serverMaxRecvSize", "512MB")
+	Params.Save("indexNode.grpc.serverMaxSendSize", "1KB")
+	Params.initServerMaxRecvSize()
+	Params.initServerMaxSendSize()
+	assert.Equal(t, 512*1024*1024, Params.ServerMaxRecvSize)
+	assert.Equal(t, grpcconfigs.DefaultServerMaxSendSize, Params.ServerMaxSendSize)
+	Params.Save("indexNode.grpc.serverMaxRecvSize", "2147483647")
+	Params.Save("indexNode.grpc.serverMaxSendSize", "2147483647")
+	Params.initServerMaxRecvSize()
+	Params.initServerMaxSendSize()
+
+	assert.Equal(t, 0, Params.MetricsPort)
+	Params.Save("indexNode.metrics.port", "-1")
+	Params.initMetricsPort()
+	assert.Equal(t, 0, Params.MetricsPort)
+	Params.Save("indexNode.metrics.port", "9092")
+	Params.initMetricsPort()
+	assert.Equal(t, 9092, Params.MetricsPort)
+	Params.Save("indexNode.metrics.port", "0")
+	Params.initMetricsPort()
+
+	assert.True(t, Params.AccessLogEnabled)
+	assert.Equal(t, zapcore.InfoLevel, Params.AccessLogLevel)
+	assert.Equal(t, 100, Params.AccessLogSampleRates["GetComponentStates"])
+	Params.Save("indexNode.accessLog.enabled", "false")
+	Params.Save("indexNode.accessLog.level", "verbose")
+	Params.Save("indexNode.accessLog.sampleRates", "CreateIndex:2, GetMetrics:0,GetComponentStates,:")
+	Params.initAccessLog()
+	assert.False(t, Params.AccessLogEnabled)
+	assert.Equal(t, zapcore.InfoLevel, Params.AccessLogLevel)
+	assert.Equal(t, map[string]int{"CreateIndex": 2}, Params.AccessLogSampleRates)
+	Params.Save("indexNode.accessLog.level", "debug")
+	Params.initAccessLog()
+	assert.Equal(t, zapcore.DebugLevel, Params.AccessLogLevel)
+	Params.Save("indexNode.accessLog.enabled", "true")
+	Params.Save("indexNode.accessLog.level", "info")
+	Params.Save("indexNode.accessLog.sampleRates", "GetComponentStates:100,GetTimeTickChannel:100,GetStatisticsChannel:100")
+	Params.initAccessLog()
+
+	assert.Equal(t, "", Params.ServerCert)
+	assert.Equal(t, "", Params.CACert)
+	Params.Save("indexNode.grpc.serverCert", " /etc/milvus/tls/indexnode.crt ")
+	Params.Save("indexNode.grpc.serverKey", "/etc/milvus/tls/indexnode.key")
+	Params.Save("indexNode.grpc.caCert", "/etc/milvus/tls/ca.crt")
+	Params.initTLS()
+	assert.Equal(t, "/etc/milvus/tls/indexnode.crt", Params.ServerCert)
+	assert.Equal(t, "/etc/milvus/tls/indexnode.key", Params.ServerKey)
+	assert.Equal(t, "/etc/milvus/tls/ca.crt", Params.CACert)
+	for _, key := range []string{"indexNode.grpc.serverCert", "indexNode.grpc.serverKey", "indexNode.grpc.caCert"} {
+		Params.Save(key, "")
+	}
+	Params.initTLS()
+
+	assert.Equal(t, grpcconfigs.DefaultServerKeepAliveTime, Params.ServerKeepAliveTime)
+	assert.Equal(t, grpcconfigs.DefaultServerKeepAliveTimeout, Params.ServerKeepAliveTimeout)
+	assert.Equal(t, grpcconfigs.DefaultServerKeepAliveMinTime, Params.ServerKeepAliveMinTime)
+	assert.True(t, Params.ServerKeepAlivePermitWithoutStream)
+	Params.Save("indexNode.grpc.serverKeepAliveTime", "30")
+	Params.Save("indexNode.grpc.serverKeepAliveTimeout", "0")
+	Params.Save("indexNode.grpc.serverKeepAliveMinTime", "5s")
+	Params.Save("indexNode.grpc.serverKeepAlivePermitWithoutStream", "false")
+	Params.initServerKeepAlive()
+
+	assert.Equal(t, defaultGracefulStopTimeout, Params.GracefulStopTimeout)
+	Params.Save("indexNode.grpc.gracefulStopTimeout", "5")
+	Params.initGracefulStopTimeout()
+	assert.Equal(t, 5*time.Second, Params.GracefulStopTimeout)
+	Params.Save("indexNode.grpc.gracefulStopTimeout", "0")
+	Params.initGracefulStopTimeout()
+	assert.Equal(t, defaultGracefulStopTimeout, Params.GracefulStopTimeout)
+	Params.Remove("indexNode.grpc.gracefulStopTimeout")
+	Params.initGracefulStopTimeout()
+
+	assert.Nil(t, Params.AllowedClientCNs)
+	Params.Save("indexNode.grpc.allowedClientCNs", " indexcoord, *.indexcoord.milvus.svc,[invalid,")
+	Params.initTLS()
+	assert.Equal(t, []string{"indexcoord", "*.indexcoord.milvus.svc"}, Params.AllowedClientCNs)
+	Params.Remove("indexNode.grpc.allowedClientCNs")
+	Params.initTLS()
+
+	assert.True(t, Params.HealthEnabled)
+	assert.False(t, Params.ReflectionEnabled)
+	Params.Save("indexNode.grpc.health.enabled", "false")
+	Params.Save("indexNode.grpc.reflection.enabled", "true")
+	Params.initServices()
+	assert.False(t, Params.HealthEnabled)
+	assert.True(t, Params.ReflectionEnabled)
+	Params.Remove("indexNode.grpc.health.enabled")
+	Params.Remove("indexNode.grpc.reflection.enabled")
+	Params.initServices()
+	assert.Equal(t, 30*time.Second, Params.ServerKeepAliveTime)
+	assert.Equal(t, grpcconfigs.DefaultServerKeepAliveTimeout, Params.ServerKeepAliveTimeout)
+	assert.Equal(t, grpcconfigs.DefaultServerKeepAliveMinTime, Params.ServerKeepAliveMinTime)
+	assert.False(t, Params.ServerKeepAlivePermitWithoutStream)
+	Params.Save("indexNode.grpc.serverKeepAliveTime", "60")
+	Params.Save("indexNode.grpc.serverKeepAliveTimeout", "20")
+	Params.Save("indexNode.grpc.serverKeepAliveMinTime", "10")
+	Params.Save("indexNode.grpc.serverKeepAlivePermitWithoutStream", "true")
+	Params.initServerKeepAlive()
+
+	assert.Equal(t, tokenauth.Token(""), Params.AuthToken)
+	Params.Save("indexNode.auth.token", " secret ")
+	Params.Save("indexNode.auth.exemptMethods", "GetComponentStates, GetMetrics,")
+	Params.initAuth()
+	assert.Equal(t, tokenauth.Token("secret"), Params.AuthToken)
+	assert.Equal(t, []string{"GetComponentStates", "GetMetrics"}, Params.AuthExemptMethods)
+	Params.Save("indexNode.auth.token", "")
+	Params.Save("indexNode.auth.exemptMethods", "")
+	Params.initAuth()
+	assert.Nil(t, Params.AuthExemptMethods)
+
+	assert.True(t, Params.ListenTCP)
+	assert.Equal(t, "", Params.UnixSocket)
+	assert.Equal(t, defaultUnixSocketMode, Params.UnixSocketMode)
+	Params.Save("indexNode.listen.tcp", "false")
+	Params.Save("indexNode.listen.unixSocket", "indexnode.sock")
+	Params.Save("indexNode.listen.unixSocketMode", "0600")
+	Params.initListen()
+	wd, _ := os.Getwd()
+	assert.False(t, Params.ListenTCP)
+	assert.Equal(t, filepath.Join(wd, "indexnode.sock"), Params.UnixSocket)
+	assert.Equal(t, os.FileMode(0600), Params.UnixSocketMode)
+	// TCP is listened on without the unix socket, and the invalid mode is replaced by the default
+	Params.Save("indexNode.listen.unixSocket", "")
+	Params.Save("indexNode.listen.unixSocketMode", "0999")
+	Params.initListen()
+	assert.True(t, Params.ListenTCP)
+	assert.Equal(t, defaultUnixSocketMode, Params.UnixSocketMode)
+	Params.Remove("indexNode.listen.tcp")
+	Params.Remove("indexNode.listen.unixSocketMode")
+	Params.initListen()
+
+	oldPort := Params.Port
+	defer func() {
+		Params.Port = oldPort
+		os.Unsetenv("INDEX_NODE_PORT")
+	}()
+	os.Setenv("INDEX_NODE_PORT", "0")
+	Params.LoadFromEnv()
+	assert.Equal(t, 0, Params.Port)
+
+	Params.Port = oldPort
+	os.Setenv("INDEX_NODE_PORT", "abc")
+	Params.LoadFromEnv()
+	assert.Equal(t, oldPort, Params.Port)
 }
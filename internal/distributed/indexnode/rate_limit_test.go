@@ -0,0 +1,69 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcindexnode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/milvus-io/milvus/internal/indexnode"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/ratelimit"
+	"github.com/milvus-io/milvus/internal/util/tokenauth"
+)
+
+// rateLimitedMock is the Mock limiting the rates of the calls to it.
+type rateLimitedMock struct {
+	indexnode.Mock
+	limiter *ratelimit.Limiter
+}
+
+func (m *rateLimitedMock) RateLimiter() *ratelimit.Limiter {
+	return m.limiter
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	Params.Init()
+	oldExempt := Params.AuthExemptMethods
+	defer func() {
+		Params.AuthExemptMethods = oldExempt
+	}()
+	Params.AuthExemptMethods = []string{"GetComponentStates"}
+	limiter := ratelimit.NewLimiter([]string{"CreateIndex"}, nil)
+	assert.Nil(t, limiter.SetLimits(map[string]ratelimit.Limit{"CreateIndex": {Rate: 0.01, Burst: 1}}))
+	addr, stop := startServer(t, &Server{indexnode: &rateLimitedMock{limiter: limiter}, token: "secret"})
+	defer stop()
+	conn := dialIndexNode(t, addr, grpc.WithInsecure())
+	defer conn.Close()
+	client := indexpb.NewIndexNodeClient(conn)
+	createIndex := func(token string) error {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), tokenauth.MetadataKey, "Bearer "+token)
+		_, err := client.CreateIndex(ctx, &indexpb.CreateIndexRequest{})
+		return err
+	}
+
+	// the unauthenticated calls are rejected before using up the limit
+	assert.Equal(t, codes.Unauthenticated, status.Code(createIndex("other")))
+	assert.Nil(t, createIndex("secret"))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(createIndex("secret")))
+
+	// the methods not limited are never rejected
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, getComponentStates(conn))
+	}
+}
@@ -16,13 +16,16 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/milvus-io/milvus/internal/types"
 
 	"go.uber.org/zap"
 
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_opentracing "github.com/grpc-ecosystem/go-grpc-middleware/tracing/opentracing"
 	"github.com/milvus-io/milvus/internal/indexnode"
 	"github.com/milvus-io/milvus/internal/log"
@@ -30,9 +33,14 @@ import (
 	"github.com/milvus-io/milvus/internal/proto/indexpb"
 	"github.com/milvus-io/milvus/internal/proto/internalpb"
 	"github.com/milvus-io/milvus/internal/proto/milvuspb"
+	"github.com/milvus-io/milvus/internal/util/accesslog"
 	"github.com/milvus-io/milvus/internal/util/funcutil"
+	"github.com/milvus-io/milvus/internal/util/ratelimit"
+	"github.com/milvus-io/milvus/internal/util/requestid"
+	"github.com/milvus-io/milvus/internal/util/tokenauth"
 	"github.com/milvus-io/milvus/internal/util/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 )
 
 // Server is the grpc wrapper of IndexNode.
@@ -41,6 +49,13 @@ type Server struct {
 
 	grpcServer  *grpc.Server
 	grpcErrChan chan error
+	// certs serves the certificates of TLS, the grpc server is plaintext if it's nil
+	certs *certReloader
+	// token is required by the calls, which are not authenticated if it's empty
+	token tokenauth.Token
+
+	httpServer    *http.Server
+	metricsServer *http.Server
 
 	loopCtx    context.Context
 	loopCancel func()
@@ -49,6 +64,26 @@ type Server struct {
 	closer io.Closer
 }
 
+// servingReporter is implemented by the IndexNode which takes the grpc serving state into its readiness.
+type servingReporter interface {
+	SetGrpcServing(serving bool)
+}
+
+// rateLimited is implemented by the IndexNode which limits the rates of the calls to it.
+type rateLimited interface {
+	RateLimiter() *ratelimit.Limiter
+}
+
+// probeHandlerProvider is implemented by the IndexNode which serves the liveness and readiness probes.
+type probeHandlerProvider interface {
+	ProbeHandler() http.Handler
+}
+
+// metricsHandlerProvider is implemented by the IndexNode which serves the prometheus metrics.
+type metricsHandlerProvider interface {
+	MetricsHandler() http.Handler
+}
+
 // Run initializes and starts IndexNode's grpc service.
 func (s *Server) Run() error {
 
@@ -62,33 +97,160 @@ func (s *Server) Run() error {
 	return nil
 }
 
-func (s *Server) startGrpcLoop(grpcPort int) {
+// listen returns the listeners of the grpc server, the TCP one of Params.Port and the unix socket one of
+// Params.UnixSocket, and sets Params.Address to the address advertised.
+func listen() ([]net.Listener, error) {
+	var listeners []net.Listener
+	if Params.ListenTCP {
+		// the port 0 binds an ephemeral port, so that the instances on the same host never collide, the bound
+		// port is registered with the session
+		lis, err := net.Listen("tcp", ":"+strconv.Itoa(Params.Port))
+		if err != nil {
+			return nil, err
+		}
+		Params.Port = lis.Addr().(*net.TCPAddr).Port
+		Params.Address = Params.IP + ":" + strconv.FormatInt(int64(Params.Port), 10)
+		listeners = append(listeners, lis)
+	}
+	if Params.UnixSocket != "" {
+		lis, err := listenUnixSocket(Params.UnixSocket, Params.UnixSocketMode)
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, err
+		}
+		if !Params.ListenTCP {
+			Params.Address = funcutil.UnixSocketAddress(Params.UnixSocket)
+		}
+		listeners = append(listeners, lis)
+	}
+	return listeners, nil
+}
+
+func (s *Server) startGrpcLoop(listeners []net.Listener) {
 
 	defer s.loopWg.Done()
 
-	log.Debug("IndexNode", zap.String("network address", Params.Address), zap.Int("network port: ", grpcPort))
-	lis, err := net.Listen("tcp", ":"+strconv.Itoa(grpcPort))
-	if err != nil {
-		log.Warn("IndexNode", zap.String("GrpcServer:failed to listen", err.Error()))
-		s.grpcErrChan <- err
-		return
-	}
+	log.Debug("IndexNode", zap.String("network address", Params.Address), zap.Int("network port: ", Params.Port),
+		zap.String("unix socket", Params.UnixSocket))
 
 	ctx, cancel := context.WithCancel(s.loopCtx)
 	defer cancel()
 
+	s.grpcServer = grpc.NewServer(s.grpcServerOptions()...)
+	s.registerServices(s.grpcServer)
+	// the listeners other than the first one are served aside, the grpc server stops serving all of them at once
+	for _, lis := range listeners[1:] {
+		s.loopWg.Add(1)
+		go func(lis net.Listener) {
+			defer s.loopWg.Done()
+			if err := s.grpcServer.Serve(lis); err != nil {
+				log.Warn("IndexNode grpc server failed to serve", zap.Stringer("address", lis.Addr()),
+					zap.Error(err))
+			}
+		}(lis)
+	}
+	go funcutil.CheckGrpcReady(ctx, s.grpcErrChan)
+	if err := s.grpcServer.Serve(listeners[0]); err != nil {
+		s.grpcErrChan <- err
+	}
+
+}
+
+// grpcServerOptions returns the options of the grpc server, which serves TLS if the certificates are configured.
+func (s *Server) grpcServerOptions() []grpc.ServerOption {
 	opts := trace.GetInterceptorOpts()
-	s.grpcServer = grpc.NewServer(
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		requestid.UnaryServerInterceptor(),
+		grpc_opentracing.UnaryServerInterceptor(opts...),
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{grpc_opentracing.StreamServerInterceptor(opts...)}
+	if Params.AccessLogEnabled {
+		accessLog := accesslog.NewInterceptor(log.L(), accesslog.Config{
+			Level:       Params.AccessLogLevel,
+			SampleRates: Params.AccessLogSampleRates,
+		})
+		unaryInterceptors = append(unaryInterceptors, accessLog.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, accessLog.StreamServerInterceptor())
+	}
+	// authenticated after the access log, which logs the rejected calls as well
+	if s.token != "" {
+		unaryInterceptors = append(unaryInterceptors,
+			tokenauth.UnaryServerInterceptor(s.token, Params.AuthExemptMethods))
+		streamInterceptors = append(streamInterceptors,
+			tokenauth.StreamServerInterceptor(s.token, Params.AuthExemptMethods))
+	}
+	if s.certs != nil && len(Params.AllowedClientCNs) > 0 {
+		verifier := &identityVerifier{patterns: Params.AllowedClientCNs}
+		unaryInterceptors = append(unaryInterceptors, verifier.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, verifier.StreamServerInterceptor())
+	}
+	// limited after the authentication, so that the unauthenticated calls don't use up the limits
+	if limited, ok := s.indexnode.(rateLimited); ok && limited.RateLimiter() != nil {
+		unaryInterceptors = append(unaryInterceptors, limited.RateLimiter().UnaryServerInterceptor())
+	}
+	// the requests compressed by the compressors of grpcconfigs are decompressed, and responded the same way
+	serverOpts := []grpc.ServerOption{
 		grpc.MaxRecvMsgSize(Params.ServerMaxRecvSize),
 		grpc.MaxSendMsgSize(Params.ServerMaxSendSize),
-		grpc.UnaryInterceptor(grpc_opentracing.UnaryServerInterceptor(opts...)),
-		grpc.StreamInterceptor(grpc_opentracing.StreamServerInterceptor(opts...)))
-	indexpb.RegisterIndexNodeServer(s.grpcServer, s)
-	go funcutil.CheckGrpcReady(ctx, s.grpcErrChan)
-	if err := s.grpcServer.Serve(lis); err != nil {
-		s.grpcErrChan <- err
+		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(unaryInterceptors...)),
+		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(streamInterceptors...)),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    Params.ServerKeepAliveTime,
+			Timeout: Params.ServerKeepAliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             Params.ServerKeepAliveMinTime,
+			PermitWithoutStream: Params.ServerKeepAlivePermitWithoutStream,
+		}),
+	}
+	if s.certs != nil {
+		serverOpts = append(serverOpts, grpc.Creds(s.certs.credentials()))
 	}
+	return serverOpts
+}
 
+func (s *Server) setGrpcServing(serving bool) {
+	if reporter, ok := s.indexnode.(servingReporter); ok {
+		reporter.SetGrpcServing(serving)
+	}
+}
+
+// startHTTPServer starts the http listener serving the probes of IndexNode if it's enabled.
+func (s *Server) startHTTPServer() {
+	provider, ok := s.indexnode.(probeHandlerProvider)
+	if Params.HTTPPort <= 0 || !ok {
+		return
+	}
+	s.httpServer = &http.Server{
+		Addr:    ":" + strconv.Itoa(Params.HTTPPort),
+		Handler: provider.ProbeHandler(),
+	}
+	go func() {
+		log.Debug("IndexNode start http server", zap.Int("port", Params.HTTPPort))
+		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Warn("IndexNode http server failed", zap.Error(err))
+		}
+	}()
+}
+
+// startMetricsServer starts the http listener serving the prometheus metrics of IndexNode if it's enabled.
+func (s *Server) startMetricsServer() {
+	provider, ok := s.indexnode.(metricsHandlerProvider)
+	if Params.MetricsPort <= 0 || !ok {
+		return
+	}
+	s.metricsServer = &http.Server{
+		Addr:    ":" + strconv.Itoa(Params.MetricsPort),
+		Handler: provider.MetricsHandler(),
+	}
+	go func() {
+		log.Debug("IndexNode start metrics server", zap.Int("port", Params.MetricsPort))
+		if err := s.metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Warn("IndexNode metrics server failed", zap.Error(err))
+		}
+	}()
 }
 
 // init initializes IndexNode's grpc service.
@@ -96,6 +258,38 @@ func (s *Server) init() error {
 	var err error
 	Params.Init()
 
+	s.certs, err = loadServerCerts()
+	if err != nil {
+		log.Error("IndexNode failed to load the grpc certificates", zap.Error(err))
+		return err
+	}
+	if s.certs != nil {
+		log.Debug("IndexNode grpc server serves TLS", zap.String("cert", Params.ServerCert),
+			zap.Bool("verifyClients", Params.CACert != ""), zap.Strings("allowedClientCNs", Params.AllowedClientCNs))
+		s.loopWg.Add(1)
+		go func() {
+			defer s.loopWg.Done()
+			s.certs.watch(s.loopCtx, certReloadInterval)
+		}()
+	}
+	s.token, err = tokenauth.LoadToken(string(Params.AuthToken), Params.AuthTokenFile)
+	if err != nil {
+		log.Error("IndexNode failed to load the auth token", zap.Error(err))
+		return err
+	}
+	if s.token != "" {
+		if s.certs == nil {
+			log.Warn("IndexNode authenticates the calls by the token over plaintext, which TLS should protect")
+		}
+		log.Debug("IndexNode grpc server authenticates the calls", zap.Strings("exempt", Params.AuthExemptMethods))
+	}
+
+	listeners, err := listen()
+	if err != nil {
+		log.Warn("IndexNode", zap.String("GrpcServer:failed to listen", err.Error()))
+		return err
+	}
+
 	indexnode.Params.InitOnce()
 	indexnode.Params.Port = Params.Port
 	indexnode.Params.IP = Params.IP
@@ -104,8 +298,6 @@ func (s *Server) init() error {
 	closer := trace.InitTracing(fmt.Sprintf("IndexNode-%d", indexnode.Params.NodeID))
 	s.closer = closer
 
-	Params.Address = Params.IP + ":" + strconv.FormatInt(int64(Params.Port), 10)
-
 	defer func() {
 		if err != nil {
 			err = s.Stop()
@@ -115,27 +307,36 @@ func (s *Server) init() error {
 		}
 	}()
 
-	err = s.indexnode.Register()
-	if err != nil {
-		log.Error("IndexNode Register etcd failed", zap.Error(err))
-		return err
-	}
-	log.Debug("IndexNode Register etcd success")
+	s.setGrpcServing(false)
+	s.startHTTPServer()
+	s.startMetricsServer()
 
+	// the grpc server listens first, the requests arriving before the startup sequence finishes are rejected
+	// by IndexNode as not ready
 	s.loopWg.Add(1)
-	go s.startGrpcLoop(Params.Port)
+	go s.startGrpcLoop(listeners)
 	// wait for grpc server loop start
 	err = <-s.grpcErrChan
 	if err != nil {
 		log.Error("IndexNode", zap.Any("grpc error", err))
 		return err
 	}
+	s.setGrpcServing(true)
 
+	// connect etcd and check the storage before registering the session, so that the coordinator never finds
+	// a node unable to build
 	err = s.indexnode.Init()
 	if err != nil {
 		log.Error("IndexNode Init failed", zap.Error(err))
 		return err
 	}
+
+	err = s.indexnode.Register()
+	if err != nil {
+		log.Error("IndexNode Register etcd failed", zap.Error(err))
+		return err
+	}
+	log.Debug("IndexNode Register etcd success")
 	return nil
 }
 
@@ -150,21 +351,55 @@ func (s *Server) start() error {
 
 // Stop stops IndexNode's grpc service.
 func (s *Server) Stop() error {
+	// the tasks are drained before the loop context is cancelled and the grpc server stops, so that they finish with
+	// the certificates still watched, and the coordinator still queries their states
+	if s.indexnode != nil {
+		s.setGrpcServing(false)
+		s.indexnode.Stop()
+	}
+	s.loopCancel()
+	s.stopGrpcServer()
+	if s.httpServer != nil {
+		if err := s.httpServer.Close(); err != nil {
+			log.Warn("IndexNode failed to close http server", zap.Error(err))
+		}
+	}
+	if s.metricsServer != nil {
+		if err := s.metricsServer.Close(); err != nil {
+			log.Warn("IndexNode failed to close metrics server", zap.Error(err))
+		}
+	}
+	s.loopWg.Wait()
+	// the spans of the tasks drained are flushed
 	if s.closer != nil {
 		if err := s.closer.Close(); err != nil {
 			return err
 		}
 	}
-	s.loopCancel()
-	if s.indexnode != nil {
-		s.indexnode.Stop()
+
+	return nil
+}
+
+// stopGrpcServer stops accepting new connections and sends GOAWAY to the clients, then waits for the in-flight
+// calls to finish up to Params.GracefulStopTimeout before closing the connections.
+func (s *Server) stopGrpcServer() {
+	if s.grpcServer == nil {
+		return
 	}
-	if s.grpcServer != nil {
+	stopped := make(chan struct{})
+	go func() {
 		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	t := time.NewTimer(Params.GracefulStopTimeout)
+	defer t.Stop()
+	select {
+	case <-stopped:
+	case <-t.C:
+		log.Warn("IndexNode grpc server closes the connections since the in-flight calls are not finished in time",
+			zap.Duration("timeout", Params.GracefulStopTimeout))
+		s.grpcServer.Stop()
 	}
-	s.loopWg.Wait()
-
-	return nil
 }
 
 // SetClient sets the IndexNode's instance.
@@ -193,6 +428,36 @@ func (s *Server) CreateIndex(ctx context.Context, req *indexpb.CreateIndexReques
 	return s.indexnode.CreateIndex(ctx, req)
 }
 
+// DryRunCreateIndex validates the create index request without building the index.
+func (s *Server) DryRunCreateIndex(ctx context.Context, req *indexpb.CreateIndexRequest) (*indexpb.DryRunCreateIndexResponse, error) {
+	return s.indexnode.DryRunCreateIndex(ctx, req)
+}
+
+// Activate switches the standby IndexNode to active.
+func (s *Server) Activate(ctx context.Context, req *indexpb.ActivateRequest) (*commonpb.Status, error) {
+	return s.indexnode.Activate(ctx, req)
+}
+
+// CaptureProfile captures a profile of IndexNode and uploads it to the object storage.
+func (s *Server) CaptureProfile(ctx context.Context, req *indexpb.CaptureProfileRequest) (*indexpb.CaptureProfileResponse, error) {
+	return s.indexnode.CaptureProfile(ctx, req)
+}
+
+// VerifyIndexManifest re-checks the index files of a build against the manifest saved along with them.
+func (s *Server) VerifyIndexManifest(ctx context.Context, req *indexpb.VerifyIndexManifestRequest) (*indexpb.VerifyIndexManifestResponse, error) {
+	return s.indexnode.VerifyIndexManifest(ctx, req)
+}
+
+// RunBuildBenchmark builds an index of the synthetic vectors to measure the build throughput of IndexNode.
+func (s *Server) RunBuildBenchmark(ctx context.Context, req *indexpb.RunBuildBenchmarkRequest) (*indexpb.RunBuildBenchmarkResponse, error) {
+	return s.indexnode.RunBuildBenchmark(ctx, req)
+}
+
+// RebuildIncompatibleIndexes starts to rebuild the indexes built by IndexNode which are too old for the current engine.
+func (s *Server) RebuildIncompatibleIndexes(ctx context.Context, req *indexpb.RebuildIncompatibleIndexesRequest) (*commonpb.Status, error) {
+	return s.indexnode.RebuildIncompatibleIndexes(ctx, req)
+}
+
 // GetMetrics gets the metrics info of IndexNode.
 func (s *Server) GetMetrics(ctx context.Context, request *milvuspb.GetMetricsRequest) (*milvuspb.GetMetricsResponse, error) {
 	return s.indexnode.GetMetrics(ctx, request)
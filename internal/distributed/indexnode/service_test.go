@@ -13,8 +13,16 @@ package grpcindexnode
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/milvus-io/milvus/internal/distributed/grpcconfigs"
+	grpcindexnodeclient "github.com/milvus-io/milvus/internal/distributed/indexnode/client"
+
 	"github.com/milvus-io/milvus/internal/indexnode"
 
 	"github.com/milvus-io/milvus/internal/proto/commonpb"
@@ -69,6 +77,48 @@ func TestIndexNodeServer(t *testing.T) {
 		assert.Equal(t, commonpb.ErrorCode_Success, resp.ErrorCode)
 	})
 
+	t.Run("DryRunCreateIndex", func(t *testing.T) {
+		req := &indexpb.CreateIndexRequest{
+			IndexBuildID: 0,
+			IndexID:      0,
+			DataPaths:    []string{},
+		}
+		resp, err := ins.DryRunCreateIndex(ctx, req)
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+		assert.True(t, resp.Passed)
+	})
+
+	t.Run("Activate", func(t *testing.T) {
+		resp, err := ins.Activate(ctx, &indexpb.ActivateRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.ErrorCode)
+	})
+
+	t.Run("CaptureProfile", func(t *testing.T) {
+		resp, err := ins.CaptureProfile(ctx, &indexpb.CaptureProfileRequest{ProfileType: "heap"})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+	})
+
+	t.Run("VerifyIndexManifest", func(t *testing.T) {
+		resp, err := ins.VerifyIndexManifest(ctx, &indexpb.VerifyIndexManifestRequest{IndexBuildID: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+	})
+
+	t.Run("RunBuildBenchmark", func(t *testing.T) {
+		resp, err := ins.RunBuildBenchmark(ctx, &indexpb.RunBuildBenchmarkRequest{Rows: 1000, Dim: 8, IndexType: "FLAT"})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+	})
+
+	t.Run("RebuildIncompatibleIndexes", func(t *testing.T) {
+		resp, err := ins.RebuildIncompatibleIndexes(ctx, &indexpb.RebuildIncompatibleIndexesRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.ErrorCode)
+	})
+
 	t.Run("GetMetrics", func(t *testing.T) {
 		req := &milvuspb.GetMetricsRequest{
 			Request: "",
@@ -81,3 +131,68 @@ func TestIndexNodeServer(t *testing.T) {
 	err = ins.Stop()
 	assert.Nil(t, err)
 }
+
+func TestServer_MaxMsgSize(t *testing.T) {
+	Params.Init()
+	grpcindexnodeclient.Params.Init()
+	oldRecv, oldSend := Params.ServerMaxRecvSize, grpcindexnodeclient.Params.ClientMaxSendSize
+	defer func() {
+		Params.ServerMaxRecvSize, grpcindexnodeclient.Params.ClientMaxSendSize = oldRecv, oldSend
+	}()
+	// just above the default limit of grpc
+	req := &indexpb.CreateIndexRequest{IndexName: strings.Repeat("x", 4*1024*1024+1024)}
+	createIndex := func() error {
+		addr, stop := startTestServer(t, nil)
+		defer stop()
+		client, err := grpcindexnodeclient.NewClient(context.Background(), addr)
+		assert.Nil(t, err)
+		defer client.Stop()
+		_, err = client.CreateIndex(context.Background(), req)
+		return err
+	}
+
+	Params.ServerMaxRecvSize = 4 * 1024 * 1024
+	err := createIndex()
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	grpcindexnodeclient.Params.ClientMaxSendSize = 4 * 1024 * 1024
+	Params.ServerMaxRecvSize = 8 * 1024 * 1024
+	err = createIndex()
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	grpcindexnodeclient.Params.ClientMaxSendSize = 8 * 1024 * 1024
+	assert.Nil(t, createIndex())
+}
+
+func TestServer_Compression(t *testing.T) {
+	Params.Init()
+	grpcindexnodeclient.Params.Init()
+	oldSend, oldCompression := grpcindexnodeclient.Params.ClientMaxSendSize, grpcindexnodeclient.Params.ClientCompression
+	defer func() {
+		grpcindexnodeclient.Params.ClientMaxSendSize, grpcindexnodeclient.Params.ClientCompression = oldSend, oldCompression
+	}()
+	addr, stop := startTestServer(t, nil)
+	defer stop()
+	// the binlog paths compress far within the limit of the client, which applies to the compressed messages
+	grpcindexnodeclient.Params.ClientMaxSendSize = grpcconfigs.MinMsgSize * 16
+	req := &indexpb.CreateIndexRequest{}
+	for i := 0; i < 4000; i++ {
+		req.DataPaths = append(req.DataPaths, fmt.Sprintf("files/insert_log/428/429/430/%d/%d", 100+i%2, 431+i))
+	}
+	createIndex := func(compression string) error {
+		grpcindexnodeclient.Params.ClientCompression = compression
+		client, err := grpcindexnodeclient.NewClient(context.Background(), addr)
+		assert.Nil(t, err)
+		defer client.Stop()
+		resp, err := client.CreateIndex(context.Background(), req)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.ErrorCode)
+		return nil
+	}
+
+	err := createIndex(grpcconfigs.CompressionNone)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Nil(t, createIndex(grpcconfigs.CompressionGzip))
+	assert.Nil(t, createIndex(grpcconfigs.CompressionSnappy))
+}
@@ -0,0 +1,192 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcindexnode
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/milvus-io/milvus/internal/log"
+)
+
+// certReloadInterval is the interval of checking whether the certificate files are renewed.
+const certReloadInterval = time.Minute
+
+// certReloader serves the certificate of the grpc server, and verifies the certificates of the clients against the
+// CA if it's provided. The files are checked periodically, the renewed certificates take effect for the new
+// connections while the established ones are kept.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	// contents is the contents of the files loaded, by which the renewed files are found
+	contents map[string][]byte
+}
+
+// loadServerCerts loads the certificates of indexNode.grpc.serverCert, serverKey and caCert, it returns nil if TLS
+// is not configured, with which the grpc server serves in plaintext.
+func loadServerCerts() (*certReloader, error) {
+	if len(Params.AllowedClientCNs) > 0 && Params.CACert == "" {
+		return nil, errors.New("indexNode.grpc.allowedClientCNs is set without indexNode.grpc.caCert verifying the clients")
+	}
+	if Params.ServerCert == "" && Params.ServerKey == "" {
+		if Params.CACert != "" {
+			return nil, errors.New("indexNode.grpc.caCert is set without indexNode.grpc.serverCert and serverKey")
+		}
+		return nil, nil
+	}
+	if Params.ServerCert == "" || Params.ServerKey == "" {
+		return nil, errors.New("both indexNode.grpc.serverCert and indexNode.grpc.serverKey are required by TLS")
+	}
+	return newCertReloader(Params.ServerCert, Params.ServerKey, Params.CACert)
+}
+
+// newCertReloader loads the certificate of the server from @certFile and @keyFile, and the CA of the clients from
+// @caFile if it's not empty.
+func newCertReloader(certFile, keyFile, caFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	contents, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(contents); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// read reads the files of the certificates.
+func (r *certReloader) read() (map[string][]byte, error) {
+	contents := make(map[string][]byte)
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the certificate file %s: %w", file, err)
+		}
+		contents[file] = content
+	}
+	return contents, nil
+}
+
+// load parses the certificates of @contents, and replaces the ones served if all of them are valid.
+func (r *certReloader) load(contents map[string][]byte) error {
+	cert, err := tls.X509KeyPair(contents[r.certFile], contents[r.keyFile])
+	if err != nil {
+		return fmt.Errorf("failed to load the server certificate %s with the key %s: %w", r.certFile, r.keyFile, err)
+	}
+	var clientCAs *x509.CertPool
+	if r.caFile != "" {
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(contents[r.caFile]) {
+			return fmt.Errorf("failed to load the CA certificate %s: no certificate found", r.caFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.contents = contents
+	return nil
+}
+
+// changed returns whether any of @contents differs from the files loaded.
+func (r *certReloader) changed(contents map[string][]byte) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for file, content := range contents {
+		if !bytes.Equal(content, r.contents[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// reload loads the renewed files, it returns whether the certificates are reloaded. The certificates served are
+// kept if the renewed files are invalid, e.g. the certificate is written but the key is not yet.
+func (r *certReloader) reload() (bool, error) {
+	contents, err := r.read()
+	if err != nil {
+		return false, err
+	}
+	if !r.changed(contents) {
+		return false, nil
+	}
+	if err := r.load(contents); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// watch reloads the renewed files every @interval until @ctx is done.
+func (r *certReloader) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.reload()
+			if err != nil {
+				log.Warn("IndexNode failed to reload the grpc certificates, keep the loaded ones", zap.Error(err))
+				continue
+			}
+			if reloaded {
+				log.Info("IndexNode reloaded the grpc certificates", zap.String("cert", r.certFile),
+					zap.String("ca", r.caFile))
+			}
+		}
+	}
+}
+
+// config returns the tls config of a new connection with the certificates served at the moment.
+func (r *certReloader) config(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*r.cert},
+		// the config replaces the one of credentials.NewTLS, which negotiates http2 as well
+		NextProtos: []string{"h2"},
+	}
+	if r.clientCAs != nil {
+		cfg.ClientCAs = r.clientCAs
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// credentials returns the transport credentials of the grpc server.
+func (r *certReloader) credentials() credentials.TransportCredentials {
+	return credentials.NewTLS(&tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetConfigForClient: r.config,
+	})
+}
@@ -0,0 +1,342 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcindexnode
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/milvus-io/milvus/internal/distributed/grpcconfigs"
+	grpcindexnodeclient "github.com/milvus-io/milvus/internal/distributed/indexnode/client"
+	"github.com/milvus-io/milvus/internal/indexnode"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+)
+
+// testCA issues the certificates of the tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+var testSerial int64
+
+func newTestTemplate(name string) *x509.Certificate {
+	testSerial++
+	return &x509.Certificate{
+		SerialNumber: big.NewInt(testSerial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := newTestTemplate(name)
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the certificate and the key in PEM of the server on 127.0.0.1 or of a client, with the DNS SANs of
+// @dnsNames.
+func (ca *testCA) issue(t *testing.T, name string, server bool, dnsNames ...string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := newTestTemplate(name)
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.DNSNames = dnsNames
+	if server {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		template.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1)}
+	} else {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+// writeServerCerts writes the certificate of the server issued by @ca, and the CA of the clients if it's not nil.
+func writeServerCerts(t *testing.T, dir string, ca *testCA, clientCA *testCA) (string, string, string) {
+	certFile, keyFile, caFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), ""
+	cert, key := ca.issue(t, "indexnode", true)
+	assert.Nil(t, ioutil.WriteFile(certFile, cert, 0600))
+	assert.Nil(t, ioutil.WriteFile(keyFile, key, 0600))
+	if clientCA != nil {
+		caFile = filepath.Join(dir, "ca.crt")
+		assert.Nil(t, ioutil.WriteFile(caFile, clientCA.pem, 0600))
+	}
+	return certFile, keyFile, caFile
+}
+
+// clientCreds returns the credentials of the client trusting @ca, with the certificate issued by @clientCA if it's
+// not nil.
+func clientCreds(t *testing.T, ca *testCA, clientCA *testCA) credentials.TransportCredentials {
+	return clientCredsOf(t, ca, clientCA, "indexcoord")
+}
+
+// clientCredsOf returns the credentials of clientCreds, the certificate of which is of @name and @dnsNames.
+func clientCredsOf(t *testing.T, ca *testCA, clientCA *testCA, name string, dnsNames ...string) credentials.TransportCredentials {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	cfg := &tls.Config{RootCAs: roots}
+	if clientCA != nil {
+		cert, key := clientCA.issue(t, name, false, dnsNames...)
+		pair, err := tls.X509KeyPair(cert, key)
+		assert.Nil(t, err)
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+	return credentials.NewTLS(cfg)
+}
+
+// writeClientCerts writes the CA trusting the servers issued by @ca, and the certificate of the client of @name
+// issued by @clientCA if it's not nil, it returns the TLS of the client dialing with them.
+func writeClientCerts(t *testing.T, dir string, ca *testCA, clientCA *testCA, name string) grpcconfigs.ClientTLS {
+	clientTLS := grpcconfigs.ClientTLS{CACert: filepath.Join(dir, "client-ca.crt")}
+	assert.Nil(t, ioutil.WriteFile(clientTLS.CACert, ca.pem, 0600))
+	if clientCA != nil {
+		clientTLS.Cert, clientTLS.Key = filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+		cert, key := clientCA.issue(t, name, false)
+		assert.Nil(t, ioutil.WriteFile(clientTLS.Cert, cert, 0600))
+		assert.Nil(t, ioutil.WriteFile(clientTLS.Key, key, 0600))
+	}
+	return clientTLS
+}
+
+// newTLSClient returns the client of IndexNode dialing @addr with @clientTLS, which gives up connecting after
+// @timeout.
+func newTLSClient(t *testing.T, addr string, clientTLS grpcconfigs.ClientTLS, timeout time.Duration) (*grpcindexnodeclient.Client, func()) {
+	grpcindexnodeclient.Params.Init()
+	oldTLS := grpcindexnodeclient.Params.ClientTLS
+	grpcindexnodeclient.Params.ClientTLS = clientTLS
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	client, err := grpcindexnodeclient.NewClient(ctx, addr)
+	assert.Nil(t, err)
+	return client, func() {
+		_ = client.Stop()
+		cancel()
+		grpcindexnodeclient.Params.ClientTLS = oldTLS
+	}
+}
+
+// startTestServer starts the grpc server of the mock IndexNode, which serves TLS if @certs is not nil.
+func startTestServer(t *testing.T, certs *certReloader) (string, func()) {
+	return startServer(t, &Server{certs: certs})
+}
+
+// startServer starts the grpc server of @s, with the mock IndexNode if s.indexnode is nil.
+func startServer(t *testing.T, s *Server) (string, func()) {
+	Params.Init()
+	if s.indexnode == nil {
+		s.indexnode = &indexnode.Mock{}
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	server := grpc.NewServer(s.grpcServerOptions()...)
+	s.registerServices(server)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	return lis.Addr().String(), server.Stop
+}
+
+func dialIndexNode(t *testing.T, addr string, opt grpc.DialOption) *grpc.ClientConn {
+	conn, err := grpc.Dial(addr, opt)
+	assert.Nil(t, err)
+	return conn
+}
+
+func getComponentStates(conn *grpc.ClientConn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := indexpb.NewIndexNodeClient(conn).GetComponentStates(ctx, &internalpb.GetComponentStatesRequest{})
+	return err
+}
+
+func TestServer_TLS(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	certs, err := newCertReloader(writeServerCerts(t, t.TempDir(), ca, nil))
+	assert.Nil(t, err)
+	addr, stop := startTestServer(t, certs)
+	defer stop()
+
+	conn := dialIndexNode(t, addr, grpc.WithTransportCredentials(clientCreds(t, ca, nil)))
+	defer conn.Close()
+	assert.Nil(t, getComponentStates(conn))
+
+	// the plaintext clients and the ones not trusting the certificate are rejected
+	plaintext := dialIndexNode(t, addr, grpc.WithInsecure())
+	defer plaintext.Close()
+	assert.NotNil(t, getComponentStates(plaintext))
+	untrusted := dialIndexNode(t, addr, grpc.WithTransportCredentials(clientCreds(t, newTestCA(t, "other"), nil)))
+	defer untrusted.Close()
+	assert.NotNil(t, getComponentStates(untrusted))
+}
+
+func TestServer_MutualTLS(t *testing.T) {
+	ca, clientCA := newTestCA(t, "ca"), newTestCA(t, "client-ca")
+	certs, err := newCertReloader(writeServerCerts(t, t.TempDir(), ca, clientCA))
+	assert.Nil(t, err)
+	addr, stop := startTestServer(t, certs)
+	defer stop()
+
+	conn := dialIndexNode(t, addr, grpc.WithTransportCredentials(clientCreds(t, ca, clientCA)))
+	defer conn.Close()
+	assert.Nil(t, getComponentStates(conn))
+
+	// the clients without a certificate, or with one issued by another CA, are rejected
+	anonymous := dialIndexNode(t, addr, grpc.WithTransportCredentials(clientCreds(t, ca, nil)))
+	defer anonymous.Close()
+	assert.NotNil(t, getComponentStates(anonymous))
+	untrusted := dialIndexNode(t, addr, grpc.WithTransportCredentials(clientCreds(t, ca, newTestCA(t, "other"))))
+	defer untrusted.Close()
+	assert.NotNil(t, getComponentStates(untrusted))
+}
+
+func TestClient_TLS(t *testing.T) {
+	ca, clientCA := newTestCA(t, "ca"), newTestCA(t, "client-ca")
+	dir := t.TempDir()
+	certs, err := newCertReloader(writeServerCerts(t, dir, ca, clientCA))
+	assert.Nil(t, err)
+	addr, stop := startTestServer(t, certs)
+	defer stop()
+
+	getStates := func(clientTLS grpcconfigs.ClientTLS, timeout time.Duration) error {
+		client, clean := newTLSClient(t, addr, clientTLS, timeout)
+		defer clean()
+		_, err := client.GetComponentStates(context.Background())
+		return err
+	}
+	assert.Nil(t, getStates(writeClientCerts(t, dir, ca, clientCA, "indexcoord"), 10*time.Second))
+
+	// the plaintext clients and the ones presenting no certificate are rejected
+	assert.NotNil(t, getStates(grpcconfigs.ClientTLS{}, time.Second))
+	assert.NotNil(t, getStates(writeClientCerts(t, dir, ca, nil, ""), time.Second))
+}
+
+func TestCertReloader_reload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "ca")
+	certs, err := newCertReloader(writeServerCerts(t, dir, ca, nil))
+	assert.Nil(t, err)
+	addr, stop := startTestServer(t, certs)
+	defer stop()
+	established := dialIndexNode(t, addr, grpc.WithTransportCredentials(clientCreds(t, ca, nil)))
+	defer established.Close()
+	assert.Nil(t, getComponentStates(established))
+
+	reloaded, err := certs.reload()
+	assert.Nil(t, err)
+	assert.False(t, reloaded)
+
+	// the certificate is renewed by another CA
+	renewedCA := newTestCA(t, "renewed-ca")
+	writeServerCerts(t, dir, renewedCA, nil)
+	reloaded, err = certs.reload()
+	assert.Nil(t, err)
+	assert.True(t, reloaded)
+	renewed := dialIndexNode(t, addr, grpc.WithTransportCredentials(clientCreds(t, renewedCA, nil)))
+	defer renewed.Close()
+	assert.Nil(t, getComponentStates(renewed))
+	// the established connection is kept, while the new ones get the renewed certificate
+	assert.Nil(t, getComponentStates(established))
+	stale := dialIndexNode(t, addr, grpc.WithTransportCredentials(clientCreds(t, ca, nil)))
+	defer stale.Close()
+	assert.NotNil(t, getComponentStates(stale))
+
+	// the invalid files are not loaded, and the renewed certificate is kept
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "server.key"), []byte("partially written"), 0600))
+	reloaded, err = certs.reload()
+	assert.NotNil(t, err)
+	assert.False(t, reloaded)
+	another := dialIndexNode(t, addr, grpc.WithTransportCredentials(clientCreds(t, renewedCA, nil)))
+	defer another.Close()
+	assert.Nil(t, getComponentStates(another))
+
+	// the watch stops with the context
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		certs.watch(ctx, time.Millisecond)
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	<-done
+}
+
+func TestLoadServerCerts(t *testing.T) {
+	oldCert, oldKey, oldCA := Params.ServerCert, Params.ServerKey, Params.CACert
+	defer func() {
+		Params.ServerCert, Params.ServerKey, Params.CACert = oldCert, oldKey, oldCA
+	}()
+	dir := t.TempDir()
+	certFile, keyFile, caFile := writeServerCerts(t, dir, newTestCA(t, "ca"), newTestCA(t, "client-ca"))
+
+	// plaintext
+	Params.ServerCert, Params.ServerKey, Params.CACert = "", "", ""
+	certs, err := loadServerCerts()
+	assert.Nil(t, err)
+	assert.Nil(t, certs)
+	Params.CACert = caFile
+	_, err = loadServerCerts()
+	assert.NotNil(t, err)
+	Params.ServerCert, Params.CACert = certFile, ""
+	_, err = loadServerCerts()
+	assert.NotNil(t, err)
+
+	Params.ServerCert, Params.ServerKey, Params.CACert = certFile, keyFile, caFile
+	certs, err = loadServerCerts()
+	assert.Nil(t, err)
+	assert.NotNil(t, certs.clientCAs)
+
+	// the errors name the files
+	missing := filepath.Join(dir, "missing.crt")
+	Params.ServerCert = missing
+	_, err = loadServerCerts()
+	assert.Contains(t, err.Error(), missing)
+	Params.ServerCert, Params.ServerKey = certFile, caFile
+	_, err = loadServerCerts()
+	assert.Contains(t, err.Error(), certFile)
+	assert.Contains(t, err.Error(), caFile)
+	Params.ServerKey, Params.CACert = keyFile, keyFile
+	_, err = loadServerCerts()
+	assert.Contains(t, err.Error(), keyFile)
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcindexnode
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+)
+
+// defaultUnixSocketMode allows the users of the group of IndexNode to connect, e.g. the coordinators on the same host.
+const defaultUnixSocketMode os.FileMode = 0660
+
+// staleSocketDialTimeout is the time waiting for the server of an existing socket, which is stale if it's not served.
+const staleSocketDialTimeout = time.Second
+
+// removeStaleSocket removes the socket of @path left by a crashed process. It fails if @path is not a socket, or
+// the socket is still served by another process.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a unix socket", path)
+	}
+	conn, err := net.DialTimeout("unix", path, staleSocketDialTimeout)
+	if err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("the unix socket %s is served by another process", path)
+	}
+	log.Info("IndexNode removes the stale unix socket", zap.String("path", path))
+	return os.Remove(path)
+}
+
+// listenUnixSocket listens on the unix socket of @path with the permission bits of @mode, the socket is removed once
+// the listener is closed.
+func listenUnixSocket(path string, mode os.FileMode) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		_ = lis.Close()
+		return nil, fmt.Errorf("failed to change the mode of the unix socket %s: %w", path, err)
+	}
+	return lis, nil
+}
@@ -0,0 +1,123 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcindexnode
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	grpcindexnodeclient "github.com/milvus-io/milvus/internal/distributed/indexnode/client"
+	"github.com/milvus-io/milvus/internal/indexnode"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/util/funcutil"
+)
+
+func TestListenUnixSocket(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "indexnode.sock")
+
+	lis, err := listenUnixSocket(path, 0600)
+	assert.Nil(t, err)
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// the socket served by another process is never removed
+	_, err = listenUnixSocket(path, 0600)
+	assert.NotNil(t, err)
+
+	// the socket is removed once the listener is closed
+	assert.Nil(t, lis.Close())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// the stale socket left by a crashed process is removed
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	assert.Nil(t, err)
+	stale.SetUnlinkOnClose(false)
+	assert.Nil(t, stale.Close())
+	_, err = os.Stat(path)
+	assert.Nil(t, err)
+	lis, err = listenUnixSocket(path, 0660)
+	assert.Nil(t, err)
+	assert.Nil(t, lis.Close())
+
+	// the files other than the sockets are never removed
+	file := filepath.Join(dir, "file")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("data"), 0600))
+	_, err = listenUnixSocket(file, 0600)
+	assert.NotNil(t, err)
+	_, err = os.Stat(file)
+	assert.Nil(t, err)
+}
+
+func TestServer_UnixSocket(t *testing.T) {
+	Params.Init()
+	oldListenTCP, oldSocket, oldMode := Params.ListenTCP, Params.UnixSocket, Params.UnixSocketMode
+	oldPort, oldAddress := Params.Port, Params.Address
+	defer func() {
+		Params.ListenTCP, Params.UnixSocket, Params.UnixSocketMode = oldListenTCP, oldSocket, oldMode
+		Params.Port, Params.Address = oldPort, oldAddress
+	}()
+	Params.UnixSocket = filepath.Join(t.TempDir(), "indexnode.sock")
+	Params.UnixSocketMode = defaultUnixSocketMode
+	Params.Port = 0
+
+	// serve the listeners and call GetComponentStates through the clients of the unix socket and the advertised
+	// address
+	serve := func() {
+		listeners, err := listen()
+		assert.Nil(t, err)
+		s := &Server{
+			loopCtx:     context.Background(),
+			loopCancel:  func() {},
+			indexnode:   &indexnode.Mock{},
+			grpcErrChan: make(chan error),
+		}
+		s.loopWg.Add(1)
+		go s.startGrpcLoop(listeners)
+		assert.Nil(t, <-s.grpcErrChan)
+		defer func() {
+			s.grpcServer.Stop()
+			s.loopWg.Wait()
+			_, err := os.Stat(Params.UnixSocket)
+			assert.True(t, os.IsNotExist(err))
+		}()
+
+		for _, address := range []string{funcutil.UnixSocketAddress(Params.UnixSocket), Params.Address} {
+			client, err := grpcindexnodeclient.NewClient(context.Background(), address)
+			assert.Nil(t, err)
+			assert.Nil(t, client.Init())
+			states, err := client.GetComponentStates(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, commonpb.ErrorCode_Success, states.Status.ErrorCode)
+			assert.Nil(t, client.Stop())
+		}
+	}
+
+	// the unix socket is advertised instead of TCP
+	Params.ListenTCP = false
+	Params.Address = ""
+	serve()
+	assert.Equal(t, funcutil.UnixSocketAddress(Params.UnixSocket), Params.Address)
+
+	// the unix socket is served in addition to TCP, which is advertised
+	Params.ListenTCP = true
+	serve()
+	assert.False(t, funcutil.IsUnixSocketAddress(Params.Address))
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/milvus-io/milvus/internal/tso"
 	"github.com/milvus-io/milvus/internal/types"
 	"github.com/milvus-io/milvus/internal/util/metricsinfo"
+	"github.com/milvus-io/milvus/internal/util/requestid"
 	"github.com/milvus-io/milvus/internal/util/retry"
 	"github.com/milvus-io/milvus/internal/util/sessionutil"
 	"github.com/milvus-io/milvus/internal/util/trace"
@@ -727,20 +728,54 @@ func (i *IndexCoord) watchMetaLoop() {
 	}
 }
 
-func (i *IndexCoord) assignTask(builderClient types.IndexNode, req *indexpb.CreateIndexRequest) bool {
+// assignTask assigns the task to the IndexNode, busy is true if the IndexNode rejects the task since it is busy,
+// which another IndexNode may take.
+func (i *IndexCoord) assignTask(builderClient types.IndexNode, req *indexpb.CreateIndexRequest) (assigned bool, busy bool) {
 	ctx, cancel := context.WithTimeout(i.loopCtx, i.reqTimeoutInterval)
 	defer cancel()
+	// the request id is logged by the IndexNode handling the request as well
+	requestID := requestid.New()
+	ctx = requestid.NewContext(ctx, requestID)
 	resp, err := builderClient.CreateIndex(ctx, req)
 	if err != nil {
-		log.Error("IndexCoord assignmentTasksLoop builderClient.CreateIndex failed", zap.Error(err))
-		return false
+		log.Error("IndexCoord assignmentTasksLoop builderClient.CreateIndex failed", zap.Int64("indexBuildID", req.IndexBuildID),
+			zap.String(requestid.FieldKey, requestID), zap.Error(err))
+		return false, false
 	}
 
+	if resp.ErrorCode == commonpb.ErrorCode_NodeBusy {
+		log.Warn("IndexCoord assignmentTasksLoop IndexNode is busy", zap.Int64("indexBuildID", req.IndexBuildID),
+			zap.String(requestid.FieldKey, requestID), zap.String("Reason", resp.Reason))
+		return false, true
+	}
 	if resp.ErrorCode != commonpb.ErrorCode_Success {
-		log.Error("IndexCoord assignmentTasksLoop builderClient.CreateIndex failed", zap.String("Reason", resp.Reason))
-		return false
+		log.Error("IndexCoord assignmentTasksLoop builderClient.CreateIndex failed", zap.Int64("indexBuildID", req.IndexBuildID),
+			zap.String(requestid.FieldKey, requestID), zap.String("Reason", resp.Reason))
+		return false, false
 	}
-	return true
+	log.Debug("IndexCoord assigned the task", zap.Int64("indexBuildID", req.IndexBuildID),
+		zap.String(requestid.FieldKey, requestID))
+	return true, false
+}
+
+// assignTaskToNodes assigns the task to the IndexNode of @nodeID, the task rejected by a busy IndexNode is assigned
+// to the next least loaded one instead, and the busy ones are added to @busyNodes. It returns the IndexNode the
+// task is assigned to, false if no IndexNode takes the task.
+func (i *IndexCoord) assignTaskToNodes(nodeID UniqueID, builderClient types.IndexNode, req *indexpb.CreateIndexRequest,
+	busyNodes map[UniqueID]bool) (UniqueID, bool) {
+	for builderClient != nil {
+		assigned, busy := i.assignTask(builderClient, req)
+		if !busy {
+			return nodeID, assigned
+		}
+		busyNodes[nodeID] = true
+		nodeID, builderClient = i.nodeManager.PeekClientExcept(busyNodes)
+		if builderClient != nil {
+			log.Debug("IndexCoord assigns the task rejected by the busy IndexNode to another one",
+				zap.Int64("indexBuildID", req.IndexBuildID), zap.Int64("nodeID", nodeID))
+		}
+	}
+	return nodeID, false
 }
 
 // assignTaskLoop is used to assign index construction tasks.
@@ -773,10 +808,16 @@ func (i *IndexCoord) assignTaskLoop() {
 			}
 			log.Debug("IndexCoord assignTaskLoop", zap.Int64s("Available IndexNode IDs", serverIDs))
 			metas := i.metaTable.GetUnassignedTasks(serverIDs)
+			// the tasks of a higher priority are assigned first
 			sort.Slice(metas, func(i, j int) bool {
+				if pi, pj := metas[i].indexMeta.Req.GetPriority(), metas[j].indexMeta.Req.GetPriority(); pi != pj {
+					return pi > pj
+				}
 				return metas[i].indexMeta.Version <= metas[j].indexMeta.Version
 			})
 			log.Debug("IndexCoord assignTaskLoop", zap.Int("Unassigned tasks number", len(metas)))
+			// the busy IndexNodes are skipped till the next round
+			busyNodes := make(map[UniqueID]bool)
 			for index, meta := range metas {
 				indexBuildID := meta.indexMeta.IndexBuildID
 				if err = i.metaTable.UpdateVersion(indexBuildID); err != nil {
@@ -784,7 +825,7 @@ func (i *IndexCoord) assignTaskLoop() {
 					continue
 				}
 				log.Debug("The version of the task has been updated", zap.Int64("indexBuildID", indexBuildID))
-				nodeID, builderClient := i.nodeManager.PeekClient()
+				nodeID, builderClient := i.nodeManager.PeekClientExcept(busyNodes)
 				if builderClient == nil {
 					log.Warn("IndexCoord assignmentTasksLoop can not find available IndexNode")
 					break
@@ -799,8 +840,12 @@ func (i *IndexCoord) assignTaskLoop() {
 					DataPaths:    meta.indexMeta.Req.DataPaths,
 					TypeParams:   meta.indexMeta.Req.TypeParams,
 					IndexParams:  meta.indexMeta.Req.IndexParams,
+					FieldSchema:  meta.indexMeta.Req.FieldSchema,
+					Priority:     meta.indexMeta.Req.Priority,
+					NumRows:      meta.indexMeta.Req.NumRows,
 				}
-				if !i.assignTask(builderClient, req) {
+				nodeID, assigned := i.assignTaskToNodes(nodeID, builderClient, req, busyNodes)
+				if !assigned {
 					log.Warn("IndexCoord assignTask assign task to IndexNode failed")
 					continue
 				}
@@ -34,6 +34,7 @@ import (
 	"github.com/milvus-io/milvus/internal/proto/commonpb"
 	"github.com/milvus-io/milvus/internal/proto/indexpb"
 	"github.com/milvus-io/milvus/internal/proto/internalpb"
+	"github.com/milvus-io/milvus/internal/types"
 )
 
 func TestIndexCoord(t *testing.T) {
@@ -201,3 +202,56 @@ func TestIndexCoord(t *testing.T) {
 	err = ic.Stop()
 	assert.Nil(t, err)
 }
+
+// statusIndexNode is the IndexNode answering CreateIndex with the status of code.
+type statusIndexNode struct {
+	types.IndexNode
+	code  commonpb.ErrorCode
+	calls int
+}
+
+func (n *statusIndexNode) CreateIndex(ctx context.Context, req *indexpb.CreateIndexRequest) (*commonpb.Status, error) {
+	n.calls++
+	return &commonpb.Status{ErrorCode: n.code}, nil
+}
+
+func TestIndexCoord_assignTaskToNodes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ic := &IndexCoord{loopCtx: ctx, reqTimeoutInterval: time.Second, nodeManager: NewNodeManager()}
+	busy := &statusIndexNode{code: commonpb.ErrorCode_NodeBusy}
+	failed := &statusIndexNode{code: commonpb.ErrorCode_UnexpectedError}
+	idle := &statusIndexNode{code: commonpb.ErrorCode_Success}
+	ic.nodeManager.setClient(1, busy)
+	ic.nodeManager.setClient(2, failed)
+	ic.nodeManager.setClient(3, idle)
+	ic.nodeManager.pq.UpdatePriority(2, 1)
+	ic.nodeManager.pq.UpdatePriority(3, 2)
+	req := &indexpb.CreateIndexRequest{IndexBuildID: 1}
+
+	// the task rejected by the busy IndexNode is assigned to the next least loaded one
+	busyNodes := make(map[UniqueID]bool)
+	nodeID, client := ic.nodeManager.PeekClientExcept(busyNodes)
+	assert.Equal(t, UniqueID(1), nodeID)
+	nodeID, assigned := ic.assignTaskToNodes(nodeID, client, req, busyNodes)
+	assert.False(t, assigned)
+	assert.Equal(t, UniqueID(2), nodeID)
+	assert.Equal(t, map[UniqueID]bool{1: true}, busyNodes)
+	assert.Equal(t, 1, failed.calls)
+
+	// the busy IndexNode is skipped by the following tasks of the round
+	ic.nodeManager.pq.UpdatePriority(2, 3)
+	nodeID, client = ic.nodeManager.PeekClientExcept(busyNodes)
+	assert.Equal(t, UniqueID(3), nodeID)
+	nodeID, assigned = ic.assignTaskToNodes(nodeID, client, req, busyNodes)
+	assert.True(t, assigned)
+	assert.Equal(t, UniqueID(3), nodeID)
+	assert.Equal(t, 1, busy.calls)
+
+	// no IndexNode takes the task if all of them are busy
+	idle.code = commonpb.ErrorCode_NodeBusy
+	failed.code = commonpb.ErrorCode_NodeBusy
+	_, assigned = ic.assignTaskToNodes(1, busy, req, make(map[UniqueID]bool))
+	assert.False(t, assigned)
+	assert.Equal(t, 2, busy.calls)
+}
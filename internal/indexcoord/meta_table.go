@@ -277,6 +277,7 @@ func (mt *metaTable) GetIndexStates(indexBuildIDs []UniqueID) []*indexpb.IndexIn
 			state.IndexID = meta.indexMeta.Req.IndexID
 			state.IndexName = meta.indexMeta.Req.IndexName
 			state.Reason = meta.indexMeta.FailReason
+			state.Skipped = meta.indexMeta.Skipped
 		}
 		indexStates = append(indexStates, state)
 	}
@@ -102,6 +102,19 @@ func (nm *NodeManager) PeekClient() (UniqueID, types.IndexNode) {
 	return nodeID, client
 }
 
+// PeekClientExcept peeks the client with the least load among the IndexNodes not in @excluded.
+func (nm *NodeManager) PeekClientExcept(excluded map[UniqueID]bool) (UniqueID, types.IndexNode) {
+	nm.lock.Lock()
+	defer nm.lock.Unlock()
+
+	nodeID := nm.pq.PeekExcept(excluded)
+	client, ok := nm.nodeClients[nodeID]
+	if !ok {
+		return nodeID, nil
+	}
+	return nodeID, client
+}
+
 type indexNodeGetMetricsResponse struct {
 	resp *milvuspb.GetMetricsResponse
 	err  error
@@ -137,6 +137,27 @@ func (pq *PriorityQueue) Peek() UniqueID {
 	return pq.items[0].key
 }
 
+// PeekExcept returns the key of the item with the highest priority among the ones not in @excluded, -1 if there
+// is none.
+func (pq *PriorityQueue) PeekExcept(excluded map[UniqueID]bool) UniqueID {
+	pq.lock.RLock()
+	defer pq.lock.RUnlock()
+
+	var peeked *PQItem
+	for _, item := range pq.items {
+		if excluded[item.key] {
+			continue
+		}
+		if peeked == nil || item.priority < peeked.priority {
+			peeked = item
+		}
+	}
+	if peeked == nil {
+		return UniqueID(-1)
+	}
+	return peeked.key
+}
+
 // PeekAll return the key of all the items.
 func (pq *PriorityQueue) PeekAll() []UniqueID {
 	pq.lock.RLock()
@@ -82,3 +82,14 @@ func TestPriorityQueue_IncPriority(t *testing.T) {
 	peekKey := pq.Peek()
 	assert.Equal(t, key, peekKey)
 }
+
+func TestPriorityQueue_PeekExcept(t *testing.T) {
+	pq := newPriorityQueue()
+	assert.Equal(t, pq.Peek(), pq.PeekExcept(nil))
+	assert.Equal(t, UniqueID(2), pq.PeekExcept(map[UniqueID]bool{0: true, 1: true}))
+	excluded := make(map[UniqueID]bool)
+	for i := 0; i < QueueLen; i++ {
+		excluded[UniqueID(i)] = true
+	}
+	assert.Equal(t, UniqueID(-1), pq.PeekExcept(excluded))
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/util/funcutil"
+)
+
+// resolveLocalIP returns the IP of the first local interface within Params.PreferredCIDR, or the default local IP
+// if it's not configured or no interface matches.
+func resolveLocalIP() string {
+	if Params.PreferredCIDR != nil {
+		addrs, err := net.InterfaceAddrs()
+		if err != nil {
+			log.Warn("IndexNode failed to list the interface addresses", zap.Error(err))
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if ok && ipNet.IP.To4() != nil && Params.PreferredCIDR.Contains(ipNet.IP) {
+				return ipNet.IP.String()
+			}
+		}
+	}
+	return funcutil.GetLocalIP()
+}
+
+// advertisedAddress returns the address registered with the session, which is the address of the unix socket if
+// the grpc server listens on the unix socket only.
+func advertisedAddress() string {
+	if funcutil.IsUnixSocketAddress(Params.Address) {
+		return Params.Address
+	}
+	return Params.IP + ":" + strconv.Itoa(Params.Port)
+}
+
+// refreshAddress re-resolves the advertised IP, and rewrites the registration with the new address if it has
+// changed. It returns true if the address is updated. The address of the unix socket is never refreshed.
+func (i *IndexNode) refreshAddress() bool {
+	if funcutil.IsUnixSocketAddress(Params.Address) {
+		return false
+	}
+	ip := i.resolveIP()
+	if ip == "" || ip == Params.IP || i.session == nil {
+		return false
+	}
+	address := ip + ":" + strconv.Itoa(Params.Port)
+
+	i.sessionMu.Lock()
+	defer i.sessionMu.Unlock()
+	oldAddress := i.session.Address
+	i.session.Address = address
+	if err := i.session.UpdateRegistration(); err != nil {
+		i.session.Address = oldAddress
+		log.Warn("IndexNode failed to update the registration with the new address",
+			zap.String("address", address), zap.Error(err))
+		return false
+	}
+	Params.IP = ip
+	Params.Address = address
+	log.Info("IndexNode advertised address changed", zap.String("oldAddress", oldAddress),
+		zap.String("address", address))
+	return true
+}
+
+// addressRefreshLoop refreshes the advertised address periodically.
+func (i *IndexNode) addressRefreshLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-i.loopCtx.Done():
+			return
+		case <-ticker.C:
+			i.refreshAddress()
+		}
+	}
+}
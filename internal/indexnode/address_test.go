@@ -0,0 +1,137 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	etcdkv "github.com/milvus-io/milvus/internal/kv/etcd"
+	"github.com/milvus-io/milvus/internal/util/funcutil"
+	"github.com/milvus-io/milvus/internal/util/sessionutil"
+	"github.com/milvus-io/milvus/internal/util/typeutil"
+)
+
+func TestResolveLocalIP(t *testing.T) {
+	oldCIDR := Params.PreferredCIDR
+	defer func() {
+		Params.PreferredCIDR = oldCIDR
+	}()
+
+	Params.PreferredCIDR = nil
+	assert.Equal(t, funcutil.GetLocalIP(), resolveLocalIP())
+
+	_, Params.PreferredCIDR, _ = net.ParseCIDR("127.0.0.0/8")
+	assert.Equal(t, "127.0.0.1", resolveLocalIP())
+
+	// no interface matches
+	_, Params.PreferredCIDR, _ = net.ParseCIDR("192.0.2.0/24")
+	assert.Equal(t, funcutil.GetLocalIP(), resolveLocalIP())
+}
+
+func TestIndexNode_RefreshAddress(t *testing.T) {
+	e, endpoints := startEmbedEtcd(t)
+	defer e.Close()
+
+	Params.Init()
+	oldEndpoints, oldMetaRootPath, oldPersistNodeID := Params.EtcdEndpoints, Params.MetaRootPath, Params.PersistNodeID
+	oldIP, oldAddress, oldPort := Params.IP, Params.Address, Params.Port
+	defer func() {
+		Params.EtcdEndpoints, Params.MetaRootPath, Params.PersistNodeID = oldEndpoints, oldMetaRootPath, oldPersistNodeID
+		Params.IP, Params.Address, Params.Port = oldIP, oldAddress, oldPort
+	}()
+	Params.EtcdEndpoints = endpoints
+	Params.MetaRootPath = fmt.Sprintf("refresh-address-test-%d", time.Now().UnixNano())
+	Params.PersistNodeID = false
+	Params.IP, Params.Port = "10.0.0.1", 21121
+
+	ctx := context.Background()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	defer in.Stop()
+	// the session is not registered
+	in.resolveIP = func() string { return "10.0.0.2" }
+	assert.False(t, in.refreshAddress())
+
+	err = in.Register()
+	assert.Nil(t, err)
+
+	etcdKV, err := etcdkv.NewEtcdKV(endpoints, Params.MetaRootPath)
+	assert.Nil(t, err)
+	defer etcdKV.Close()
+	registeredAddress := func() string {
+		value, err := etcdKV.Load(path.Join(sessionutil.DefaultServiceRoot,
+			typeutil.IndexNodeRole+"-"+strconv.FormatInt(Params.NodeID, 10)))
+		assert.Nil(t, err)
+		session := &sessionutil.Session{}
+		assert.Nil(t, json.Unmarshal([]byte(value), session))
+		return session.Address
+	}
+	assert.Equal(t, "10.0.0.1:21121", registeredAddress())
+
+	assert.True(t, in.refreshAddress())
+	assert.Equal(t, "10.0.0.2", Params.IP)
+	assert.Equal(t, "10.0.0.2:21121", Params.Address)
+	assert.Equal(t, "10.0.0.2:21121", registeredAddress())
+
+	// the IP does not change, or fails to be resolved
+	assert.False(t, in.refreshAddress())
+	in.resolveIP = func() string { return "" }
+	assert.False(t, in.refreshAddress())
+	assert.Equal(t, "10.0.0.2:21121", registeredAddress())
+
+	// the registration fails to be updated after the session is gone
+	assert.Nil(t, in.session.Revoke(time.Second))
+	in.resolveIP = func() string { return "10.0.0.3" }
+	assert.False(t, in.refreshAddress())
+	assert.Equal(t, "10.0.0.2", Params.IP)
+	assert.Equal(t, "10.0.0.2:21121", in.session.Address)
+}
+
+func TestIndexNode_UnixSocketAddress(t *testing.T) {
+	e, endpoints := startEmbedEtcd(t)
+	defer e.Close()
+
+	Params.Init()
+	oldEndpoints, oldMetaRootPath, oldPersistNodeID := Params.EtcdEndpoints, Params.MetaRootPath, Params.PersistNodeID
+	oldIP, oldAddress, oldPort := Params.IP, Params.Address, Params.Port
+	defer func() {
+		Params.EtcdEndpoints, Params.MetaRootPath, Params.PersistNodeID = oldEndpoints, oldMetaRootPath, oldPersistNodeID
+		Params.IP, Params.Address, Params.Port = oldIP, oldAddress, oldPort
+	}()
+	Params.EtcdEndpoints = endpoints
+	Params.MetaRootPath = fmt.Sprintf("unix-socket-address-test-%d", time.Now().UnixNano())
+	Params.PersistNodeID = false
+	Params.IP, Params.Port = "10.0.0.1", 21121
+	Params.Address = funcutil.UnixSocketAddress("/run/milvus/indexnode.sock")
+	assert.Equal(t, Params.Address, advertisedAddress())
+
+	in, err := NewIndexNode(context.Background())
+	assert.Nil(t, err)
+	defer in.Stop()
+	assert.Nil(t, in.Register())
+	assert.Equal(t, "unix:///run/milvus/indexnode.sock", in.session.Address)
+
+	// the address of the unix socket is never refreshed by the IP
+	in.resolveIP = func() string { return "10.0.0.2" }
+	assert.False(t, in.refreshAddress())
+	assert.Equal(t, "unix:///run/milvus/indexnode.sock", in.session.Address)
+	assert.Equal(t, "10.0.0.1", Params.IP)
+}
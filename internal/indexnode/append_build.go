@@ -0,0 +1,192 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/milvus-io/milvus/internal/metrics"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/encryption"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/indexparamcheck"
+)
+
+// appendIndexTypes are the index types whose index can be loaded and appended with the vectors by the engine of
+// currentEngineVersion, the indexes of the other types are rebuilt from scratch when their segments grow.
+var appendIndexTypes = map[string]bool{
+	indexparamcheck.IndexHNSW: true,
+}
+
+// appendMatchedParams are the params the request must share with the base build, the other params are of the build
+// of the graph, which is loaded from the index files of the base build.
+var appendMatchedParams = []string{indexTypeKey, metricTypeKey, normalizeKey}
+
+// checkAppendBuild rejects the request appending to the index of a base build whose index type does not support
+// append, which fails permanently with UNSUPPORTED, so that the index is rebuilt from scratch instead. The malformed
+// params are left to fail the task.
+func checkAppendBuild(req *indexpb.CreateIndexRequest) error {
+	baseID := req.GetBaseIndexBuildID()
+	if baseID == 0 {
+		return nil
+	}
+	if baseID == req.GetIndexBuildID() {
+		return errorcode.Errorf(errorcode.InvalidParams, "the index of build %d can't be appended to itself", baseID)
+	}
+	_, indexParams, err := parseBuildParams(req)
+	if err != nil {
+		return nil
+	}
+	if !appendIndexTypes[strings.ToUpper(indexParams[indexTypeKey])] {
+		return errorcode.Errorf(errorcode.Unsupported,
+			"index type %s does not support appending to the index of build %d, rebuild the index from scratch instead",
+			indexParams[indexTypeKey], baseID)
+	}
+	return nil
+}
+
+// dimOf returns the dim of the params, which is in either the type params or the index params.
+func dimOf(typeParams, indexParams map[string]string) string {
+	if dim, ok := typeParams[dimKey]; ok {
+		return dim
+	}
+	return indexParams[dimKey]
+}
+
+// loadAppendBase loads the index meta of the base build the vectors of the task are appended to, which must be
+// finished with the same params and loadable by the current engine. The vectors must be of the segment of the base.
+func (it *IndexBuildTask) loadAppendBase(typeParams, indexParams map[string]string) (*indexpb.IndexMeta, error) {
+	baseID := it.req.GetBaseIndexBuildID()
+	value, err := it.etcdKV.Load(path.Join(indexMetaPrefix, strconv.FormatInt(baseID, 10)))
+	if err != nil && etcdErrorCategory(err) != metrics.IndexNodeEtcdErrorNotFound {
+		return nil, etcdError(fmt.Errorf("failed to load the index meta of the base build %d: %w", baseID, err))
+	}
+	if err != nil || value == "" {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "the index meta of the base build %d is not found", baseID)
+	}
+	base := &indexpb.IndexMeta{}
+	if err := proto.Unmarshal([]byte(value), base); err != nil {
+		return nil, err
+	}
+	if base.MarkDeleted || base.State != commonpb.IndexState_Finished {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "the base build %d is not finished, whose state is %s",
+			baseID, base.State.String())
+	}
+	if err := checkArtifactCompatibility(base.ArtifactVersion, currentArtifactVersion()); err != nil {
+		return nil, errorcode.Errorf(errorcode.Unsupported, "the index of the base build %d can't be appended to: %w",
+			baseID, err)
+	}
+
+	baseTypeParams, err := parseKeyValueParams(base.GetReq().GetTypeParams())
+	if err != nil {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "%w in type params of the base build %d", err, baseID)
+	}
+	baseIndexParams, err := parseKeyValueParams(base.GetReq().GetIndexParams())
+	if err != nil {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "%w in index params of the base build %d", err, baseID)
+	}
+	if dim, baseDim := dimOf(typeParams, indexParams), dimOf(baseTypeParams, baseIndexParams); dim != baseDim {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "the dim %s differs from the dim %s of the base build %d",
+			dim, baseDim, baseID)
+	}
+	for _, key := range appendMatchedParams {
+		if indexParams[key] != baseIndexParams[key] {
+			return nil, errorcode.Errorf(errorcode.InvalidParams, "the %s %q differs from the %s %q of the base build %d",
+				key, indexParams[key], key, baseIndexParams[key], baseID)
+		}
+	}
+
+	if len(it.req.GetDataPaths()) > 0 && len(base.GetReq().GetDataPaths()) > 0 {
+		_, _, segmentID, err := segmentOfPath(it.req.DataPaths[0])
+		if err != nil {
+			return nil, err
+		}
+		_, _, baseSegmentID, err := segmentOfPath(base.Req.DataPaths[0])
+		if err != nil {
+			return nil, err
+		}
+		if segmentID != baseSegmentID {
+			return nil, errorcode.Errorf(errorcode.InvalidParams, "the segment %d differs from the segment %d of the base build %d",
+				segmentID, baseSegmentID, baseID)
+		}
+	}
+	return base, nil
+}
+
+// loadBaseIndex downloads the index files of @base and loads them into the index of the task, the encrypted ones are
+// decrypted by the data key unwrapped by the keyring of the node.
+func (it *IndexBuildTask) loadBaseIndex(base *indexpb.IndexMeta) error {
+	var dataKey []byte
+	if encrypted := base.Encryption; encrypted != nil {
+		if Params.EncryptionKeyring == nil {
+			return errorcode.Errorf(errorcode.InvalidParams, "the index files of the base build %d are encrypted by the key %s, "+
+				"but the encryption is not enabled", base.IndexBuildID, encrypted.KeyId)
+		}
+		var err error
+		dataKey, err = Params.EncryptionKeyring.UnwrapDataKey(encrypted.KeyId, encrypted.WrappedKey)
+		if err != nil {
+			return errorcode.Wrap(errorcode.InvalidParams, err)
+		}
+	}
+
+	blobs := make([]*Blob, 0, len(base.IndexFilePaths))
+	var loadedBytes int64
+	for _, filePath := range base.IndexFilePaths {
+		value, err := it.kv.Load(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to load the index file %s of the base build %d: %w", filePath, base.IndexBuildID, storageError(err))
+		}
+		data := []byte(value)
+		if dataKey != nil {
+			if data, err = encryption.Decrypt(dataKey, data); err != nil {
+				return errorcode.Errorf(errorcode.InvalidParams, "failed to decrypt the index file %s of the base build %d: %w",
+					filePath, base.IndexBuildID, err)
+			}
+		}
+		loadedBytes += int64(len(value))
+		blobs = append(blobs, &Blob{Key: path.Base(filePath), Value: data})
+	}
+	codec := storage.NewIndexFileBinlogCodec()
+	indexBlobs, _, _, _, err := codec.Deserialize(blobs)
+	if err != nil {
+		return errorcode.Errorf(errorcode.InvalidParams, "failed to deserialize the index files of the base build %d: %w",
+			base.IndexBuildID, err)
+	}
+	_ = codec.Close()
+	if err := it.index.Load(indexBlobs); err != nil {
+		return err
+	}
+	it.loadedBytes += loadedBytes
+	return nil
+}
+
+// appendFloatVectors appends the float vectors to the index loaded from the base build, which must be of the element
+// type of the vectors the base is built from.
+func (it *IndexBuildTask) appendFloatVectors(vectors []float32) error {
+	if baseType := it.base.GetElementType(); baseType != schemapb.DataType_None && baseType != it.elementType {
+		return errorcode.Errorf(errorcode.InvalidParams, "the vectors of %s differ from the vectors of %s the base build %d is built from",
+			it.elementType.String(), baseType.String(), it.base.IndexBuildID)
+	}
+	index, ok := it.index.(AppendIndex)
+	if !ok {
+		return errorcode.Errorf(errorcode.Unsupported, "the index of the base build %d does not support append", it.base.IndexBuildID)
+	}
+	return index.AppendFloatVecIndex(vectors)
+}
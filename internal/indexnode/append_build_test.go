@@ -0,0 +1,258 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	etcdkv "github.com/milvus-io/milvus/internal/kv/etcd"
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/encryption"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+// mockAppendIndex records the index files it is loaded with and the vectors appended to it.
+type mockAppendIndex struct {
+	mockIncrementalIndex
+	loaded   []*Blob
+	appended []float32
+}
+
+func (index *mockAppendIndex) Load(blobs []*Blob) error {
+	index.loaded = blobs
+	return nil
+}
+
+func (index *mockAppendIndex) AppendFloatVecIndex(vectors []float32) error {
+	index.appended = append(index.appended, vectors...)
+	return nil
+}
+
+func newAppendTestRequest(indexType string) *indexpb.CreateIndexRequest {
+	return &indexpb.CreateIndexRequest{
+		IndexBuildID:     2,
+		BaseIndexBuildID: 1,
+		DataPaths:        []string{"insert_log/1/2/3/100/10"},
+		TypeParams:       []*commonpb.KeyValuePair{{Key: dimKey, Value: "8"}},
+		IndexParams: []*commonpb.KeyValuePair{
+			{Key: indexTypeKey, Value: indexType},
+			{Key: metricTypeKey, Value: "L2"},
+			{Key: "M", Value: "16"},
+			{Key: "efConstruction", Value: "200"},
+		},
+	}
+}
+
+func TestCheckAppendBuild(t *testing.T) {
+	assert.Nil(t, checkAppendBuild(&indexpb.CreateIndexRequest{IndexBuildID: 2}))
+	assert.Nil(t, checkAppendBuild(newAppendTestRequest("HNSW")))
+	assert.Nil(t, checkAppendBuild(newAppendTestRequest("hnsw")))
+
+	// the index types without append support are rebuilt from scratch
+	for _, indexType := range []string{"IVF_FLAT", "FLAT", "RHNSW_FLAT", "ANNOY"} {
+		err := checkAppendBuild(newAppendTestRequest(indexType))
+		assert.Equal(t, errorcode.Unsupported, classifyError(err), indexType)
+		assert.False(t, isRetryableOnOtherNode(err))
+	}
+
+	req := newAppendTestRequest("HNSW")
+	req.BaseIndexBuildID = req.IndexBuildID
+	assert.Equal(t, errorcode.InvalidParams, classifyError(checkAppendBuild(req)))
+
+	// the malformed params are left to fail the task
+	req = newAppendTestRequest("IVF_FLAT")
+	req.IndexParams = append(req.IndexParams, &commonpb.KeyValuePair{Key: indexTypeKey, Value: "HNSW"})
+	assert.Nil(t, checkAppendBuild(req))
+}
+
+func TestIndexBuildTask_loadAppendBase(t *testing.T) {
+	e, endpoints := startEmbedEtcd(t)
+	defer e.Close()
+	client, err := etcdkv.NewEtcdKV(endpoints, "/append-base")
+	assert.Nil(t, err)
+	defer client.Close()
+
+	newBase := func() *indexpb.IndexMeta {
+		req := newAppendTestRequest("HNSW")
+		return &indexpb.IndexMeta{
+			IndexBuildID:    1,
+			Version:         3,
+			State:           commonpb.IndexState_Finished,
+			ArtifactVersion: currentArtifactVersion(),
+			Req: &indexpb.BuildIndexRequest{
+				IndexBuildID: 1,
+				DataPaths:    []string{"insert_log/1/2/3/100/1"},
+				TypeParams:   req.TypeParams,
+				IndexParams:  req.IndexParams,
+			},
+		}
+	}
+	saveBase := func(base *indexpb.IndexMeta) {
+		value, err := proto.Marshal(base)
+		assert.Nil(t, err)
+		assert.Nil(t, client.Save(path.Join(indexMetaPrefix, "1"), string(value)))
+	}
+	loadBase := func(req *indexpb.CreateIndexRequest) (*indexpb.IndexMeta, error) {
+		it := &IndexBuildTask{etcdKV: newMetricsEtcdKV(client), req: req}
+		typeParams, indexParams, err := parseBuildParams(req)
+		assert.Nil(t, err)
+		return it.loadAppendBase(typeParams, indexParams)
+	}
+
+	_, err = loadBase(newAppendTestRequest("HNSW"))
+	assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+
+	saveBase(newBase())
+	base, err := loadBase(newAppendTestRequest("HNSW"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), base.IndexBuildID)
+	assert.Equal(t, int64(3), base.Version)
+
+	// the params of the graph are loaded from the base
+	req := newAppendTestRequest("HNSW")
+	req.IndexParams[2].Value = "32"
+	_, err = loadBase(req)
+	assert.Nil(t, err)
+
+	for name, c := range map[string]struct {
+		base func(base *indexpb.IndexMeta)
+		req  func(req *indexpb.CreateIndexRequest)
+		code errorcode.Code
+	}{
+		"in progress": {base: func(base *indexpb.IndexMeta) { base.State = commonpb.IndexState_InProgress }, code: errorcode.InvalidParams},
+		"deleted":     {base: func(base *indexpb.IndexMeta) { base.MarkDeleted = true }, code: errorcode.InvalidParams},
+		"newer engine": {
+			base: func(base *indexpb.IndexMeta) { base.ArtifactVersion.EngineVersion = currentEngineVersion + 1 },
+			code: errorcode.Unsupported,
+		},
+		"dim":         {req: func(req *indexpb.CreateIndexRequest) { req.TypeParams[0].Value = "16" }, code: errorcode.InvalidParams},
+		"metric type": {req: func(req *indexpb.CreateIndexRequest) { req.IndexParams[1].Value = "IP" }, code: errorcode.InvalidParams},
+		"normalize": {
+			req: func(req *indexpb.CreateIndexRequest) {
+				req.IndexParams = append(req.IndexParams, &commonpb.KeyValuePair{Key: normalizeKey, Value: "true"})
+			},
+			code: errorcode.InvalidParams,
+		},
+		"segment": {req: func(req *indexpb.CreateIndexRequest) { req.DataPaths = []string{"insert_log/1/2/4/100/10"} }, code: errorcode.InvalidParams},
+	} {
+		base, req := newBase(), newAppendTestRequest("HNSW")
+		if c.base != nil {
+			c.base(base)
+		}
+		if c.req != nil {
+			c.req(req)
+		}
+		saveBase(base)
+		_, err := loadBase(req)
+		assert.Equal(t, c.code, classifyError(err), name)
+	}
+}
+
+func TestIndexBuildTask_loadBaseIndex(t *testing.T) {
+	old := Params.EncryptionKeyring
+	defer func() {
+		Params.EncryptionKeyring = old
+	}()
+	keyring, err := encryption.NewKeyring([]encryption.KEK{newTestKEK(t, "k1")})
+	assert.Nil(t, err)
+
+	indexBlobs := []*Blob{{Key: "HNSW", Value: []byte("the hnsw graph")}}
+	codec := storage.NewIndexFileBinlogCodec()
+	serialized, err := codec.Serialize(1, 3, 10, 20, 30, 100, map[string]string{indexTypeKey: "HNSW"}, "index", 1000, indexBlobs)
+	assert.Nil(t, err)
+
+	for _, encrypted := range []bool{false, true} {
+		Params.EncryptionKeyring = nil
+		base := &indexpb.IndexMeta{IndexBuildID: 1, Version: 3}
+		files := make([]*Blob, len(serialized))
+		for i, blob := range serialized {
+			files[i] = &Blob{Key: blob.Key, Value: append([]byte{}, blob.Value...)}
+		}
+		if encrypted {
+			Params.EncryptionKeyring = keyring
+			dataKey, err := encryptBlobs(keyring, files)
+			assert.Nil(t, err)
+			base.Encryption = indexEncryptionOf(dataKey)
+		}
+		storageKV := memkv.NewMemoryKV()
+		for _, file := range files {
+			filePath := path.Join("index_files/1/3/20/30", file.Key)
+			assert.Nil(t, storageKV.Save(filePath, string(file.Value)))
+			base.IndexFilePaths = append(base.IndexFilePaths, filePath)
+		}
+
+		index := &mockAppendIndex{}
+		it := &IndexBuildTask{index: index, kv: storageKV, req: &indexpb.CreateIndexRequest{IndexBuildID: 2}}
+		assert.Nil(t, it.loadBaseIndex(base))
+		assert.Equal(t, 1, len(index.loaded))
+		assert.Equal(t, "HNSW", index.loaded[0].Key)
+		assert.Equal(t, []byte("the hnsw graph"), index.loaded[0].Value)
+		assert.Greater(t, it.loadedBytes, int64(0))
+	}
+}
+
+func TestIndexBuildTask_loadBaseIndex_errors(t *testing.T) {
+	old := Params.EncryptionKeyring
+	defer func() {
+		Params.EncryptionKeyring = old
+	}()
+
+	base := &indexpb.IndexMeta{IndexBuildID: 1, Version: 3, IndexFilePaths: []string{"index_files/1/3/20/30/HNSW"}}
+	it := &IndexBuildTask{
+		index: &mockAppendIndex{},
+		kv:    &faultyLoadKV{MemoryKV: memkv.NewMemoryKV(), loadErr: errors.New("connection reset")},
+		req:   &indexpb.CreateIndexRequest{IndexBuildID: 2},
+	}
+	assert.Equal(t, errorcode.StorageTransient, classifyError(it.loadBaseIndex(base)))
+
+	// the encrypted index files are never loaded without the keyring
+	Params.EncryptionKeyring = nil
+	base.Encryption = &indexpb.IndexEncryption{Algorithm: encryption.Algorithm, KeyId: "k1", WrappedKey: []byte("wrapped")}
+	assert.Equal(t, errorcode.InvalidParams, classifyError(it.loadBaseIndex(base)))
+	keyring, err := encryption.NewKeyring([]encryption.KEK{newTestKEK(t, "k2")})
+	assert.Nil(t, err)
+	Params.EncryptionKeyring = keyring
+	assert.Equal(t, errorcode.InvalidParams, classifyError(it.loadBaseIndex(base)))
+}
+
+func TestIndexBuildTask_appendFloatVectors(t *testing.T) {
+	index := &mockAppendIndex{}
+	it := &IndexBuildTask{
+		index:       index,
+		base:        &indexpb.IndexMeta{IndexBuildID: 1, ElementType: schemapb.DataType_FloatVector},
+		elementType: schemapb.DataType_FloatVector,
+	}
+	assert.Nil(t, it.appendFloatVectors([]float32{1, 2, 3, 4}))
+	assert.Equal(t, []float32{1, 2, 3, 4}, index.appended)
+
+	// the base built before the element type is recorded
+	it.base.ElementType = schemapb.DataType_None
+	assert.Nil(t, it.appendFloatVectors([]float32{5, 6, 7, 8}))
+	assert.Equal(t, 8, len(index.appended))
+
+	it.elementType = schemapb.DataType_Float16Vector
+	it.base.ElementType = schemapb.DataType_FloatVector
+	assert.Equal(t, errorcode.InvalidParams, classifyError(it.appendFloatVectors([]float32{1, 2, 3, 4})))
+
+	it.elementType = schemapb.DataType_FloatVector
+	it.index = &mockIncrementalIndex{}
+	assert.Equal(t, errorcode.Unsupported, classifyError(it.appendFloatVectors([]float32{1, 2, 3, 4})))
+}
@@ -0,0 +1,267 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"path"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/kv"
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/metrics"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+const (
+	// benchmarkObjectPrefix is the scratch prefix of the index files uploaded by the benchmarks under the index root path
+	benchmarkObjectPrefix = ".benchmark"
+	// maxBenchmarkVectorBytes bounds the synthetic vectors of a benchmark, which are all kept in memory
+	maxBenchmarkVectorBytes = 4 * 1024 * 1024 * 1024
+)
+
+// buildBenchmark builds an index of the synthetic vectors generated in memory, and optionally serializes and uploads
+// it to a scratch prefix, which is removed once it finishes. It never reads or writes the index meta.
+type buildBenchmark struct {
+	storage  kv.BaseKV
+	simd     *simdSwitcher
+	newIndex func(typeParams, indexParams map[string]string) (Index, error)
+
+	// req is the build request of the synthetic vectors, whose params are validated as the ones of the dry runs
+	req         *indexpb.CreateIndexRequest
+	typeParams  map[string]string
+	indexParams map[string]string
+	rows        int64
+	dim         int64
+	upload      bool
+	prefix      string
+}
+
+func newBuildBenchmark(storage kv.BaseKV, simd *simdSwitcher, req *indexpb.RunBuildBenchmarkRequest) (*buildBenchmark, error) {
+	if req.Rows <= 0 {
+		return nil, fmt.Errorf("invalid rows %d, expect a positive integer", req.Rows)
+	}
+	indexParams := make([]*commonpb.KeyValuePair, 0, len(req.Params)+1)
+	for _, pair := range req.Params {
+		if pair.Key != indexTypeKey {
+			indexParams = append(indexParams, pair)
+		}
+	}
+	indexParams = append(indexParams, &commonpb.KeyValuePair{Key: indexTypeKey, Value: req.IndexType})
+	buildReq := &indexpb.CreateIndexRequest{
+		IndexName:   benchmarkObjectPrefix,
+		TypeParams:  []*commonpb.KeyValuePair{{Key: dimKey, Value: strconv.FormatInt(req.Dim, 10)}},
+		IndexParams: indexParams,
+	}
+	check := &dryRun{req: buildReq, simd: simd, resp: &indexpb.DryRunCreateIndexResponse{}}
+	if _, err := check.checkParams(); err != nil {
+		return nil, err
+	}
+	if isDiskIndexType(req.IndexType) {
+		return nil, fmt.Errorf("the disk index type %s is not supported by the benchmark", req.IndexType)
+	}
+	rowBytes := req.Dim * 4
+	if isBinaryIndexType(req.IndexType) {
+		rowBytes = int64(binaryVectorBytes(int(req.Dim)))
+	}
+	if req.Rows > maxBenchmarkVectorBytes/rowBytes {
+		return nil, fmt.Errorf("the vectors of %d rows of dim %d exceed %d bytes", req.Rows, req.Dim,
+			int64(maxBenchmarkVectorBytes))
+	}
+	return &buildBenchmark{
+		storage:     storage,
+		simd:        simd,
+		newIndex:    NewCIndex,
+		req:         buildReq,
+		typeParams:  check.typeParams,
+		indexParams: check.indexParams,
+		rows:        req.Rows,
+		dim:         req.Dim,
+		upload:      req.Upload,
+		prefix: path.Join(Params.IndexRootPath, benchmarkObjectPrefix, strconv.FormatInt(Params.NodeID, 10),
+			strconv.FormatInt(time.Now().UnixNano(), 10)),
+	}, nil
+}
+
+// run runs the benchmark and returns the measures of it.
+func (b *buildBenchmark) run(ctx context.Context) (*indexpb.RunBuildBenchmarkResponse, error) {
+	indexType := b.indexParams[indexTypeKey]
+	resp := &indexpb.RunBuildBenchmarkResponse{
+		Status:    &commonpb.Status{ErrorCode: commonpb.ErrorCode_Success},
+		IndexType: indexType,
+		SimdType:  Params.EffectiveSimdType,
+		Rows:      b.rows,
+		Dim:       b.dim,
+	}
+	normalize, err := normalizeOf(b.indexParams)
+	if err != nil {
+		return nil, err
+	}
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var floatVectors []float32
+	var binaryVectors []byte
+	if isBinaryIndexType(indexType) {
+		binaryVectors = make([]byte, b.rows*int64(binaryVectorBytes(int(b.dim))))
+		random.Read(binaryVectors)
+	} else {
+		floatVectors = make([]float32, b.rows*b.dim)
+		for idx := range floatVectors {
+			floatVectors[idx] = random.Float32()
+		}
+		if normalize {
+			normalizeVectors(floatVectors, int(b.dim))
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if b.simd != nil {
+		resp.SimdType = b.simd.acquire(Params.simdTypeOf(indexType))
+		defer b.simd.release()
+	}
+	engineIndexParams := engineConfigOf(indexType, b.indexParams)
+	delete(engineIndexParams, normalizeKey)
+	index, err := b.newIndex(b.typeParams, engineIndexParams)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := index.Delete(); err != nil {
+			log.Warn("IndexNode benchmark failed to delete the index", zap.Error(err))
+		}
+	}()
+	start := time.Now()
+	if binaryVectors != nil {
+		err = index.BuildBinaryVecIndexWithoutIds(binaryVectors)
+	} else {
+		err = index.BuildFloatVecIndexWithoutIds(floatVectors)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the index: %w", err)
+	}
+	buildDuration := time.Since(start)
+	resp.BuildDurationMs = buildDuration.Milliseconds()
+	resp.BuildRowsPerSecond = perSecond(float64(b.rows), buildDuration)
+	if !b.upload {
+		return resp, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	start = time.Now()
+	blobs, err := serializeIndexFiles(index, b.req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize the index: %w", err)
+	}
+	resp.SerializeDurationMs = time.Since(start).Milliseconds()
+	for _, blob := range blobs {
+		resp.UploadBytes += int64(len(blob.Value))
+	}
+	defer func() {
+		if err := b.storage.RemoveWithPrefix(b.prefix); err != nil {
+			log.Warn("IndexNode benchmark failed to remove the uploaded index files", zap.String("prefix", b.prefix),
+				zap.Error(err))
+		}
+	}()
+	start = time.Now()
+	if err := uploadIndexFiles(b.storage, b.prefix, b.req, blobs); err != nil {
+		return nil, storageError(err)
+	}
+	uploadDuration := time.Since(start)
+	resp.UploadDurationMs = uploadDuration.Milliseconds()
+	resp.UploadMbPerSecond = perSecond(float64(resp.UploadBytes)/(1024*1024), uploadDuration)
+	return resp, nil
+}
+
+// perSecond returns the rate of @amount in @duration.
+func perSecond(amount float64, duration time.Duration) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return amount / duration.Seconds()
+}
+
+// recordBenchmark exports the measures of the benchmark as the metrics, the upload measures are kept if the
+// benchmark uploads nothing.
+func recordBenchmark(resp *indexpb.RunBuildBenchmarkResponse) {
+	measures := map[string]float64{
+		metrics.IndexNodeBenchmarkBuildSeconds:       float64(resp.BuildDurationMs) / 1000,
+		metrics.IndexNodeBenchmarkBuildRowsPerSecond: resp.BuildRowsPerSecond,
+	}
+	if resp.UploadBytes > 0 {
+		measures[metrics.IndexNodeBenchmarkUploadSeconds] = float64(resp.UploadDurationMs) / 1000
+		measures[metrics.IndexNodeBenchmarkUploadMBPerSecond] = resp.UploadMbPerSecond
+	}
+	for measure, value := range measures {
+		metrics.IndexNodeBenchmarkResult.WithLabelValues(resp.IndexType, measure).Set(value)
+	}
+}
+
+var errBenchmarkRunning = errorcode.New(errorcode.Busy, "another build benchmark is running")
+
+// RunBuildBenchmark builds an index of the synthetic vectors to measure the build throughput of the node, the index
+// meta is never touched. The benchmark requires the node to have no tasks unless it's forced, and the admission of
+// new tasks is paused while it runs so that they never share the node with it.
+func (i *IndexNode) RunBuildBenchmark(ctx context.Context, req *indexpb.RunBuildBenchmarkRequest) (*indexpb.RunBuildBenchmarkResponse, error) {
+	failed := func(code commonpb.ErrorCode, err error) (*indexpb.RunBuildBenchmarkResponse, error) {
+		return &indexpb.RunBuildBenchmarkResponse{
+			Status: &commonpb.Status{ErrorCode: code, Reason: failureReason(err)},
+		}, nil
+	}
+	if !i.isHealthy() {
+		return &indexpb.RunBuildBenchmarkResponse{Status: i.notReadyStatus()}, nil
+	}
+	b, err := newBuildBenchmark(i.kv, i.simd, req)
+	if err != nil {
+		return failed(commonpb.ErrorCode_IllegalArgument, errorcode.Wrap(errorcode.InvalidParams, err))
+	}
+	if !atomic.CompareAndSwapInt32(&i.benchmarking, 0, 1) {
+		return failed(commonpb.ErrorCode_UnexpectedError, errBenchmarkRunning)
+	}
+	defer atomic.StoreInt32(&i.benchmarking, 0)
+	// the admission is paused before the tasks are checked, so that no task is admitted in between
+	if i.admission != nil {
+		i.admission.update(watermarkBenchmark, true, false, "a build benchmark is running")
+		defer i.admission.update(watermarkBenchmark, false, true, "")
+	}
+	if !req.Force {
+		if queued, active := i.sched.IndexBuildQueue.utLen(), i.sched.IndexBuildQueue.atLen(); queued+active > 0 {
+			return failed(commonpb.ErrorCode_UnexpectedError, errorcode.Errorf(errorcode.Busy,
+				"the node has %d queued and %d active tasks, force the benchmark to run it anyway", queued, active))
+		}
+	}
+
+	log.Info("IndexNode runs the build benchmark", zap.String("indexType", req.IndexType), zap.Int64("rows", req.Rows),
+		zap.Int64("dim", req.Dim), zap.Bool("upload", req.Upload), zap.Bool("force", req.Force))
+	resp, err := b.run(ctx)
+	if err != nil {
+		log.Warn("IndexNode build benchmark failed", zap.String("indexType", req.IndexType), zap.Error(err))
+		return failed(commonpb.ErrorCode_UnexpectedError, err)
+	}
+	recordBenchmark(resp)
+	log.Info("IndexNode build benchmark finished", zap.String("indexType", resp.IndexType),
+		zap.String("simdType", resp.SimdType), zap.Int64("rows", resp.Rows), zap.Int64("dim", resp.Dim),
+		zap.Int64("buildDurationMs", resp.BuildDurationMs), zap.Float64("buildRowsPerSecond", resp.BuildRowsPerSecond),
+		zap.Int64("uploadBytes", resp.UploadBytes), zap.Int64("uploadDurationMs", resp.UploadDurationMs),
+		zap.Float64("uploadMBPerSecond", resp.UploadMbPerSecond))
+	return resp, nil
+}
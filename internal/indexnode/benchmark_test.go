@@ -0,0 +1,220 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
+	"github.com/milvus-io/milvus/internal/metrics"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+func newBenchmarkRequest(indexType string, metricType string) *indexpb.RunBuildBenchmarkRequest {
+	return &indexpb.RunBuildBenchmarkRequest{
+		Rows:      100,
+		Dim:       pipelineTestDim,
+		IndexType: indexType,
+		Params:    []*commonpb.KeyValuePair{{Key: "metric_type", Value: metricType}},
+	}
+}
+
+func newTestBuildBenchmark(t *testing.T, storage *recordingKV, req *indexpb.RunBuildBenchmarkRequest, index Index) *buildBenchmark {
+	b, err := newBuildBenchmark(storage, nil, req)
+	assert.Nil(t, err)
+	b.newIndex = func(typeParams, indexParams map[string]string) (Index, error) {
+		if index == nil {
+			return nil, errors.New("the engine is not loaded")
+		}
+		return index, nil
+	}
+	return b
+}
+
+func TestNewBuildBenchmark(t *testing.T) {
+	storage := &recordingKV{MemoryKV: memkv.NewMemoryKV()}
+	b, err := newBuildBenchmark(storage, nil, newBenchmarkRequest("FLAT", "L2"))
+	assert.Nil(t, err)
+	assert.Equal(t, "FLAT", b.indexParams[indexTypeKey])
+	assert.True(t, strings.Contains(b.prefix, benchmarkObjectPrefix))
+
+	// the index type in the params is overridden by the one of the request
+	req := newBenchmarkRequest("FLAT", "L2")
+	req.Params = append(req.Params, &commonpb.KeyValuePair{Key: indexTypeKey, Value: "IVF_FLAT"})
+	b, err = newBuildBenchmark(storage, nil, req)
+	assert.Nil(t, err)
+	assert.Equal(t, "FLAT", b.indexParams[indexTypeKey])
+
+	noRows, tooManyRows := newBenchmarkRequest("FLAT", "L2"), newBenchmarkRequest("FLAT", "L2")
+	noRows.Rows = 0
+	tooManyRows.Rows = maxBenchmarkVectorBytes
+	invalids := []*indexpb.RunBuildBenchmarkRequest{
+		noRows,
+		tooManyRows,
+		newBenchmarkRequest("UNKNOWN", "L2"),
+		newBenchmarkRequest("FLAT", "JACCARD"),
+		newBenchmarkRequest("DISKANN", "L2"),
+	}
+	for _, req := range invalids {
+		_, err := newBuildBenchmark(storage, nil, req)
+		assert.NotNil(t, err, req.String())
+	}
+}
+
+func TestBuildBenchmark(t *testing.T) {
+	ctx := context.Background()
+	storage := &recordingKV{MemoryKV: memkv.NewMemoryKV()}
+	index := &serializedIncrementalIndex{}
+	resp, err := newTestBuildBenchmark(t, storage, newBenchmarkRequest("FLAT", "L2"), index).run(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "FLAT", resp.IndexType)
+	assert.Equal(t, int64(100), resp.Rows)
+	assert.Equal(t, int64(pipelineTestDim), resp.Dim)
+	assert.Equal(t, 100*pipelineTestDim, len(index.floatData))
+	assert.Zero(t, resp.UploadBytes)
+	assert.Empty(t, storage.saved)
+
+	t.Run("upload", func(t *testing.T) {
+		storage := &recordingKV{MemoryKV: memkv.NewMemoryKV()}
+		req := newBenchmarkRequest("FLAT", "L2")
+		req.Upload = true
+		b := newTestBuildBenchmark(t, storage, req, &serializedIncrementalIndex{})
+		resp, err := b.run(ctx)
+		assert.Nil(t, err)
+		assert.True(t, resp.UploadBytes > 0)
+		assert.NotEmpty(t, storage.saved)
+		for _, key := range storage.saved {
+			assert.True(t, strings.HasPrefix(key, b.prefix), key)
+		}
+		// the uploaded index files are removed once the benchmark finishes
+		keys, _, err := storage.LoadWithPrefix("")
+		assert.Nil(t, err)
+		assert.Empty(t, keys)
+
+		// the removal failure never fails the benchmark
+		storage = &recordingKV{MemoryKV: memkv.NewMemoryKV(), removeErr: errors.New("remove failed")}
+		_, err = newTestBuildBenchmark(t, storage, req, &serializedIncrementalIndex{}).run(ctx)
+		assert.Nil(t, err)
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		index := &mockIncrementalIndex{}
+		resp, err := newTestBuildBenchmark(t, storage, newBenchmarkRequest("BIN_FLAT", "JACCARD"), index).run(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, "BIN_FLAT", resp.IndexType)
+		assert.Equal(t, 100*pipelineTestDim/8, len(index.binaryData))
+	})
+
+	t.Run("normalize", func(t *testing.T) {
+		req := newBenchmarkRequest("FLAT", "IP")
+		req.Params = append(req.Params, &commonpb.KeyValuePair{Key: normalizeKey, Value: "true"})
+		index := &serializedIncrementalIndex{}
+		_, err := newTestBuildBenchmark(t, storage, req, index).run(ctx)
+		assert.Nil(t, err)
+		var norm float64
+		for _, v := range index.floatData[:pipelineTestDim] {
+			norm += float64(v) * float64(v)
+		}
+		assert.InDelta(t, 1.0, norm, 1e-5)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		_, err := newTestBuildBenchmark(t, storage, newBenchmarkRequest("FLAT", "L2"), nil).run(ctx)
+		assert.NotNil(t, err)
+
+		index := &serializedIncrementalIndex{}
+		index.addErr = errors.New("build failed")
+		_, err = newTestBuildBenchmark(t, storage, newBenchmarkRequest("FLAT", "L2"), index).run(ctx)
+		assert.NotNil(t, err)
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = newTestBuildBenchmark(t, storage, newBenchmarkRequest("FLAT", "L2"), &serializedIncrementalIndex{}).run(cancelled)
+		assert.Equal(t, errorcode.Cancelled, classifyError(err))
+	})
+}
+
+func TestRecordBenchmark(t *testing.T) {
+	recordBenchmark(&indexpb.RunBuildBenchmarkResponse{IndexType: "BENCHMARK_TEST", BuildDurationMs: 1500,
+		BuildRowsPerSecond: 1000})
+	assert.Equal(t, 1.5, testutil.ToFloat64(metrics.IndexNodeBenchmarkResult.WithLabelValues("BENCHMARK_TEST",
+		metrics.IndexNodeBenchmarkBuildSeconds)))
+	assert.Equal(t, 1000.0, testutil.ToFloat64(metrics.IndexNodeBenchmarkResult.WithLabelValues("BENCHMARK_TEST",
+		metrics.IndexNodeBenchmarkBuildRowsPerSecond)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.IndexNodeBenchmarkResult.WithLabelValues("BENCHMARK_TEST",
+		metrics.IndexNodeBenchmarkUploadMBPerSecond)))
+
+	recordBenchmark(&indexpb.RunBuildBenchmarkResponse{IndexType: "BENCHMARK_TEST", BuildDurationMs: 1500,
+		UploadBytes: 1024, UploadDurationMs: 500, UploadMbPerSecond: 2})
+	assert.Equal(t, 0.5, testutil.ToFloat64(metrics.IndexNodeBenchmarkResult.WithLabelValues("BENCHMARK_TEST",
+		metrics.IndexNodeBenchmarkUploadSeconds)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.IndexNodeBenchmarkResult.WithLabelValues("BENCHMARK_TEST",
+		metrics.IndexNodeBenchmarkUploadMBPerSecond)))
+
+	assert.Equal(t, 0.0, perSecond(100, 0))
+	assert.Equal(t, 200.0, perSecond(100, 500*time.Millisecond))
+}
+
+func TestIndexNode_RunBuildBenchmark(t *testing.T) {
+	ctx := context.Background()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	in.kv = memkv.NewMemoryKV()
+	req := newBenchmarkRequest("FLAT", "L2")
+	resp, err := in.RunBuildBenchmark(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.Status.ErrorCode)
+
+	in.probe.update(probeEtcdSession, nil)
+	in.probe.update(probeStorage, nil)
+	in.UpdateStateCode(internalpb.StateCode_Healthy)
+	in.admission = newAdmissionGuard(watermarks{}, nil, nil)
+
+	resp, err = in.RunBuildBenchmark(ctx, newBenchmarkRequest("UNKNOWN", "L2"))
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_IllegalArgument, resp.Status.ErrorCode)
+	code, _ := errorcode.Parse(resp.Status.Reason)
+	assert.Equal(t, errorcode.InvalidParams, code)
+
+	// only one benchmark runs at a time
+	in.benchmarking = 1
+	resp, err = in.RunBuildBenchmark(ctx, req)
+	assert.Nil(t, err)
+	code, _ = errorcode.Parse(resp.Status.Reason)
+	assert.Equal(t, errorcode.Busy, code)
+	in.benchmarking = 0
+
+	// the benchmark never shares the node with the tasks unless it's forced
+	assert.Nil(t, in.sched.IndexBuildQueue.Enqueue(newCollectionTask(ctx, 1, 1)))
+	resp, err = in.RunBuildBenchmark(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.Status.ErrorCode)
+	code, msg := errorcode.Parse(resp.Status.Reason)
+	assert.Equal(t, errorcode.Busy, code)
+	assert.True(t, strings.Contains(msg, "1 queued"), msg)
+
+	// the admission is resumed and the benchmark is released after it finishes
+	admissible, _ := in.admission.admissible()
+	assert.True(t, admissible)
+	assert.Equal(t, int32(0), in.benchmarking)
+	assert.Nil(t, in.Stop())
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/indexparamcheck"
+)
+
+// errNoVectors is the error of building an index on a segment without rows, the engine is never called for it, and
+// the task is finished as skipped.
+var errNoVectors = errorcode.New(errorcode.InvalidParams, "no vectors to build the index")
+
+// checkBinaryDim returns an error if @dim of the binary vectors is not a positive multiple of 8, the dim of the
+// binary vectors is in bits and each row takes dim/8 bytes.
+func checkBinaryDim(dim int) error {
+	if dim <= 0 || dim%8 != 0 {
+		return errorcode.Errorf(errorcode.InvalidParams, "invalid dim %d of the binary vectors, expect a positive multiple of 8", dim)
+	}
+	return nil
+}
+
+// binaryVectorBytes returns the bytes of a binary vector of @dim bits, which is checked by checkBinaryDim.
+func binaryVectorBytes(dim int) int {
+	return dim / 8
+}
+
+// isBinaryIndexType returns whether @indexType is built on the binary vectors.
+func isBinaryIndexType(indexType string) bool {
+	return len(indexparamcheck.AllowedMetricTypes(indexType, indexparamcheck.BinaryVector)) > 0
+}
+
+// vectorLayout returns the dim, the elements of a row and the elements of the decoded vector field, the elements of
+// the binary and the half-precision vectors are bytes.
+func vectorLayout(value storage.FieldData) (dim int, rowSize int, size int, err error) {
+	switch data := value.(type) {
+	case *storage.FloatVectorFieldData:
+		if data.Dim <= 0 {
+			return 0, 0, 0, errorcode.Errorf(errorcode.InvalidParams, "invalid dim %d of the float vectors", data.Dim)
+		}
+		dim, rowSize, size = data.Dim, data.Dim, len(data.Data)
+	case *storage.BinaryVectorFieldData:
+		if err := checkBinaryDim(data.Dim); err != nil {
+			return 0, 0, 0, err
+		}
+		dim, rowSize, size = data.Dim, binaryVectorBytes(data.Dim), len(data.Data)
+	case *storage.Float16VectorFieldData:
+		if data.Dim <= 0 {
+			return 0, 0, 0, errorcode.Errorf(errorcode.InvalidParams, "invalid dim %d of the float16 vectors", data.Dim)
+		}
+		dim, rowSize, size = data.Dim, data.Dim*halfFloatBytes, len(data.Data)
+	case *storage.BFloat16VectorFieldData:
+		if data.Dim <= 0 {
+			return 0, 0, 0, errorcode.Errorf(errorcode.InvalidParams, "invalid dim %d of the bfloat16 vectors", data.Dim)
+		}
+		dim, rowSize, size = data.Dim, data.Dim*halfFloatBytes, len(data.Data)
+	default:
+		return 0, 0, 0, errorcode.New(errorcode.InvalidParams, "we expect the field data of the float, binary, float16 or bfloat16 vectors")
+	}
+	if size%rowSize != 0 {
+		return 0, 0, 0, errorcode.Errorf(errorcode.InvalidParams,
+			"the %d elements of the vectors are not whole rows of dim %d", size, dim)
+	}
+	return dim, rowSize, size, nil
+}
+
+// vectorTypeOf returns the vector type the index type is checked against for the vectors of @dataType, the
+// half-precision vectors are built as the float vectors.
+func vectorTypeOf(dataType schemapb.DataType) indexparamcheck.VectorType {
+	if dataType == schemapb.DataType_BinaryVector {
+		return schemapb.DataType_BinaryVector.String()
+	}
+	return schemapb.DataType_FloatVector.String()
+}
+
+// checkVectors validates the vectors decoded from the binlogs against the index params of the task, so that the
+// vectors the index type does not support fail the task before the engine is called, it records their dim and
+// element type, whether they are normalized, and returns the rows.
+func (it *IndexBuildTask) checkVectors(value storage.FieldData) (int, error) {
+	dim, rowSize, size, err := vectorLayout(value)
+	if err != nil {
+		return 0, err
+	}
+	elementType := elementTypeOf(value)
+	if err := checkElementType(it.req.GetFieldSchema(), elementType); err != nil {
+		return 0, err
+	}
+	_, indexParams, err := parseBuildParams(it.req)
+	if err != nil {
+		return 0, err
+	}
+	normalize, err := normalizeOf(indexParams)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkNormalize(normalize, elementType); err != nil {
+		return 0, err
+	}
+	err = indexparamcheck.CheckMetricCompatibility(indexParams[indexTypeKey], indexParams[metricTypeKey], vectorTypeOf(elementType))
+	if err != nil {
+		return 0, errorcode.Wrap(errorcode.InvalidParams, err)
+	}
+	it.vectorDim = int64(dim)
+	it.elementType = elementType
+	it.normalized = normalize
+	return size / rowSize, nil
+}
@@ -0,0 +1,313 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/common"
+	etcdkv "github.com/milvus-io/milvus/internal/kv/etcd"
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/etcdpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/indexparamcheck"
+	"github.com/milvus-io/milvus/internal/util/timerecord"
+)
+
+const binaryTestFieldID = 101
+
+// saveBinaryBinlogs saves the binlogs of a segment of the binary vectors of @dim bits, the bytes of each row are
+// its row offset, and returns their paths along with all the vectors.
+func saveBinaryBinlogs(t *testing.T, objects *memkv.MemoryKV, dim int, rowsOfBinlogs ...int) ([]string, []byte) {
+	insertCodec := storage.InsertCodec{
+		Schema: &etcdpb.CollectionMeta{
+			ID: 1,
+			Schema: &schemapb.CollectionSchema{
+				Fields: []*schemapb.FieldSchema{
+					{FieldID: binaryTestFieldID, Name: "binary_vector", DataType: schemapb.DataType_BinaryVector},
+				},
+			},
+		},
+	}
+	defer insertCodec.Close()
+	var paths []string
+	var vectors []byte
+	offset := 0
+	for idx, rows := range rowsOfBinlogs {
+		ts := make([]int64, rows)
+		data := make([]byte, rows*binaryVectorBytes(dim))
+		for row := 0; row < rows; row++ {
+			ts[row] = int64(offset + row + 100)
+			for b := 0; b < binaryVectorBytes(dim); b++ {
+				data[row*binaryVectorBytes(dim)+b] = byte(offset + row)
+			}
+		}
+		offset += rows
+		vectors = append(vectors, data...)
+		blobs, _, err := insertCodec.Serialize(2, 3, &storage.InsertData{
+			Data: map[UniqueID]storage.FieldData{
+				common.TimeStampField: &storage.Int64FieldData{NumRows: []int64{int64(rows)}, Data: ts},
+				binaryTestFieldID:     &storage.BinaryVectorFieldData{NumRows: []int64{int64(rows)}, Data: data, Dim: dim},
+			},
+			Infos: []storage.BlobInfo{{Length: rows}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(blobs))
+		path := fmt.Sprintf("insert_log/1/2/3/%d/%d", binaryTestFieldID, idx)
+		assert.Nil(t, objects.Save(path, string(blobs[0].Value)))
+		paths = append(paths, path)
+	}
+	return paths, vectors
+}
+
+func TestVectorLayout(t *testing.T) {
+	for _, dim := range []int{8, 24, 512} {
+		assert.Nil(t, checkBinaryDim(dim))
+		d, rowSize, size, err := vectorLayout(&storage.BinaryVectorFieldData{Data: make([]byte, 3*dim/8), Dim: dim})
+		assert.Nil(t, err)
+		assert.Equal(t, []int{dim, dim / 8, 3 * dim / 8}, []int{d, rowSize, size})
+	}
+	for _, dim := range []int{0, -8, 7, 12} {
+		err := checkBinaryDim(dim)
+		assert.NotNil(t, err)
+		assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+		_, _, _, err = vectorLayout(&storage.BinaryVectorFieldData{Data: make([]byte, 8), Dim: dim})
+		assert.NotNil(t, err)
+	}
+	// the bytes are not whole rows
+	_, _, _, err := vectorLayout(&storage.BinaryVectorFieldData{Data: make([]byte, 5), Dim: 16})
+	assert.NotNil(t, err)
+	_, _, _, err = vectorLayout(&storage.FloatVectorFieldData{Data: make([]float32, 5), Dim: 4})
+	assert.NotNil(t, err)
+	_, _, _, err = vectorLayout(&storage.Int64FieldData{})
+	assert.NotNil(t, err)
+
+	_, rowSize, size, err := vectorLayout(&storage.BinaryVectorFieldData{Dim: 16})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, size/rowSize)
+}
+
+func TestIndexBuildTask_checkVectors(t *testing.T) {
+	newTask := func(indexType, metricType string) *IndexBuildTask {
+		return &IndexBuildTask{
+			req: &indexpb.CreateIndexRequest{IndexParams: []*commonpb.KeyValuePair{
+				{Key: indexTypeKey, Value: indexType}, {Key: metricTypeKey, Value: metricType}}},
+		}
+	}
+	binary := &storage.BinaryVectorFieldData{Data: make([]byte, 6), Dim: 16}
+	float := &storage.FloatVectorFieldData{Data: make([]float32, 8), Dim: 4}
+
+	task := newTask("BIN_IVF_FLAT", "JACCARD")
+	rows, err := task.checkVectors(binary)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, rows)
+	assert.Equal(t, int64(16), task.vectorDim)
+	rows, err = newTask("HNSW", "L2").checkVectors(float)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, rows)
+
+	for _, c := range []struct {
+		task  *IndexBuildTask
+		value storage.FieldData
+	}{
+		{newTask("BIN_IVF_FLAT", "SUBSTRUCTURE"), binary},
+		{newTask("BIN_FLAT", "HAMMING"), float},
+		{newTask("HNSW", ""), binary},
+		{newTask("BIN_FLAT", "HAMMING"), &storage.BinaryVectorFieldData{Data: make([]byte, 6), Dim: 12}},
+	} {
+		_, err := c.task.checkVectors(c.value)
+		assert.NotNil(t, err)
+		assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+	}
+}
+
+func TestIndexBuildTask_buildBinaryIndex(t *testing.T) {
+	oldBufferSize, oldChunkRows, oldStatsEnabled := Params.BuildPipelineBufferSize, Params.BuildChunkRows, Params.EngineStatsEnabled
+	defer func() {
+		Params.BuildPipelineBufferSize, Params.BuildChunkRows = oldBufferSize, oldChunkRows
+		Params.EngineStatsEnabled = oldStatsEnabled
+	}()
+	Params.BuildPipelineBufferSize, Params.BuildChunkRows, Params.EngineStatsEnabled = 2, 7, true
+	ctx := context.Background()
+	const dim = 24
+	objects := memkv.NewMemoryKV()
+	paths, vectors := saveBinaryBinlogs(t, objects, dim, 10, 5, 12)
+
+	for _, indexType := range []string{indexparamcheck.IndexFaissBinIDMap, indexparamcheck.IndexFaissBinIvfFlat} {
+		metricTypes := indexparamcheck.AllowedMetricTypes(indexType, indexparamcheck.BinaryVector)
+		assert.NotEmpty(t, metricTypes)
+		for _, metricType := range metricTypes {
+			newTask := func(index Index) *IndexBuildTask {
+				return &IndexBuildTask{
+					index: index,
+					kv:    objects,
+					req: &indexpb.CreateIndexRequest{
+						IndexBuildID: 1,
+						Version:      1,
+						DataPaths:    paths,
+						TypeParams:   []*commonpb.KeyValuePair{{Key: dimKey, Value: fmt.Sprint(dim)}},
+						IndexParams: []*commonpb.KeyValuePair{
+							{Key: indexTypeKey, Value: indexType}, {Key: metricTypeKey, Value: metricType}},
+					},
+					stats:    newTaskStatistics(),
+					progress: newTaskProgress(1, 1),
+				}
+			}
+			name := indexType + "/" + metricType
+			assert.Nil(t, checkMetricCompatibility(newTask(nil).req), name)
+
+			// the engine reports the dim in bytes, which is reported in bits
+			index := &mockStatisticsIndex{stats: `{"rows":27,"dim":3}`}
+			task := newTask(index)
+			_, _, segmentID, fieldID, err := task.loadAndBuild(ctx, nil, timerecord.NewTimeRecorder(name))
+			assert.Nil(t, err, name)
+			assert.Equal(t, UniqueID(3), segmentID)
+			assert.Equal(t, UniqueID(binaryTestFieldID), fieldID)
+			assert.Equal(t, vectors, index.binaryData, name)
+			task.recordEngineStatistics()
+			stats := task.stats.taskInfos(0, 0, 0).IndexTypeEngineStatistics[indexType]
+			assert.Equal(t, int64(dim), *stats.Dim, name)
+
+			if !supportsIncrementalAdd(indexType) {
+				continue
+			}
+			pipelined := &mockIncrementalIndex{}
+			task = newTask(pipelined)
+			incremental, ok := task.incrementalIndex(indexType)
+			assert.True(t, ok)
+			pipeline, err := task.buildPipelined(ctx, incremental)
+			assert.Nil(t, err, name)
+			assert.Equal(t, 27, pipeline.feeder.addedRows)
+			assert.Equal(t, 4, len(pipelined.chunks))
+			assert.Equal(t, vectors, pipelined.binaryData, name)
+			assert.Equal(t, int64(dim), task.vectorDim)
+		}
+	}
+
+	// the metric type not supported fails the task before the engine is called
+	index := &mockStatisticsIndex{}
+	task := &IndexBuildTask{
+		index: index,
+		kv:    objects,
+		req: &indexpb.CreateIndexRequest{IndexBuildID: 1, Version: 1, DataPaths: paths,
+			IndexParams: []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: "HNSW"}, {Key: metricTypeKey, Value: "L2"}}},
+		stats:    newTaskStatistics(),
+		progress: newTaskProgress(1, 1),
+	}
+	_, _, _, _, err := task.loadAndBuild(ctx, nil, timerecord.NewTimeRecorder("hnsw"))
+	assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+	assert.Nil(t, index.binaryData)
+}
+
+func TestBinlogPipeline_binaryMetricTypes(t *testing.T) {
+	const dim = 16
+	segment := newPipelineTestSegment(3, 0)
+	var vectors []byte
+	for idx, path := range segment.paths {
+		data := make([]byte, 5*binaryVectorBytes(dim))
+		for b := range data {
+			data[b] = byte(idx*5 + b/binaryVectorBytes(dim))
+		}
+		vectors = append(vectors, data...)
+		segment.binlogs[path].data = &storage.BinaryVectorFieldData{NumRows: []int64{5}, Data: data, Dim: dim}
+	}
+	for _, c := range indexparamcheck.MetricCompatibilities() {
+		if c.VectorType != indexparamcheck.BinaryVector || !supportsIncrementalAdd(c.IndexType) {
+			continue
+		}
+		for _, metricType := range append(c.MetricTypes, "L2") {
+			task := &IndexBuildTask{req: &indexpb.CreateIndexRequest{IndexParams: []*commonpb.KeyValuePair{
+				{Key: indexTypeKey, Value: c.IndexType}, {Key: metricTypeKey, Value: metricType}}}}
+			index := &mockIncrementalIndex{}
+			pipeline := segment.pipeline(index, 4)
+			pipeline.check = func(value storage.FieldData) error {
+				_, err := task.checkVectors(value)
+				return err
+			}
+			err := pipeline.run(context.Background())
+			name := c.IndexType + "/" + metricType
+			if metricType == "L2" {
+				assert.Equal(t, errorcode.InvalidParams, classifyError(err), name)
+				assert.Empty(t, index.chunks, name)
+				continue
+			}
+			assert.Nil(t, err, name)
+			assert.Equal(t, vectors, index.binaryData, name)
+			assert.Equal(t, int64(dim), task.vectorDim, name)
+		}
+	}
+}
+
+func TestBinlogPipeline_noVectors(t *testing.T) {
+	// the segment without rows fails cleanly either way, instead of the engine crashing on no vectors
+	segment := newPipelineTestSegment(2, 0)
+	for _, binlog := range segment.binlogs {
+		binlog.data = &storage.BinaryVectorFieldData{NumRows: []int64{0}, Dim: 16}
+	}
+	index := &mockIncrementalIndex{}
+	err := segment.pipeline(index, 10).run(context.Background())
+	assert.Equal(t, errNoVectors, err)
+	assert.Empty(t, index.chunks)
+
+	cIndex := &CIndex{}
+	assert.Equal(t, errNoVectors, cIndex.BuildBinaryVecIndexWithoutIds(nil))
+	assert.Equal(t, errNoVectors, cIndex.AddBinaryVecIndexWithoutIds([]byte{}))
+	assert.Equal(t, errNoVectors, cIndex.BuildFloatVecIndexWithoutIds(nil))
+	assert.Equal(t, errNoVectors, cIndex.AddFloatVecIndexWithoutIds(nil))
+}
+
+func TestIndexBuildTask_skipEmptySegment(t *testing.T) {
+	e, endpoints := startEmbedEtcd(t)
+	defer e.Close()
+	client, err := etcdkv.NewEtcdKV(endpoints, "/skip-empty-segment")
+	assert.Nil(t, err)
+	defer client.Close()
+
+	for _, failed := range []bool{false, true} {
+		meta := &indexpb.IndexMeta{IndexBuildID: 1, Version: 1, State: commonpb.IndexState_InProgress}
+		value, err := proto.Marshal(meta)
+		assert.Nil(t, err)
+		assert.Nil(t, client.Save("indexes/1", string(value)))
+
+		it := &IndexBuildTask{
+			etcdKV: newMetricsEtcdKV(client),
+			req:    &indexpb.CreateIndexRequest{IndexBuildID: 1, Version: 1, MetaPath: "indexes/1"},
+		}
+		// the segment without rows finishes the task without any index file
+		assert.Nil(t, it.skipEmptySegment())
+		if failed {
+			it.SetError(errorcode.New(errorcode.Internal, "failed to save the index files"))
+		}
+		assert.Nil(t, it.checkIndexMeta(context.Background(), false))
+
+		saved, err := client.Load("indexes/1")
+		assert.Nil(t, err)
+		assert.Nil(t, proto.Unmarshal([]byte(saved), meta))
+		assert.Empty(t, meta.IndexFilePaths)
+		if failed {
+			assert.Equal(t, commonpb.IndexState_Failed, meta.State)
+			assert.False(t, meta.Skipped)
+		} else {
+			assert.Equal(t, commonpb.IndexState_Finished, meta.State)
+			assert.True(t, meta.Skipped)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/indexparamcheck"
+)
+
+// buildSeedKey is the index param seeding the randomized initialization of the build, which makes the index files
+// reproducible, the builds not asking for it are seeded by indexNode.build.seed.
+const buildSeedKey = "build_seed"
+
+// seededIndexTypes are the index types whose builds are reproducible by the seed with the engine of
+// currentEngineVersion, the flat ones are reproducible anyway.
+var seededIndexTypes = map[string]bool{
+	indexparamcheck.IndexFaissIDMap:      true,
+	indexparamcheck.IndexFaissBinIDMap:   true,
+	indexparamcheck.IndexFaissIvfFlat:    true,
+	indexparamcheck.IndexFaissIvfPQ:      true,
+	indexparamcheck.IndexFaissIvfSQ8:     true,
+	indexparamcheck.IndexFaissBinIvfFlat: true,
+	indexparamcheck.IndexHNSW:            true,
+}
+
+// serialSeededIndexTypes are the seeded index types built single-threaded by the engine, since the levels of the
+// vectors are drawn from the seed in the order they are inserted. They are only seeded if Params.BuildSeedHNSW
+// allows it, so that the seed configured for all the builds never slows them down.
+var serialSeededIndexTypes = map[string]bool{
+	indexparamcheck.IndexHNSW: true,
+}
+
+// buildSeedOf returns the seed of the build of @indexParams, which falls back to Params.BuildSeed, nil if the build
+// is not seeded. The seed is not honored if the engine can't seed the index type, or the seeded builds of the index
+// type run single-threaded and they are not allowed, the build asking for such a seed is rejected.
+func buildSeedOf(indexParams map[string]string) (*indexpb.IndexBuildSeed, error) {
+	seed := Params.BuildSeed
+	indexType := strings.ToUpper(indexParams[indexTypeKey])
+	value, requested := indexParams[buildSeedKey]
+	if requested {
+		var err error
+		seed, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || seed < 0 || seed > math.MaxInt32 {
+			return nil, errorcode.Errorf(errorcode.InvalidParams, "invalid %s %q, expect an integer within [0, %d]",
+				buildSeedKey, value, math.MaxInt32)
+		}
+	}
+	if seed < 0 {
+		return nil, nil
+	}
+	disallowed := serialSeededIndexTypes[indexType] && !Params.BuildSeedHNSW
+	if disallowed && requested {
+		return nil, errorcode.Errorf(errorcode.InvalidParams,
+			"the seeded builds of %s run single-threaded, enable indexNode.build.seedHNSW to allow %s", indexType, buildSeedKey)
+	}
+	return &indexpb.IndexBuildSeed{
+		Seed:    seed,
+		Honored: seededIndexTypes[indexType] && !disallowed,
+	}, nil
+}
+
+// isSerialSeededBuild returns whether the build of @indexType seeded by @seed runs single-threaded.
+func isSerialSeededBuild(indexType string, seed *indexpb.IndexBuildSeed) bool {
+	return seed != nil && seed.Honored && serialSeededIndexTypes[strings.ToUpper(indexType)]
+}
+
+// buildSeedIgnoredReason returns why the seed of the build of @indexType is not honored.
+func buildSeedIgnoredReason(indexType string) string {
+	if serialSeededIndexTypes[strings.ToUpper(indexType)] {
+		return "not honored unless indexNode.build.seedHNSW is enabled"
+	}
+	return "not honored by the engine"
+}
+
+// applyBuildSeed passes @seed to the engine by @engineParams, the seed not honored is never passed.
+func applyBuildSeed(engineParams map[string]string, seed *indexpb.IndexBuildSeed) {
+	delete(engineParams, buildSeedKey)
+	if seed != nil && seed.Honored {
+		engineParams[buildSeedKey] = strconv.FormatInt(seed.Seed, 10)
+	}
+}
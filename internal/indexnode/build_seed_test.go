@@ -0,0 +1,90 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+func TestBuildSeedOf(t *testing.T) {
+	old, oldHNSW := Params.BuildSeed, Params.BuildSeedHNSW
+	defer func() {
+		Params.BuildSeed, Params.BuildSeedHNSW = old, oldHNSW
+	}()
+
+	Params.BuildSeed = defaultBuildSeed
+	seed, err := buildSeedOf(map[string]string{indexTypeKey: "IVF_FLAT"})
+	assert.Nil(t, err)
+	assert.Nil(t, seed)
+
+	seed, err = buildSeedOf(map[string]string{indexTypeKey: "IVF_FLAT", buildSeedKey: "0"})
+	assert.Nil(t, err)
+	assert.Equal(t, &indexpb.IndexBuildSeed{Seed: 0, Honored: true}, seed)
+	// the seeded HNSW builds run single-threaded, which are rejected unless allowed
+	_, err = buildSeedOf(map[string]string{indexTypeKey: "hnsw", buildSeedKey: "2147483647"})
+	assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+	Params.BuildSeedHNSW = true
+	seed, err = buildSeedOf(map[string]string{indexTypeKey: "hnsw", buildSeedKey: "2147483647"})
+	assert.Nil(t, err)
+	assert.Equal(t, &indexpb.IndexBuildSeed{Seed: 2147483647, Honored: true}, seed)
+	assert.True(t, isSerialSeededBuild("hnsw", seed))
+	assert.False(t, isSerialSeededBuild("IVF_FLAT", seed))
+	Params.BuildSeedHNSW = false
+
+	// the engine can't seed the index type
+	seed, err = buildSeedOf(map[string]string{indexTypeKey: "ANNOY", buildSeedKey: "7"})
+	assert.Nil(t, err)
+	assert.Equal(t, &indexpb.IndexBuildSeed{Seed: 7, Honored: false}, seed)
+
+	for _, invalid := range []string{"", "-1", "2147483648", "seed", "1.5"} {
+		_, err = buildSeedOf(map[string]string{indexTypeKey: "IVF_FLAT", buildSeedKey: invalid})
+		assert.Equal(t, errorcode.InvalidParams, classifyError(err), invalid)
+	}
+
+	// the builds not asking for a seed are seeded by the config
+	Params.BuildSeed = 42
+	seed, err = buildSeedOf(map[string]string{indexTypeKey: "IVF_PQ"})
+	assert.Nil(t, err)
+	assert.Equal(t, &indexpb.IndexBuildSeed{Seed: 42, Honored: true}, seed)
+	seed, err = buildSeedOf(map[string]string{indexTypeKey: "IVF_PQ", buildSeedKey: "7"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7), seed.Seed)
+	// the HNSW builds are left unseeded by the config unless allowed
+	seed, err = buildSeedOf(map[string]string{indexTypeKey: "HNSW"})
+	assert.Nil(t, err)
+	assert.Equal(t, &indexpb.IndexBuildSeed{Seed: 42, Honored: false}, seed)
+	assert.False(t, isSerialSeededBuild("HNSW", seed))
+	assert.Contains(t, buildSeedIgnoredReason("HNSW"), "indexNode.build.seedHNSW")
+	Params.BuildSeedHNSW = true
+	seed, err = buildSeedOf(map[string]string{indexTypeKey: "HNSW"})
+	assert.Nil(t, err)
+	assert.Equal(t, &indexpb.IndexBuildSeed{Seed: 42, Honored: true}, seed)
+}
+
+func TestApplyBuildSeed(t *testing.T) {
+	params := map[string]string{indexTypeKey: "IVF_FLAT", buildSeedKey: "7"}
+	applyBuildSeed(params, nil)
+	assert.Equal(t, map[string]string{indexTypeKey: "IVF_FLAT"}, params)
+
+	applyBuildSeed(params, &indexpb.IndexBuildSeed{Seed: 42, Honored: true})
+	assert.Equal(t, "42", params[buildSeedKey])
+
+	// the seed not honored is never passed to the engine
+	applyBuildSeed(params, &indexpb.IndexBuildSeed{Seed: 42})
+	_, ok := params[buildSeedKey]
+	assert.False(t, ok)
+}
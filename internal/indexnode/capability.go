@@ -0,0 +1,72 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// CapabilityDiskIndex is advertised by the node building the disk index types in its scratch path
+	CapabilityDiskIndex = "disk-index"
+	// CapabilityGPU is advertised by the node configured with the gpu tag, since the GPU is not detected
+	CapabilityGPU = "gpu"
+	// CapabilityHighMem is advertised by the node whose memory reaches indexNode.capabilities.highMemThreshold
+	CapabilityHighMem = "high-mem"
+)
+
+// normalizeCapability returns the tag compared case-insensitively.
+func normalizeCapability(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// nodeCapabilities returns the sorted capability tags advertised by the node with @memory bytes of memory.
+func nodeCapabilities(memory uint64) []string {
+	tags := make(map[string]struct{})
+	if Params.CapabilityDiskIndex {
+		tags[CapabilityDiskIndex] = struct{}{}
+	}
+	if Params.HighMemThreshold > 0 && memory >= uint64(Params.HighMemThreshold) {
+		tags[CapabilityHighMem] = struct{}{}
+	}
+	for _, tag := range Params.CapabilityTags {
+		tags[tag] = struct{}{}
+	}
+	capabilities := make([]string, 0, len(tags))
+	for tag := range tags {
+		capabilities = append(capabilities, tag)
+	}
+	sort.Strings(capabilities)
+	return capabilities
+}
+
+// missingCapabilities returns the tags in @required which are not in @advertised.
+func missingCapabilities(required []string, advertised []string) []string {
+	advertisedSet := make(map[string]struct{}, len(advertised))
+	for _, tag := range advertised {
+		advertisedSet[tag] = struct{}{}
+	}
+	var missing []string
+	for _, tag := range required {
+		tag = normalizeCapability(tag)
+		if tag == "" {
+			continue
+		}
+		if _, ok := advertisedSet[tag]; !ok {
+			missing = append(missing, tag)
+			// report each missing tag once
+			advertisedSet[tag] = struct{}{}
+		}
+	}
+	return missing
+}
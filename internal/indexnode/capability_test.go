@@ -0,0 +1,79 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+)
+
+func TestNodeCapabilities(t *testing.T) {
+	oldDiskIndex, oldThreshold, oldTags := Params.CapabilityDiskIndex, Params.HighMemThreshold, Params.CapabilityTags
+	defer func() {
+		Params.CapabilityDiskIndex, Params.HighMemThreshold, Params.CapabilityTags = oldDiskIndex, oldThreshold, oldTags
+	}()
+
+	Params.CapabilityDiskIndex = true
+	Params.HighMemThreshold = 1024
+	Params.CapabilityTags = []string{"nvme", CapabilityGPU, CapabilityDiskIndex}
+	assert.Equal(t, []string{CapabilityDiskIndex, CapabilityGPU, CapabilityHighMem, "nvme"}, nodeCapabilities(1024))
+	assert.Equal(t, []string{CapabilityDiskIndex, CapabilityGPU, "nvme"}, nodeCapabilities(1023))
+
+	Params.CapabilityDiskIndex = false
+	Params.HighMemThreshold = 0
+	Params.CapabilityTags = nil
+	assert.Equal(t, []string{}, nodeCapabilities(1024))
+}
+
+func TestMissingCapabilities(t *testing.T) {
+	advertised := []string{CapabilityDiskIndex, CapabilityHighMem}
+	assert.Nil(t, missingCapabilities(nil, advertised))
+	assert.Nil(t, missingCapabilities([]string{" Disk-Index ", "", CapabilityHighMem}, advertised))
+	assert.Equal(t, []string{CapabilityGPU, "nvme"},
+		missingCapabilities([]string{"GPU", CapabilityDiskIndex, "nvme", "gpu"}, advertised))
+	assert.Equal(t, []string{CapabilityGPU}, missingCapabilities([]string{CapabilityGPU}, nil))
+}
+
+func TestIndexNode_CapabilityMismatch(t *testing.T) {
+	ctx := context.Background()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	in.probe.update(probeEtcdSession, nil)
+	in.probe.update(probeStorage, nil)
+	in.UpdateStateCode(internalpb.StateCode_Healthy)
+	in.capabilities = []string{CapabilityDiskIndex}
+
+	status, err := in.CreateIndex(ctx, &indexpb.CreateIndexRequest{
+		IndexBuildID:         1,
+		RequiredCapabilities: []string{CapabilityDiskIndex, CapabilityGPU},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, status.ErrorCode)
+	assert.Equal(t, msgCapabilityMismatch(Params.NodeID, []string{CapabilityGPU}), status.Reason)
+
+	// the scheduler is not started, so the task stays in the queue
+	status, err = in.CreateIndex(ctx, &indexpb.CreateIndexRequest{
+		IndexBuildID:         2,
+		RequiredCapabilities: []string{CapabilityDiskIndex},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, status.ErrorCode)
+	assert.Equal(t, 1, in.sched.IndexBuildQueue.utLen())
+
+	assert.Nil(t, in.Stop())
+}
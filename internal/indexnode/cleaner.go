@@ -0,0 +1,243 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/kv"
+	"github.com/milvus-io/milvus/internal/log"
+)
+
+// cleanupRecordsDirName is the directory under the scratch path holding the records of the failed cleanups
+const cleanupRecordsDirName = "cleanup_records"
+
+// the kinds of the resources created by a task
+const (
+	// resourceLocalPath is a temporary file or a staging directory on local disk
+	resourceLocalPath = "local_path"
+	// resourceMultipartUpload is an initiated multipart upload of an object
+	resourceMultipartUpload = "multipart_upload"
+	// resourceObject is an object saved to the object storage
+	resourceObject = "object"
+)
+
+// cleanupResource is a resource created by a task which must be removed once the task finishes.
+type cleanupResource struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+	// OnFailure means the resource is only removed if the task fails, such as the saved index files
+	OnFailure bool `json:"on_failure"`
+}
+
+// cleanupRecord is persisted on local disk for the resources failed to clean, which are retried at next startup.
+type cleanupRecord struct {
+	IndexBuildID UniqueID          `json:"index_build_id"`
+	Version      int64             `json:"version"`
+	Resources    []cleanupResource `json:"resources"`
+}
+
+// incompleteUploadRemover is implemented by the object storage which aborts the incomplete multipart uploads.
+type incompleteUploadRemover interface {
+	RemoveIncompleteUpload(key string) error
+}
+
+// taskCleaner tracks the resources created by a task, and removes them exactly once when the task finishes,
+// no matter it succeeds, fails, is canceled or panics.
+type taskCleaner struct {
+	indexBuildID UniqueID
+	version      int64
+	storage      kv.BaseKV
+
+	mu        sync.Mutex
+	resources []cleanupResource
+	once      sync.Once
+}
+
+func newTaskCleaner(indexBuildID UniqueID, version int64, storage kv.BaseKV) *taskCleaner {
+	return &taskCleaner{
+		indexBuildID: indexBuildID,
+		version:      version,
+		storage:      storage,
+		resources:    make([]cleanupResource, 0),
+	}
+}
+
+// register tracks a resource of the task, a nil cleaner tracks nothing.
+func (c *taskCleaner) register(kind string, path string, onFailure bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resources = append(c.resources, cleanupResource{Kind: kind, Path: path, OnFailure: onFailure})
+}
+
+// forget stops tracking a resource which needs no cleanup any more.
+func (c *taskCleaner) forget(kind string, path string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for idx := len(c.resources) - 1; idx >= 0; idx-- {
+		if c.resources[idx].Kind == kind && c.resources[idx].Path == path {
+			c.resources = append(c.resources[:idx], c.resources[idx+1:]...)
+			return
+		}
+	}
+}
+
+// guard runs the stage of the task and cleans up the resources once it returns or panics,
+// the panic is recovered and returned as the error of the task.
+func (c *taskCleaner) guard(stage func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("IndexNode task panicked", zap.Int64("indexBuildID", c.indexBuildID), zap.Int64("version", c.version),
+				zap.Any("panic", r), zap.String("stack", string(debug.Stack())))
+			err = fmt.Errorf("index build task panicked: %v", r)
+		}
+		c.run(err != nil)
+	}()
+	return stage()
+}
+
+// run removes the resources in the reverse order of registration, it only runs once.
+// The resources failed to remove are persisted and retried at next startup.
+func (c *taskCleaner) run(failed bool) {
+	c.once.Do(func() {
+		c.mu.Lock()
+		resources := c.resources
+		c.resources = nil
+		c.mu.Unlock()
+
+		remaining := make([]cleanupResource, 0)
+		for idx := len(resources) - 1; idx >= 0; idx-- {
+			resource := resources[idx]
+			if resource.OnFailure && !failed {
+				continue
+			}
+			if err := cleanResource(c.storage, resource); err != nil {
+				log.Warn("IndexNode failed to clean up the resource of the task", zap.Int64("indexBuildID", c.indexBuildID),
+					zap.String("kind", resource.Kind), zap.String("path", resource.Path), zap.Error(err))
+				remaining = append(remaining, resource)
+			}
+		}
+		if err := saveCleanupRecord(&cleanupRecord{
+			IndexBuildID: c.indexBuildID,
+			Version:      c.version,
+			Resources:    remaining,
+		}); err != nil {
+			log.Warn("IndexNode failed to persist the resources failed to clean up", zap.Int64("indexBuildID", c.indexBuildID),
+				zap.Any("resources", remaining), zap.Error(err))
+		}
+	})
+}
+
+func cleanResource(storage kv.BaseKV, resource cleanupResource) error {
+	switch resource.Kind {
+	case resourceLocalPath:
+		return os.RemoveAll(resource.Path)
+	case resourceMultipartUpload:
+		if remover, ok := storage.(incompleteUploadRemover); ok {
+			return remover.RemoveIncompleteUpload(resource.Path)
+		}
+		return nil
+	case resourceObject:
+		return storage.Remove(resource.Path)
+	default:
+		return fmt.Errorf("unknown kind of resource %s", resource.Kind)
+	}
+}
+
+func cleanupRecordPath(indexBuildID UniqueID, version int64) string {
+	return filepath.Join(Params.ScratchPath, cleanupRecordsDirName,
+		strconv.FormatInt(indexBuildID, 10)+"_"+strconv.FormatInt(version, 10)+".json")
+}
+
+// saveCleanupRecord persists the record, or removes the existing one if nothing remains to clean.
+func saveCleanupRecord(record *cleanupRecord) error {
+	recordPath := cleanupRecordPath(record.IndexBuildID, record.Version)
+	if len(record.Resources) == 0 {
+		if err := os.Remove(recordPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(recordPath), os.ModePerm); err != nil {
+		return err
+	}
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	tmpPath := recordPath + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, value, 0644); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, recordPath)
+}
+
+// retryPendingCleanups cleans up the resources left by the tasks of the last run according to the persisted records.
+func retryPendingCleanups(storage kv.BaseKV) {
+	dirPath := filepath.Join(Params.ScratchPath, cleanupRecordsDirName)
+	files, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warn("IndexNode failed to read the cleanup records", zap.String("path", dirPath), zap.Error(err))
+		}
+		return
+	}
+	for _, file := range files {
+		filePath := filepath.Join(dirPath, file.Name())
+		if filepath.Ext(file.Name()) != ".json" {
+			// the record which is not completely written
+			_ = os.Remove(filePath)
+			continue
+		}
+		value, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			log.Warn("IndexNode failed to read the cleanup record", zap.String("path", filePath), zap.Error(err))
+			continue
+		}
+		record := &cleanupRecord{}
+		if err := json.Unmarshal(value, record); err != nil {
+			log.Warn("IndexNode failed to decode the cleanup record, drop it", zap.String("path", filePath), zap.Error(err))
+			_ = os.Remove(filePath)
+			continue
+		}
+		remaining := make([]cleanupResource, 0)
+		for _, resource := range record.Resources {
+			if err := cleanResource(storage, resource); err != nil {
+				log.Warn("IndexNode failed to clean up the resource left by the last run", zap.Int64("indexBuildID", record.IndexBuildID),
+					zap.String("kind", resource.Kind), zap.String("path", resource.Path), zap.Error(err))
+				remaining = append(remaining, resource)
+			}
+		}
+		record.Resources = remaining
+		if err := saveCleanupRecord(record); err != nil {
+			log.Warn("IndexNode failed to update the cleanup record", zap.String("path", filePath), zap.Error(err))
+		}
+		log.Debug("IndexNode retry the cleanup left by the last run", zap.Int64("indexBuildID", record.IndexBuildID),
+			zap.Int64("version", record.Version), zap.Int("remaining", len(remaining)))
+	}
+}
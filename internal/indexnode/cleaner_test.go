@@ -0,0 +1,265 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
+)
+
+// mockMultipartStorage tracks the incomplete multipart uploads, an upload of failKey is left incomplete.
+type mockMultipartStorage struct {
+	*memkv.MemoryKV
+	mu         sync.Mutex
+	incomplete map[string]bool
+	failKey    string
+	removeErr  error
+}
+
+func newMockMultipartStorage() *mockMultipartStorage {
+	return &mockMultipartStorage{
+		MemoryKV:   memkv.NewMemoryKV(),
+		incomplete: make(map[string]bool),
+	}
+}
+
+func (m *mockMultipartStorage) FPutObject(key, localPath string, partSize uint64, metadata map[string]string) error {
+	m.mu.Lock()
+	m.incomplete[key] = true
+	m.mu.Unlock()
+	if key == m.failKey {
+		return errors.New("upload part failed")
+	}
+	content, err := ioutil.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	delete(m.incomplete, key)
+	m.mu.Unlock()
+	return m.Save(key, string(content))
+}
+
+func (m *mockMultipartStorage) RemoveIncompleteUpload(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.incomplete, key)
+	return nil
+}
+
+func (m *mockMultipartStorage) Remove(key string) error {
+	if m.removeErr != nil {
+		return m.removeErr
+	}
+	return m.MemoryKV.Remove(key)
+}
+
+func (m *mockMultipartStorage) incompleteNum() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.incomplete)
+}
+
+func (m *mockMultipartStorage) objectNum(t *testing.T) int {
+	keys, _, err := m.LoadWithPrefix("")
+	assert.Nil(t, err)
+	return len(keys)
+}
+
+const (
+	panicAfterDiskDir  = "after creating the disk directory"
+	panicAfterSave     = "after saving the index files"
+	panicAfterUpload   = "after uploading the disk index files"
+	panicBeforeSuccess = "before the task succeeds"
+)
+
+// runPipeline runs the stages of persisting a disk index with the cleaner, and panics at @panicAt.
+func runPipeline(ctx context.Context, cleaner *taskCleaner, storage *mockMultipartStorage, panicAt string) error {
+	return cleaner.guard(func() error {
+		diskDir, err := newTaskDiskDir(cleaner.indexBuildID, cleaner.version, 0)
+		if err != nil {
+			return err
+		}
+		cleaner.register(resourceLocalPath, diskDir.path, false)
+		for i := 0; i < 3; i++ {
+			if err := ioutil.WriteFile(filepath.Join(diskDir.path, fmt.Sprintf("file_%d", i)), []byte("data"), 0644); err != nil {
+				return err
+			}
+		}
+		if panicAt == panicAfterDiskDir {
+			panic(panicAt)
+		}
+
+		getSavePath := func(key string) string {
+			return path.Join("index_files", "1", "1", key)
+		}
+		report := newPersistReport()
+		blobPaths := []string{getSavePath("blob_0"), getSavePath("blob_1")}
+		report.persistFiles(blobPaths, func(idx int) error {
+			if err := storage.Save(blobPaths[idx], "blob"); err != nil {
+				return err
+			}
+			cleaner.register(resourceObject, blobPaths[idx], true)
+			return nil
+		}, "saveIndexFile")
+		if panicAt == panicAfterSave {
+			panic(panicAt)
+		}
+
+		if _, err := diskDir.upload(ctx, storage, getSavePath, nil, nil, report, cleaner); err != nil {
+			return err
+		}
+		if panicAt == panicAfterUpload {
+			panic(panicAt)
+		}
+		if panicAt == panicBeforeSuccess {
+			panic(panicAt)
+		}
+		return nil
+	})
+}
+
+func assertNoLeftovers(t *testing.T, storage *mockMultipartStorage) {
+	dirs, err := ioutil.ReadDir(filepath.Join(Params.ScratchPath, diskIndexDirName, "1"))
+	if err == nil {
+		assert.Equal(t, 0, len(dirs))
+	} else {
+		assert.True(t, os.IsNotExist(err))
+	}
+	assert.Equal(t, 0, storage.objectNum(t))
+	assert.Equal(t, 0, storage.incompleteNum())
+	_, err = os.Stat(cleanupRecordPath(1, 1))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestTaskCleaner_panic(t *testing.T) {
+	defer withScratchPath(t)()
+
+	for _, panicAt := range []string{panicAfterDiskDir, panicAfterSave, panicAfterUpload, panicBeforeSuccess} {
+		t.Run(panicAt, func(t *testing.T) {
+			storage := newMockMultipartStorage()
+			cleaner := newTaskCleaner(1, 1, storage)
+			err := runPipeline(context.Background(), cleaner, storage, panicAt)
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), panicAt)
+			assertNoLeftovers(t, storage)
+		})
+	}
+
+	t.Run("upload failed", func(t *testing.T) {
+		storage := newMockMultipartStorage()
+		storage.failKey = path.Join("index_files", "1", "1", "file_1")
+		cleaner := newTaskCleaner(1, 1, storage)
+		err := runPipeline(context.Background(), cleaner, storage, "")
+		assert.NotNil(t, err)
+		assertNoLeftovers(t, storage)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		storage := newMockMultipartStorage()
+		storage.failKey = path.Join("index_files", "1", "1", "file_0")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		cleaner := newTaskCleaner(1, 1, storage)
+		err := runPipeline(ctx, cleaner, storage, "")
+		assert.NotNil(t, err)
+		assertNoLeftovers(t, storage)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		storage := newMockMultipartStorage()
+		cleaner := newTaskCleaner(1, 1, storage)
+		err := runPipeline(context.Background(), cleaner, storage, "")
+		assert.Nil(t, err)
+		// the index files are kept, the local files are removed
+		assert.Equal(t, 5, storage.objectNum(t))
+		assert.Equal(t, 0, storage.incompleteNum())
+		_, err = os.Stat(filepath.Join(Params.ScratchPath, diskIndexDirName, "1", "1"))
+		assert.True(t, os.IsNotExist(err))
+
+		// runs only once
+		cleaner.register(resourceObject, path.Join("index_files", "1", "1", "blob_0"), false)
+		cleaner.run(true)
+		assert.Equal(t, 5, storage.objectNum(t))
+	})
+}
+
+func TestTaskCleaner_forget(t *testing.T) {
+	var nilCleaner *taskCleaner
+	nilCleaner.register(resourceObject, "a", false)
+	nilCleaner.forget(resourceObject, "a")
+
+	cleaner := newTaskCleaner(1, 1, memkv.NewMemoryKV())
+	cleaner.register(resourceObject, "a", false)
+	cleaner.register(resourceMultipartUpload, "a", false)
+	cleaner.forget(resourceObject, "a")
+	cleaner.forget(resourceObject, "not_exist")
+	assert.Equal(t, []cleanupResource{{Kind: resourceMultipartUpload, Path: "a"}}, cleaner.resources)
+
+	assert.NotNil(t, cleanResource(memkv.NewMemoryKV(), cleanupResource{Kind: "unknown"}))
+	// the storage without multipart uploads has nothing to abort
+	assert.Nil(t, cleanResource(memkv.NewMemoryKV(), cleanupResource{Kind: resourceMultipartUpload, Path: "a"}))
+}
+
+func TestRetryPendingCleanups(t *testing.T) {
+	defer withScratchPath(t)()
+
+	storage := newMockMultipartStorage()
+	storage.removeErr = errors.New("access denied")
+	err := storage.Save("index_files/1/1/blob_0", "blob")
+	assert.Nil(t, err)
+	localPath := filepath.Join(Params.ScratchPath, "staging.tmp")
+	err = ioutil.WriteFile(localPath, []byte("data"), 0644)
+	assert.Nil(t, err)
+
+	cleaner := newTaskCleaner(1, 1, storage)
+	cleaner.register(resourceObject, "index_files/1/1/blob_0", true)
+	cleaner.register(resourceLocalPath, localPath, false)
+	err = cleaner.guard(func() error {
+		return errors.New("build failed")
+	})
+	assert.NotNil(t, err)
+	_, err = os.Stat(localPath)
+	assert.True(t, os.IsNotExist(err))
+
+	// the object failed to remove is recorded
+	value, err := ioutil.ReadFile(cleanupRecordPath(1, 1))
+	assert.Nil(t, err)
+	assert.Contains(t, string(value), "index_files/1/1/blob_0")
+
+	// fails again at next startup
+	retryPendingCleanups(storage)
+	_, err = os.Stat(cleanupRecordPath(1, 1))
+	assert.Nil(t, err)
+
+	storage.removeErr = nil
+	err = ioutil.WriteFile(filepath.Join(Params.ScratchPath, cleanupRecordsDirName, "2_1.json.tmp"), []byte("{"), 0644)
+	assert.Nil(t, err)
+	err = ioutil.WriteFile(filepath.Join(Params.ScratchPath, cleanupRecordsDirName, "3_1.json"), []byte("{"), 0644)
+	assert.Nil(t, err)
+	retryPendingCleanups(storage)
+	assert.Equal(t, 0, storage.objectNum(t))
+	files, err := ioutil.ReadDir(filepath.Join(Params.ScratchPath, cleanupRecordsDirName))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(files))
+}
@@ -0,0 +1,181 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/milvus-io/milvus/internal/kv"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/metricsinfo"
+)
+
+const (
+	// currentEngineVersion is the version of the index engine, it must be increased when the engine
+	// changes the format of the index data
+	currentEngineVersion int64 = 1
+	// currentSchemaVersion is the version of the serialization of the index files, it must be increased
+	// when the codec of the index files changes
+	currentSchemaVersion int64 = 1
+
+	// the user metadata keys of the index files stamped with the artifact version
+	engineVersionMetadataKey = "Engine-Version"
+	schemaVersionMetadataKey = "Schema-Version"
+
+	// indexMetaPrefix is the prefix of the index meta written by IndexCoord
+	indexMetaPrefix = "indexes"
+)
+
+// engineCompatibility maps the engine version to the oldest engine version whose indexes it can load.
+var engineCompatibility = map[int64]int64{
+	1: 0,
+}
+
+// schemaCompatibility maps the schema version to the schema versions of the index files it can decode.
+var schemaCompatibility = map[int64][]int64{
+	1: {0, 1},
+}
+
+func currentArtifactVersion() *indexpb.IndexArtifactVersion {
+	return &indexpb.IndexArtifactVersion{
+		EngineVersion: currentEngineVersion,
+		SchemaVersion: currentSchemaVersion,
+	}
+}
+
+// artifactObjectMetadata returns the user metadata stamped on the index files.
+func artifactObjectMetadata(version *indexpb.IndexArtifactVersion) map[string]string {
+	return map[string]string{
+		engineVersionMetadataKey: strconv.FormatInt(version.GetEngineVersion(), 10),
+		schemaVersionMetadataKey: strconv.FormatInt(version.GetSchemaVersion(), 10),
+	}
+}
+
+// metadataSaver is implemented by the object storage which saves objects along with user metadata.
+type metadataSaver interface {
+	SaveWithMetadata(key, value string, metadata map[string]string) error
+}
+
+// saveWithMetadata saves the object with the user metadata if the storage supports it.
+func saveWithMetadata(storage kv.BaseKV, key, value string, metadata map[string]string) error {
+	if saver, ok := storage.(metadataSaver); ok {
+		return saver.SaveWithMetadata(key, value, metadata)
+	}
+	return storage.Save(key, value)
+}
+
+// checkArtifactCompatibility checks whether the index built with @built can be loaded by the engine of @target,
+// nil means the index is built before versioning, whose versions are zero.
+func checkArtifactCompatibility(built, target *indexpb.IndexArtifactVersion) error {
+	builtEngine, builtSchema := built.GetEngineVersion(), built.GetSchemaVersion()
+	targetEngine, targetSchema := target.GetEngineVersion(), target.GetSchemaVersion()
+
+	oldestEngine, ok := engineCompatibility[targetEngine]
+	if !ok {
+		return fmt.Errorf("unknown engine version %d", targetEngine)
+	}
+	if builtEngine > targetEngine {
+		return fmt.Errorf("the index is built by engine version %d, newer than %d", builtEngine, targetEngine)
+	}
+	if builtEngine < oldestEngine {
+		return fmt.Errorf("the index is built by engine version %d, older than the oldest supported version %d",
+			builtEngine, oldestEngine)
+	}
+
+	schemas, ok := schemaCompatibility[targetSchema]
+	if !ok {
+		return fmt.Errorf("unknown schema version %d", targetSchema)
+	}
+	for _, schema := range schemas {
+		if schema == builtSchema {
+			return nil
+		}
+	}
+	return fmt.Errorf("the index files are serialized with schema version %d, which is not supported by schema version %d",
+		builtSchema, targetSchema)
+}
+
+// isBelowMinimumVersion returns whether the index built with @built is too old to be loaded by the engine of @target,
+// which can be fixed by rebuilding it, unlike the index built by a newer engine.
+func isBelowMinimumVersion(built, target *indexpb.IndexArtifactVersion) bool {
+	if checkArtifactCompatibility(built, target) == nil {
+		return false
+	}
+	return built.GetEngineVersion() <= target.GetEngineVersion() && built.GetSchemaVersion() <= target.GetSchemaVersion()
+}
+
+func toArtifactVersionInfo(version *indexpb.IndexArtifactVersion) metricsinfo.IndexArtifactVersion {
+	return metricsinfo.IndexArtifactVersion{
+		EngineVersion: version.GetEngineVersion(),
+		SchemaVersion: version.GetSchemaVersion(),
+	}
+}
+
+// checkIndexCompatibility checks whether the indexes of @buildIDs can be loaded by the current engine,
+// all the indexes in @metaKV are checked if no build id is given.
+func checkIndexCompatibility(metaKV kv.BaseKV, buildIDs []UniqueID) (*metricsinfo.IndexCompatibilityInfos, error) {
+	current := currentArtifactVersion()
+	infos := &metricsinfo.IndexCompatibilityInfos{
+		CurrentVersion: toArtifactVersionInfo(current),
+		Builds:         make([]metricsinfo.IndexBuildCompatibility, 0, len(buildIDs)),
+	}
+	addBuild := func(value string) error {
+		indexMeta := indexpb.IndexMeta{}
+		if err := proto.Unmarshal([]byte(value), &indexMeta); err != nil {
+			return err
+		}
+		build := metricsinfo.IndexBuildCompatibility{
+			IndexBuildID:    indexMeta.IndexBuildID,
+			ArtifactVersion: toArtifactVersionInfo(indexMeta.ArtifactVersion),
+			Compatible:      true,
+		}
+		if err := checkArtifactCompatibility(indexMeta.ArtifactVersion, current); err != nil {
+			build.Compatible = false
+			build.Reason = err.Error()
+		}
+		infos.Builds = append(infos.Builds, build)
+		return nil
+	}
+
+	if len(buildIDs) == 0 {
+		_, values, err := metaKV.LoadWithPrefix(indexMetaPrefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			if err := addBuild(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, buildID := range buildIDs {
+		value, err := metaKV.Load(path.Join(indexMetaPrefix, strconv.FormatInt(buildID, 10)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the index meta of build %d: %s", buildID, err.Error())
+		}
+		if value == "" {
+			return nil, fmt.Errorf("the index meta of build %d is not found", buildID)
+		}
+		if err := addBuild(value); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(infos.Builds, func(i, j int) bool {
+		return infos.Builds[i].IndexBuildID < infos.Builds[j].IndexBuildID
+	})
+	return infos, nil
+}
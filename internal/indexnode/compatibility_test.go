@@ -0,0 +1,161 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strconv"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/milvuspb"
+	"github.com/milvus-io/milvus/internal/util/metricsinfo"
+)
+
+type mockMetadataSaver struct {
+	*memkv.MemoryKV
+	metadata map[string]map[string]string
+}
+
+func (m *mockMetadataSaver) SaveWithMetadata(key, value string, metadata map[string]string) error {
+	m.metadata[key] = metadata
+	return m.Save(key, value)
+}
+
+func saveIndexMeta(t *testing.T, metaKV *memkv.MemoryKV, buildID UniqueID, version *indexpb.IndexArtifactVersion) {
+	value, err := proto.Marshal(&indexpb.IndexMeta{
+		IndexBuildID:    buildID,
+		State:           commonpb.IndexState_Finished,
+		ArtifactVersion: version,
+	})
+	assert.Nil(t, err)
+	err = metaKV.Save(path.Join(indexMetaPrefix, strconv.FormatInt(buildID, 10)), string(value))
+	assert.Nil(t, err)
+}
+
+func TestCheckArtifactCompatibility(t *testing.T) {
+	current := currentArtifactVersion()
+	assert.Nil(t, checkArtifactCompatibility(current, current))
+	// the index built before versioning
+	assert.Nil(t, checkArtifactCompatibility(nil, current))
+	assert.Nil(t, checkArtifactCompatibility(&indexpb.IndexArtifactVersion{}, current))
+
+	newerEngine := &indexpb.IndexArtifactVersion{EngineVersion: currentEngineVersion + 1, SchemaVersion: currentSchemaVersion}
+	assert.NotNil(t, checkArtifactCompatibility(newerEngine, current))
+	newerSchema := &indexpb.IndexArtifactVersion{EngineVersion: currentEngineVersion, SchemaVersion: currentSchemaVersion + 1}
+	assert.NotNil(t, checkArtifactCompatibility(newerSchema, current))
+	assert.NotNil(t, checkArtifactCompatibility(current, newerEngine))
+	assert.NotNil(t, checkArtifactCompatibility(current, newerSchema))
+
+	engineCompatibility[currentEngineVersion+1] = currentEngineVersion
+	defer delete(engineCompatibility, currentEngineVersion+1)
+	target := &indexpb.IndexArtifactVersion{EngineVersion: currentEngineVersion + 1, SchemaVersion: currentSchemaVersion}
+	assert.Nil(t, checkArtifactCompatibility(current, target))
+	assert.NotNil(t, checkArtifactCompatibility(nil, target))
+}
+
+func TestSaveWithMetadata(t *testing.T) {
+	metadata := artifactObjectMetadata(currentArtifactVersion())
+	assert.Equal(t, strconv.FormatInt(currentEngineVersion, 10), metadata[engineVersionMetadataKey])
+	assert.Equal(t, strconv.FormatInt(currentSchemaVersion, 10), metadata[schemaVersionMetadataKey])
+
+	saver := &mockMetadataSaver{MemoryKV: memkv.NewMemoryKV(), metadata: make(map[string]map[string]string)}
+	err := saveWithMetadata(saver, "key", "value", metadata)
+	assert.Nil(t, err)
+	assert.Equal(t, metadata, saver.metadata["key"])
+
+	// the storage without metadata support saves the object only
+	storage := memkv.NewMemoryKV()
+	err = saveWithMetadata(storage, "key", "value", metadata)
+	assert.Nil(t, err)
+	value, err := storage.Load("key")
+	assert.Nil(t, err)
+	assert.Equal(t, "value", value)
+}
+
+func TestCheckIndexCompatibility(t *testing.T) {
+	metaKV := memkv.NewMemoryKV()
+	saveIndexMeta(t, metaKV, 3, currentArtifactVersion())
+	saveIndexMeta(t, metaKV, 1, nil)
+	saveIndexMeta(t, metaKV, 2, &indexpb.IndexArtifactVersion{EngineVersion: currentEngineVersion + 1})
+
+	infos, err := checkIndexCompatibility(metaKV, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, currentEngineVersion, infos.CurrentVersion.EngineVersion)
+	assert.Equal(t, 3, len(infos.Builds))
+	assert.Equal(t, int64(1), infos.Builds[0].IndexBuildID)
+	assert.True(t, infos.Builds[0].Compatible)
+	assert.Equal(t, metricsinfo.IndexArtifactVersion{}, infos.Builds[0].ArtifactVersion)
+	assert.False(t, infos.Builds[1].Compatible)
+	assert.NotEqual(t, "", infos.Builds[1].Reason)
+	assert.True(t, infos.Builds[2].Compatible)
+
+	infos, err = checkIndexCompatibility(metaKV, []UniqueID{2})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(infos.Builds))
+	assert.False(t, infos.Builds[0].Compatible)
+
+	_, err = checkIndexCompatibility(metaKV, []UniqueID{4})
+	assert.NotNil(t, err)
+
+	err = metaKV.Save(path.Join(indexMetaPrefix, "5"), "invalid meta")
+	assert.Nil(t, err)
+	_, err = checkIndexCompatibility(metaKV, nil)
+	assert.NotNil(t, err)
+}
+
+func TestGetIndexCompatibilityMetrics(t *testing.T) {
+	ctx := context.Background()
+	metaKV := memkv.NewMemoryKV()
+	saveIndexMeta(t, metaKV, 1, currentArtifactVersion())
+	saveIndexMeta(t, metaKV, 2, nil)
+
+	request, err := json.Marshal(map[string]interface{}{
+		metricsinfo.MetricTypeKey: metricsinfo.IndexCompatibilityMetrics,
+		metricsinfo.BuildIDsKey:   []UniqueID{2},
+	})
+	assert.Nil(t, err)
+	resp, err := getIndexCompatibilityMetrics(ctx, &milvuspb.GetMetricsRequest{Request: string(request)}, metaKV)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+	infos := metricsinfo.IndexCompatibilityInfos{}
+	err = metricsinfo.UnmarshalComponentInfos(resp.Response, &infos)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(infos.Builds))
+	assert.Equal(t, int64(2), infos.Builds[0].IndexBuildID)
+
+	req, err := metricsinfo.ConstructRequestByMetricType(metricsinfo.IndexCompatibilityMetrics)
+	assert.Nil(t, err)
+	resp, err = getIndexCompatibilityMetrics(ctx, req, metaKV)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+	err = metricsinfo.UnmarshalComponentInfos(resp.Response, &infos)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(infos.Builds))
+
+	request = []byte(`{"metric_type": "index_compatibility", "build_ids": "1"}`)
+	resp, err = getIndexCompatibilityMetrics(ctx, &milvuspb.GetMetricsRequest{Request: string(request)}, metaKV)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.Status.ErrorCode)
+
+	request = []byte(`{"metric_type": "index_compatibility", "build_ids": [3]}`)
+	resp, err = getIndexCompatibilityMetrics(ctx, &milvuspb.GetMetricsRequest{Request: string(request)}, metaKV)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.Status.ErrorCode)
+}
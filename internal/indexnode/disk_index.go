@@ -0,0 +1,364 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/disk"
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/kv"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/encryption"
+	"github.com/milvus-io/milvus/internal/util/retry"
+)
+
+const (
+	// indexFilesDirKey is the index param telling the engine the local directory to write index files into
+	indexFilesDirKey = "index_files_dir"
+
+	// diskIndexDirName is the directory under the scratch path holding the directories of disk index tasks
+	diskIndexDirName = "index_files"
+
+	diskUsageCheckInterval = 10 * time.Second
+
+	// diskIndexUploadPartSize is the part size of the multipart upload of index files
+	diskIndexUploadPartSize = 64 * 1024 * 1024
+)
+
+// diskIndexTypes are the index types whose engine writes the index files to local disk directly.
+var diskIndexTypes = map[string]bool{
+	"DISKANN": true,
+}
+
+func isDiskIndexType(indexType string) bool {
+	return diskIndexTypes[strings.ToUpper(indexType)]
+}
+
+// fileUploader is implemented by the object storage which uploads local files with multipart upload.
+type fileUploader interface {
+	FPutObject(key, localPath string, partSize uint64, metadata map[string]string) error
+}
+
+// streamUploader is implemented by the object storage which uploads the streams of the known sizes with multipart
+// upload, e.g. the local files encrypted while they are being uploaded.
+type streamUploader interface {
+	PutObject(key string, reader io.Reader, size int64, partSize uint64, metadata map[string]string) error
+}
+
+// retryableError is an error caused by the local environment of IndexNode,
+// the task failed with it should be retried on another IndexNode.
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+func retryOnOtherNode(err error) error {
+	if err == nil || isRetryableOnOtherNode(err) {
+		return err
+	}
+	return &retryableError{err: err}
+}
+
+func isRetryableOnOtherNode(err error) bool {
+	var retryable *retryableError
+	return errors.As(err, &retryable)
+}
+
+// classifyDiskError marks the errors caused by the full local disk as retryable on another node.
+func classifyDiskError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, syscall.ENOSPC) || strings.Contains(strings.ToLower(err.Error()), "no space left on device") {
+		return retryOnOtherNode(err)
+	}
+	return err
+}
+
+// taskDiskDir is the local directory where the engine writes the index files of a task.
+type taskDiskDir struct {
+	path  string
+	quota int64
+
+	mu  sync.Mutex
+	err error
+}
+
+// newTaskDiskDir creates an empty directory for the task under the scratch path.
+func newTaskDiskDir(indexBuildID UniqueID, version int64, quota int64) (*taskDiskDir, error) {
+	dirPath := filepath.Join(Params.ScratchPath, diskIndexDirName,
+		strconv.FormatInt(indexBuildID, 10), strconv.FormatInt(version, 10))
+	if err := os.RemoveAll(dirPath); err != nil {
+		return nil, retryOnOtherNode(err)
+	}
+	if err := os.MkdirAll(dirPath, os.ModePerm); err != nil {
+		return nil, classifyDiskError(retryOnOtherNode(err))
+	}
+	return &taskDiskDir{
+		path:  dirPath,
+		quota: quota,
+	}, nil
+}
+
+// usage returns the total size of the files in the directory.
+func (d *taskDiskDir) usage() (int64, error) {
+	var size int64
+	err := filepath.Walk(d.path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// checkSpace checks whether the task exceeds its disk quota, or the local disk is full.
+func (d *taskDiskDir) checkSpace() error {
+	size, err := d.usage()
+	if err != nil {
+		return err
+	}
+	if d.quota > 0 && size > d.quota {
+		return fmt.Errorf("the index files of the task take %d bytes, exceed the disk quota %d bytes", size, d.quota)
+	}
+	usage, err := disk.Usage(d.path)
+	if err != nil {
+		return err
+	}
+	if usage.Free < scratchMinFreeSpace {
+		return retryOnOtherNode(fmt.Errorf("no space left on the local disk, free space is %d bytes", usage.Free))
+	}
+	return nil
+}
+
+// watch checks the disk space periodically until the returned function is called,
+// the first failure is kept and returned by spaceErr.
+func (d *taskDiskDir) watch(ctx context.Context) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(diskUsageCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := d.checkSpace(); err != nil {
+					storageLog.Warn("IndexNode disk index task is short of disk space", zap.String("path", d.path), zap.Error(err))
+					d.setSpaceErr(err)
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+func (d *taskDiskDir) setSpaceErr(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *taskDiskDir) spaceErr() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
+
+// files returns the paths relative to the directory and the sizes of the files in the directory.
+func (d *taskDiskDir) files() ([]*indexpb.IndexFileInfo, error) {
+	files := make([]*indexpb.IndexFileInfo, 0)
+	err := filepath.Walk(d.path, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(d.path, filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, &indexpb.IndexFileInfo{
+			FilePath: filepath.ToSlash(relPath),
+			FileSize: info.Size(),
+		})
+		return nil
+	})
+	return files, err
+}
+
+// putFunc uploads the local file of @size bytes to @savePath, and returns the size and the checksum of the object.
+type putFunc func(ctx context.Context, savePath, localPath string, size int64) (int64, string, error)
+
+// filePut returns the putFunc uploading the local files as they are, the checksum of each file is taken while it's
+// being uploaded by the object storage uploading the streams, instead of reading the file once more.
+func filePut(storage kv.BaseKV, metadata map[string]string) (putFunc, error) {
+	if uploader, ok := storage.(streamUploader); ok {
+		return func(ctx context.Context, savePath, localPath string, size int64) (int64, string, error) {
+			var checksum string
+			err := retry.Do(ctx, func() error {
+				file, err := os.Open(localPath)
+				if err != nil {
+					return retry.Unrecoverable(err)
+				}
+				defer file.Close()
+				hash := newChecksumHash()
+				if err := storageError(uploader.PutObject(savePath, io.TeeReader(file, hash), size,
+					diskIndexUploadPartSize, metadata)); err != nil {
+					return err
+				}
+				checksum = checksumOfHash(hash)
+				return nil
+			}, retry.Attempts(5))
+			return size, checksum, err
+		}, nil
+	}
+	uploader, ok := storage.(fileUploader)
+	if !ok {
+		return nil, errors.New("the object storage does not support uploading files")
+	}
+	return func(ctx context.Context, savePath, localPath string, size int64) (int64, string, error) {
+		checksum, err := fileChecksum(localPath)
+		if err != nil {
+			return 0, "", err
+		}
+		err = retry.Do(ctx, func() error {
+			return storageError(uploader.FPutObject(savePath, localPath, diskIndexUploadPartSize, metadata))
+		}, retry.Attempts(5))
+		return size, checksum, err
+	}, nil
+}
+
+// encryptedPut returns the putFunc uploading the local files encrypted by @key, which are encrypted while they are
+// being uploaded part by part instead of being encrypted into another file or the memory first.
+func encryptedPut(storage kv.BaseKV, metadata map[string]string, key []byte) (putFunc, error) {
+	uploader, ok := storage.(streamUploader)
+	if !ok {
+		return nil, errors.New("the object storage does not support uploading the encrypted files")
+	}
+	return func(ctx context.Context, savePath, localPath string, size int64) (int64, string, error) {
+		objectSize := encryption.EncryptedSize(size)
+		var checksum string
+		err := retry.Do(ctx, func() error {
+			file, err := os.Open(localPath)
+			if err != nil {
+				return retry.Unrecoverable(err)
+			}
+			defer file.Close()
+			encrypted, err := encryption.NewEncryptReader(key, file)
+			if err != nil {
+				return retry.Unrecoverable(err)
+			}
+			// the checksum of the object is taken while it's being uploaded, the files are encrypted differently
+			// each time
+			hash := newChecksumHash()
+			if err := storageError(uploader.PutObject(savePath, io.TeeReader(encrypted, hash), objectSize,
+				diskIndexUploadPartSize, metadata)); err != nil {
+				return err
+			}
+			checksum = checksumOfHash(hash)
+			return nil
+		}, retry.Attempts(5))
+		return objectSize, checksum, err
+	}, nil
+}
+
+// upload uploads the files in the directory with the user metadata, encrypted by @key if it's not nil, and returns
+// the manifest of the uploaded files in the object storage, the result of each file is recorded in the report. The
+// multipart uploads and the uploaded files are tracked by the cleaner.
+func (d *taskDiskDir) upload(ctx context.Context, storage kv.BaseKV, getSavePath func(file string) string,
+	metadata map[string]string, key []byte, report *persistReport, cleaner *taskCleaner) ([]*indexpb.IndexFileInfo, error) {
+	put, err := filePut(storage, metadata)
+	if key != nil {
+		put, err = encryptedPut(storage, metadata, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	files, err := d.files()
+	if err != nil {
+		return nil, err
+	}
+	savePaths := make([]string, len(files))
+	manifest := make([]*indexpb.IndexFileInfo, len(files))
+	for idx, file := range files {
+		savePaths[idx] = getSavePath(file.FilePath)
+		manifest[idx] = &indexpb.IndexFileInfo{
+			FilePath: savePaths[idx],
+			FileSize: file.FileSize,
+		}
+	}
+	uploadFile := func(idx int) error {
+		file := files[idx]
+		cleaner.register(resourceMultipartUpload, savePaths[idx], false)
+		size, checksum, err := put(ctx, savePaths[idx], filepath.Join(d.path, filepath.FromSlash(file.FilePath)), file.FileSize)
+		storageLog.Debug("IndexNode upload index file", zap.String("savePath", savePaths[idx]), zap.Int64("size", size), zap.Error(err))
+		if err == nil {
+			manifest[idx].FileSize, manifest[idx].Checksum = size, checksum
+			cleaner.forget(resourceMultipartUpload, savePaths[idx])
+			cleaner.register(resourceObject, savePaths[idx], true)
+		}
+		return err
+	}
+	report.persistFiles(savePaths, uploadFile, "uploadIndexFile")
+	if err := report.err(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// remove removes the directory along with all the files in it.
+func (d *taskDiskDir) remove() {
+	if err := os.RemoveAll(d.path); err != nil {
+		storageLog.Warn("IndexNode failed to remove the directory of disk index task", zap.String("path", d.path), zap.Error(err))
+	}
+}
+
+// cleanDiskIndexDirs removes the directories left by the disk index tasks of the last run.
+func cleanDiskIndexDirs() {
+	dirPath := filepath.Join(Params.ScratchPath, diskIndexDirName)
+	if err := os.RemoveAll(dirPath); err != nil {
+		storageLog.Warn("IndexNode failed to clean the directories of disk index tasks", zap.String("path", dirPath), zap.Error(err))
+	}
+}
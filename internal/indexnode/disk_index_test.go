@@ -0,0 +1,257 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/encryption"
+)
+
+type mockFileUploader struct {
+	*memkv.MemoryKV
+	mu       sync.Mutex
+	partSize uint64
+	metadata map[string]string
+	failKey  string
+}
+
+func (m *mockFileUploader) FPutObject(key, localPath string, partSize uint64, metadata map[string]string) error {
+	if key == m.failKey {
+		return errors.New("upload failed")
+	}
+	content, err := ioutil.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.partSize = partSize
+	m.metadata = metadata
+	m.mu.Unlock()
+	return m.Save(key, string(content))
+}
+
+func (m *mockFileUploader) PutObject(key string, reader io.Reader, size int64, partSize uint64, metadata map[string]string) error {
+	if key == m.failKey {
+		return errors.New("upload failed")
+	}
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if int64(len(content)) != size {
+		return fmt.Errorf("read %d bytes, expect %d bytes", len(content), size)
+	}
+	m.mu.Lock()
+	m.partSize = partSize
+	m.metadata = metadata
+	m.mu.Unlock()
+	return m.Save(key, string(content))
+}
+
+func withScratchPath(t *testing.T) func() {
+	scratchPath, err := ioutil.TempDir("", "indexnode_disk_index")
+	assert.Nil(t, err)
+	oldScratchPath := Params.ScratchPath
+	Params.ScratchPath = scratchPath
+	return func() {
+		Params.ScratchPath = oldScratchPath
+		os.RemoveAll(scratchPath)
+	}
+}
+
+func TestDiskIndex_errors(t *testing.T) {
+	assert.True(t, isDiskIndexType("DISKANN"))
+	assert.True(t, isDiskIndexType("diskann"))
+	assert.False(t, isDiskIndexType("IVF_FLAT"))
+	assert.False(t, isDiskIndexType(""))
+
+	err := errors.New("build failed")
+	assert.False(t, isRetryableOnOtherNode(err))
+	assert.Nil(t, retryOnOtherNode(nil))
+	retryable := retryOnOtherNode(err)
+	assert.True(t, isRetryableOnOtherNode(retryable))
+	assert.Equal(t, err.Error(), retryable.Error())
+	assert.True(t, errors.Is(retryable, err))
+	assert.Equal(t, retryable, retryOnOtherNode(retryable))
+
+	assert.Nil(t, classifyDiskError(nil))
+	assert.Equal(t, err, classifyDiskError(err))
+	assert.True(t, isRetryableOnOtherNode(classifyDiskError(&os.PathError{Op: "write", Path: "f", Err: syscall.ENOSPC})))
+	assert.True(t, isRetryableOnOtherNode(classifyDiskError(errors.New("write index file: No space left on device"))))
+}
+
+func TestTaskDiskDir(t *testing.T) {
+	defer withScratchPath(t)()
+
+	dir, err := newTaskDiskDir(1, 2, 1024)
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(Params.ScratchPath, diskIndexDirName, "1", "2"), dir.path)
+	size, err := dir.usage()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), size)
+
+	err = ioutil.WriteFile(filepath.Join(dir.path, "index"), make([]byte, 512), 0644)
+	assert.Nil(t, err)
+	err = os.MkdirAll(filepath.Join(dir.path, "data"), os.ModePerm)
+	assert.Nil(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir.path, "data", "0"), make([]byte, 256), 0644)
+	assert.Nil(t, err)
+
+	size, err = dir.usage()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(768), size)
+	assert.Nil(t, dir.checkSpace())
+
+	files, err := dir.files()
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []*indexpb.IndexFileInfo{
+		{FilePath: "data/0", FileSize: 256},
+		{FilePath: "index", FileSize: 512},
+	}, files)
+
+	t.Run("exceed quota", func(t *testing.T) {
+		err = ioutil.WriteFile(filepath.Join(dir.path, "data", "1"), make([]byte, 512), 0644)
+		assert.Nil(t, err)
+		err = dir.checkSpace()
+		assert.NotNil(t, err)
+		// the quota is the same on all nodes, retrying on another node does not help
+		assert.False(t, isRetryableOnOtherNode(err))
+
+		dir.setSpaceErr(err)
+		dir.setSpaceErr(errors.New("another error"))
+		assert.Equal(t, err, dir.spaceErr())
+	})
+
+	t.Run("recreate", func(t *testing.T) {
+		// the files left by the last try are removed
+		dir, err := newTaskDiskDir(1, 2, 0)
+		assert.Nil(t, err)
+		files, err := dir.files()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(files))
+		assert.Nil(t, dir.checkSpace())
+	})
+
+	dir.remove()
+	_, err = os.Stat(dir.path)
+	assert.True(t, os.IsNotExist(err))
+	_, err = dir.files()
+	assert.NotNil(t, err)
+
+	_, err = newTaskDiskDir(3, 1, 0)
+	assert.Nil(t, err)
+	cleanDiskIndexDirs()
+	_, err = os.Stat(filepath.Join(Params.ScratchPath, diskIndexDirName))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestTaskDiskDir_watch(t *testing.T) {
+	defer withScratchPath(t)()
+
+	dir, err := newTaskDiskDir(1, 1, 0)
+	assert.Nil(t, err)
+	defer dir.remove()
+	stop := dir.watch(context.Background())
+	stop()
+	assert.Nil(t, dir.spaceErr())
+}
+
+func TestTaskDiskDir_upload(t *testing.T) {
+	defer withScratchPath(t)()
+	ctx := context.Background()
+
+	dir, err := newTaskDiskDir(1, 1, 0)
+	assert.Nil(t, err)
+	defer dir.remove()
+	for i := 0; i < 3; i++ {
+		err = ioutil.WriteFile(filepath.Join(dir.path, fmt.Sprintf("file_%d", i)), []byte(fmt.Sprintf("content_%d", i)), 0644)
+		assert.Nil(t, err)
+	}
+	getSavePath := func(file string) string {
+		return path.Join("index_files", "1", file)
+	}
+
+	uploader := &mockFileUploader{MemoryKV: memkv.NewMemoryKV()}
+	metadata := artifactObjectMetadata(currentArtifactVersion())
+	manifest, err := dir.upload(ctx, uploader, getSavePath, metadata, nil, newPersistReport(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(diskIndexUploadPartSize), uploader.partSize)
+	assert.Equal(t, metadata, uploader.metadata)
+	sort.Slice(manifest, func(i, j int) bool {
+		return manifest[i].FilePath < manifest[j].FilePath
+	})
+	assert.Equal(t, 3, len(manifest))
+	for i, file := range manifest {
+		assert.Equal(t, getSavePath(fmt.Sprintf("file_%d", i)), file.FilePath)
+		assert.Equal(t, int64(len("content_0")), file.FileSize)
+		value, err := uploader.Load(file.FilePath)
+		assert.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf("content_%d", i), value)
+		assert.Equal(t, checksumOf([]byte(value)), file.Checksum)
+	}
+
+	uploader.failKey = getSavePath("file_1")
+	report := newPersistReport()
+	_, err = dir.upload(ctx, uploader, getSavePath, metadata, nil, report, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{getSavePath("file_0"), getSavePath("file_2")}, report.succeededFiles())
+	failed := report.failedFiles()
+	assert.Equal(t, 1, len(failed))
+	assert.Equal(t, getSavePath("file_1"), failed[0].path)
+	assert.Contains(t, failed[0].reason, "upload failed")
+
+	_, err = dir.upload(ctx, memkv.NewMemoryKV(), getSavePath, metadata, nil, newPersistReport(), nil)
+	assert.NotNil(t, err)
+
+	// the files are encrypted while they are being uploaded, whose sizes and checksums are of the encrypted ones
+	keyring, err := encryption.NewKeyring([]encryption.KEK{{ID: "k1", Key: make([]byte, encryption.KeySize)}})
+	assert.Nil(t, err)
+	dataKey, err := keyring.NewDataKey()
+	assert.Nil(t, err)
+	uploader = &mockFileUploader{MemoryKV: memkv.NewMemoryKV()}
+	manifest, err = dir.upload(ctx, uploader, getSavePath, metadata, dataKey.Key, newPersistReport(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(diskIndexUploadPartSize), uploader.partSize)
+	sort.Slice(manifest, func(i, j int) bool {
+		return manifest[i].FilePath < manifest[j].FilePath
+	})
+	assert.Equal(t, 3, len(manifest))
+	for i, file := range manifest {
+		value, err := uploader.Load(file.FilePath)
+		assert.Nil(t, err)
+		assert.Equal(t, encryption.EncryptedSize(int64(len("content_0"))), file.FileSize)
+		assert.Equal(t, int64(len(value)), file.FileSize)
+		assert.Equal(t, checksumOf([]byte(value)), file.Checksum)
+		plaintext, err := encryption.Decrypt(dataKey.Key, []byte(value))
+		assert.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf("content_%d", i), string(plaintext))
+	}
+	_, err = dir.upload(ctx, &struct{ *memkv.MemoryKV }{memkv.NewMemoryKV()}, getSavePath, metadata, dataKey.Key,
+		newPersistReport(), nil)
+	assert.NotNil(t, err)
+}
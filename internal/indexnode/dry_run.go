@@ -0,0 +1,552 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/kv"
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/funcutil"
+	"github.com/milvus-io/milvus/internal/util/indexparamcheck"
+)
+
+// the checks of a dry run, in the order they run
+const (
+	dryRunCheckParams    = "params"
+	dryRunCheckMeta      = "meta"
+	dryRunCheckBinlogs   = "binlogs"
+	dryRunCheckResources = "resources"
+	dryRunCheckStorage   = "storage"
+)
+
+// binlogHeadSize is the bytes loaded from the head of a binlog to read its first event, the rest of the
+// binlog is loaded only if the first event is larger.
+const binlogHeadSize = 64 * 1024
+
+// objectRangeKV is implemented by the object storages able to read a part of an object, the dry run loads
+// whole binlogs if the storage does not implement it.
+type objectRangeKV interface {
+	GetObjectSize(key string) (int64, error)
+	LoadRange(key string, offset, length int64) ([]byte, error)
+}
+
+// metaLoader loads the index meta without modifying it.
+type metaLoader interface {
+	Load(key string) (string, error)
+}
+
+// binlogSummary is what the dry run learns from the first event of a binlog.
+type binlogSummary struct {
+	path string
+	size int64
+	head *storage.BinlogHead
+	// rows and dim of the vectors in the first event, the dim is 0 if it is unknown before the vectors are read
+	rows int
+	dim  int
+}
+
+// estimatedRows estimates the rows of the binlog by its size, assuming the events are of similar sizes.
+func (s *binlogSummary) estimatedRows() int64 {
+	if s.head.FirstEventEnd <= 0 || s.size <= s.head.FirstEventEnd {
+		return int64(s.rows)
+	}
+	return int64(float64(s.rows) * float64(s.size) / float64(s.head.FirstEventEnd))
+}
+
+// loadBinlogHead loads the head of the binlog containing its descriptor and its first event.
+func loadBinlogHead(objects kv.BaseKV, dataPath string) ([]byte, int64, *storage.BinlogHead, error) {
+	rangeKV, ok := objects.(objectRangeKV)
+	if !ok {
+		value, err := objects.Load(dataPath)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		head, err := storage.ReadBinlogHead([]byte(value))
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		return []byte(value), int64(len(value)), head, nil
+	}
+	size, err := rangeKV.GetObjectSize(dataPath)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if size <= 0 {
+		return nil, 0, nil, fmt.Errorf("the binlog %s is empty", dataPath)
+	}
+	length := int64(binlogHeadSize)
+	if length > size {
+		length = size
+	}
+	data, err := rangeKV.LoadRange(dataPath, 0, length)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	head, err := storage.ReadBinlogHead(data)
+	if errors.Is(err, io.ErrUnexpectedEOF) && length < size {
+		// the descriptor is larger than the head, load the whole binlog
+		if data, err = rangeKV.LoadRange(dataPath, 0, size); err != nil {
+			return nil, 0, nil, err
+		}
+		head, err = storage.ReadBinlogHead(data)
+	}
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if head.FirstEventEnd > int64(len(data)) {
+		if head.FirstEventEnd > size {
+			return nil, 0, nil, fmt.Errorf("the first event of the binlog %s ends at %d beyond its size %d",
+				dataPath, head.FirstEventEnd, size)
+		}
+		if data, err = rangeKV.LoadRange(dataPath, 0, head.FirstEventEnd); err != nil {
+			return nil, 0, nil, err
+		}
+	}
+	return data, size, head, nil
+}
+
+// readBinlogSummary reads the vectors in the first event of the binlog.
+func readBinlogSummary(objects kv.BaseKV, dataPath string) (*binlogSummary, error) {
+	data, size, head, err := loadBinlogHead(objects, dataPath)
+	if err != nil {
+		return nil, err
+	}
+	summary := &binlogSummary{
+		path: dataPath,
+		size: size,
+		head: head,
+	}
+	if head.FirstEventType != storage.InsertEventType {
+		return nil, fmt.Errorf("the first event of the binlog %s is %s, expect %s", dataPath,
+			head.FirstEventType.String(), storage.InsertEventType.String())
+	}
+	if !isVectorDataType(head.PayloadDataType) {
+		// the rows are not needed since the binlog fails the check anyway
+		return summary, nil
+	}
+	reader, err := storage.NewBinlogReader(data[:head.FirstEventEnd])
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	event, err := reader.NextEventReader()
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, fmt.Errorf("the binlog %s has no event", dataPath)
+	}
+	switch head.PayloadDataType {
+	case schemapb.DataType_FloatVector:
+		vectors, dim, err := event.GetFloatVectorFromPayload()
+		if err != nil {
+			return nil, err
+		}
+		summary.dim = dim
+		if dim > 0 {
+			summary.rows = len(vectors) / dim
+		}
+	case schemapb.DataType_BinaryVector:
+		vectors, dim, err := event.GetBinaryVectorFromPayload()
+		if err != nil {
+			return nil, err
+		}
+		summary.dim = dim
+		if checkBinaryDim(dim) == nil {
+			summary.rows = len(vectors) / binaryVectorBytes(dim)
+		}
+	default:
+		vectors, dim, err := event.GetDataFromPayload()
+		if err != nil {
+			return nil, err
+		}
+		summary.dim = dim
+		if dim > 0 {
+			summary.rows = len(vectors.([]byte)) / (dim * halfFloatBytes)
+		}
+	}
+	return summary, nil
+}
+
+// dryRun performs the checks of a build without building and uploading the index, nothing is written to
+// the object storage or the meta.
+type dryRun struct {
+	req         *indexpb.CreateIndexRequest
+	objects     kv.BaseKV
+	meta        metaLoader
+	admission   *admissionGuard
+	probe       *readinessProbe
+	simd        *simdSwitcher
+	memoryUsage func() (used uint64, total uint64, err error)
+	freeSpace   func() (uint64, error)
+	// readBinlog is a variable so that tests can mock it
+	readBinlog func(objects kv.BaseKV, dataPath string) (*binlogSummary, error)
+
+	resp        *indexpb.DryRunCreateIndexResponse
+	typeParams  map[string]string
+	indexParams map[string]string
+	binlogSize  int64
+}
+
+func (i *IndexNode) newDryRun(req *indexpb.CreateIndexRequest) *dryRun {
+	var meta metaLoader
+	if i.etcdKV != nil {
+		meta = i.etcdKV
+	}
+	return &dryRun{
+		req:         req,
+		objects:     i.kv,
+		meta:        meta,
+		admission:   i.admission,
+		probe:       i.probe,
+		simd:        i.simd,
+		memoryUsage: nodeMemoryUsage,
+		freeSpace:   scratchFreeSpace,
+		readBinlog:  readBinlogSummary,
+	}
+}
+
+func (d *dryRun) addCheck(name string, err error, detail string) {
+	check := &indexpb.DryRunCheck{
+		Name:   name,
+		Passed: err == nil,
+		Detail: detail,
+	}
+	if err != nil {
+		check.Detail = err.Error()
+	}
+	d.resp.Checks = append(d.resp.Checks, check)
+}
+
+// run performs all the checks and returns the report, the report passes only if all the checks pass.
+func (d *dryRun) run() *indexpb.DryRunCreateIndexResponse {
+	d.resp = &indexpb.DryRunCreateIndexResponse{
+		Status: &commonpb.Status{ErrorCode: commonpb.ErrorCode_Success},
+	}
+	detail, err := d.checkParams()
+	d.addCheck(dryRunCheckParams, err, detail)
+	detail, err = d.checkMeta()
+	d.addCheck(dryRunCheckMeta, err, detail)
+	detail, err = d.checkBinlogs()
+	d.addCheck(dryRunCheckBinlogs, err, detail)
+	detail, err = d.checkResources()
+	d.addCheck(dryRunCheckResources, err, detail)
+	detail, err = d.checkStorage()
+	d.addCheck(dryRunCheckStorage, err, detail)
+
+	d.resp.Passed = true
+	for _, check := range d.resp.Checks {
+		d.resp.Passed = d.resp.Passed && check.Passed
+	}
+	return d.resp
+}
+
+func (d *dryRun) checkParams() (string, error) {
+	var err error
+	d.typeParams, d.indexParams, err = parseBuildParams(d.req)
+	if err != nil {
+		return "", err
+	}
+	indexType := d.indexParams[indexTypeKey]
+	d.resp.IndexType = indexType
+	if indexType == "" {
+		return "", errors.New("the index type is not specified")
+	}
+	if !isKnownIndexType(indexType) {
+		return "", fmt.Errorf("unknown index type %s", indexType)
+	}
+	if err := indexparamcheck.CheckMetricCompatibility(indexType, d.indexParams[metricTypeKey], ""); err != nil {
+		return "", err
+	}
+	d.resp.SimdType = Params.simdTypeOf(indexType)
+	if d.resp.SimdType == "" && d.simd != nil {
+		d.resp.SimdType = d.simd.defaultType
+	}
+	d.resp.Pipelined = Params.BuildChunkRows > 0 && supportsIncrementalAdd(indexType)
+
+	dimValue, ok := d.typeParams[dimKey]
+	if !ok {
+		dimValue, ok = d.indexParams[dimKey]
+	}
+	if !ok {
+		return "", errors.New("the dim is not specified")
+	}
+	dim, err := strconv.ParseInt(dimValue, 10, 64)
+	if err != nil || dim <= 0 {
+		return "", fmt.Errorf("invalid dim %q, expect a positive integer", dimValue)
+	}
+	d.resp.Dim = dim
+	normalize, err := normalizeOf(d.indexParams)
+	if err != nil {
+		return "", err
+	}
+	if isBinaryIndexType(indexType) {
+		if err := checkBinaryDim(int(dim)); err != nil {
+			return "", err
+		}
+		if err := checkNormalize(normalize, schemapb.DataType_BinaryVector); err != nil {
+			return "", err
+		}
+	}
+
+	if !isDiskIndexType(indexType) {
+		adapter, err := indexparamcheck.GetConfAdapterMgrInstance().GetAdapter(strings.ToUpper(indexType))
+		if err != nil {
+			return "", err
+		}
+		params := make(map[string]string, len(d.typeParams)+len(d.indexParams))
+		for k, v := range d.typeParams {
+			params[k] = v
+		}
+		for k, v := range d.indexParams {
+			params[k] = v
+		}
+		delete(params, normalizeKey)
+		delete(params, buildSeedKey)
+		if !adapter.CheckTrain(params) {
+			return "", fmt.Errorf("invalid params of index type %s: %v", indexType, d.indexParams)
+		}
+	}
+	if err := checkAppendBuild(d.req); err != nil {
+		return "", err
+	}
+	seed, err := buildSeedOf(d.indexParams)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case seed == nil:
+		return fmt.Sprintf("index type: %s, dim: %d", indexType, dim), nil
+	case !seed.Honored:
+		return fmt.Sprintf("index type: %s, dim: %d, build seed: %d, %s", indexType, dim, seed.Seed,
+			buildSeedIgnoredReason(indexType)), nil
+	case isSerialSeededBuild(indexType, seed):
+		return fmt.Sprintf("index type: %s, dim: %d, build seed: %d, built single-threaded", indexType, dim, seed.Seed), nil
+	default:
+		return fmt.Sprintf("index type: %s, dim: %d, build seed: %d", indexType, dim, seed.Seed), nil
+	}
+}
+
+func (d *dryRun) checkMeta() (string, error) {
+	if d.meta == nil {
+		return "", errors.New("the meta store is not initialized")
+	}
+	value, err := d.meta.Load(d.req.MetaPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to load the index meta %s: %s", d.req.MetaPath, err.Error())
+	}
+	if value == "" {
+		return "", fmt.Errorf("the index meta %s is empty", d.req.MetaPath)
+	}
+	indexMeta := indexpb.IndexMeta{}
+	if err = proto.Unmarshal([]byte(value), &indexMeta); err != nil {
+		return "", fmt.Errorf("failed to unmarshal the index meta %s: %s", d.req.MetaPath, err.Error())
+	}
+	switch {
+	case indexMeta.MarkDeleted:
+		return "", fmt.Errorf("the index has been deleted with indexBuildID %d", indexMeta.IndexBuildID)
+	case indexMeta.Version > d.req.Version:
+		return "", fmt.Errorf("the task has been reassigned, the version %d is older than %d", d.req.Version, indexMeta.Version)
+	case indexMeta.State == commonpb.IndexState_Finished:
+		return "", fmt.Errorf("the index has been built with version %d", indexMeta.Version)
+	}
+	return fmt.Sprintf("version: %d, state: %s", indexMeta.Version, indexMeta.State.String()), nil
+}
+
+func (d *dryRun) checkBinlogs() (string, error) {
+	dataPaths := d.req.GetDataPaths()
+	d.resp.BinlogNum = int64(len(dataPaths))
+	if len(dataPaths) == 0 {
+		return "", errors.New("no binlog to build index on")
+	}
+	format, err := segmentFormatOf(d.req)
+	if err != nil {
+		return "", err
+	}
+	readBinlog := d.readBinlog
+	if format == segmentFormatParquet {
+		readBinlog = func(objects kv.BaseKV, dataPath string) (*binlogSummary, error) {
+			return readParquetSummary(objects, dataPath, d.req.GetFieldSchema())
+		}
+	}
+	summaries := make([]*binlogSummary, len(dataPaths))
+	readFn := func(idx int) error {
+		summary, err := readBinlog(d.objects, dataPaths[idx])
+		if err != nil {
+			return fmt.Errorf("failed to read the binlog %s: %s", dataPaths[idx], err.Error())
+		}
+		summaries[idx] = summary
+		return nil
+	}
+	if err := funcutil.ProcessFuncParallel(len(dataPaths), runtime.NumCPU(), readFn, "readBinlog"); err != nil {
+		return "", err
+	}
+
+	first := summaries[0].head
+	for _, summary := range summaries {
+		d.binlogSize += summary.size
+		d.resp.BinlogSize += summary.size
+		d.resp.EstimatedRows += summary.estimatedRows()
+	}
+	for _, summary := range summaries {
+		head := summary.head
+		if !isVectorDataType(head.PayloadDataType) {
+			return "", fmt.Errorf("the binlog %s is of %s, expect a vector field", summary.path, head.PayloadDataType.String())
+		}
+		if head.FieldID != first.FieldID || head.SegmentID != first.SegmentID || head.PayloadDataType != first.PayloadDataType {
+			return "", fmt.Errorf("the binlog %s is of field %d of segment %d, which differs from field %d of segment %d",
+				summary.path, head.FieldID, head.SegmentID, first.FieldID, first.SegmentID)
+		}
+		if d.resp.Dim > 0 && summary.dim > 0 && int64(summary.dim) != d.resp.Dim {
+			return "", fmt.Errorf("the dim of the binlog %s is %d, which mismatches the dim %d of the params",
+				summary.path, summary.dim, d.resp.Dim)
+		}
+	}
+	if err := checkElementType(d.req.GetFieldSchema(), first.PayloadDataType); err != nil {
+		return "", err
+	}
+	// the normalize param is validated by checkParams
+	normalize, _ := normalizeOf(d.indexParams)
+	if err := checkNormalize(normalize, first.PayloadDataType); err != nil {
+		return "", err
+	}
+	if err := indexparamcheck.CheckMetricCompatibility(d.resp.IndexType, d.indexParams[metricTypeKey],
+		vectorTypeOf(first.PayloadDataType)); err != nil {
+		return "", err
+	}
+	d.resp.IndexFilePrefix = path.Join(Params.IndexRootPath, strconv.FormatInt(d.req.IndexBuildID, 10),
+		strconv.FormatInt(d.req.Version, 10), strconv.FormatInt(first.PartitionID, 10), strconv.FormatInt(first.SegmentID, 10))
+	return fmt.Sprintf("collection: %d, partition: %d, segment: %d, field: %d, %s", first.CollectionID,
+		first.PartitionID, first.SegmentID, first.FieldID, first.PayloadDataType.String()), nil
+}
+
+func (d *dryRun) checkResources() (string, error) {
+	if admissible, reason := d.admission.admissible(); !admissible {
+		return "", fmt.Errorf("new tasks are paused: %s", reason)
+	}
+	d.resp.EstimatedMemory = estimateBuildMemory(d.resp.IndexType, d.binlogSize)
+	details := []string{fmt.Sprintf("estimated memory: %d bytes", d.resp.EstimatedMemory)}
+	used, total, err := d.memoryUsage()
+	if err != nil {
+		details = append(details, "available memory is unknown: "+err.Error())
+	} else if total > used {
+		available := int64(total - used)
+		if d.resp.EstimatedMemory > available {
+			return "", fmt.Errorf("the estimated memory %d bytes exceeds the available memory %d bytes",
+				d.resp.EstimatedMemory, available)
+		}
+		details = append(details, fmt.Sprintf("available memory: %d bytes", available))
+	}
+	if isDiskIndexType(d.resp.IndexType) {
+		// the local index files are at least as large as the raw vectors
+		if Params.TaskDiskQuota > 0 && d.binlogSize > Params.TaskDiskQuota {
+			return "", fmt.Errorf("the binlogs of %d bytes exceed the disk quota %d bytes of a task",
+				d.binlogSize, Params.TaskDiskQuota)
+		}
+		free, err := d.freeSpace()
+		if err != nil {
+			details = append(details, "free space of scratch path is unknown: "+err.Error())
+		} else {
+			if int64(free) < d.binlogSize {
+				return "", fmt.Errorf("the binlogs of %d bytes exceed the free space %d bytes of scratch path",
+					d.binlogSize, free)
+			}
+			details = append(details, fmt.Sprintf("free space of scratch path: %d bytes", free))
+		}
+	}
+	return strings.Join(details, ", "), nil
+}
+
+// checkStorage reports the last storage check of the readiness probe, which writes, reads and removes an
+// object periodically, since the dry run itself must not write the object storage.
+func (d *dryRun) checkStorage() (string, error) {
+	if d.probe == nil {
+		return "", errors.New("object storage has not been checked yet")
+	}
+	checkTime, err := d.probe.check(probeStorage)
+	if err != nil {
+		return "", fmt.Errorf("object storage is not writable: %s", err.Error())
+	}
+	return fmt.Sprintf("object storage is writable, last checked at %s", checkTime.Format(time.RFC3339)), nil
+}
+
+// failedChecks returns the reasons of the failed checks of the report.
+func failedChecks(resp *indexpb.DryRunCreateIndexResponse) string {
+	reasons := make([]string, 0, len(resp.Checks))
+	for _, check := range resp.Checks {
+		if !check.Passed {
+			reasons = append(reasons, check.Name+": "+check.Detail)
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// dryRunCheckCodes are the codes of the failures of the dry run checks
+var dryRunCheckCodes = map[string]errorcode.Code{
+	dryRunCheckParams:    errorcode.InvalidParams,
+	dryRunCheckMeta:      errorcode.InvalidParams,
+	dryRunCheckBinlogs:   errorcode.InvalidParams,
+	dryRunCheckResources: errorcode.Busy,
+	dryRunCheckStorage:   errorcode.StorageTransient,
+}
+
+// dryRunFailureCode returns the code of the failure of the report, which is the code of the first failed check.
+func dryRunFailureCode(resp *indexpb.DryRunCreateIndexResponse) errorcode.Code {
+	for _, check := range resp.Checks {
+		if code, ok := dryRunCheckCodes[check.Name]; ok && !check.Passed {
+			return code
+		}
+	}
+	return errorcode.Internal
+}
+
+// DryRunCreateIndex validates the request of building an index without building it, the returned report
+// tells what the build would do and the problems found.
+func (i *IndexNode) DryRunCreateIndex(ctx context.Context, request *indexpb.CreateIndexRequest) (*indexpb.DryRunCreateIndexResponse, error) {
+	if !i.isHealthy() {
+		return &indexpb.DryRunCreateIndexResponse{
+			Status: i.notReadyStatus(),
+		}, nil
+	}
+	return i.dryRunCreateIndex(request), nil
+}
+
+func (i *IndexNode) dryRunCreateIndex(request *indexpb.CreateIndexRequest) *indexpb.DryRunCreateIndexResponse {
+	resp := i.newDryRun(request).run()
+	log.Info("IndexNode dry run of building index",
+		zap.Int64("IndexBuildID", request.IndexBuildID),
+		zap.Int64("Version", request.Version),
+		zap.Bool("Passed", resp.Passed),
+		zap.String("IndexType", resp.IndexType),
+		zap.Int64("BinlogSize", resp.BinlogSize),
+		zap.Int64("EstimatedRows", resp.EstimatedRows),
+		zap.Int64("EstimatedMemory", resp.EstimatedMemory),
+		zap.String("FailedChecks", failedChecks(resp)))
+	return resp
+}
@@ -0,0 +1,363 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/kv"
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+// mockRangeKV records the bytes loaded by the range reads.
+type mockRangeKV struct {
+	*memkv.MemoryKV
+	loadedBytes int64
+}
+
+func (kv *mockRangeKV) GetObjectSize(key string) (int64, error) {
+	value, err := kv.MemoryKV.Load(key)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(value)), nil
+}
+
+func (kv *mockRangeKV) LoadRange(key string, offset, length int64) ([]byte, error) {
+	value, err := kv.MemoryKV.Load(key)
+	if err != nil {
+		return nil, err
+	}
+	end := offset + length
+	if end > int64(len(value)) {
+		end = int64(len(value))
+	}
+	kv.loadedBytes += end - offset
+	return []byte(value[offset:end]), nil
+}
+
+func TestLoadBinlogHead(t *testing.T) {
+	rangeKV := &mockRangeKV{MemoryKV: memkv.NewMemoryKV()}
+	err := rangeKV.Save("binlog", strings.Repeat("x", 4*binlogHeadSize))
+	assert.Nil(t, err)
+	// only the head is loaded to find out the binlog is invalid
+	_, _, _, err = loadBinlogHead(rangeKV, "binlog")
+	assert.NotNil(t, err)
+	assert.Equal(t, int64(binlogHeadSize), rangeKV.loadedBytes)
+
+	_, _, _, err = loadBinlogHead(rangeKV, "not_exist")
+	assert.NotNil(t, err)
+	err = rangeKV.Save("empty", "")
+	assert.Nil(t, err)
+	_, _, _, err = loadBinlogHead(rangeKV, "empty")
+	assert.NotNil(t, err)
+
+	// the storage unable to read a part of an object
+	plainKV := memkv.NewMemoryKV()
+	err = plainKV.Save("binlog", "xx")
+	assert.Nil(t, err)
+	_, _, _, err = loadBinlogHead(plainKV, "binlog")
+	assert.NotNil(t, err)
+	_, _, _, err = loadBinlogHead(plainKV, "not_exist")
+	assert.NotNil(t, err)
+}
+
+func newDryRunForTest(t *testing.T, req *indexpb.CreateIndexRequest, indexMeta *indexpb.IndexMeta) *dryRun {
+	meta := memkv.NewMemoryKV()
+	if indexMeta != nil {
+		value, err := proto.Marshal(indexMeta)
+		assert.Nil(t, err)
+		err = meta.Save(req.MetaPath, string(value))
+		assert.Nil(t, err)
+	}
+	probe := newReadinessProbe()
+	probe.update(probeStorage, nil)
+	return &dryRun{
+		req:       req,
+		objects:   memkv.NewMemoryKV(),
+		meta:      meta,
+		admission: newAdmissionGuard(watermarks{}, nodeMemoryUsage, scratchFreeSpace),
+		probe:     probe,
+		memoryUsage: func() (uint64, uint64, error) {
+			return 1000, 1 << 30, nil
+		},
+		freeSpace: func() (uint64, error) {
+			return 1 << 30, nil
+		},
+		readBinlog: func(objects kv.BaseKV, dataPath string) (*binlogSummary, error) {
+			return &binlogSummary{
+				path: dataPath,
+				size: 1000,
+				head: &storage.BinlogHead{
+					DescriptorEventDataFixPart: storage.DescriptorEventDataFixPart{
+						CollectionID:    1,
+						PartitionID:     2,
+						SegmentID:       3,
+						FieldID:         100,
+						PayloadDataType: schemapb.DataType_FloatVector,
+					},
+					FirstEventType: storage.InsertEventType,
+					FirstEventEnd:  500,
+				},
+				rows: 10,
+				dim:  8,
+			}, nil
+		},
+	}
+}
+
+func dryRunCheckOf(resp *indexpb.DryRunCreateIndexResponse, name string) *indexpb.DryRunCheck {
+	for _, check := range resp.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	return nil
+}
+
+func TestDryRun(t *testing.T) {
+	newRequest := func() *indexpb.CreateIndexRequest {
+		return &indexpb.CreateIndexRequest{
+			IndexBuildID: 10,
+			Version:      2,
+			MetaPath:     "indexes/10",
+			DataPaths:    []string{"insert_log/1/2/3/100/1", "insert_log/1/2/3/100/2"},
+			TypeParams:   []*commonpb.KeyValuePair{{Key: dimKey, Value: "8"}},
+			IndexParams: []*commonpb.KeyValuePair{
+				{Key: indexTypeKey, Value: "IVF_SQ8"},
+				{Key: "metric_type", Value: "L2"},
+				{Key: "nlist", Value: "100"},
+			},
+		}
+	}
+	indexMeta := &indexpb.IndexMeta{IndexBuildID: 10, Version: 2, State: commonpb.IndexState_Unissued}
+
+	resp := newDryRunForTest(t, newRequest(), indexMeta).run()
+	assert.True(t, resp.Passed, failedChecks(resp))
+	assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+	assert.Equal(t, 5, len(resp.Checks))
+	assert.Equal(t, "IVF_SQ8", resp.IndexType)
+	assert.Equal(t, int64(8), resp.Dim)
+	assert.Equal(t, int64(2), resp.BinlogNum)
+	assert.Equal(t, int64(2000), resp.BinlogSize)
+	assert.Equal(t, int64(40), resp.EstimatedRows)
+	assert.Equal(t, estimateBuildMemory("IVF_SQ8", 2000), resp.EstimatedMemory)
+	assert.True(t, strings.HasSuffix(resp.IndexFilePrefix, "10/2/2/3"))
+	assert.Equal(t, "", failedChecks(resp))
+
+	t.Run("invalid params", func(t *testing.T) {
+		req := newRequest()
+		req.TypeParams = []*commonpb.KeyValuePair{{Key: dimKey, Value: "0"}}
+		resp := newDryRunForTest(t, req, indexMeta).run()
+		assert.False(t, resp.Passed)
+		assert.False(t, dryRunCheckOf(resp, dryRunCheckParams).Passed)
+
+		req = newRequest()
+		req.IndexParams = []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: "UNKNOWN"}}
+		resp = newDryRunForTest(t, req, indexMeta).run()
+		assert.False(t, dryRunCheckOf(resp, dryRunCheckParams).Passed)
+
+		req = newRequest()
+		req.IndexParams = append(req.IndexParams, &commonpb.KeyValuePair{Key: "nlist", Value: "0"})
+		resp = newDryRunForTest(t, req, indexMeta).run()
+		assert.False(t, dryRunCheckOf(resp, dryRunCheckParams).Passed)
+		assert.True(t, strings.Contains(failedChecks(resp), "duplicated key"))
+
+		req = newRequest()
+		req.IndexParams[1].Value = "SUBSTRUCTURE"
+		resp = newDryRunForTest(t, req, indexMeta).run()
+		assert.False(t, dryRunCheckOf(resp, dryRunCheckParams).Passed)
+		assert.True(t, strings.Contains(failedChecks(resp), "allowed metric types: L2,IP"))
+
+		// normalize is handled by IndexNode instead of the engine, and never on the binary vectors
+		req = newRequest()
+		req.IndexParams = append(req.IndexParams, &commonpb.KeyValuePair{Key: normalizeKey, Value: "true"})
+		resp = newDryRunForTest(t, req, indexMeta).run()
+		assert.True(t, resp.Passed, failedChecks(resp))
+
+		req = newRequest()
+		req.IndexParams = append(req.IndexParams, &commonpb.KeyValuePair{Key: normalizeKey, Value: "yes"})
+		resp = newDryRunForTest(t, req, indexMeta).run()
+		assert.False(t, dryRunCheckOf(resp, dryRunCheckParams).Passed)
+
+		req = newRequest()
+		req.IndexParams = []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: "BIN_FLAT"}, {Key: "metric_type", Value: "HAMMING"},
+			{Key: normalizeKey, Value: "true"}}
+		resp = newDryRunForTest(t, req, indexMeta).run()
+		assert.False(t, dryRunCheckOf(resp, dryRunCheckParams).Passed)
+		assert.True(t, strings.Contains(failedChecks(resp), "normalize is not supported by BinaryVector"))
+
+		// the build seed not honored by the engine is reported instead of being ignored
+		req = newRequest()
+		req.IndexParams = append(req.IndexParams, &commonpb.KeyValuePair{Key: buildSeedKey, Value: "7"})
+		resp = newDryRunForTest(t, req, indexMeta).run()
+		assert.True(t, resp.Passed, failedChecks(resp))
+		assert.Equal(t, "index type: IVF_SQ8, dim: 8, build seed: 7", dryRunCheckOf(resp, dryRunCheckParams).Detail)
+
+		req = newRequest()
+		req.IndexParams = []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: "ANNOY"}, {Key: "metric_type", Value: "L2"},
+			{Key: "n_trees", Value: "8"}, {Key: buildSeedKey, Value: "7"}}
+		resp = newDryRunForTest(t, req, indexMeta).run()
+		assert.True(t, resp.Passed, failedChecks(resp))
+		assert.True(t, strings.HasSuffix(dryRunCheckOf(resp, dryRunCheckParams).Detail, "not honored by the engine"))
+
+		req = newRequest()
+		req.IndexParams = append(req.IndexParams, &commonpb.KeyValuePair{Key: buildSeedKey, Value: "-1"})
+		resp = newDryRunForTest(t, req, indexMeta).run()
+		assert.False(t, dryRunCheckOf(resp, dryRunCheckParams).Passed)
+	})
+
+	t.Run("meta", func(t *testing.T) {
+		resp := newDryRunForTest(t, newRequest(), nil).run()
+		assert.False(t, dryRunCheckOf(resp, dryRunCheckMeta).Passed)
+		assert.True(t, dryRunCheckOf(resp, dryRunCheckParams).Passed)
+
+		for _, meta := range []*indexpb.IndexMeta{
+			{IndexBuildID: 10, Version: 3},
+			{IndexBuildID: 10, Version: 2, MarkDeleted: true},
+			{IndexBuildID: 10, Version: 2, State: commonpb.IndexState_Finished},
+		} {
+			resp := newDryRunForTest(t, newRequest(), meta).run()
+			assert.False(t, resp.Passed)
+			assert.False(t, dryRunCheckOf(resp, dryRunCheckMeta).Passed)
+		}
+	})
+
+	t.Run("binlogs", func(t *testing.T) {
+		req := newRequest()
+		req.DataPaths = nil
+		resp := newDryRunForTest(t, req, indexMeta).run()
+		assert.False(t, dryRunCheckOf(resp, dryRunCheckBinlogs).Passed)
+
+		d := newDryRunForTest(t, newRequest(), indexMeta)
+		d.readBinlog = func(objects kv.BaseKV, dataPath string) (*binlogSummary, error) {
+			return nil, errors.New("access denied")
+		}
+		resp = d.run()
+		assert.True(t, strings.Contains(dryRunCheckOf(resp, dryRunCheckBinlogs).Detail, "access denied"))
+
+		mutations := []func(summary *binlogSummary){
+			func(summary *binlogSummary) { summary.dim = 16 },
+			func(summary *binlogSummary) { summary.head.FieldID = 101 },
+			func(summary *binlogSummary) { summary.head.PayloadDataType = schemapb.DataType_Int64 },
+		}
+		for _, mutate := range mutations {
+			d := newDryRunForTest(t, newRequest(), indexMeta)
+			readBinlog := d.readBinlog
+			d.readBinlog = func(objects kv.BaseKV, dataPath string) (*binlogSummary, error) {
+				summary, err := readBinlog(objects, dataPath)
+				if strings.HasSuffix(dataPath, "/2") {
+					mutate(summary)
+				}
+				return summary, err
+			}
+			resp := d.run()
+			assert.False(t, resp.Passed)
+			assert.False(t, dryRunCheckOf(resp, dryRunCheckBinlogs).Passed)
+		}
+
+		// the float16 binlogs pass for the index types of the float vectors if the schema agrees
+		for _, schemaType := range []schemapb.DataType{schemapb.DataType_Float16Vector, schemapb.DataType_FloatVector} {
+			req := newRequest()
+			req.FieldSchema = &schemapb.FieldSchema{FieldID: 100, DataType: schemaType}
+			d := newDryRunForTest(t, req, indexMeta)
+			readBinlog := d.readBinlog
+			d.readBinlog = func(objects kv.BaseKV, dataPath string) (*binlogSummary, error) {
+				summary, err := readBinlog(objects, dataPath)
+				summary.head.PayloadDataType = schemapb.DataType_Float16Vector
+				return summary, err
+			}
+			resp := d.run()
+			assert.Equal(t, schemaType == schemapb.DataType_Float16Vector, dryRunCheckOf(resp, dryRunCheckBinlogs).Passed,
+				dryRunCheckOf(resp, dryRunCheckBinlogs).Detail)
+		}
+	})
+
+	t.Run("resources", func(t *testing.T) {
+		d := newDryRunForTest(t, newRequest(), indexMeta)
+		d.memoryUsage = func() (uint64, uint64, error) {
+			return 1000, 2000, nil
+		}
+		resp := d.run()
+		assert.False(t, dryRunCheckOf(resp, dryRunCheckResources).Passed)
+
+		d = newDryRunForTest(t, newRequest(), indexMeta)
+		d.memoryUsage = func() (uint64, uint64, error) {
+			return 0, 0, errors.New("permission denied")
+		}
+		resp = d.run()
+		assert.True(t, dryRunCheckOf(resp, dryRunCheckResources).Passed)
+
+		d = newDryRunForTest(t, newRequest(), indexMeta)
+		d.admission.update(watermarkMemory, true, false, "memory usage 0.95 exceeds the high watermark 0.90")
+		resp = d.run()
+		assert.False(t, dryRunCheckOf(resp, dryRunCheckResources).Passed)
+
+		req := newRequest()
+		req.IndexParams = []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: "DISKANN"}}
+		d = newDryRunForTest(t, req, indexMeta)
+		d.freeSpace = func() (uint64, error) {
+			return 100, nil
+		}
+		resp = d.run()
+		assert.True(t, dryRunCheckOf(resp, dryRunCheckParams).Passed)
+		assert.False(t, dryRunCheckOf(resp, dryRunCheckResources).Passed)
+	})
+
+	t.Run("storage", func(t *testing.T) {
+		d := newDryRunForTest(t, newRequest(), indexMeta)
+		d.probe.update(probeStorage, errors.New("failed to write object storage: access denied"))
+		resp := d.run()
+		assert.False(t, resp.Passed)
+		assert.False(t, dryRunCheckOf(resp, dryRunCheckStorage).Passed)
+		assert.Equal(t, 1, strings.Count(failedChecks(resp), ";")+1)
+	})
+}
+
+func TestIndexNode_DryRunCreateIndex(t *testing.T) {
+	ctx := context.Background()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	resp, err := in.DryRunCreateIndex(ctx, &indexpb.CreateIndexRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.Status.ErrorCode)
+
+	in.probe.update(probeEtcdSession, nil)
+	in.probe.update(probeStorage, nil)
+	in.UpdateStateCode(internalpb.StateCode_Healthy)
+	resp, err = in.DryRunCreateIndex(ctx, &indexpb.CreateIndexRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+	assert.False(t, resp.Passed)
+
+	// the dry run is not enqueued
+	status, err := in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1, DryRun: true})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, status.ErrorCode)
+	code, msg := errorcode.Parse(status.Reason)
+	assert.Equal(t, errorcode.InvalidParams, code)
+	assert.True(t, strings.HasPrefix(msg, "dry run failed"))
+	assert.Equal(t, 0, in.sched.IndexBuildQueue.utLen())
+	assert.Nil(t, in.Stop())
+}
@@ -0,0 +1,182 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/util/ratelimit"
+)
+
+// DynamicConfigPrefix is the prefix of the etcd keys of the configurations changed at runtime, the key of a
+// configuration is the prefix followed by its key in milvus.yaml, e.g. indexnode-config/indexNode.scheduler.policy.
+const DynamicConfigPrefix = "indexnode-config"
+
+// dynamicConfigKV is the etcd kv storing the dynamic configurations.
+type dynamicConfigKV interface {
+	LoadWithPrefix(key string) ([]string, []string, error)
+	WatchWithPrefix(key string) clientv3.WatchChan
+}
+
+type dynamicConfigHandler struct {
+	// staticValue is the value in the configuration file, which is restored once the dynamic one is removed
+	staticValue string
+	apply       func(value string) error
+}
+
+// dynamicConfig applies the configurations changed in etcd at runtime, the invalid values are ignored.
+type dynamicConfig struct {
+	kv dynamicConfigKV
+
+	mu       sync.Mutex
+	handlers map[string]*dynamicConfigHandler
+}
+
+func newDynamicConfig(kv dynamicConfigKV) *dynamicConfig {
+	return &dynamicConfig{
+		kv:       kv,
+		handlers: make(map[string]*dynamicConfigHandler),
+	}
+}
+
+// register registers the handler applying the configuration of @key set at runtime.
+func (c *dynamicConfig) register(key string, staticValue string, apply func(value string) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[key] = &dynamicConfigHandler{
+		staticValue: staticValue,
+		apply:       apply,
+	}
+}
+
+// apply applies the value of the configuration, the static value is restored if @removed.
+// It returns whether the value is applied.
+func (c *dynamicConfig) apply(key string, value string, removed bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	handler, ok := c.handlers[key]
+	if !ok {
+		log.Warn("IndexNode ignores the unknown dynamic configuration", zap.String("key", key))
+		return false
+	}
+	if removed {
+		value = handler.staticValue
+	}
+	if err := handler.apply(value); err != nil {
+		log.Warn("IndexNode failed to apply the dynamic configuration", zap.String("key", key),
+			zap.String("value", value), zap.Error(err))
+		return false
+	}
+	log.Info("IndexNode applied the dynamic configuration", zap.String("key", key), zap.String("value", value),
+		zap.Bool("restored", removed))
+	return true
+}
+
+// start applies the dynamic configurations in etcd, and watches their changes until @ctx is done.
+func (c *dynamicConfig) start(ctx context.Context) error {
+	watchCh := c.kv.WatchWithPrefix(DynamicConfigPrefix)
+	if err := c.refresh(); err != nil {
+		return err
+	}
+	go c.watch(ctx, watchCh)
+	return nil
+}
+
+// refresh applies all the dynamic configurations in etcd, UpdatedTime is bumped if any of them is applied.
+func (c *dynamicConfig) refresh() error {
+	keys, values, err := c.kv.LoadWithPrefix(DynamicConfigPrefix)
+	if err != nil {
+		return err
+	}
+	applied := false
+	for idx, key := range keys {
+		if c.apply(path.Base(key), values[idx], false) {
+			applied = true
+		}
+	}
+	if applied {
+		Params.markConfigUpdated()
+	}
+	return nil
+}
+
+func (c *dynamicConfig) watch(ctx context.Context, watchCh clientv3.WatchChan) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case resp, ok := <-watchCh:
+			if !ok {
+				log.Warn("IndexNode stops watching the dynamic configurations, the watch channel is closed")
+				return
+			}
+			if err := resp.Err(); err != nil {
+				log.Warn("IndexNode failed to watch the dynamic configurations", zap.Error(err))
+				continue
+			}
+			applied := false
+			for _, ev := range resp.Events {
+				key := path.Base(string(ev.Kv.Key))
+				switch ev.Type {
+				case mvccpb.PUT:
+					applied = c.apply(key, string(ev.Kv.Value), false) || applied
+				case mvccpb.DELETE:
+					applied = c.apply(key, "", true) || applied
+				}
+			}
+			if applied {
+				Params.markConfigUpdated()
+			}
+		}
+	}
+}
+
+// registerDynamicConfigs registers the configurations of IndexNode which can be changed at runtime.
+func (i *IndexNode) registerDynamicConfigs(c *dynamicConfig) {
+	policy, maxDefer := i.sched.IndexBuildQueue.schedulePolicy()
+	c.register("indexNode.scheduler.policy", policy, func(value string) error {
+		policy, err := normalizeSchedulePolicy(value)
+		if err != nil {
+			return err
+		}
+		_, maxDefer := i.sched.IndexBuildQueue.schedulePolicy()
+		i.sched.IndexBuildQueue.setSchedulePolicy(policy, maxDefer)
+		return nil
+	})
+	c.register("indexNode.scheduler.maxDeferSeconds", strconv.FormatInt(int64(maxDefer/time.Second), 10),
+		func(value string) error {
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || seconds < 0 {
+				return fmt.Errorf("invalid seconds %q, expect a non-negative integer", value)
+			}
+			policy, _ := i.sched.IndexBuildQueue.schedulePolicy()
+			i.sched.IndexBuildQueue.setSchedulePolicy(policy, time.Duration(seconds)*time.Second)
+			return nil
+		})
+	c.register("indexNode.rateLimit.limits", ratelimit.FormatLimits(Params.RateLimits), func(value string) error {
+		limits, err := parseRateLimits(value)
+		if err != nil {
+			return err
+		}
+		return i.rateLimiter.SetLimits(limits)
+	})
+}
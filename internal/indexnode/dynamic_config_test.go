@@ -0,0 +1,159 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/milvus-io/milvus/internal/util/ratelimit"
+)
+
+// mockDynamicConfigKV serves the dynamic configurations in kvs, and sends the changes through watchCh.
+type mockDynamicConfigKV struct {
+	kvs     map[string]string
+	loadErr error
+	watchCh chan clientv3.WatchResponse
+}
+
+func newMockDynamicConfigKV(kvs map[string]string) *mockDynamicConfigKV {
+	return &mockDynamicConfigKV{
+		kvs:     kvs,
+		watchCh: make(chan clientv3.WatchResponse),
+	}
+}
+
+func (kv *mockDynamicConfigKV) LoadWithPrefix(key string) ([]string, []string, error) {
+	if kv.loadErr != nil {
+		return nil, nil, kv.loadErr
+	}
+	keys := make([]string, 0, len(kv.kvs))
+	values := make([]string, 0, len(kv.kvs))
+	for k, v := range kv.kvs {
+		keys = append(keys, path.Join("by-dev/meta", key, k))
+		values = append(values, v)
+	}
+	return keys, values, nil
+}
+
+func (kv *mockDynamicConfigKV) WatchWithPrefix(key string) clientv3.WatchChan {
+	return kv.watchCh
+}
+
+func (kv *mockDynamicConfigKV) send(eventType mvccpb.Event_EventType, key string, value string) {
+	kv.watchCh <- clientv3.WatchResponse{
+		Events: []*clientv3.Event{{
+			Type: eventType,
+			Kv: &mvccpb.KeyValue{
+				Key:   []byte(path.Join("by-dev/meta", DynamicConfigPrefix, key)),
+				Value: []byte(value),
+			},
+		}},
+	}
+}
+
+func TestIndexNode_dynamicConfig(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	queue := in.sched.IndexBuildQueue
+
+	kv := newMockDynamicConfigKV(map[string]string{
+		"indexNode.scheduler.policy": "smallest-first",
+		"indexNode.unknown":          "1",
+	})
+	c := newDynamicConfig(kv)
+	in.registerDynamicConfigs(c)
+	assert.Nil(t, c.start(ctx))
+	policy, _ := queue.schedulePolicy()
+	assert.Equal(t, schedulePolicySmallestFirst, policy)
+
+	waitPolicy := func(expectedPolicy string, expectedMaxDefer time.Duration) {
+		assert.Eventually(t, func() bool {
+			policy, maxDefer := queue.schedulePolicy()
+			return policy == expectedPolicy && maxDefer == expectedMaxDefer
+		}, time.Second, time.Millisecond)
+	}
+	kv.send(mvccpb.PUT, "indexNode.scheduler.maxDeferSeconds", "60")
+	waitPolicy(schedulePolicySmallestFirst, time.Minute)
+	kv.send(mvccpb.PUT, "indexNode.scheduler.policy", "FIFO")
+	waitPolicy(schedulePolicyFIFO, time.Minute)
+
+	// the invalid values are ignored
+	kv.send(mvccpb.PUT, "indexNode.scheduler.policy", "random")
+	kv.send(mvccpb.PUT, "indexNode.scheduler.maxDeferSeconds", "-1")
+	waitPolicy(schedulePolicyFIFO, time.Minute)
+
+	// the static configurations are restored once removed
+	kv.send(mvccpb.DELETE, "indexNode.scheduler.policy", "")
+	kv.send(mvccpb.DELETE, "indexNode.scheduler.maxDeferSeconds", "")
+	waitPolicy(schedulePolicyFair, Params.ScheduleMaxDefer)
+
+	select {
+	case kv.watchCh <- clientv3.WatchResponse{Canceled: true, CompactRevision: 1}:
+	case <-time.After(time.Second):
+		t.Fatal("the watch is not consumed")
+	}
+
+	t.Run("refresh", func(t *testing.T) {
+		createdTime, updatedTime, refreshes := Params.lifetime()
+		time.Sleep(10 * time.Millisecond)
+		kv.kvs = map[string]string{"indexNode.scheduler.policy": "fifo"}
+		assert.Nil(t, c.refresh())
+		policy, _ := queue.schedulePolicy()
+		assert.Equal(t, schedulePolicyFIFO, policy)
+		newCreatedTime, newUpdatedTime, newRefreshes := Params.lifetime()
+		assert.Equal(t, createdTime, newCreatedTime)
+		assert.True(t, newUpdatedTime.After(updatedTime))
+		assert.Equal(t, refreshes+1, newRefreshes)
+
+		// nothing is applied
+		kv.kvs = map[string]string{"indexNode.unknown": "1"}
+		assert.Nil(t, c.refresh())
+		_, _, newRefreshes = Params.lifetime()
+		assert.Equal(t, refreshes+1, newRefreshes)
+	})
+
+	t.Run("rate limits", func(t *testing.T) {
+		limits := map[string]ratelimit.Limit{"CreateIndex": {Rate: 100, Burst: 200}}
+		assert.Nil(t, in.rateLimiter.SetLimits(limits))
+		kv := newMockDynamicConfigKV(nil)
+		c := newDynamicConfig(kv)
+		in.registerDynamicConfigs(c)
+
+		kv.kvs = map[string]string{"indexNode.rateLimit.limits": "CreateIndex:1:2,GetMetrics:5"}
+		assert.Nil(t, c.refresh())
+		assert.Equal(t, map[string]ratelimit.Limit{
+			"CreateIndex": {Rate: 1, Burst: 2},
+			"GetMetrics":  {Rate: 5, Burst: 5},
+		}, in.rateLimiter.Limits())
+
+		// the limits of the methods never limited are rejected
+		kv.kvs = map[string]string{"indexNode.rateLimit.limits": "Activate:1"}
+		assert.Nil(t, c.refresh())
+		assert.Equal(t, ratelimit.Limit{Rate: 1, Burst: 2}, in.rateLimiter.Limits()["CreateIndex"])
+	})
+
+	t.Run("load failed", func(t *testing.T) {
+		kv := newMockDynamicConfigKV(nil)
+		kv.loadErr = errors.New("etcdserver: request timed out")
+		assert.NotNil(t, newDynamicConfig(kv).start(ctx))
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/util/indexparamcheck"
+)
+
+// mixedCaseEngineKeys restores the names of the engine parameters which are not lower case, since the keys of
+// the configuration are.
+var mixedCaseEngineKeys = map[string]string{
+	strings.ToLower(indexparamcheck.EFConstruction): indexparamcheck.EFConstruction,
+	strings.ToLower(indexparamcheck.PQM):            indexparamcheck.PQM,
+}
+
+// engineConfigKey returns the name of the engine parameter of the configuration @key for @indexType.
+func engineConfigKey(indexType string, key string) string {
+	if name, ok := mixedCaseEngineKeys[key]; ok {
+		return name
+	}
+	// m is the number of the sub quantizers of IVF_PQ, but M is the max degree of the graph of HNSW
+	if key == indexparamcheck.IVFM && strings.Contains(indexType, "HNSW") {
+		return indexparamcheck.HNSWM
+	}
+	return key
+}
+
+// engineConfigOf returns the build configuration of the engine for @indexType, which is the global configuration
+// overridden by the one of the index type, and then by @params of the request.
+func engineConfigOf(indexType string, params map[string]string) map[string]string {
+	indexType = strings.ToUpper(indexType)
+	config := make(map[string]string, len(Params.EngineConfig)+len(params))
+	for _, layer := range []map[string]string{Params.EngineConfig, Params.EngineConfigOverrides[indexType]} {
+		for key, value := range layer {
+			config[engineConfigKey(indexType, key)] = value
+		}
+	}
+	for key, value := range params {
+		// the key of the request may differ in case from the configured one, e.g. nlist and NLIST
+		for configured := range config {
+			if configured != key && strings.EqualFold(configured, key) {
+				delete(config, configured)
+			}
+		}
+		config[key] = value
+	}
+	return config
+}
+
+// recordEngineConfig records @config as the effective build configuration of the engine for the build.
+func (it *IndexBuildTask) recordEngineConfig(config map[string]string) {
+	recorded := make(map[string]string, len(config))
+	for key, value := range config {
+		recorded[key] = value
+	}
+	indexType := it.indexType()
+	it.stats.recordEngineConfig(indexType, recorded)
+	it.logger(engineLog).Debug("IndexNode engine config of the build", zap.Int64("indexBuildID", it.req.IndexBuildID),
+		zap.String("indexType", indexType), zap.Any("engineConfig", recorded))
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+)
+
+func setEngineConfig(t *testing.T, global map[string]string, overrides map[string]map[string]string) {
+	oldGlobal, oldOverrides := Params.EngineConfig, Params.EngineConfigOverrides
+	t.Cleanup(func() {
+		Params.EngineConfig, Params.EngineConfigOverrides = oldGlobal, oldOverrides
+	})
+	Params.EngineConfig, Params.EngineConfigOverrides = global, overrides
+}
+
+func TestEngineConfigOf(t *testing.T) {
+	setEngineConfig(t, map[string]string{"nlist": "128", "nprobe": "8", "build_thread_num": "4"},
+		map[string]map[string]string{
+			"IVF_FLAT": {"nlist": "256", "nprobe": "16"},
+			"HNSW":     {"m": "16", "efconstruction": "100"},
+		})
+
+	// request > per-type config > global config
+	config := engineConfigOf("ivf_flat", map[string]string{indexTypeKey: "IVF_FLAT", "nlist": "1024"})
+	assert.Equal(t, map[string]string{indexTypeKey: "IVF_FLAT", "nlist": "1024", "nprobe": "16",
+		"build_thread_num": "4"}, config)
+	config = engineConfigOf("IVF_SQ8", map[string]string{indexTypeKey: "IVF_SQ8"})
+	assert.Equal(t, map[string]string{indexTypeKey: "IVF_SQ8", "nlist": "128", "nprobe": "8",
+		"build_thread_num": "4"}, config)
+
+	// the names of the mixed case parameters are restored, and the request wins whatever the case is
+	config = engineConfigOf("HNSW", map[string]string{indexTypeKey: "HNSW", "EFCONSTRUCTION": "200"})
+	assert.Equal(t, map[string]string{indexTypeKey: "HNSW", "M": "16", "EFCONSTRUCTION": "200", "nlist": "128",
+		"nprobe": "8", "build_thread_num": "4"}, config)
+
+	// the request params are never modified
+	params := map[string]string{indexTypeKey: "IVF_FLAT"}
+	engineConfigOf("IVF_FLAT", params)
+	assert.Equal(t, map[string]string{indexTypeKey: "IVF_FLAT"}, params)
+}
+
+func TestEngineConfigKey(t *testing.T) {
+	assert.Equal(t, "efConstruction", engineConfigKey("HNSW", "efconstruction"))
+	assert.Equal(t, "PQM", engineConfigKey("ANNOY", "pqm"))
+	assert.Equal(t, "M", engineConfigKey("RHNSW_PQ", "m"))
+	assert.Equal(t, "m", engineConfigKey("IVF_PQ", "m"))
+	// the unknown keys are passed as is
+	assert.Equal(t, "unknown_key", engineConfigKey("IVF_PQ", "unknown_key"))
+}
+
+func TestIndexBuildTask_recordEngineConfig(t *testing.T) {
+	setEngineConfig(t, map[string]string{"nprobe": "8"}, map[string]map[string]string{"IVF_FLAT": {"nlist": "256"}})
+	task := &IndexBuildTask{
+		req: &indexpb.CreateIndexRequest{
+			IndexBuildID: 1,
+			IndexParams:  []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: "IVF_FLAT"}},
+		},
+		stats: newTaskStatistics(),
+	}
+	assert.Nil(t, task.stats.taskInfos(0, 0, 0).IndexTypeEngineConfig)
+
+	config := engineConfigOf("IVF_FLAT", map[string]string{indexTypeKey: "IVF_FLAT"})
+	task.recordEngineConfig(config)
+	// the keys only known by the build, e.g. the local directory, are added after it's recorded
+	config[indexFilesDirKey] = "/tmp/index"
+	assert.Equal(t, map[string]map[string]string{
+		"IVF_FLAT": {indexTypeKey: "IVF_FLAT", "nlist": "256", "nprobe": "8"},
+	}, task.stats.taskInfos(0, 0, 0).IndexTypeEngineConfig)
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/metrics"
+	"github.com/milvus-io/milvus/internal/util/metricsinfo"
+)
+
+// parseEngineStatistics parses the statistics reported by the engine in json. The statistics not exposed by the
+// engine, e.g. of an older version, are left absent, and the unknown ones of a newer version are ignored.
+func parseEngineStatistics(stats string) (metricsinfo.IndexEngineStatistics, error) {
+	var s metricsinfo.IndexEngineStatistics
+	if err := json.Unmarshal([]byte(stats), &s); err != nil {
+		return metricsinfo.IndexEngineStatistics{}, err
+	}
+	if s.Threads == nil || *s.Threads <= 0 || s.CPUMs == nil {
+		return s, nil
+	}
+	wallMs := 0.0
+	for _, phaseMs := range []*float64{s.TrainMs, s.AddMs, s.BuildMs} {
+		if phaseMs != nil {
+			wallMs += *phaseMs
+		}
+	}
+	if wallMs > 0 {
+		// the cpu time is of the process, which exceeds the threads of the build if other builds run along with it
+		utilization := *s.CPUMs / (wallMs * float64(*s.Threads))
+		if utilization > 1 {
+			utilization = 1
+		}
+		s.ThreadUtilization = &utilization
+	}
+	return s, nil
+}
+
+// observeEngineStatistics records the statistics of a build of @indexType into the prometheus metrics.
+func observeEngineStatistics(indexType string, s metricsinfo.IndexEngineStatistics) {
+	if s.ThreadUtilization != nil {
+		metrics.IndexNodeEngineThreadUtilization.WithLabelValues(indexType).Observe(*s.ThreadUtilization)
+	}
+	for phase, phaseMs := range map[string]*float64{
+		metrics.IndexNodeEnginePhaseTrain: s.TrainMs,
+		metrics.IndexNodeEnginePhaseAdd:   s.AddMs,
+		metrics.IndexNodeEnginePhaseBuild: s.BuildMs,
+	} {
+		if phaseMs != nil {
+			duration := time.Duration(*phaseMs * float64(time.Millisecond))
+			metrics.IndexNodeEnginePhaseDuration.WithLabelValues(indexType, phase).Observe(duration.Seconds())
+		}
+	}
+}
+
+// recordEngineStatistics collects the statistics of the build from the engine if indexNode.engineStats.enabled,
+// the index exposing none is skipped, and a failure of the collection never fails the build.
+func (it *IndexBuildTask) recordEngineStatistics() {
+	if !Params.EngineStatsEnabled {
+		return
+	}
+	index, ok := it.index.(StatisticsIndex)
+	if !ok {
+		return
+	}
+	raw, err := index.Statistics()
+	if err != nil {
+		engineLog.Warn("IndexNode failed to collect the engine statistics of the build",
+			zap.Int64("indexBuildID", it.req.IndexBuildID), zap.Error(err))
+		return
+	}
+	stats, err := parseEngineStatistics(raw)
+	if err != nil {
+		engineLog.Warn("IndexNode failed to parse the engine statistics of the build",
+			zap.Int64("indexBuildID", it.req.IndexBuildID), zap.String("statistics", raw), zap.Error(err))
+		return
+	}
+	if it.vectorDim > 0 {
+		// the dim is of the decoded vectors, which is in bits for the binary vectors whatever the engine reports
+		dim := it.vectorDim
+		stats.Dim = &dim
+	}
+	if it.buildSeed != nil {
+		seed, honored := it.buildSeed.Seed, it.buildSeed.Honored
+		stats.Seed, stats.SeedHonored = &seed, &honored
+	}
+	stats.SimdType, stats.EffectiveSimdType = it.configuredSimdType, it.simdType
+	indexType := it.indexType()
+	it.stats.recordEngineStatistics(indexType, stats)
+	observeEngineStatistics(indexType, stats)
+	engineLog.Debug("IndexNode engine statistics of the build", zap.Int64("indexBuildID", it.req.IndexBuildID),
+		zap.String("indexType", indexType), zap.String("statistics", raw))
+}
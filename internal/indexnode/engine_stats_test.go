@@ -0,0 +1,150 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/metrics"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+)
+
+// mockStatisticsIndex reports the statistics of the engine.
+type mockStatisticsIndex struct {
+	mockIncrementalIndex
+	stats    string
+	statsErr error
+}
+
+func (index *mockStatisticsIndex) Statistics() (string, error) {
+	return index.stats, index.statsErr
+}
+
+func TestParseEngineStatistics(t *testing.T) {
+	stats, err := parseEngineStatistics(`{"index_type":"IVF_FLAT","threads":4,"train_ms":100,"add_ms":300,` +
+		`"cpu_ms":800,"rows":1000,"dim":8,"index_size":40960}`)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), *stats.Threads)
+	assert.Equal(t, float64(100), *stats.TrainMs)
+	assert.Equal(t, float64(300), *stats.AddMs)
+	assert.Nil(t, stats.BuildMs)
+	assert.Equal(t, int64(1000), *stats.Rows)
+	assert.Equal(t, int64(8), *stats.Dim)
+	assert.Equal(t, int64(40960), *stats.IndexSize)
+	assert.Equal(t, 0.5, *stats.ThreadUtilization)
+
+	// an older engine exposes fewer statistics, the missing ones are absent, and the unknown ones are ignored
+	stats, err = parseEngineStatistics(`{"build_ms":0,"rows":10,"new_counter":1}`)
+	assert.Nil(t, err)
+	assert.Nil(t, stats.Threads)
+	assert.Nil(t, stats.CPUMs)
+	assert.Nil(t, stats.ThreadUtilization)
+	assert.Equal(t, float64(0), *stats.BuildMs)
+	data, err := json.Marshal(stats)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"build_ms":0,"rows":10}`, string(data))
+
+	// the cpu time of the process covers the other builds
+	stats, err = parseEngineStatistics(`{"threads":2,"build_ms":100,"cpu_ms":400}`)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(1), *stats.ThreadUtilization)
+	stats, err = parseEngineStatistics(`{"threads":2,"build_ms":0,"cpu_ms":400}`)
+	assert.Nil(t, err)
+	assert.Nil(t, stats.ThreadUtilization)
+
+	_, err = parseEngineStatistics("not json")
+	assert.NotNil(t, err)
+	_, err = parseEngineStatistics(`{"threads":"many"}`)
+	assert.NotNil(t, err)
+}
+
+func TestIndexBuildTask_recordEngineStatistics(t *testing.T) {
+	old := Params.EngineStatsEnabled
+	defer func() {
+		Params.EngineStatsEnabled = old
+	}()
+
+	newTask := func(index Index) *IndexBuildTask {
+		return &IndexBuildTask{
+			index: index,
+			req: &indexpb.CreateIndexRequest{
+				IndexBuildID: 1,
+				IndexParams:  []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: "ENGINE_STATS_TEST"}},
+			},
+			stats: newTaskStatistics(),
+		}
+	}
+	utilizationCount := func() uint64 {
+		m := &dto.Metric{}
+		assert.Nil(t, metrics.IndexNodeEngineThreadUtilization.WithLabelValues("ENGINE_STATS_TEST").(prometheus.Histogram).Write(m))
+		return m.GetHistogram().GetSampleCount()
+	}
+	trainCount := func() uint64 {
+		m := &dto.Metric{}
+		assert.Nil(t, metrics.IndexNodeEnginePhaseDuration.WithLabelValues("ENGINE_STATS_TEST",
+			metrics.IndexNodeEnginePhaseTrain).(prometheus.Histogram).Write(m))
+		return m.GetHistogram().GetSampleCount()
+	}
+	stats := `{"threads":4,"train_ms":100,"add_ms":300,"cpu_ms":800}`
+
+	// disabled
+	Params.EngineStatsEnabled = false
+	task := newTask(&mockStatisticsIndex{stats: stats})
+	task.recordEngineStatistics()
+	assert.Nil(t, task.stats.taskInfos(0, 0, 0).IndexTypeEngineStatistics)
+
+	Params.EngineStatsEnabled = true
+	utilizations, trains := utilizationCount(), trainCount()
+	task.recordEngineStatistics()
+	infos := task.stats.taskInfos(0, 0, 0).IndexTypeEngineStatistics
+	assert.Equal(t, 1, len(infos))
+	assert.Equal(t, 0.5, *infos["ENGINE_STATS_TEST"].ThreadUtilization)
+	assert.Equal(t, utilizations+1, utilizationCount())
+	assert.Equal(t, trains+1, trainCount())
+	assert.Nil(t, infos["ENGINE_STATS_TEST"].Seed)
+
+	// the index exposing no statistics, or failing to report them, is skipped
+	for _, index := range []Index{
+		&mockIncrementalIndex{},
+		&mockStatisticsIndex{statsErr: errors.New("not supported")},
+		&mockStatisticsIndex{stats: "{"},
+	} {
+		task = newTask(index)
+		task.recordEngineStatistics()
+		assert.Nil(t, task.stats.taskInfos(0, 0, 0).IndexTypeEngineStatistics)
+	}
+	assert.Equal(t, utilizations+1, utilizationCount())
+
+	// the seed of the build is recorded along with the statistics of the engine
+	task = newTask(&mockStatisticsIndex{stats: stats})
+	task.buildSeed = &indexpb.IndexBuildSeed{Seed: 7}
+	task.recordEngineStatistics()
+	infos = task.stats.taskInfos(0, 0, 0).IndexTypeEngineStatistics
+	assert.Equal(t, int64(7), *infos["ENGINE_STATS_TEST"].Seed)
+	assert.False(t, *infos["ENGINE_STATS_TEST"].SeedHonored)
+	assert.Equal(t, "", infos["ENGINE_STATS_TEST"].EffectiveSimdType)
+
+	// so are the configured and the effective simd types of the build
+	task = newTask(&mockStatisticsIndex{stats: stats})
+	task.configuredSimdType, task.simdType = "avx512", "avx2"
+	task.recordEngineStatistics()
+	infos = task.stats.taskInfos(0, 0, 0).IndexTypeEngineStatistics
+	assert.Equal(t, "avx512", infos["ENGINE_STATS_TEST"].SimdType)
+	assert.Equal(t, "avx2", infos["ENGINE_STATS_TEST"].EffectiveSimdType)
+}
@@ -12,14 +12,127 @@
 package indexnode
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/milvus-io/milvus/internal/metrics"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/metricsinfo"
+	"github.com/milvus-io/milvus/internal/util/retry"
 )
 
 func msgIndexNodeIsUnhealthy(nodeID UniqueID) string {
-	return fmt.Sprintf("index node %d is not ready", nodeID)
+	return errorcode.Format(errorcode.NotReady, fmt.Sprintf("index node %d is not ready", nodeID))
+}
+
+func msgIndexNodeIsStarting(nodeID UniqueID, phase startupPhase) string {
+	return errorcode.Format(errorcode.NotReady, fmt.Sprintf("index node %d is not ready, starting up in phase: %s", nodeID, phase))
 }
 
 func errIndexNodeIsUnhealthy(nodeID UniqueID) error {
-	return errors.New(msgIndexNodeIsUnhealthy(nodeID))
+	return errorcode.Errorf(errorcode.NotReady, "index node %d is not ready", nodeID)
+}
+
+func msgIndexNodeIsBusy(nodeID UniqueID, queueDepth int, estimatedWait time.Duration) string {
+	return errorcode.Format(errorcode.Busy,
+		fmt.Sprintf("index node %d is busy, retry later, queue depth: %d, estimated wait: %s", nodeID, queueDepth, estimatedWait))
+}
+
+func msgIndexNodeIsPaused(nodeID UniqueID, reason string) string {
+	return errorcode.Format(errorcode.Busy, fmt.Sprintf("index node %d is busy, retry later, new tasks are paused: %s", nodeID, reason))
+}
+
+func msgIndexNodeIsStandby(nodeID UniqueID) string {
+	return errorcode.Format(errorcode.NotReady, fmt.Sprintf("index node %d is standby, activate it to accept tasks", nodeID))
+}
+
+func msgCapabilityMismatch(nodeID UniqueID, missing []string) string {
+	return errorcode.Format(errorcode.CapabilityMismatch,
+		fmt.Sprintf("index node %d capability mismatch, missing capabilities: %s", nodeID, strings.Join(missing, ",")))
+}
+
+func msgUnsupportedMetricType(metricType string) string {
+	return errorcode.Format(errorcode.InvalidParams, fmt.Sprintf("%s, metric type: %s", metricsinfo.MsgUnimplementedMetric, metricType))
+}
+
+// the error codes of the object storage meaning the credentials of IndexNode are rejected
+var storageAuthErrorCodes = map[string]bool{
+	"AccessDenied":          true,
+	"InvalidAccessKeyId":    true,
+	"SignatureDoesNotMatch": true,
+	"InvalidToken":          true,
+	"ExpiredToken":          true,
+}
+
+// storageError attaches the code to the error of an object storage operation, the missing objects are the invalid
+// data referred by the request, and the other failures are considered transient.
+func storageError(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	switch resp := minio.ToErrorResponse(err); {
+	case storageAuthErrorCodes[resp.Code]:
+		return errorcode.Wrap(errorcode.StorageAuth, err)
+	case resp.Code == "NoSuchKey":
+		return errorcode.Wrap(errorcode.InvalidParams, err)
+	}
+	return errorcode.Wrap(errorcode.StorageTransient, err)
+}
+
+// etcdError attaches the code to the error of an etcd operation if etcd fails to serve it, the missing keys and
+// the failed comparisons are left to the callers.
+func etcdError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch etcdErrorCategory(err) {
+	case metrics.IndexNodeEtcdErrorTimeout, metrics.IndexNodeEtcdErrorOther:
+		return errorcode.Wrap(errorcode.EtcdUnavailable, err)
+	}
+	return err
+}
+
+func isOutOfMemory(err error) bool {
+	if errors.Is(err, syscall.ENOMEM) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "bad_alloc") || strings.Contains(msg, "out of memory") ||
+		strings.Contains(msg, "cannot allocate memory")
+}
+
+// classifyError returns the code of the failure of @err, the code attached to @err is preferred, and the errors
+// of all the attempts of a retry are classified by the last attempt. The errors attached no code are classified by
+// the context errors, the memory errors of the engine and the local disk errors.
+func classifyError(err error) errorcode.Code {
+	if code := errorcode.CodeOf(err); code != "" {
+		return code
+	}
+	var attempts retry.ErrorList
+	if errors.As(err, &attempts) && len(attempts) > 0 {
+		return classifyError(attempts[len(attempts)-1])
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return errorcode.Cancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		return errorcode.Timeout
+	case isOutOfMemory(err):
+		return errorcode.OOM
+	case isRetryableOnOtherNode(err):
+		// the local environment of IndexNode can not take the task, another node may
+		return errorcode.Busy
+	}
+	return errorcode.Internal
+}
+
+// failureReason returns the reason of the failure of @err with the code attached, which is reported to IndexCoord.
+func failureReason(err error) string {
+	return errorcode.Format(classifyError(err), err.Error())
 }
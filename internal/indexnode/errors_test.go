@@ -12,12 +12,31 @@
 package indexnode
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
-	"github.com/milvus-io/milvus/internal/util/typeutil"
+	"github.com/golang/protobuf/proto"
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	etcdkv "github.com/milvus-io/milvus/internal/kv/etcd"
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
 	"github.com/milvus-io/milvus/internal/log"
-	"go.uber.org/zap"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+	"github.com/milvus-io/milvus/internal/proto/milvuspb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/retry"
+	"github.com/milvus-io/milvus/internal/util/typeutil"
 )
 
 func TestMsgIndexNodeIsUnhealthy(t *testing.T) {
@@ -33,3 +52,198 @@ func TestErrIndexNodeIsUnhealthy(t *testing.T) {
 		log.Info("TestErrIndexNodeIsUnhealthy", zap.Error(errIndexNodeIsUnhealthy(nodeID)))
 	}
 }
+
+func TestMsgUnsupportedMetricType(t *testing.T) {
+	log.Info("TestMsgUnsupportedMetricType", zap.String("msg", msgUnsupportedMetricType("unknown_metric")))
+}
+
+func TestMsgCapabilityMismatch(t *testing.T) {
+	log.Info("TestMsgCapabilityMismatch", zap.String("msg", msgCapabilityMismatch(1, []string{CapabilityGPU, CapabilityHighMem})))
+}
+
+func TestMsgErrorCodes(t *testing.T) {
+	for reason, expected := range map[string]errorcode.Code{
+		msgIndexNodeIsUnhealthy(1):                        errorcode.NotReady,
+		msgIndexNodeIsStarting(1, startupPhaseEtcd):       errorcode.NotReady,
+		msgIndexNodeIsStandby(1):                          errorcode.NotReady,
+		msgIndexNodeIsBusy(1, 10, time.Minute):            errorcode.Busy,
+		msgIndexNodeIsPaused(1, "memory"):                 errorcode.Busy,
+		msgCapabilityMismatch(1, []string{CapabilityGPU}): errorcode.CapabilityMismatch,
+		msgUnsupportedMetricType("unknown_metric"):        errorcode.InvalidParams,
+		failureReason(errIndexNodeIsUnhealthy(1)):         errorcode.NotReady,
+	} {
+		code, msg := errorcode.Parse(reason)
+		assert.Equal(t, expected, code, reason)
+		assert.NotEmpty(t, msg)
+	}
+}
+
+// faultyLoadKV fails to load the objects with loadErr.
+type faultyLoadKV struct {
+	*memkv.MemoryKV
+	loadErr error
+}
+
+func (kv *faultyLoadKV) Load(key string) (string, error) {
+	if kv.loadErr != nil {
+		return "", kv.loadErr
+	}
+	return kv.MemoryKV.Load(key)
+}
+
+func TestClassifyError(t *testing.T) {
+	for name, c := range map[string]struct {
+		err  error
+		code errorcode.Code
+	}{
+		"storage auth":      {storageError(minio.ErrorResponse{Code: "AccessDenied", Message: "Access Denied."}), errorcode.StorageAuth},
+		"storage key":       {storageError(minio.ErrorResponse{Code: "InvalidAccessKeyId"}), errorcode.StorageAuth},
+		"storage missing":   {storageError(minio.ErrorResponse{Code: "NoSuchKey"}), errorcode.InvalidParams},
+		"storage transient": {storageError(errors.New("connection reset by peer")), errorcode.StorageTransient},
+		"storage cancelled": {storageError(context.Canceled), errorcode.Cancelled},
+		"etcd unavailable":  {etcdError(status.Error(codes.Unavailable, "connection refused")), errorcode.EtcdUnavailable},
+		"etcd timeout":      {etcdError(context.DeadlineExceeded), errorcode.EtcdUnavailable},
+		"etcd conflict":     {etcdError(errors.New("function CompareAndSwap error for compare is false for key: a")), errorcode.Internal},
+		"oom":               {errors.New("BuildFloatVecIndexWithoutIds failed, C runtime error detected, err msg = std::bad_alloc"), errorcode.OOM},
+		"enomem":            {fmt.Errorf("mmap: %w", syscall.ENOMEM), errorcode.OOM},
+		"timeout":           {context.DeadlineExceeded, errorcode.Timeout},
+		"cancelled":         {fmt.Errorf("build: %w", context.Canceled), errorcode.Cancelled},
+		"disk full":         {retryOnOtherNode(errors.New("no space left on device")), errorcode.Busy},
+		"retried":           {retry.ErrorList{errors.New("unknown"), storageError(errors.New("i/o timeout"))}, errorcode.StorageTransient},
+		"unknown":           {errors.New("unknown"), errorcode.Internal},
+	} {
+		assert.Equal(t, c.code, classifyError(c.err), name)
+		code, msg := errorcode.Parse(failureReason(c.err))
+		assert.Equal(t, c.code, code, name)
+		assert.Equal(t, c.err.Error(), msg, name)
+	}
+}
+
+func TestIndexBuildTask_failureCodes(t *testing.T) {
+	oldBufferSize, oldChunkRows := Params.BuildPipelineBufferSize, Params.BuildChunkRows
+	defer func() {
+		Params.BuildPipelineBufferSize, Params.BuildChunkRows = oldBufferSize, oldChunkRows
+	}()
+	Params.BuildPipelineBufferSize, Params.BuildChunkRows = defaultBuildPipelineBuffer, defaultBuildChunkRows
+	ctx := context.Background()
+	newTask := func(loadErr error) *IndexBuildTask {
+		storageKV := &faultyLoadKV{MemoryKV: memkv.NewMemoryKV(), loadErr: loadErr}
+		_ = storageKV.Save("insert_log/1/2/3/101/0", "not a binlog")
+		return &IndexBuildTask{
+			kv:       storageKV,
+			req:      &indexpb.CreateIndexRequest{IndexBuildID: 1, Version: 1, DataPaths: []string{"insert_log/1/2/3/101/0"}},
+			progress: newTaskProgress(1, 1),
+		}
+	}
+
+	t.Run("download", func(t *testing.T) {
+		for loadErr, code := range map[error]errorcode.Code{
+			minio.ErrorResponse{Code: "SignatureDoesNotMatch"}: errorcode.StorageAuth,
+			errors.New("connection reset by peer"):             errorcode.StorageTransient,
+		} {
+			_, err := newTask(loadErr).buildPipelined(ctx, &mockIncrementalIndex{})
+			assert.NotNil(t, err)
+			assert.Equal(t, code, classifyError(err))
+		}
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		_, err := newTask(nil).buildPipelined(ctx, &mockIncrementalIndex{})
+		assert.NotNil(t, err)
+		assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+	})
+
+	t.Run("build", func(t *testing.T) {
+		segment := newPipelineTestSegment(2, 10)
+		index := &mockIncrementalIndex{addErr: errors.New("AddFloatVecIndexWithoutIds failed, err msg = std::bad_alloc")}
+		err := segment.pipeline(index, 10).run(ctx)
+		assert.NotNil(t, err)
+		assert.Equal(t, errorcode.OOM, classifyError(err))
+	})
+
+	t.Run("params", func(t *testing.T) {
+		_, _, err := parseBuildParams(&indexpb.CreateIndexRequest{IndexParams: []*commonpb.KeyValuePair{
+			{Key: indexTypeKey, Value: "IVF_FLAT"}, {Key: indexTypeKey, Value: "HNSW"}}})
+		assert.NotNil(t, err)
+		assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		task := &BaseTask{ctx: ctx, done: make(chan error)}
+		assert.Equal(t, errorcode.Timeout, classifyError(task.WaitToFinish()))
+	})
+}
+
+func TestIndexBuildTask_failReason(t *testing.T) {
+	e, endpoints := startEmbedEtcd(t)
+	defer e.Close()
+	client, err := etcdkv.NewEtcdKV(endpoints, "/fail-reason")
+	assert.Nil(t, err)
+	defer client.Close()
+
+	meta := &indexpb.IndexMeta{IndexBuildID: 1, Version: 1, State: commonpb.IndexState_InProgress}
+	value, err := proto.Marshal(meta)
+	assert.Nil(t, err)
+	assert.Nil(t, client.Save("indexes/1", string(value)))
+
+	it := &IndexBuildTask{
+		etcdKV: newMetricsEtcdKV(client),
+		req:    &indexpb.CreateIndexRequest{IndexBuildID: 1, Version: 1, MetaPath: "indexes/1"},
+	}
+	it.SetError(fmt.Errorf("failed to load the binlog: %w", storageError(minio.ErrorResponse{Code: "AccessDenied"})))
+	assert.Nil(t, it.checkIndexMeta(context.Background(), false))
+
+	value2, err := client.Load("indexes/1")
+	assert.Nil(t, err)
+	assert.Nil(t, proto.Unmarshal([]byte(value2), meta))
+	assert.Equal(t, commonpb.IndexState_Failed, meta.State)
+	code, msg := errorcode.Parse(meta.FailReason)
+	assert.Equal(t, errorcode.StorageAuth, code)
+	assert.True(t, strings.HasPrefix(msg, "failed to load the binlog"))
+}
+
+func TestIndexNode_errorCodes(t *testing.T) {
+	ctx := context.Background()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	defer in.Stop()
+	assertCode := func(status *commonpb.Status, code errorcode.Code) {
+		assert.Equal(t, commonpb.ErrorCode_UnexpectedError, status.ErrorCode)
+		parsed, _ := errorcode.Parse(status.Reason)
+		assert.Equal(t, code, parsed, status.Reason)
+	}
+
+	status, err := in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1})
+	assert.Nil(t, err)
+	assertCode(status, errorcode.NotReady)
+	dryRunResp, err := in.DryRunCreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1})
+	assert.Nil(t, err)
+	assertCode(dryRunResp.Status, errorcode.NotReady)
+
+	in.probe.update(probeEtcdSession, nil)
+	in.probe.update(probeStorage, nil)
+	in.setStartupPhase(startupPhaseServing)
+	in.UpdateStateCode(internalpb.StateCode_Healthy)
+	status, err = in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1, RequiredCapabilities: []string{CapabilityGPU}})
+	assert.Nil(t, err)
+	assertCode(status, errorcode.CapabilityMismatch)
+	status, err = in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 2, BaseIndexBuildID: 1,
+		IndexParams: []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: "IVF_FLAT"}, {Key: metricTypeKey, Value: "L2"}}})
+	assert.Nil(t, err)
+	assertCode(status, errorcode.Unsupported)
+	status, err = in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1, DryRun: true})
+	assert.Nil(t, err)
+	assertCode(status, errorcode.InvalidParams)
+	metricsResp, err := in.GetMetrics(ctx, &milvuspb.GetMetricsRequest{Request: "invalid"})
+	assert.Nil(t, err)
+	assertCode(metricsResp.Status, errorcode.InvalidParams)
+
+	in.modeMu.Lock()
+	in.standby = true
+	in.modeMu.Unlock()
+	status, err = in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1})
+	assert.Nil(t, err)
+	assertCode(status, errorcode.NotReady)
+}
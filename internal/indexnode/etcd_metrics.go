@@ -0,0 +1,150 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	etcdkv "github.com/milvus-io/milvus/internal/kv/etcd"
+	"github.com/milvus-io/milvus/internal/metrics"
+)
+
+// metricsEtcdKV is the etcd kv of IndexNode recording the latencies, the bytes of the values and the errors of the
+// operations. Only the operations are the labels, the keys never are, so that the cardinality stays bounded.
+type metricsEtcdKV struct {
+	*etcdkv.EtcdKV
+}
+
+func newMetricsEtcdKV(kv *etcdkv.EtcdKV) *metricsEtcdKV {
+	return &metricsEtcdKV{EtcdKV: kv}
+}
+
+// etcdErrorCategory returns the category of the error of an etcd operation.
+func etcdErrorCategory(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), err == rpctypes.ErrTimeout,
+		err == rpctypes.ErrTimeoutDueToLeaderFail, err == rpctypes.ErrTimeoutDueToConnectionLost,
+		status.Code(err) == codes.DeadlineExceeded:
+		return metrics.IndexNodeEtcdErrorTimeout
+	case err == rpctypes.ErrCompacted:
+		return metrics.IndexNodeEtcdErrorCompacted
+	case err == rpctypes.ErrRequestTooLarge, status.Code(err) == codes.ResourceExhausted:
+		return metrics.IndexNodeEtcdErrorTooLarge
+	case strings.Contains(err.Error(), "there is no value on key"):
+		return metrics.IndexNodeEtcdErrorNotFound
+	case strings.Contains(err.Error(), "compare is false"):
+		return metrics.IndexNodeEtcdErrorConflict
+	default:
+		return metrics.IndexNodeEtcdErrorOther
+	}
+}
+
+// observeEtcd records an etcd operation started at @start, which carried the values of @size bytes.
+func observeEtcd(operation string, start time.Time, size int, err error) {
+	metrics.IndexNodeEtcdLatency.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.IndexNodeEtcdErrors.WithLabelValues(operation, etcdErrorCategory(err)).Inc()
+		return
+	}
+	metrics.IndexNodeEtcdBytes.WithLabelValues(operation).Add(float64(size))
+}
+
+func valuesSize(values []string) int {
+	size := 0
+	for _, value := range values {
+		size += len(value)
+	}
+	return size
+}
+
+func (kv *metricsEtcdKV) Load(key string) (string, error) {
+	start := time.Now()
+	value, err := kv.EtcdKV.Load(key)
+	observeEtcd(metrics.IndexNodeEtcdLoad, start, len(value), err)
+	return value, err
+}
+
+func (kv *metricsEtcdKV) LoadWithPrefix(key string) ([]string, []string, error) {
+	start := time.Now()
+	keys, values, err := kv.EtcdKV.LoadWithPrefix(key)
+	observeEtcd(metrics.IndexNodeEtcdLoadWithPrefix, start, valuesSize(values), err)
+	return keys, values, err
+}
+
+func (kv *metricsEtcdKV) LoadWithPrefix2(key string) ([]string, []string, []int64, error) {
+	start := time.Now()
+	keys, values, versions, err := kv.EtcdKV.LoadWithPrefix2(key)
+	observeEtcd(metrics.IndexNodeEtcdLoadWithPrefix, start, valuesSize(values), err)
+	return keys, values, versions, err
+}
+
+func (kv *metricsEtcdKV) Save(key, value string) error {
+	start := time.Now()
+	err := kv.EtcdKV.Save(key, value)
+	observeEtcd(metrics.IndexNodeEtcdSave, start, len(value), err)
+	return err
+}
+
+func (kv *metricsEtcdKV) MultiSave(kvs map[string]string) error {
+	start := time.Now()
+	err := kv.EtcdKV.MultiSave(kvs)
+	size := 0
+	for _, value := range kvs {
+		size += len(value)
+	}
+	observeEtcd(metrics.IndexNodeEtcdMultiSave, start, size, err)
+	return err
+}
+
+func (kv *metricsEtcdKV) Remove(key string) error {
+	start := time.Now()
+	err := kv.EtcdKV.Remove(key)
+	observeEtcd(metrics.IndexNodeEtcdRemove, start, 0, err)
+	return err
+}
+
+func (kv *metricsEtcdKV) RemoveWithPrefix(prefix string) error {
+	start := time.Now()
+	err := kv.EtcdKV.RemoveWithPrefix(prefix)
+	observeEtcd(metrics.IndexNodeEtcdRemoveWithPrefix, start, 0, err)
+	return err
+}
+
+func (kv *metricsEtcdKV) CompareVersionAndSwap(key string, version int64, target string, opts ...clientv3.OpOption) error {
+	start := time.Now()
+	err := kv.EtcdKV.CompareVersionAndSwap(key, version, target, opts...)
+	observeEtcd(metrics.IndexNodeEtcdCompareAndSwap, start, len(target), err)
+	return err
+}
+
+// sessionLeaseTTL returns the remaining time to live of the lease of the session in seconds, 0 if IndexNode has
+// not registered, and -1 if the lease has expired or is unknown.
+func sessionLeaseTTL(lease sessionLease) float64 {
+	if lease == nil {
+		return 0
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), leaseTTLTimeout)
+	defer cancel()
+	_, ttl, err := lease.GetLeaseTTL(ctx)
+	if err != nil || ttl < 0 {
+		return -1
+	}
+	return float64(ttl)
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	etcdkv "github.com/milvus-io/milvus/internal/kv/etcd"
+	"github.com/milvus-io/milvus/internal/metrics"
+)
+
+func etcdLatencyCount(t *testing.T, operation string) uint64 {
+	m := &dto.Metric{}
+	assert.Nil(t, metrics.IndexNodeEtcdLatency.WithLabelValues(operation).(prometheus.Histogram).Write(m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func etcdBytes(operation string) float64 {
+	return testutil.ToFloat64(metrics.IndexNodeEtcdBytes.WithLabelValues(operation))
+}
+
+func etcdErrors(operation string, category string) float64 {
+	return testutil.ToFloat64(metrics.IndexNodeEtcdErrors.WithLabelValues(operation, category))
+}
+
+func TestMetricsEtcdKV(t *testing.T) {
+	e, _ := startEmbedEtcd(t)
+	// restart with a small request limit to fail the large saves
+	e.Close()
+	cfg := e.Config()
+	cfg.MaxRequestBytes = 4096
+	e = startEmbedEtcdWithConfig(t, &cfg)
+	defer e.Close()
+
+	client, err := etcdkv.NewEtcdKV([]string{cfg.ACUrls[0].Host}, "/metrics-etcd-kv")
+	assert.Nil(t, err)
+	defer client.Close()
+	kv := newMetricsEtcdKV(client)
+
+	type snapshot struct {
+		count uint64
+		bytes float64
+	}
+	operations := []string{metrics.IndexNodeEtcdLoad, metrics.IndexNodeEtcdLoadWithPrefix, metrics.IndexNodeEtcdSave,
+		metrics.IndexNodeEtcdMultiSave, metrics.IndexNodeEtcdRemove, metrics.IndexNodeEtcdRemoveWithPrefix,
+		metrics.IndexNodeEtcdCompareAndSwap}
+	before := make(map[string]snapshot)
+	for _, operation := range operations {
+		before[operation] = snapshot{etcdLatencyCount(t, operation), etcdBytes(operation)}
+	}
+	notFound := etcdErrors(metrics.IndexNodeEtcdLoad, metrics.IndexNodeEtcdErrorNotFound)
+	tooLarge := etcdErrors(metrics.IndexNodeEtcdSave, metrics.IndexNodeEtcdErrorTooLarge)
+	conflict := etcdErrors(metrics.IndexNodeEtcdCompareAndSwap, metrics.IndexNodeEtcdErrorConflict)
+
+	assert.Nil(t, kv.Save("meta/1", "0123456789"))
+	assert.Nil(t, kv.MultiSave(map[string]string{"meta/2": "01234", "meta/3": "01234"}))
+	value, err := kv.Load("meta/1")
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789", value)
+	_, values, versions, err := kv.LoadWithPrefix2("meta/")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(values))
+	assert.Nil(t, kv.CompareVersionAndSwap("meta/1", versions[0], "01234"))
+	assert.Nil(t, kv.Remove("meta/2"))
+	assert.Nil(t, kv.RemoveWithPrefix("meta/"))
+
+	expectedBytes := map[string]float64{
+		metrics.IndexNodeEtcdLoad:           10,
+		metrics.IndexNodeEtcdLoadWithPrefix: 20,
+		metrics.IndexNodeEtcdSave:           10,
+		metrics.IndexNodeEtcdMultiSave:      10,
+		metrics.IndexNodeEtcdCompareAndSwap: 5,
+	}
+	for _, operation := range operations {
+		assert.Equal(t, before[operation].count+1, etcdLatencyCount(t, operation), operation)
+		assert.Equal(t, before[operation].bytes+expectedBytes[operation], etcdBytes(operation), operation)
+	}
+
+	_, err = kv.Load("meta/1")
+	assert.NotNil(t, err)
+	assert.Equal(t, notFound+1, etcdErrors(metrics.IndexNodeEtcdLoad, metrics.IndexNodeEtcdErrorNotFound))
+	assert.NotNil(t, kv.Save("meta/large", strings.Repeat("x", 8192)))
+	assert.Equal(t, tooLarge+1, etcdErrors(metrics.IndexNodeEtcdSave, metrics.IndexNodeEtcdErrorTooLarge))
+	assert.NotNil(t, kv.CompareVersionAndSwap("meta/1", 100, "01234"))
+	assert.Equal(t, conflict+1, etcdErrors(metrics.IndexNodeEtcdCompareAndSwap, metrics.IndexNodeEtcdErrorConflict))
+	// the failed operations carry no bytes
+	assert.Equal(t, before[metrics.IndexNodeEtcdSave].bytes+10, etcdBytes(metrics.IndexNodeEtcdSave))
+
+	// the keys are never the labels
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metrics.IndexNodeEtcdLatency, metrics.IndexNodeEtcdBytes, metrics.IndexNodeEtcdErrors)
+	families, err := registry.Gather()
+	assert.Nil(t, err)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				assert.NotContains(t, label.GetValue(), "meta/")
+			}
+		}
+	}
+}
+
+func TestEtcdErrorCategory(t *testing.T) {
+	for err, category := range map[error]string{
+		context.DeadlineExceeded:                                                    metrics.IndexNodeEtcdErrorTimeout,
+		fmt.Errorf("wrapped: %w", context.DeadlineExceeded):                         metrics.IndexNodeEtcdErrorTimeout,
+		rpctypes.ErrTimeout:                                                         metrics.IndexNodeEtcdErrorTimeout,
+		status.Error(codes.DeadlineExceeded, "deadline exceeded"):                   metrics.IndexNodeEtcdErrorTimeout,
+		rpctypes.ErrCompacted:                                                       metrics.IndexNodeEtcdErrorCompacted,
+		rpctypes.ErrRequestTooLarge:                                                 metrics.IndexNodeEtcdErrorTooLarge,
+		status.Error(codes.ResourceExhausted, "message too large"):                  metrics.IndexNodeEtcdErrorTooLarge,
+		errors.New("there is no value on key = a"):                                  metrics.IndexNodeEtcdErrorNotFound,
+		errors.New("function CompareAndSwap error for compare is false for key: a"): metrics.IndexNodeEtcdErrorConflict,
+		errors.New("connection refused"):                                            metrics.IndexNodeEtcdErrorOther,
+	} {
+		assert.Equal(t, category, etcdErrorCategory(err), err.Error())
+	}
+}
+
+func TestSessionLeaseTTL(t *testing.T) {
+	assert.Equal(t, float64(0), sessionLeaseTTL(nil))
+	assert.Equal(t, float64(30), sessionLeaseTTL(&mockSessionLease{ttl: 30}))
+	assert.Equal(t, float64(-1), sessionLeaseTTL(&mockSessionLease{ttl: -1}))
+	assert.Equal(t, float64(-1), sessionLeaseTTL(&mockSessionLease{err: errors.New("etcd unavailable")}))
+}
@@ -0,0 +1,107 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+// halfFloatBytes is the bytes of an element of the float16 and bfloat16 vectors.
+const halfFloatBytes = 2
+
+// isVectorDataType returns whether the binlogs of @dataType can be built on.
+func isVectorDataType(dataType schemapb.DataType) bool {
+	switch dataType {
+	case schemapb.DataType_FloatVector, schemapb.DataType_BinaryVector,
+		schemapb.DataType_Float16Vector, schemapb.DataType_BFloat16Vector:
+		return true
+	}
+	return false
+}
+
+// elementTypeOf returns the data type of the decoded vector field.
+func elementTypeOf(value storage.FieldData) schemapb.DataType {
+	switch value.(type) {
+	case *storage.FloatVectorFieldData:
+		return schemapb.DataType_FloatVector
+	case *storage.BinaryVectorFieldData:
+		return schemapb.DataType_BinaryVector
+	case *storage.Float16VectorFieldData:
+		return schemapb.DataType_Float16Vector
+	case *storage.BFloat16VectorFieldData:
+		return schemapb.DataType_BFloat16Vector
+	}
+	return schemapb.DataType_None
+}
+
+// checkElementType returns an error if the vectors decoded from the binlogs are not of the data type of the field
+// in @schema, which is not checked if the request carries no schema.
+func checkElementType(schema *schemapb.FieldSchema, elementType schemapb.DataType) error {
+	if schema == nil || schema.GetDataType() == elementType {
+		return nil
+	}
+	return errorcode.Errorf(errorcode.InvalidParams, "the vectors of the binlogs are %s, but the field %d is %s in the schema",
+		elementType.String(), schema.GetFieldID(), schema.GetDataType().String())
+}
+
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h) & 0x3ff
+	switch exp {
+	case 0x1f:
+		// inf or nan
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		// the subnormal float16 is a normal float32
+		exp = 127 - 15 + 1
+		for mant&0x400 == 0 {
+			mant <<= 1
+			exp--
+		}
+		return math.Float32frombits(sign | exp<<23 | (mant&0x3ff)<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+}
+
+func bfloat16ToFloat32(b uint16) float32 {
+	return math.Float32frombits(uint32(b) << 16)
+}
+
+// halfFloatsToFloat32 converts the little endian half-precision floats in @data by @convert.
+func halfFloatsToFloat32(data []byte, convert func(uint16) float32) []float32 {
+	floats := make([]float32, len(data)/halfFloatBytes)
+	for i := range floats {
+		floats[i] = convert(binary.LittleEndian.Uint16(data[i*halfFloatBytes:]))
+	}
+	return floats
+}
+
+// engineVectors returns the vectors to feed the engine, which builds the indexes on the float32 and the binary
+// vectors only, so the float16 and bfloat16 vectors are converted to float32 before they are fed.
+func engineVectors(value storage.FieldData) storage.FieldData {
+	switch data := value.(type) {
+	case *storage.Float16VectorFieldData:
+		return &storage.FloatVectorFieldData{NumRows: data.NumRows, Data: halfFloatsToFloat32(data.Data, float16ToFloat32), Dim: data.Dim}
+	case *storage.BFloat16VectorFieldData:
+		return &storage.FloatVectorFieldData{NumRows: data.NumRows, Data: halfFloatsToFloat32(data.Data, bfloat16ToFloat32), Dim: data.Dim}
+	}
+	return value
+}
@@ -0,0 +1,295 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	etcdkv "github.com/milvus-io/milvus/internal/kv/etcd"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/encryption"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+// float32ToFloat16 rounds @f to the nearest float16, the subnormal float16 are flushed to zero.
+func float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int32(bits>>23&0xff) - 127 + 15
+	mant := bits & 0x7fffff
+	switch {
+	case exp >= 0x1f:
+		return sign | 0x7c00
+	case exp <= 0:
+		return sign
+	}
+	half := uint32(exp)<<10 | mant>>13
+	// round half to even, the carry into the exponent is the correct rounding as well
+	if rest := mant & 0x1fff; rest > 0x1000 || (rest == 0x1000 && half&1 == 1) {
+		half++
+	}
+	return sign | uint16(half)
+}
+
+func float32ToBFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	return uint16((bits + 0x7fff + (bits >> 16 & 1)) >> 16)
+}
+
+func halfFloatsOf(vectors []float32, convert func(float32) uint16) []byte {
+	data := make([]byte, len(vectors)*halfFloatBytes)
+	for i, f := range vectors {
+		binary.LittleEndian.PutUint16(data[i*halfFloatBytes:], convert(f))
+	}
+	return data
+}
+
+func TestHalfFloatToFloat32(t *testing.T) {
+	for h, f := range map[uint16]float32{
+		0x0000: 0,
+		0x3c00: 1,
+		0xc000: -2,
+		0x3800: 0.5,
+		0x7bff: 65504,
+		0x0400: float32(math.Pow(2, -14)),
+		0x0001: float32(math.Pow(2, -24)),
+		0x0200: float32(math.Pow(2, -15)),
+		0x7c00: float32(math.Inf(1)),
+		0xfc00: float32(math.Inf(-1)),
+	} {
+		assert.Equal(t, f, float16ToFloat32(h), "%#04x", h)
+	}
+	assert.True(t, math.IsNaN(float64(float16ToFloat32(0x7e00))))
+	assert.Equal(t, uint32(0x80000000), math.Float32bits(float16ToFloat32(0x8000)))
+
+	for b, f := range map[uint16]float32{
+		0x0000: 0,
+		0x3f80: 1,
+		0xc000: -2,
+		0x4049: 3.140625,
+		0x7f80: float32(math.Inf(1)),
+	} {
+		assert.Equal(t, f, bfloat16ToFloat32(b), "%#04x", b)
+	}
+
+	// the conversion is exact for the values of the half-precision floats
+	for _, f := range []float32{0.1, -0.75, 3.3, 1000} {
+		half := float16ToFloat32(float32ToFloat16(f))
+		assert.Equal(t, half, float16ToFloat32(float32ToFloat16(half)))
+		assert.InDelta(t, f, float16ToFloat32(float32ToFloat16(f)), math.Abs(float64(f))/1024)
+		assert.InDelta(t, f, bfloat16ToFloat32(float32ToBFloat16(f)), math.Abs(float64(f))/128)
+	}
+}
+
+func TestEngineVectors(t *testing.T) {
+	vectors := []float32{1, -2, 0.5, 4}
+	float16 := &storage.Float16VectorFieldData{NumRows: []int64{2}, Data: halfFloatsOf(vectors, float32ToFloat16), Dim: 2}
+	bfloat16 := &storage.BFloat16VectorFieldData{NumRows: []int64{2}, Data: halfFloatsOf(vectors, float32ToBFloat16), Dim: 2}
+	for _, value := range []storage.FieldData{float16, bfloat16} {
+		converted, ok := engineVectors(value).(*storage.FloatVectorFieldData)
+		assert.True(t, ok)
+		assert.Equal(t, &storage.FloatVectorFieldData{NumRows: []int64{2}, Data: vectors, Dim: 2}, converted)
+		assert.Equal(t, int64(2), vectorRows(value))
+	}
+	binary := &storage.BinaryVectorFieldData{Data: []byte{1}, Dim: 8}
+	assert.Equal(t, storage.FieldData(binary), engineVectors(binary))
+
+	_, rowSize, size, err := vectorLayout(float16)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{4, 8}, []int{rowSize, size})
+	_, _, _, err = vectorLayout(&storage.BFloat16VectorFieldData{Data: make([]byte, 6), Dim: 2})
+	assert.NotNil(t, err)
+}
+
+func TestIndexBuildTask_checkVectors_elementType(t *testing.T) {
+	newTask := func(dataType schemapb.DataType) *IndexBuildTask {
+		return &IndexBuildTask{req: &indexpb.CreateIndexRequest{
+			IndexParams: []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: "IVF_FLAT"}, {Key: metricTypeKey, Value: "L2"}},
+			FieldSchema: &schemapb.FieldSchema{FieldID: 101, DataType: dataType},
+		}}
+	}
+	float16 := &storage.Float16VectorFieldData{Data: make([]byte, 16), Dim: 4}
+
+	task := newTask(schemapb.DataType_Float16Vector)
+	rows, err := task.checkVectors(float16)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, rows)
+	assert.Equal(t, schemapb.DataType_Float16Vector, task.elementType)
+	// the request without the schema takes the data type of the binlogs
+	task.req.FieldSchema = nil
+	_, err = task.checkVectors(&storage.BFloat16VectorFieldData{Data: make([]byte, 16), Dim: 4})
+	assert.Nil(t, err)
+	assert.Equal(t, schemapb.DataType_BFloat16Vector, task.elementType)
+
+	for _, dataType := range []schemapb.DataType{schemapb.DataType_FloatVector, schemapb.DataType_BFloat16Vector} {
+		_, err = newTask(dataType).checkVectors(float16)
+		assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+	}
+	// the half-precision vectors are built as the float vectors
+	task = newTask(schemapb.DataType_Float16Vector)
+	task.req.IndexParams = []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: "BIN_FLAT"}, {Key: metricTypeKey, Value: "HAMMING"}}
+	_, err = task.checkVectors(float16)
+	assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+}
+
+// newVectorTestSegment returns a segment of 3 binlogs of @vectors, whose field data are returned by @newData.
+func newVectorTestSegment(vectors []float32, dim int, newData func(vectors []float32, rows int) storage.FieldData) *pipelineTestSegment {
+	segment := newPipelineTestSegment(3, 0)
+	rowsPerBinlog := len(vectors) / dim / 3
+	for idx, path := range segment.paths {
+		segment.binlogs[path].data = newData(vectors[idx*rowsPerBinlog*dim:(idx+1)*rowsPerBinlog*dim], rowsPerBinlog)
+	}
+	return segment
+}
+
+// topK returns the offsets of the @k nearest vectors to @query in L2.
+func topK(vectors []float32, dim int, query []float32, k int) []int {
+	offsets := make([]int, len(vectors)/dim)
+	distances := make([]float64, len(offsets))
+	for offset := range offsets {
+		offsets[offset] = offset
+		for d := 0; d < dim; d++ {
+			diff := float64(vectors[offset*dim+d] - query[d])
+			distances[offset] += diff * diff
+		}
+	}
+	sort.SliceStable(offsets, func(i, j int) bool {
+		return distances[offsets[i]] < distances[offsets[j]]
+	})
+	return offsets[:k]
+}
+
+func TestBinlogPipeline_halfFloatRecall(t *testing.T) {
+	const dim, rows, queries, k = 16, 600, 30, 10
+	r := rand.New(rand.NewSource(1))
+	vectors := make([]float32, rows*dim)
+	for i := range vectors {
+		vectors[i] = r.Float32()*2 - 1
+	}
+	queryVectors := make([]float32, queries*dim)
+	for i := range queryVectors {
+		queryVectors[i] = r.Float32()*2 - 1
+	}
+	groundTruth := make([][]int, queries)
+	for q := range groundTruth {
+		groundTruth[q] = topK(vectors, dim, queryVectors[q*dim:(q+1)*dim], k)
+	}
+	// recall returns the recall@k of the FLAT index built on the vectors fed to @index
+	recall := func(index *mockIncrementalIndex) float64 {
+		assert.Equal(t, len(vectors), len(index.floatData))
+		hits := 0
+		for q, truth := range groundTruth {
+			for _, offset := range topK(index.floatData, dim, queryVectors[q*dim:(q+1)*dim], k) {
+				for _, expected := range truth {
+					if offset == expected {
+						hits++
+					}
+				}
+			}
+		}
+		return float64(hits) / float64(queries*k)
+	}
+	build := func(segment *pipelineTestSegment) *mockIncrementalIndex {
+		index := &mockIncrementalIndex{}
+		assert.Nil(t, segment.pipeline(index, 64).run(context.Background()))
+		return index
+	}
+
+	float32Segment := newVectorTestSegment(vectors, dim, func(vectors []float32, rows int) storage.FieldData {
+		return &storage.FloatVectorFieldData{NumRows: []int64{int64(rows)}, Data: vectors, Dim: dim}
+	})
+	float32Recall := recall(build(float32Segment))
+	assert.Equal(t, 1.0, float32Recall)
+
+	float16Segment := newVectorTestSegment(vectors, dim, func(vectors []float32, rows int) storage.FieldData {
+		return &storage.Float16VectorFieldData{NumRows: []int64{int64(rows)}, Data: halfFloatsOf(vectors, float32ToFloat16), Dim: dim}
+	})
+	float16Recall := recall(build(float16Segment))
+	assert.GreaterOrEqual(t, float16Recall, 0.95)
+
+	bfloat16Segment := newVectorTestSegment(vectors, dim, func(vectors []float32, rows int) storage.FieldData {
+		return &storage.BFloat16VectorFieldData{NumRows: []int64{int64(rows)}, Data: halfFloatsOf(vectors, float32ToBFloat16), Dim: dim}
+	})
+	bfloat16Recall := recall(build(bfloat16Segment))
+	assert.GreaterOrEqual(t, bfloat16Recall, 0.9)
+	assert.GreaterOrEqual(t, float16Recall, bfloat16Recall)
+	t.Logf("recall@%d float32: %.3f, float16: %.3f, bfloat16: %.3f", k, float32Recall, float16Recall, bfloat16Recall)
+
+	// the binlogs of the different element types are never built together
+	bfloat16Segment.binlogs[bfloat16Segment.paths[2]].data = float16Segment.binlogs[float16Segment.paths[2]].data
+	err := bfloat16Segment.pipeline(&mockIncrementalIndex{}, 64).run(context.Background())
+	assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+}
+
+func TestIndexBuildTask_elementTypeMeta(t *testing.T) {
+	e, endpoints := startEmbedEtcd(t)
+	defer e.Close()
+	client, err := etcdkv.NewEtcdKV(endpoints, "/element-type")
+	assert.Nil(t, err)
+	defer client.Close()
+
+	keyring, err := encryption.NewKeyring([]encryption.KEK{newTestKEK(t, "k1")})
+	assert.Nil(t, err)
+	dataKey, err := keyring.NewDataKey()
+	assert.Nil(t, err)
+	for _, failed := range []bool{false, true} {
+		meta := &indexpb.IndexMeta{IndexBuildID: 1, Version: 1, State: commonpb.IndexState_InProgress}
+		value, err := proto.Marshal(meta)
+		assert.Nil(t, err)
+		assert.Nil(t, client.Save("indexes/1", string(value)))
+
+		it := &IndexBuildTask{
+			etcdKV:      newMetricsEtcdKV(client),
+			req:         &indexpb.CreateIndexRequest{IndexBuildID: 1, Version: 1, MetaPath: "indexes/1"},
+			elementType: schemapb.DataType_Float16Vector,
+			normalized:  true,
+			encryption:  indexEncryptionOf(dataKey),
+			buildSeed:   &indexpb.IndexBuildSeed{Seed: 7, Honored: true},
+			base:        &indexpb.IndexMeta{IndexBuildID: 3, Version: 2},
+		}
+		if failed {
+			it.SetError(errNoVectors)
+		}
+		assert.Nil(t, it.checkIndexMeta(context.Background(), false))
+
+		value2, err := client.Load("indexes/1")
+		assert.Nil(t, err)
+		assert.Nil(t, proto.Unmarshal([]byte(value2), meta))
+		if failed {
+			assert.Equal(t, schemapb.DataType_None, meta.ElementType)
+			assert.False(t, meta.Normalized)
+			assert.Nil(t, meta.Encryption)
+			assert.Nil(t, meta.BuildSeed)
+			assert.Equal(t, int64(0), meta.BaseIndexBuildID)
+		} else {
+			assert.Equal(t, schemapb.DataType_Float16Vector, meta.ElementType)
+			assert.True(t, meta.Normalized)
+			assert.Equal(t, "k1", meta.Encryption.KeyId)
+			assert.Equal(t, dataKey.WrappedKey, meta.Encryption.WrappedKey)
+			assert.Equal(t, int64(7), meta.BuildSeed.Seed)
+			assert.True(t, meta.BuildSeed.Honored)
+			assert.Equal(t, int64(3), meta.BaseIndexBuildID)
+			assert.Equal(t, int64(2), meta.BaseVersion)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/disk"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+)
+
+const (
+	dependencyEtcdSession = probeEtcdSession
+	dependencyStorage     = probeStorage
+	dependencyScheduler   = "scheduler"
+	dependencyDisk        = "disk"
+
+	// reason codes reported in the component states when a dependency check fails
+	reasonSessionNotRegistered = "SESSION_NOT_REGISTERED"
+	reasonSessionExpired       = "SESSION_EXPIRED"
+	reasonSessionLeaseUnknown  = "SESSION_LEASE_UNKNOWN"
+	reasonStorageNotChecked    = "STORAGE_NOT_CHECKED"
+	reasonStorageCheckFailed   = "STORAGE_CHECK_FAILED"
+	reasonSchedulerStopped     = "SCHEDULER_STOPPED"
+	reasonTaskQueueFull        = "TASK_QUEUE_FULL"
+	reasonDiskNotChecked       = "DISK_NOT_CHECKED"
+	reasonDiskUnavailable      = "DISK_UNAVAILABLE"
+	reasonDiskSpaceLow         = "DISK_SPACE_LOW"
+
+	healthReasonCodeKey = "reason_code"
+	healthReasonKey     = "reason"
+
+	storageCheckInterval    = time.Minute
+	dependencyCheckInterval = 10 * time.Second
+	leaseTTLTimeout         = 3 * time.Second
+
+	// scratchMinFreeSpace is the free space of the scratch path below which the disk is considered full
+	scratchMinFreeSpace = 64 * 1024 * 1024
+)
+
+// sessionLease is the lease of the etcd session which keeps IndexNode registered.
+type sessionLease interface {
+	GetLeaseTTL(ctx context.Context) (clientv3.LeaseID, int64, error)
+}
+
+// dependencyState is the health state of a dependency of IndexNode, the dependency is healthy if code is empty.
+// IndexNode is not healthy when a critical dependency fails.
+type dependencyState struct {
+	name      string
+	critical  bool
+	code      string
+	message   string
+	extraInfo []*commonpb.KeyValuePair
+}
+
+func (s *dependencyState) healthy() bool {
+	return s.code == ""
+}
+
+func (s *dependencyState) fail(critical bool, code string, message string) *dependencyState {
+	s.critical = critical
+	s.code = code
+	s.message = message
+	return s
+}
+
+func (s *dependencyState) addInfo(key string, value string) {
+	s.extraInfo = append(s.extraInfo, &commonpb.KeyValuePair{Key: key, Value: value})
+}
+
+func (s *dependencyState) componentInfo() *internalpb.ComponentInfo {
+	info := &internalpb.ComponentInfo{
+		NodeID:    Params.NodeID,
+		Role:      s.name,
+		StateCode: internalpb.StateCode_Healthy,
+		ExtraInfo: s.extraInfo,
+	}
+	if !s.healthy() {
+		info.StateCode = internalpb.StateCode_Abnormal
+		info.ExtraInfo = append(info.ExtraInfo,
+			&commonpb.KeyValuePair{Key: healthReasonCodeKey, Value: s.code},
+			&commonpb.KeyValuePair{Key: healthReasonKey, Value: s.message})
+	}
+	return info
+}
+
+// dependencyCache caches the states of the dependencies whose checks are slow, which are refreshed by
+// dependencyCheckLoop instead of being checked by each GetComponentStates, the zero value is empty.
+type dependencyCache struct {
+	mu     sync.RWMutex
+	states map[string]*dependencyState
+}
+
+// get returns a copy of the cached state of the dependency, false if it has not been checked.
+func (c *dependencyCache) get(name string) (*dependencyState, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	state, ok := c.states[name]
+	if !ok {
+		return nil, false
+	}
+	copied := *state
+	copied.extraInfo = append([]*commonpb.KeyValuePair{}, state.extraInfo...)
+	return &copied, true
+}
+
+func (c *dependencyCache) set(state *dependencyState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.states == nil {
+		c.states = make(map[string]*dependencyState)
+	}
+	c.states[state.name] = state
+}
+
+// checkSessionHealth returns the state of the etcd session, the registration and the expiration reported by the
+// readiness probe are checked at once, while the lease is the last one checked by checkLeaseHealth.
+func checkSessionHealth(lease sessionLease, probe *readinessProbe, cache *dependencyCache) *dependencyState {
+	state := &dependencyState{name: dependencyEtcdSession}
+	if lease == nil {
+		return state.fail(true, reasonSessionNotRegistered, "IndexNode has not registered to etcd")
+	}
+	if _, err := probe.check(probeEtcdSession); err != nil {
+		return state.fail(true, reasonSessionExpired, err.Error())
+	}
+	if cached, ok := cache.get(dependencyEtcdSession); ok {
+		return cached
+	}
+	return state.fail(true, reasonSessionLeaseUnknown, "the lease of session has not been checked yet")
+}
+
+// checkLeaseHealth checks the lease of the etcd session, which takes up to leaseTTLTimeout if etcd stalls.
+func checkLeaseHealth(ctx context.Context, lease sessionLease) *dependencyState {
+	state := &dependencyState{name: dependencyEtcdSession}
+	state.addInfo("last_check_time", time.Now().Format(time.RFC3339))
+	ctx, cancel := context.WithTimeout(ctx, leaseTTLTimeout)
+	defer cancel()
+	leaseID, ttl, err := lease.GetLeaseTTL(ctx)
+	state.addInfo("lease_id", strconv.FormatInt(int64(leaseID), 10))
+	if err != nil {
+		return state.fail(true, reasonSessionLeaseUnknown, fmt.Sprintf("failed to get the lease of session: %s", err.Error()))
+	}
+	state.addInfo("ttl_remaining", strconv.FormatInt(ttl, 10))
+	if ttl <= 0 {
+		return state.fail(true, reasonSessionExpired, "the lease of session has expired")
+	}
+	return state
+}
+
+func checkStorageHealth(probe *readinessProbe) *dependencyState {
+	state := &dependencyState{name: dependencyStorage}
+	checkTime, err := probe.check(probeStorage)
+	if err == errProbeNotChecked {
+		return state.fail(true, reasonStorageNotChecked, "object storage has not been checked yet")
+	}
+	state.addInfo("last_check_time", checkTime.Format(time.RFC3339))
+	if err != nil {
+		state.addInfo("last_check_result", err.Error())
+		return state.fail(true, reasonStorageCheckFailed, err.Error())
+	}
+	state.addInfo("last_check_result", probeCheckPassed)
+	return state
+}
+
+func checkSchedulerHealth(sched *TaskScheduler) *dependencyState {
+	state := &dependencyState{name: dependencyScheduler}
+	queueDepth, capacity := sched.IndexBuildQueue.utLen(), sched.IndexBuildQueue.utCap()
+	state.addInfo("queue_depth", strconv.Itoa(queueDepth))
+	state.addInfo("queue_capacity", strconv.Itoa(capacity))
+	if sched.ctx.Err() != nil {
+		return state.fail(true, reasonSchedulerStopped, "the task scheduler has been stopped")
+	}
+	if queueDepth >= capacity {
+		// a full queue rejects new tasks but does not make IndexNode unhealthy
+		return state.fail(false, reasonTaskQueueFull, fmt.Sprintf("the task queue is full, depth: %d, capacity: %d", queueDepth, capacity))
+	}
+	return state
+}
+
+// checkDiskHealth checks the disk usage of the scratch path.
+func checkDiskHealth(scratchPath string) *dependencyState {
+	state := &dependencyState{name: dependencyDisk}
+	state.addInfo("path", scratchPath)
+	state.addInfo("last_check_time", time.Now().Format(time.RFC3339))
+	usage, err := disk.Usage(scratchPath)
+	if err != nil {
+		return state.fail(true, reasonDiskUnavailable, fmt.Sprintf("failed to get the disk usage of scratch path: %s", err.Error()))
+	}
+	state.addInfo("free", strconv.FormatUint(usage.Free, 10))
+	state.addInfo("total", strconv.FormatUint(usage.Total, 10))
+	if usage.Free < scratchMinFreeSpace {
+		return state.fail(true, reasonDiskSpaceLow, fmt.Sprintf("free space of scratch path is %d bytes, less than %d bytes", usage.Free, scratchMinFreeSpace))
+	}
+	return state
+}
+
+// cachedDiskHealth returns the last state of the disk checked by checkDiskHealth.
+func cachedDiskHealth(cache *dependencyCache) *dependencyState {
+	if cached, ok := cache.get(dependencyDisk); ok {
+		return cached
+	}
+	state := &dependencyState{name: dependencyDisk}
+	return state.fail(true, reasonDiskNotChecked, "the disk of scratch path has not been checked yet")
+}
+
+// checkDependencies returns the states of the dependencies without blocking, the slow checks are the cached
+// results of refreshDependencies.
+func (i *IndexNode) checkDependencies(lease sessionLease) []*dependencyState {
+	return []*dependencyState{
+		checkSessionHealth(lease, i.probe, &i.dependencies),
+		checkStorageHealth(i.probe),
+		checkSchedulerHealth(i.sched),
+		cachedDiskHealth(&i.dependencies),
+	}
+}
+
+// refreshDependencies runs the slow checks of the dependencies and caches the results, the lease is checked only
+// if the node has registered.
+func (i *IndexNode) refreshDependencies(ctx context.Context, lease sessionLease) {
+	if lease != nil {
+		i.dependencies.set(checkLeaseHealth(ctx, lease))
+	}
+	i.dependencies.set(checkDiskHealth(Params.ScratchPath))
+}
+
+// sessionLease returns the lease of the session of the node, nil if it has not registered.
+func (i *IndexNode) sessionLease() sessionLease {
+	if i.session == nil {
+		return nil
+	}
+	return i.session
+}
+
+// dependencyCheckLoop refreshes the cached states of the dependencies periodically.
+func (i *IndexNode) dependencyCheckLoop() {
+	ticker := time.NewTicker(dependencyCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-i.loopCtx.Done():
+			return
+		case <-ticker.C:
+			i.refreshDependencies(i.loopCtx, i.sessionLease())
+		}
+	}
+}
+
+// stateCodeWithDependencies returns the state code degraded by the failed critical dependencies,
+// along with the reasons of all failed dependencies in the form of code and message.
+func stateCodeWithDependencies(code internalpb.StateCode, states []*dependencyState) (internalpb.StateCode, []*commonpb.KeyValuePair) {
+	reasons := make([]*commonpb.KeyValuePair, 0)
+	for _, state := range states {
+		if state.healthy() {
+			continue
+		}
+		reasons = append(reasons, &commonpb.KeyValuePair{Key: state.code, Value: state.message})
+		if state.critical && code == internalpb.StateCode_Healthy {
+			code = internalpb.StateCode_Abnormal
+		}
+	}
+	return code, reasons
+}
+
+// storageCheckLoop checks the object storage periodically and updates the readiness probe.
+func (i *IndexNode) storageCheckLoop() {
+	ticker := time.NewTicker(storageCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-i.loopCtx.Done():
+			return
+		case <-ticker.C:
+			err := i.checkStorage()
+			if err != nil {
+				log.Warn("IndexNode storage check failed", zap.Error(err))
+			}
+			i.probe.update(probeStorage, err)
+		}
+	}
+}
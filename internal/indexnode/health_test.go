@@ -0,0 +1,203 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+	"github.com/stretchr/testify/assert"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+type mockSessionLease struct {
+	ttl   int64
+	err   error
+	calls int
+}
+
+func (m *mockSessionLease) GetLeaseTTL(ctx context.Context) (clientv3.LeaseID, int64, error) {
+	m.calls++
+	return 100, m.ttl, m.err
+}
+
+func extraInfo(info *internalpb.ComponentInfo, key string) string {
+	for _, kv := range info.ExtraInfo {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return ""
+}
+
+// newHealthyIndexNode returns an IndexNode whose dependencies all pass except the etcd session,
+// which is provided by mockSessionLease in the tests.
+func newHealthyIndexNode(t *testing.T) (*IndexNode, func()) {
+	in, err := NewIndexNode(context.Background())
+	assert.Nil(t, err)
+	in.probe.update(probeEtcdSession, nil)
+	in.probe.update(probeStorage, nil)
+	in.setStartupPhase(startupPhaseServing)
+	in.UpdateStateCode(internalpb.StateCode_Healthy)
+
+	scratchPath, err := ioutil.TempDir("", "indexnode_health")
+	assert.Nil(t, err)
+	oldScratchPath := Params.ScratchPath
+	Params.ScratchPath = scratchPath
+	in.refreshDependencies(context.Background(), nil)
+	return in, func() {
+		Params.ScratchPath = oldScratchPath
+		os.RemoveAll(scratchPath)
+		in.Stop()
+	}
+}
+
+func assertDependencyFailed(t *testing.T, in *IndexNode, lease sessionLease, name string, code string, critical bool) {
+	in.refreshDependencies(context.Background(), lease)
+	states := in.checkDependencies(lease)
+	for _, state := range states {
+		info := state.componentInfo()
+		if state.name == name {
+			assert.Equal(t, internalpb.StateCode_Abnormal, info.StateCode, name)
+			assert.Equal(t, code, extraInfo(info, healthReasonCodeKey), name)
+			assert.NotEmpty(t, extraInfo(info, healthReasonKey), name)
+		} else {
+			assert.Equal(t, internalpb.StateCode_Healthy, info.StateCode, state.name)
+		}
+	}
+	stateCode, reasons := stateCodeWithDependencies(internalpb.StateCode_Healthy, states)
+	if critical {
+		assert.Equal(t, internalpb.StateCode_Abnormal, stateCode)
+	} else {
+		assert.Equal(t, internalpb.StateCode_Healthy, stateCode)
+	}
+	assert.Equal(t, 1, len(reasons))
+	assert.Equal(t, code, reasons[0].Key)
+}
+
+func TestIndexNode_checkDependencies(t *testing.T) {
+	t.Run("all passed", func(t *testing.T) {
+		in, clean := newHealthyIndexNode(t)
+		defer clean()
+		lease := &mockSessionLease{ttl: 10}
+		in.refreshDependencies(context.Background(), lease)
+		states := in.checkDependencies(lease)
+		assert.Equal(t, 4, len(states))
+		for _, state := range states {
+			assert.True(t, state.healthy(), state.name)
+		}
+		stateCode, reasons := stateCodeWithDependencies(internalpb.StateCode_Healthy, states)
+		assert.Equal(t, internalpb.StateCode_Healthy, stateCode)
+		assert.Equal(t, 0, len(reasons))
+
+		session := states[0].componentInfo()
+		assert.Equal(t, "100", extraInfo(session, "lease_id"))
+		assert.Equal(t, "10", extraInfo(session, "ttl_remaining"))
+		storage := states[1].componentInfo()
+		assert.NotEmpty(t, extraInfo(storage, "last_check_time"))
+		assert.Equal(t, probeCheckPassed, extraInfo(storage, "last_check_result"))
+		scheduler := states[2].componentInfo()
+		assert.Equal(t, "0", extraInfo(scheduler, "queue_depth"))
+		assert.Equal(t, "1024", extraInfo(scheduler, "queue_capacity"))
+		assert.Equal(t, Params.ScratchPath, extraInfo(states[3].componentInfo(), "path"))
+
+		// the states are the cached ones, which are not modified by the readers
+		assert.Equal(t, 1, lease.calls)
+		states = in.checkDependencies(lease)
+		assert.Equal(t, 1, lease.calls)
+		assert.Equal(t, session, states[0].componentInfo())
+	})
+
+	t.Run("not checked", func(t *testing.T) {
+		in, clean := newHealthyIndexNode(t)
+		defer clean()
+		in.dependencies = dependencyCache{}
+		states := in.checkDependencies(&mockSessionLease{ttl: 10})
+		assert.Equal(t, reasonSessionLeaseUnknown, states[0].code)
+		assert.Equal(t, reasonDiskNotChecked, states[3].code)
+	})
+
+	t.Run("session not registered", func(t *testing.T) {
+		in, clean := newHealthyIndexNode(t)
+		defer clean()
+		assertDependencyFailed(t, in, nil, dependencyEtcdSession, reasonSessionNotRegistered, true)
+	})
+
+	t.Run("session expired", func(t *testing.T) {
+		in, clean := newHealthyIndexNode(t)
+		defer clean()
+		assertDependencyFailed(t, in, &mockSessionLease{ttl: -1}, dependencyEtcdSession, reasonSessionExpired, true)
+		in.probe.update(probeEtcdSession, errors.New("etcd session expired"))
+		assertDependencyFailed(t, in, &mockSessionLease{ttl: 10}, dependencyEtcdSession, reasonSessionExpired, true)
+	})
+
+	t.Run("session lease unknown", func(t *testing.T) {
+		in, clean := newHealthyIndexNode(t)
+		defer clean()
+		assertDependencyFailed(t, in, &mockSessionLease{err: errors.New("etcd unavailable")}, dependencyEtcdSession, reasonSessionLeaseUnknown, true)
+	})
+
+	t.Run("storage", func(t *testing.T) {
+		in, clean := newHealthyIndexNode(t)
+		defer clean()
+		in.probe.update(probeStorage, errors.New("bucket not found"))
+		assertDependencyFailed(t, in, &mockSessionLease{ttl: 10}, dependencyStorage, reasonStorageCheckFailed, true)
+		in.probe = newReadinessProbe()
+		in.probe.update(probeEtcdSession, nil)
+		assertDependencyFailed(t, in, &mockSessionLease{ttl: 10}, dependencyStorage, reasonStorageNotChecked, true)
+	})
+
+	t.Run("scheduler", func(t *testing.T) {
+		in, clean := newHealthyIndexNode(t)
+		defer clean()
+		in.sched.IndexBuildQueue.(*IndexBuildTaskQueue).maxTaskNum = 0
+		assertDependencyFailed(t, in, &mockSessionLease{ttl: 10}, dependencyScheduler, reasonTaskQueueFull, false)
+		in.sched.IndexBuildQueue.(*IndexBuildTaskQueue).maxTaskNum = 1024
+		in.sched.cancel()
+		assertDependencyFailed(t, in, &mockSessionLease{ttl: 10}, dependencyScheduler, reasonSchedulerStopped, true)
+	})
+
+	t.Run("disk", func(t *testing.T) {
+		in, clean := newHealthyIndexNode(t)
+		defer clean()
+		Params.ScratchPath = path.Join(Params.ScratchPath, "not_exist")
+		assertDependencyFailed(t, in, &mockSessionLease{ttl: 10}, dependencyDisk, reasonDiskUnavailable, true)
+	})
+}
+
+func TestIndexNode_GetComponentStatesWithDependencies(t *testing.T) {
+	in, clean := newHealthyIndexNode(t)
+	defer clean()
+
+	// the session is not registered
+	states, err := in.GetComponentStates(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, states.Status.ErrorCode)
+	assert.Equal(t, internalpb.StateCode_Abnormal, states.State.StateCode)
+	assert.Equal(t, 4, len(states.SubcomponentStates))
+	// the reason of the failed dependency is followed by the mode and the lifetime infos
+	assert.Equal(t, 2+len(lifetimeInfos()), len(states.State.ExtraInfo))
+	assert.Equal(t, reasonSessionNotRegistered, states.State.ExtraInfo[0].Key)
+	for _, info := range states.SubcomponentStates {
+		if info.Role == dependencyEtcdSession {
+			assert.Equal(t, internalpb.StateCode_Abnormal, info.StateCode)
+		} else {
+			assert.Equal(t, internalpb.StateCode_Healthy, info.StateCode, info.Role)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"encoding/json"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+)
+
+// TaskHeartbeatPrefix is the prefix of the etcd keys of the task heartbeats, the key of a task is
+// TaskHeartbeatPrefix/{indexBuildID} under the meta root path, and expires if IndexNode stops reporting it.
+const TaskHeartbeatPrefix = "indexnode-task-heartbeats"
+
+// the stages of an index build task
+const (
+	taskStagePreExecute  = "pre_execute"
+	taskStageLoad        = "load"
+	taskStageBuild       = "build"
+	taskStageSerialize   = "serialize"
+	taskStageSave        = "save"
+	taskStagePostExecute = "post_execute"
+)
+
+// taskStages are the stages of an index build task in the order they are run.
+var taskStages = []string{taskStagePreExecute, taskStageLoad, taskStageBuild, taskStageSerialize, taskStageSave,
+	taskStagePostExecute}
+
+// TaskHeartbeat is the heartbeat of an in-progress task, LastProgressTime stops advancing if the task is stuck,
+// while ReportTime advances as long as IndexNode is alive.
+type TaskHeartbeat struct {
+	NodeID           UniqueID  `json:"node_id"`
+	IndexBuildID     UniqueID  `json:"index_build_id"`
+	Version          int64     `json:"version"`
+	Stage            string    `json:"stage"`
+	StageStartTime   time.Time `json:"stage_start_time"`
+	LastProgressTime time.Time `json:"last_progress_time"`
+	ReportTime       time.Time `json:"report_time"`
+	// Suspect means the task stays in the stage longer than indexNode.taskHeartbeat.stallTimeout
+	Suspect bool `json:"suspect"`
+}
+
+// taskProgress is the stage and the liveness of an in-progress task.
+type taskProgress struct {
+	indexBuildID UniqueID
+	version      int64
+
+	mu           sync.Mutex
+	stage        string
+	stageStart   time.Time
+	lastProgress time.Time
+	suspect      bool
+	// durations is the time spent in each of the stages left, a stage entered more than once accumulates
+	durations map[string]time.Duration
+}
+
+func newTaskProgress(indexBuildID UniqueID, version int64) *taskProgress {
+	now := time.Now()
+	return &taskProgress{
+		indexBuildID: indexBuildID,
+		version:      version,
+		stage:        taskStagePreExecute,
+		stageStart:   now,
+		lastProgress: now,
+		durations:    make(map[string]time.Duration),
+	}
+}
+
+// setStage moves the task to the stage, a nil progress tracks nothing.
+func (p *taskProgress) setStage(stage string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	p.durations[p.stage] += now.Sub(p.stageStart)
+	p.stage = stage
+	p.stageStart = now
+	p.lastProgress = now
+	p.suspect = false
+}
+
+// stageDurations returns the time spent in each of the stages entered by @now, including the current one, a nil
+// progress returns nil.
+func (p *taskProgress) stageDurations(now time.Time) map[string]time.Duration {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	durations := make(map[string]time.Duration, len(p.durations)+1)
+	for stage, duration := range p.durations {
+		durations[stage] = duration
+	}
+	elapsed := now.Sub(p.stageStart)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	durations[p.stage] += elapsed
+	return durations
+}
+
+// advance records the progress made in the current stage, such as a file is loaded.
+func (p *taskProgress) advance() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastProgress = time.Now()
+}
+
+// checkStalled marks the task suspect if it stays in the current stage longer than @stallTimeout,
+// it returns true if the task becomes suspect.
+func (p *taskProgress) checkStalled(now time.Time, stallTimeout time.Duration) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.suspect || stallTimeout <= 0 || now.Sub(p.stageStart) <= stallTimeout {
+		return false
+	}
+	p.suspect = true
+	return true
+}
+
+func (p *taskProgress) heartbeat(nodeID UniqueID, now time.Time) *TaskHeartbeat {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return &TaskHeartbeat{
+		NodeID:           nodeID,
+		IndexBuildID:     p.indexBuildID,
+		Version:          p.version,
+		Stage:            p.stage,
+		StageStartTime:   p.stageStart,
+		LastProgressTime: p.lastProgress,
+		ReportTime:       now,
+		Suspect:          p.suspect,
+	}
+}
+
+// heartbeatKV is the storage of the task heartbeats, which is implemented by etcdkv.EtcdKV.
+type heartbeatKV interface {
+	Grant(ttl int64) (clientv3.LeaseID, error)
+	SaveWithLease(key, value string, id clientv3.LeaseID) error
+}
+
+// taskTracker tracks the in-progress tasks of IndexNode.
+type taskTracker struct {
+	mu    sync.Mutex
+	tasks map[UniqueID]*taskProgress
+}
+
+func newTaskTracker() *taskTracker {
+	return &taskTracker{
+		tasks: make(map[UniqueID]*taskProgress),
+	}
+}
+
+func (t *taskTracker) add(progress *taskProgress) {
+	if t == nil || progress == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tasks[progress.indexBuildID] = progress
+}
+
+func (t *taskTracker) remove(progress *taskProgress) {
+	if t == nil || progress == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// the task may be replaced by a newer version of the same build
+	if t.tasks[progress.indexBuildID] == progress {
+		delete(t.tasks, progress.indexBuildID)
+	}
+}
+
+func (t *taskTracker) snapshot() []*taskProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tasks := make([]*taskProgress, 0, len(t.tasks))
+	for _, progress := range t.tasks {
+		tasks = append(tasks, progress)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].indexBuildID < tasks[j].indexBuildID
+	})
+	return tasks
+}
+
+// count returns the number of the in-progress tasks.
+func (t *taskTracker) count() int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.tasks)
+}
+
+// checkStalled marks the tasks staying in a stage longer than @stallTimeout suspect.
+func (t *taskTracker) checkStalled(now time.Time, stallTimeout time.Duration) {
+	for _, progress := range t.snapshot() {
+		if progress.checkStalled(now, stallTimeout) {
+			heartbeat := progress.heartbeat(Params.NodeID, now)
+			log.Warn("IndexNode task does not advance its stage in time, it may be stuck",
+				zap.Int64("indexBuildID", heartbeat.IndexBuildID), zap.Int64("version", heartbeat.Version),
+				zap.String("stage", heartbeat.Stage), zap.Time("stageStartTime", heartbeat.StageStartTime),
+				zap.Duration("stallTimeout", stallTimeout))
+		}
+	}
+}
+
+// suspectBuildIDs returns the tasks which are suspected to be stuck.
+func (t *taskTracker) suspectBuildIDs() []UniqueID {
+	buildIDs := make([]UniqueID, 0)
+	for _, progress := range t.snapshot() {
+		progress.mu.Lock()
+		if progress.suspect {
+			buildIDs = append(buildIDs, progress.indexBuildID)
+		}
+		progress.mu.Unlock()
+	}
+	return buildIDs
+}
+
+// report saves the heartbeats of the in-progress tasks with a lease of @ttl, so that the heartbeats expire
+// if IndexNode stops reporting them.
+func (t *taskTracker) report(hbKV heartbeatKV, nodeID UniqueID, now time.Time, ttl time.Duration) error {
+	tasks := t.snapshot()
+	if len(tasks) == 0 {
+		return nil
+	}
+	ttlSeconds := int64(ttl / time.Second)
+	if ttlSeconds < 1 {
+		ttlSeconds = 1
+	}
+	leaseID, err := hbKV.Grant(ttlSeconds)
+	if err != nil {
+		return err
+	}
+	for _, progress := range tasks {
+		value, err := json.Marshal(progress.heartbeat(nodeID, now))
+		if err != nil {
+			return err
+		}
+		key := path.Join(TaskHeartbeatPrefix, strconv.FormatInt(progress.indexBuildID, 10))
+		if err := hbKV.SaveWithLease(key, string(value), leaseID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taskHeartbeatLoop reports the heartbeats of the in-progress tasks and detects the stuck ones periodically.
+func (i *IndexNode) taskHeartbeatLoop() {
+	interval := Params.TaskHeartbeatInterval
+	if interval <= 0 || i.etcdKV == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-i.loopCtx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			i.taskTracker.checkStalled(now, Params.TaskStallTimeout)
+			// the heartbeat expires after missing three reports
+			if err := i.taskTracker.report(i.etcdKV, Params.NodeID, now, 3*interval); err != nil {
+				log.Warn("IndexNode failed to report the task heartbeats", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"encoding/json"
+	"errors"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// mockHeartbeatKV records the heartbeats saved with the leases granted.
+type mockHeartbeatKV struct {
+	values   map[string]string
+	leases   map[string]clientv3.LeaseID
+	ttls     []int64
+	grantErr error
+}
+
+func newMockHeartbeatKV() *mockHeartbeatKV {
+	return &mockHeartbeatKV{
+		values: make(map[string]string),
+		leases: make(map[string]clientv3.LeaseID),
+	}
+}
+
+func (kv *mockHeartbeatKV) Grant(ttl int64) (clientv3.LeaseID, error) {
+	if kv.grantErr != nil {
+		return 0, kv.grantErr
+	}
+	kv.ttls = append(kv.ttls, ttl)
+	return clientv3.LeaseID(len(kv.ttls)), nil
+}
+
+func (kv *mockHeartbeatKV) SaveWithLease(key, value string, id clientv3.LeaseID) error {
+	kv.values[key] = value
+	kv.leases[key] = id
+	return nil
+}
+
+func (kv *mockHeartbeatKV) heartbeat(t *testing.T, indexBuildID UniqueID) *TaskHeartbeat {
+	value, ok := kv.values[path.Join(TaskHeartbeatPrefix, strconv.FormatInt(indexBuildID, 10))]
+	assert.True(t, ok)
+	heartbeat := &TaskHeartbeat{}
+	err := json.Unmarshal([]byte(value), heartbeat)
+	assert.Nil(t, err)
+	assert.Equal(t, indexBuildID, heartbeat.IndexBuildID)
+	return heartbeat
+}
+
+func TestTaskProgress(t *testing.T) {
+	var nilProgress *taskProgress
+	nilProgress.setStage(taskStageBuild)
+	nilProgress.advance()
+	assert.Nil(t, nilProgress.stageDurations(time.Now()))
+
+	progress := newTaskProgress(1, 2)
+	heartbeat := progress.heartbeat(3, time.Now())
+	assert.Equal(t, taskStagePreExecute, heartbeat.Stage)
+	assert.Equal(t, int64(2), heartbeat.Version)
+	assert.Equal(t, UniqueID(3), heartbeat.NodeID)
+
+	progress.setStage(taskStageLoad)
+	lastProgress := progress.heartbeat(3, time.Now()).LastProgressTime
+	time.Sleep(time.Millisecond)
+	progress.advance()
+	heartbeat = progress.heartbeat(3, time.Now())
+	assert.Equal(t, taskStageLoad, heartbeat.Stage)
+	assert.True(t, heartbeat.LastProgressTime.After(lastProgress))
+
+	// the stages left and the current one, which is never negative
+	progress.setStage(taskStagePreExecute)
+	durations := progress.stageDurations(time.Now().Add(time.Minute))
+	assert.Equal(t, 2, len(durations))
+	assert.True(t, durations[taskStageLoad] >= time.Millisecond)
+	assert.True(t, durations[taskStagePreExecute] >= time.Minute)
+	durations = progress.stageDurations(time.Now().Add(-time.Hour))
+	assert.True(t, durations[taskStagePreExecute] > 0)
+	assert.True(t, durations[taskStagePreExecute] < time.Minute)
+
+	// the detection is disabled
+	assert.False(t, progress.checkStalled(time.Now().Add(time.Hour), 0))
+	assert.False(t, progress.heartbeat(3, time.Now()).Suspect)
+}
+
+func TestTaskTracker_stalledStage(t *testing.T) {
+	tracker := newTaskTracker()
+	hbKV := newMockHeartbeatKV()
+	// nothing to report
+	err := tracker.report(hbKV, 1, time.Now(), 30*time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(hbKV.ttls))
+
+	progress := newTaskProgress(1, 1)
+	tracker.add(progress)
+	progress.setStage(taskStageBuild)
+	start := progress.heartbeat(1, time.Now()).StageStartTime
+
+	// the build stage never returns, the reports keep coming while the progress stops advancing
+	stallTimeout := 30 * time.Minute
+	var last *TaskHeartbeat
+	for tick := 1; tick <= 3; tick++ {
+		now := start.Add(time.Duration(tick) * 10 * time.Second)
+		tracker.checkStalled(now, stallTimeout)
+		err = tracker.report(hbKV, 1, now, 30*time.Second)
+		assert.Nil(t, err)
+		heartbeat := hbKV.heartbeat(t, 1)
+		assert.Equal(t, taskStageBuild, heartbeat.Stage)
+		assert.Equal(t, now.UnixNano(), heartbeat.ReportTime.UnixNano())
+		if last != nil {
+			assert.True(t, heartbeat.ReportTime.After(last.ReportTime))
+			assert.Equal(t, last.LastProgressTime.UnixNano(), heartbeat.LastProgressTime.UnixNano())
+		}
+		assert.False(t, heartbeat.Suspect)
+		last = heartbeat
+	}
+	assert.Equal(t, []int64{30, 30, 30}, hbKV.ttls)
+	assert.Equal(t, 0, len(tracker.suspectBuildIDs()))
+
+	// the stage exceeds the stall timeout
+	now := start.Add(stallTimeout + time.Second)
+	tracker.checkStalled(now, stallTimeout)
+	assert.Equal(t, []UniqueID{1}, tracker.suspectBuildIDs())
+	err = tracker.report(hbKV, 1, now, 30*time.Second)
+	assert.Nil(t, err)
+	assert.True(t, hbKV.heartbeat(t, 1).Suspect)
+	assert.Equal(t, last.LastProgressTime.UnixNano(), hbKV.heartbeat(t, 1).LastProgressTime.UnixNano())
+
+	// moving to the next stage clears the suspicion
+	progress.setStage(taskStageSerialize)
+	assert.Equal(t, 0, len(tracker.suspectBuildIDs()))
+
+	hbKV.grantErr = errors.New("etcd unavailable")
+	assert.NotNil(t, tracker.report(hbKV, 1, time.Now(), 30*time.Second))
+
+	// a newer version of the build is not removed by the older one
+	newer := newTaskProgress(1, 2)
+	tracker.add(newer)
+	tracker.remove(progress)
+	assert.Equal(t, []*taskProgress{newer}, tracker.snapshot())
+	tracker.remove(newer)
+	assert.Equal(t, 0, len(tracker.snapshot()))
+
+	var nilTracker *taskTracker
+	nilTracker.add(newer)
+	nilTracker.remove(newer)
+}
@@ -31,7 +31,6 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/golang/protobuf/proto"
-	"github.com/milvus-io/milvus/internal/log"
 	"github.com/milvus-io/milvus/internal/proto/commonpb"
 	"github.com/milvus-io/milvus/internal/proto/indexcgopb"
 	"github.com/milvus-io/milvus/internal/storage"
@@ -49,6 +48,33 @@ type Index interface {
 	Delete() error
 }
 
+// IncrementalIndex is implemented by the index which can be fed with the vectors chunk by chunk,
+// the vectors of all the chunks are indexed in the order they are added.
+type IncrementalIndex interface {
+	AddFloatVecIndexWithoutIds(vectors []float32) error
+	AddBinaryVecIndexWithoutIds(vectors []byte) error
+}
+
+// AppendIndex is implemented by the index which can be appended with more vectors after it is built or loaded,
+// the offsets of the vectors appended follow the ones already in the index.
+type AppendIndex interface {
+	AppendFloatVecIndex(vectors []float32) error
+}
+
+// InPlaceIndex is implemented by the index whose index files can be read in place from the memory of the engine
+// instead of being copied out of it.
+type InPlaceIndex interface {
+	// SerializeInPlace returns the index files referring to the memory of the engine, which are valid until they
+	// are released by the returned func
+	SerializeInPlace() ([]*Blob, func(), error)
+}
+
+// StatisticsIndex is implemented by the index exposing the statistics of its build in the engine.
+type StatisticsIndex interface {
+	// Statistics returns the statistics in json, the statistics not exposed by the engine are absent
+	Statistics() (string, error)
+}
+
 // CIndex is a pointer used to access 'CGO'.
 type CIndex struct {
 	indexPtr C.CIndex
@@ -89,6 +115,55 @@ func (index *CIndex) Serialize() ([]*Blob, error) {
 	return ret, nil
 }
 
+// SerializeInPlace serializes the index into the binary set of the engine, the values of the blobs refer to the
+// binary set until they are released.
+func (index *CIndex) SerializeInPlace() ([]*Blob, func(), error) {
+	var cBinarySet C.CBinarySet
+
+	status := C.SerializeToBinarySet(index.indexPtr, &cBinarySet)
+	errorCode := status.error_code
+	if errorCode != 0 {
+		errorMsg := C.GoString(status.error_msg)
+		defer C.free(unsafe.Pointer(status.error_msg))
+		return nil, nil, fmt.Errorf("SerializeToBinarySet failed, C runtime error detected, error code = %d, err msg = %s", errorCode, errorMsg)
+	}
+
+	num := int(C.GetBinarySetFileNum(cBinarySet))
+	ret := make([]*Blob, 0, num)
+	for i := 0; i < num; i++ {
+		var key *C.char
+		var data *C.uint8_t
+		var size C.int64_t
+		C.GetBinarySetFile(cBinarySet, C.int64_t(i), &key, &data, &size)
+		blob := &Blob{Key: C.GoString(key), Value: []byte{}}
+		if size > 0 {
+			blob.Value = (*[1 << 40]byte)(unsafe.Pointer(data))[:size:size]
+		}
+		ret = append(ret, blob)
+	}
+	release := func() {
+		for _, blob := range ret {
+			blob.Value = nil
+		}
+		C.DeleteBinarySet(cBinarySet)
+	}
+	return ret, release, nil
+}
+
+// Statistics returns the statistics of the build reported by the engine in json.
+func (index *CIndex) Statistics() (string, error) {
+	var cStats *C.char
+	status := C.GetIndexStatistics(index.indexPtr, &cStats)
+	errorCode := status.error_code
+	if errorCode != 0 {
+		errorMsg := C.GoString(status.error_msg)
+		defer C.free(unsafe.Pointer(status.error_msg))
+		return "", fmt.Errorf("GetIndexStatistics failed, C runtime error detected, error code = %d, err msg = %s", errorCode, errorMsg)
+	}
+	defer C.free(unsafe.Pointer(cStats))
+	return C.GoString(cStats), nil
+}
+
 // Load loads data from 'C'.
 func (index *CIndex) Load(blobs []*Blob) error {
 	binarySet := &indexcgopb.BinarySet{Datas: make([]*indexcgopb.Binary, 0)}
@@ -121,12 +196,15 @@ func (index *CIndex) BuildFloatVecIndexWithoutIds(vectors []float32) error {
 		CStatus
 		BuildFloatVecIndexWithoutIds(CIndex index, int64_t float_value_num, const float* vectors);
 	*/
-	log.Debug("before BuildFloatVecIndexWithoutIds")
+	if len(vectors) == 0 {
+		return errNoVectors
+	}
+	engineLog.Debug("before BuildFloatVecIndexWithoutIds")
 	status := C.BuildFloatVecIndexWithoutIds(index.indexPtr, (C.int64_t)(len(vectors)), (*C.float)(&vectors[0]))
 	errorCode := status.error_code
 	if errorCode != 0 {
 		errorMsg := C.GoString(status.error_msg)
-		log.Debug("indexnode", zap.String("BuildFloatVecIndexWithoutIds error msg: ", errorMsg))
+		engineLog.Debug("indexnode", zap.String("BuildFloatVecIndexWithoutIds error msg: ", errorMsg))
 		defer C.free(unsafe.Pointer(status.error_msg))
 		return fmt.Errorf("BuildFloatVecIndexWithoutIds failed, C runtime error detected, error code = %d, err msg = %s", errorCode, errorMsg)
 	}
@@ -139,6 +217,9 @@ func (index *CIndex) BuildBinaryVecIndexWithoutIds(vectors []byte) error {
 		CStatus
 		BuildBinaryVecIndexWithoutIds(CIndex index, int64_t data_size, const uint8_t* vectors);
 	*/
+	if len(vectors) == 0 {
+		return errNoVectors
+	}
 	status := C.BuildBinaryVecIndexWithoutIds(index.indexPtr, (C.int64_t)(len(vectors)), (*C.uint8_t)(&vectors[0]))
 	errorCode := status.error_code
 	if errorCode != 0 {
@@ -149,6 +230,63 @@ func (index *CIndex) BuildBinaryVecIndexWithoutIds(vectors []byte) error {
 	return nil
 }
 
+// AddFloatVecIndexWithoutIds adds a chunk of float vectors to the index.
+func (index *CIndex) AddFloatVecIndexWithoutIds(vectors []float32) error {
+	/*
+		CStatus
+		AddFloatVecIndexWithoutIds(CIndex index, int64_t float_value_num, const float* vectors);
+	*/
+	if len(vectors) == 0 {
+		return errNoVectors
+	}
+	status := C.AddFloatVecIndexWithoutIds(index.indexPtr, (C.int64_t)(len(vectors)), (*C.float)(&vectors[0]))
+	errorCode := status.error_code
+	if errorCode != 0 {
+		errorMsg := C.GoString(status.error_msg)
+		defer C.free(unsafe.Pointer(status.error_msg))
+		return fmt.Errorf("AddFloatVecIndexWithoutIds failed, C runtime error detected, error code = %d, err msg = %s", errorCode, errorMsg)
+	}
+	return nil
+}
+
+// AppendFloatVecIndex appends the float vectors to the built or loaded index.
+func (index *CIndex) AppendFloatVecIndex(vectors []float32) error {
+	/*
+		CStatus
+		AppendFloatVecIndex(CIndex index, int64_t float_value_num, const float* vectors);
+	*/
+	if len(vectors) == 0 {
+		return errNoVectors
+	}
+	status := C.AppendFloatVecIndex(index.indexPtr, (C.int64_t)(len(vectors)), (*C.float)(&vectors[0]))
+	errorCode := status.error_code
+	if errorCode != 0 {
+		errorMsg := C.GoString(status.error_msg)
+		defer C.free(unsafe.Pointer(status.error_msg))
+		return fmt.Errorf("AppendFloatVecIndex failed, C runtime error detected, error code = %d, err msg = %s", errorCode, errorMsg)
+	}
+	return nil
+}
+
+// AddBinaryVecIndexWithoutIds adds a chunk of binary vectors to the index.
+func (index *CIndex) AddBinaryVecIndexWithoutIds(vectors []byte) error {
+	/*
+		CStatus
+		AddBinaryVecIndexWithoutIds(CIndex index, int64_t data_size, const uint8_t* vectors);
+	*/
+	if len(vectors) == 0 {
+		return errNoVectors
+	}
+	status := C.AddBinaryVecIndexWithoutIds(index.indexPtr, (C.int64_t)(len(vectors)), (*C.uint8_t)(&vectors[0]))
+	errorCode := status.error_code
+	if errorCode != 0 {
+		errorMsg := C.GoString(status.error_msg)
+		defer C.free(unsafe.Pointer(status.error_msg))
+		return fmt.Errorf("AddBinaryVecIndexWithoutIds failed, C runtime error detected, error code = %d, err msg = %s", errorCode, errorMsg)
+	}
+	return nil
+}
+
 // Delete removes the pointer to build the index in 'C'.
 func (index *CIndex) Delete() error {
 	/*
@@ -191,13 +329,13 @@ func NewCIndex(typeParams, indexParams map[string]string) (Index, error) {
 					CIndex* res_index);
 	*/
 	var indexPtr C.CIndex
-	log.Debug("before create index ...")
+	engineLog.Debug("before create index ...")
 	status := C.CreateIndex(typeParamsPointer, indexParamsPointer, &indexPtr)
-	log.Debug("after create index ...")
+	engineLog.Debug("after create index ...")
 	errorCode := status.error_code
 	if errorCode != 0 {
 		errorMsg := C.GoString(status.error_msg)
-		log.Debug("indexnode", zap.String("create index error msg", errorMsg))
+		engineLog.Debug("indexnode", zap.String("create index error msg", errorMsg))
 		defer C.free(unsafe.Pointer(status.error_msg))
 		return nil, fmt.Errorf(" failed, C runtime error detected, error code = %d, err msg = %s", errorCode, errorMsg)
 	}
@@ -0,0 +1,50 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/encryption"
+)
+
+// encryptBlobs encrypts the serialized index files in place by a new data key of @keyring, and returns the data
+// key. The blobs are replaced by the encrypted ones one by one, so only one of them is copied at a time.
+func encryptBlobs(keyring *encryption.Keyring, blobs []*Blob) (*encryption.DataKey, error) {
+	dataKey, err := keyring.NewDataKey()
+	if err != nil {
+		return nil, err
+	}
+	for _, blob := range blobs {
+		if blob.Value, err = encryption.Encrypt(dataKey.Key, blob.Value); err != nil {
+			return nil, err
+		}
+	}
+	return dataKey, nil
+}
+
+// indexEncryptionOf returns the encryption recorded in the index meta, by which the readers holding the
+// key-encryption key unwrap the data key and decrypt the index files.
+func indexEncryptionOf(dataKey *encryption.DataKey) *indexpb.IndexEncryption {
+	return &indexpb.IndexEncryption{
+		Algorithm:  encryption.Algorithm,
+		KeyId:      dataKey.KeyID,
+		WrappedKey: dataKey.WrappedKey,
+	}
+}
+
+func manifestEncryptionOf(dataKey *encryption.DataKey) *manifestEncryption {
+	return &manifestEncryption{
+		Algorithm:  encryption.Algorithm,
+		KeyID:      dataKey.KeyID,
+		WrappedKey: dataKey.WrappedKey,
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/encryption"
+)
+
+func newTestKEK(t *testing.T, id string) encryption.KEK {
+	key := make([]byte, encryption.KeySize)
+	_, err := rand.Read(key)
+	assert.Nil(t, err)
+	return encryption.KEK{ID: id, Key: key}
+}
+
+func TestEncryptBlobs(t *testing.T) {
+	old, current := newTestKEK(t, "old"), newTestKEK(t, "current")
+	keyring, err := encryption.NewKeyring([]encryption.KEK{old, current})
+	assert.Nil(t, err)
+	plaintexts := [][]byte{[]byte("the ivf data"), bytes.Repeat([]byte("params"), encryption.SegmentSize)}
+	blobs := []*Blob{{Key: "IVF", Value: plaintexts[0]}, {Key: "indexParams", Value: plaintexts[1]}}
+	dataKey, err := encryptBlobs(keyring, blobs)
+	assert.Nil(t, err)
+
+	// the readers decrypt the index files with the encryption recorded in the index meta
+	meta := indexEncryptionOf(dataKey)
+	assert.Equal(t, encryption.Algorithm, meta.Algorithm)
+	assert.Equal(t, "current", meta.KeyId)
+	// the key-encryption keys are rotated, the old ones still decrypt
+	reader, err := encryption.NewKeyring([]encryption.KEK{current, newTestKEK(t, "newer")})
+	assert.Nil(t, err)
+	key, err := reader.UnwrapDataKey(meta.KeyId, meta.WrappedKey)
+	assert.Nil(t, err)
+	for idx, blob := range blobs {
+		assert.False(t, bytes.Contains(blob.Value, plaintexts[idx][:12]))
+		plaintext, err := encryption.Decrypt(key, blob.Value)
+		assert.Nil(t, err)
+		assert.Equal(t, plaintexts[idx], plaintext)
+	}
+
+	// the manifest records the encryption, and verifies the encrypted files
+	storage := memkv.NewMemoryKV()
+	manifest := newIndexManifest(&indexpb.CreateIndexRequest{IndexBuildID: 1, Version: 1}, nil, nil, nil)
+	manifest.Encryption = manifestEncryptionOf(dataKey)
+	paths := make([]string, 0, len(blobs))
+	for _, blob := range blobs {
+		paths = append(paths, path.Join(manifestTestPrefix, blob.Key))
+		assert.Nil(t, storage.Save(paths[len(paths)-1], string(blob.Value)))
+		manifest.addFile(blob.Key, int64(len(blob.Value)), checksumOf(blob.Value))
+	}
+	value, err := manifest.marshal()
+	assert.Nil(t, err)
+	manifestPath := path.Join(manifestTestPrefix, manifestFileName)
+	assert.Nil(t, storage.Save(manifestPath, value))
+	parsed, mismatches, err := verifyManifest(storage, manifestPath, paths)
+	assert.Nil(t, err)
+	assert.Empty(t, mismatches)
+	assert.Equal(t, manifest.Encryption, parsed.Encryption)
+	fields := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal([]byte(value), &fields))
+	assert.Equal(t, "current", fields["encryption"].(map[string]interface{})["key_id"])
+
+	// the manifest of the plaintext files has no encryption
+	value, err = newIndexManifest(&indexpb.CreateIndexRequest{}, nil, nil, nil).marshal()
+	assert.Nil(t, err)
+	assert.NotContains(t, value, "encryption")
+}
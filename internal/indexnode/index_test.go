@@ -308,6 +308,104 @@ func TestCIndex_Codec(t *testing.T) {
 	}
 }
 
+func TestCIndex_buildSeed(t *testing.T) {
+	cases := []testCase{
+		{IndexFaissIVFFlat, L2, false},
+		{IndexFaissIVFPQ, L2, false},
+		{IndexFaissIVFSQ8, L2, false},
+		{IndexHNSW, L2, false},
+		{IndexFaissBinIVFFlat, Jaccard, true},
+	}
+	floatVectors := generateFloatVectors()
+	binaryVectors := generateBinaryVectors()
+	build := func(c testCase, seed string) map[string]string {
+		typeParams, indexParams := generateParams(c.indexType, c.metricType)
+		indexParams[buildSeedKey] = seed
+		index, err := NewCIndex(typeParams, indexParams)
+		assert.Nil(t, err)
+		defer index.Delete()
+		if c.isBinary {
+			err = index.BuildBinaryVecIndexWithoutIds(binaryVectors)
+		} else {
+			err = index.BuildFloatVecIndexWithoutIds(floatVectors)
+		}
+		assert.Nil(t, err)
+		blobs, err := index.Serialize()
+		assert.Nil(t, err)
+		checksums := make(map[string]string, len(blobs))
+		for _, blob := range blobs {
+			checksums[blob.Key] = checksumOf(blob.Value)
+		}
+		return checksums
+	}
+	for _, c := range cases {
+		t.Run(c.indexType, func(t *testing.T) {
+			checksums := build(c, "7")
+			assert.Equal(t, checksums, build(c, "7"))
+			if c.indexType != IndexHNSW {
+				// the levels of HNSW drawn by another seed may happen to be the same on the small dataset
+				assert.NotEqual(t, checksums, build(c, "8"))
+			}
+		})
+	}
+}
+
+func TestCIndex_append(t *testing.T) {
+	floatVectors := generateFloatVectors()
+	half := len(floatVectors) / dim / 2 * dim
+	build := func(indexType string) *CIndex {
+		typeParams, indexParams := generateParams(indexType, L2)
+		index, err := NewCIndex(typeParams, indexParams)
+		assert.Nil(t, err)
+		assert.Nil(t, index.BuildFloatVecIndexWithoutIds(floatVectors[:half]))
+		blobs, err := index.Serialize()
+		assert.Nil(t, err)
+		assert.Nil(t, index.Delete())
+
+		loaded, err := NewCIndex(typeParams, indexParams)
+		assert.Nil(t, err)
+		assert.Nil(t, loaded.Load(blobs))
+		return loaded.(*CIndex)
+	}
+
+	index := build(IndexHNSW)
+	defer index.Delete()
+	assert.Nil(t, index.AppendFloatVecIndex(floatVectors[half:]))
+	_, err := index.Serialize()
+	assert.Nil(t, err)
+
+	index = build(IndexFaissIVFFlat)
+	defer index.Delete()
+	assert.NotNil(t, index.AppendFloatVecIndex(floatVectors[half:]))
+}
+
+func TestCIndex_SerializeInPlace(t *testing.T) {
+	for _, c := range generateTestCases() {
+		typeParams, indexParams := generateParams(c.indexType, c.metricType)
+		index, err := NewCIndex(typeParams, indexParams)
+		assert.Nil(t, err)
+		if c.isBinary {
+			err = index.BuildBinaryVecIndexWithoutIds(generateBinaryVectors())
+		} else {
+			err = index.BuildFloatVecIndexWithoutIds(generateFloatVectors())
+		}
+		assert.Nil(t, err)
+
+		blobs, err := index.Serialize()
+		assert.Nil(t, err)
+		inPlace, release, err := index.(InPlaceIndex).SerializeInPlace()
+		assert.Nil(t, err)
+		assert.Equal(t, len(blobs), len(inPlace), c.indexType)
+		for i, blob := range blobs {
+			assert.Equal(t, blob.Key, inPlace[i].Key)
+			assert.Equal(t, blob.Value, inPlace[i].Value, c.indexType)
+		}
+		release()
+		assert.Nil(t, inPlace[0].Value)
+		assert.Nil(t, index.Delete())
+	}
+}
+
 func TestCIndex_Delete(t *testing.T) {
 	for _, c := range generateTestCases() {
 		typeParams, indexParams := generateParams(c.indexType, c.metricType)
@@ -27,12 +27,15 @@ import (
 	"errors"
 	"io"
 	"math/rand"
+	"os"
 	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 	"unsafe"
 
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/funcutil"
 	"github.com/milvus-io/milvus/internal/util/metricsinfo"
 
 	"go.uber.org/zap"
@@ -45,6 +48,8 @@ import (
 	"github.com/milvus-io/milvus/internal/proto/indexpb"
 	"github.com/milvus-io/milvus/internal/proto/internalpb"
 	"github.com/milvus-io/milvus/internal/proto/milvuspb"
+	"github.com/milvus-io/milvus/internal/util/ratelimit"
+	"github.com/milvus-io/milvus/internal/util/requestid"
 	"github.com/milvus-io/milvus/internal/util/retry"
 	"github.com/milvus-io/milvus/internal/util/sessionutil"
 	"github.com/milvus-io/milvus/internal/util/trace"
@@ -54,6 +59,9 @@ import (
 // UniqueID is an alias of int64, is used as a unique identifier for the request.
 type UniqueID = typeutil.UniqueID
 
+// deregisterTimeout time-boxes the deregistration on shutdown, so that an unreachable etcd does not block it
+const deregisterTimeout = 3 * time.Second
+
 // IndexNode is a component that executes the task of building indexes.
 type IndexNode struct {
 	stateCode atomic.Value
@@ -73,12 +81,47 @@ type IndexNode struct {
 	startCallbacks []func()
 	closeCallbacks []func()
 
-	etcdKV        *etcdkv.EtcdKV
+	etcdKV        *metricsEtcdKV
 	finishedTasks map[UniqueID]commonpb.IndexState
 
 	closer io.Closer
 
 	initOnce sync.Once
+
+	probe     *readinessProbe
+	taskStats *taskStatistics
+	simd      *simdSwitcher
+	rebuilder *rebuilder
+	// dependencies caches the states of the dependencies whose checks are slow, see refreshDependencies
+	dependencies dependencyCache
+	// probeKey is the unique suffix of the key of the storage check object of the node, see probeObjectKey
+	probeKeyOnce sync.Once
+	probeKey     string
+	// taskTracker tracks the stages of the in-progress tasks for the heartbeats
+	taskTracker *taskTracker
+	// admission pauses the admission of new tasks when the memory or the disk runs low
+	admission *admissionGuard
+	// capabilities are the capability tags advertised by the node, which the build requests are validated against
+	capabilities []string
+	// phase is the startupPhase of the node
+	phase atomic.Value
+	// standby is true if the node is a warm spare rejecting the build requests, see Activate
+	modeMu  sync.RWMutex
+	standby bool
+	// sessionMu serializes the updates of the registration of the session
+	sessionMu sync.Mutex
+	// resolveIP resolves the IP advertised by the node, see refreshAddress
+	resolveIP func() string
+	// registry is the *prometheus.Registry of the metrics of the node, created once the NodeID is assigned
+	registry atomic.Value
+	// taskEvents records the lifecycle events of the tasks, nil if indexNode.taskEvents.sink is none
+	taskEvents *taskEventLog
+	// rateLimiter limits the rates of the build submissions and the queries, see RateLimiter
+	rateLimiter *ratelimit.Limiter
+	// selfTest is the *metricsinfo.IndexNodeSelfTest of the self-test at startup, see runSelfTest
+	selfTest atomic.Value
+	// benchmarking is 1 while a build benchmark is running, see RunBuildBenchmark
+	benchmarking int32
 }
 
 // NewIndexNode creates a new IndexNode component.
@@ -87,10 +130,16 @@ func NewIndexNode(ctx context.Context) (*IndexNode, error) {
 	rand.Seed(time.Now().UnixNano())
 	ctx1, cancel := context.WithCancel(ctx)
 	b := &IndexNode{
-		loopCtx:    ctx1,
-		loopCancel: cancel,
+		loopCtx:     ctx1,
+		loopCancel:  cancel,
+		probe:       newReadinessProbe(),
+		taskStats:   newTaskStatistics(),
+		taskTracker: newTaskTracker(),
+		resolveIP:   resolveLocalIP,
+		rateLimiter: newRateLimiter(),
 	}
 	b.UpdateStateCode(internalpb.StateCode_Abnormal)
+	b.setStartupPhase(startupPhaseParams)
 	sc, err := NewTaskScheduler(b.loopCtx, b.kv)
 	if err != nil {
 		return nil, err
@@ -102,25 +151,65 @@ func NewIndexNode(ctx context.Context) (*IndexNode, error) {
 
 // Register register index node at etcd.
 func (i *IndexNode) Register() error {
+	return i.runStartupPhase(startupPhaseSession, Params.StartupSessionTimeout, i.register)
+}
+
+func (i *IndexNode) register() error {
 	i.session = sessionutil.NewSession(i.loopCtx, Params.MetaRootPath, Params.EtcdEndpoints)
 	if i.session == nil {
 		return errors.New("failed to initialize session")
 	}
-	i.liveCh = i.session.Init(typeutil.IndexNodeRole, Params.IP+":"+strconv.Itoa(Params.Port), false)
+	i.session.Alias = Params.Alias
+	i.session.Version = os.Getenv(metricsinfo.GitCommitEnvKey)
+	i.session.CreatedTime = time.Now().UTC().Format(time.RFC3339)
+	i.session.Labels = Params.Labels
+	i.capabilities = nodeCapabilities(metricsinfo.GetMemoryCount())
+	i.session.Capabilities = i.capabilities
+	i.modeMu.Lock()
+	i.standby = Params.Standby
+	i.session.Standby = Params.Standby
+	i.modeMu.Unlock()
+	if Params.PreferredCIDR != nil && !funcutil.IsUnixSocketAddress(Params.Address) {
+		Params.IP = i.resolveIP()
+		Params.Address = Params.IP + ":" + strconv.Itoa(Params.Port)
+	}
+	address := advertisedAddress()
+	if Params.PersistNodeID {
+		if err := i.registerWithPersistedNodeID(address); err != nil {
+			return err
+		}
+	} else {
+		i.liveCh = i.session.Init(typeutil.IndexNodeRole, address, false)
+	}
 	Params.NodeID = i.session.ServerID
+	Params.Log.Fields = logFields()
 	Params.SetLogger(Params.NodeID)
+	i.registry.Store(i.newMetricsRegistry())
+	i.probe.update(probeEtcdSession, nil)
+	i.refreshDependencies(i.loopCtx, i.sessionLease())
 	return nil
 }
 
 func (i *IndexNode) initKnowhere() {
 	C.IndexBuilderInit()
 
-	// override index builder SIMD type
-	cSimdType := C.CString(Params.SimdType)
+	// override index builder SIMD type, the instruction set reported by the engine is the effective one
+	setSimdType := func(simdType string) string {
+		return effectiveSimdType(simdType, setIndexBuilderSimdType(simdType), detectCPUFeatures())
+	}
+	Params.EffectiveSimdType = setSimdType(Params.SimdType)
+	i.simd = newSimdSwitcher(Params.SimdType, Params.EffectiveSimdType, setSimdType)
+	recordEffectiveSimdType()
+}
+
+// setIndexBuilderSimdType sets the SIMD type of index builder and returns the instruction set it resolves to.
+func setIndexBuilderSimdType(simdType string) string {
+	cSimdType := C.CString(simdType)
 	cRealSimdType := C.IndexBuilderSetSimdType(cSimdType)
-	Params.SimdType = C.GoString(cRealSimdType)
+	realSimdType := C.GoString(cRealSimdType)
 	C.free(unsafe.Pointer(cRealSimdType))
 	C.free(unsafe.Pointer(cSimdType))
+	return realSimdType
 }
 
 // Init initializes the IndexNode component.
@@ -128,42 +217,38 @@ func (i *IndexNode) Init() error {
 	var initErr error = nil
 	i.initOnce.Do(func() {
 		Params.Init()
+		Params.CreatedTime = time.Now()
+		Params.UpdatedTime = Params.CreatedTime
+		configLogSampling()
+		if Params.simdTypeErr != nil {
+			initErr = Params.simdTypeErr
+			log.Error("IndexNode init failed", zap.Error(initErr))
+			return
+		}
+		// the queue is created before the configuration is loaded, rebuild it with the configured capacity
+		i.sched.IndexBuildQueue = NewIndexBuildTaskQueue(i.sched)
 		i.UpdateStateCode(internalpb.StateCode_Initializing)
 		log.Debug("IndexNode init", zap.Any("State", internalpb.StateCode_Initializing))
-		connectEtcdFn := func() error {
-			etcdKV, err := etcdkv.NewEtcdKV(Params.EtcdEndpoints, Params.MetaRootPath)
-			i.etcdKV = etcdKV
-			return err
+		if initErr = i.runStartupPhase(startupPhaseEtcd, Params.StartupEtcdTimeout, i.connectEtcd); initErr != nil {
+			return
 		}
-		err := retry.Do(i.loopCtx, connectEtcdFn, retry.Attempts(300))
-		if err != nil {
-			log.Error("IndexNode try connect etcd failed", zap.Error(err))
-			initErr = err
+		if initErr = i.runStartupPhase(startupPhaseStorage, Params.StartupStorageTimeout, i.initStorage); initErr != nil {
 			return
 		}
-		log.Debug("IndexNode connect to etcd successfully")
-
-		option := &miniokv.Option{
-			Address:           Params.MinIOAddress,
-			AccessKeyID:       Params.MinIOAccessKeyID,
-			SecretAccessKeyID: Params.MinIOSecretAccessKey,
-			UseSSL:            Params.MinIOUseSSL,
-			BucketName:        Params.MinioBucketName,
-			CreateBucket:      true,
+		if err := os.MkdirAll(Params.ScratchPath, os.ModePerm); err != nil {
+			log.Warn("IndexNode failed to create the scratch path", zap.String("path", Params.ScratchPath), zap.Error(err))
 		}
-		kv, err := miniokv.NewMinIOKV(i.loopCtx, option)
-		if err != nil {
-			log.Error("IndexNode NewMinIOKV failed", zap.Error(err))
-			initErr = err
-			return
+		retryPendingCleanups(i.kv)
+		cleanDiskIndexDirs()
+		if Params.TracingEnabled {
+			i.closer = trace.InitTracingWithSampler("index_node", Params.TracingEndpoint, Params.TracingSamplingRatio)
 		}
-
-		i.kv = kv
-
-		log.Debug("IndexNode NewMinIOKV successfully")
-		i.closer = trace.InitTracing("index_node")
+		i.initTaskEvents()
 
 		i.initKnowhere()
+		if Params.SelfTestEnabled {
+			i.runSelfTest(newSelfTest(i.kv, Params.SelfTestIndexType, i.simd))
+		}
 	})
 
 	log.Debug("Init IndexNode finished", zap.Error(initErr))
@@ -171,20 +256,110 @@ func (i *IndexNode) Init() error {
 	return initErr
 }
 
+// connectEtcd connects to etcd, retrying within the budget of the etcd startup phase.
+func (i *IndexNode) connectEtcd() error {
+	connectEtcdFn := func() error {
+		etcdKV, err := etcdkv.NewEtcdKV(Params.EtcdEndpoints, Params.MetaRootPath)
+		if err != nil {
+			return err
+		}
+		i.etcdKV = newMetricsEtcdKV(etcdKV)
+		return nil
+	}
+	err := retry.Do(i.loopCtx, connectEtcdFn, retry.Attempts(300))
+	if err != nil {
+		log.Error("IndexNode try connect etcd failed", zap.Error(err))
+		return err
+	}
+	log.Debug("IndexNode connect to etcd successfully")
+	return nil
+}
+
+// initTaskEvents opens the log of the task events, a failure to open it disables the events instead of failing the
+// startup.
+func (i *IndexNode) initTaskEvents() {
+	var etcdKV heartbeatKV
+	if i.etcdKV != nil {
+		etcdKV = i.etcdKV
+	}
+	taskEvents, err := newTaskEventLog(etcdKV)
+	if err != nil {
+		log.Warn("IndexNode failed to open the task events, the events are not recorded",
+			zap.String("sink", Params.TaskEventSink), zap.Error(err))
+		return
+	}
+	i.taskEvents = taskEvents
+}
+
+// initStorage connects to the object storage and checks it, the failed check is reported by the readiness probe
+// instead of failing the startup, since the storage check loop keeps checking it.
+func (i *IndexNode) initStorage() error {
+	option := &miniokv.Option{
+		Address:           Params.MinIOAddress,
+		AccessKeyID:       Params.MinIOAccessKeyID,
+		SecretAccessKeyID: Params.MinIOSecretAccessKey,
+		UseSSL:            Params.MinIOUseSSL,
+		BucketName:        Params.MinioBucketName,
+		CreateBucket:      true,
+	}
+	kv, err := miniokv.NewMinIOKV(i.loopCtx, option)
+	if err != nil {
+		log.Error("IndexNode NewMinIOKV failed", zap.Error(err))
+		return err
+	}
+
+	i.kv = kv
+
+	log.Debug("IndexNode NewMinIOKV successfully")
+	if err := i.checkStorage(); err != nil {
+		log.Warn("IndexNode storage check failed", zap.Error(err))
+		i.probe.update(probeStorage, err)
+	} else {
+		i.probe.update(probeStorage, nil)
+	}
+	return nil
+}
+
 // Start starts the IndexNode component.
 func (i *IndexNode) Start() error {
 	var startErr error = nil
 	i.once.Do(func() {
 		startErr = i.sched.Start()
 
-		Params.CreatedTime = time.Now()
-		Params.UpdatedTime = time.Now()
-
 		//start liveness check
-		go i.session.LivenessCheck(i.loopCtx, i.liveCh, func() {
-			i.Stop()
-		})
+		go i.livenessCheckLoop(i.liveCh)
+		go i.storageCheckLoop()
+		// the states of the dependencies are cached before the node serves GetComponentStates
+		i.refreshDependencies(i.loopCtx, i.sessionLease())
+		go i.dependencyCheckLoop()
+		go i.taskHeartbeatLoop()
+		go i.logSamplingLoop()
+		i.admission = newAdmissionGuard(watermarksFromParams(), nodeMemoryUsage, scratchFreeSpace)
+		i.admission.check()
+		go i.watermarkCheckLoop()
+		if Params.IPRefreshEnabled {
+			go i.addressRefreshLoop(Params.IPRefreshInterval)
+		}
 
+		if err := i.rateLimiter.SetLimits(Params.RateLimits); err != nil {
+			log.Warn("IndexNode failed to set the rate limits", zap.Error(err))
+		}
+		if i.etcdKV != nil {
+			dynamicConfig := newDynamicConfig(i.etcdKV)
+			i.registerDynamicConfigs(dynamicConfig)
+			if err := dynamicConfig.start(i.loopCtx); err != nil {
+				log.Warn("IndexNode failed to load the dynamic configurations", zap.Error(err))
+			}
+		}
+
+		if Params.AutoRebuildIncompatible {
+			i.rebuilder = newRebuilder(i.etcdKV, Params.NodeID, Params.AutoRebuildConcurrency, i.isIdle, i.rebuildIndex)
+			if err := i.rebuilder.start(i.loopCtx); err != nil {
+				log.Warn("IndexNode failed to start rebuilding incompatible indexes", zap.Error(err))
+			}
+		}
+
+		i.setStartupPhase(startupPhaseServing)
 		i.UpdateStateCode(internalpb.StateCode_Healthy)
 		log.Debug("IndexNode", zap.Any("State", i.stateCode.Load()))
 	})
@@ -199,10 +374,20 @@ func (i *IndexNode) Start() error {
 
 // Stop closes the server.
 func (i *IndexNode) Stop() error {
-	i.loopCancel()
+	i.UpdateStateCode(internalpb.StateCode_Abnormal)
+	// the tasks accepted are finished before cancelling the loop context the storage and the heartbeats run on
 	if i.sched != nil {
+		if !i.sched.Drain(Params.GracefulStopTimeout) {
+			log.Warn("IndexNode stops before the tasks are drained", zap.Int64("NodeID", Params.NodeID),
+				zap.Int("unissued", i.sched.IndexBuildQueue.utLen()), zap.Duration("timeout", Params.GracefulStopTimeout))
+		}
 		i.sched.Close()
 	}
+	i.loopCancel()
+	i.taskEvents.close()
+	if i.session != nil {
+		i.deregister()
+	}
 	for _, cb := range i.closeCallbacks {
 		cb()
 	}
@@ -210,26 +395,144 @@ func (i *IndexNode) Stop() error {
 	return nil
 }
 
+// deregister deletes the session key after the tasks are drained, so that the coordinator stops assigning
+// tasks to the node at once instead of after the lease expires.
+func (i *IndexNode) deregister() {
+	if err := i.session.Revoke(deregisterTimeout); err != nil {
+		log.Warn("IndexNode failed to deregister from etcd, the session expires with the lease",
+			zap.Int64("NodeID", Params.NodeID), zap.Error(err))
+		return
+	}
+	log.Debug("IndexNode deregistered from etcd", zap.Int64("NodeID", Params.NodeID))
+}
+
 // UpdateStateCode updates the component state of IndexNode.
 func (i *IndexNode) UpdateStateCode(code internalpb.StateCode) {
 	i.stateCode.Store(code)
 }
 
-func (i *IndexNode) isHealthy() bool {
+// stateCodeWithProbe returns the state code agreed with the readiness probe,
+// IndexNode is not healthy as long as any readiness check fails.
+func (i *IndexNode) stateCodeWithProbe() internalpb.StateCode {
 	code := i.stateCode.Load().(internalpb.StateCode)
-	return code == internalpb.StateCode_Healthy
+	if code == internalpb.StateCode_Healthy && i.probe != nil && !i.probe.passed() {
+		return internalpb.StateCode_Abnormal
+	}
+	return code
+}
+
+// isIdle returns whether no task is waiting and new tasks are admitted, the rebuilds only run when idle.
+func (i *IndexNode) isIdle() bool {
+	admissible, _ := i.admission.admissible()
+	return admissible && i.sched.IndexBuildQueue.utEmpty()
+}
+
+func (i *IndexNode) isHealthy() bool {
+	return i.stateCodeWithProbe() == internalpb.StateCode_Healthy
+}
+
+func (i *IndexNode) newIndexBuildTask(ctx context.Context, request *indexpb.CreateIndexRequest) *IndexBuildTask {
+	return &IndexBuildTask{
+		BaseTask: BaseTask{
+			ctx:  ctx,
+			done: make(chan error),
+		},
+		req:     request,
+		kv:      i.kv,
+		etcdKV:  i.etcdKV,
+		nodeID:  Params.NodeID,
+		stats:   i.taskStats,
+		simd:    i.simd,
+		tracker: i.taskTracker,
+		events:  i.taskEvents,
+	}
+}
+
+// rebuildIndex builds the index of the request in the low-priority class of the task queue, and waits for it to
+// finish, so that the rebuilds share the build parallelism with the other tasks and never run ahead of them.
+func (i *IndexNode) rebuildIndex(ctx context.Context, request *indexpb.CreateIndexRequest) error {
+	t := i.newIndexBuildTask(ctx, request)
+	t.done = make(chan error, 1)
+	t.lowPriority = true
+	if err := i.sched.IndexBuildQueue.EnqueueLowPriority(t); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		// the rebuild popped already finishes aside, the one still queued is never built
+		if i.sched.IndexBuildQueue.removeLowPriorityTask(t) {
+			log.Debug("IndexNode cancels the queued rebuild", zap.Int64("indexBuildID", request.IndexBuildID))
+		}
+		return ctx.Err()
+	case err := <-t.done:
+		if err != nil {
+			return err
+		}
+	}
+	return t.finalErr
+}
+
+// RebuildIncompatibleIndexes starts to rebuild the indexes built by this node which are too old for the current
+// index engine, it is only available if indexNode.autoRebuildIncompatible is enabled. The rebuilds run in background,
+// the progress of which is returned by GetMetrics of metricsinfo.IndexRebuildProgressMetrics.
+func (i *IndexNode) RebuildIncompatibleIndexes(ctx context.Context, req *indexpb.RebuildIncompatibleIndexesRequest) (*commonpb.Status, error) {
+	if !i.isHealthy() {
+		return i.notReadyStatus(), nil
+	}
+	if err := i.startRebuild(); err != nil {
+		log.Warn("IndexNode failed to start rebuilding incompatible indexes", zap.Error(err))
+		return &commonpb.Status{
+			ErrorCode: commonpb.ErrorCode_UnexpectedError,
+			Reason:    failureReason(err),
+		}, nil
+	}
+	return &commonpb.Status{ErrorCode: commonpb.ErrorCode_Success}, nil
+}
+
+// startRebuild starts to rebuild the incompatible indexes, unless a rebuild is running or it is disabled.
+func (i *IndexNode) startRebuild() error {
+	if !Params.AutoRebuildIncompatible || i.rebuilder == nil {
+		return errRebuildDisabled
+	}
+	return i.rebuilder.start(i.loopCtx)
+}
+
+// GetRebuildProgress returns the progress of rebuilding the incompatible indexes.
+func (i *IndexNode) GetRebuildProgress() (metricsinfo.IndexRebuildProgress, error) {
+	if !Params.AutoRebuildIncompatible || i.rebuilder == nil {
+		return metricsinfo.IndexRebuildProgress{}, errRebuildDisabled
+	}
+	return i.rebuilder.getProgress(), nil
 }
 
 // CreateIndex receives request from IndexCoordinator to build an index.
 // Index building is asynchronous, so when an index building request comes, IndexNode records the task and returns.
 func (i *IndexNode) CreateIndex(ctx context.Context, request *indexpb.CreateIndexRequest) (*commonpb.Status, error) {
-	if i.stateCode.Load().(internalpb.StateCode) != internalpb.StateCode_Healthy {
+	if request.DryRun {
+		return i.createIndex(ctx, request)
+	}
+	i.taskEvents.record(request.IndexBuildID, request.Version, TaskEventReceived)
+	ret, err := i.createIndex(ctx, request)
+	if err == nil && ret.ErrorCode != commonpb.ErrorCode_Success {
+		i.taskEvents.record(request.IndexBuildID, request.Version, TaskEventRejected, withReason(ret.Reason))
+	}
+	return ret, err
+}
+
+// createIndex validates the request and enqueues the task, or runs the dry run of it.
+func (i *IndexNode) createIndex(ctx context.Context, request *indexpb.CreateIndexRequest) (*commonpb.Status, error) {
+	if !i.isHealthy() {
+		return i.notReadyStatus(), nil
+	}
+	logger := requestid.Logger(ctx, log.L())
+	if i.isStandby() {
+		logger.Warn("IndexNode is standby, reject the task", zap.Int64("indexBuildID", request.IndexBuildID))
 		return &commonpb.Status{
 			ErrorCode: commonpb.ErrorCode_UnexpectedError,
-			Reason:    "state code is not healthy",
+			Reason:    msgIndexNodeIsStandby(Params.NodeID),
 		}, nil
 	}
-	log.Info("IndexNode building index ...",
+	logger.Info("IndexNode building index ...",
 		zap.Int64("IndexBuildID", request.IndexBuildID),
 		zap.String("IndexName", request.IndexName),
 		zap.Int64("IndexID", request.IndexID),
@@ -243,29 +546,72 @@ func (i *IndexNode) CreateIndex(ctx context.Context, request *indexpb.CreateInde
 	defer sp.Finish()
 	sp.SetTag("IndexBuildID", strconv.FormatInt(request.IndexBuildID, 10))
 
-	t := &IndexBuildTask{
-		BaseTask: BaseTask{
-			ctx:  ctx,
-			done: make(chan error),
-		},
-		req:    request,
-		kv:     i.kv,
-		etcdKV: i.etcdKV,
-		nodeID: Params.NodeID,
-	}
-
 	ret := &commonpb.Status{
 		ErrorCode: commonpb.ErrorCode_Success,
 	}
+	if missing := missingCapabilities(request.RequiredCapabilities, i.capabilities); len(missing) > 0 {
+		logger.Warn("IndexNode capability mismatch, reject the task", zap.Int64("indexBuildID", request.IndexBuildID),
+			zap.Strings("required", request.RequiredCapabilities), zap.Strings("capabilities", i.capabilities))
+		ret.ErrorCode = commonpb.ErrorCode_UnexpectedError
+		ret.Reason = msgCapabilityMismatch(Params.NodeID, missing)
+		return ret, nil
+	}
+	if request.DryRun {
+		// the dry run is not enqueued, nothing is built
+		if resp := i.dryRunCreateIndex(request); !resp.Passed {
+			ret.ErrorCode = commonpb.ErrorCode_UnexpectedError
+			ret.Reason = errorcode.Format(dryRunFailureCode(resp), "dry run failed, "+failedChecks(resp))
+		}
+		return ret, nil
+	}
+	if err := checkMetricCompatibility(request); err != nil {
+		logger.Warn("IndexNode rejects the task of the incompatible metric type",
+			zap.Int64("indexBuildID", request.IndexBuildID), zap.Error(err))
+		ret.ErrorCode = commonpb.ErrorCode_UnexpectedError
+		ret.Reason = failureReason(err)
+		return ret, nil
+	}
+	if err := checkAppendBuild(request); err != nil {
+		logger.Warn("IndexNode rejects the task appending to the index of the base build",
+			zap.Int64("indexBuildID", request.IndexBuildID), zap.Int64("baseIndexBuildID", request.BaseIndexBuildID),
+			zap.Error(err))
+		ret.ErrorCode = commonpb.ErrorCode_UnexpectedError
+		ret.Reason = failureReason(err)
+		return ret, nil
+	}
+	if admissible, reason := i.admission.admissible(); !admissible {
+		logger.Warn("IndexNode is busy, reject the task", zap.Int64("indexBuildID", request.IndexBuildID),
+			zap.String("reason", reason))
+		ret.ErrorCode = commonpb.ErrorCode_NodeBusy
+		ret.Reason = msgIndexNodeIsPaused(Params.NodeID, reason)
+		return ret, nil
+	}
+
+	t := i.newIndexBuildTask(ctx, request)
 
 	err := i.sched.IndexBuildQueue.Enqueue(t)
+	if errors.Is(err, errTaskQueueFull) {
+		queueDepth := i.sched.IndexBuildQueue.utLen()
+		var averageBuildTime time.Duration
+		if i.taskStats != nil {
+			averageBuildTime = i.taskStats.averageBuildTime()
+		}
+		estimatedWait := i.sched.estimateWaitTime(averageBuildTime)
+		logger.Warn("IndexNode is busy, reject the task", zap.Int64("indexBuildID", request.IndexBuildID),
+			zap.Int("queueDepth", queueDepth), zap.Duration("estimatedWait", estimatedWait))
+		ret.ErrorCode = commonpb.ErrorCode_NodeBusy
+		ret.Reason = msgIndexNodeIsBusy(Params.NodeID, queueDepth, estimatedWait)
+		return ret, nil
+	}
 	if err != nil {
-		log.Warn("IndexNode failed to schedule", zap.Int64("indexBuildID", request.IndexBuildID), zap.Error(err))
+		logger.Warn("IndexNode failed to schedule", zap.Int64("indexBuildID", request.IndexBuildID), zap.Error(err))
 		ret.ErrorCode = commonpb.ErrorCode_UnexpectedError
-		ret.Reason = err.Error()
+		ret.Reason = failureReason(err)
 		return ret, nil
 	}
-	log.Info("IndexNode successfully schedule", zap.Int64("indexBuildID", request.IndexBuildID))
+	logger.Info("IndexNode successfully schedule", zap.Int64("indexBuildID", request.IndexBuildID))
+	// the task may have been admitted already, the event is of the time it is enqueued
+	t.recordEvent(TaskEventQueued, withTime(t.enqueueTime))
 
 	return ret, nil
 }
@@ -273,15 +619,27 @@ func (i *IndexNode) CreateIndex(ctx context.Context, request *indexpb.CreateInde
 // GetComponentStates gets the component states of IndexNode.
 func (i *IndexNode) GetComponentStates(ctx context.Context) (*internalpb.ComponentStates, error) {
 	log.Debug("get IndexNode components states ...")
+	dependencies := i.checkDependencies(i.sessionLease())
+	subcomponentStates := make([]*internalpb.ComponentInfo, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		subcomponentStates = append(subcomponentStates, dependency.componentInfo())
+	}
+	stateCode, extraInfo := stateCodeWithDependencies(i.stateCodeWithProbe(), dependencies)
+	if phase := i.startupPhase(); phase != startupPhaseServing {
+		extraInfo = append(extraInfo, &commonpb.KeyValuePair{Key: startupPhaseKey, Value: string(phase)})
+	}
+	extraInfo = append(extraInfo, &commonpb.KeyValuePair{Key: modeKey, Value: i.mode()})
+	extraInfo = append(extraInfo, lifetimeInfos()...)
 	stateInfo := &internalpb.ComponentInfo{
 		NodeID:    Params.NodeID,
 		Role:      "NodeImpl",
-		StateCode: i.stateCode.Load().(internalpb.StateCode),
+		StateCode: stateCode,
+		ExtraInfo: extraInfo,
 	}
 
 	ret := &internalpb.ComponentStates{
 		State:              stateInfo,
-		SubcomponentStates: nil, // todo add subcomponents states
+		SubcomponentStates: subcomponentStates,
 		Status: &commonpb.Status{
 			ErrorCode: commonpb.ErrorCode_Success,
 		},
@@ -315,6 +673,12 @@ func (i *IndexNode) GetStatisticsChannel(ctx context.Context) (*milvuspb.StringR
 	}, nil
 }
 
+// CheckIndexCompatibility checks whether the indexes of the builds can be loaded by the current index engine,
+// it checks all the indexes if no build id is given.
+func (i *IndexNode) CheckIndexCompatibility(ctx context.Context, buildIDs []UniqueID) (*metricsinfo.IndexCompatibilityInfos, error) {
+	return checkIndexCompatibility(i.etcdKV, buildIDs)
+}
+
 // GetMetrics gets the metrics info of IndexNode.
 // TODO(dragondriver): cache the Metrics and set a retention to the cache
 func (i *IndexNode) GetMetrics(ctx context.Context, req *milvuspb.GetMetricsRequest) (*milvuspb.GetMetricsResponse, error) {
@@ -329,10 +693,7 @@ func (i *IndexNode) GetMetrics(ctx context.Context, req *milvuspb.GetMetricsRequ
 			zap.Error(errIndexNodeIsUnhealthy(Params.NodeID)))
 
 		return &milvuspb.GetMetricsResponse{
-			Status: &commonpb.Status{
-				ErrorCode: commonpb.ErrorCode_UnexpectedError,
-				Reason:    msgIndexNodeIsUnhealthy(Params.NodeID),
-			},
+			Status:   i.notReadyStatus(),
 			Response: "",
 		}, nil
 	}
@@ -347,7 +708,7 @@ func (i *IndexNode) GetMetrics(ctx context.Context, req *milvuspb.GetMetricsRequ
 		return &milvuspb.GetMetricsResponse{
 			Status: &commonpb.Status{
 				ErrorCode: commonpb.ErrorCode_UnexpectedError,
-				Reason:    err.Error(),
+				Reason:    errorcode.Format(errorcode.InvalidParams, err.Error()),
 			},
 			Response: "",
 		}, nil
@@ -366,6 +727,42 @@ func (i *IndexNode) GetMetrics(ctx context.Context, req *milvuspb.GetMetricsRequ
 		return metrics, err
 	}
 
+	if metricType == metricsinfo.IndexCompatibilityMetrics {
+		metrics, err := getIndexCompatibilityMetrics(ctx, req, i.etcdKV)
+
+		log.Debug("IndexNode.GetMetrics",
+			zap.Int64("node_id", Params.NodeID),
+			zap.String("req", req.Request),
+			zap.String("metric_type", metricType),
+			zap.Error(err))
+
+		return metrics, err
+	}
+
+	if metricType == metricsinfo.IndexMetricCompatibilityMetrics {
+		metrics, err := getIndexMetricCompatibilityMetrics(ctx, req)
+
+		log.Debug("IndexNode.GetMetrics",
+			zap.Int64("node_id", Params.NodeID),
+			zap.String("req", req.Request),
+			zap.String("metric_type", metricType),
+			zap.Error(err))
+
+		return metrics, err
+	}
+
+	if metricType == metricsinfo.IndexRebuildProgressMetrics {
+		metrics, err := getIndexRebuildMetrics(ctx, req, i)
+
+		log.Debug("IndexNode.GetMetrics",
+			zap.Int64("node_id", Params.NodeID),
+			zap.String("req", req.Request),
+			zap.String("metric_type", metricType),
+			zap.Error(err))
+
+		return metrics, err
+	}
+
 	log.Warn("IndexNode.GetMetrics failed, request metric type is not implemented yet",
 		zap.Int64("node_id", Params.NodeID),
 		zap.String("req", req.Request),
@@ -374,7 +771,7 @@ func (i *IndexNode) GetMetrics(ctx context.Context, req *milvuspb.GetMetricsRequ
 	return &milvuspb.GetMetricsResponse{
 		Status: &commonpb.Status{
 			ErrorCode: commonpb.ErrorCode_UnexpectedError,
-			Reason:    metricsinfo.MsgUnimplementedMetric,
+			Reason:    msgUnsupportedMetricType(metricType),
 		},
 		Response: "",
 	}, nil
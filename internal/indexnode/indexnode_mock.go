@@ -246,6 +246,99 @@ func (inm *Mock) CreateIndex(ctx context.Context, req *indexpb.CreateIndexReques
 	}, nil
 }
 
+func (inm *Mock) DryRunCreateIndex(ctx context.Context, req *indexpb.CreateIndexRequest) (*indexpb.DryRunCreateIndexResponse, error) {
+	if inm.Err {
+		return &indexpb.DryRunCreateIndexResponse{
+			Status: &commonpb.Status{
+				ErrorCode: commonpb.ErrorCode_UnexpectedError,
+			},
+		}, errors.New("IndexNode DryRunCreateIndex failed")
+	}
+
+	return &indexpb.DryRunCreateIndexResponse{
+		Status: &commonpb.Status{
+			ErrorCode: commonpb.ErrorCode_Success,
+		},
+		Passed: true,
+	}, nil
+}
+
+func (inm *Mock) Activate(ctx context.Context, req *indexpb.ActivateRequest) (*commonpb.Status, error) {
+	if inm.Err {
+		return &commonpb.Status{
+			ErrorCode: commonpb.ErrorCode_UnexpectedError,
+		}, errors.New("IndexNode Activate failed")
+	}
+
+	return &commonpb.Status{
+		ErrorCode: commonpb.ErrorCode_Success,
+	}, nil
+}
+
+func (inm *Mock) CaptureProfile(ctx context.Context, req *indexpb.CaptureProfileRequest) (*indexpb.CaptureProfileResponse, error) {
+	if inm.Err {
+		return &indexpb.CaptureProfileResponse{
+			Status: &commonpb.Status{
+				ErrorCode: commonpb.ErrorCode_UnexpectedError,
+			},
+		}, errors.New("IndexNode CaptureProfile failed")
+	}
+
+	return &indexpb.CaptureProfileResponse{
+		Status: &commonpb.Status{
+			ErrorCode: commonpb.ErrorCode_Success,
+		},
+	}, nil
+}
+
+func (inm *Mock) VerifyIndexManifest(ctx context.Context, req *indexpb.VerifyIndexManifestRequest) (*indexpb.VerifyIndexManifestResponse, error) {
+	if inm.Err {
+		return &indexpb.VerifyIndexManifestResponse{
+			Status: &commonpb.Status{
+				ErrorCode: commonpb.ErrorCode_UnexpectedError,
+			},
+		}, errors.New("IndexNode VerifyIndexManifest failed")
+	}
+
+	return &indexpb.VerifyIndexManifestResponse{
+		Status: &commonpb.Status{
+			ErrorCode: commonpb.ErrorCode_Success,
+		},
+		Passed: true,
+	}, nil
+}
+
+func (inm *Mock) RunBuildBenchmark(ctx context.Context, req *indexpb.RunBuildBenchmarkRequest) (*indexpb.RunBuildBenchmarkResponse, error) {
+	if inm.Err {
+		return &indexpb.RunBuildBenchmarkResponse{
+			Status: &commonpb.Status{
+				ErrorCode: commonpb.ErrorCode_UnexpectedError,
+			},
+		}, errors.New("IndexNode RunBuildBenchmark failed")
+	}
+
+	return &indexpb.RunBuildBenchmarkResponse{
+		Status: &commonpb.Status{
+			ErrorCode: commonpb.ErrorCode_Success,
+		},
+		IndexType: req.IndexType,
+		Rows:      req.Rows,
+		Dim:       req.Dim,
+	}, nil
+}
+
+func (inm *Mock) RebuildIncompatibleIndexes(ctx context.Context, req *indexpb.RebuildIncompatibleIndexesRequest) (*commonpb.Status, error) {
+	if inm.Err {
+		return &commonpb.Status{
+			ErrorCode: commonpb.ErrorCode_UnexpectedError,
+		}, errors.New("IndexNode RebuildIncompatibleIndexes failed")
+	}
+
+	return &commonpb.Status{
+		ErrorCode: commonpb.ErrorCode_Success,
+	}, nil
+}
+
 func (inm *Mock) GetMetrics(ctx context.Context, req *milvuspb.GetMetricsRequest) (*milvuspb.GetMetricsResponse, error) {
 	if inm.Err {
 		return &milvuspb.GetMetricsResponse{
@@ -67,6 +67,44 @@ func TestIndexNodeMock(t *testing.T) {
 		assert.Equal(t, commonpb.ErrorCode_Success, resp.ErrorCode)
 	})
 
+	t.Run("DryRunCreateIndex", func(t *testing.T) {
+		resp, err := inm.DryRunCreateIndex(ctx, &indexpb.CreateIndexRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+		assert.True(t, resp.Passed)
+	})
+
+	t.Run("Activate", func(t *testing.T) {
+		resp, err := inm.Activate(ctx, &indexpb.ActivateRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.ErrorCode)
+	})
+
+	t.Run("CaptureProfile", func(t *testing.T) {
+		resp, err := inm.CaptureProfile(ctx, &indexpb.CaptureProfileRequest{ProfileType: "heap"})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+	})
+
+	t.Run("VerifyIndexManifest", func(t *testing.T) {
+		resp, err := inm.VerifyIndexManifest(ctx, &indexpb.VerifyIndexManifestRequest{IndexBuildID: 1})
+		assert.Nil(t, err)
+		assert.True(t, resp.Passed)
+	})
+
+	t.Run("RunBuildBenchmark", func(t *testing.T) {
+		resp, err := inm.RunBuildBenchmark(ctx, &indexpb.RunBuildBenchmarkRequest{Rows: 1000, Dim: 8, IndexType: "FLAT"})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+		assert.Equal(t, int64(1000), resp.Rows)
+	})
+
+	t.Run("RebuildIncompatibleIndexes", func(t *testing.T) {
+		resp, err := inm.RebuildIncompatibleIndexes(ctx, &indexpb.RebuildIncompatibleIndexesRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.ErrorCode)
+	})
+
 	t.Run("GetMetrics", func(t *testing.T) {
 		req := &milvuspb.GetMetricsRequest{
 			Request: "",
@@ -121,6 +159,42 @@ func TestIndexNodeMockError(t *testing.T) {
 		assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.ErrorCode)
 	})
 
+	t.Run("DryRunCreateIndex error", func(t *testing.T) {
+		resp, err := inm.DryRunCreateIndex(ctx, &indexpb.CreateIndexRequest{})
+		assert.NotNil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.Status.ErrorCode)
+	})
+
+	t.Run("Activate error", func(t *testing.T) {
+		resp, err := inm.Activate(ctx, &indexpb.ActivateRequest{})
+		assert.NotNil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.ErrorCode)
+	})
+
+	t.Run("CaptureProfile error", func(t *testing.T) {
+		resp, err := inm.CaptureProfile(ctx, &indexpb.CaptureProfileRequest{ProfileType: "heap"})
+		assert.NotNil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.Status.ErrorCode)
+	})
+
+	t.Run("VerifyIndexManifest error", func(t *testing.T) {
+		resp, err := inm.VerifyIndexManifest(ctx, &indexpb.VerifyIndexManifestRequest{IndexBuildID: 1})
+		assert.NotNil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.Status.ErrorCode)
+	})
+
+	t.Run("RunBuildBenchmark error", func(t *testing.T) {
+		resp, err := inm.RunBuildBenchmark(ctx, &indexpb.RunBuildBenchmarkRequest{Rows: 1000, Dim: 8, IndexType: "FLAT"})
+		assert.NotNil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.Status.ErrorCode)
+	})
+
+	t.Run("RebuildIncompatibleIndexes error", func(t *testing.T) {
+		resp, err := inm.RebuildIncompatibleIndexes(ctx, &indexpb.RebuildIncompatibleIndexesRequest{})
+		assert.NotNil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.ErrorCode)
+	})
+
 	t.Run("GetMetrics error", func(t *testing.T) {
 		req := &milvuspb.GetMetricsRequest{}
 		resp, err := inm.GetMetrics(ctx, req)
@@ -12,10 +12,11 @@
 package indexnode
 
 import (
-	"container/list"
 	"context"
+	"fmt"
 	"path"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -37,7 +38,11 @@ import (
 	"github.com/milvus-io/milvus/internal/proto/etcdpb"
 	"github.com/milvus-io/milvus/internal/proto/schemapb"
 
+	etcdkv "github.com/milvus-io/milvus/internal/kv/etcd"
 	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/funcutil"
+	"github.com/milvus-io/milvus/internal/util/sessionutil"
+	"github.com/milvus-io/milvus/internal/util/typeutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -777,7 +782,7 @@ func TestIndexNode_InitError(t *testing.T) {
 		sched: &TaskScheduler{
 			IndexBuildQueue: &IndexBuildTaskQueue{
 				BaseTaskQueue: BaseTaskQueue{
-					unissuedTasks: list.New(),
+					unissuedTasks: newFairTaskList(nil),
 					activeTasks:   make(map[UniqueID]task),
 					maxTaskNum:    0,
 					utBufChan:     make(chan int, 1024),
@@ -790,6 +795,288 @@ func TestIndexNode_InitError(t *testing.T) {
 	t.Run("CreateIndex", func(t *testing.T) {
 		status, err := in.CreateIndex(ctx, &indexpb.CreateIndexRequest{})
 		assert.Nil(t, err)
-		assert.Equal(t, commonpb.ErrorCode_UnexpectedError, status.ErrorCode)
+		assert.Equal(t, commonpb.ErrorCode_NodeBusy, status.ErrorCode)
 	})
 }
+
+func TestIndexNode_Busy(t *testing.T) {
+	ctx := context.Background()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	in.probe.update(probeEtcdSession, nil)
+	in.probe.update(probeStorage, nil)
+	in.UpdateStateCode(internalpb.StateCode_Healthy)
+
+	oldMaxPendingTasks := Params.MaxPendingTasks
+	Params.MaxPendingTasks = 2
+	defer func() {
+		Params.MaxPendingTasks = oldMaxPendingTasks
+	}()
+	in.sched.IndexBuildQueue = NewIndexBuildTaskQueue(in.sched)
+	in.taskStats.recordTask("IVF_FLAT", "AVX2", time.Second, nil)
+
+	createIndex := func(indexBuildID UniqueID) *commonpb.Status {
+		status, err := in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: indexBuildID})
+		assert.Nil(t, err)
+		return status
+	}
+
+	// the scheduler is not started, so the tasks stay in the queue
+	assert.Equal(t, commonpb.ErrorCode_Success, createIndex(1).ErrorCode)
+	assert.Equal(t, commonpb.ErrorCode_Success, createIndex(2).ErrorCode)
+	status := createIndex(3)
+	assert.Equal(t, commonpb.ErrorCode_NodeBusy, status.ErrorCode)
+	assert.Equal(t, msgIndexNodeIsBusy(Params.NodeID, 2, 2*time.Second), status.Reason)
+
+	// accept again after the queue drains, the same as what the scheduler loop does
+	<-in.sched.IndexBuildQueue.utChan()
+	assert.NotNil(t, in.sched.IndexBuildQueue.PopUnissuedTask())
+	assert.Equal(t, commonpb.ErrorCode_Success, createIndex(3).ErrorCode)
+	assert.Equal(t, commonpb.ErrorCode_NodeBusy, createIndex(4).ErrorCode)
+
+	assert.Nil(t, in.Stop())
+}
+
+func TestIndexNode_DryRun(t *testing.T) {
+	ctx := context.Background()
+
+	indexBuildID := UniqueID(54322)
+	floatVectorFieldID := UniqueID(101)
+	collectionID := UniqueID(201)
+	metaPath := "FloatVectorDryRun"
+	floatVectorBinlogPath := "float_vector_dry_run_binlog"
+
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	Params.Init()
+
+	err = in.Register()
+	assert.Nil(t, err)
+	err = in.Init()
+	assert.Nil(t, err)
+
+	err = in.Start()
+	assert.Nil(t, err)
+
+	var insertCodec storage.InsertCodec
+	defer insertCodec.Close()
+
+	insertCodec.Schema = &etcdpb.CollectionMeta{
+		ID: collectionID,
+		Schema: &schemapb.CollectionSchema{
+			Fields: []*schemapb.FieldSchema{
+				{
+					FieldID:  floatVectorFieldID,
+					Name:     "float_vector",
+					DataType: schemapb.DataType_FloatVector,
+				},
+			},
+		},
+	}
+	data := make(map[UniqueID]storage.FieldData)
+	data[floatVectorFieldID] = &storage.FloatVectorFieldData{
+		NumRows: []int64{nb},
+		Data:    generateFloatVectors(),
+		Dim:     dim,
+	}
+	insertData := storage.InsertData{
+		Data: data,
+		Infos: []storage.BlobInfo{
+			{
+				Length: 10,
+			},
+		},
+	}
+	binLogs, _, err := insertCodec.Serialize(999, 888, &insertData)
+	assert.Nil(t, err)
+	kvs := make(map[string]string, len(binLogs))
+	paths := make([]string, 0, len(binLogs))
+	for i, blob := range binLogs {
+		key := path.Join(floatVectorBinlogPath, strconv.Itoa(i))
+		paths = append(paths, key)
+		kvs[key] = string(blob.Value[:])
+	}
+	err = in.kv.MultiSave(kvs)
+	assert.Nil(t, err)
+	defer func() {
+		for k := range kvs {
+			in.kv.Remove(k)
+		}
+	}()
+
+	indexMeta := &indexpb.IndexMeta{
+		IndexBuildID: indexBuildID,
+		State:        commonpb.IndexState_InProgress,
+		Version:      1,
+	}
+	value, err := proto.Marshal(indexMeta)
+	assert.Nil(t, err)
+	err = in.etcdKV.Save(metaPath, string(value))
+	assert.Nil(t, err)
+	defer in.etcdKV.RemoveWithPrefix(metaPath)
+
+	req := &indexpb.CreateIndexRequest{
+		IndexBuildID: indexBuildID,
+		IndexName:    "FloatVector",
+		IndexID:      999,
+		Version:      1,
+		MetaPath:     metaPath,
+		DataPaths:    paths,
+		TypeParams: []*commonpb.KeyValuePair{
+			{
+				Key:   "dim",
+				Value: "8",
+			},
+		},
+		IndexParams: []*commonpb.KeyValuePair{
+			{
+				Key:   "index_type",
+				Value: "IVF_SQ8",
+			},
+			{
+				Key:   "params",
+				Value: "{\"nlist\": 128}",
+			},
+			{
+				Key:   "metric_type",
+				Value: "L2",
+			},
+		},
+	}
+
+	// wait for the first storage check, which the dry run reports
+	for _, err := in.probe.check(probeStorage); err == errProbeNotChecked; _, err = in.probe.check(probeStorage) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	report, err := in.DryRunCreateIndex(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, report.Status.ErrorCode)
+	assert.True(t, report.Passed, failedChecks(report))
+	assert.Equal(t, "IVF_SQ8", report.IndexType)
+	assert.Equal(t, int64(dim), report.Dim)
+	assert.Equal(t, int64(len(paths)), report.BinlogNum)
+	assert.Equal(t, int64(nb), report.EstimatedRows)
+
+	dryRunReq := proto.Clone(req).(*indexpb.CreateIndexRequest)
+	dryRunReq.DryRun = true
+	status, err := in.CreateIndex(ctx, dryRunReq)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, status.ErrorCode)
+
+	// nothing is written by the dry runs
+	strValue, err := in.etcdKV.Load(metaPath)
+	assert.Nil(t, err)
+	assert.Equal(t, string(value), strValue)
+	_, values, err := in.kv.LoadWithPrefix(report.IndexFilePrefix)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(values))
+
+	status, err = in.CreateIndex(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, status.ErrorCode)
+	indexMetaTmp := indexpb.IndexMeta{}
+	for indexMetaTmp.State != commonpb.IndexState_Finished && indexMetaTmp.State != commonpb.IndexState_Failed {
+		time.Sleep(100 * time.Millisecond)
+		strValue, err := in.etcdKV.Load(metaPath)
+		assert.Nil(t, err)
+		err = proto.Unmarshal([]byte(strValue), &indexMetaTmp)
+		assert.Nil(t, err)
+	}
+	defer in.kv.MultiRemove(indexMetaTmp.IndexFilePaths)
+	// the dry run agrees with the real build
+	assert.Equal(t, commonpb.IndexState_Finished, indexMetaTmp.State)
+	assert.NotEqual(t, 0, len(indexMetaTmp.IndexFilePaths))
+	for _, indexFilePath := range indexMetaTmp.IndexFilePaths {
+		assert.True(t, strings.HasPrefix(indexFilePath, report.IndexFilePrefix+"/"))
+	}
+
+	// the dry run fails once the index is built
+	report, err = in.DryRunCreateIndex(ctx, req)
+	assert.Nil(t, err)
+	assert.False(t, report.Passed)
+	status, err = in.CreateIndex(ctx, dryRunReq)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, status.ErrorCode)
+
+	err = in.Stop()
+	assert.Nil(t, err)
+}
+
+func TestIndexNode_Deregister(t *testing.T) {
+	e, endpoints := startEmbedEtcd(t)
+	defer e.Close()
+
+	Params.Init()
+	oldEndpoints, oldMetaRootPath, oldPersistNodeID := Params.EtcdEndpoints, Params.MetaRootPath, Params.PersistNodeID
+	defer func() {
+		Params.EtcdEndpoints, Params.MetaRootPath, Params.PersistNodeID = oldEndpoints, oldMetaRootPath, oldPersistNodeID
+	}()
+	Params.EtcdEndpoints = endpoints
+	Params.MetaRootPath = fmt.Sprintf("deregister-test-%d", time.Now().UnixNano())
+	Params.PersistNodeID = false
+
+	ctx := context.Background()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	err = in.Register()
+	assert.Nil(t, err)
+
+	etcdKV, err := etcdkv.NewEtcdKV(endpoints, Params.MetaRootPath)
+	assert.Nil(t, err)
+	defer etcdKV.Close()
+	sessionKey := path.Join(sessionutil.DefaultServiceRoot, typeutil.IndexNodeRole+"-"+strconv.FormatInt(Params.NodeID, 10))
+	_, err = etcdKV.Load(sessionKey)
+	assert.Nil(t, err)
+
+	assert.Nil(t, in.Stop())
+	_, err = etcdKV.Load(sessionKey)
+	assert.NotNil(t, err)
+}
+
+func TestIndexNode_MultipleInstances(t *testing.T) {
+	e, endpoints := startEmbedEtcd(t)
+	defer e.Close()
+
+	Params.Init()
+	oldAlias, oldScratchPath, oldPort := Params.Alias, Params.ScratchPath, Params.Port
+	oldEndpoints, oldMetaRootPath, oldPersistNodeID := Params.EtcdEndpoints, Params.MetaRootPath, Params.PersistNodeID
+	defer func() {
+		Params.Alias, Params.ScratchPath, Params.Port = oldAlias, oldScratchPath, oldPort
+		Params.EtcdEndpoints, Params.MetaRootPath, Params.PersistNodeID = oldEndpoints, oldMetaRootPath, oldPersistNodeID
+	}()
+	Params.EtcdEndpoints = endpoints
+	Params.MetaRootPath = fmt.Sprintf("multiple-instances-test-%d", time.Now().UnixNano())
+	Params.PersistNodeID = false
+
+	// the instances start in the same process one by one, since they share the params
+	ctx := context.Background()
+	scratchPaths := make(map[string]struct{})
+	for i := 1; i <= 3; i++ {
+		Params.InitAlias(fmt.Sprintf("in-%d", i))
+		Params.initScratchPath()
+		Params.Port = funcutil.GetAvailablePort()
+		in, err := NewIndexNode(ctx)
+		assert.Nil(t, err)
+		err = in.Register()
+		assert.Nil(t, err)
+		defer in.Stop()
+		scratchPaths[Params.ScratchPath] = struct{}{}
+	}
+	assert.Equal(t, 3, len(scratchPaths))
+
+	session := sessionutil.NewSession(ctx, Params.MetaRootPath, endpoints)
+	sessions, _, err := session.GetSessions(typeutil.IndexNodeRole)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(sessions))
+	nodeIDs := make(map[UniqueID]struct{})
+	aliases := make(map[string]struct{})
+	addresses := make(map[string]struct{})
+	for _, s := range sessions {
+		nodeIDs[s.ServerID] = struct{}{}
+		aliases[s.Alias] = struct{}{}
+		addresses[s.Address] = struct{}{}
+	}
+	assert.Equal(t, 3, len(nodeIDs))
+	assert.Equal(t, 3, len(aliases))
+	assert.Equal(t, 3, len(addresses))
+}
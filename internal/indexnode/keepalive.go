@@ -0,0 +1,100 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+)
+
+// the policies of indexNode.session.onKeepaliveFailure
+const (
+	// keepaliveFailureExit stops the node once the keepalive of the session fails
+	keepaliveFailureExit = "exit"
+	// keepaliveFailureRetryForever keeps re-registering the session, the node rejects new tasks while disconnected
+	keepaliveFailureRetryForever = "retry-forever"
+	// keepaliveFailureRetryThenExit re-registers the session within Params.KeepaliveRetryBudget, then stops the node
+	keepaliveFailureRetryThenExit = "retry-then-exit"
+)
+
+var (
+	// keepaliveRetryInterval is the interval between the attempts of re-registering the session
+	keepaliveRetryInterval = time.Second
+	// keepaliveRetryTimeout bounds each attempt of re-registering the session
+	keepaliveRetryTimeout = 3 * time.Second
+)
+
+// errSessionExpired is the failure of the session probe while the keepalive of the session fails
+var errSessionExpired = errors.New("etcd session expired")
+
+// livenessCheckLoop watches the keepalive of the session, and handles its failure by Params.KeepaliveFailurePolicy.
+func (i *IndexNode) livenessCheckLoop(liveCh <-chan bool) {
+	for {
+		i.session.LivenessCheck(i.loopCtx, liveCh, nil)
+		if i.loopCtx.Err() != nil {
+			return
+		}
+		// the node reports Abnormal and rejects new tasks until the session is registered again
+		i.probe.update(probeEtcdSession, errSessionExpired)
+		if Params.KeepaliveFailurePolicy == keepaliveFailureExit {
+			i.Stop()
+			return
+		}
+		var ok bool
+		if liveCh, ok = i.reregister(); !ok {
+			if i.loopCtx.Err() == nil {
+				i.Stop()
+			}
+			return
+		}
+	}
+}
+
+// reregister registers the session again with the same NodeID until it succeeds, or the budget of
+// keepaliveFailureRetryThenExit runs out.
+func (i *IndexNode) reregister() (<-chan bool, bool) {
+	var budgetCh <-chan time.Time
+	if Params.KeepaliveFailurePolicy == keepaliveFailureRetryThenExit {
+		timer := time.NewTimer(Params.KeepaliveRetryBudget)
+		defer timer.Stop()
+		budgetCh = timer.C
+	}
+	ticker := time.NewTicker(keepaliveRetryInterval)
+	defer ticker.Stop()
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		i.sessionMu.Lock()
+		liveCh, err := i.session.Reregister(keepaliveRetryTimeout)
+		i.sessionMu.Unlock()
+		if err == nil {
+			i.probe.update(probeEtcdSession, nil)
+			log.Info("IndexNode registered the session again", zap.Int64("NodeID", Params.NodeID),
+				zap.Int("attempts", attempt), zap.Duration("disconnected", time.Since(start)))
+			return liveCh, true
+		}
+		log.Warn("IndexNode failed to register the session again", zap.Int64("NodeID", Params.NodeID),
+			zap.Int("attempt", attempt), zap.Error(err))
+		select {
+		case <-i.loopCtx.Done():
+			return nil, false
+		case <-budgetCh:
+			log.Error("IndexNode failed to register the session again within the budget, stop the node",
+				zap.Int64("NodeID", Params.NodeID), zap.Duration("budget", Params.KeepaliveRetryBudget))
+			return nil, false
+		case <-ticker.C:
+		}
+	}
+}
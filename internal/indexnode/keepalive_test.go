@@ -0,0 +1,153 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+	"github.com/milvus-io/milvus/internal/util/typeutil"
+)
+
+func TestIndexNode_KeepaliveFailure(t *testing.T) {
+	e, endpoints := startEmbedEtcd(t)
+	defer func() {
+		e.Close()
+	}()
+	// pauseEtcd and resumeEtcd make etcd unreachable and reachable again
+	pauseEtcd := func() {
+		e.Close()
+	}
+	resumeEtcd := func() {
+		cfg := e.Config()
+		e = startEmbedEtcdWithConfig(t, &cfg)
+	}
+
+	Params.Init()
+	oldEndpoints, oldMetaRootPath, oldPersistNodeID := Params.EtcdEndpoints, Params.MetaRootPath, Params.PersistNodeID
+	oldPolicy, oldBudget := Params.KeepaliveFailurePolicy, Params.KeepaliveRetryBudget
+	oldInterval, oldTimeout := keepaliveRetryInterval, keepaliveRetryTimeout
+	defer func() {
+		Params.EtcdEndpoints, Params.MetaRootPath, Params.PersistNodeID = oldEndpoints, oldMetaRootPath, oldPersistNodeID
+		Params.KeepaliveFailurePolicy, Params.KeepaliveRetryBudget = oldPolicy, oldBudget
+		keepaliveRetryInterval, keepaliveRetryTimeout = oldInterval, oldTimeout
+	}()
+	Params.EtcdEndpoints = endpoints
+	Params.MetaRootPath = fmt.Sprintf("keepalive-test-%d", time.Now().UnixNano())
+	Params.PersistNodeID = false
+	keepaliveRetryInterval, keepaliveRetryTimeout = 100*time.Millisecond, 500*time.Millisecond
+
+	ctx := context.Background()
+	// startNode starts a serving node, whose keepalive fails once the returned channel is closed
+	startNode := func(t *testing.T) (*IndexNode, chan bool) {
+		in, err := NewIndexNode(ctx)
+		assert.Nil(t, err)
+		err = in.Register()
+		assert.Nil(t, err)
+		in.probe.update(probeStorage, nil)
+		in.setStartupPhase(startupPhaseServing)
+		in.UpdateStateCode(internalpb.StateCode_Healthy)
+		liveCh := make(chan bool)
+		go in.livenessCheckLoop(liveCh)
+		return in, liveCh
+	}
+	stopped := func(in *IndexNode) func() bool {
+		return func() bool {
+			return in.loopCtx.Err() != nil
+		}
+	}
+	registered := func(in *IndexNode, nodeID UniqueID) bool {
+		sessions, _, err := in.session.GetSessions(typeutil.IndexNodeRole)
+		if err != nil {
+			return false
+		}
+		for _, session := range sessions {
+			if session.ServerID == nodeID {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run(keepaliveFailureExit, func(t *testing.T) {
+		Params.KeepaliveFailurePolicy = keepaliveFailureExit
+		in, liveCh := startNode(t)
+		close(liveCh)
+		assert.Eventually(t, stopped(in), 10*time.Second, 10*time.Millisecond)
+		assert.Equal(t, internalpb.StateCode_Abnormal, in.stateCodeWithProbe())
+	})
+
+	t.Run(keepaliveFailureRetryForever, func(t *testing.T) {
+		Params.KeepaliveFailurePolicy = keepaliveFailureRetryForever
+		in, liveCh := startNode(t)
+		defer in.Stop()
+		nodeID := in.session.ServerID
+
+		// the lease is lost while etcd is unreachable
+		assert.Nil(t, in.session.Revoke(time.Second))
+		pauseEtcd()
+		close(liveCh)
+		assert.Eventually(t, func() bool {
+			return !in.isHealthy()
+		}, 10*time.Second, 10*time.Millisecond)
+		assert.Equal(t, internalpb.StateCode_Abnormal, in.stateCodeWithProbe())
+		status, err := in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_UnexpectedError, status.ErrorCode)
+		assert.Equal(t, msgIndexNodeIsUnhealthy(Params.NodeID), status.Reason)
+
+		// keeps retrying longer than the attempts of several intervals
+		time.Sleep(time.Second)
+		assert.False(t, stopped(in)())
+
+		resumeEtcd()
+		assert.Eventually(t, in.isHealthy, 30*time.Second, 50*time.Millisecond)
+		assert.Equal(t, nodeID, in.session.ServerID)
+		assert.True(t, registered(in, nodeID))
+		status, err = in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, status.ErrorCode)
+	})
+
+	t.Run(keepaliveFailureRetryThenExit, func(t *testing.T) {
+		Params.KeepaliveFailurePolicy = keepaliveFailureRetryThenExit
+		Params.KeepaliveRetryBudget = 2 * time.Second
+		in1, liveCh1 := startNode(t)
+		defer in1.Stop()
+		in2, liveCh2 := startNode(t)
+
+		// the session is registered again within the budget
+		nodeID := in1.session.ServerID
+		assert.Nil(t, in1.session.Revoke(time.Second))
+		close(liveCh1)
+		assert.Eventually(t, func() bool {
+			return registered(in1, nodeID)
+		}, 10*time.Second, 50*time.Millisecond)
+		assert.Eventually(t, in1.isHealthy, 10*time.Second, 10*time.Millisecond)
+		assert.False(t, stopped(in1)())
+
+		// etcd is unreachable longer than the budget
+		pauseEtcd()
+		defer resumeEtcd()
+		start := time.Now()
+		close(liveCh2)
+		assert.Eventually(t, stopped(in2), 30*time.Second, 10*time.Millisecond)
+		assert.True(t, time.Since(start) >= Params.KeepaliveRetryBudget)
+	})
+}
@@ -0,0 +1,169 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/util/healthz"
+	"github.com/milvus-io/milvus/internal/util/requestid"
+)
+
+// LogLevelRouterPath is the path reading and changing the log levels of IndexNode.
+const LogLevelRouterPath = "/log/level"
+
+// the log modules of IndexNode, whose levels can be changed separately at runtime
+const (
+	logModuleScheduler = "indexnode.scheduler"
+	logModuleStorage   = "indexnode.storage"
+	logModuleMeta      = "indexnode.meta"
+	logModuleEngine    = "indexnode.engine"
+)
+
+// logLevelGlobal is the module name standing for the global log level in the log level requests
+const logLevelGlobal = "global"
+
+var (
+	schedulerLog = log.Module(logModuleScheduler)
+	storageLog   = log.Module(logModuleStorage)
+	metaLog      = log.Module(logModuleMeta)
+	engineLog    = log.Module(logModuleEngine)
+)
+
+// logFields returns the fields attached to every log entry of IndexNode, the same keys are attached whether the
+// alias is set or not, so that the entries of the nodes share a schema.
+func logFields() map[string]string {
+	return map[string]string{
+		"role":   Params.RoleName,
+		"nodeID": strconv.FormatInt(Params.NodeID, 10),
+		"alias":  Params.Alias,
+	}
+}
+
+// logSamplingSummaryInterval is the window of the sampling of the hot log sites, at the end of which the numbers of
+// the suppressed entries are logged
+const logSamplingSummaryInterval = time.Minute
+
+// the hot log sites sampled by log.sampling
+const (
+	logSiteDownload = "download"
+	logSiteDecode   = "decode"
+	logSiteChunk    = "chunk"
+)
+
+// the samplers of the hot log sites of the modules, whose rates are set by configLogSampling
+var (
+	storageSampledLog = log.NewSampler(storageLog, 0, 0)
+	engineSampledLog  = log.NewSampler(engineLog, 0, 0)
+)
+
+// sampledLogger returns @base attaching the request id carried by @ctx, or @base itself if there is none.
+func sampledLogger(ctx context.Context, base *log.Sampler) *log.Sampler {
+	if id := requestid.FromContext(ctx); id != "" {
+		return base.With(zap.String(requestid.FieldKey, id))
+	}
+	return base
+}
+
+// configLogSampling sets the rates of the samplers of the hot log sites.
+func configLogSampling() {
+	for _, sampler := range []*log.Sampler{storageSampledLog, engineSampledLog} {
+		sampler.SetRates(Params.LogSamplingInitial, Params.LogSamplingThereafter)
+	}
+}
+
+// logSamplingLoop logs the numbers of the entries suppressed by the samplers periodically.
+func (i *IndexNode) logSamplingLoop() {
+	ticker := time.NewTicker(logSamplingSummaryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-i.loopCtx.Done():
+			return
+		case <-ticker.C:
+			storageSampledLog.Summarize()
+			engineSampledLog.Summarize()
+		}
+	}
+}
+
+// LogLevels is the json body returned by the log level router.
+type LogLevels struct {
+	Global  string            `json:"global"`
+	Modules map[string]string `json:"modules"`
+}
+
+func getLogLevels() *LogLevels {
+	ret := &LogLevels{
+		Global:  log.GetLevel().String(),
+		Modules: make(map[string]string),
+	}
+	for _, name := range log.Modules() {
+		level, _, err := log.GetModuleLevel(name)
+		if err != nil {
+			continue
+		}
+		ret.Modules[name] = level.String()
+	}
+	return ret
+}
+
+// setLogLevel changes the log level of the module, or the global level if the module is empty or global. An
+// empty level or global makes the module follow the global level again.
+func setLogLevel(module string, level string) error {
+	if module == "" || module == logLevelGlobal {
+		var l zapcore.Level
+		if err := l.UnmarshalText([]byte(level)); err != nil {
+			return err
+		}
+		log.SetLevel(l)
+		return nil
+	}
+	if level == "" || level == logLevelGlobal {
+		return log.ResetModuleLevel(module)
+	}
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+	return log.SetModuleLevel(module, l)
+}
+
+// handleLogLevel serves GET to read the log levels, and PUT or POST with the level and module form values to
+// change one of them.
+func handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		module, level := r.FormValue("module"), r.FormValue("level")
+		if err := setLogLevel(module, level); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Info("IndexNode log level changed", zap.String("module", module), zap.String("level", level))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set(healthz.ContentTypeHeader, healthz.ContentTypeJSON)
+	if err := json.NewEncoder(w).Encode(getLogLevels()); err != nil {
+		log.Warn("failed to send response", zap.Error(err))
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/requestid"
+)
+
+func requestLogLevel(t *testing.T, handler http.Handler, method string, form url.Values) (int, *LogLevels) {
+	req := httptest.NewRequest(method, LogLevelRouterPath, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		return w.Code, nil
+	}
+	ret := &LogLevels{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), ret))
+	return w.Code, ret
+}
+
+func TestIndexNode_LogLevel(t *testing.T) {
+	oldLevel := log.GetLevel()
+	defer func() {
+		logger, props, err := log.InitLogger(&log.Config{Level: oldLevel.String()})
+		assert.Nil(t, err)
+		log.ReplaceGlobals(logger, props)
+		for _, module := range log.Modules() {
+			_ = log.ResetModuleLevel(module)
+		}
+	}()
+
+	buf := &bytes.Buffer{}
+	logger, props, err := log.InitLoggerWithWriteSyncer(&log.Config{Level: "info", DisableTimestamp: true},
+		zapcore.AddSync(buf))
+	assert.Nil(t, err)
+	log.ReplaceGlobals(logger, props)
+
+	handler := (&IndexNode{}).ProbeHandler()
+	queue := &BaseTaskQueue{activeTasks: make(map[UniqueID]task)}
+	const debugMsg = "the task was not found in the active task list"
+
+	code, levels := requestLogLevel(t, handler, http.MethodGet, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "info", levels.Global)
+	for _, module := range []string{logModuleScheduler, logModuleStorage, logModuleMeta, logModuleEngine} {
+		assert.Equal(t, "info", levels.Modules[module])
+	}
+	queue.PopActiveTask(1)
+	assert.NotContains(t, buf.String(), debugMsg)
+
+	// the debug lines of the scheduler appear
+	code, levels = requestLogLevel(t, handler, http.MethodPut,
+		url.Values{"module": {logModuleScheduler}, "level": {"debug"}})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "debug", levels.Modules[logModuleScheduler])
+	assert.Equal(t, "info", levels.Modules[logModuleStorage])
+	queue.PopActiveTask(1)
+	assert.Contains(t, buf.String(), debugMsg)
+	assert.Contains(t, buf.String(), logModuleScheduler)
+
+	// and disappear once the module follows the global level again
+	buf.Reset()
+	code, levels = requestLogLevel(t, handler, http.MethodPost,
+		url.Values{"module": {logModuleScheduler}, "level": {logLevelGlobal}})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "info", levels.Modules[logModuleScheduler])
+	queue.PopActiveTask(1)
+	assert.NotContains(t, buf.String(), debugMsg)
+
+	// the global level applies to the modules not overridden
+	code, levels = requestLogLevel(t, handler, http.MethodPut, url.Values{"level": {"debug"}})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "debug", levels.Global)
+	assert.Equal(t, "debug", levels.Modules[logModuleScheduler])
+	queue.PopActiveTask(1)
+	assert.Contains(t, buf.String(), debugMsg)
+
+	buf.Reset()
+	code, _ = requestLogLevel(t, handler, http.MethodPut,
+		url.Values{"module": {logModuleScheduler}, "level": {"error"}})
+	assert.Equal(t, http.StatusOK, code)
+	queue.PopActiveTask(1)
+	assert.NotContains(t, buf.String(), debugMsg)
+
+	code, _ = requestLogLevel(t, handler, http.MethodPut, url.Values{"level": {"verbose"}})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = requestLogLevel(t, handler, http.MethodPut, url.Values{"module": {"unknown"}, "level": {"debug"}})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = requestLogLevel(t, handler, http.MethodDelete, nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+}
+
+func TestLogFields(t *testing.T) {
+	oldNodeID, oldAlias := Params.NodeID, Params.Alias
+	defer func() {
+		Params.NodeID, Params.Alias = oldNodeID, oldAlias
+	}()
+	Params.initRoleName()
+	Params.NodeID, Params.Alias = 7, ""
+	assert.Equal(t, map[string]string{"role": "indexnode", "nodeID": "7", "alias": ""}, logFields())
+
+	// the module loggers write the fields of the global logger
+	Params.Alias = "in-1"
+	oldLevel := log.GetLevel()
+	defer func() {
+		logger, props, err := log.InitLogger(&log.Config{Level: oldLevel.String()})
+		assert.Nil(t, err)
+		log.ReplaceGlobals(logger, props)
+	}()
+	buf := &bytes.Buffer{}
+	logger, p, err := log.InitLoggerWithWriteSyncer(&log.Config{Level: "info", Format: "json", LevelKey: "severity",
+		Fields: logFields()}, zapcore.AddSync(buf))
+	assert.Nil(t, err)
+	log.ReplaceGlobals(logger, p)
+	storageLog.Info("IndexNode load data success")
+
+	entry := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "INFO", entry["severity"])
+	assert.Equal(t, logModuleStorage, entry["name"])
+	assert.Equal(t, "indexnode", entry["role"])
+	assert.Equal(t, "7", entry["nodeID"])
+	assert.Equal(t, "in-1", entry["alias"])
+}
+
+// requestIDTask logs in the stages of the index build task, and builds a segment with the pipeline.
+type requestIDTask struct {
+	*IndexBuildTask
+	segment *pipelineTestSegment
+}
+
+func (t *requestIDTask) PreExecute(ctx context.Context) error {
+	t.logger(schedulerLog).Info("IndexNode admitted the task")
+	return nil
+}
+
+func (t *requestIDTask) Execute(ctx context.Context) error {
+	pipeline := t.segment.pipeline(&mockIncrementalIndex{}, 10)
+	pipeline.feeder.sampledLog = sampledLogger(t.ctx, engineSampledLog)
+	return pipeline.run(ctx)
+}
+
+func (t *requestIDTask) PostExecute(ctx context.Context) error {
+	t.logger(log.L()).Info("IndexNode built the task")
+	return nil
+}
+
+func TestIndexBuildTask_RequestID(t *testing.T) {
+	oldLevel := log.GetLevel()
+	defer func() {
+		logger, props, err := log.InitLogger(&log.Config{Level: oldLevel.String()})
+		assert.Nil(t, err)
+		log.ReplaceGlobals(logger, props)
+		configLogSampling()
+	}()
+	buf := &bytes.Buffer{}
+	logger, props, err := log.InitLoggerWithWriteSyncer(&log.Config{Level: "debug", Format: "json"},
+		zapcore.Lock(zapcore.AddSync(buf)))
+	assert.Nil(t, err)
+	log.ReplaceGlobals(logger, props)
+	storageSampledLog.SetRates(0, 0)
+	engineSampledLog.SetRates(0, 0)
+
+	sched, err := NewTaskScheduler(context.Background(), nil)
+	assert.Nil(t, err)
+	ctx := requestid.NewContext(context.Background(), "r-1")
+	it := &requestIDTask{
+		IndexBuildTask: &IndexBuildTask{
+			BaseTask: BaseTask{ctx: ctx},
+			req:      &indexpb.CreateIndexRequest{IndexBuildID: 1},
+			progress: newTaskProgress(1, 0),
+		},
+		segment: newPipelineTestSegment(4, 10),
+	}
+	// the task runs in the goroutine of the scheduler
+	done := make(chan struct{})
+	go func() {
+		sched.processTask(it, sched.IndexBuildQueue)
+		close(done)
+	}()
+	<-done
+	assert.Nil(t, it.err)
+
+	// the lines logged by the scheduler and the workers of the pipeline carry the request id
+	messages := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	for scanner.Scan() {
+		entry := make(map[string]interface{})
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &entry))
+		msg, _ := entry["message"].(string)
+		switch msg {
+		case "IndexNode admitted the task", "IndexNode built the task", "IndexNode downloaded the binlog",
+			"IndexNode decoded the binlog", "IndexNode added the chunk to the index":
+			assert.Equal(t, "r-1", entry[requestid.FieldKey], msg)
+			messages[msg]++
+		}
+	}
+	assert.Equal(t, map[string]int{
+		"IndexNode admitted the task":            1,
+		"IndexNode built the task":               1,
+		"IndexNode downloaded the binlog":        4,
+		"IndexNode decoded the binlog":           4,
+		"IndexNode added the chunk to the index": 4,
+	}, messages)
+}
@@ -0,0 +1,328 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/kv"
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/funcutil"
+	"github.com/milvus-io/milvus/internal/util/retry"
+)
+
+const (
+	// manifestFormatVersion is the version of the manifest format, it must be increased when the fields of the
+	// manifest change incompatibly
+	manifestFormatVersion int64 = 1
+	// manifestFileName is the name of the manifest saved under the prefix of the index files
+	manifestFileName = "manifest.json"
+	// manifestChecksumType is the checksum of the files in the manifest
+	manifestChecksumType = "crc32c"
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// manifestFile is an index file in the manifest, whose name is relative to the manifest.
+type manifestFile struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+}
+
+// manifestEncryption is how the index files are encrypted, see indexpb.IndexEncryption.
+type manifestEncryption struct {
+	Algorithm  string `json:"algorithm"`
+	KeyID      string `json:"key_id"`
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+// manifestBuildSeed is the seed the index is built with, see indexpb.IndexBuildSeed.
+type manifestBuildSeed struct {
+	Seed    int64 `json:"seed"`
+	Honored bool  `json:"honored"`
+}
+
+type manifestArtifactVersion struct {
+	EngineVersion int64 `json:"engine_version"`
+	SchemaVersion int64 `json:"schema_version"`
+}
+
+// indexManifest lists the index files of a build along with the params the index is built with, which is saved
+// as JSON along with the index files so that the drift between the index meta and the bucket is detected.
+type indexManifest struct {
+	FormatVersion   int64                   `json:"format_version"`
+	IndexBuildID    int64                   `json:"index_build_id"`
+	Version         int64                   `json:"version"`
+	ArtifactVersion manifestArtifactVersion `json:"artifact_version"`
+	TypeParams      map[string]string       `json:"type_params"`
+	IndexParams     map[string]string       `json:"index_params"`
+	// EngineParams are the params the engine builds the index with, the configured engine params included
+	EngineParams map[string]string `json:"engine_params"`
+	ChecksumType string            `json:"checksum_type"`
+	// Encryption is nil if the index files are not encrypted, the checksums and the sizes are of the encrypted ones
+	Encryption *manifestEncryption `json:"encryption,omitempty"`
+	// BuildSeed is nil if the build is not seeded
+	BuildSeed *manifestBuildSeed `json:"build_seed,omitempty"`
+	// BaseIndexBuildID and BaseVersion are of the index the index is appended to, zeros if it is built from scratch
+	BaseIndexBuildID int64          `json:"base_index_build_id,omitempty"`
+	BaseVersion      int64          `json:"base_version,omitempty"`
+	Files            []manifestFile `json:"files"`
+}
+
+func newIndexManifest(req *indexpb.CreateIndexRequest, typeParams, indexParams, engineParams map[string]string) *indexManifest {
+	version := currentArtifactVersion()
+	return &indexManifest{
+		FormatVersion: manifestFormatVersion,
+		IndexBuildID:  req.IndexBuildID,
+		Version:       req.Version,
+		ArtifactVersion: manifestArtifactVersion{
+			EngineVersion: version.EngineVersion,
+			SchemaVersion: version.SchemaVersion,
+		},
+		TypeParams:   typeParams,
+		IndexParams:  indexParams,
+		EngineParams: engineParams,
+		ChecksumType: manifestChecksumType,
+		Files:        make([]manifestFile, 0),
+	}
+}
+
+// addFile adds the file of @name with its @size and @checksum.
+func (m *indexManifest) addFile(name string, size int64, checksum string) {
+	m.Files = append(m.Files, manifestFile{Name: name, Size: size, Checksum: checksum})
+}
+
+// marshal encodes the manifest with the files in order of the names.
+func (m *indexManifest) marshal() (string, error) {
+	sort.Slice(m.Files, func(i, j int) bool {
+		return m.Files[i].Name < m.Files[j].Name
+	})
+	value, err := json.Marshal(m)
+	return string(value), err
+}
+
+// parseIndexManifest decodes the manifest, the manifest of the format newer than IndexNode is rejected.
+func parseIndexManifest(value string) (*indexManifest, error) {
+	manifest := &indexManifest{}
+	if err := json.Unmarshal([]byte(value), manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.FormatVersion <= 0 || manifest.FormatVersion > manifestFormatVersion {
+		return nil, fmt.Errorf("unsupported manifest format version %d, the supported version is %d",
+			manifest.FormatVersion, manifestFormatVersion)
+	}
+	if manifest.ChecksumType != manifestChecksumType {
+		return nil, fmt.Errorf("unsupported checksum type %q of the manifest", manifest.ChecksumType)
+	}
+	return manifest, nil
+}
+
+// checksumOf returns the crc32c of @data in hex.
+func checksumOf(data []byte) string {
+	return fmt.Sprintf("%08x", crc32.Checksum(data, castagnoliTable))
+}
+
+func newChecksumHash() hash.Hash32 {
+	return crc32.New(castagnoliTable)
+}
+
+func checksumOfHash(h hash.Hash32) string {
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// fileChecksum returns the crc32c of the local file in hex.
+func fileChecksum(localPath string) (string, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := newChecksumHash()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return checksumOfHash(h), nil
+}
+
+// verifyManifest checks every file of the manifest saved in @manifestPath against the object in @storage, and
+// against @expected, the paths of the index files recorded in the index meta, if it is not nil. It returns the
+// manifest along with the files missing or mismatched, the error is returned only if the manifest can not be read.
+func verifyManifest(storage kv.BaseKV, manifestPath string, expected []string) (*indexManifest, []string, error) {
+	value, err := storage.Load(manifestPath)
+	if err != nil {
+		return nil, nil, storageError(err)
+	}
+	manifest, err := parseIndexManifest(value)
+	if err != nil {
+		return nil, nil, errorcode.Wrap(errorcode.InvalidParams, err)
+	}
+
+	var mu sync.Mutex
+	mismatches := make([]string, 0)
+	mismatch := func(format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		mismatches = append(mismatches, fmt.Sprintf(format, args...))
+	}
+	dir := path.Dir(manifestPath)
+	_ = funcutil.ProcessFuncParallel(len(manifest.Files), runtime.NumCPU(), func(idx int) error {
+		file := manifest.Files[idx]
+		value, err := storage.Load(path.Join(dir, file.Name))
+		if err != nil {
+			mismatch("%s: failed to load: %s", file.Name, err.Error())
+			return nil
+		}
+		if int64(len(value)) != file.Size {
+			mismatch("%s: size %d, expect %d", file.Name, len(value), file.Size)
+			return nil
+		}
+		if checksum := checksumOf([]byte(value)); checksum != file.Checksum {
+			mismatch("%s: checksum %s, expect %s", file.Name, checksum, file.Checksum)
+		}
+		return nil
+	}, "verifyManifest")
+
+	if expected != nil {
+		listed := make(map[string]bool, len(manifest.Files))
+		for _, file := range manifest.Files {
+			listed[path.Join(dir, file.Name)] = true
+		}
+		recorded := make(map[string]bool, len(expected))
+		for _, filePath := range expected {
+			recorded[filePath] = true
+			if !listed[filePath] {
+				mismatch("%s: recorded in the index meta but not in the manifest", filePath)
+			}
+		}
+		for _, file := range manifest.Files {
+			if !recorded[path.Join(dir, file.Name)] {
+				mismatch("%s: listed in the manifest but not in the index meta", file.Name)
+			}
+		}
+	}
+	sort.Strings(mismatches)
+	return manifest, mismatches, nil
+}
+
+// manifestError is the failure of the build whose saved index files do not agree with the manifest, which is
+// retried since the object storage may fail to keep what is written.
+func manifestError(manifestPath string, mismatches []string) error {
+	return errorcode.Errorf(errorcode.StorageTransient, "the index files mismatch the manifest %s: %s",
+		manifestPath, strings.Join(mismatches, "; "))
+}
+
+// saveManifest saves @manifest to @manifestPath along with the index files saved by the build, and verifies the
+// saved index files against it as the last step of the build, which reads all the index files back.
+func (it *IndexBuildTask) saveManifest(ctx context.Context, manifestPath string, manifest *indexManifest,
+	metadata map[string]string) error {
+	value, err := manifest.marshal()
+	if err != nil {
+		return err
+	}
+	err = retry.Do(ctx, it.recordRetries(taskStageSave, func() error {
+		return storageError(saveWithMetadata(it.kv, manifestPath, value, metadata))
+	}), retry.Attempts(5))
+	if err != nil {
+		return err
+	}
+	it.cleaner.register(resourceObject, manifestPath, true)
+
+	expected := append([]string{}, it.savePaths...)
+	for _, file := range it.fileManifest {
+		expected = append(expected, file.FilePath)
+	}
+	_, mismatches, err := verifyManifest(it.kv, manifestPath, expected)
+	if err != nil {
+		return err
+	}
+	if len(mismatches) > 0 {
+		return manifestError(manifestPath, mismatches)
+	}
+	it.manifestPath = manifestPath
+	return nil
+}
+
+// verifyBuildManifest verifies the index files of @buildID in @storage against the manifest recorded in the index
+// meta in @metaKV.
+func verifyBuildManifest(metaKV kv.BaseKV, storage kv.BaseKV, buildID UniqueID) (*indexpb.VerifyIndexManifestResponse, error) {
+	value, err := metaKV.Load(path.Join(indexMetaPrefix, strconv.FormatInt(buildID, 10)))
+	if err != nil {
+		return nil, etcdError(fmt.Errorf("failed to load the index meta of build %d: %w", buildID, err))
+	}
+	if value == "" {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "the index meta of build %d is not found", buildID)
+	}
+	indexMeta := indexpb.IndexMeta{}
+	if err := proto.Unmarshal([]byte(value), &indexMeta); err != nil {
+		return nil, err
+	}
+	if indexMeta.ManifestPath == "" {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "the build %d has no manifest, whose state is %s",
+			buildID, indexMeta.State.String())
+	}
+
+	expected := append([]string{}, indexMeta.IndexFilePaths...)
+	for _, file := range indexMeta.FileManifest {
+		expected = append(expected, file.FilePath)
+	}
+	manifest, mismatches, err := verifyManifest(storage, indexMeta.ManifestPath, expected)
+	if err != nil {
+		return nil, err
+	}
+	return &indexpb.VerifyIndexManifestResponse{
+		Status:        &commonpb.Status{ErrorCode: commonpb.ErrorCode_Success},
+		ManifestPath:  indexMeta.ManifestPath,
+		FormatVersion: manifest.FormatVersion,
+		Passed:        len(mismatches) == 0,
+		Mismatches:    mismatches,
+	}, nil
+}
+
+// VerifyIndexManifest re-checks the index files of a build against the manifest saved along with them, and against
+// the index files recorded in the index meta.
+func (i *IndexNode) VerifyIndexManifest(ctx context.Context, req *indexpb.VerifyIndexManifestRequest) (*indexpb.VerifyIndexManifestResponse, error) {
+	if !i.isHealthy() {
+		return &indexpb.VerifyIndexManifestResponse{Status: i.notReadyStatus()}, nil
+	}
+	resp, err := verifyBuildManifest(i.etcdKV, i.kv, req.IndexBuildID)
+	if err != nil {
+		log.Warn("IndexNode failed to verify the manifest", zap.Int64("IndexBuildID", req.IndexBuildID), zap.Error(err))
+		return &indexpb.VerifyIndexManifestResponse{
+			Status: &commonpb.Status{
+				ErrorCode: commonpb.ErrorCode_UnexpectedError,
+				Reason:    errorcode.Format(classifyError(err), err.Error()),
+			},
+		}, nil
+	}
+	log.Info("IndexNode verified the manifest", zap.Int64("IndexBuildID", req.IndexBuildID),
+		zap.String("ManifestPath", resp.ManifestPath), zap.Bool("Passed", resp.Passed), zap.Strings("Mismatches", resp.Mismatches))
+	return resp, nil
+}
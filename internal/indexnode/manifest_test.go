@@ -0,0 +1,228 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+// truncatingKV saves the objects of truncateKey truncated, as if the object storage fails to keep them.
+type truncatingKV struct {
+	*memkv.MemoryKV
+	truncateKey string
+}
+
+func (kv *truncatingKV) Save(key, value string) error {
+	if key == kv.truncateKey {
+		value = value[:len(value)/2]
+	}
+	return kv.MemoryKV.Save(key, value)
+}
+
+const manifestTestPrefix = "index_files/1/2/3/4"
+
+// newManifestTestFiles saves the index files in @storage, and returns the manifest of them.
+func newManifestTestFiles(t *testing.T, storage *memkv.MemoryKV) (*indexManifest, []string) {
+	manifest := newIndexManifest(&indexpb.CreateIndexRequest{IndexBuildID: 1, Version: 2},
+		map[string]string{dimKey: "8"}, map[string]string{indexTypeKey: "IVF_FLAT", "nlist": "100"},
+		map[string]string{indexTypeKey: "IVF_FLAT", "nlist": "100", "nprobe": "8"})
+	paths := make([]string, 0)
+	for name, value := range map[string]string{"IVF": "the ivf data", "indexParams": "the index params"} {
+		filePath := path.Join(manifestTestPrefix, name)
+		assert.Nil(t, storage.Save(filePath, value))
+		manifest.addFile(name, int64(len(value)), checksumOf([]byte(value)))
+		paths = append(paths, filePath)
+	}
+	value, err := manifest.marshal()
+	assert.Nil(t, err)
+	assert.Nil(t, storage.Save(path.Join(manifestTestPrefix, manifestFileName), value))
+	return manifest, paths
+}
+
+func TestIndexManifest(t *testing.T) {
+	manifest, _ := newManifestTestFiles(t, memkv.NewMemoryKV())
+	manifest.BuildSeed = &manifestBuildSeed{Seed: 7, Honored: true}
+	value, err := manifest.marshal()
+	assert.Nil(t, err)
+	parsed, err := parseIndexManifest(value)
+	assert.Nil(t, err)
+	assert.Equal(t, manifest, parsed)
+	assert.Equal(t, int64(7), parsed.BuildSeed.Seed)
+	assert.Equal(t, "IVF", parsed.Files[0].Name)
+	assert.Equal(t, "indexParams", parsed.Files[1].Name)
+	assert.Equal(t, currentEngineVersion, parsed.ArtifactVersion.EngineVersion)
+	assert.Equal(t, "8", parsed.EngineParams["nprobe"])
+
+	// the manifest is a versioned JSON
+	fields := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal([]byte(value), &fields))
+	assert.Equal(t, float64(manifestFormatVersion), fields["format_version"])
+	assert.Equal(t, manifestChecksumType, fields["checksum_type"])
+
+	for _, invalid := range []string{
+		"not a manifest",
+		`{"format_version":0,"checksum_type":"crc32c"}`,
+		`{"format_version":2,"checksum_type":"crc32c"}`,
+		`{"format_version":1,"checksum_type":"md5"}`,
+	} {
+		_, err := parseIndexManifest(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+
+	localPath := filepath.Join(t.TempDir(), "IVF")
+	assert.Nil(t, ioutil.WriteFile(localPath, []byte("the ivf data"), 0600))
+	checksum, err := fileChecksum(localPath)
+	assert.Nil(t, err)
+	assert.Equal(t, checksumOf([]byte("the ivf data")), checksum)
+	_, err = fileChecksum(filepath.Join(t.TempDir(), "missing"))
+	assert.NotNil(t, err)
+}
+
+func TestVerifyManifest(t *testing.T) {
+	manifestPath := path.Join(manifestTestPrefix, manifestFileName)
+
+	storage := memkv.NewMemoryKV()
+	_, paths := newManifestTestFiles(t, storage)
+	manifest, mismatches, err := verifyManifest(storage, manifestPath, paths)
+	assert.Nil(t, err)
+	assert.Empty(t, mismatches)
+	assert.Equal(t, 2, len(manifest.Files))
+	_, mismatches, err = verifyManifest(storage, manifestPath, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, mismatches)
+
+	// the files of the same size but different content
+	assert.Nil(t, storage.Save(paths[0], strings.ToUpper(mustLoad(t, storage, paths[0]))))
+	_, mismatches, err = verifyManifest(storage, manifestPath, paths)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(mismatches))
+	assert.Contains(t, mismatches[0], "checksum")
+
+	storage = memkv.NewMemoryKV()
+	_, paths = newManifestTestFiles(t, storage)
+	assert.Nil(t, storage.Remove(paths[1]))
+	_, mismatches, err = verifyManifest(storage, manifestPath, paths)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(mismatches))
+	assert.Contains(t, mismatches[0], "size 0")
+
+	// the drift between the index meta and the manifest
+	storage = memkv.NewMemoryKV()
+	_, paths = newManifestTestFiles(t, storage)
+	_, mismatches, err = verifyManifest(storage, manifestPath, []string{paths[0], path.Join(manifestTestPrefix, "extra")})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(mismatches))
+	assert.Contains(t, strings.Join(mismatches, ";"), "recorded in the index meta but not in the manifest")
+	assert.Contains(t, strings.Join(mismatches, ";"), "listed in the manifest but not in the index meta")
+
+	_, _, err = verifyManifest(storage, path.Join("missing", manifestFileName), nil)
+	assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+}
+
+func mustLoad(t *testing.T, storage *memkv.MemoryKV, key string) string {
+	value, err := storage.Load(key)
+	assert.Nil(t, err)
+	return value
+}
+
+func TestIndexBuildTask_saveManifest(t *testing.T) {
+	ctx := context.Background()
+	manifestPath := path.Join(manifestTestPrefix, manifestFileName)
+	newTask := func(storage *truncatingKV) (*IndexBuildTask, *indexManifest) {
+		it := &IndexBuildTask{
+			kv:      storage,
+			req:     &indexpb.CreateIndexRequest{IndexBuildID: 1, Version: 2},
+			cleaner: newTaskCleaner(1, 2, storage),
+		}
+		manifest := newIndexManifest(it.req, nil, nil, nil)
+		for name, value := range map[string]string{"IVF": "the ivf data", "indexParams": "the index params"} {
+			filePath := path.Join(manifestTestPrefix, name)
+			assert.Nil(t, storage.Save(filePath, value))
+			manifest.addFile(name, int64(len(value)), checksumOf([]byte(value)))
+			it.savePaths = append(it.savePaths, filePath)
+		}
+		// the disk index files
+		assert.Nil(t, storage.Save(path.Join(manifestTestPrefix, "disk/graph"), "the graph"))
+		manifest.addFile("disk/graph", int64(len("the graph")), checksumOf([]byte("the graph")))
+		it.fileManifest = []*indexpb.IndexFileInfo{{FilePath: path.Join(manifestTestPrefix, "disk/graph"), FileSize: 9}}
+		return it, manifest
+	}
+
+	storage := &truncatingKV{MemoryKV: memkv.NewMemoryKV()}
+	it, manifest := newTask(storage)
+	assert.Nil(t, it.saveManifest(ctx, manifestPath, manifest, nil))
+	assert.Equal(t, manifestPath, it.manifestPath)
+	_, err := parseIndexManifest(mustLoad(t, storage.MemoryKV, manifestPath))
+	assert.Nil(t, err)
+
+	// the build fails if the saved files do not agree with the manifest
+	storage = &truncatingKV{MemoryKV: memkv.NewMemoryKV(), truncateKey: path.Join(manifestTestPrefix, "IVF")}
+	it, manifest = newTask(storage)
+	err = it.saveManifest(ctx, manifestPath, manifest, nil)
+	assert.Equal(t, errorcode.StorageTransient, classifyError(err))
+	assert.Contains(t, err.Error(), "IVF: size")
+	assert.Equal(t, "", it.manifestPath)
+
+	it, manifest = newTask(&truncatingKV{MemoryKV: memkv.NewMemoryKV()})
+	it.savePaths = it.savePaths[:1]
+	err = it.saveManifest(ctx, manifestPath, manifest, nil)
+	assert.Equal(t, errorcode.StorageTransient, classifyError(err))
+	assert.Contains(t, err.Error(), "listed in the manifest but not in the index meta")
+}
+
+func TestVerifyBuildManifest(t *testing.T) {
+	metaKV := memkv.NewMemoryKV()
+	storage := memkv.NewMemoryKV()
+	_, paths := newManifestTestFiles(t, storage)
+	saveMeta := func(meta *indexpb.IndexMeta) {
+		value, err := proto.Marshal(meta)
+		assert.Nil(t, err)
+		assert.Nil(t, metaKV.Save(path.Join(indexMetaPrefix, "1"), string(value)))
+	}
+
+	saveMeta(&indexpb.IndexMeta{IndexBuildID: 1, State: commonpb.IndexState_Finished, IndexFilePaths: paths,
+		ManifestPath: path.Join(manifestTestPrefix, manifestFileName)})
+	resp, err := verifyBuildManifest(metaKV, storage, 1)
+	assert.Nil(t, err)
+	assert.True(t, resp.Passed)
+	assert.Equal(t, manifestFormatVersion, resp.FormatVersion)
+	assert.Equal(t, path.Join(manifestTestPrefix, manifestFileName), resp.ManifestPath)
+
+	assert.Nil(t, storage.Save(paths[0], "corrupted"))
+	resp, err = verifyBuildManifest(metaKV, storage, 1)
+	assert.Nil(t, err)
+	assert.False(t, resp.Passed)
+	assert.Equal(t, 1, len(resp.Mismatches))
+
+	_, err = verifyBuildManifest(metaKV, storage, 2)
+	assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+
+	// the builds before the manifest or not finished have no manifest
+	saveMeta(&indexpb.IndexMeta{IndexBuildID: 1, State: commonpb.IndexState_InProgress})
+	_, err = verifyBuildManifest(metaKV, storage, 1)
+	assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+	assert.Contains(t, err.Error(), "InProgress")
+}
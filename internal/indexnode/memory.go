@@ -0,0 +1,182 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/process"
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/metrics"
+)
+
+// buildMemoryFactors is the estimated peak memory of a build in multiples of the size of the loaded binlogs,
+// the binlogs and the decoded vectors are held while the engine builds its own copy of the index.
+var buildMemoryFactors = map[string]float64{
+	"FLAT":       2,
+	"BIN_FLAT":   2,
+	"HNSW":       3,
+	"RHNSW_FLAT": 3,
+	"NSG":        4,
+	"ANNOY":      3,
+	"DISKANN":    1,
+}
+
+const defaultBuildMemoryFactor = 2.5
+
+// estimateBuildMemory estimates the peak memory of building an index of @indexType on @rawSize bytes of binlogs.
+func estimateBuildMemory(indexType string, rawSize int64) int64 {
+	factor, ok := buildMemoryFactors[strings.ToUpper(indexType)]
+	if !ok {
+		factor = defaultBuildMemoryFactor
+	}
+	return int64(float64(rawSize) * factor)
+}
+
+// processRSS returns the resident memory of IndexNode, including the memory allocated by the engine.
+func processRSS() (uint64, error) {
+	proc, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return 0, err
+	}
+	info, err := proc.MemoryInfo()
+	if err != nil {
+		return 0, err
+	}
+	return info.RSS, nil
+}
+
+// memorySampler samples the resident memory during a build, the peak is the growth of the memory over the
+// baseline when the build starts. The growth is only attributed to the task if no other task runs meanwhile.
+type memorySampler struct {
+	read        func() (uint64, error)
+	activeTasks func() int
+
+	mu       sync.Mutex
+	baseline uint64
+	peak     uint64
+	shared   bool
+	err      error
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// startMemorySampler samples the memory every @interval until stopped.
+func startMemorySampler(read func() (uint64, error), activeTasks func() int, interval time.Duration) *memorySampler {
+	s := &memorySampler{
+		read:        read,
+		activeTasks: activeTasks,
+		stopCh:      make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	s.baseline, s.err = read()
+	s.peak = s.baseline
+	s.shared = activeTasks() > 1
+	go s.loop(interval)
+	return s
+}
+
+func (s *memorySampler) loop(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.sample()
+		}
+	}
+}
+
+func (s *memorySampler) sample() {
+	rss, err := s.read()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.err = err
+		return
+	}
+	if rss > s.peak {
+		s.peak = rss
+	}
+	if s.activeTasks() > 1 {
+		s.shared = true
+	}
+}
+
+// stop stops sampling and returns the peak growth of the memory, exclusive is false if the memory may be
+// used by the other tasks, or the memory failed to read.
+func (s *memorySampler) stop() (peak int64, exclusive bool) {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		<-s.done
+		// the last sample covers the memory held right before the task ends
+		s.sample()
+	})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, false
+	}
+	return int64(s.peak - s.baseline), !s.shared
+}
+
+// isEstimateOff returns true if the estimated memory differs from the actual one more than @tolerance times.
+func isEstimateOff(estimated, actual int64, tolerance float64) bool {
+	if estimated <= 0 || actual <= 0 {
+		return false
+	}
+	return float64(actual) > float64(estimated)*tolerance || float64(estimated) > float64(actual)*tolerance
+}
+
+// startMemorySampler samples the memory used by the task while it executes, it samples nothing if disabled.
+func (it *IndexBuildTask) startMemorySampler() *memorySampler {
+	if Params.MemorySampleInterval <= 0 {
+		return nil
+	}
+	return startMemorySampler(processRSS, it.tracker.count, Params.MemorySampleInterval)
+}
+
+// recordPeakMemory records the peak memory of the succeeded build, and warns if it is far from the estimate.
+func (it *IndexBuildTask) recordPeakMemory(sampler *memorySampler) {
+	if sampler == nil {
+		return
+	}
+	peak, exclusive := sampler.stop()
+	if !exclusive {
+		log.Debug("IndexNode does not attribute the memory to the task running along with the other tasks",
+			zap.Int64("indexBuildID", it.req.IndexBuildID))
+		return
+	}
+	indexType := it.indexType()
+	it.stats.recordPeakMemory(indexType, peak)
+	metrics.IndexNodeBuildPeakMemory.WithLabelValues(indexType).Observe(float64(peak))
+	log.Debug("IndexNode build peak memory", zap.Int64("indexBuildID", it.req.IndexBuildID),
+		zap.String("indexType", indexType), zap.Int64("peakMemory", peak))
+
+	estimated := estimateBuildMemory(indexType, it.loadedBytes)
+	if isEstimateOff(estimated, peak, Params.MemoryEstimateTolerance) {
+		log.Warn("IndexNode memory estimate of the build is off, the estimate needs calibration",
+			zap.Int64("indexBuildID", it.req.IndexBuildID), zap.String("indexType", indexType),
+			zap.Int64("loadedBytes", it.loadedBytes), zap.Int64("estimatedMemory", estimated),
+			zap.Int64("peakMemory", peak), zap.Float64("tolerance", Params.MemoryEstimateTolerance))
+	}
+}
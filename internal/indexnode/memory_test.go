@@ -0,0 +1,150 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+)
+
+// fakeMemory returns the memory usages in order, and keeps returning the last one.
+type fakeMemory struct {
+	mu     sync.Mutex
+	usages []uint64
+	reads  int
+	err    error
+}
+
+func (m *fakeMemory) read() (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return 0, m.err
+	}
+	idx := m.reads
+	if idx >= len(m.usages) {
+		idx = len(m.usages) - 1
+	}
+	m.reads++
+	return m.usages[idx], nil
+}
+
+func (m *fakeMemory) readNum() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.reads
+}
+
+func TestMemorySampler(t *testing.T) {
+	single := func() int { return 1 }
+
+	memory := &fakeMemory{usages: []uint64{100, 300, 700, 200}}
+	sampler := startMemorySampler(memory.read, single, time.Millisecond)
+	for memory.readNum() < 4 {
+		time.Sleep(time.Millisecond)
+	}
+	peak, exclusive := sampler.stop()
+	assert.Equal(t, int64(600), peak)
+	assert.True(t, exclusive)
+	// stops only once
+	peak, _ = sampler.stop()
+	assert.Equal(t, int64(600), peak)
+
+	t.Run("stop promptly", func(t *testing.T) {
+		memory := &fakeMemory{usages: []uint64{100, 150}}
+		sampler := startMemorySampler(memory.read, single, time.Hour)
+		start := time.Now()
+		peak, exclusive := sampler.stop()
+		assert.True(t, time.Since(start) < time.Second)
+		// the final sample is taken when stopped
+		assert.Equal(t, int64(50), peak)
+		assert.True(t, exclusive)
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		memory := &fakeMemory{usages: []uint64{100, 300}}
+		var mu sync.Mutex
+		active := 1
+		sampler := startMemorySampler(memory.read, func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return active
+		}, time.Hour)
+		mu.Lock()
+		active = 2
+		mu.Unlock()
+		_, exclusive := sampler.stop()
+		assert.False(t, exclusive)
+	})
+
+	t.Run("read failed", func(t *testing.T) {
+		memory := &fakeMemory{err: errors.New("permission denied")}
+		sampler := startMemorySampler(memory.read, single, time.Hour)
+		peak, exclusive := sampler.stop()
+		assert.Equal(t, int64(0), peak)
+		assert.False(t, exclusive)
+	})
+
+	t.Run("process", func(t *testing.T) {
+		rss, err := processRSS()
+		assert.Nil(t, err)
+		assert.True(t, rss > 0)
+	})
+}
+
+func TestIsEstimateOff(t *testing.T) {
+	assert.False(t, isEstimateOff(100, 150, 2))
+	assert.False(t, isEstimateOff(100, 60, 2))
+	assert.True(t, isEstimateOff(100, 201, 2))
+	assert.True(t, isEstimateOff(100, 49, 2))
+	// nothing to compare with
+	assert.False(t, isEstimateOff(0, 100, 2))
+	assert.False(t, isEstimateOff(100, 0, 2))
+
+	assert.Equal(t, int64(200), estimateBuildMemory("flat", 100))
+	assert.Equal(t, int64(250), estimateBuildMemory("IVF_PQ", 100))
+}
+
+func TestIndexBuildTask_recordPeakMemory(t *testing.T) {
+	it := &IndexBuildTask{
+		req: &indexpb.CreateIndexRequest{
+			IndexBuildID: 1,
+			IndexParams:  []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: "HNSW"}},
+		},
+		stats:       newTaskStatistics(),
+		loadedBytes: 100,
+	}
+	it.recordPeakMemory(nil)
+
+	memory := &fakeMemory{usages: []uint64{1000, 2000}}
+	it.recordPeakMemory(startMemorySampler(memory.read, func() int { return 1 }, time.Hour))
+	memory = &fakeMemory{usages: []uint64{1000, 1500}}
+	it.recordPeakMemory(startMemorySampler(memory.read, func() int { return 1 }, time.Hour))
+	// the memory shared with the other tasks is not recorded
+	memory = &fakeMemory{usages: []uint64{1000, 5000}}
+	it.recordPeakMemory(startMemorySampler(memory.read, func() int { return 2 }, time.Hour))
+	assert.Equal(t, map[string]int64{"HNSW": 1000}, it.stats.taskInfos(0, 0, 0).IndexTypePeakMemory)
+
+	interval := Params.MemorySampleInterval
+	defer func() {
+		Params.MemorySampleInterval = interval
+	}()
+	Params.MemorySampleInterval = 0
+	assert.Nil(t, it.startMemorySampler())
+}
@@ -0,0 +1,56 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/indexparamcheck"
+	"github.com/milvus-io/milvus/internal/util/metricsinfo"
+)
+
+// checkMetricCompatibility rejects the request whose metric type is not supported by its index type before it is
+// enqueued, since the engine fails it only after the binlogs are loaded with an unusable message. The vector type
+// is the one of the field schema, which is implied by the index type if the schema is missing, and the malformed
+// params are left to fail the task.
+func checkMetricCompatibility(req *indexpb.CreateIndexRequest) error {
+	_, indexParams, err := parseBuildParams(req)
+	if err != nil {
+		return nil
+	}
+	var vectorType indexparamcheck.VectorType
+	if schema := req.GetFieldSchema(); schema != nil && isVectorDataType(schema.DataType) {
+		vectorType = vectorTypeOf(schema.DataType)
+	}
+	err = indexparamcheck.CheckMetricCompatibility(indexParams[indexTypeKey], indexParams[metricTypeKey], vectorType)
+	if err != nil {
+		return errorcode.Wrap(errorcode.InvalidParams, err)
+	}
+	return nil
+}
+
+// metricCompatibilityInfos returns the table of the metric types supported by each index type in the format of
+// metrics.
+func metricCompatibilityInfos() *metricsinfo.IndexMetricCompatibilityInfos {
+	table := indexparamcheck.MetricCompatibilities()
+	infos := &metricsinfo.IndexMetricCompatibilityInfos{
+		Compatibilities: make([]metricsinfo.IndexMetricCompatibility, 0, len(table)),
+	}
+	for _, c := range table {
+		infos.Compatibilities = append(infos.Compatibilities, metricsinfo.IndexMetricCompatibility{
+			IndexType:   c.IndexType,
+			VectorType:  c.VectorType,
+			MetricTypes: c.MetricTypes,
+		})
+	}
+	return infos
+}
@@ -0,0 +1,104 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/metricsinfo"
+)
+
+func TestCheckMetricCompatibility(t *testing.T) {
+	newRequest := func(indexParams ...*commonpb.KeyValuePair) *indexpb.CreateIndexRequest {
+		return &indexpb.CreateIndexRequest{IndexBuildID: 1, IndexParams: indexParams}
+	}
+	assert.Nil(t, checkMetricCompatibility(newRequest(&commonpb.KeyValuePair{Key: indexTypeKey, Value: "HNSW"},
+		&commonpb.KeyValuePair{Key: metricTypeKey, Value: "IP"})))
+	assert.Nil(t, checkMetricCompatibility(newRequest(&commonpb.KeyValuePair{Key: paramsKeyToParse,
+		Value: `{"index_type": "BIN_FLAT", "metric_type": "SUBSTRUCTURE"}`})))
+
+	err := checkMetricCompatibility(newRequest(&commonpb.KeyValuePair{Key: indexTypeKey, Value: "HNSW"},
+		&commonpb.KeyValuePair{Key: metricTypeKey, Value: "SUBSTRUCTURE"}))
+	assert.NotNil(t, err)
+	// the incompatible requests fail whichever node takes them
+	assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+	assert.True(t, strings.Contains(err.Error(), "allowed metric types: L2,IP"))
+
+	// the vector type is the one of the field schema if it is present
+	req := newRequest(&commonpb.KeyValuePair{Key: indexTypeKey, Value: "BIN_IVF_FLAT"},
+		&commonpb.KeyValuePair{Key: metricTypeKey, Value: "JACCARD"})
+	req.FieldSchema = &schemapb.FieldSchema{DataType: schemapb.DataType_FloatVector}
+	assert.NotNil(t, checkMetricCompatibility(req))
+	req.FieldSchema.DataType = schemapb.DataType_BinaryVector
+	assert.Nil(t, checkMetricCompatibility(req))
+
+	// the malformed params are left to fail the task
+	assert.Nil(t, checkMetricCompatibility(newRequest(&commonpb.KeyValuePair{Key: metricTypeKey, Value: "L2"},
+		&commonpb.KeyValuePair{Key: metricTypeKey, Value: "IP"})))
+}
+
+func TestIndexNode_IncompatibleMetricType(t *testing.T) {
+	ctx := context.Background()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	in.probe.update(probeEtcdSession, nil)
+	in.probe.update(probeStorage, nil)
+	in.UpdateStateCode(internalpb.StateCode_Healthy)
+
+	status, err := in.CreateIndex(ctx, &indexpb.CreateIndexRequest{
+		IndexBuildID: 1,
+		IndexParams: []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: "IVF_FLAT"},
+			{Key: metricTypeKey, Value: "HAMMING"}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, status.ErrorCode)
+	code, msg := errorcode.Parse(status.Reason)
+	assert.Equal(t, errorcode.InvalidParams, code)
+	assert.True(t, strings.Contains(msg, "IVF_FLAT"), msg)
+	assert.Equal(t, 0, in.sched.IndexBuildQueue.utLen())
+
+	// the float index on the binary field is rejected by the vector type of the field schema
+	status, err = in.CreateIndex(ctx, &indexpb.CreateIndexRequest{
+		IndexBuildID: 2,
+		IndexParams: []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: "HNSW"},
+			{Key: metricTypeKey, Value: "L2"}},
+		FieldSchema: &schemapb.FieldSchema{FieldID: 101, DataType: schemapb.DataType_BinaryVector},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, status.ErrorCode)
+	code, msg = errorcode.Parse(status.Reason)
+	assert.Equal(t, errorcode.InvalidParams, code)
+	assert.True(t, strings.Contains(msg, "does not support BinaryVector"), msg)
+	assert.Equal(t, 0, in.sched.IndexBuildQueue.utLen())
+
+	req, err := metricsinfo.ConstructRequestByMetricType(metricsinfo.IndexMetricCompatibilityMetrics)
+	assert.Nil(t, err)
+	resp, err := in.GetMetrics(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+	infos := metricsinfo.IndexMetricCompatibilityInfos{}
+	assert.Nil(t, metricsinfo.UnmarshalComponentInfos(resp.Response, &infos))
+	assert.Contains(t, infos.Compatibilities, metricsinfo.IndexMetricCompatibility{
+		IndexType: "BIN_IVF_FLAT", VectorType: "BinaryVector", MetricTypes: []string{"HAMMING", "JACCARD", "TANIMOTO"},
+	})
+
+	assert.Nil(t, in.Stop())
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/milvus-io/milvus/internal/metrics"
+)
+
+// MetricsRouterPath is the path of the prometheus metrics of IndexNode.
+const MetricsRouterPath = "/metrics"
+
+// newMetricsRegistry returns the registry of the metrics of the node, including the gauges reporting the states of
+// the node itself.
+func (i *IndexNode) newMetricsRegistry() *prometheus.Registry {
+	return metrics.NewIndexNodeRegistry(Params.NodeID,
+		metrics.NewIndexNodeQueueDepth(metrics.IndexNodeQueueUnissued, func() float64 {
+			return float64(i.sched.IndexBuildQueue.utLen())
+		}),
+		metrics.NewIndexNodeQueueDepth(metrics.IndexNodeQueueActive, func() float64 {
+			return float64(i.sched.IndexBuildQueue.atLen())
+		}),
+		metrics.NewIndexNodeSessionLeaseTTL(func() float64 {
+			var lease sessionLease
+			if i.session != nil {
+				lease = i.session
+			}
+			return sessionLeaseTTL(lease)
+		}),
+		metrics.NewIndexNodeMemoryUsage(func() float64 {
+			used, total, err := nodeMemoryUsage()
+			if err != nil || total == 0 {
+				return 0
+			}
+			return float64(used) / float64(total)
+		}),
+	)
+}
+
+// MetricsHandler returns the http handler serving the prometheus metrics of IndexNode, which are available once
+// the node is registered.
+func (i *IndexNode) MetricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(MetricsRouterPath, func(w http.ResponseWriter, r *http.Request) {
+		registry, ok := i.registry.Load().(*prometheus.Registry)
+		if !ok {
+			http.Error(w, "index node is not registered yet", http.StatusServiceUnavailable)
+			return
+		}
+		promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	})
+	return mux
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/metrics"
+)
+
+func scrape(handler http.Handler) (int, string) {
+	req := httptest.NewRequest(http.MethodGet, MetricsRouterPath, nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w.Code, w.Body.String()
+}
+
+func TestIndexNode_MetricsHandler(t *testing.T) {
+	oldNodeID := Params.NodeID
+	defer func() {
+		Params.NodeID = oldNodeID
+	}()
+
+	newNode := func(nodeID UniqueID) *IndexNode {
+		in, err := NewIndexNode(context.Background())
+		assert.Nil(t, err)
+		// the registry is created by Register once the NodeID is assigned
+		Params.NodeID = nodeID
+		in.registry.Store(in.newMetricsRegistry())
+		return in
+	}
+
+	in, err := NewIndexNode(context.Background())
+	assert.Nil(t, err)
+	code, _ := scrape(in.MetricsHandler())
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	// the metrics can be registered by multiple nodes in one process
+	metrics.RegisterIndexNode()
+	metrics.RegisterIndexNode()
+	in1, in2 := newNode(101), newNode(102)
+
+	in1.taskStats.recordTask("IVF_FLAT", "avx2", time.Second, nil)
+	in1.taskStats.recordTask("IVF_FLAT", "avx2", time.Second, errors.New("build failed"))
+	in1.taskStats.recordLoad(1024, time.Second)
+	in1.taskStats.recordSave(1024, time.Second)
+	metrics.IndexNodeBuildPeakMemory.WithLabelValues("IVF_FLAT").Observe(1024)
+	metrics.IndexNodeAdmissionPaused.WithLabelValues(watermarkMemory).Set(0)
+
+	for nodeID, node := range map[UniqueID]*IndexNode{101: in1, 102: in2} {
+		code, body := scrape(node.MetricsHandler())
+		assert.Equal(t, http.StatusOK, code)
+		for _, series := range []string{
+			`milvus_indexnode_task_total{node_id="%d",status="succeeded"}`,
+			`milvus_indexnode_task_total{node_id="%d",status="failed"}`,
+			`milvus_indexnode_build_duration_seconds_count{index_type="IVF_FLAT",node_id="%d"}`,
+			`milvus_indexnode_storage_latency_seconds_count{node_id="%d",operation="load"}`,
+			`milvus_indexnode_storage_latency_seconds_count{node_id="%d",operation="save"}`,
+			`milvus_indexnode_build_peak_memory_bytes_count{index_type="IVF_FLAT",node_id="%d"}`,
+			`milvus_indexnode_admission_paused{node_id="%d",resource="memory"}`,
+			`milvus_indexnode_queue_depth{node_id="%d",queue="unissued"} 0`,
+			`milvus_indexnode_queue_depth{node_id="%d",queue="active"} 0`,
+			`milvus_indexnode_session_lease_ttl_seconds{node_id="%d"} 0`,
+			`milvus_indexnode_memory_usage_ratio{node_id="%d"}`,
+			`milvus_indexnode_go_goroutines{node_id="%d"}`,
+			`milvus_indexnode_go_memstats_heap_alloc_bytes{node_id="%d"}`,
+			`milvus_indexnode_process_resident_memory_bytes{node_id="%d"}`,
+		} {
+			assert.Contains(t, body, fmt.Sprintf(series, nodeID))
+		}
+		assert.NotContains(t, body, "\ngo_goroutines")
+	}
+}
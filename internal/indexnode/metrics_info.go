@@ -13,8 +13,13 @@ package indexnode
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
+	"strconv"
+	"time"
 
+	"github.com/milvus-io/milvus/internal/kv"
 	"github.com/milvus-io/milvus/internal/proto/commonpb"
 	"github.com/milvus-io/milvus/internal/util/metricsinfo"
 	"github.com/milvus-io/milvus/internal/util/typeutil"
@@ -22,13 +27,21 @@ import (
 	"github.com/milvus-io/milvus/internal/proto/milvuspb"
 )
 
+// the keys of the extra info of the component states, see lifetimeInfos
+const (
+	createdTimeKey     = "created_time"
+	updatedTimeKey     = "updated_time"
+	uptimeKey          = "uptime_seconds"
+	configRefreshesKey = "config_refreshes"
+)
+
 // TODO(dragondriver): maybe IndexNode should be an interface so that we can mock it in the test cases
 func getSystemInfoMetrics(
 	ctx context.Context,
 	req *milvuspb.GetMetricsRequest,
 	node *IndexNode,
 ) (*milvuspb.GetMetricsResponse, error) {
-	// TODO(dragondriver): add more metrics
+	createdTime, updatedTime, refreshes := Params.lifetime()
 	nodeInfos := metricsinfo.IndexNodeInfos{
 		BaseComponentInfos: metricsinfo.BaseComponentInfos{
 			Name: metricsinfo.ConstructComponentName(typeutil.IndexNodeRole, Params.NodeID),
@@ -45,22 +58,42 @@ func getSystemInfoMetrics(
 				SystemVersion: os.Getenv(metricsinfo.GitCommitEnvKey),
 				DeployMode:    os.Getenv(metricsinfo.DeployModeEnvKey),
 			},
-			CreatedTime: Params.CreatedTime.String(),
-			UpdatedTime: Params.UpdatedTime.String(),
+			CreatedTime: formatTime(createdTime),
+			UpdatedTime: formatTime(updatedTime),
 			Type:        typeutil.IndexNodeRole,
 		},
+		Mode:            node.mode(),
+		SelfTest:        node.selfTestResult(),
+		UptimeSeconds:   int64(uptime(createdTime) / time.Second),
+		ConfigRefreshes: refreshes,
 		SystemConfigurations: metricsinfo.IndexNodeConfiguration{
 			MinioBucketName: Params.MinioBucketName,
 
-			SimdType: Params.SimdType,
+			SimdType:          Params.SimdType,
+			RequestedSimdType: Params.SimdTypeRequested,
+			EffectiveSimdType: Params.EffectiveSimdType,
+			SimdTypeOverrides: Params.SimdTypeOverrides,
+
+			BuildParallel:       node.sched.buildParallel,
+			MemoryBudget:        memoryBudget(metricsinfo.GetMemoryCount(), Params.MemoryHighWatermark),
+			MemoryHighWatermark: Params.MemoryHighWatermark,
+			MemoryLowWatermark:  Params.MemoryLowWatermark,
+			DiskMinFree:         Params.DiskMinFree,
+			DiskResumeFree:      Params.DiskResumeFree,
+			Capabilities:        node.capabilities,
 		},
+		TaskInfos: node.taskStats.taskInfos(node.sched.IndexBuildQueue.utLen(), node.sched.IndexBuildQueue.atLen(),
+			node.sched.IndexBuildQueue.utCap()),
 	}
+	suspectBuildIDs := node.taskTracker.suspectBuildIDs()
+	nodeInfos.TaskInfos.SuspectTaskNum = int64(len(suspectBuildIDs))
+	nodeInfos.TaskInfos.SuspectBuildIDs = suspectBuildIDs
 	resp, err := metricsinfo.MarshalComponentInfos(nodeInfos)
 	if err != nil {
 		return &milvuspb.GetMetricsResponse{
 			Status: &commonpb.Status{
 				ErrorCode: commonpb.ErrorCode_UnexpectedError,
-				Reason:    err.Error(),
+				Reason:    failureReason(err),
 			},
 			Response:      "",
 			ComponentName: metricsinfo.ConstructComponentName(typeutil.IndexNodeRole, Params.NodeID),
@@ -76,3 +109,147 @@ func getSystemInfoMetrics(
 		ComponentName: metricsinfo.ConstructComponentName(typeutil.IndexNodeRole, Params.NodeID),
 	}, nil
 }
+
+// formatTime formats the time in RFC3339 UTC.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// uptime returns the duration since the node is created, 0 if it has not been initialized.
+func uptime(createdTime time.Time) time.Duration {
+	if createdTime.IsZero() {
+		return 0
+	}
+	return time.Since(createdTime)
+}
+
+// lifetimeInfos returns the extra info of the component states telling when the node is created and updated.
+func lifetimeInfos() []*commonpb.KeyValuePair {
+	createdTime, updatedTime, refreshes := Params.lifetime()
+	return []*commonpb.KeyValuePair{
+		{Key: createdTimeKey, Value: formatTime(createdTime)},
+		{Key: updatedTimeKey, Value: formatTime(updatedTime)},
+		{Key: uptimeKey, Value: strconv.FormatInt(int64(uptime(createdTime)/time.Second), 10)},
+		{Key: configRefreshesKey, Value: strconv.FormatInt(refreshes, 10)},
+	}
+}
+
+// parseBuildIDs returns the build ids given by metricsinfo.BuildIDsKey in the request.
+func parseBuildIDs(req string) ([]UniqueID, error) {
+	m := make(map[string]json.RawMessage)
+	if err := json.Unmarshal([]byte(req), &m); err != nil {
+		return nil, fmt.Errorf("failed to decode the request: %s", err.Error())
+	}
+	raw, ok := m[metricsinfo.BuildIDsKey]
+	if !ok {
+		return nil, nil
+	}
+	var buildIDs []UniqueID
+	if err := json.Unmarshal(raw, &buildIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode %s of the request: %s", metricsinfo.BuildIDsKey, err.Error())
+	}
+	return buildIDs, nil
+}
+
+func getIndexCompatibilityMetrics(
+	ctx context.Context,
+	req *milvuspb.GetMetricsRequest,
+	metaKV kv.BaseKV,
+) (*milvuspb.GetMetricsResponse, error) {
+	componentName := metricsinfo.ConstructComponentName(typeutil.IndexNodeRole, Params.NodeID)
+	failed := func(err error) (*milvuspb.GetMetricsResponse, error) {
+		return &milvuspb.GetMetricsResponse{
+			Status: &commonpb.Status{
+				ErrorCode: commonpb.ErrorCode_UnexpectedError,
+				Reason:    failureReason(err),
+			},
+			Response:      "",
+			ComponentName: componentName,
+		}, nil
+	}
+
+	buildIDs, err := parseBuildIDs(req.Request)
+	if err != nil {
+		return failed(err)
+	}
+	infos, err := checkIndexCompatibility(metaKV, buildIDs)
+	if err != nil {
+		return failed(err)
+	}
+	resp, err := metricsinfo.MarshalComponentInfos(infos)
+	if err != nil {
+		return failed(err)
+	}
+
+	return &milvuspb.GetMetricsResponse{
+		Status: &commonpb.Status{
+			ErrorCode: commonpb.ErrorCode_Success,
+			Reason:    "",
+		},
+		Response:      resp,
+		ComponentName: componentName,
+	}, nil
+}
+
+// getIndexMetricCompatibilityMetrics returns the metric types supported by each index type on each vector type.
+func getIndexMetricCompatibilityMetrics(ctx context.Context, req *milvuspb.GetMetricsRequest) (*milvuspb.GetMetricsResponse, error) {
+	componentName := metricsinfo.ConstructComponentName(typeutil.IndexNodeRole, Params.NodeID)
+	resp, err := metricsinfo.MarshalComponentInfos(metricCompatibilityInfos())
+	if err != nil {
+		return &milvuspb.GetMetricsResponse{
+			Status: &commonpb.Status{
+				ErrorCode: commonpb.ErrorCode_UnexpectedError,
+				Reason:    failureReason(err),
+			},
+			Response:      "",
+			ComponentName: componentName,
+		}, nil
+	}
+
+	return &milvuspb.GetMetricsResponse{
+		Status: &commonpb.Status{
+			ErrorCode: commonpb.ErrorCode_Success,
+			Reason:    "",
+		},
+		Response:      resp,
+		ComponentName: componentName,
+	}, nil
+}
+
+// getIndexRebuildMetrics returns the progress of rebuilding the incompatible indexes, which is started by
+// RebuildIncompatibleIndexes.
+func getIndexRebuildMetrics(
+	ctx context.Context,
+	req *milvuspb.GetMetricsRequest,
+	node *IndexNode,
+) (*milvuspb.GetMetricsResponse, error) {
+	componentName := metricsinfo.ConstructComponentName(typeutil.IndexNodeRole, Params.NodeID)
+	failed := func(err error) (*milvuspb.GetMetricsResponse, error) {
+		return &milvuspb.GetMetricsResponse{
+			Status: &commonpb.Status{
+				ErrorCode: commonpb.ErrorCode_UnexpectedError,
+				Reason:    failureReason(err),
+			},
+			Response:      "",
+			ComponentName: componentName,
+		}, nil
+	}
+
+	progress, err := node.GetRebuildProgress()
+	if err != nil {
+		return failed(err)
+	}
+	resp, err := metricsinfo.MarshalComponentInfos(progress)
+	if err != nil {
+		return failed(err)
+	}
+
+	return &milvuspb.GetMetricsResponse{
+		Status: &commonpb.Status{
+			ErrorCode: commonpb.ErrorCode_Success,
+			Reason:    "",
+		},
+		Response:      resp,
+		ComponentName: componentName,
+	}, nil
+}
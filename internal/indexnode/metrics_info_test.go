@@ -12,11 +12,69 @@
 package indexnode
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/util/metricsinfo"
+	"github.com/milvus-io/milvus/internal/util/sessionutil"
+	"github.com/milvus-io/milvus/internal/util/typeutil"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestGetSystemInfoMetrics(t *testing.T) {
-	log.Info("TestGetSystemInfoMetrics, todo")
+	ctx := context.Background()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	in.session = &sessionutil.Session{Address: "127.0.0.1:21121"}
+	in.taskStats.recordTask("IVF_FLAT", "AVX2", time.Second, nil)
+	oldCreatedTime, oldUpdatedTime := Params.CreatedTime, Params.UpdatedTime
+	defer func() {
+		Params.CreatedTime, Params.UpdatedTime = oldCreatedTime, oldUpdatedTime
+	}()
+	Params.CreatedTime = time.Now().Add(-time.Minute)
+	Params.UpdatedTime = Params.CreatedTime
+
+	req, err := metricsinfo.ConstructRequestByMetricType(metricsinfo.SystemInfoMetrics)
+	assert.Nil(t, err)
+	resp, err := getSystemInfoMetrics(ctx, req, in)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+
+	infos := metricsinfo.IndexNodeInfos{}
+	err = metricsinfo.UnmarshalComponentInfos(resp.Response, &infos)
+	assert.Nil(t, err)
+	assert.Equal(t, typeutil.IndexNodeRole, infos.Type)
+	assert.Equal(t, "127.0.0.1:21121", infos.HardwareInfos.IP)
+	assert.Equal(t, in.sched.buildParallel, infos.SystemConfigurations.BuildParallel)
+	assert.Equal(t, Params.SimdType, infos.SystemConfigurations.SimdType)
+	assert.Equal(t, Params.EffectiveSimdType, infos.SystemConfigurations.EffectiveSimdType)
+	assert.Equal(t, Params.MemoryHighWatermark, infos.SystemConfigurations.MemoryHighWatermark)
+	assert.Equal(t, Params.MemoryLowWatermark, infos.SystemConfigurations.MemoryLowWatermark)
+	assert.Equal(t, memoryBudget(metricsinfo.GetMemoryCount(), Params.MemoryHighWatermark), infos.SystemConfigurations.MemoryBudget)
+	assert.Equal(t, Params.DiskMinFree, infos.SystemConfigurations.DiskMinFree)
+	assert.Equal(t, int64(1), infos.TaskInfos.CompletedTaskNum)
+	assert.Equal(t, int64(1), infos.TaskInfos.IndexTypeBuildNum["IVF_FLAT"])
+	assert.Equal(t, int64(0), infos.TaskInfos.QueuedTaskNum)
+	assert.Equal(t, int64(in.sched.IndexBuildQueue.utCap()), infos.TaskInfos.MaxPendingTaskNum)
+	assert.Equal(t, infos.TaskInfos.MaxPendingTaskNum, infos.TaskInfos.AvailableSlotNum)
+	createdTime, err := time.Parse(time.RFC3339, infos.CreatedTime)
+	assert.Nil(t, err)
+	assert.Equal(t, Params.CreatedTime.Unix(), createdTime.Unix())
+	assert.True(t, strings.HasSuffix(infos.UpdatedTime, "Z"))
+	assert.True(t, infos.UptimeSeconds >= 60)
+	assert.Equal(t, modeActive, infos.Mode)
+	assert.Nil(t, infos.SelfTest)
+
+	in.selfTest.Store(&metricsinfo.IndexNodeSelfTest{Passed: true, IndexType: "IVF_FLAT", SimdType: "avx2", DurationMs: 120})
+	resp, err = getSystemInfoMetrics(ctx, req, in)
+	assert.Nil(t, err)
+	infos = metricsinfo.IndexNodeInfos{}
+	assert.Nil(t, metricsinfo.UnmarshalComponentInfos(resp.Response, &infos))
+	assert.True(t, infos.SelfTest.Passed)
+	assert.Equal(t, "avx2", infos.SelfTest.SimdType)
+	assert.Equal(t, int64(120), infos.SelfTest.DurationMs)
+	assert.Nil(t, in.Stop())
 }
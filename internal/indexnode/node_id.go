@@ -0,0 +1,180 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	etcdkv "github.com/milvus-io/milvus/internal/kv/etcd"
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/util/sessionutil"
+	"github.com/milvus-io/milvus/internal/util/typeutil"
+)
+
+// NodeIDPrefix is the prefix of the etcd keys under the meta root persisting the NodeIDs, the key of a node
+// is the prefix followed by its identity, e.g. indexnode-id/{identity}.
+const NodeIDPrefix = "indexnode-id"
+
+// nodeIDPollInterval is the interval of checking whether the previous session has expired
+const nodeIDPollInterval = time.Second
+
+// nodeIDKV is the etcd kv persisting the NodeIDs.
+type nodeIDKV interface {
+	LoadWithPrefix(key string) ([]string, []string, error)
+	Save(key, value string) error
+}
+
+// sessionGetter gets the live sessions, which is implemented by sessionutil.Session.
+type sessionGetter interface {
+	GetSessions(prefix string) (map[string]*sessionutil.Session, int64, error)
+}
+
+// nodeIDReuser reuses the NodeID persisted by the previous run of the node, unless another live node has
+// registered with it.
+type nodeIDReuser struct {
+	kv       nodeIDKV
+	sessions sessionGetter
+	key      string
+	address  string
+	// maxWait is how long to wait for the previous session of the same address to expire, since the session
+	// of a crashed node lives until its lease expires
+	maxWait      time.Duration
+	pollInterval time.Duration
+
+	// persisted is the NodeID loaded, 0 if there is no record
+	persisted int64
+	// loadErr prevents overwriting the record which fails to load
+	loadErr error
+}
+
+func newNodeIDReuser(kv nodeIDKV, sessions sessionGetter, identity string, address string) *nodeIDReuser {
+	return &nodeIDReuser{
+		kv:           kv,
+		sessions:     sessions,
+		key:          path.Join(NodeIDPrefix, identity),
+		address:      address,
+		maxWait:      time.Duration(sessionutil.DefaultTTL) * time.Second,
+		pollInterval: nodeIDPollInterval,
+	}
+}
+
+// load loads the persisted NodeID, the keys are compared as a whole since they are loaded by the prefix.
+func (r *nodeIDReuser) load() {
+	keys, values, err := r.kv.LoadWithPrefix(r.key)
+	if err != nil {
+		r.loadErr = err
+		log.Warn("IndexNode failed to load the persisted NodeID", zap.String("key", r.key), zap.Error(err))
+		return
+	}
+	for idx, key := range keys {
+		if key != r.key && !strings.HasSuffix(key, "/"+r.key) {
+			continue
+		}
+		nodeID, err := strconv.ParseInt(values[idx], 10, 64)
+		if err != nil || nodeID <= 0 {
+			log.Warn("IndexNode ignores the invalid persisted NodeID", zap.String("key", r.key),
+				zap.String("value", values[idx]))
+			return
+		}
+		r.persisted = nodeID
+		return
+	}
+}
+
+// liveSession returns the live session registered with the NodeID, or nil if there is none.
+func (r *nodeIDReuser) liveSession(nodeID UniqueID) (*sessionutil.Session, error) {
+	key := typeutil.IndexNodeRole + "-" + strconv.FormatInt(nodeID, 10)
+	sessions, _, err := r.sessions.GetSessions(key)
+	if err != nil {
+		return nil, err
+	}
+	// the sessions of the NodeIDs prefixed by it are also returned
+	return sessions[key], nil
+}
+
+// reusableID returns the persisted NodeID if no live node has registered with it, or 0 to allocate a new one.
+// A live session of the same address is the previous run of this node, which is waited to expire.
+func (r *nodeIDReuser) reusableID() UniqueID {
+	r.load()
+	if r.persisted <= 0 {
+		return 0
+	}
+	deadline := time.Now().Add(r.maxWait)
+	for {
+		session, err := r.liveSession(r.persisted)
+		if err != nil {
+			log.Warn("IndexNode failed to check the session of the persisted NodeID", zap.Int64("NodeID", r.persisted),
+				zap.Error(err))
+			return 0
+		}
+		if session == nil {
+			return r.persisted
+		}
+		if session.Address != r.address {
+			log.Warn("IndexNode finds another live node registered with the persisted NodeID, allocate a new one",
+				zap.Int64("NodeID", r.persisted), zap.String("address", session.Address))
+			return 0
+		}
+		if !time.Now().Before(deadline) {
+			log.Warn("IndexNode gives up waiting for the previous session to expire, allocate a new NodeID",
+				zap.Int64("NodeID", r.persisted), zap.Duration("wait", r.maxWait))
+			return 0
+		}
+		log.Info("IndexNode waits for the previous session of the persisted NodeID to expire",
+			zap.Int64("NodeID", r.persisted))
+		time.Sleep(r.pollInterval)
+	}
+}
+
+// record persists the registered NodeID if there was no valid record, the record of another live node is
+// retained so that the node reuses its NodeID after restarting.
+func (r *nodeIDReuser) record(registered UniqueID) {
+	if registered == r.persisted {
+		log.Info("IndexNode reuses the persisted NodeID", zap.Int64("NodeID", registered))
+		return
+	}
+	if r.loadErr != nil {
+		return
+	}
+	if r.persisted > 0 {
+		session, err := r.liveSession(r.persisted)
+		if err != nil || session != nil {
+			log.Warn("IndexNode keeps the persisted NodeID of the other node", zap.Int64("persisted", r.persisted),
+				zap.Int64("NodeID", registered), zap.Error(err))
+			return
+		}
+	}
+	if err := r.kv.Save(r.key, strconv.FormatInt(registered, 10)); err != nil {
+		log.Warn("IndexNode failed to persist the NodeID", zap.Int64("NodeID", registered), zap.Error(err))
+		return
+	}
+	log.Info("IndexNode persisted the NodeID", zap.String("key", r.key), zap.Int64("NodeID", registered))
+}
+
+// registerWithPersistedNodeID registers the session with the persisted NodeID if it is reusable, and persists
+// the NodeID registered.
+func (i *IndexNode) registerWithPersistedNodeID(address string) error {
+	kv, err := etcdkv.NewEtcdKV(Params.EtcdEndpoints, Params.MetaRootPath)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+	reuser := newNodeIDReuser(newMetricsEtcdKV(kv), i.session, Params.NodeIdentity, address)
+	i.liveCh = i.session.InitWithServerID(typeutil.IndexNodeRole, address, false, reuser.reusableID())
+	reuser.record(i.session.ServerID)
+	return nil
+}
@@ -0,0 +1,247 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/server/v3/embed"
+
+	etcdkv "github.com/milvus-io/milvus/internal/kv/etcd"
+	"github.com/milvus-io/milvus/internal/util/sessionutil"
+	"github.com/milvus-io/milvus/internal/util/typeutil"
+)
+
+func freeLocalURL(t *testing.T) url.URL {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	u, err := url.Parse("http://" + listener.Addr().String())
+	assert.Nil(t, err)
+	return *u
+}
+
+// startEmbedEtcd starts an embedded etcd listening on the local ports, and returns its client endpoints.
+func startEmbedEtcd(t *testing.T) (*embed.Etcd, []string) {
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	clientURL, peerURL := freeLocalURL(t), freeLocalURL(t)
+	cfg.LCUrls, cfg.ACUrls = []url.URL{clientURL}, []url.URL{clientURL}
+	cfg.LPUrls, cfg.APUrls = []url.URL{peerURL}, []url.URL{peerURL}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+	return startEmbedEtcdWithConfig(t, cfg), []string{clientURL.Host}
+}
+
+// startEmbedEtcdWithConfig starts the embedded etcd of cfg, which restarts the closed one of the same cfg.
+func startEmbedEtcdWithConfig(t *testing.T, cfg *embed.Config) *embed.Etcd {
+	e, err := embed.StartEtcd(cfg)
+	assert.Nil(t, err)
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(30 * time.Second):
+		e.Close()
+		t.Fatal("embedded etcd took too long to start")
+	}
+	return e
+}
+
+// mockSessionGetter returns the sessions of @sessions with the key prefixed by the requested prefix.
+type mockSessionGetter struct {
+	sessions map[string]*sessionutil.Session
+	err      error
+}
+
+func (m *mockSessionGetter) GetSessions(prefix string) (map[string]*sessionutil.Session, int64, error) {
+	if m.err != nil {
+		return nil, 0, m.err
+	}
+	ret := make(map[string]*sessionutil.Session)
+	for key, session := range m.sessions {
+		if len(key) >= len(prefix) && key[:len(prefix)] == prefix {
+			ret[key] = session
+		}
+	}
+	return ret, 0, nil
+}
+
+type mockNodeIDKV struct {
+	keys    []string
+	values  []string
+	err     error
+	saveErr error
+	saved   map[string]string
+}
+
+func (m *mockNodeIDKV) LoadWithPrefix(key string) ([]string, []string, error) {
+	return m.keys, m.values, m.err
+}
+
+func (m *mockNodeIDKV) Save(key, value string) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	if m.saved == nil {
+		m.saved = make(map[string]string)
+	}
+	m.saved[key] = value
+	return nil
+}
+
+func TestNodeIDReuser(t *testing.T) {
+	newReuser := func(kv *mockNodeIDKV, sessions *mockSessionGetter) *nodeIDReuser {
+		r := newNodeIDReuser(kv, sessions, "in1", "10.0.0.1:21121")
+		r.maxWait = 50 * time.Millisecond
+		r.pollInterval = 10 * time.Millisecond
+		return r
+	}
+	liveSessions := func(nodeID UniqueID, address string) *mockSessionGetter {
+		key := typeutil.IndexNodeRole + "-" + strconv.FormatInt(nodeID, 10)
+		return &mockSessionGetter{sessions: map[string]*sessionutil.Session{key: {ServerID: nodeID, Address: address}}}
+	}
+
+	// the record of the other identity prefixed by this one is ignored
+	kv := &mockNodeIDKV{keys: []string{"root/indexnode-id/in10"}, values: []string{"10"}}
+	r := newReuser(kv, &mockSessionGetter{})
+	assert.Equal(t, UniqueID(0), r.reusableID())
+	r.record(3)
+	assert.Equal(t, "3", kv.saved[path.Join(NodeIDPrefix, "in1")])
+
+	kv = &mockNodeIDKV{keys: []string{"root/indexnode-id/in1"}, values: []string{"5"}}
+	r = newReuser(kv, liveSessions(50, "10.0.0.2:21121"))
+	assert.Equal(t, UniqueID(5), r.reusableID())
+	r.record(5)
+	assert.Nil(t, kv.saved)
+
+	// conflict with another live node
+	r = newReuser(kv, liveSessions(5, "10.0.0.2:21121"))
+	assert.Equal(t, UniqueID(0), r.reusableID())
+	r.record(7)
+	assert.Nil(t, kv.saved)
+
+	// the previous session of this node does not expire in time
+	r = newReuser(kv, liveSessions(5, "10.0.0.1:21121"))
+	assert.Equal(t, UniqueID(0), r.reusableID())
+
+	r = newReuser(kv, &mockSessionGetter{err: errors.New("etcd unavailable")})
+	assert.Equal(t, UniqueID(0), r.reusableID())
+
+	// the invalid record is overwritten
+	kv = &mockNodeIDKV{keys: []string{"root/indexnode-id/in1"}, values: []string{"abc"}}
+	r = newReuser(kv, &mockSessionGetter{})
+	assert.Equal(t, UniqueID(0), r.reusableID())
+	r.record(8)
+	assert.Equal(t, "8", kv.saved[path.Join(NodeIDPrefix, "in1")])
+
+	// the record failed to load is not overwritten
+	kv = &mockNodeIDKV{err: errors.New("etcd unavailable")}
+	r = newReuser(kv, &mockSessionGetter{})
+	assert.Equal(t, UniqueID(0), r.reusableID())
+	r.record(9)
+	assert.Nil(t, kv.saved)
+
+	kv = &mockNodeIDKV{saveErr: errors.New("etcd unavailable")}
+	r = newReuser(kv, &mockSessionGetter{})
+	assert.Equal(t, UniqueID(0), r.reusableID())
+	r.record(9)
+	assert.Nil(t, kv.saved)
+}
+
+func TestNodeIDReuser_EmbedEtcd(t *testing.T) {
+	e, endpoints := startEmbedEtcd(t)
+	defer e.Close()
+
+	metaRoot := fmt.Sprintf("node-id-test-%d", time.Now().UnixNano())
+	metaKV, err := etcdkv.NewEtcdKV(endpoints, metaRoot)
+	assert.Nil(t, err)
+	defer metaKV.Close()
+
+	// register starts a node of the identity at the address
+	register := func(ctx context.Context, identity string, address string) (*sessionutil.Session, *nodeIDReuser) {
+		session := sessionutil.NewSession(ctx, metaRoot, endpoints)
+		assert.NotNil(t, session)
+		reuser := newNodeIDReuser(metaKV, session, identity, address)
+		reuser.maxWait = 5 * time.Second
+		reuser.pollInterval = 50 * time.Millisecond
+		session.InitWithServerID(typeutil.IndexNodeRole, address, false, reuser.reusableID())
+		reuser.record(session.ServerID)
+		return session, reuser
+	}
+	// expire removes the session key as if the lease of the stopped node expires
+	expire := func(cancel context.CancelFunc, nodeID UniqueID) {
+		cancel()
+		err := metaKV.Remove(path.Join(sessionutil.DefaultServiceRoot,
+			typeutil.IndexNodeRole+"-"+strconv.FormatInt(nodeID, 10)))
+		assert.Nil(t, err)
+	}
+	persisted := func(identity string) string {
+		value, err := metaKV.Load(path.Join(NodeIDPrefix, identity))
+		assert.Nil(t, err)
+		return value
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	session1, _ := register(ctx1, "in1", "10.0.0.1:21121")
+	nodeID := session1.ServerID
+	assert.True(t, nodeID > 0)
+	assert.Equal(t, strconv.FormatInt(nodeID, 10), persisted("in1"))
+
+	// another node is allocated a new NodeID
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	session2, _ := register(ctx2, "in2", "10.0.0.2:21121")
+	assert.NotEqual(t, nodeID, session2.ServerID)
+	expire(cancel2, session2.ServerID)
+
+	t.Run("restart reuse", func(t *testing.T) {
+		expire(cancel1, nodeID)
+		ctx, cancel := context.WithCancel(context.Background())
+		session, _ := register(ctx, "in1", "10.0.0.1:21121")
+		assert.Equal(t, nodeID, session.ServerID)
+		assert.Equal(t, strconv.FormatInt(nodeID, 10), persisted("in1"))
+
+		// restarts before the previous session expires
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			expire(cancel, nodeID)
+		}()
+		ctx1, cancel1 = context.WithCancel(context.Background())
+		session, _ = register(ctx1, "in1", "10.0.0.1:21121")
+		assert.Equal(t, nodeID, session.ServerID)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		// the live node keeps the NodeID, the node of the same identity is allocated a new one
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		session, _ := register(ctx, "in1", "10.0.0.3:21121")
+		assert.NotEqual(t, nodeID, session.ServerID)
+		assert.Equal(t, strconv.FormatInt(nodeID, 10), persisted("in1"))
+
+		// registering with a NodeID taken by a live node falls back to a new one
+		ctx3, cancel3 := context.WithCancel(context.Background())
+		defer cancel3()
+		session3 := sessionutil.NewSession(ctx3, metaRoot, endpoints)
+		session3.InitWithServerID(typeutil.IndexNodeRole, "10.0.0.4:21121", false, nodeID)
+		assert.NotEqual(t, nodeID, session3.ServerID)
+		assert.NotEqual(t, session.ServerID, session3.ServerID)
+	})
+	cancel1()
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+// normalizeKey is the index param to L2-normalize the vectors before building, which is mostly asked by the
+// indexes of the IP metric on the vectors not normalized, it is handled by IndexNode and never passed to the engine.
+const normalizeKey = "normalize"
+
+// normalizeOf returns whether the vectors are normalized by @indexParams.
+func normalizeOf(indexParams map[string]string) (bool, error) {
+	value, ok := indexParams[normalizeKey]
+	if !ok {
+		return false, nil
+	}
+	normalize, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errorcode.Errorf(errorcode.InvalidParams, "invalid %s %q, expect true or false", normalizeKey, value)
+	}
+	return normalize, nil
+}
+
+// checkNormalize returns an error if the vectors of @elementType are normalized, which are not float vectors.
+func checkNormalize(normalize bool, elementType schemapb.DataType) error {
+	if normalize && elementType == schemapb.DataType_BinaryVector {
+		return errorcode.Errorf(errorcode.InvalidParams, "%s is not supported by %s", normalizeKey, elementType.String())
+	}
+	return nil
+}
+
+// normalizeVectors L2-normalizes each vector of @dim in @vectors in place, the zero vectors are left as they are.
+func normalizeVectors(vectors []float32, dim int) {
+	for offset := 0; offset+dim <= len(vectors); offset += dim {
+		vector := vectors[offset : offset+dim]
+		var norm float64
+		for _, v := range vector {
+			norm += float64(v) * float64(v)
+		}
+		if norm == 0 {
+			continue
+		}
+		scale := 1 / math.Sqrt(norm)
+		for i, v := range vector {
+			vector[i] = float32(float64(v) * scale)
+		}
+	}
+}
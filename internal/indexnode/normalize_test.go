@@ -0,0 +1,127 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+// serializedIncrementalIndex serializes the vectors fed to it as the index file.
+type serializedIncrementalIndex struct {
+	mockIncrementalIndex
+}
+
+func (index *serializedIncrementalIndex) Serialize() ([]*Blob, error) {
+	var buffer bytes.Buffer
+	if err := binary.Write(&buffer, binary.LittleEndian, index.floatData); err != nil {
+		return nil, err
+	}
+	return []*Blob{{Key: "IVF", Value: buffer.Bytes()}}, nil
+}
+
+func TestNormalizeOf(t *testing.T) {
+	normalize, err := normalizeOf(map[string]string{indexTypeKey: "IVF_FLAT"})
+	assert.Nil(t, err)
+	assert.False(t, normalize)
+	normalize, err = normalizeOf(map[string]string{normalizeKey: "true"})
+	assert.Nil(t, err)
+	assert.True(t, normalize)
+	_, err = normalizeOf(map[string]string{normalizeKey: "yes"})
+	assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+
+	assert.Nil(t, checkNormalize(true, schemapb.DataType_FloatVector))
+	assert.Nil(t, checkNormalize(true, schemapb.DataType_Float16Vector))
+	assert.Nil(t, checkNormalize(false, schemapb.DataType_BinaryVector))
+	assert.Equal(t, errorcode.InvalidParams, classifyError(checkNormalize(true, schemapb.DataType_BinaryVector)))
+}
+
+func TestNormalizeVectors(t *testing.T) {
+	vectors := []float32{3, 4, 0, 0, 0, 0, -1, 1, 1}
+	normalizeVectors(vectors, 3)
+	for offset := 0; offset < len(vectors); offset += 3 {
+		var norm float64
+		for _, v := range vectors[offset : offset+3] {
+			norm += float64(v) * float64(v)
+		}
+		if offset == 3 {
+			// the zero vector is left as it is
+			assert.Equal(t, 0.0, norm)
+			continue
+		}
+		assert.InDelta(t, 1.0, norm, 1e-6)
+	}
+	assert.InDelta(t, 0.6, vectors[0], 1e-6)
+	assert.InDelta(t, 0.8, vectors[1], 1e-6)
+}
+
+func TestBinlogPipeline_normalize(t *testing.T) {
+	build := func(normalize string) (*IndexBuildTask, *pipelineTestSegment, []*Blob) {
+		task := &IndexBuildTask{req: &indexpb.CreateIndexRequest{
+			IndexParams: []*commonpb.KeyValuePair{
+				{Key: indexTypeKey, Value: "IVF_FLAT"}, {Key: metricTypeKey, Value: "IP"}, {Key: normalizeKey, Value: normalize}},
+		}}
+		segment := newPipelineTestSegment(3, 10)
+		index := &serializedIncrementalIndex{}
+		pipeline := segment.pipeline(index, 8)
+		pipeline.check = func(value storage.FieldData) error {
+			if _, err := task.checkVectors(value); err != nil {
+				return err
+			}
+			pipeline.feeder.normalize = task.normalized
+			return nil
+		}
+		assert.Nil(t, pipeline.run(context.Background()))
+		blobs, err := index.Serialize()
+		assert.Nil(t, err)
+		return task, segment, blobs
+	}
+
+	task, segment, blobs := build("false")
+	assert.False(t, task.normalized)
+	normalizedTask, normalizedSegment, normalizedBlobs := build("true")
+	assert.True(t, normalizedTask.normalized)
+	assert.NotEqual(t, blobs[0].Value, normalizedBlobs[0].Value)
+	assert.Equal(t, len(blobs[0].Value), len(normalizedBlobs[0].Value))
+
+	// the rows of the normalized index are of the unit norm except the zero vector, and the decoded binlogs are
+	// left as they are
+	floats := make([]float32, len(normalizedBlobs[0].Value)/4)
+	assert.Nil(t, binary.Read(bytes.NewReader(normalizedBlobs[0].Value), binary.LittleEndian, floats))
+	for offset := pipelineTestDim; offset < len(floats); offset += pipelineTestDim {
+		var norm float64
+		for _, v := range floats[offset : offset+pipelineTestDim] {
+			norm += float64(v) * float64(v)
+		}
+		assert.InDelta(t, 1.0, math.Sqrt(norm), 1e-5)
+	}
+	assert.Equal(t, segment.binlogs[segment.paths[1]].data, normalizedSegment.binlogs[normalizedSegment.paths[1]].data)
+
+	// the binary vectors are never normalized
+	task = &IndexBuildTask{req: &indexpb.CreateIndexRequest{
+		IndexParams: []*commonpb.KeyValuePair{
+			{Key: indexTypeKey, Value: "BIN_IVF_FLAT"}, {Key: metricTypeKey, Value: "JACCARD"}, {Key: normalizeKey, Value: "true"}},
+	}}
+	_, err := task.checkVectors(&storage.BinaryVectorFieldData{NumRows: []int64{1}, Data: make([]byte, 1), Dim: 8})
+	assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+}
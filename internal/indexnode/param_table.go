@@ -12,6 +12,10 @@
 package indexnode
 
 import (
+	"fmt"
+	"math"
+	"net"
+	"os"
 	"path"
 	"strconv"
 	"strings"
@@ -21,11 +25,50 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/util/encryption"
 	"github.com/milvus-io/milvus/internal/util/paramtable"
+	"github.com/milvus-io/milvus/internal/util/ratelimit"
 )
 
 const (
 	StartParamsKey = "START_PARAMS"
+
+	// simdTypeOverridePrefix is the prefix of the keys overriding the simd type for an index type
+	simdTypeOverridePrefix = "knowhere.simdType."
+	// engineConfigPrefix is the prefix of the keys of the engine build configuration, knowhere.engine.<key> for
+	// all the index types and knowhere.engine.<indexType>.<key> for an index type
+	engineConfigPrefix = "knowhere.engine."
+
+	defaultMaxPendingTasks         = 1024
+	defaultTaskDiskQuota           = 100 * 1024 * 1024 * 1024
+	defaultAutoRebuildConcurrency  = 1
+	defaultTaskHeartbeatInterval   = 10
+	defaultTaskStallTimeout        = 1800
+	defaultBuildChunkRows          = 65536
+	defaultBuildPipelineBuffer     = 4
+	defaultBuildSeed               = -1
+	defaultMemorySampleInterval    = 1000
+	defaultMemoryEstimateTolerance = 2.0
+	defaultMemoryHighWatermark     = 0.9
+	defaultMemoryLowWatermark      = 0.8
+	defaultDiskMinFree             = 1024 * 1024 * 1024
+	defaultDiskResumeFree          = 2 * 1024 * 1024 * 1024
+	defaultScheduleMaxDefer        = 600
+	defaultHighMemThreshold        = 256 * 1024 * 1024 * 1024
+	defaultStartupPhaseTimeout     = 300
+	defaultSelfTestIndexType       = "IVF_FLAT"
+	defaultSelfTestTimeout         = 5
+	defaultIPRefreshInterval       = 30
+	defaultKeepaliveRetryBudget    = 60
+	defaultGracefulStopTimeout     = 30
+	defaultTracingSamplingRatio    = 1.0
+	defaultProfilingUploadPath     = "profiles"
+	defaultTaskEventFilePath       = "/var/lib/milvus/indexnode/task-events.log"
+	defaultTaskEventFileMaxSize    = 100
+	defaultTaskEventFileMaxBackups = 10
+	defaultTaskEventRetention      = 86400
+	defaultLogSamplingInitial      = 10
+	defaultLogSamplingThereafter   = 1000
 )
 
 // ParamTable is used to record configuration items.
@@ -49,10 +92,165 @@ type ParamTable struct {
 	MinIOUseSSL          bool
 	MinioBucketName      string
 
-	SimdType string
+	// ScratchPath is the local path where IndexNode keeps temporary files
+	ScratchPath string
+	// TaskDiskQuota is the max bytes of the local files written by a disk index task, 0 means unlimited
+	TaskDiskQuota int64
+
+	// MaxPendingTasks is the max number of tasks waiting in the queue, IndexNode is busy when it is reached
+	MaxPendingTasks int64
+	// CollectionWeights is the number of tasks of a collection scheduled in its round-robin turn, 1 by default
+	CollectionWeights map[UniqueID]int64
+	// SchedulePolicy is the policy of scheduling the queued tasks, fifo, fair, priority or smallest-first
+	SchedulePolicy string
+	// ScheduleMaxDefer is the max time a task is deferred by the smallest-first policy, 0 means unlimited
+	ScheduleMaxDefer time.Duration
+
+	// SimdType is the simd type set to the engine, SimdTypeRequested is the configured one,
+	// which is downgraded if it is not supported by the CPU
+	SimdType          string
+	SimdTypeRequested string
+	// EffectiveSimdType is the simd type the engine actually uses for SimdType, known once the engine initializes
+	EffectiveSimdType string
+	simdTypeErr       error
+	// StrictSimdType makes the simd type not supported by the CPU a startup error instead of downgrading it
+	StrictSimdType bool
+	// SimdTypeOverrides is the simd type of each index type overriding SimdType, the keys are upper case index types
+	SimdTypeOverrides map[string]string
+	// EngineConfig is the build configuration of the engine for all the index types, and EngineConfigOverrides is
+	// the one of each index type, the keys of which are upper case index types
+	EngineConfig          map[string]string
+	EngineConfigOverrides map[string]map[string]string
+
+	// StrictConfig rejects the invalid configurations at startup instead of ignoring them
+	StrictConfig bool
+
+	// ResumableBuild retains the index files saved by a failed build as checkpoints instead of removing them
+	ResumableBuild bool
+
+	// PersistNodeID reuses the NodeID persisted by the previous run of the node identified by NodeIdentity,
+	// which is the alias or the hostname of the node by default
+	PersistNodeID bool
+	NodeIdentity  string
+
+	// Labels are the operator-supplied labels registered in the session of the node for display
+	Labels map[string]string
+
+	// Standby starts the node as a warm spare, which rejects the build requests until it's activated
+	Standby bool
+
+	// CapabilityDiskIndex, HighMemThreshold and CapabilityTags decide the capability tags advertised by the node,
+	// see nodeCapabilities
+	CapabilityDiskIndex bool
+	HighMemThreshold    int64
+	CapabilityTags      []string
+
+	// AutoRebuildIncompatible rebuilds the indexes built by this node which are incompatible with the current engine,
+	// at startup or on request, at most AutoRebuildConcurrency indexes are rebuilt at a time
+	AutoRebuildIncompatible bool
+	AutoRebuildConcurrency  int
+
+	// StartupEtcdTimeout, StartupStorageTimeout and StartupSessionTimeout bound the phases of the startup sequence,
+	// 0 means unlimited
+	StartupEtcdTimeout    time.Duration
+	StartupStorageTimeout time.Duration
+	StartupSessionTimeout time.Duration
+
+	// SelfTestEnabled builds a tiny synthetic index of SelfTestIndexType through the whole pipeline at startup, the
+	// node never reports ready if it fails or takes longer than SelfTestTimeout
+	SelfTestEnabled   bool
+	SelfTestIndexType string
+	SelfTestTimeout   time.Duration
+
+	// KeepaliveFailurePolicy is what the node does when the keepalive of its session fails, see keepaliveFailureExit,
+	// KeepaliveRetryBudget bounds the retries of keepaliveFailureRetryThenExit, 0 means exiting at once
+	KeepaliveFailurePolicy string
+	KeepaliveRetryBudget   time.Duration
+
+	// IPRefreshEnabled re-resolves the advertised IP every IPRefreshInterval, and updates the registration if the
+	// IP changes. PreferredCIDR prefers the IP of the local interfaces within it, nil means the default local IP
+	IPRefreshEnabled  bool
+	IPRefreshInterval time.Duration
+	PreferredCIDR     *net.IPNet
+
+	// TracingEnabled traces the stages of the build tasks, the spans are sampled by TracingSamplingRatio and
+	// reported to TracingEndpoint, which overrides the jaeger reporter configured by the environment variables
+	TracingEnabled       bool
+	TracingEndpoint      string
+	TracingSamplingRatio float64
+
+	// ProfilingEnabled serves the pprof endpoints and the CaptureProfile rpc, the endpoints require the
+	// ProfilingSecret header if it's set, and the captured profiles are uploaded under ProfilingUploadPath
+	ProfilingEnabled    bool
+	ProfilingSecret     string
+	ProfilingUploadPath string
+
+	// EncryptionKeyring encrypts the index files before they are uploaded if it's not nil, whose key-encryption
+	// keys are configured by indexNode.encryption.keys or the file of them, the last one wraps the new data keys
+	EncryptionKeyring *encryption.Keyring
+
+	// TaskEventSink is where the lifecycle events of the tasks are appended, none, file or etcd, the file sink
+	// rotates TaskEventFilePath by TaskEventFileMaxSize in MB, and the etcd sink keeps the events at least
+	// TaskEventRetention
+	TaskEventSink           string
+	TaskEventFilePath       string
+	TaskEventFileMaxSize    int
+	TaskEventFileMaxBackups int
+	TaskEventRetention      time.Duration
+
+	// LogSamplingInitial and LogSamplingThereafter sample the hot log sites, e.g. the lines per binlog and chunk,
+	// the first LogSamplingInitial entries of a site in a summary window are written and then every
+	// LogSamplingThereafter-th one, 0 LogSamplingInitial disables the sampling
+	LogSamplingInitial    int
+	LogSamplingThereafter int
+
+	// EngineStatsEnabled collects the statistics of each build from the index engine, which costs some of the
+	// engines a scan of the index
+	EngineStatsEnabled bool
+
+	// TaskHeartbeatInterval is the interval of reporting the heartbeats of the in-progress tasks, 0 disables them
+	TaskHeartbeatInterval time.Duration
+	// TaskStallTimeout marks a task suspect if it stays in a stage longer than it, 0 disables the detection
+	TaskStallTimeout time.Duration
+	// GracefulStopTimeout is the longest time waiting for the tasks accepted to finish on stopping, which is shared
+	// with the grpc server waiting for the in-flight calls
+	GracefulStopTimeout time.Duration
+
+	// BuildChunkRows is the number of rows fed to the index at a time while the binlogs are still being loaded,
+	// for the index types supporting incremental add, 0 builds the index after all the binlogs are loaded
+	BuildChunkRows int
+	// BuildPipelineBufferSize is the max number of binlogs loaded but not fed to the index yet
+	BuildPipelineBufferSize int
+	// BuildSeed seeds the builds not asking for a build_seed, negative leaves them unseeded, the HNSW builds are only
+	// seeded if BuildSeedHNSW
+	BuildSeed int64
+	// BuildSeedHNSW allows seeding the HNSW builds, which run single-threaded once seeded, the HNSW builds asking for
+	// a build_seed are rejected without it, and the ones seeded by BuildSeed are left unseeded
+	BuildSeedHNSW bool
+
+	// MemorySampleInterval is the interval of sampling the memory used by a build, 0 disables the sampling
+	MemorySampleInterval time.Duration
+	// MemoryEstimateTolerance warns if the estimated memory of a build differs from the peak more than it times
+	MemoryEstimateTolerance float64
+	// MemoryHighWatermark pauses the admission of new tasks when the memory usage ratio of the node exceeds it,
+	// the admission resumes after the ratio falls below MemoryLowWatermark, 0 disables the memory watermark
+	MemoryHighWatermark float64
+	MemoryLowWatermark  float64
+	// DiskMinFree pauses the admission of new tasks when the free bytes of ScratchPath fall below it,
+	// the admission resumes after the free bytes exceed DiskResumeFree, 0 disables the disk watermark
+	DiskMinFree    int64
+	DiskResumeFree int64
+
+	// RateLimits are the limits of the rates of the calls of the rateLimitedMethods, the methods missing are not
+	// limited, it can be changed at runtime via the dynamic configuration
+	RateLimits map[string]ratelimit.Limit
 
 	CreatedTime time.Time
 	UpdatedTime time.Time
+	// ConfigRefreshes is the number of the configuration refreshes at runtime, each of which bumps UpdatedTime
+	ConfigRefreshes int64
+	// updateMu guards UpdatedTime and ConfigRefreshes which are changed by the dynamic configurations
+	updateMu sync.RWMutex
 }
 
 // Params is an alias for ParamTable.
@@ -78,6 +276,8 @@ func (pt *ParamTable) Init() {
 
 	pt.initParams()
 	pt.initKnowhereSimdType()
+	pt.initKnowhereSimdTypeOverrides()
+	pt.initKnowhereEngineConfig()
 }
 
 // InitOnce is used to initialize configuration items, and it will only be called once.
@@ -88,6 +288,8 @@ func (pt *ParamTable) InitOnce() {
 }
 
 func (pt *ParamTable) initParams() {
+	pt.initStrictConfig()
+	pt.initStrictSimdType()
 	pt.initMinIOAddress()
 	pt.initMinIOAccessKeyID()
 	pt.initMinIOSecretAccessKey()
@@ -96,9 +298,63 @@ func (pt *ParamTable) initParams() {
 	pt.initEtcdEndpoints()
 	pt.initMetaRootPath()
 	pt.initIndexRootPath()
+	pt.initScratchPath()
+	pt.initTaskDiskQuota()
+	pt.initMaxPendingTasks()
+	pt.initCollectionWeights()
+	pt.initSchedulePolicy()
+	pt.initScheduleMaxDefer()
+	pt.initResumableBuild()
+	pt.initPersistNodeID()
+	pt.initNodeIdentity()
+	pt.initLabels()
+	pt.initStandby()
+	pt.initCapabilityDiskIndex()
+	pt.initHighMemThreshold()
+	pt.initCapabilityTags()
+	pt.initAutoRebuildIncompatible()
+	pt.initAutoRebuildConcurrency()
+	pt.initStartupTimeouts()
+	pt.initSelfTest()
+	pt.initKeepaliveFailurePolicy()
+	pt.initIPRefresh()
+	pt.initPreferredCIDR()
+	pt.initTracing()
+	pt.initProfiling()
+	pt.initEncryption()
+	pt.initEngineStats()
+	pt.initTaskEvents()
+	pt.initLogSampling()
+	pt.initTaskHeartbeatInterval()
+	pt.initTaskStallTimeout()
+	pt.initGracefulStopTimeout()
+	pt.initBuildChunkRows()
+	pt.initBuildSeed()
+	pt.initBuildSeedHNSW()
+	pt.initBuildPipelineBufferSize()
+	pt.initMemorySampleInterval()
+	pt.initMemoryEstimateTolerance()
+	pt.initMemoryWatermarks()
+	pt.initDiskWatermarks()
+	pt.initRateLimits()
 	pt.initRoleName()
 }
 
+// markConfigUpdated bumps UpdatedTime and ConfigRefreshes on a refresh of the configurations at runtime.
+func (pt *ParamTable) markConfigUpdated() {
+	pt.updateMu.Lock()
+	defer pt.updateMu.Unlock()
+	pt.UpdatedTime = time.Now()
+	pt.ConfigRefreshes++
+}
+
+// lifetime returns CreatedTime, UpdatedTime and ConfigRefreshes.
+func (pt *ParamTable) lifetime() (createdTime time.Time, updatedTime time.Time, refreshes int64) {
+	pt.updateMu.RLock()
+	defer pt.updateMu.RUnlock()
+	return pt.CreatedTime, pt.UpdatedTime, pt.ConfigRefreshes
+}
+
 func (pt *ParamTable) initMinIOAddress() {
 	ret, err := pt.Load("_MinioAddress")
 	if err != nil {
@@ -170,6 +426,593 @@ func (pt *ParamTable) initMinioBucketName() {
 	pt.MinioBucketName = bucketName
 }
 
+func (pt *ParamTable) initScratchPath() {
+	scratchPath, err := pt.LoadWithDefault("indexNode.scratchPath", "/tmp/milvus/indexnode")
+	if err != nil {
+		panic(err)
+	}
+	// the instances running on the same host are separated by their aliases
+	if pt.Alias != "" {
+		scratchPath = path.Join(scratchPath, pt.Alias)
+	}
+	pt.ScratchPath = scratchPath
+}
+
+func (pt *ParamTable) initTaskDiskQuota() {
+	valueStr, err := pt.LoadWithDefault("indexNode.diskIndex.taskDiskQuota", strconv.FormatInt(defaultTaskDiskQuota, 10))
+	if err != nil {
+		panic(err)
+	}
+	taskDiskQuota, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil || taskDiskQuota < 0 {
+		log.Warn("Failed to parse indexNode.diskIndex.taskDiskQuota, use the default value",
+			zap.String("indexNode.diskIndex.taskDiskQuota", valueStr),
+			zap.Int64("default", defaultTaskDiskQuota),
+			zap.Error(err))
+		taskDiskQuota = defaultTaskDiskQuota
+	}
+	pt.TaskDiskQuota = taskDiskQuota
+}
+
+func (pt *ParamTable) initMaxPendingTasks() {
+	valueStr, err := pt.LoadWithDefault("indexNode.scheduler.maxPendingTasks", strconv.Itoa(defaultMaxPendingTasks))
+	if err != nil {
+		panic(err)
+	}
+	maxPendingTasks, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil || maxPendingTasks <= 0 {
+		log.Warn("Failed to parse indexNode.scheduler.maxPendingTasks, use the default value",
+			zap.String("indexNode.scheduler.maxPendingTasks", valueStr),
+			zap.Int("default", defaultMaxPendingTasks),
+			zap.Error(err))
+		maxPendingTasks = defaultMaxPendingTasks
+	}
+	pt.MaxPendingTasks = maxPendingTasks
+}
+
+// initCollectionWeights parses indexNode.scheduler.collectionWeights in the form of "collectionID:weight,...".
+func (pt *ParamTable) initCollectionWeights() {
+	valueStr, err := pt.LoadWithDefault("indexNode.scheduler.collectionWeights", "")
+	if err != nil {
+		panic(err)
+	}
+	pt.CollectionWeights = make(map[UniqueID]int64)
+	for _, item := range strings.Split(valueStr, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pair := strings.Split(item, ":")
+		if len(pair) != 2 {
+			log.Warn("Failed to parse indexNode.scheduler.collectionWeights, ignore the item", zap.String("item", item))
+			continue
+		}
+		collectionID, err1 := strconv.ParseInt(strings.TrimSpace(pair[0]), 10, 64)
+		weight, err2 := strconv.ParseInt(strings.TrimSpace(pair[1]), 10, 64)
+		if err1 != nil || err2 != nil || weight <= 0 {
+			log.Warn("Failed to parse indexNode.scheduler.collectionWeights, ignore the item", zap.String("item", item))
+			continue
+		}
+		pt.CollectionWeights[collectionID] = weight
+	}
+}
+
+func (pt *ParamTable) initSchedulePolicy() {
+	valueStr, err := pt.LoadWithDefault("indexNode.scheduler.policy", defaultSchedulePolicy)
+	if err != nil {
+		panic(err)
+	}
+	policy, err := normalizeSchedulePolicy(valueStr)
+	if err != nil {
+		log.Warn("Failed to parse indexNode.scheduler.policy, use the default value",
+			zap.String("indexNode.scheduler.policy", valueStr),
+			zap.String("default", defaultSchedulePolicy),
+			zap.Error(err))
+		policy = defaultSchedulePolicy
+	}
+	pt.SchedulePolicy = policy
+}
+
+func (pt *ParamTable) initScheduleMaxDefer() {
+	pt.ScheduleMaxDefer = pt.parseSeconds("indexNode.scheduler.maxDeferSeconds", defaultScheduleMaxDefer)
+}
+
+func (pt *ParamTable) initResumableBuild() {
+	pt.ResumableBuild = pt.ParseBool("indexNode.resumableBuild", false)
+}
+
+func (pt *ParamTable) initPersistNodeID() {
+	pt.PersistNodeID = pt.ParseBool("indexNode.nodeID.persist", false)
+}
+
+func (pt *ParamTable) initNodeIdentity() {
+	identity, err := pt.LoadWithDefault("indexNode.nodeID.identity", "")
+	if err != nil {
+		panic(err)
+	}
+	identity = strings.TrimSpace(identity)
+	if identity == "" {
+		identity = pt.Alias
+	}
+	if identity == "" {
+		if identity, err = os.Hostname(); err != nil {
+			log.Warn("Failed to get the hostname as the identity of the node", zap.Error(err))
+		}
+	}
+	pt.NodeIdentity = identity
+}
+
+func (pt *ParamTable) initLabels() {
+	valueStr, err := pt.LoadWithDefault("indexNode.labels", "")
+	if err != nil {
+		panic(err)
+	}
+	pt.Labels = make(map[string]string)
+	for _, item := range strings.Split(valueStr, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pair := strings.SplitN(item, ":", 2)
+		if len(pair) != 2 || strings.TrimSpace(pair[0]) == "" {
+			log.Warn("Failed to parse indexNode.labels, ignore the item", zap.String("item", item))
+			continue
+		}
+		pt.Labels[strings.TrimSpace(pair[0])] = strings.TrimSpace(pair[1])
+	}
+}
+
+func (pt *ParamTable) initStandby() {
+	pt.Standby = pt.ParseBool("indexNode.standby", false)
+}
+
+func (pt *ParamTable) initCapabilityDiskIndex() {
+	pt.CapabilityDiskIndex = pt.ParseBool("indexNode.capabilities.diskIndex", true)
+}
+
+func (pt *ParamTable) initHighMemThreshold() {
+	valueStr, err := pt.LoadWithDefault("indexNode.capabilities.highMemThreshold", strconv.FormatInt(defaultHighMemThreshold, 10))
+	if err != nil {
+		panic(err)
+	}
+	threshold, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil || threshold < 0 {
+		log.Warn("Failed to parse indexNode.capabilities.highMemThreshold, use the default value",
+			zap.String("indexNode.capabilities.highMemThreshold", valueStr),
+			zap.Int64("default", defaultHighMemThreshold),
+			zap.Error(err))
+		threshold = defaultHighMemThreshold
+	}
+	pt.HighMemThreshold = threshold
+}
+
+func (pt *ParamTable) initCapabilityTags() {
+	valueStr, err := pt.LoadWithDefault("indexNode.capabilities.tags", "")
+	if err != nil {
+		panic(err)
+	}
+	pt.CapabilityTags = make([]string, 0)
+	for _, tag := range strings.Split(valueStr, ",") {
+		if tag = normalizeCapability(tag); tag != "" {
+			pt.CapabilityTags = append(pt.CapabilityTags, tag)
+		}
+	}
+}
+
+func (pt *ParamTable) initAutoRebuildIncompatible() {
+	pt.AutoRebuildIncompatible = pt.ParseBool("indexNode.autoRebuildIncompatible", false)
+}
+
+func (pt *ParamTable) initAutoRebuildConcurrency() {
+	valueStr, err := pt.LoadWithDefault("indexNode.autoRebuildConcurrency", strconv.Itoa(defaultAutoRebuildConcurrency))
+	if err != nil {
+		panic(err)
+	}
+	concurrency, err := strconv.Atoi(valueStr)
+	if err != nil || concurrency <= 0 {
+		log.Warn("Failed to parse indexNode.autoRebuildConcurrency, use the default value",
+			zap.String("indexNode.autoRebuildConcurrency", valueStr),
+			zap.Int("default", defaultAutoRebuildConcurrency),
+			zap.Error(err))
+		concurrency = defaultAutoRebuildConcurrency
+	}
+	pt.AutoRebuildConcurrency = concurrency
+}
+
+func (pt *ParamTable) initStartupTimeouts() {
+	pt.StartupEtcdTimeout = pt.parseSeconds("indexNode.startup.etcdTimeout", defaultStartupPhaseTimeout)
+	pt.StartupStorageTimeout = pt.parseSeconds("indexNode.startup.storageTimeout", defaultStartupPhaseTimeout)
+	pt.StartupSessionTimeout = pt.parseSeconds("indexNode.startup.sessionTimeout", defaultStartupPhaseTimeout)
+}
+
+func (pt *ParamTable) initSelfTest() {
+	pt.SelfTestEnabled = pt.ParseBool("indexNode.selfTest.enabled", false)
+	indexType, err := pt.LoadWithDefault("indexNode.selfTest.indexType", defaultSelfTestIndexType)
+	if err != nil {
+		panic(err)
+	}
+	indexType = strings.ToUpper(strings.TrimSpace(indexType))
+	if !isSelfTestIndexType(indexType) {
+		log.Warn("Failed to parse indexNode.selfTest.indexType, use the default value",
+			zap.String("indexNode.selfTest.indexType", indexType),
+			zap.String("default", defaultSelfTestIndexType),
+			zap.Strings("supported", selfTestIndexTypes))
+		indexType = defaultSelfTestIndexType
+	}
+	pt.SelfTestIndexType = indexType
+	pt.SelfTestTimeout = pt.parseSeconds("indexNode.selfTest.timeout", defaultSelfTestTimeout)
+}
+
+func (pt *ParamTable) initKeepaliveFailurePolicy() {
+	policy, err := pt.LoadWithDefault("indexNode.session.onKeepaliveFailure", keepaliveFailureExit)
+	if err != nil {
+		panic(err)
+	}
+	policy = strings.ToLower(strings.TrimSpace(policy))
+	switch policy {
+	case keepaliveFailureExit, keepaliveFailureRetryForever, keepaliveFailureRetryThenExit:
+	default:
+		log.Warn("Failed to parse indexNode.session.onKeepaliveFailure, use the default value",
+			zap.String("indexNode.session.onKeepaliveFailure", policy),
+			zap.String("default", keepaliveFailureExit))
+		policy = keepaliveFailureExit
+	}
+	pt.KeepaliveFailurePolicy = policy
+	pt.KeepaliveRetryBudget = pt.parseSeconds("indexNode.session.retryBudget", defaultKeepaliveRetryBudget)
+}
+
+func (pt *ParamTable) initIPRefresh() {
+	pt.IPRefreshEnabled = pt.ParseBool("indexNode.ipRefresh.enabled", true)
+	pt.IPRefreshInterval = pt.parseSeconds("indexNode.ipRefresh.interval", defaultIPRefreshInterval)
+	if pt.IPRefreshInterval == 0 {
+		pt.IPRefreshEnabled = false
+	}
+}
+
+func (pt *ParamTable) initPreferredCIDR() {
+	cidr, err := pt.LoadWithDefault("indexNode.ipRefresh.preferredCIDR", "")
+	if err != nil {
+		panic(err)
+	}
+	pt.PreferredCIDR = nil
+	cidr = strings.TrimSpace(cidr)
+	if cidr == "" {
+		return
+	}
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		log.Warn("Failed to parse indexNode.ipRefresh.preferredCIDR, use the default local IP",
+			zap.String("indexNode.ipRefresh.preferredCIDR", cidr),
+			zap.Error(err))
+		return
+	}
+	pt.PreferredCIDR = ipNet
+}
+
+func (pt *ParamTable) initTracing() {
+	pt.TracingEnabled = pt.ParseBool("indexNode.tracing.enabled", false)
+	endpoint, err := pt.LoadWithDefault("indexNode.tracing.endpoint", "")
+	if err != nil {
+		panic(err)
+	}
+	pt.TracingEndpoint = strings.TrimSpace(endpoint)
+	pt.TracingSamplingRatio = pt.parseRatio("indexNode.tracing.samplingRatio", defaultTracingSamplingRatio)
+}
+
+func (pt *ParamTable) initProfiling() {
+	pt.ProfilingEnabled = pt.ParseBool("indexNode.profiling.enabled", false)
+	secret, err := pt.LoadWithDefault("indexNode.profiling.secret", "")
+	if err != nil {
+		panic(err)
+	}
+	pt.ProfilingSecret = secret
+	uploadPath, err := pt.LoadWithDefault("indexNode.profiling.uploadPath", defaultProfilingUploadPath)
+	if err != nil {
+		panic(err)
+	}
+	pt.ProfilingUploadPath = strings.Trim(strings.TrimSpace(uploadPath), "/")
+	if pt.ProfilingUploadPath == "" {
+		pt.ProfilingUploadPath = defaultProfilingUploadPath
+	}
+}
+
+// initEncryption panics on the invalid keys if the encryption is enabled, rather than uploading the index files
+// in plaintext.
+func (pt *ParamTable) initEncryption() {
+	pt.EncryptionKeyring = nil
+	if !pt.ParseBool("indexNode.encryption.enabled", false) {
+		return
+	}
+	keys, err := pt.LoadWithDefault("indexNode.encryption.keys", "")
+	if err != nil {
+		panic(err)
+	}
+	keysFile, err := pt.LoadWithDefault("indexNode.encryption.keysFile", "")
+	if err != nil {
+		panic(err)
+	}
+	keks, err := encryption.LoadKeys(keys, keysFile)
+	if err != nil {
+		panic(fmt.Errorf("invalid indexNode.encryption.keys: %w", err))
+	}
+	keyring, err := encryption.NewKeyring(keks)
+	if err != nil {
+		panic(fmt.Errorf("invalid indexNode.encryption.keys: %w", err))
+	}
+	pt.EncryptionKeyring = keyring
+	log.Debug("initEncryption", zap.Strings("keyIDs", keyIDsOf(keks)), zap.String("activeKeyID", keyring.ActiveKeyID()))
+}
+
+func keyIDsOf(keks []encryption.KEK) []string {
+	ids := make([]string, 0, len(keks))
+	for _, kek := range keks {
+		ids = append(ids, kek.ID)
+	}
+	return ids
+}
+
+func (pt *ParamTable) initEngineStats() {
+	pt.EngineStatsEnabled = pt.ParseBool("indexNode.engineStats.enabled", false)
+}
+
+func (pt *ParamTable) initTaskEvents() {
+	sink, err := pt.LoadWithDefault("indexNode.taskEvents.sink", taskEventSinkNone)
+	if err != nil {
+		panic(err)
+	}
+	sink = strings.ToLower(strings.TrimSpace(sink))
+	switch sink {
+	case taskEventSinkNone, taskEventSinkFile, taskEventSinkEtcd:
+	default:
+		log.Warn("Failed to parse indexNode.taskEvents.sink, the task events are not recorded",
+			zap.String("indexNode.taskEvents.sink", sink))
+		sink = taskEventSinkNone
+	}
+	pt.TaskEventSink = sink
+
+	filePath, err := pt.LoadWithDefault("indexNode.taskEvents.file.path", defaultTaskEventFilePath)
+	if err != nil {
+		panic(err)
+	}
+	pt.TaskEventFilePath = strings.TrimSpace(filePath)
+	if pt.TaskEventFilePath == "" {
+		pt.TaskEventFilePath = defaultTaskEventFilePath
+	}
+	pt.TaskEventFileMaxSize = pt.parsePositiveInt("indexNode.taskEvents.file.maxSize", defaultTaskEventFileMaxSize)
+	pt.TaskEventFileMaxBackups = pt.parsePositiveInt("indexNode.taskEvents.file.maxBackups", defaultTaskEventFileMaxBackups)
+	pt.TaskEventRetention = pt.parseSeconds("indexNode.taskEvents.etcd.retention", defaultTaskEventRetention)
+	if pt.TaskEventRetention <= 0 {
+		pt.TaskEventRetention = defaultTaskEventRetention * time.Second
+	}
+}
+
+func (pt *ParamTable) initRateLimits() {
+	value, err := pt.LoadWithDefault("indexNode.rateLimit.limits", defaultRateLimits)
+	if err != nil {
+		panic(err)
+	}
+	limits, err := parseRateLimits(value)
+	if err != nil {
+		log.Warn("Failed to parse indexNode.rateLimit.limits, set to default", zap.Error(err))
+		limits, _ = parseRateLimits(defaultRateLimits)
+	}
+	pt.RateLimits = limits
+}
+
+func (pt *ParamTable) initLogSampling() {
+	pt.LogSamplingInitial = pt.parseNonNegativeInt("log.sampling.initial", defaultLogSamplingInitial)
+	pt.LogSamplingThereafter = pt.parseNonNegativeInt("log.sampling.thereafter", defaultLogSamplingThereafter)
+}
+
+func (pt *ParamTable) initTaskHeartbeatInterval() {
+	pt.TaskHeartbeatInterval = pt.parseSeconds("indexNode.taskHeartbeat.interval", defaultTaskHeartbeatInterval)
+}
+
+func (pt *ParamTable) initTaskStallTimeout() {
+	pt.TaskStallTimeout = pt.parseSeconds("indexNode.taskHeartbeat.stallTimeout", defaultTaskStallTimeout)
+}
+
+func (pt *ParamTable) initGracefulStopTimeout() {
+	pt.GracefulStopTimeout = pt.parseSeconds("indexNode.grpc.gracefulStopTimeout", defaultGracefulStopTimeout)
+}
+
+func (pt *ParamTable) initBuildChunkRows() {
+	valueStr, err := pt.LoadWithDefault("indexNode.pipeline.chunkRows", strconv.Itoa(defaultBuildChunkRows))
+	if err != nil {
+		panic(err)
+	}
+	chunkRows, err := strconv.Atoi(valueStr)
+	if err != nil || chunkRows < 0 {
+		log.Warn("Failed to parse indexNode.pipeline.chunkRows, use the default value",
+			zap.String("indexNode.pipeline.chunkRows", valueStr),
+			zap.Int("default", defaultBuildChunkRows),
+			zap.Error(err))
+		chunkRows = defaultBuildChunkRows
+	}
+	pt.BuildChunkRows = chunkRows
+}
+
+func (pt *ParamTable) initBuildSeed() {
+	valueStr, err := pt.LoadWithDefault("indexNode.build.seed", strconv.Itoa(defaultBuildSeed))
+	if err != nil {
+		panic(err)
+	}
+	seed, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil || seed > math.MaxInt32 {
+		log.Warn("Failed to parse indexNode.build.seed, use the default value",
+			zap.String("indexNode.build.seed", valueStr),
+			zap.Int("default", defaultBuildSeed),
+			zap.Error(err))
+		seed = defaultBuildSeed
+	}
+	pt.BuildSeed = seed
+}
+
+func (pt *ParamTable) initBuildSeedHNSW() {
+	pt.BuildSeedHNSW = pt.ParseBool("indexNode.build.seedHNSW", false)
+}
+
+func (pt *ParamTable) initBuildPipelineBufferSize() {
+	valueStr, err := pt.LoadWithDefault("indexNode.pipeline.bufferSize", strconv.Itoa(defaultBuildPipelineBuffer))
+	if err != nil {
+		panic(err)
+	}
+	bufferSize, err := strconv.Atoi(valueStr)
+	if err != nil || bufferSize <= 0 {
+		log.Warn("Failed to parse indexNode.pipeline.bufferSize, use the default value",
+			zap.String("indexNode.pipeline.bufferSize", valueStr),
+			zap.Int("default", defaultBuildPipelineBuffer),
+			zap.Error(err))
+		bufferSize = defaultBuildPipelineBuffer
+	}
+	pt.BuildPipelineBufferSize = bufferSize
+}
+
+func (pt *ParamTable) initMemorySampleInterval() {
+	valueStr, err := pt.LoadWithDefault("indexNode.memory.sampleIntervalMs", strconv.Itoa(defaultMemorySampleInterval))
+	if err != nil {
+		panic(err)
+	}
+	interval, err := strconv.Atoi(valueStr)
+	if err != nil || interval < 0 {
+		log.Warn("Failed to parse indexNode.memory.sampleIntervalMs, use the default value",
+			zap.String("indexNode.memory.sampleIntervalMs", valueStr),
+			zap.Int("default", defaultMemorySampleInterval),
+			zap.Error(err))
+		interval = defaultMemorySampleInterval
+	}
+	pt.MemorySampleInterval = time.Duration(interval) * time.Millisecond
+}
+
+func (pt *ParamTable) initMemoryEstimateTolerance() {
+	valueStr, err := pt.LoadWithDefault("indexNode.memory.estimateTolerance", strconv.FormatFloat(defaultMemoryEstimateTolerance, 'f', -1, 64))
+	if err != nil {
+		panic(err)
+	}
+	tolerance, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil || tolerance < 1 {
+		log.Warn("Failed to parse indexNode.memory.estimateTolerance, use the default value",
+			zap.String("indexNode.memory.estimateTolerance", valueStr),
+			zap.Float64("default", defaultMemoryEstimateTolerance),
+			zap.Error(err))
+		tolerance = defaultMemoryEstimateTolerance
+	}
+	pt.MemoryEstimateTolerance = tolerance
+}
+
+// parseRatio parses the ratio in [0, 1] of @key, the invalid value is replaced by @defaultValue.
+func (pt *ParamTable) parseRatio(key string, defaultValue float64) float64 {
+	valueStr, err := pt.LoadWithDefault(key, strconv.FormatFloat(defaultValue, 'f', -1, 64))
+	if err != nil {
+		panic(err)
+	}
+	ratio, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		log.Warn("Failed to parse "+key+", use the default value",
+			zap.String(key, valueStr),
+			zap.Float64("default", defaultValue),
+			zap.Error(err))
+		ratio = defaultValue
+	}
+	return ratio
+}
+
+func (pt *ParamTable) initMemoryWatermarks() {
+	pt.MemoryHighWatermark = pt.parseRatio("indexNode.memory.highWatermark", defaultMemoryHighWatermark)
+	pt.MemoryLowWatermark = pt.parseRatio("indexNode.memory.lowWatermark", defaultMemoryLowWatermark)
+	if pt.MemoryLowWatermark > pt.MemoryHighWatermark {
+		log.Warn("indexNode.memory.lowWatermark is greater than indexNode.memory.highWatermark, use the high watermark",
+			zap.Float64("indexNode.memory.lowWatermark", pt.MemoryLowWatermark),
+			zap.Float64("indexNode.memory.highWatermark", pt.MemoryHighWatermark))
+		pt.MemoryLowWatermark = pt.MemoryHighWatermark
+	}
+}
+
+// parseBytes parses the non-negative bytes of @key, the invalid value is replaced by @defaultValue.
+func (pt *ParamTable) parseBytes(key string, defaultValue int64) int64 {
+	valueStr, err := pt.LoadWithDefault(key, strconv.FormatInt(defaultValue, 10))
+	if err != nil {
+		panic(err)
+	}
+	bytes, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil || bytes < 0 {
+		log.Warn("Failed to parse "+key+", use the default value",
+			zap.String(key, valueStr),
+			zap.Int64("default", defaultValue),
+			zap.Error(err))
+		bytes = defaultValue
+	}
+	return bytes
+}
+
+func (pt *ParamTable) initDiskWatermarks() {
+	pt.DiskMinFree = pt.parseBytes("indexNode.disk.minFree", defaultDiskMinFree)
+	pt.DiskResumeFree = pt.parseBytes("indexNode.disk.resumeFree", defaultDiskResumeFree)
+	if pt.DiskResumeFree < pt.DiskMinFree {
+		log.Warn("indexNode.disk.resumeFree is less than indexNode.disk.minFree, use the min free",
+			zap.Int64("indexNode.disk.resumeFree", pt.DiskResumeFree),
+			zap.Int64("indexNode.disk.minFree", pt.DiskMinFree))
+		pt.DiskResumeFree = pt.DiskMinFree
+	}
+}
+
+// parseSeconds parses the non-negative duration in seconds of @key, the invalid value is replaced by @defaultValue.
+func (pt *ParamTable) parsePositiveInt(key string, defaultValue int) int {
+	valueStr, err := pt.LoadWithDefault(key, strconv.Itoa(defaultValue))
+	if err != nil {
+		panic(err)
+	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value <= 0 {
+		log.Warn("Failed to parse "+key+", use the default value",
+			zap.String(key, valueStr),
+			zap.Int("default", defaultValue),
+			zap.Error(err))
+		value = defaultValue
+	}
+	return value
+}
+
+func (pt *ParamTable) parseNonNegativeInt(key string, defaultValue int) int {
+	valueStr, err := pt.LoadWithDefault(key, strconv.Itoa(defaultValue))
+	if err != nil {
+		panic(err)
+	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 0 {
+		log.Warn("Failed to parse "+key+", use the default value",
+			zap.String(key, valueStr),
+			zap.Int("default", defaultValue),
+			zap.Error(err))
+		value = defaultValue
+	}
+	return value
+}
+
+func (pt *ParamTable) parseSeconds(key string, defaultValue int64) time.Duration {
+	valueStr, err := pt.LoadWithDefault(key, strconv.FormatInt(defaultValue, 10))
+	if err != nil {
+		panic(err)
+	}
+	seconds, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil || seconds < 0 {
+		log.Warn("Failed to parse "+key+", use the default value",
+			zap.String(key, valueStr),
+			zap.Int64("default", defaultValue),
+			zap.Error(err))
+		seconds = defaultValue
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func (pt *ParamTable) initStrictConfig() {
+	pt.StrictConfig = pt.ParseBool("indexNode.strictConfig", false)
+}
+
+func (pt *ParamTable) initStrictSimdType() {
+	pt.StrictSimdType = pt.ParseBool("indexNode.strictSimdType", false)
+}
+
 func (pt *ParamTable) initRoleName() {
 	pt.RoleName = "indexnode"
 }
@@ -183,8 +1026,107 @@ func (pt *ParamTable) initKnowhereSimdType() {
 		panic(err)
 	}
 
-	pt.SimdType = simdType
+	pt.SimdTypeRequested = simdType
+	normalized, err := normalizeSimdType(simdType)
+	pt.simdTypeErr = nil
+	if err != nil {
+		// the invalid simd type fails the initialization of IndexNode
+		pt.simdTypeErr = fmt.Errorf("knowhere.simdType: %s", err.Error())
+		log.Error("invalid simd type", zap.Error(pt.simdTypeErr))
+		pt.SimdType = ""
+		return
+	}
+	pt.SimdType = pt.resolveSimdType(normalized, "knowhere.simdType")
 
 	log.Debug("initialize the knowhere simd type",
+		zap.String("requested_simd_type", pt.SimdTypeRequested),
 		zap.String("simd_type", pt.SimdType))
 }
+
+// resolveSimdType downgrades the simd type not supported by the CPU, or panics if StrictSimdType is on.
+func (pt *ParamTable) resolveSimdType(simdType string, key string) string {
+	resolved, mismatch := resolveSimdType(simdType, detectCPUFeatures())
+	if mismatch != nil {
+		if pt.StrictSimdType {
+			panic(fmt.Errorf("%s: %s", key, mismatch.Error()))
+		}
+		log.Warn("!!! the configured simd type is not supported by the CPU of this node, "+
+			"the index building may be slower than expected !!!",
+			zap.String("key", key),
+			zap.String("requested_simd_type", simdType),
+			zap.String("simd_type", resolved),
+			zap.Error(mismatch))
+	}
+	return resolved
+}
+
+// initKnowhereSimdTypeOverrides loads knowhere.simdType.<indexType>, the overrides with unknown index types or
+// invalid simd types are ignored, or rejected if StrictConfig is on.
+func (pt *ParamTable) initKnowhereSimdTypeOverrides() {
+	prefix := strings.ToLower(simdTypeOverridePrefix)
+	// all the keys are lower case, "/" is the next character of "." so the range covers the keys with the prefix
+	keys, values, err := pt.LoadRange(prefix, strings.TrimSuffix(prefix, ".")+"/", 0)
+	if err != nil {
+		panic(err)
+	}
+	pt.SimdTypeOverrides = make(map[string]string, len(keys))
+	for idx, key := range keys {
+		indexType := strings.ToUpper(strings.TrimPrefix(key, prefix))
+		simdType, invalidErr := normalizeSimdType(values[idx])
+		if !isKnownIndexType(indexType) {
+			invalidErr = fmt.Errorf("unknown index type %s in %s", indexType, key)
+		} else if invalidErr != nil {
+			invalidErr = fmt.Errorf("%s: %s", key, invalidErr.Error())
+		}
+		if invalidErr != nil {
+			if pt.StrictConfig {
+				panic(invalidErr)
+			}
+			log.Warn("ignore the invalid simd type override", zap.Error(invalidErr))
+			continue
+		}
+		pt.SimdTypeOverrides[indexType] = pt.resolveSimdType(simdType, key)
+	}
+	log.Debug("initialize the knowhere simd type overrides",
+		zap.Any("simd_type_overrides", pt.SimdTypeOverrides))
+}
+
+// initKnowhereEngineConfig loads knowhere.engine.*, the keys are passed to the engine as is which validates them,
+// the ones of unknown index types are ignored, or rejected if StrictConfig is on.
+func (pt *ParamTable) initKnowhereEngineConfig() {
+	prefix := strings.ToLower(engineConfigPrefix)
+	keys, values, err := pt.LoadRange(prefix, strings.TrimSuffix(prefix, ".")+"/", 0)
+	if err != nil {
+		panic(err)
+	}
+	pt.EngineConfig = make(map[string]string)
+	pt.EngineConfigOverrides = make(map[string]map[string]string)
+	for idx, key := range keys {
+		name := strings.TrimPrefix(key, prefix)
+		sep := strings.Index(name, ".")
+		if sep < 0 {
+			pt.EngineConfig[name] = values[idx]
+			continue
+		}
+		indexType := strings.ToUpper(name[:sep])
+		if !isKnownIndexType(indexType) {
+			invalidErr := fmt.Errorf("unknown index type %s in %s", indexType, key)
+			if pt.StrictConfig {
+				panic(invalidErr)
+			}
+			log.Warn("ignore the invalid engine config", zap.Error(invalidErr))
+			continue
+		}
+		if pt.EngineConfigOverrides[indexType] == nil {
+			pt.EngineConfigOverrides[indexType] = make(map[string]string)
+		}
+		pt.EngineConfigOverrides[indexType][name[sep+1:]] = values[idx]
+	}
+	log.Debug("initialize the knowhere engine config", zap.Any("engine_config", pt.EngineConfig),
+		zap.Any("engine_config_overrides", pt.EngineConfigOverrides))
+}
+
+// simdTypeOf returns the simd type configured for the index type, or an empty string if it is not overridden.
+func (pt *ParamTable) simdTypeOf(indexType string) string {
+	return pt.SimdTypeOverrides[strings.ToUpper(indexType)]
+}
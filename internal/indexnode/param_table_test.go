@@ -12,8 +12,23 @@
 package indexnode
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/util/encryption"
+	"github.com/milvus-io/milvus/internal/util/ratelimit"
 )
 
 func TestParamTable(t *testing.T) {
@@ -85,6 +100,753 @@ func TestParamTable(t *testing.T) {
 	t.Run("IndexRootPath", func(t *testing.T) {
 		t.Logf("IndexRootPath: %v", Params.IndexRootPath)
 	})
+
+	t.Run("ScratchPath", func(t *testing.T) {
+		t.Logf("ScratchPath: %v", Params.ScratchPath)
+
+		oldAlias, oldScratchPath := Params.Alias, Params.ScratchPath
+		defer func() {
+			Params.Alias, Params.ScratchPath = oldAlias, oldScratchPath
+		}()
+		Params.Alias = ""
+		Params.initScratchPath()
+		basePath := Params.ScratchPath
+		Params.Alias = "in-1"
+		Params.initScratchPath()
+		assert.Equal(t, path.Join(basePath, "in-1"), Params.ScratchPath)
+	})
+
+	t.Run("LogFile", func(t *testing.T) {
+		t.Logf("Log: %+v", Params.Log)
+
+		dir, err := ioutil.TempDir("", "indexnode-log")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+		values := map[string]string{
+			"log.file.rootPath":   dir,
+			"log.file.maxSize":    "1",
+			"log.file.maxBackups": "3",
+			"log.format":          "json",
+			"log.stdout":          "true",
+		}
+		olds := make(map[string]string, len(values))
+		for key, value := range values {
+			olds[key], _ = Params.LoadWithDefault(key, "")
+			assert.Nil(t, Params.Save(key, value))
+		}
+		oldLogConfigFunction := Params.LogConfigFunction
+		defer func() {
+			for key, old := range olds {
+				_ = Params.Save(key, old)
+			}
+			Params.InitLogCfg()
+			Params.LogConfigFunction = oldLogConfigFunction
+		}()
+
+		var cfg log.Config
+		Params.InitLogCfg()
+		Params.SetLogConfig(func(c log.Config) { cfg = c })
+		Params.SetLogger(7)
+		assert.Equal(t, path.Join(dir, "indexnode-7.log"), cfg.File.Filename)
+		assert.Equal(t, 1, cfg.File.MaxSize)
+		assert.Equal(t, 3, cfg.File.MaxBackups)
+		assert.Equal(t, "json", cfg.Format)
+		assert.True(t, cfg.Stdout)
+
+		// only the file is examined, keep the megabytes of logs out of stdout
+		cfg.Stdout = false
+		logger, _, err := log.InitLogger(&cfg)
+		assert.Nil(t, err)
+		// the build workers write concurrently past the size threshold
+		msg := strings.Repeat("d", 1000)
+		var wg sync.WaitGroup
+		for worker := 0; worker < 8; worker++ {
+			wg.Add(1)
+			go func(worker int) {
+				defer wg.Done()
+				for n := 0; n < 150; n++ {
+					logger.Info(msg, zap.Int("worker", worker))
+				}
+			}(worker)
+		}
+		wg.Wait()
+		assert.Nil(t, logger.Sync())
+
+		files, err := ioutil.ReadDir(dir)
+		assert.Nil(t, err)
+		assert.Len(t, files, 2)
+		rotated := false
+		for _, file := range files {
+			if file.Name() != "indexnode-7.log" {
+				rotated = strings.HasPrefix(file.Name(), "indexnode-7-")
+			}
+			assert.LessOrEqual(t, file.Size(), int64(1024*1024))
+		}
+		assert.True(t, rotated)
+
+		// every line is kept whole under the concurrent writes
+		lines := 0
+		for _, file := range files {
+			content, err := ioutil.ReadFile(path.Join(dir, file.Name()))
+			assert.Nil(t, err)
+			for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+				entry := make(map[string]interface{})
+				assert.Nil(t, json.Unmarshal([]byte(line), &entry))
+				assert.Equal(t, msg, entry["message"])
+				lines++
+			}
+		}
+		assert.Equal(t, 8*150, lines)
+	})
+
+	t.Run("TaskDiskQuota", func(t *testing.T) {
+		t.Logf("TaskDiskQuota: %v", Params.TaskDiskQuota)
+	})
+
+	t.Run("MaxPendingTasks", func(t *testing.T) {
+		t.Logf("MaxPendingTasks: %v", Params.MaxPendingTasks)
+	})
+
+	t.Run("AutoRebuild", func(t *testing.T) {
+		t.Logf("AutoRebuildIncompatible: %v, AutoRebuildConcurrency: %v",
+			Params.AutoRebuildIncompatible, Params.AutoRebuildConcurrency)
+
+		key := "indexNode.autoRebuildConcurrency"
+		old, _ := Params.LoadWithDefault(key, "")
+		defer func() {
+			_ = Params.Save(key, old)
+			Params.initAutoRebuildConcurrency()
+		}()
+		err := Params.Save(key, "4")
+		assert.Nil(t, err)
+		Params.initAutoRebuildConcurrency()
+		assert.Equal(t, 4, Params.AutoRebuildConcurrency)
+		err = Params.Save(key, "0")
+		assert.Nil(t, err)
+		Params.initAutoRebuildConcurrency()
+		assert.Equal(t, defaultAutoRebuildConcurrency, Params.AutoRebuildConcurrency)
+	})
+
+	t.Run("NodeIdentity", func(t *testing.T) {
+		t.Logf("PersistNodeID: %v, NodeIdentity: %v", Params.PersistNodeID, Params.NodeIdentity)
+
+		key := "indexNode.nodeID.identity"
+		old, _ := Params.LoadWithDefault(key, "")
+		oldAlias := Params.Alias
+		defer func() {
+			_ = Params.Save(key, old)
+			Params.Alias = oldAlias
+			Params.initNodeIdentity()
+		}()
+		err := Params.Save(key, " in1 ")
+		assert.Nil(t, err)
+		Params.initNodeIdentity()
+		assert.Equal(t, "in1", Params.NodeIdentity)
+		err = Params.Save(key, "")
+		assert.Nil(t, err)
+		Params.Alias = "indexnode-alias"
+		Params.initNodeIdentity()
+		assert.Equal(t, "indexnode-alias", Params.NodeIdentity)
+	})
+
+	t.Run("Labels", func(t *testing.T) {
+		t.Logf("Labels: %v", Params.Labels)
+
+		key := "indexNode.labels"
+		old, _ := Params.LoadWithDefault(key, "")
+		defer func() {
+			_ = Params.Save(key, old)
+			Params.initLabels()
+		}()
+		err := Params.Save(key, "zone: zone-a, url:http://host:80,invalid,:empty")
+		assert.Nil(t, err)
+		Params.initLabels()
+		assert.Equal(t, map[string]string{"zone": "zone-a", "url": "http://host:80"}, Params.Labels)
+	})
+
+	t.Run("Standby", func(t *testing.T) {
+		t.Logf("Standby: %v", Params.Standby)
+		assert.False(t, Params.Standby)
+	})
+
+	t.Run("Capabilities", func(t *testing.T) {
+		t.Logf("CapabilityDiskIndex: %v, HighMemThreshold: %v, CapabilityTags: %v",
+			Params.CapabilityDiskIndex, Params.HighMemThreshold, Params.CapabilityTags)
+
+		keys := []string{"indexNode.capabilities.highMemThreshold", "indexNode.capabilities.tags"}
+		olds := make([]string, len(keys))
+		for idx, key := range keys {
+			olds[idx], _ = Params.LoadWithDefault(key, "")
+		}
+		defer func() {
+			for idx, key := range keys {
+				_ = Params.Save(key, olds[idx])
+			}
+			Params.initHighMemThreshold()
+			Params.initCapabilityTags()
+		}()
+		assert.Nil(t, Params.Save(keys[0], "1024"))
+		assert.Nil(t, Params.Save(keys[1], " GPU, nvme,,"))
+		Params.initHighMemThreshold()
+		Params.initCapabilityTags()
+		assert.Equal(t, int64(1024), Params.HighMemThreshold)
+		assert.Equal(t, []string{CapabilityGPU, "nvme"}, Params.CapabilityTags)
+
+		assert.Nil(t, Params.Save(keys[0], "-1"))
+		Params.initHighMemThreshold()
+		assert.Equal(t, int64(defaultHighMemThreshold), Params.HighMemThreshold)
+	})
+
+	t.Run("KeepaliveFailurePolicy", func(t *testing.T) {
+		t.Logf("KeepaliveFailurePolicy: %v, KeepaliveRetryBudget: %v",
+			Params.KeepaliveFailurePolicy, Params.KeepaliveRetryBudget)
+		assert.Equal(t, keepaliveFailureExit, Params.KeepaliveFailurePolicy)
+
+		key := "indexNode.session.onKeepaliveFailure"
+		old, _ := Params.LoadWithDefault(key, "")
+		defer func() {
+			_ = Params.Save(key, old)
+			Params.initKeepaliveFailurePolicy()
+		}()
+		assert.Nil(t, Params.Save(key, " Retry-Forever"))
+		Params.initKeepaliveFailurePolicy()
+		assert.Equal(t, keepaliveFailureRetryForever, Params.KeepaliveFailurePolicy)
+
+		assert.Nil(t, Params.Save(key, "restart"))
+		Params.initKeepaliveFailurePolicy()
+		assert.Equal(t, keepaliveFailureExit, Params.KeepaliveFailurePolicy)
+	})
+
+	t.Run("IPRefresh", func(t *testing.T) {
+		t.Logf("IPRefreshEnabled: %v, IPRefreshInterval: %v, PreferredCIDR: %v",
+			Params.IPRefreshEnabled, Params.IPRefreshInterval, Params.PreferredCIDR)
+
+		keys := []string{"indexNode.ipRefresh.interval", "indexNode.ipRefresh.preferredCIDR"}
+		olds := make([]string, len(keys))
+		for idx, key := range keys {
+			olds[idx], _ = Params.LoadWithDefault(key, "")
+		}
+		defer func() {
+			for idx, key := range keys {
+				_ = Params.Save(key, olds[idx])
+			}
+			Params.initIPRefresh()
+			Params.initPreferredCIDR()
+		}()
+		assert.Nil(t, Params.Save(keys[0], "0"))
+		assert.Nil(t, Params.Save(keys[1], "10.0.0.0/8"))
+		Params.initIPRefresh()
+		Params.initPreferredCIDR()
+		assert.False(t, Params.IPRefreshEnabled)
+		assert.Equal(t, "10.0.0.0/8", Params.PreferredCIDR.String())
+
+		assert.Nil(t, Params.Save(keys[1], "10.0.0.1"))
+		Params.initPreferredCIDR()
+		assert.Nil(t, Params.PreferredCIDR)
+	})
+
+	t.Run("Tracing", func(t *testing.T) {
+		t.Logf("TracingEnabled: %v, TracingEndpoint: %v, TracingSamplingRatio: %v",
+			Params.TracingEnabled, Params.TracingEndpoint, Params.TracingSamplingRatio)
+		assert.False(t, Params.TracingEnabled)
+
+		keys := []string{"indexNode.tracing.enabled", "indexNode.tracing.endpoint", "indexNode.tracing.samplingRatio"}
+		olds := make([]string, len(keys))
+		for idx, key := range keys {
+			olds[idx], _ = Params.LoadWithDefault(key, "")
+		}
+		defer func() {
+			for idx, key := range keys {
+				_ = Params.Save(key, olds[idx])
+			}
+			Params.initTracing()
+		}()
+		assert.Nil(t, Params.Save(keys[0], "true"))
+		assert.Nil(t, Params.Save(keys[1], " localhost:6831 "))
+		assert.Nil(t, Params.Save(keys[2], "0.1"))
+		Params.initTracing()
+		assert.True(t, Params.TracingEnabled)
+		assert.Equal(t, "localhost:6831", Params.TracingEndpoint)
+		assert.Equal(t, 0.1, Params.TracingSamplingRatio)
+
+		assert.Nil(t, Params.Save(keys[2], "2"))
+		Params.initTracing()
+		assert.Equal(t, defaultTracingSamplingRatio, Params.TracingSamplingRatio)
+	})
+
+	t.Run("Profiling", func(t *testing.T) {
+		t.Logf("ProfilingEnabled: %v, ProfilingUploadPath: %v", Params.ProfilingEnabled, Params.ProfilingUploadPath)
+		assert.False(t, Params.ProfilingEnabled)
+		assert.Equal(t, defaultProfilingUploadPath, Params.ProfilingUploadPath)
+
+		keys := []string{"indexNode.profiling.enabled", "indexNode.profiling.secret", "indexNode.profiling.uploadPath"}
+		olds := make([]string, len(keys))
+		for idx, key := range keys {
+			olds[idx], _ = Params.LoadWithDefault(key, "")
+		}
+		defer func() {
+			for idx, key := range keys {
+				_ = Params.Save(key, olds[idx])
+			}
+			Params.initProfiling()
+		}()
+		assert.Nil(t, Params.Save(keys[0], "true"))
+		assert.Nil(t, Params.Save(keys[1], "s3cret"))
+		assert.Nil(t, Params.Save(keys[2], "/support/profiles/"))
+		Params.initProfiling()
+		assert.True(t, Params.ProfilingEnabled)
+		assert.Equal(t, "s3cret", Params.ProfilingSecret)
+		assert.Equal(t, "support/profiles", Params.ProfilingUploadPath)
+
+		assert.Nil(t, Params.Save(keys[2], " / "))
+		Params.initProfiling()
+		assert.Equal(t, defaultProfilingUploadPath, Params.ProfilingUploadPath)
+	})
+
+	t.Run("Encryption", func(t *testing.T) {
+		assert.Nil(t, Params.EncryptionKeyring)
+
+		keys := []string{"indexNode.encryption.enabled", "indexNode.encryption.keys", "indexNode.encryption.keysFile"}
+		olds := make([]string, len(keys))
+		for idx, key := range keys {
+			olds[idx], _ = Params.LoadWithDefault(key, "")
+		}
+		defer func() {
+			for idx, key := range keys {
+				_ = Params.Save(key, olds[idx])
+			}
+			Params.initEncryption()
+		}()
+		key := base64.StdEncoding.EncodeToString(make([]byte, encryption.KeySize))
+		assert.Nil(t, Params.Save(keys[1], "k1:"+key+",k2:"+key))
+		Params.initEncryption()
+		assert.Nil(t, Params.EncryptionKeyring)
+
+		assert.Nil(t, Params.Save(keys[0], "true"))
+		Params.initEncryption()
+		assert.NotNil(t, Params.EncryptionKeyring)
+		assert.Equal(t, "k2", Params.EncryptionKeyring.ActiveKeyID())
+
+		file := filepath.Join(t.TempDir(), "keys")
+		assert.Nil(t, ioutil.WriteFile(file, []byte("k3:"+key+"\n"), 0600))
+		assert.Nil(t, Params.Save(keys[1], ""))
+		assert.Nil(t, Params.Save(keys[2], file))
+		Params.initEncryption()
+		assert.Equal(t, "k3", Params.EncryptionKeyring.ActiveKeyID())
+
+		// the index files are never uploaded in plaintext if the encryption is enabled with the invalid keys
+		for _, invalid := range [][]string{{"k1:short", ""}, {"", ""}, {"k1:" + key, file}} {
+			assert.Nil(t, Params.Save(keys[1], invalid[0]))
+			assert.Nil(t, Params.Save(keys[2], invalid[1]))
+			assert.Panics(t, Params.initEncryption, invalid)
+		}
+	})
+
+	t.Run("EngineStats", func(t *testing.T) {
+		t.Logf("EngineStatsEnabled: %v", Params.EngineStatsEnabled)
+		assert.False(t, Params.EngineStatsEnabled)
+
+		key := "indexNode.engineStats.enabled"
+		old, _ := Params.LoadWithDefault(key, "")
+		defer func() {
+			_ = Params.Save(key, old)
+			Params.initEngineStats()
+		}()
+		assert.Nil(t, Params.Save(key, "true"))
+		Params.initEngineStats()
+		assert.True(t, Params.EngineStatsEnabled)
+	})
+
+	t.Run("TaskEvents", func(t *testing.T) {
+		t.Logf("TaskEventSink: %s, TaskEventFilePath: %s, TaskEventRetention: %v",
+			Params.TaskEventSink, Params.TaskEventFilePath, Params.TaskEventRetention)
+		assert.Equal(t, taskEventSinkNone, Params.TaskEventSink)
+		assert.Equal(t, 100, Params.TaskEventFileMaxSize)
+		assert.Equal(t, 10, Params.TaskEventFileMaxBackups)
+		assert.Equal(t, 24*time.Hour, Params.TaskEventRetention)
+
+		keys := []string{"indexNode.taskEvents.sink", "indexNode.taskEvents.file.maxSize", "indexNode.taskEvents.etcd.retention"}
+		olds := make([]string, len(keys))
+		for i, key := range keys {
+			olds[i], _ = Params.LoadWithDefault(key, "")
+		}
+		defer func() {
+			for i, key := range keys {
+				_ = Params.Save(key, olds[i])
+			}
+			Params.initTaskEvents()
+		}()
+		assert.Nil(t, Params.Save(keys[0], " Etcd "))
+		assert.Nil(t, Params.Save(keys[1], "0"))
+		assert.Nil(t, Params.Save(keys[2], "60"))
+		Params.initTaskEvents()
+		assert.Equal(t, taskEventSinkEtcd, Params.TaskEventSink)
+		assert.Equal(t, 100, Params.TaskEventFileMaxSize)
+		assert.Equal(t, time.Minute, Params.TaskEventRetention)
+
+		assert.Nil(t, Params.Save(keys[0], "kafka"))
+		Params.initTaskEvents()
+		assert.Equal(t, taskEventSinkNone, Params.TaskEventSink)
+	})
+
+	t.Run("LogSampling", func(t *testing.T) {
+		t.Logf("LogSamplingInitial: %d, LogSamplingThereafter: %d", Params.LogSamplingInitial, Params.LogSamplingThereafter)
+		assert.Equal(t, 10, Params.LogSamplingInitial)
+		assert.Equal(t, 1000, Params.LogSamplingThereafter)
+
+		keys := []string{"log.sampling.initial", "log.sampling.thereafter"}
+		olds := make([]string, len(keys))
+		for i, key := range keys {
+			olds[i], _ = Params.LoadWithDefault(key, "")
+		}
+		defer func() {
+			for i, key := range keys {
+				_ = Params.Save(key, olds[i])
+			}
+			Params.initLogSampling()
+		}()
+		assert.Nil(t, Params.Save(keys[0], "0"))
+		assert.Nil(t, Params.Save(keys[1], "-1"))
+		Params.initLogSampling()
+		assert.Equal(t, 0, Params.LogSamplingInitial)
+		assert.Equal(t, 1000, Params.LogSamplingThereafter)
+	})
+
+	t.Run("StartupTimeouts", func(t *testing.T) {
+		t.Logf("StartupEtcdTimeout: %v, StartupStorageTimeout: %v, StartupSessionTimeout: %v",
+			Params.StartupEtcdTimeout, Params.StartupStorageTimeout, Params.StartupSessionTimeout)
+
+		key := "indexNode.startup.sessionTimeout"
+		old, _ := Params.LoadWithDefault(key, "")
+		defer func() {
+			_ = Params.Save(key, old)
+			Params.initStartupTimeouts()
+		}()
+		err := Params.Save(key, "0")
+		assert.Nil(t, err)
+		Params.initStartupTimeouts()
+		assert.Equal(t, time.Duration(0), Params.StartupSessionTimeout)
+		err = Params.Save(key, "abc")
+		assert.Nil(t, err)
+		Params.initStartupTimeouts()
+		assert.Equal(t, defaultStartupPhaseTimeout*time.Second, Params.StartupSessionTimeout)
+	})
+
+	t.Run("SelfTest", func(t *testing.T) {
+		assert.False(t, Params.SelfTestEnabled)
+		assert.Equal(t, defaultSelfTestIndexType, Params.SelfTestIndexType)
+		assert.Equal(t, defaultSelfTestTimeout*time.Second, Params.SelfTestTimeout)
+
+		keys := []string{"indexNode.selfTest.enabled", "indexNode.selfTest.indexType", "indexNode.selfTest.timeout"}
+		olds := make([]string, len(keys))
+		for idx, key := range keys {
+			olds[idx], _ = Params.LoadWithDefault(key, "")
+		}
+		defer func() {
+			for idx, key := range keys {
+				_ = Params.Save(key, olds[idx])
+			}
+			Params.initSelfTest()
+		}()
+		assert.Nil(t, Params.Save(keys[0], "true"))
+		assert.Nil(t, Params.Save(keys[1], "flat"))
+		assert.Nil(t, Params.Save(keys[2], "3"))
+		Params.initSelfTest()
+		assert.True(t, Params.SelfTestEnabled)
+		assert.Equal(t, "FLAT", Params.SelfTestIndexType)
+		assert.Equal(t, 3*time.Second, Params.SelfTestTimeout)
+
+		assert.Nil(t, Params.Save(keys[1], "HNSW"))
+		Params.initSelfTest()
+		assert.Equal(t, defaultSelfTestIndexType, Params.SelfTestIndexType)
+	})
+
+	t.Run("TaskHeartbeat", func(t *testing.T) {
+		t.Logf("TaskHeartbeatInterval: %v, TaskStallTimeout: %v", Params.TaskHeartbeatInterval, Params.TaskStallTimeout)
+
+		key := "indexNode.taskHeartbeat.interval"
+		old, _ := Params.LoadWithDefault(key, "")
+		defer func() {
+			_ = Params.Save(key, old)
+			Params.initTaskHeartbeatInterval()
+		}()
+		err := Params.Save(key, "0")
+		assert.Nil(t, err)
+		Params.initTaskHeartbeatInterval()
+		assert.Equal(t, time.Duration(0), Params.TaskHeartbeatInterval)
+		err = Params.Save(key, "-1")
+		assert.Nil(t, err)
+		Params.initTaskHeartbeatInterval()
+		assert.Equal(t, defaultTaskHeartbeatInterval*time.Second, Params.TaskHeartbeatInterval)
+	})
+
+	t.Run("GracefulStopTimeout", func(t *testing.T) {
+		assert.Equal(t, defaultGracefulStopTimeout*time.Second, Params.GracefulStopTimeout)
+
+		key := "indexNode.grpc.gracefulStopTimeout"
+		old, _ := Params.LoadWithDefault(key, "")
+		defer func() {
+			_ = Params.Save(key, old)
+			Params.initGracefulStopTimeout()
+		}()
+		assert.Nil(t, Params.Save(key, "5"))
+		Params.initGracefulStopTimeout()
+		assert.Equal(t, 5*time.Second, Params.GracefulStopTimeout)
+		assert.Nil(t, Params.Save(key, "-1"))
+		Params.initGracefulStopTimeout()
+		assert.Equal(t, defaultGracefulStopTimeout*time.Second, Params.GracefulStopTimeout)
+	})
+
+	t.Run("BuildSeed", func(t *testing.T) {
+		assert.Equal(t, int64(defaultBuildSeed), Params.BuildSeed)
+
+		key := "indexNode.build.seed"
+		old, _ := Params.LoadWithDefault(key, "")
+		defer func() {
+			_ = Params.Save(key, old)
+			Params.initBuildSeed()
+		}()
+		for value, expected := range map[string]int64{
+			"7":          7,
+			"-1":         -1,
+			"seed":       defaultBuildSeed,
+			"2147483648": defaultBuildSeed,
+		} {
+			assert.Nil(t, Params.Save(key, value))
+			Params.initBuildSeed()
+			assert.Equal(t, expected, Params.BuildSeed, value)
+		}
+	})
+
+	t.Run("BuildSeedHNSW", func(t *testing.T) {
+		t.Logf("BuildSeedHNSW: %v", Params.BuildSeedHNSW)
+		assert.False(t, Params.BuildSeedHNSW)
+	})
+
+	t.Run("Watermarks", func(t *testing.T) {
+		t.Logf("MemoryHighWatermark: %v, MemoryLowWatermark: %v, DiskMinFree: %v, DiskResumeFree: %v",
+			Params.MemoryHighWatermark, Params.MemoryLowWatermark, Params.DiskMinFree, Params.DiskResumeFree)
+
+		keys := []string{"indexNode.memory.highWatermark", "indexNode.memory.lowWatermark",
+			"indexNode.disk.minFree", "indexNode.disk.resumeFree"}
+		olds := make([]string, len(keys))
+		for idx, key := range keys {
+			olds[idx], _ = Params.LoadWithDefault(key, "")
+		}
+		defer func() {
+			for idx, key := range keys {
+				_ = Params.Save(key, olds[idx])
+			}
+			Params.initMemoryWatermarks()
+			Params.initDiskWatermarks()
+		}()
+		save := func(values ...string) {
+			for idx, value := range values {
+				assert.Nil(t, Params.Save(keys[idx], value))
+			}
+			Params.initMemoryWatermarks()
+			Params.initDiskWatermarks()
+		}
+
+		save("0.7", "0.6", "100", "200")
+		assert.Equal(t, 0.7, Params.MemoryHighWatermark)
+		assert.Equal(t, 0.6, Params.MemoryLowWatermark)
+		assert.Equal(t, int64(100), Params.DiskMinFree)
+		assert.Equal(t, int64(200), Params.DiskResumeFree)
+
+		// the low watermarks never pass the high ones
+		save("0.7", "0.8", "100", "50")
+		assert.Equal(t, 0.7, Params.MemoryLowWatermark)
+		assert.Equal(t, int64(100), Params.DiskResumeFree)
+
+		save("1.5", "-1", "-1", "abc")
+		assert.Equal(t, defaultMemoryHighWatermark, Params.MemoryHighWatermark)
+		assert.Equal(t, defaultMemoryLowWatermark, Params.MemoryLowWatermark)
+		assert.Equal(t, int64(defaultDiskMinFree), Params.DiskMinFree)
+		assert.Equal(t, int64(defaultDiskResumeFree), Params.DiskResumeFree)
+	})
+
+	t.Run("SchedulePolicy", func(t *testing.T) {
+		t.Logf("SchedulePolicy: %v, ScheduleMaxDefer: %v", Params.SchedulePolicy, Params.ScheduleMaxDefer)
+
+		key := "indexNode.scheduler.policy"
+		old, _ := Params.LoadWithDefault(key, "")
+		defer func() {
+			_ = Params.Save(key, old)
+			Params.initSchedulePolicy()
+		}()
+		err := Params.Save(key, "Smallest-First")
+		assert.Nil(t, err)
+		Params.initSchedulePolicy()
+		assert.Equal(t, schedulePolicySmallestFirst, Params.SchedulePolicy)
+		err = Params.Save(key, "lifo")
+		assert.Nil(t, err)
+		Params.initSchedulePolicy()
+		assert.Equal(t, defaultSchedulePolicy, Params.SchedulePolicy)
+	})
+
+	t.Run("SimdTypeOverrides", func(t *testing.T) {
+		t.Logf("SimdTypeOverrides: %v", Params.SimdTypeOverrides)
+
+		keys := []string{simdTypeOverridePrefix + "HNSW", simdTypeOverridePrefix + "IVF_PQ",
+			simdTypeOverridePrefix + "NOT_EXIST", simdTypeOverridePrefix + "IVF_FLAT"}
+		oldDetectCPUFeatures := detectCPUFeatures
+		detectCPUFeatures = func() cpuFeatures {
+			return cpuFeatures{sse42: true, avx2: true, avx512: true}
+		}
+		defer func() {
+			detectCPUFeatures = oldDetectCPUFeatures
+			for _, key := range keys {
+				_ = Params.Remove(key)
+			}
+			Params.StrictConfig = false
+			Params.initKnowhereSimdTypeOverrides()
+		}()
+		assert.Nil(t, Params.Save(keys[0], "avx512"))
+		assert.Nil(t, Params.Save(keys[1], "SSE"))
+		assert.Nil(t, Params.Save(keys[2], "avx2"))
+		assert.Nil(t, Params.Save(keys[3], "avx1024"))
+		Params.initKnowhereSimdTypeOverrides()
+		assert.Equal(t, map[string]string{"HNSW": "avx512", "IVF_PQ": "sse"}, Params.SimdTypeOverrides)
+		assert.Equal(t, "avx512", Params.simdTypeOf("hnsw"))
+		assert.Equal(t, "", Params.simdTypeOf("IVF_SQ8"))
+
+		Params.StrictConfig = true
+		assert.Panics(t, func() {
+			Params.initKnowhereSimdTypeOverrides()
+		})
+		assert.Nil(t, Params.Remove(keys[2]))
+		assert.Panics(t, func() {
+			Params.initKnowhereSimdTypeOverrides()
+		})
+		assert.Nil(t, Params.Remove(keys[3]))
+		Params.initKnowhereSimdTypeOverrides()
+		assert.Equal(t, 2, len(Params.SimdTypeOverrides))
+	})
+
+	t.Run("EngineConfig", func(t *testing.T) {
+		keys := []string{engineConfigPrefix + "build_thread_num", engineConfigPrefix + "HNSW.efConstruction",
+			engineConfigPrefix + "HNSW.unknown_key", engineConfigPrefix + "NOT_EXIST.nlist"}
+		defer func() {
+			for _, key := range keys {
+				_ = Params.Remove(key)
+			}
+			Params.StrictConfig = false
+			Params.initKnowhereEngineConfig()
+		}()
+		assert.Nil(t, Params.Save(keys[0], "8"))
+		assert.Nil(t, Params.Save(keys[1], "200"))
+		assert.Nil(t, Params.Save(keys[2], "1"))
+		assert.Nil(t, Params.Save(keys[3], "1024"))
+		Params.initKnowhereEngineConfig()
+		assert.Equal(t, "8", Params.EngineConfig["build_thread_num"])
+		// the unknown keys are passed to the engine, but the unknown index types are ignored
+		assert.Equal(t, map[string]map[string]string{"HNSW": {"efconstruction": "200", "unknown_key": "1"}},
+			Params.EngineConfigOverrides)
+
+		Params.StrictConfig = true
+		assert.Panics(t, func() {
+			Params.initKnowhereEngineConfig()
+		})
+		assert.Nil(t, Params.Remove(keys[3]))
+		Params.initKnowhereEngineConfig()
+		assert.Equal(t, 1, len(Params.EngineConfigOverrides))
+	})
+
+	t.Run("SimdTypeDowngrade", func(t *testing.T) {
+		t.Logf("SimdType: %v, SimdTypeRequested: %v", Params.SimdType, Params.SimdTypeRequested)
+
+		oldSimdType, _ := Params.LoadWithDefault("knowhere.simdType", "auto")
+		oldDetectCPUFeatures := detectCPUFeatures
+		detectCPUFeatures = func() cpuFeatures {
+			return cpuFeatures{sse42: true, avx2: true}
+		}
+		defer func() {
+			detectCPUFeatures = oldDetectCPUFeatures
+			_ = Params.Save("knowhere.simdType", oldSimdType)
+			Params.StrictSimdType = false
+			Params.initKnowhereSimdType()
+		}()
+
+		assert.Nil(t, Params.Save("knowhere.simdType", "avx2"))
+		Params.initKnowhereSimdType()
+		assert.Equal(t, "avx2", Params.SimdTypeRequested)
+		assert.Equal(t, "avx2", Params.SimdType)
+
+		assert.Nil(t, Params.Save("knowhere.simdType", "avx512"))
+		Params.initKnowhereSimdType()
+		assert.Equal(t, "avx512", Params.SimdTypeRequested)
+		assert.Equal(t, "avx2", Params.SimdType)
+
+		assert.Nil(t, Params.Save("knowhere.simdType", "SSE4_2"))
+		Params.initKnowhereSimdType()
+		assert.Nil(t, Params.simdTypeErr)
+		assert.Equal(t, "SSE4_2", Params.SimdTypeRequested)
+		assert.Equal(t, "sse", Params.SimdType)
+
+		assert.Nil(t, Params.Save("knowhere.simdType", ""))
+		Params.initKnowhereSimdType()
+		assert.Nil(t, Params.simdTypeErr)
+		assert.Equal(t, "auto", Params.SimdType)
+
+		assert.Nil(t, Params.Save("knowhere.simdType", "avx1024"))
+		Params.initKnowhereSimdType()
+		assert.NotNil(t, Params.simdTypeErr)
+		assert.Contains(t, Params.simdTypeErr.Error(), acceptedSimdTypes)
+
+		assert.Nil(t, Params.Save("knowhere.simdType", "avx512"))
+		Params.StrictSimdType = true
+		assert.Panics(t, func() {
+			Params.initKnowhereSimdType()
+		})
+	})
+
+	t.Run("CollectionWeights", func(t *testing.T) {
+		t.Logf("CollectionWeights: %v", Params.CollectionWeights)
+
+		key := "indexNode.scheduler.collectionWeights"
+		old, _ := Params.LoadWithDefault(key, "")
+		defer func() {
+			_ = Params.Save(key, old)
+			Params.initCollectionWeights()
+		}()
+		err := Params.Save(key, "100:3, 101, 102:0,103:a,104:2,")
+		assert.Nil(t, err)
+		Params.initCollectionWeights()
+		assert.Equal(t, map[UniqueID]int64{100: 3, 104: 2}, Params.CollectionWeights)
+	})
+
+	t.Run("RateLimits", func(t *testing.T) {
+		t.Logf("RateLimits: %v", Params.RateLimits)
+		assert.Equal(t, ratelimit.Limit{Rate: 100, Burst: 200}, Params.RateLimits["CreateIndex"])
+
+		key := "indexNode.rateLimit.limits"
+		old, _ := Params.LoadWithDefault(key, "")
+		defer func() {
+			_ = Params.Save(key, old)
+			Params.initRateLimits()
+		}()
+		err := Params.Save(key, "CreateIndex:10, GetMetrics:0.5:2")
+		assert.Nil(t, err)
+		Params.initRateLimits()
+		assert.Equal(t, map[string]ratelimit.Limit{
+			"CreateIndex": {Rate: 10, Burst: 10},
+			"GetMetrics":  {Rate: 0.5, Burst: 2},
+		}, Params.RateLimits)
+
+		err = Params.Save(key, "")
+		assert.Nil(t, err)
+		Params.initRateLimits()
+		assert.Empty(t, Params.RateLimits)
+
+		// the invalid limits and the ones of the methods never limited fall back to the default
+		for _, value := range []string{"CreateIndex:-1", "CreateIndex", "GetComponentStates:10"} {
+			err = Params.Save(key, value)
+			assert.Nil(t, err)
+			Params.initRateLimits()
+			assert.Equal(t, defaultRateLimits, ratelimit.FormatLimits(Params.RateLimits))
+		}
+	})
 }
 
 //TODO: Params Load should be return error when key does not exist.
@@ -0,0 +1,244 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/kv"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/encryption"
+	"github.com/milvus-io/milvus/internal/util/funcutil"
+)
+
+// persistPartSize is the part size of the multipart upload of the serialized index files, which bounds the memory
+// buffering each index file being uploaded.
+const persistPartSize = 16 * 1024 * 1024
+
+// the results of cleaning up the files saved by a failed build
+const (
+	persistCleanupNotNeeded = "not needed"
+	persistCleanupPerformed = "performed"
+	persistCleanupFailed    = "failed"
+	persistCleanupRetained  = "retained as checkpoints"
+)
+
+// persistFailure is an index file failed to save.
+type persistFailure struct {
+	path   string
+	reason string
+	err    error
+}
+
+// persistReport records the result of saving each index file of a build.
+type persistReport struct {
+	mu        sync.Mutex
+	total     int
+	succeeded []string
+	failed    []persistFailure
+	cleanup   string
+	// cleanupErr is the error of removing the saved files if the cleanup failed
+	cleanupErr error
+}
+
+func newPersistReport() *persistReport {
+	return &persistReport{
+		succeeded: make([]string, 0),
+		failed:    make([]persistFailure, 0),
+		cleanup:   persistCleanupNotNeeded,
+	}
+}
+
+// persistFiles saves the files of @paths in parallel with @save, it saves all the files even if some of them fail,
+// and records the result of each file in the report.
+func (r *persistReport) persistFiles(paths []string, save func(idx int) error, fname string) {
+	r.mu.Lock()
+	r.total += len(paths)
+	r.mu.Unlock()
+	_ = funcutil.ProcessFuncParallel(len(paths), runtime.NumCPU(), func(idx int) error {
+		r.record(paths[idx], save(idx))
+		return nil
+	}, fname)
+}
+
+func (r *persistReport) record(path string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.failed = append(r.failed, persistFailure{path: path, reason: err.Error(), err: err})
+		return
+	}
+	r.succeeded = append(r.succeeded, path)
+}
+
+func (r *persistReport) hasFailure() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.failed) > 0
+}
+
+// succeededFiles returns the saved files in order.
+func (r *persistReport) succeededFiles() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	files := append([]string{}, r.succeeded...)
+	sort.Strings(files)
+	return files
+}
+
+// failedFiles returns the files failed to save along with the reasons, in order.
+func (r *persistReport) failedFiles() []persistFailure {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	files := append([]persistFailure{}, r.failed...)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].path < files[j].path
+	})
+	return files
+}
+
+// cleanupSaved removes the saved files of the failed build, or retains them as checkpoints if @retain.
+func (r *persistReport) cleanupSaved(storage kv.BaseKV, retain bool) {
+	succeeded := r.succeededFiles()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case len(succeeded) == 0:
+		r.cleanup = persistCleanupNotNeeded
+	case retain:
+		r.cleanup = persistCleanupRetained
+	default:
+		if err := storage.MultiRemove(succeeded); err != nil {
+			r.cleanup = persistCleanupFailed
+			r.cleanupErr = err
+			return
+		}
+		r.cleanup = persistCleanupPerformed
+	}
+}
+
+func (r *persistReport) cleanupResult() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cleanupErr != nil {
+		return r.cleanup + ": " + r.cleanupErr.Error()
+	}
+	return r.cleanup
+}
+
+func (r *persistReport) logFields() []zap.Field {
+	failed := r.failedFiles()
+	reasons := make(map[string]string, len(failed))
+	for _, file := range failed {
+		reasons[file.path] = file.reason
+	}
+	return []zap.Field{
+		zap.Int("total", r.total),
+		zap.Strings("succeededFiles", r.succeededFiles()),
+		zap.Any("failedFiles", reasons),
+		zap.String("cleanup", r.cleanupResult()),
+	}
+}
+
+// err returns the error describing the result of each file, or nil if all the files are saved.
+func (r *persistReport) err() error {
+	if !r.hasFailure() {
+		return nil
+	}
+	return &persistError{report: r}
+}
+
+// persistError is the failure of saving the index files of a build.
+type persistError struct {
+	report *persistReport
+}
+
+func (e *persistError) Error() string {
+	failed := e.report.failedFiles()
+	reasons := make([]string, 0, len(failed))
+	for _, file := range failed {
+		reasons = append(reasons, fmt.Sprintf("%s: %s", file.path, file.reason))
+	}
+	succeeded := e.report.succeededFiles()
+	return fmt.Sprintf("failed to save %d of %d index files [%s], succeeded files [%s], cleanup of the succeeded files: %s",
+		len(failed), e.report.total, strings.Join(reasons, "; "), strings.Join(succeeded, ", "), e.report.cleanupResult())
+}
+
+// Unwrap returns the error of the first file failed to save, which classifies the failure of the build.
+func (e *persistError) Unwrap() error {
+	failed := e.report.failedFiles()
+	if len(failed) == 0 {
+		return nil
+	}
+	return failed[0].err
+}
+
+// serializeIndex serializes the index in place if the index supports it, the returned func releases the index
+// files, which can be called more than once.
+func serializeIndex(index Index) ([]*Blob, func(), error) {
+	inPlace, ok := index.(InPlaceIndex)
+	if !ok {
+		blobs, err := index.Serialize()
+		return blobs, func() {}, err
+	}
+	blobs, release, err := inPlace.SerializeInPlace()
+	if err != nil {
+		return nil, nil, err
+	}
+	var once sync.Once
+	return blobs, func() { once.Do(release) }, nil
+}
+
+// streamObjectSize returns the size of the object of the stream of @size bytes, encrypted by @key if it's not nil.
+func streamObjectSize(size int64, key []byte) int64 {
+	if key != nil {
+		return encryption.EncryptedSize(size)
+	}
+	return size
+}
+
+// putStream saves the stream of @blob to @savePath, encrypted by @key if it's not nil, and returns the checksum of
+// the object taken while the stream is read. The stream is uploaded part by part by the object storage supporting
+// it, otherwise it's read into the memory and saved as a whole.
+func putStream(objects kv.BaseKV, savePath string, blob *storage.StreamBlob, metadata map[string]string, key []byte) (string, error) {
+	reader := blob.NewReader()
+	if key != nil {
+		var err error
+		if reader, err = encryption.NewEncryptReader(key, reader); err != nil {
+			return "", err
+		}
+	}
+	hash := newChecksumHash()
+	reader = io.TeeReader(reader, hash)
+	if uploader, ok := objects.(streamUploader); ok {
+		if err := uploader.PutObject(savePath, reader, streamObjectSize(blob.Size, key), persistPartSize, metadata); err != nil {
+			return "", err
+		}
+		return checksumOfHash(hash), nil
+	}
+	value, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	if err := saveWithMetadata(objects, savePath, string(value), metadata); err != nil {
+		return "", err
+	}
+	return checksumOfHash(hash), nil
+}
@@ -0,0 +1,353 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/kv"
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/encryption"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+// faultyKV fails to save the keys in failKeys, and fails to remove if removeErr is set.
+type faultyKV struct {
+	*memkv.MemoryKV
+	failKeys  map[string]bool
+	removeErr error
+}
+
+func (kv *faultyKV) Save(key, value string) error {
+	if kv.failKeys[key] {
+		return errors.New("connection reset by peer")
+	}
+	return kv.MemoryKV.Save(key, value)
+}
+
+func (kv *faultyKV) MultiRemove(keys []string) error {
+	if kv.removeErr != nil {
+		return kv.removeErr
+	}
+	return kv.MemoryKV.MultiRemove(keys)
+}
+
+// persistWithFault saves 12 index files and fails the 9th one.
+func persistWithFault(t *testing.T, storage *faultyKV) (*persistReport, []string) {
+	paths := make([]string, 12)
+	for idx := range paths {
+		paths[idx] = fmt.Sprintf("index_files/1/1/%02d", idx)
+	}
+	storage.failKeys = map[string]bool{paths[8]: true}
+	report := newPersistReport()
+	report.persistFiles(paths, func(idx int) error {
+		return storageError(storage.Save(paths[idx], "value"))
+	}, "saveIndexFile")
+	assert.True(t, report.hasFailure())
+	return report, paths
+}
+
+func TestPersistReport(t *testing.T) {
+	report := newPersistReport()
+	report.persistFiles([]string{"a", "b"}, func(idx int) error { return nil }, "saveIndexFile")
+	assert.False(t, report.hasFailure())
+	assert.Nil(t, report.err())
+	assert.Equal(t, []string{"a", "b"}, report.succeededFiles())
+
+	t.Run("cleanup", func(t *testing.T) {
+		storage := &faultyKV{MemoryKV: memkv.NewMemoryKV()}
+		report, paths := persistWithFault(t, storage)
+		succeeded := append(append([]string{}, paths[:8]...), paths[9:]...)
+		assert.Equal(t, succeeded, report.succeededFiles())
+		failed := report.failedFiles()
+		assert.Equal(t, 1, len(failed))
+		assert.Equal(t, paths[8], failed[0].path)
+		assert.Equal(t, "connection reset by peer", failed[0].reason)
+		assert.Equal(t, errorcode.StorageTransient, classifyError(report.err()))
+
+		report.cleanupSaved(storage, false)
+		assert.Equal(t, persistCleanupPerformed, report.cleanupResult())
+		for _, path := range paths {
+			value, err := storage.Load(path)
+			assert.Nil(t, err)
+			assert.Equal(t, "", value)
+		}
+
+		err := report.err()
+		assert.NotNil(t, err)
+		msg := err.Error()
+		assert.True(t, strings.HasPrefix(msg, "failed to save 1 of 12 index files"))
+		assert.Contains(t, msg, paths[8]+": connection reset by peer")
+		assert.Contains(t, msg, strings.Join(succeeded, ", "))
+		assert.Contains(t, msg, "cleanup of the succeeded files: "+persistCleanupPerformed)
+		assert.Equal(t, 4, len(report.logFields()))
+	})
+
+	t.Run("cleanup failed", func(t *testing.T) {
+		storage := &faultyKV{MemoryKV: memkv.NewMemoryKV(), removeErr: errors.New("access denied")}
+		report, _ := persistWithFault(t, storage)
+		report.cleanupSaved(storage, false)
+		assert.Equal(t, persistCleanupFailed+": access denied", report.cleanupResult())
+		assert.Contains(t, report.err().Error(), "cleanup of the succeeded files: failed: access denied")
+	})
+
+	t.Run("retained", func(t *testing.T) {
+		storage := &faultyKV{MemoryKV: memkv.NewMemoryKV()}
+		report, paths := persistWithFault(t, storage)
+		report.cleanupSaved(storage, true)
+		assert.Equal(t, persistCleanupRetained, report.cleanupResult())
+		value, err := storage.Load(paths[0])
+		assert.Nil(t, err)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("all failed", func(t *testing.T) {
+		report := newPersistReport()
+		report.persistFiles([]string{"a"}, func(idx int) error { return errors.New("timeout") }, "saveIndexFile")
+		report.cleanupSaved(memkv.NewMemoryKV(), false)
+		assert.Equal(t, persistCleanupNotNeeded, report.cleanupResult())
+	})
+}
+
+func TestIndexBuildTask_persistFailure(t *testing.T) {
+	storage := &faultyKV{MemoryKV: memkv.NewMemoryKV()}
+	it := &IndexBuildTask{
+		kv:  storage,
+		req: &indexpb.CreateIndexRequest{IndexBuildID: 1, Version: 1},
+	}
+	report, paths := persistWithFault(t, storage)
+	err := it.persistFailure(report)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), paths[8])
+	assert.Nil(t, it.checkpointFiles)
+	value, err := storage.Load(paths[0])
+	assert.Nil(t, err)
+	assert.Equal(t, "", value)
+
+	Params.ResumableBuild = true
+	defer func() {
+		Params.ResumableBuild = false
+	}()
+	report, paths = persistWithFault(t, storage)
+	err = it.persistFailure(report)
+	assert.Contains(t, err.Error(), persistCleanupRetained)
+	checkpoints := append(append([]string{}, paths[:8]...), paths[9:]...)
+	sort.Strings(checkpoints)
+	assert.Equal(t, checkpoints, it.checkpointFiles)
+	value, err = storage.Load(paths[0])
+	assert.Nil(t, err)
+	assert.Equal(t, "value", value)
+}
+
+// mockInPlaceIndex serializes the blobs in place, and counts the releases of them.
+type mockInPlaceIndex struct {
+	mockIncrementalIndex
+	blobs    []*Blob
+	released int
+}
+
+func (index *mockInPlaceIndex) SerializeInPlace() ([]*Blob, func(), error) {
+	return index.blobs, func() {
+		index.released++
+	}, nil
+}
+
+func TestSerializeIndex(t *testing.T) {
+	blobs, release, err := serializeIndex(&mockIncrementalIndex{})
+	assert.Nil(t, err)
+	assert.Nil(t, blobs)
+	release()
+
+	index := &mockInPlaceIndex{blobs: []*Blob{{Key: "HNSW", Value: []byte("graph")}}}
+	blobs, release, err = serializeIndex(index)
+	assert.Nil(t, err)
+	assert.Equal(t, index.blobs, blobs)
+	assert.Equal(t, 0, index.released)
+	release()
+	release()
+	assert.Equal(t, 1, index.released)
+}
+
+func newTestStreamBlob(t testing.TB, value []byte) *storage.StreamBlob {
+	codec := storage.NewIndexFileBinlogCodec()
+	streams, err := codec.SerializeToStreams(1, 2, 3, 4, 5, 6, map[string]string{indexTypeKey: "HNSW"}, "index", 7,
+		[]*Blob{{Key: "HNSW", Value: value}})
+	assert.Nil(t, err)
+	return streams[0]
+}
+
+func TestPutStream(t *testing.T) {
+	blob := newTestStreamBlob(t, []byte(strings.Repeat("graph", 1000)))
+	expected, err := ioutil.ReadAll(blob.NewReader())
+	assert.Nil(t, err)
+	key := make([]byte, encryption.KeySize)
+	metadata := map[string]string{"engine-version": "1"}
+
+	// the object storage without multipart upload saves the stream as a whole
+	uploader := &mockFileUploader{MemoryKV: memkv.NewMemoryKV()}
+	for _, objects := range []kv.BaseKV{memkv.NewMemoryKV(), uploader} {
+		for _, dataKey := range [][]byte{nil, key} {
+			savePath := fmt.Sprintf("index_files/%v/HNSW", dataKey != nil)
+			checksum, err := putStream(objects, savePath, blob, metadata, dataKey)
+			assert.Nil(t, err)
+
+			value, err := objects.Load(savePath)
+			assert.Nil(t, err)
+			assert.Equal(t, checksumOf([]byte(value)), checksum)
+			assert.Equal(t, streamObjectSize(blob.Size, dataKey), int64(len(value)))
+			if dataKey != nil {
+				decrypted, err := encryption.Decrypt(key, []byte(value))
+				assert.Nil(t, err)
+				assert.Equal(t, expected, decrypted)
+			} else {
+				assert.Equal(t, expected, []byte(value))
+			}
+		}
+	}
+	assert.Equal(t, uint64(persistPartSize), uploader.partSize)
+	assert.Equal(t, metadata, uploader.metadata)
+
+	uploader.failKey = "index_files/HNSW"
+	_, err = putStream(uploader, "index_files/HNSW", blob, metadata, nil)
+	assert.NotNil(t, err)
+}
+
+// discardUploader reads the streams part by part into a buffer of the part size as the multipart upload of minio
+// does, and discards them along with the values saved as a whole.
+type discardUploader struct {
+	*memkv.MemoryKV
+}
+
+func (m *discardUploader) Save(key, value string) error {
+	return nil
+}
+
+func (m *discardUploader) PutObject(key string, reader io.Reader, size int64, partSize uint64, metadata map[string]string) error {
+	part := make([]byte, partSize)
+	for {
+		if _, err := io.ReadFull(reader, part); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// samplePeakHeap samples the heap in use until the returned func is called, which returns the peak of the heap in
+// use above the one when the sampling starts.
+func samplePeakHeap() func() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	base, peak := stats.HeapInuse, stats.HeapInuse
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				runtime.ReadMemStats(&stats)
+				if stats.HeapInuse > peak {
+					peak = stats.HeapInuse
+				}
+			}
+		}
+	}()
+	return func() uint64 {
+		close(done)
+		wg.Wait()
+		return peak - base
+	}
+}
+
+// BenchmarkPersistIndexFiles compares the peak heap of persisting an index of 4 files of 64MB, whose binlogs are
+// copied out of the blobs of the engine before they are saved, or streamed from the blobs while they are uploaded.
+// The blobs are held by the engine in both, the streaming saves about the size of the index files.
+func BenchmarkPersistIndexFiles(b *testing.B) {
+	indexBlobs := make([]*Blob, 4)
+	paths := make([]string, len(indexBlobs)+1)
+	for i := range indexBlobs {
+		value := make([]byte, 64*1024*1024)
+		for j := range value {
+			value[j] = byte(i + j)
+		}
+		indexBlobs[i] = &Blob{Key: fmt.Sprintf("HNSW_%d", i), Value: value}
+	}
+	codec := storage.NewIndexFileBinlogCodec()
+	streams, err := codec.SerializeToStreams(1, 2, 3, 4, 5, 6, map[string]string{indexTypeKey: "HNSW"}, "index", 7, indexBlobs)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i, stream := range streams {
+		paths[i] = "index_files/" + stream.Key
+	}
+	objects := &discardUploader{MemoryKV: memkv.NewMemoryKV()}
+
+	// serialize returns the func saving each index file, which is called along with the saves in the sampling
+	persist := func(b *testing.B, serialize func() func(idx int) error) {
+		var peak uint64
+		for i := 0; i < b.N; i++ {
+			stop := samplePeakHeap()
+			report := newPersistReport()
+			report.persistFiles(paths, serialize(), "saveIndexFile")
+			if p := stop(); p > peak {
+				peak = p
+			}
+			if report.hasFailure() {
+				b.Fatal(report.failedFiles()[0].err)
+			}
+		}
+		b.ReportMetric(float64(peak)/(1024*1024), "peak-MB")
+	}
+
+	b.Run("copied", func(b *testing.B) {
+		persist(b, func() func(idx int) error {
+			// the binlogs of all the index files are serialized before any of them is saved
+			binlogs := make([][]byte, len(streams))
+			for i, stream := range streams {
+				binlogs[i], _ = ioutil.ReadAll(stream.NewReader())
+			}
+			return func(idx int) error {
+				return objects.Save(paths[idx], string(binlogs[idx]))
+			}
+		})
+	})
+
+	b.Run("streamed", func(b *testing.B) {
+		persist(b, func() func(idx int) error {
+			return func(idx int) error {
+				_, err := putStream(objects, paths[idx], streams[idx], nil, nil)
+				return err
+			}
+		})
+	})
+}
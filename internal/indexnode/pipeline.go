@@ -0,0 +1,386 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+// incrementalIndexTypes are the index types whose engine can be fed with the vectors chunk by chunk.
+var incrementalIndexTypes = map[string]bool{
+	"FLAT":     true,
+	"BIN_FLAT": true,
+}
+
+func supportsIncrementalAdd(indexType string) bool {
+	return incrementalIndexTypes[strings.ToUpper(indexType)]
+}
+
+// decodedBinlog is the vector field decoded from a binlog of the segment.
+type decodedBinlog struct {
+	collectionID UniqueID
+	partitionID  UniqueID
+	segmentID    UniqueID
+	fieldID      UniqueID
+	data         storage.FieldData
+}
+
+// decodeInsertBinlog decodes a binlog of the vector field to build the index on.
+func decodeInsertBinlog(blob *Blob) (*decodedBinlog, error) {
+	var insertCodec storage.InsertCodec
+	// the decoded data is copied out of the binlog readers, which can be closed once decoded
+	defer insertCodec.Close()
+	collectionID, partitionID, segmentID, insertData, err := insertCodec.DeserializeAll([]*Blob{blob})
+	if err != nil {
+		return nil, errorcode.Wrap(errorcode.InvalidParams, err)
+	}
+	if len(insertData.Data) != 1 {
+		return nil, errorcode.New(errorcode.InvalidParams, "we expect only one field in deserialized insert data")
+	}
+	decoded := &decodedBinlog{
+		collectionID: collectionID,
+		partitionID:  partitionID,
+		segmentID:    segmentID,
+	}
+	for fieldID, value := range insertData.Data {
+		decoded.fieldID = fieldID
+		decoded.data = value
+	}
+	return decoded, nil
+}
+
+// sortBinlogPaths returns the paths of the binlogs in the order the rows are decoded by InsertCodec.DeserializeAll,
+// so that the row offsets of the index are the same as the sequential build.
+func sortBinlogPaths(paths []string) []string {
+	blobs := make(storage.BlobList, len(paths))
+	for idx, path := range paths {
+		blobs[idx] = &Blob{Key: path}
+	}
+	sort.Sort(blobs)
+	sorted := make([]string, len(blobs))
+	for idx, blob := range blobs {
+		sorted[idx] = blob.Key
+	}
+	return sorted
+}
+
+// chunkFeeder buffers the decoded vectors and feeds them to the index chunk by chunk.
+type chunkFeeder struct {
+	index     IncrementalIndex
+	chunkRows int
+
+	floatData  []float32
+	binaryData []byte
+	// rowSize is the number of the elements of a row in floatData or binaryData
+	rowSize int
+	rows    int
+	// normalize is whether the float vectors are L2-normalized when they are buffered
+	normalize bool
+
+	addedRows int
+	chunkNum  int
+
+	sampledLog *log.Sampler
+}
+
+func newChunkFeeder(index IncrementalIndex, chunkRows int) *chunkFeeder {
+	return &chunkFeeder{
+		index:      index,
+		chunkRows:  chunkRows,
+		sampledLog: engineSampledLog,
+	}
+}
+
+// feed appends the vectors of a binlog and adds the full chunks to the index.
+func (f *chunkFeeder) feed(value storage.FieldData) error {
+	value = engineVectors(value)
+	_, rowSize, size, err := vectorLayout(value)
+	if err != nil {
+		return err
+	}
+	switch data := value.(type) {
+	case *storage.FloatVectorFieldData:
+		if f.binaryData != nil || (f.rowSize != 0 && f.rowSize != rowSize) {
+			return errorcode.New(errorcode.InvalidParams, "the decoded float vectors are inconsistent with the previous binlogs")
+		}
+		start := len(f.floatData)
+		f.floatData = append(f.floatData, data.Data...)
+		if f.normalize {
+			// the vectors are normalized in the chunk buffer, instead of another copy of them
+			normalizeVectors(f.floatData[start:], rowSize)
+		}
+	case *storage.BinaryVectorFieldData:
+		if f.floatData != nil || (f.rowSize != 0 && f.rowSize != rowSize) {
+			return errorcode.New(errorcode.InvalidParams, "the decoded binary vectors are inconsistent with the previous binlogs")
+		}
+		f.binaryData = append(f.binaryData, data.Data...)
+	}
+	f.rowSize = rowSize
+	f.rows += size / rowSize
+	for f.rows >= f.chunkRows {
+		if err := f.add(f.chunkRows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flush adds the remaining vectors to the index.
+func (f *chunkFeeder) flush() error {
+	if f.rows > 0 {
+		if err := f.add(f.rows); err != nil {
+			return err
+		}
+	}
+	if f.addedRows == 0 {
+		return errNoVectors
+	}
+	return nil
+}
+
+func (f *chunkFeeder) add(rows int) error {
+	size := rows * f.rowSize
+	var err error
+	if f.floatData != nil {
+		err = f.index.AddFloatVecIndexWithoutIds(f.floatData[:size])
+		f.floatData = append(f.floatData[:0], f.floatData[size:]...)
+	} else {
+		err = f.index.AddBinaryVecIndexWithoutIds(f.binaryData[:size])
+		f.binaryData = append(f.binaryData[:0], f.binaryData[size:]...)
+	}
+	if err != nil {
+		return err
+	}
+	f.rows -= rows
+	f.addedRows += rows
+	f.chunkNum++
+	f.sampledLog.Debug(logSiteChunk, "IndexNode added the chunk to the index", zap.Int("chunk", f.chunkNum),
+		zap.Int("rows", rows), zap.Int("addedRows", f.addedRows))
+	return nil
+}
+
+// binlogResult is a binlog downloaded and decoded by the pipeline.
+type binlogResult struct {
+	idx     int
+	size    int64
+	decoded *decodedBinlog
+	err     error
+}
+
+// binlogPipeline downloads, decodes and feeds the binlogs to the index concurrently, the binlogs are fed
+// in the order of paths, at most bufferSize binlogs are downloaded or decoded but not fed yet.
+type binlogPipeline struct {
+	paths      []string
+	reader     segmentReader
+	parallel   int
+	bufferSize int
+	feeder     *chunkFeeder
+	progress   *taskProgress
+	// check validates the vectors of the first binlog before they are fed, the rest are checked by the feeder
+	check func(value storage.FieldData) error
+
+	// the results of the pipeline
+	collectionID UniqueID
+	partitionID  UniqueID
+	segmentID    UniqueID
+	fieldID      UniqueID
+	elementType  schemapb.DataType
+	loadedBytes  int64
+	loadDuration time.Duration
+}
+
+func (p *binlogPipeline) run(ctx context.Context) error {
+	if len(p.paths) == 0 {
+		return errorcode.New(errorcode.InvalidParams, "no binlogs to build the index")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	start := time.Now()
+
+	slots := make(chan struct{}, p.bufferSize)
+	jobs := make(chan int)
+	results := make(chan *binlogResult, p.bufferSize)
+	go func() {
+		defer close(jobs)
+		for idx := range p.paths {
+			// the binlogs are dispatched in order, so the next binlog to feed always holds a slot
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- idx:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// the workers log with the request id of the task
+	downloadLog, decodeLog := sampledLogger(ctx, storageSampledLog), sampledLogger(ctx, engineSampledLog)
+	var mu sync.Mutex
+	var lastLoaded time.Time
+	var wg sync.WaitGroup
+	for i := 0; i < p.parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				result := &binlogResult{idx: idx}
+				loadStart := time.Now()
+				file, err := p.reader.load(p.paths[idx])
+				if err == nil {
+					mu.Lock()
+					lastLoaded = time.Now()
+					mu.Unlock()
+					downloadLog.Debug(logSiteDownload, "IndexNode downloaded the binlog", zap.String("path", p.paths[idx]),
+						zap.Int64("size", file.size), zap.Duration("duration", time.Since(loadStart)))
+					result.size = file.size
+					decodeStart := time.Now()
+					result.decoded, err = p.reader.decode(file)
+					if err == nil {
+						decodeLog.Debug(logSiteDecode, "IndexNode decoded the binlog", zap.String("path", p.paths[idx]),
+							zap.Duration("duration", time.Since(decodeStart)))
+					}
+				}
+				result.err = err
+				p.progress.advance()
+				// never blocks, the results in flight are bounded by the slots
+				results <- result
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	defer func() {
+		cancel()
+		for range results {
+		}
+	}()
+
+	pending := make(map[int]*binlogResult)
+	next := 0
+	for next < len(p.paths) {
+		var result *binlogResult
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case result = <-results:
+		}
+		if result.err != nil {
+			return fmt.Errorf("failed to load the binlog %s: %w", p.paths[result.idx], result.err)
+		}
+		pending[result.idx] = result
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			if err := p.feedBinlog(result); err != nil {
+				return err
+			}
+			<-slots
+			next++
+		}
+	}
+	mu.Lock()
+	p.loadDuration = lastLoaded.Sub(start)
+	mu.Unlock()
+	return p.feeder.flush()
+}
+
+func (p *binlogPipeline) feedBinlog(result *binlogResult) error {
+	decoded := result.decoded
+	if result.idx == 0 {
+		p.fieldID, p.elementType = decoded.fieldID, elementTypeOf(decoded.data)
+		if p.check != nil {
+			if err := p.check(decoded.data); err != nil {
+				return err
+			}
+		}
+	} else if decoded.fieldID != p.fieldID {
+		return errorcode.New(errorcode.InvalidParams, "we expect only one field in deserialized insert data")
+	} else if elementType := elementTypeOf(decoded.data); elementType != p.elementType {
+		// the vectors of the binlogs are all converted to float32 for the engine, which never detects the mismatch
+		return errorcode.Errorf(errorcode.InvalidParams, "the vectors of the binlog %s are %s, which differ from %s of the previous binlogs",
+			p.paths[result.idx], elementType.String(), p.elementType.String())
+	}
+	p.collectionID, p.partitionID, p.segmentID = decoded.collectionID, decoded.partitionID, decoded.segmentID
+	p.loadedBytes += result.size
+	if err := p.feeder.feed(decoded.data); err != nil {
+		return err
+	}
+	p.progress.advance()
+	return nil
+}
+
+// buildPipelined builds the index while the binlogs are being downloaded and decoded, instead of waiting for
+// all the data, whose row offsets are the same as the sequential build.
+func (it *IndexBuildTask) buildPipelined(ctx context.Context, index IncrementalIndex) (*binlogPipeline, error) {
+	it.setStage(taskStageBuild)
+	reader, err := it.segmentReader()
+	if err != nil {
+		return nil, err
+	}
+	pipeline := &binlogPipeline{
+		paths:      reader.sortPaths(it.req.GetDataPaths()),
+		reader:     reader,
+		parallel:   runtime.NumCPU(),
+		bufferSize: Params.BuildPipelineBufferSize,
+		feeder:     newChunkFeeder(index, Params.BuildChunkRows),
+		progress:   it.progress,
+	}
+	pipeline.check = func(value storage.FieldData) error {
+		if _, err := it.checkVectors(value); err != nil {
+			return err
+		}
+		pipeline.feeder.normalize = it.normalized
+		return nil
+	}
+	pipeline.feeder.sampledLog = sampledLogger(it.ctx, engineSampledLog)
+	if err := pipeline.run(ctx); err != nil {
+		it.logger(log.L()).Error("IndexNode pipelined build failed", zap.Int64("indexBuildID", it.req.IndexBuildID), zap.Error(err))
+		return nil, err
+	}
+	it.loadedBytes = pipeline.loadedBytes
+	it.stats.recordLoad(pipeline.loadedBytes, pipeline.loadDuration)
+	it.logger(log.L()).Debug("IndexNode pipelined build done", zap.Int64("indexBuildID", it.req.IndexBuildID),
+		zap.Int("binlogs", len(pipeline.paths)), zap.Int("rows", pipeline.feeder.addedRows),
+		zap.Int("chunks", pipeline.feeder.chunkNum))
+	return pipeline, nil
+}
+
+// incrementalIndex returns the index to build with the pipeline, it returns false if the index requires
+// all the data up front, which is built sequentially.
+func (it *IndexBuildTask) incrementalIndex(indexType string) (IncrementalIndex, bool) {
+	if Params.BuildChunkRows <= 0 || !supportsIncrementalAdd(indexType) {
+		return nil, false
+	}
+	index, ok := it.index.(IncrementalIndex)
+	return index, ok
+}
@@ -0,0 +1,222 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+	"github.com/milvus-io/milvus/internal/util/healthz"
+)
+
+const (
+	// LivezRouterPath is the path of the liveness probe of IndexNode.
+	LivezRouterPath = healthz.HealthzRouterPath
+	// ReadyzRouterPath is the path of the readiness probe of IndexNode.
+	ReadyzRouterPath = "/readyz"
+
+	probeEtcdSession = "etcd_session"
+	probeStorage     = "storage"
+	probeGrpcServing = "grpc_serving"
+	probeStateCode   = "state_code"
+	probeLoop        = "loop"
+	probeSelfTest    = "self_test"
+
+	probeCheckPassed = "ok"
+
+	probeObjectPrefix = ".probe"
+)
+
+var errProbeNotChecked = errors.New("not checked yet")
+
+// ProbeResult is the json body returned by the liveness and readiness probes.
+type ProbeResult struct {
+	Passed bool              `json:"passed"`
+	Checks map[string]string `json:"checks"`
+}
+
+// readinessProbe records the results of the sub checks which decide whether IndexNode is ready to serve.
+type readinessProbe struct {
+	mu          sync.RWMutex
+	checks      map[string]error
+	updateTimes map[string]time.Time
+}
+
+func newReadinessProbe() *readinessProbe {
+	return &readinessProbe{
+		checks: map[string]error{
+			probeEtcdSession: errProbeNotChecked,
+			probeStorage:     errProbeNotChecked,
+		},
+		updateTimes: make(map[string]time.Time),
+	}
+}
+
+func (p *readinessProbe) update(name string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	old, ok := p.checks[name]
+	p.checks[name] = err
+	p.updateTimes[name] = time.Now()
+	if ok && (old == nil) != (err == nil) {
+		log.Debug("IndexNode readiness check changed", zap.String("check", name), zap.Error(err))
+	}
+}
+
+// check returns the last time the sub check was updated and its result.
+func (p *readinessProbe) check(name string) (time.Time, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	err, ok := p.checks[name]
+	if !ok {
+		return time.Time{}, errProbeNotChecked
+	}
+	return p.updateTimes[name], err
+}
+
+func (p *readinessProbe) passed() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, err := range p.checks {
+		if err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *readinessProbe) result() *ProbeResult {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	ret := &ProbeResult{
+		Passed: true,
+		Checks: make(map[string]string, len(p.checks)),
+	}
+	for name, err := range p.checks {
+		if err != nil {
+			ret.Passed = false
+			ret.Checks[name] = err.Error()
+		} else {
+			ret.Checks[name] = probeCheckPassed
+		}
+	}
+	return ret
+}
+
+// SetGrpcServing is called by the grpc server of IndexNode to report whether it is serving,
+// the grpc serving check only takes part in the readiness once it has been reported.
+func (i *IndexNode) SetGrpcServing(serving bool) {
+	if serving {
+		i.probe.update(probeGrpcServing, nil)
+		return
+	}
+	i.probe.update(probeGrpcServing, errors.New("grpc server is not serving"))
+}
+
+// Liveness returns the liveness of IndexNode, the process is considered alive until it is stopped.
+func (i *IndexNode) Liveness() *ProbeResult {
+	ret := &ProbeResult{
+		Passed: true,
+		Checks: map[string]string{probeLoop: probeCheckPassed},
+	}
+	if err := i.loopCtx.Err(); err != nil {
+		ret.Passed = false
+		ret.Checks[probeLoop] = err.Error()
+	}
+	return ret
+}
+
+// Readiness returns the readiness of IndexNode with the detail of all sub checks.
+func (i *IndexNode) Readiness() *ProbeResult {
+	ret := i.probe.result()
+	code := i.stateCode.Load().(internalpb.StateCode)
+	if code == internalpb.StateCode_Healthy {
+		ret.Checks[probeStateCode] = probeCheckPassed
+	} else {
+		ret.Passed = false
+		ret.Checks[probeStateCode] = code.String()
+	}
+	return ret
+}
+
+// ProbeHandler returns the http handler serving the liveness and readiness probes, the log levels, and the pprof
+// endpoints if the profiling is enabled, of IndexNode.
+func (i *IndexNode) ProbeHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivezRouterPath, func(w http.ResponseWriter, r *http.Request) {
+		writeProbeResult(w, i.Liveness())
+	})
+	mux.HandleFunc(ReadyzRouterPath, func(w http.ResponseWriter, r *http.Request) {
+		writeProbeResult(w, i.Readiness())
+	})
+	mux.HandleFunc(LogLevelRouterPath, handleLogLevel)
+	if Params.ProfilingEnabled {
+		mux.HandleFunc(ProfilingRouterPath, profilingHandler)
+	}
+	return mux
+}
+
+// probeObjectKey returns the key of the storage check object of the node. The storage is checked before the NodeID
+// is assigned by the registration, so the key is made unique by the pid and a random suffix, which keeps the nodes
+// starting at the same time from removing the objects of each other.
+func (i *IndexNode) probeObjectKey() string {
+	i.probeKeyOnce.Do(func() {
+		i.probeKey = fmt.Sprintf("%d-%x", os.Getpid(), rand.Int63())
+	})
+	return path.Join(Params.IndexRootPath, probeObjectPrefix, strconv.FormatInt(Params.NodeID, 10)+"-"+i.probeKey)
+}
+
+// checkStorage verifies that the object storage can be written, read and cleaned by IndexNode.
+func (i *IndexNode) checkStorage() error {
+	key := i.probeObjectKey()
+	value := time.Now().String()
+	if err := i.kv.Save(key, value); err != nil {
+		return fmt.Errorf("failed to write object storage: %s", err.Error())
+	}
+	defer func() {
+		if err := i.kv.Remove(key); err != nil {
+			log.Warn("IndexNode failed to remove the storage check object", zap.String("key", key), zap.Error(err))
+		}
+	}()
+	loaded, err := i.kv.Load(key)
+	if err != nil {
+		return fmt.Errorf("failed to read object storage: %s", err.Error())
+	}
+	if loaded != value {
+		return errors.New("the object read from storage mismatches the one written")
+	}
+	return nil
+}
+
+func writeProbeResult(w http.ResponseWriter, result *ProbeResult) {
+	w.Header().Set(healthz.ContentTypeHeader, healthz.ContentTypeJSON)
+	if result.Passed {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Warn("failed to send response", zap.Error(err))
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+	"github.com/stretchr/testify/assert"
+)
+
+func probe(t *testing.T, handler http.Handler, path string) (int, *ProbeResult) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	result := &ProbeResult{}
+	err := json.Unmarshal(w.Body.Bytes(), result)
+	assert.Nil(t, err)
+	return w.Code, result
+}
+
+func TestReadinessProbe(t *testing.T) {
+	p := newReadinessProbe()
+	assert.False(t, p.passed())
+	ret := p.result()
+	assert.False(t, ret.Passed)
+	assert.Equal(t, errProbeNotChecked.Error(), ret.Checks[probeEtcdSession])
+	assert.Equal(t, errProbeNotChecked.Error(), ret.Checks[probeStorage])
+
+	p.update(probeEtcdSession, nil)
+	p.update(probeStorage, nil)
+	assert.True(t, p.passed())
+	assert.Equal(t, probeCheckPassed, p.result().Checks[probeStorage])
+
+	p.update(probeStorage, errors.New("bucket not found"))
+	assert.False(t, p.passed())
+	assert.Equal(t, "bucket not found", p.result().Checks[probeStorage])
+}
+
+func TestIndexNode_Probes(t *testing.T) {
+	in, err := NewIndexNode(context.Background())
+	assert.Nil(t, err)
+	handler := in.ProbeHandler()
+
+	code, ret := probe(t, handler, LivezRouterPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, ret.Passed)
+
+	// just created, nothing is checked
+	code, ret = probe(t, handler, ReadyzRouterPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, ret.Passed)
+	assert.Equal(t, internalpb.StateCode_Abnormal.String(), ret.Checks[probeStateCode])
+
+	// grpc server starts, then session registered and storage checked during Register and Init
+	in.SetGrpcServing(false)
+	in.probe.update(probeEtcdSession, nil)
+	in.probe.update(probeStorage, nil)
+	in.UpdateStateCode(internalpb.StateCode_Healthy)
+	code, ret = probe(t, handler, ReadyzRouterPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.NotEqual(t, probeCheckPassed, ret.Checks[probeGrpcServing])
+	assert.Equal(t, internalpb.StateCode_Abnormal, in.stateCodeWithProbe())
+
+	in.SetGrpcServing(true)
+	code, ret = probe(t, handler, ReadyzRouterPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, ret.Passed)
+	for name, check := range ret.Checks {
+		assert.Equal(t, probeCheckPassed, check, name)
+	}
+	assert.Equal(t, internalpb.StateCode_Healthy, in.stateCodeWithProbe())
+
+	// session lost
+	in.probe.update(probeEtcdSession, errors.New("etcd session expired"))
+	code, ret = probe(t, handler, ReadyzRouterPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "etcd session expired", ret.Checks[probeEtcdSession])
+	in.probe.update(probeEtcdSession, nil)
+
+	// shutdown
+	err = in.Stop()
+	assert.Nil(t, err)
+	code, _ = probe(t, handler, ReadyzRouterPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	code, ret = probe(t, handler, LivezRouterPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, ret.Passed)
+	assert.Equal(t, internalpb.StateCode_Abnormal, in.stateCodeWithProbe())
+}
+
+func TestIndexNode_checkStorage(t *testing.T) {
+	oldNodeID := Params.NodeID
+	defer func() {
+		Params.NodeID = oldNodeID
+	}()
+
+	// the nodes starting at the same time check the storage before their NodeIDs are assigned
+	Params.NodeID = 0
+	storage := memkv.NewMemoryKV()
+	first, second := &IndexNode{kv: storage}, &IndexNode{kv: storage}
+	key := first.probeObjectKey()
+	assert.NotEqual(t, key, second.probeObjectKey())
+	assert.Equal(t, key, first.probeObjectKey())
+	assert.True(t, strings.HasPrefix(key, path.Join(Params.IndexRootPath, probeObjectPrefix, "0-")))
+
+	assert.Nil(t, first.checkStorage())
+	assert.Nil(t, second.checkStorage())
+	keys, _, err := storage.LoadWithPrefix(path.Join(Params.IndexRootPath, probeObjectPrefix))
+	assert.Nil(t, err)
+	assert.Empty(t, keys)
+}
@@ -0,0 +1,208 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+const (
+	// ProfilingRouterPath is the path prefix of the pprof endpoints of IndexNode, which are served only if the
+	// profiling is enabled.
+	ProfilingRouterPath = "/debug/pprof/"
+	// ProfilingSecretHeader is the header carrying the shared secret of the pprof endpoints.
+	ProfilingSecretHeader = "X-Profiling-Secret"
+)
+
+const (
+	// profileTypeCPU is the cpu profile sampled for a duration, the other types are the runtime profiles,
+	// e.g. heap, goroutine, allocs, block, mutex and threadcreate
+	profileTypeCPU = "cpu"
+	// profileCPURouterName is the name of the cpu profile endpoint, the same as net/http/pprof
+	profileCPURouterName = "profile"
+
+	defaultProfileDuration = 30 * time.Second
+	maxProfileDuration     = 10 * time.Minute
+)
+
+// checkProfile checks the type and the sampling duration of the profile to capture.
+func checkProfile(profileType string, duration time.Duration) error {
+	if profileType == profileTypeCPU {
+		if duration <= 0 || duration > maxProfileDuration {
+			return fmt.Errorf("the duration of the cpu profile must be in (0, %s], got %s", maxProfileDuration, duration)
+		}
+		return nil
+	}
+	if pprof.Lookup(profileType) == nil {
+		return fmt.Errorf("unknown profile type %q", profileType)
+	}
+	return nil
+}
+
+// writeProfile writes the profile in the pprof format to @w. The cpu profile is sampled for @duration, or until
+// @ctx is done, the other profiles are the snapshots of now, and are written in text if @debug is not 0.
+func writeProfile(ctx context.Context, w io.Writer, profileType string, duration time.Duration, debug int, gc bool) error {
+	if profileType != profileTypeCPU {
+		if gc && profileType == "heap" {
+			runtime.GC()
+		}
+		return pprof.Lookup(profileType).WriteTo(w, debug)
+	}
+	if err := pprof.StartCPUProfile(w); err != nil {
+		return err
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		pprof.StopCPUProfile()
+		return nil
+	case <-ctx.Done():
+		pprof.StopCPUProfile()
+		return ctx.Err()
+	}
+}
+
+// profilingHandler serves the index of the profiles, the cpu profile on /debug/pprof/profile?seconds=N, and the
+// runtime profiles on /debug/pprof/<name>?debug=N&gc=N, the same query parameters as net/http/pprof, which is
+// not imported to keep the endpoints away from http.DefaultServeMux.
+func profilingHandler(w http.ResponseWriter, r *http.Request) {
+	if Params.ProfilingSecret != "" &&
+		subtle.ConstantTimeCompare([]byte(r.Header.Get(ProfilingSecretHeader)), []byte(Params.ProfilingSecret)) != 1 {
+		http.Error(w, "invalid profiling secret", http.StatusUnauthorized)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, ProfilingRouterPath)
+	if name == "" {
+		writeProfileIndex(w)
+		return
+	}
+
+	profileType, duration := name, time.Duration(0)
+	if name == profileCPURouterName {
+		profileType, duration = profileTypeCPU, defaultProfileDuration
+		if seconds := r.FormValue("seconds"); seconds != "" {
+			value, err := strconv.ParseInt(seconds, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid seconds: "+seconds, http.StatusBadRequest)
+				return
+			}
+			duration = time.Duration(value) * time.Second
+		}
+	}
+	if err := checkProfile(profileType, duration); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	debug, _ := strconv.Atoi(r.FormValue("debug"))
+	gc, _ := strconv.Atoi(r.FormValue("gc"))
+
+	buf := &bytes.Buffer{}
+	if err := writeProfile(r.Context(), buf, profileType, duration, debug, gc > 0); err != nil {
+		http.Error(w, "failed to capture the profile: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if debug != 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, profileType))
+	}
+	_, _ = w.Write(buf.Bytes())
+}
+
+func writeProfileIndex(w http.ResponseWriter) {
+	profiles := pprof.Profiles()
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name() < profiles[j].Name()
+	})
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "%s%s?seconds=%d\n", ProfilingRouterPath, profileCPURouterName, int(defaultProfileDuration.Seconds()))
+	for _, profile := range profiles {
+		fmt.Fprintf(w, "%s%s?debug=1 (%d)\n", ProfilingRouterPath, profile.Name(), profile.Count())
+	}
+}
+
+// CaptureProfile captures a profile of IndexNode and uploads it to the object storage, so that it can be retrieved
+// without the access to the node. The cpu profile is sampled for the duration of the request, the other profiles
+// are the snapshots of now.
+func (i *IndexNode) CaptureProfile(ctx context.Context, req *indexpb.CaptureProfileRequest) (*indexpb.CaptureProfileResponse, error) {
+	if !Params.ProfilingEnabled {
+		return &indexpb.CaptureProfileResponse{
+			Status: &commonpb.Status{
+				ErrorCode: commonpb.ErrorCode_UnexpectedError,
+				Reason:    errorcode.Format(errorcode.InvalidParams, fmt.Sprintf("profiling is disabled on IndexNode %d", Params.NodeID)),
+			},
+		}, nil
+	}
+	if !i.isHealthy() {
+		return &indexpb.CaptureProfileResponse{Status: i.notReadyStatus()}, nil
+	}
+	duration := time.Duration(req.DurationSeconds) * time.Second
+	if err := checkProfile(req.ProfileType, duration); err != nil {
+		return &indexpb.CaptureProfileResponse{
+			Status: &commonpb.Status{
+				ErrorCode: commonpb.ErrorCode_IllegalArgument,
+				Reason:    errorcode.Format(errorcode.InvalidParams, err.Error()),
+			},
+		}, nil
+	}
+
+	log.Info("IndexNode captures the profile", zap.String("type", req.ProfileType), zap.Duration("duration", duration))
+	buf := &bytes.Buffer{}
+	if err := writeProfile(ctx, buf, req.ProfileType, duration, 0, false); err != nil {
+		log.Warn("IndexNode failed to capture the profile", zap.String("type", req.ProfileType), zap.Error(err))
+		return &indexpb.CaptureProfileResponse{
+			Status: &commonpb.Status{
+				ErrorCode: commonpb.ErrorCode_UnexpectedError,
+				Reason:    errorcode.Format(classifyError(err), "failed to capture the profile: "+err.Error()),
+			},
+		}, nil
+	}
+	key := path.Join(Params.ProfilingUploadPath, strconv.FormatInt(Params.NodeID, 10),
+		fmt.Sprintf("%s-%s.pprof", req.ProfileType, time.Now().Format("20060102-150405")))
+	if err := storageError(i.kv.Save(key, buf.String())); err != nil {
+		log.Warn("IndexNode failed to upload the profile", zap.String("key", key), zap.Error(err))
+		return &indexpb.CaptureProfileResponse{
+			Status: &commonpb.Status{
+				ErrorCode: commonpb.ErrorCode_UnexpectedError,
+				Reason:    errorcode.Format(classifyError(err), "failed to upload the profile: "+err.Error()),
+			},
+		}, nil
+	}
+	log.Info("IndexNode uploaded the profile", zap.String("key", key), zap.Int("size", buf.Len()))
+	return &indexpb.CaptureProfileResponse{
+		Status: &commonpb.Status{ErrorCode: commonpb.ErrorCode_Success},
+		Path:   key,
+		Size:   int64(buf.Len()),
+	}, nil
+}
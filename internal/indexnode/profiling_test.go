@@ -0,0 +1,173 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+)
+
+type faultyProfileKV struct {
+	*memkv.MemoryKV
+}
+
+func (kv *faultyProfileKV) Save(key, value string) error {
+	return errors.New("bucket not found")
+}
+
+func requestProfile(handler http.Handler, target string, secret string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if secret != "" {
+		req.Header.Set(ProfilingSecretHeader, secret)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestIndexNode_ProfilingHandler(t *testing.T) {
+	oldEnabled, oldSecret := Params.ProfilingEnabled, Params.ProfilingSecret
+	defer func() {
+		Params.ProfilingEnabled, Params.ProfilingSecret = oldEnabled, oldSecret
+	}()
+
+	// the routes are not registered at all if disabled
+	Params.ProfilingEnabled = false
+	w := requestProfile((&IndexNode{}).ProbeHandler(), ProfilingRouterPath+"heap", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NotContains(t, w.Body.String(), "heap profile")
+
+	Params.ProfilingEnabled, Params.ProfilingSecret = true, ""
+	handler := (&IndexNode{}).ProbeHandler()
+	w = requestProfile(handler, ProfilingRouterPath, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), ProfilingRouterPath+"heap?debug=1")
+	assert.Contains(t, w.Body.String(), ProfilingRouterPath+"profile?seconds=30")
+
+	w = requestProfile(handler, ProfilingRouterPath+"heap?debug=1&gc=1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "heap profile")
+	w = requestProfile(handler, ProfilingRouterPath+"goroutine", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+	assert.NotZero(t, w.Body.Len())
+	w = requestProfile(handler, ProfilingRouterPath+"profile?seconds=1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotZero(t, w.Body.Len())
+
+	assert.Equal(t, http.StatusNotFound, requestProfile(handler, ProfilingRouterPath+"unknown", "").Code)
+	assert.Equal(t, http.StatusNotFound, requestProfile(handler, ProfilingRouterPath+"profile?seconds=0", "").Code)
+	assert.Equal(t, http.StatusBadRequest, requestProfile(handler, ProfilingRouterPath+"profile?seconds=x", "").Code)
+
+	Params.ProfilingSecret = "s3cret"
+	assert.Equal(t, http.StatusUnauthorized, requestProfile(handler, ProfilingRouterPath+"heap", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, requestProfile(handler, ProfilingRouterPath+"heap", "wrong").Code)
+	assert.Equal(t, http.StatusOK, requestProfile(handler, ProfilingRouterPath+"heap", "s3cret").Code)
+}
+
+func TestWriteProfile_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	start := time.Now()
+	err := writeProfile(ctx, &strings.Builder{}, profileTypeCPU, maxProfileDuration, 0, false)
+	assert.Equal(t, context.Canceled, err)
+	assert.Less(t, int64(time.Since(start)), int64(time.Minute))
+}
+
+func TestIndexNode_CaptureProfile(t *testing.T) {
+	oldEnabled, oldNodeID, oldPath := Params.ProfilingEnabled, Params.NodeID, Params.ProfilingUploadPath
+	defer func() {
+		Params.ProfilingEnabled, Params.NodeID, Params.ProfilingUploadPath = oldEnabled, oldNodeID, oldPath
+	}()
+	Params.NodeID, Params.ProfilingUploadPath = 7, "support/profiles"
+
+	in, err := NewIndexNode(context.Background())
+	assert.Nil(t, err)
+	kv := memkv.NewMemoryKV()
+	in.kv = kv
+	ctx := context.Background()
+
+	Params.ProfilingEnabled = false
+	resp, err := in.CaptureProfile(ctx, &indexpb.CaptureProfileRequest{ProfileType: "heap"})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.Status.ErrorCode)
+	assert.Contains(t, resp.Status.Reason, "disabled")
+
+	Params.ProfilingEnabled = true
+	resp, err = in.CaptureProfile(ctx, &indexpb.CaptureProfileRequest{ProfileType: "heap"})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.Status.ErrorCode)
+
+	in.probe.update(probeEtcdSession, nil)
+	in.probe.update(probeStorage, nil)
+	in.SetGrpcServing(true)
+	in.UpdateStateCode(internalpb.StateCode_Healthy)
+
+	t.Run("heap", func(t *testing.T) {
+		resp, err := in.CaptureProfile(ctx, &indexpb.CaptureProfileRequest{ProfileType: "heap"})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+		assert.True(t, strings.HasPrefix(resp.Path, "support/profiles/7/heap-"))
+		assert.True(t, strings.HasSuffix(resp.Path, ".pprof"))
+		value, err := kv.Load(resp.Path)
+		assert.Nil(t, err)
+		assert.Equal(t, resp.Size, int64(len(value)))
+		assert.NotZero(t, resp.Size)
+	})
+
+	t.Run("cpu", func(t *testing.T) {
+		resp, err := in.CaptureProfile(ctx, &indexpb.CaptureProfileRequest{ProfileType: profileTypeCPU, DurationSeconds: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+		assert.True(t, strings.HasPrefix(resp.Path, "support/profiles/7/cpu-"))
+		_, err = kv.Load(resp.Path)
+		assert.Nil(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, req := range []*indexpb.CaptureProfileRequest{
+			{ProfileType: "unknown"},
+			{ProfileType: profileTypeCPU},
+			{ProfileType: profileTypeCPU, DurationSeconds: int64(maxProfileDuration/time.Second) + 1},
+		} {
+			resp, err := in.CaptureProfile(ctx, req)
+			assert.Nil(t, err)
+			assert.Equal(t, commonpb.ErrorCode_IllegalArgument, resp.Status.ErrorCode)
+		}
+	})
+
+	t.Run("upload failed", func(t *testing.T) {
+		in.kv = &faultyProfileKV{MemoryKV: kv}
+		defer func() {
+			in.kv = kv
+		}()
+		resp, err := in.CaptureProfile(ctx, &indexpb.CaptureProfileRequest{ProfileType: "goroutine"})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.Status.ErrorCode)
+		assert.Contains(t, resp.Status.Reason, "bucket not found")
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"fmt"
+
+	"github.com/milvus-io/milvus/internal/metrics"
+	"github.com/milvus-io/milvus/internal/util/ratelimit"
+)
+
+// defaultRateLimits are the rate limits of the methods, far above the rates of IndexCoord, which only protect the
+// node from the buggy clients flooding it.
+const defaultRateLimits = "CreateIndex:100:200,DryRunCreateIndex:100:200,GetMetrics:50:100"
+
+// rateLimitedMethods are the methods submitting and querying the tasks which can be rate limited, the internal and
+// the administrative ones like GetComponentStates, Activate, CaptureProfile, VerifyIndexManifest, RunBuildBenchmark
+// and RebuildIncompatibleIndexes are never limited.
+var rateLimitedMethods = []string{"CreateIndex", "DryRunCreateIndex", "GetMetrics"}
+
+// parseRateLimits parses the rate limits in the form of "method:rate[:burst],...", all of the methods must be
+// rateLimitedMethods.
+func parseRateLimits(s string) (map[string]ratelimit.Limit, error) {
+	limits, err := ratelimit.ParseLimits(s)
+	if err != nil {
+		return nil, err
+	}
+	for method := range limits {
+		if !isRateLimitedMethod(method) {
+			return nil, fmt.Errorf("method %s is not rate limited, expect the methods of %v", method,
+				rateLimitedMethods)
+		}
+	}
+	return limits, nil
+}
+
+func isRateLimitedMethod(method string) bool {
+	for _, m := range rateLimitedMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func newRateLimiter() *ratelimit.Limiter {
+	return ratelimit.NewLimiter(rateLimitedMethods, func(method string) {
+		metrics.IndexNodeThrottledRequests.WithLabelValues(method).Inc()
+	})
+}
+
+// RateLimiter returns the limiter of the rates of the calls to the node, which the grpc server intercepts the
+// calls with.
+func (i *IndexNode) RateLimiter() *ratelimit.Limiter {
+	return i.rateLimiter
+}
@@ -0,0 +1,261 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/metricsinfo"
+)
+
+// rebuildIdleCheckInterval is the interval to check whether the task queue is idle to run a rebuild
+const rebuildIdleCheckInterval = time.Second
+
+var (
+	errRebuildDisabled = errors.New("rebuilding incompatible indexes is disabled, enable it by indexNode.autoRebuildIncompatible")
+	errRebuildRunning  = errors.New("rebuilding incompatible indexes is already running")
+)
+
+// rebuildMetaKV is the storage of the index meta, which is implemented by etcdkv.EtcdKV.
+type rebuildMetaKV interface {
+	LoadWithPrefix2(key string) ([]string, []string, []int64, error)
+	CompareVersionAndSwap(key string, version int64, target string, opts ...clientv3.OpOption) error
+}
+
+// rebuildCandidate is an index built by this node, which is too old to be loaded by the current engine.
+type rebuildCandidate struct {
+	key          string
+	metaRevision int64
+	indexMeta    *indexpb.IndexMeta
+}
+
+// rebuilder rebuilds the incompatible indexes built by this node. The rebuilds are in low priority,
+// a rebuild only starts when no task is waiting in the task queue, and at most concurrency rebuilds run at a time.
+// The rebuilds are built by the low-priority class of the task queue, within the build parallelism of the node.
+type rebuilder struct {
+	metaKV      rebuildMetaKV
+	nodeID      UniqueID
+	concurrency int
+
+	// isIdle returns whether no task is waiting in the task queue
+	isIdle func() bool
+	// build builds the index of the request and returns the error the build finishes with
+	build func(ctx context.Context, req *indexpb.CreateIndexRequest) error
+
+	mu       sync.Mutex
+	progress metricsinfo.IndexRebuildProgress
+}
+
+func newRebuilder(metaKV rebuildMetaKV, nodeID UniqueID, concurrency int, isIdle func() bool,
+	build func(ctx context.Context, req *indexpb.CreateIndexRequest) error) *rebuilder {
+	return &rebuilder{
+		metaKV:      metaKV,
+		nodeID:      nodeID,
+		concurrency: concurrency,
+		isIdle:      isIdle,
+		build:       build,
+		progress: metricsinfo.IndexRebuildProgress{
+			RebuiltBuildIDs: []int64{},
+			FailedBuilds:    map[int64]string{},
+		},
+	}
+}
+
+// start scans the index meta and rebuilds the incompatible indexes in background.
+func (r *rebuilder) start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.progress.Running {
+		return errRebuildRunning
+	}
+	r.progress = metricsinfo.IndexRebuildProgress{
+		Running:         true,
+		StartTime:       time.Now().String(),
+		RebuiltBuildIDs: []int64{},
+		FailedBuilds:    map[int64]string{},
+	}
+	go r.run(ctx)
+	return nil
+}
+
+// getProgress returns a copy of the progress of the last run.
+func (r *rebuilder) getProgress() metricsinfo.IndexRebuildProgress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	progress := r.progress
+	progress.RebuiltBuildIDs = append([]int64{}, r.progress.RebuiltBuildIDs...)
+	progress.FailedBuilds = make(map[int64]string, len(r.progress.FailedBuilds))
+	for buildID, reason := range r.progress.FailedBuilds {
+		progress.FailedBuilds[buildID] = reason
+	}
+	return progress
+}
+
+func (r *rebuilder) run(ctx context.Context) {
+	defer func() {
+		r.mu.Lock()
+		r.progress.Running = false
+		r.progress.FinishTime = time.Now().String()
+		r.mu.Unlock()
+	}()
+
+	candidates, scanned, err := r.scan()
+	r.mu.Lock()
+	r.progress.ScannedNum = int64(scanned)
+	r.progress.CandidateNum = int64(len(candidates))
+	r.progress.PendingNum = int64(len(candidates))
+	if err != nil {
+		r.progress.Error = err.Error()
+	}
+	r.mu.Unlock()
+	if err != nil {
+		log.Warn("IndexNode failed to scan the index meta for incompatible indexes", zap.Error(err))
+		return
+	}
+	log.Info("IndexNode start to rebuild incompatible indexes", zap.Int("scanned", scanned), zap.Int("candidates", len(candidates)))
+
+	candidateCh := make(chan *rebuildCandidate, len(candidates))
+	for _, candidate := range candidates {
+		candidateCh <- candidate
+	}
+	close(candidateCh)
+
+	concurrency := r.concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	var wg sync.WaitGroup
+	for idx := 0; idx < concurrency; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for candidate := range candidateCh {
+				if !r.waitIdle(ctx) {
+					return
+				}
+				r.rebuild(ctx, candidate)
+			}
+		}()
+	}
+	wg.Wait()
+	log.Info("IndexNode finish rebuilding incompatible indexes", zap.Any("progress", r.getProgress()))
+}
+
+// scan returns the incompatible indexes built by this node, along with the number of the index meta scanned.
+func (r *rebuilder) scan() ([]*rebuildCandidate, int, error) {
+	keys, values, revisions, err := r.metaKV.LoadWithPrefix2(indexMetaPrefix)
+	if err != nil {
+		return nil, 0, err
+	}
+	current := currentArtifactVersion()
+	candidates := make([]*rebuildCandidate, 0)
+	for idx, value := range values {
+		indexMeta := &indexpb.IndexMeta{}
+		if err := proto.Unmarshal([]byte(value), indexMeta); err != nil {
+			log.Warn("IndexNode failed to unmarshal the index meta", zap.String("key", keys[idx]), zap.Error(err))
+			continue
+		}
+		if indexMeta.NodeID != r.nodeID || indexMeta.State != commonpb.IndexState_Finished ||
+			indexMeta.MarkDeleted || indexMeta.Req == nil {
+			continue
+		}
+		if !isBelowMinimumVersion(indexMeta.ArtifactVersion, current) {
+			continue
+		}
+		candidates = append(candidates, &rebuildCandidate{
+			key:          path.Join(indexMetaPrefix, strconv.FormatInt(indexMeta.IndexBuildID, 10)),
+			metaRevision: revisions[idx],
+			indexMeta:    indexMeta,
+		})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].indexMeta.IndexBuildID < candidates[j].indexMeta.IndexBuildID
+	})
+	return candidates, len(values), nil
+}
+
+// waitIdle waits until the task queue is idle, it returns false if the context is done.
+func (r *rebuilder) waitIdle(ctx context.Context) bool {
+	ticker := time.NewTicker(rebuildIdleCheckInterval)
+	defer ticker.Stop()
+	for !r.isIdle() {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+	return ctx.Err() == nil
+}
+
+// rebuild assigns the index to this node with a new version, and builds it.
+func (r *rebuilder) rebuild(ctx context.Context, candidate *rebuildCandidate) {
+	indexMeta := proto.Clone(candidate.indexMeta).(*indexpb.IndexMeta)
+	buildID := indexMeta.IndexBuildID
+	indexMeta.Version++
+	indexMeta.State = commonpb.IndexState_InProgress
+	indexMeta.NodeID = r.nodeID
+	indexMeta.FailReason = ""
+	// let IndexCoord recycle the index files of the old version once the rebuild finishes
+	indexMeta.Recycled = false
+
+	r.mu.Lock()
+	r.progress.PendingNum--
+	r.progress.RebuildingNum++
+	r.mu.Unlock()
+
+	err := func() error {
+		value, err := proto.Marshal(indexMeta)
+		if err != nil {
+			return err
+		}
+		// fails if the meta is changed since the scan, such as the index is dropped
+		if err := r.metaKV.CompareVersionAndSwap(candidate.key, candidate.metaRevision, string(value)); err != nil {
+			return err
+		}
+		req := indexMeta.Req
+		return r.build(ctx, &indexpb.CreateIndexRequest{
+			IndexBuildID: buildID,
+			IndexName:    req.IndexName,
+			IndexID:      req.IndexID,
+			Version:      indexMeta.Version,
+			MetaPath:     "/" + candidate.key,
+			DataPaths:    req.DataPaths,
+			TypeParams:   req.TypeParams,
+			IndexParams:  req.IndexParams,
+		})
+	}()
+	log.Debug("IndexNode rebuild incompatible index", zap.Int64("indexBuildID", buildID),
+		zap.Int64("version", indexMeta.Version), zap.Error(err))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress.RebuildingNum--
+	if err != nil {
+		r.progress.FailedBuilds[buildID] = err.Error()
+		return
+	}
+	r.progress.RebuiltBuildIDs = append(r.progress.RebuiltBuildIDs, buildID)
+}
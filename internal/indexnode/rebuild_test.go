@@ -0,0 +1,362 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/milvuspb"
+	"github.com/milvus-io/milvus/internal/util/metricsinfo"
+)
+
+type mockRebuildMetaKV struct {
+	mu        sync.Mutex
+	values    map[string]string
+	revisions map[string]int64
+	loadErr   error
+}
+
+func newMockRebuildMetaKV() *mockRebuildMetaKV {
+	return &mockRebuildMetaKV{
+		values:    make(map[string]string),
+		revisions: make(map[string]int64),
+	}
+}
+
+func (m *mockRebuildMetaKV) save(t *testing.T, indexMeta *indexpb.IndexMeta) {
+	value, err := proto.Marshal(indexMeta)
+	assert.Nil(t, err)
+	key := path.Join(indexMetaPrefix, strconv.FormatInt(indexMeta.IndexBuildID, 10))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = string(value)
+	m.revisions[key]++
+}
+
+func (m *mockRebuildMetaKV) load(t *testing.T, buildID UniqueID) *indexpb.IndexMeta {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	indexMeta := &indexpb.IndexMeta{}
+	err := proto.Unmarshal([]byte(m.values[path.Join(indexMetaPrefix, strconv.FormatInt(buildID, 10))]), indexMeta)
+	assert.Nil(t, err)
+	return indexMeta
+}
+
+func (m *mockRebuildMetaKV) LoadWithPrefix2(key string) ([]string, []string, []int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.loadErr != nil {
+		return nil, nil, nil, m.loadErr
+	}
+	keys := make([]string, 0, len(m.values))
+	for k := range m.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]string, 0, len(keys))
+	revisions := make([]int64, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, m.values[k])
+		revisions = append(revisions, m.revisions[k])
+	}
+	return keys, values, revisions, nil
+}
+
+func (m *mockRebuildMetaKV) CompareVersionAndSwap(key string, version int64, target string, opts ...clientv3.OpOption) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.revisions[key] != version {
+		return errors.New("the meta is changed")
+	}
+	m.values[key] = target
+	m.revisions[key]++
+	return nil
+}
+
+func newRebuildIndexMeta(buildID UniqueID, nodeID UniqueID, version *indexpb.IndexArtifactVersion) *indexpb.IndexMeta {
+	return &indexpb.IndexMeta{
+		IndexBuildID: buildID,
+		State:        commonpb.IndexState_Finished,
+		NodeID:       nodeID,
+		Version:      1,
+		Recycled:     true,
+		Req: &indexpb.BuildIndexRequest{
+			IndexBuildID: buildID,
+			IndexName:    "index",
+			IndexID:      10,
+			DataPaths:    []string{"insert_log/1/2/3/4/5"},
+		},
+		ArtifactVersion: version,
+	}
+}
+
+func waitRebuildFinished(t *testing.T, r *rebuilder) metricsinfo.IndexRebuildProgress {
+	for i := 0; i < 100; i++ {
+		progress := r.getProgress()
+		if !progress.Running {
+			return progress
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.FailNow(t, "rebuilding does not finish in time")
+	return metricsinfo.IndexRebuildProgress{}
+}
+
+func TestIsBelowMinimumVersion(t *testing.T) {
+	current := currentArtifactVersion()
+	assert.False(t, isBelowMinimumVersion(current, current))
+	assert.False(t, isBelowMinimumVersion(nil, current))
+	// the index built by a newer engine can not be fixed by rebuilding
+	assert.False(t, isBelowMinimumVersion(&indexpb.IndexArtifactVersion{EngineVersion: currentEngineVersion + 1}, current))
+
+	engineCompatibility[currentEngineVersion] = currentEngineVersion
+	defer func() {
+		engineCompatibility[currentEngineVersion] = 0
+	}()
+	assert.True(t, isBelowMinimumVersion(nil, current))
+	assert.False(t, isBelowMinimumVersion(current, current))
+}
+
+func TestRebuilder(t *testing.T) {
+	// versions older than the current one are no longer loadable
+	engineCompatibility[currentEngineVersion] = currentEngineVersion
+	defer func() {
+		engineCompatibility[currentEngineVersion] = 0
+	}()
+
+	const nodeID = 1
+	metaKV := newMockRebuildMetaKV()
+	metaKV.save(t, newRebuildIndexMeta(1, nodeID, nil))
+	metaKV.save(t, newRebuildIndexMeta(2, nodeID, currentArtifactVersion()))
+	metaKV.save(t, newRebuildIndexMeta(3, nodeID+1, nil))
+	metaKV.save(t, newRebuildIndexMeta(4, nodeID, &indexpb.IndexArtifactVersion{EngineVersion: currentEngineVersion - 1}))
+	deleted := newRebuildIndexMeta(5, nodeID, nil)
+	deleted.MarkDeleted = true
+	metaKV.save(t, deleted)
+	failed := newRebuildIndexMeta(6, nodeID, nil)
+	failed.State = commonpb.IndexState_Failed
+	metaKV.save(t, failed)
+	metaKV.save(t, newRebuildIndexMeta(7, nodeID, nil))
+
+	var idle atomic.Value
+	idle.Store(false)
+	var mu sync.Mutex
+	var running, maxRunning int
+	var requests []*indexpb.CreateIndexRequest
+	build := func(ctx context.Context, req *indexpb.CreateIndexRequest) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		requests = append(requests, req)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if req.IndexBuildID == 7 {
+			return errors.New("build failed")
+		}
+		return nil
+	}
+	r := newRebuilder(metaKV, nodeID, 2, func() bool { return idle.Load().(bool) }, build)
+
+	err := r.start(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, errRebuildRunning, r.start(context.Background()))
+
+	// nothing is rebuilt while the task queue is busy
+	time.Sleep(50 * time.Millisecond)
+	progress := r.getProgress()
+	assert.True(t, progress.Running)
+	assert.Equal(t, int64(7), progress.ScannedNum)
+	assert.Equal(t, int64(3), progress.CandidateNum)
+	assert.Equal(t, int64(3), progress.PendingNum)
+	mu.Lock()
+	assert.Equal(t, 0, len(requests))
+	mu.Unlock()
+
+	idle.Store(true)
+	progress = waitRebuildFinished(t, r)
+	assert.Equal(t, int64(0), progress.PendingNum)
+	assert.Equal(t, int64(0), progress.RebuildingNum)
+	sort.Slice(progress.RebuiltBuildIDs, func(i, j int) bool {
+		return progress.RebuiltBuildIDs[i] < progress.RebuiltBuildIDs[j]
+	})
+	assert.Equal(t, []int64{1, 4}, progress.RebuiltBuildIDs)
+	assert.Equal(t, map[int64]string{7: "build failed"}, progress.FailedBuilds)
+	assert.NotEqual(t, "", progress.FinishTime)
+	assert.LessOrEqual(t, maxRunning, 2)
+
+	for _, req := range requests {
+		assert.Equal(t, int64(2), req.Version)
+		assert.Equal(t, "/indexes/"+strconv.FormatInt(req.IndexBuildID, 10), req.MetaPath)
+		assert.Equal(t, []string{"insert_log/1/2/3/4/5"}, req.DataPaths)
+	}
+	indexMeta := metaKV.load(t, 1)
+	assert.Equal(t, int64(2), indexMeta.Version)
+	assert.Equal(t, commonpb.IndexState_InProgress, indexMeta.State)
+	assert.False(t, indexMeta.Recycled)
+	// the compatible builds are never touched
+	for _, buildID := range []UniqueID{2, 3, 5, 6} {
+		assert.Equal(t, int64(1), metaKV.load(t, buildID).Version)
+	}
+
+	t.Run("scan failed", func(t *testing.T) {
+		metaKV.loadErr = errors.New("etcd unavailable")
+		defer func() {
+			metaKV.loadErr = nil
+		}()
+		err := r.start(context.Background())
+		assert.Nil(t, err)
+		progress := waitRebuildFinished(t, r)
+		assert.Equal(t, "etcd unavailable", progress.Error)
+		assert.Equal(t, 0, len(progress.RebuiltBuildIDs))
+	})
+
+	t.Run("meta changed", func(t *testing.T) {
+		r := newRebuilder(metaKV, nodeID, 1, func() bool { return true }, build)
+		candidates, _, err := r.scan()
+		assert.Nil(t, err)
+		// the rebuilt builds are in progress, 7 is failed to build
+		assert.Equal(t, 0, len(candidates))
+
+		metaKV.save(t, newRebuildIndexMeta(8, nodeID, nil))
+		candidates, _, err = r.scan()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(candidates))
+		metaKV.save(t, newRebuildIndexMeta(8, nodeID, nil))
+		r.rebuild(context.Background(), candidates[0])
+		assert.Contains(t, r.getProgress().FailedBuilds, int64(8))
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		metaKV.save(t, newRebuildIndexMeta(9, nodeID, nil))
+		r := newRebuilder(metaKV, nodeID, 1, func() bool { return false }, build)
+		ctx, cancel := context.WithCancel(context.Background())
+		err := r.start(ctx)
+		assert.Nil(t, err)
+		cancel()
+		progress := waitRebuildFinished(t, r)
+		// 8 is left by the meta changed case
+		assert.Equal(t, int64(2), progress.PendingNum)
+		assert.Equal(t, 0, len(progress.RebuiltBuildIDs))
+	})
+}
+
+func TestGetIndexRebuildMetrics(t *testing.T) {
+	ctx := context.Background()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	defer in.Stop()
+
+	req, err := metricsinfo.ConstructRequestByMetricType(metricsinfo.IndexRebuildProgressMetrics)
+	assert.Nil(t, err)
+	resp, err := getIndexRebuildMetrics(ctx, req, in)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.Status.ErrorCode)
+	assert.Equal(t, errRebuildDisabled, in.startRebuild())
+
+	Params.AutoRebuildIncompatible = true
+	defer func() {
+		Params.AutoRebuildIncompatible = false
+	}()
+	in.rebuilder = newRebuilder(newMockRebuildMetaKV(), Params.NodeID, 1, func() bool { return true },
+		func(ctx context.Context, req *indexpb.CreateIndexRequest) error { return nil })
+	assert.Nil(t, in.startRebuild())
+	resp, err = getIndexRebuildMetrics(ctx, &milvuspb.GetMetricsRequest{Request: req.Request}, in)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+	progress := metricsinfo.IndexRebuildProgress{}
+	err = metricsinfo.UnmarshalComponentInfos(resp.Response, &progress)
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", progress.StartTime)
+
+	waitRebuildFinished(t, in.rebuilder)
+	resp, err = getIndexRebuildMetrics(ctx, req, in)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+}
+
+func TestIndexNode_rebuildIndexCanceled(t *testing.T) {
+	in, err := NewIndexNode(context.Background())
+	assert.Nil(t, err)
+	defer in.Stop()
+
+	// the scheduler is not started, the rebuild stays queued until it's canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	rebuilt := make(chan error, 1)
+	go func() {
+		rebuilt <- in.rebuildIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1})
+	}()
+	for in.sched.IndexBuildQueue.lpLen() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	assert.Equal(t, context.Canceled, <-rebuilt)
+	assert.Equal(t, 0, in.sched.IndexBuildQueue.lpLen())
+}
+
+func TestIndexNode_RebuildIncompatibleIndexes(t *testing.T) {
+	ctx := context.Background()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	status, err := in.RebuildIncompatibleIndexes(ctx, &indexpb.RebuildIncompatibleIndexesRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, status.ErrorCode)
+	in.Stop()
+
+	in, clean := newHealthyIndexNode(t)
+	defer clean()
+	status, err = in.RebuildIncompatibleIndexes(ctx, &indexpb.RebuildIncompatibleIndexesRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, status.ErrorCode)
+	assert.Contains(t, status.Reason, "disabled")
+
+	Params.AutoRebuildIncompatible = true
+	defer func() {
+		Params.AutoRebuildIncompatible = false
+	}()
+	in.rebuilder = newRebuilder(newMockRebuildMetaKV(), Params.NodeID, 1, func() bool { return true },
+		func(ctx context.Context, req *indexpb.CreateIndexRequest) error { return nil })
+	status, err = in.RebuildIncompatibleIndexes(ctx, &indexpb.RebuildIncompatibleIndexesRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, status.ErrorCode)
+	waitRebuildFinished(t, in.rebuilder)
+
+	// the progress is only queried by GetMetrics
+	req, err := metricsinfo.ConstructRequestByMetricType(metricsinfo.IndexRebuildProgressMetrics)
+	assert.Nil(t, err)
+	resp, err := in.GetMetrics(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, resp.Status.ErrorCode)
+	req, err = metricsinfo.ConstructRequestByMetricType("rebuild_incompatible_indexes")
+	assert.Nil(t, err)
+	resp, err = in.GetMetrics(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, resp.Status.ErrorCode)
+}
@@ -0,0 +1,199 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// the policies of scheduling the unissued tasks
+const (
+	// schedulePolicyFIFO schedules the tasks in the order of arrival
+	schedulePolicyFIFO = "fifo"
+	// schedulePolicyFair schedules the tasks of a higher priority first, the tasks of the same priority are of
+	// the collections in weighted round-robin, FIFO within a collection
+	schedulePolicyFair = "fair"
+	// schedulePolicyPriority schedules the tasks strictly by their priority, FIFO within a priority
+	schedulePolicyPriority = "priority"
+	// schedulePolicySmallestFirst schedules the tasks with the least estimated work first
+	schedulePolicySmallestFirst = "smallest-first"
+
+	defaultSchedulePolicy = schedulePolicyFair
+)
+
+// normalizeSchedulePolicy returns the schedule policy of the configured one, which is case-insensitive.
+func normalizeSchedulePolicy(policy string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(policy))
+	switch normalized {
+	case "":
+		return defaultSchedulePolicy, nil
+	case schedulePolicyFIFO, schedulePolicyFair, schedulePolicyPriority, schedulePolicySmallestFirst:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("invalid schedule policy %q, accepted values: %s, %s, %s, %s", policy,
+			schedulePolicyFIFO, schedulePolicyFair, schedulePolicyPriority, schedulePolicySmallestFirst)
+	}
+}
+
+// taskList keeps the unissued tasks, PopFront pops the task to schedule next according to the policy.
+type taskList interface {
+	Len() int
+	PushBack(t task)
+	PopFront() task
+}
+
+// newTaskList creates the task list of the policy, the collections are weighted by @weights for the fair
+// policy, and a task is deferred at most @maxDefer by the smallest-first policy.
+func newTaskList(policy string, weights map[UniqueID]int64, maxDefer time.Duration) taskList {
+	switch policy {
+	case schedulePolicyFIFO:
+		return newFIFOTaskList()
+	case schedulePolicySmallestFirst:
+		return newSmallestFirstTaskList(maxDefer)
+	case schedulePolicyPriority:
+		return newPriorityTaskList(func() taskList {
+			return newFIFOTaskList()
+		})
+	default:
+		// the priority of the tasks trumps the fairness across the collections
+		return newPriorityTaskList(func() taskList {
+			return newFairTaskList(weights)
+		})
+	}
+}
+
+// drainTaskList pops all the tasks of the list in the order of their arrival.
+func drainTaskList(l taskList) []task {
+	tasks := make([]task, 0, l.Len())
+	for l.Len() > 0 {
+		tasks = append(tasks, l.PopFront())
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return taskEnqueueTime(tasks[i]).Before(taskEnqueueTime(tasks[j]))
+	})
+	return tasks
+}
+
+// fifoTaskList pops the tasks in the order they are pushed.
+type fifoTaskList struct {
+	tasks *list.List
+}
+
+func newFIFOTaskList() *fifoTaskList {
+	return &fifoTaskList{tasks: list.New()}
+}
+
+func (l *fifoTaskList) Len() int {
+	return l.tasks.Len()
+}
+
+func (l *fifoTaskList) PushBack(t task) {
+	l.tasks.PushBack(t)
+}
+
+func (l *fifoTaskList) PopFront() task {
+	front := l.tasks.Front()
+	if front == nil {
+		return nil
+	}
+	return l.tasks.Remove(front).(task)
+}
+
+// queuedTask is an unissued task of smallestFirstTaskList.
+type queuedTask struct {
+	t           task
+	work        int64
+	enqueueTime time.Time
+}
+
+// smallestFirstTaskList pops the task with the least estimated work, so that many small builds complete
+// before a giant one. A task waiting longer than maxDefer is popped first to not starve the large tasks.
+type smallestFirstTaskList struct {
+	tasks    []*queuedTask
+	maxDefer time.Duration
+	// now is a variable so that tests can mock it
+	now func() time.Time
+}
+
+func newSmallestFirstTaskList(maxDefer time.Duration) *smallestFirstTaskList {
+	return &smallestFirstTaskList{
+		maxDefer: maxDefer,
+		now:      time.Now,
+	}
+}
+
+func (l *smallestFirstTaskList) Len() int {
+	return len(l.tasks)
+}
+
+func (l *smallestFirstTaskList) PushBack(t task) {
+	enqueueTime := taskEnqueueTime(t)
+	if enqueueTime.IsZero() {
+		enqueueTime = l.now()
+	}
+	l.tasks = append(l.tasks, &queuedTask{
+		t:           t,
+		work:        taskEstimatedWork(t),
+		enqueueTime: enqueueTime,
+	})
+}
+
+// PopFront pops the task waiting the longest among the ones waiting longer than maxDefer, or the one with
+// the least work if no task is overdue, the tasks with the same work are popped in the order they are pushed.
+func (l *smallestFirstTaskList) PopFront() task {
+	if len(l.tasks) == 0 {
+		return nil
+	}
+	selected := -1
+	if l.maxDefer > 0 {
+		now := l.now()
+		for idx, queued := range l.tasks {
+			if now.Sub(queued.enqueueTime) < l.maxDefer {
+				continue
+			}
+			if selected < 0 || queued.enqueueTime.Before(l.tasks[selected].enqueueTime) {
+				selected = idx
+			}
+		}
+	}
+	if selected < 0 {
+		selected = 0
+		for idx, queued := range l.tasks {
+			if queued.work < l.tasks[selected].work {
+				selected = idx
+			}
+		}
+	}
+	t := l.tasks[selected].t
+	l.tasks = append(l.tasks[:selected], l.tasks[selected+1:]...)
+	return t
+}
+
+// taskEnqueueTime returns when the task is enqueued, or the zero time if it is unknown.
+func taskEnqueueTime(t task) time.Time {
+	if it, ok := t.(*IndexBuildTask); ok {
+		return it.enqueueTime
+	}
+	return time.Time{}
+}
+
+// taskEstimatedWork returns the estimated work of the task, tasks of unknown work are estimated 0.
+func taskEstimatedWork(t task) int64 {
+	if it, ok := t.(*IndexBuildTask); ok {
+		return it.estimatedWork()
+	}
+	return 0
+}
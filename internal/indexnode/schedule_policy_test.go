@@ -0,0 +1,176 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+)
+
+// newSizedTask creates a task of @binlogs binlogs of @dim dimensions, enqueued at @enqueueTime.
+func newSizedTask(buildID UniqueID, binlogs int, dim int, enqueueTime time.Time) *IndexBuildTask {
+	it := newCollectionTask(context.Background(), buildID, 1)
+	for idx := 1; idx < binlogs; idx++ {
+		it.req.DataPaths = append(it.req.DataPaths, fmt.Sprintf("by-dev/insert_log/1/1/%d/100/%d", buildID, idx+1))
+	}
+	it.req.TypeParams = []*commonpb.KeyValuePair{{Key: dimKey, Value: strconv.Itoa(dim)}}
+	it.enqueueTime = enqueueTime
+	return it
+}
+
+func popBuildIDs(l taskList) []UniqueID {
+	popped := make([]UniqueID, 0)
+	for l.Len() > 0 {
+		popped = append(popped, l.PopFront().(*IndexBuildTask).req.IndexBuildID)
+	}
+	return popped
+}
+
+func TestIndexBuildTask_estimatedWork(t *testing.T) {
+	it := newSizedTask(1, 3, 128, time.Now())
+	assert.Equal(t, int64(3*128), it.estimatedWork())
+
+	// the dim in the index params
+	it.req.TypeParams = nil
+	it.req.IndexParams = []*commonpb.KeyValuePair{{Key: dimKey, Value: "16"}}
+	assert.Equal(t, int64(3*16), it.estimatedWork())
+
+	// the dim encoded in the params
+	it.req.IndexParams = []*commonpb.KeyValuePair{{Key: paramsKeyToParse, Value: `{"dim": "32"}`}}
+	assert.Equal(t, int64(3*32), it.estimatedWork())
+
+	// the dim is unknown
+	it.req.IndexParams = []*commonpb.KeyValuePair{{Key: dimKey, Value: "abc"}}
+	assert.Equal(t, int64(3), it.estimatedWork())
+
+	// the row count of the segment is preferred to the number of binlogs
+	it.req.NumRows = 10000
+	it.req.TypeParams = []*commonpb.KeyValuePair{{Key: dimKey, Value: "128"}}
+	assert.Equal(t, int64(10000*128), it.estimatedWork())
+}
+
+func TestSmallestFirstTaskList(t *testing.T) {
+	now := time.Now()
+	l := newSmallestFirstTaskList(time.Minute)
+	l.now = func() time.Time { return now }
+	assert.Nil(t, l.PopFront())
+
+	l.PushBack(newSizedTask(1, 10, 128, now))
+	l.PushBack(newSizedTask(2, 1, 128, now))
+	l.PushBack(newSizedTask(3, 2, 64, now))
+	l.PushBack(newSizedTask(4, 1, 128, now))
+	l.PushBack(newSizedTask(5, 1, 8, now))
+	// the tasks with the same work are popped in the order of arrival
+	assert.Equal(t, []UniqueID{5, 2, 3, 4, 1}, popBuildIDs(l))
+	assert.Nil(t, l.PopFront())
+}
+
+func TestSmallestFirstTaskList_maxDefer(t *testing.T) {
+	now := time.Now()
+	l := newSmallestFirstTaskList(time.Minute)
+	l.now = func() time.Time { return now }
+
+	l.PushBack(newSizedTask(1, 100, 128, now))
+	for buildID := UniqueID(2); buildID <= 4; buildID++ {
+		l.PushBack(newSizedTask(buildID, 1, 128, now))
+	}
+	// the large task is deferred by the small ones
+	assert.Equal(t, UniqueID(2), l.PopFront().(*IndexBuildTask).req.IndexBuildID)
+
+	// small tasks keep arriving, the large task is scheduled first once it waits longer than maxDefer
+	now = now.Add(time.Minute)
+	l.PushBack(newSizedTask(5, 1, 128, now))
+	assert.Equal(t, UniqueID(1), l.PopFront().(*IndexBuildTask).req.IndexBuildID)
+
+	// the overdue tasks are scheduled in the order of arrival
+	l.PushBack(newSizedTask(6, 50, 128, now.Add(-2*time.Minute)))
+	now = now.Add(time.Minute)
+	assert.Equal(t, []UniqueID{6, 3, 4, 5}, popBuildIDs(l))
+
+	t.Run("unlimited", func(t *testing.T) {
+		l := newSmallestFirstTaskList(0)
+		l.PushBack(newSizedTask(1, 100, 128, time.Now().Add(-time.Hour)))
+		l.PushBack(newSizedTask(2, 1, 128, time.Now()))
+		assert.Equal(t, []UniqueID{2, 1}, popBuildIDs(l))
+	})
+
+	t.Run("unknown enqueue time", func(t *testing.T) {
+		l := newSmallestFirstTaskList(time.Minute)
+		l.PushBack(newSizedTask(1, 100, 128, time.Time{}))
+		l.PushBack(newSizedTask(2, 1, 128, time.Time{}))
+		// the tasks are enqueued when pushed
+		assert.Equal(t, []UniqueID{2, 1}, popBuildIDs(l))
+	})
+}
+
+func TestFIFOTaskList(t *testing.T) {
+	l := newTaskList(schedulePolicyFIFO, nil, 0)
+	assert.Nil(t, l.PopFront())
+	now := time.Now()
+	for buildID := UniqueID(1); buildID <= 3; buildID++ {
+		l.PushBack(newSizedTask(buildID, int(4-buildID), 128, now))
+	}
+	assert.Equal(t, []UniqueID{1, 2, 3}, popBuildIDs(l))
+}
+
+func TestNormalizeSchedulePolicy(t *testing.T) {
+	for value, expected := range map[string]string{
+		"":                   schedulePolicyFair,
+		"FIFO":               schedulePolicyFIFO,
+		"Fair":               schedulePolicyFair,
+		" priority ":         schedulePolicyPriority,
+		"Smallest-First":     schedulePolicySmallestFirst,
+		"smallest_first":     "",
+		"shortest-job-first": "",
+	} {
+		policy, err := normalizeSchedulePolicy(value)
+		assert.Equal(t, expected, policy)
+		assert.Equal(t, expected == "", err != nil)
+	}
+}
+
+func TestBaseTaskQueue_setSchedulePolicy(t *testing.T) {
+	sched, err := NewTaskScheduler(context.Background(), nil)
+	assert.Nil(t, err)
+	queue := sched.IndexBuildQueue
+	policy, _ := queue.schedulePolicy()
+	assert.Equal(t, schedulePolicyFair, policy)
+
+	now := time.Now()
+	for buildID := UniqueID(1); buildID <= 4; buildID++ {
+		// the later tasks are the smaller ones
+		it := newSizedTask(buildID, int(5-buildID), 128, time.Time{})
+		assert.Nil(t, queue.Enqueue(it))
+		it.enqueueTime = now.Add(time.Duration(buildID) * time.Second)
+	}
+	assert.Equal(t, UniqueID(1), queue.PopUnissuedTask().ID())
+
+	// the queued tasks are kept after switched
+	queue.setSchedulePolicy(schedulePolicySmallestFirst, time.Hour)
+	policy, maxDefer := queue.schedulePolicy()
+	assert.Equal(t, schedulePolicySmallestFirst, policy)
+	assert.Equal(t, time.Hour, maxDefer)
+	assert.Equal(t, 3, queue.utLen())
+	assert.Equal(t, UniqueID(4), queue.PopUnissuedTask().ID())
+
+	queue.setSchedulePolicy(schedulePolicyFIFO, time.Hour)
+	assert.Equal(t, UniqueID(2), queue.PopUnissuedTask().ID())
+	assert.Equal(t, UniqueID(3), queue.PopUnissuedTask().ID())
+	assert.Nil(t, queue.PopUnissuedTask())
+}
@@ -0,0 +1,530 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/milvus-io/milvus/internal/common"
+	"github.com/milvus-io/milvus/internal/kv"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/storage/parquet"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+const (
+	segmentFormatBinlog  = "binlog"
+	segmentFormatParquet = "parquet"
+	// parquetFileExt is the extension of the parquet segment files, the other files are binlogs
+	parquetFileExt = ".parquet"
+)
+
+// segmentFormatOf returns the format of the segment files of @req, which is the data format of the request, or
+// detected by the extensions of the data paths if the request has none.
+func segmentFormatOf(req *indexpb.CreateIndexRequest) (string, error) {
+	if dataFormat := req.GetDataFormat(); dataFormat != "" {
+		format := strings.ToLower(dataFormat)
+		if format != segmentFormatBinlog && format != segmentFormatParquet {
+			return "", errorcode.Errorf(errorcode.InvalidParams, "unknown data format %s, we expect %s or %s",
+				dataFormat, segmentFormatBinlog, segmentFormatParquet)
+		}
+		return format, nil
+	}
+	format := ""
+	for _, dataPath := range req.GetDataPaths() {
+		pathFormat := segmentFormatBinlog
+		if strings.EqualFold(path.Ext(dataPath), parquetFileExt) {
+			pathFormat = segmentFormatParquet
+		}
+		if format != "" && pathFormat != format {
+			return "", errorcode.Errorf(errorcode.InvalidParams, "the data path %s is a %s file, but the previous paths are %s files",
+				dataPath, pathFormat, format)
+		}
+		format = pathFormat
+	}
+	if format == "" {
+		format = segmentFormatBinlog
+	}
+	return format, nil
+}
+
+// segmentFile is a segment file loaded by a segmentReader, which is decoded by the same reader.
+type segmentFile struct {
+	path string
+	// size is the bytes loaded from the object storage
+	size int64
+	data interface{}
+}
+
+// segmentReader reads the vector field to build the index on from the segment files of a format, the vectors of
+// all the formats are decoded into the same field data, which is fed to the build.
+type segmentReader interface {
+	// load downloads the parts of the segment file to decode
+	load(dataPath string) (*segmentFile, error)
+	// decode decodes the vector field of the file
+	decode(file *segmentFile) (*decodedBinlog, error)
+	// decodeAll decodes the vector field of all the files, whose vectors are concatenated in the order of sortPaths
+	decodeAll(files []*segmentFile) (*decodedBinlog, error)
+	// sortPaths returns the data paths in the order their vectors are concatenated by decodeAll
+	sortPaths(dataPaths []string) []string
+}
+
+// segmentReader returns the reader of the segment files of the task.
+func (it *IndexBuildTask) segmentReader() (segmentReader, error) {
+	format, err := segmentFormatOf(it.req)
+	if err != nil {
+		return nil, err
+	}
+	if format == segmentFormatParquet {
+		return newParquetSegmentReader(it.kv, it.req.GetFieldSchema())
+	}
+	return &binlogSegmentReader{objects: it.kv}, nil
+}
+
+// binlogSegmentReader reads the binlogs of the vector field.
+type binlogSegmentReader struct {
+	objects kv.BaseKV
+}
+
+func (r *binlogSegmentReader) load(dataPath string) (*segmentFile, error) {
+	value, err := r.objects.Load(dataPath)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	return &segmentFile{path: dataPath, size: int64(len(value)), data: []byte(value)}, nil
+}
+
+func (r *binlogSegmentReader) blob(file *segmentFile) *Blob {
+	return &Blob{Key: file.path, Value: file.data.([]byte)}
+}
+
+func (r *binlogSegmentReader) decode(file *segmentFile) (*decodedBinlog, error) {
+	return decodeInsertBinlog(r.blob(file))
+}
+
+func (r *binlogSegmentReader) decodeAll(files []*segmentFile) (*decodedBinlog, error) {
+	blobs := make([]*Blob, len(files))
+	for idx, file := range files {
+		blobs[idx] = r.blob(file)
+	}
+	var insertCodec storage.InsertCodec
+	defer insertCodec.Close()
+	collectionID, partitionID, segmentID, insertData, err := insertCodec.DeserializeAll(blobs)
+	if err != nil {
+		return nil, errorcode.Wrap(errorcode.InvalidParams, err)
+	}
+	if len(insertData.Data) != 1 {
+		return nil, errorcode.New(errorcode.InvalidParams, "we expect only one field in deserialized insert data")
+	}
+	decoded := &decodedBinlog{
+		collectionID: collectionID,
+		partitionID:  partitionID,
+		segmentID:    segmentID,
+	}
+	for fieldID, value := range insertData.Data {
+		decoded.fieldID = fieldID
+		decoded.data = value
+	}
+	return decoded, nil
+}
+
+func (r *binlogSegmentReader) sortPaths(dataPaths []string) []string {
+	return sortBinlogPaths(dataPaths)
+}
+
+// parquetSegmentReader reads the vector field from the parquet files of the segment, which hold all the fields. Only
+// the column chunks of the vector field and the row IDs are downloaded if the object storage serves the ranges of
+// the objects.
+type parquetSegmentReader struct {
+	objects kv.BaseKV
+	fieldID UniqueID
+	// columnNames are the names the column of the vector field is looked up by
+	columnNames []string
+	dataType    schemapb.DataType
+}
+
+// parquetColumns are the columns of the vector field and the row IDs read from a parquet file.
+type parquetColumns struct {
+	rows    int64
+	vector  *parquet.Column
+	vectors *parquet.ColumnData
+	// rowIDs is nil if the file has no column of the row IDs
+	rowIDs *parquet.ColumnData
+}
+
+func newParquetSegmentReader(objects kv.BaseKV, schema *schemapb.FieldSchema) (*parquetSegmentReader, error) {
+	if schema == nil {
+		// the fixed-length vectors of the parquet files tell nothing about their element type
+		return nil, errorcode.New(errorcode.InvalidParams, "the parquet segment files require the field schema of the request")
+	}
+	if !isVectorDataType(schema.GetDataType()) {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "the field %d is %s, expect a vector field",
+			schema.GetFieldID(), schema.GetDataType().String())
+	}
+	columnNames := []string{strconv.FormatInt(schema.GetFieldID(), 10)}
+	if schema.GetName() != "" {
+		columnNames = append(columnNames, schema.GetName())
+	}
+	return &parquetSegmentReader{
+		objects:     objects,
+		fieldID:     schema.GetFieldID(),
+		columnNames: columnNames,
+		dataType:    schema.GetDataType(),
+	}, nil
+}
+
+// objectReaderAt reads the ranges of an object, counting the bytes loaded.
+type objectReaderAt struct {
+	objects objectRangeKV
+	path    string
+	loaded  int64
+}
+
+func (r *objectReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	data, err := r.objects.LoadRange(r.path, off, int64(len(p)))
+	if err != nil {
+		return 0, storageError(err)
+	}
+	r.loaded += int64(len(data))
+	n := copy(p, data)
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// openParquetFile opens the parquet file of @dataPath and returns its size, the columns are read by the ranges of
+// the object if @objects serves them, the returned function returns the bytes loaded so far.
+func openParquetFile(objects kv.BaseKV, dataPath string) (*parquet.File, int64, func() int64, error) {
+	if rangeKV, ok := objects.(objectRangeKV); ok {
+		size, err := rangeKV.GetObjectSize(dataPath)
+		if err != nil {
+			return nil, 0, nil, storageError(err)
+		}
+		reader := &objectReaderAt{objects: rangeKV, path: dataPath}
+		file, err := parquet.Open(reader, size)
+		if err != nil {
+			return nil, 0, nil, errorcode.Wrap(errorcode.InvalidParams, err)
+		}
+		return file, size, func() int64 { return reader.loaded }, nil
+	}
+	value, err := objects.Load(dataPath)
+	if err != nil {
+		return nil, 0, nil, storageError(err)
+	}
+	file, err := parquet.Open(strings.NewReader(value), int64(len(value)))
+	if err != nil {
+		return nil, 0, nil, errorcode.Wrap(errorcode.InvalidParams, err)
+	}
+	return file, int64(len(value)), func() int64 { return int64(len(value)) }, nil
+}
+
+// lookupParquetColumn returns the column of the first of @names in @file, or nil if there is none.
+func lookupParquetColumn(file *parquet.File, names ...string) (*parquet.Column, error) {
+	for _, name := range names {
+		for _, column := range file.Columns() {
+			if column.Name() == name {
+				return file.LookupColumn(name)
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (r *parquetSegmentReader) load(dataPath string) (*segmentFile, error) {
+	file, _, loaded, err := openParquetFile(r.objects, dataPath)
+	if err != nil {
+		return nil, err
+	}
+	vector, err := lookupParquetColumn(file, r.columnNames...)
+	if err != nil {
+		return nil, errorcode.Wrap(errorcode.InvalidParams, err)
+	}
+	if vector == nil {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "the parquet file %s has no column of the field %d",
+			dataPath, r.fieldID)
+	}
+	columns := &parquetColumns{rows: file.NumRows(), vector: vector}
+	if columns.vectors, err = file.ReadColumn(vector); err != nil {
+		return nil, errorcode.Wrap(errorcode.InvalidParams, err)
+	}
+	rowID, err := lookupParquetColumn(file, strconv.Itoa(common.RowIDField), common.RowIDFieldName)
+	if err != nil {
+		return nil, errorcode.Wrap(errorcode.InvalidParams, err)
+	}
+	if rowID != nil {
+		if columns.rowIDs, err = file.ReadColumn(rowID); err != nil {
+			return nil, errorcode.Wrap(errorcode.InvalidParams, err)
+		}
+	}
+	return &segmentFile{path: dataPath, size: loaded(), data: columns}, nil
+}
+
+func (r *parquetSegmentReader) decode(file *segmentFile) (*decodedBinlog, error) {
+	columns := file.data.(*parquetColumns)
+	collectionID, partitionID, segmentID, err := segmentOfPath(file.path)
+	if err != nil {
+		return nil, err
+	}
+	if columns.rowIDs != nil {
+		rowIDs, ok := columns.rowIDs.Values.([]int64)
+		if !ok || int64(len(rowIDs)) != columns.rows {
+			return nil, errorcode.Errorf(errorcode.InvalidParams, "the row IDs of the parquet file %s are not %d int64 values",
+				file.path, columns.rows)
+		}
+	}
+	data, err := parquetVectors(columns.vector, columns.vectors, r.dataType, int(columns.rows))
+	if err != nil {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "failed to decode the vectors of the parquet file %s: %s",
+			file.path, err.Error())
+	}
+	return &decodedBinlog{
+		collectionID: collectionID,
+		partitionID:  partitionID,
+		segmentID:    segmentID,
+		fieldID:      r.fieldID,
+		data:         data,
+	}, nil
+}
+
+func (r *parquetSegmentReader) decodeAll(files []*segmentFile) (*decodedBinlog, error) {
+	if len(files) == 0 {
+		return nil, errorcode.New(errorcode.InvalidParams, "no parquet files to build the index")
+	}
+	var all *decodedBinlog
+	for _, file := range files {
+		decoded, err := r.decode(file)
+		if err != nil {
+			return nil, err
+		}
+		if all == nil {
+			all = decoded
+			continue
+		}
+		if decoded.segmentID != all.segmentID {
+			return nil, errorcode.Errorf(errorcode.InvalidParams, "the parquet file %s is of segment %d, which differs from segment %d of the previous files",
+				file.path, decoded.segmentID, all.segmentID)
+		}
+		if all.data, err = appendVectors(all.data, decoded.data); err != nil {
+			return nil, errorcode.Errorf(errorcode.InvalidParams, "the vectors of the parquet file %s: %s", file.path, err.Error())
+		}
+	}
+	return all, nil
+}
+
+// sortPaths sorts the parquet files by the numbers of their names, as the binlogs are sorted by their log IDs.
+func (r *parquetSegmentReader) sortPaths(dataPaths []string) []string {
+	logID := func(dataPath string) int64 {
+		name := path.Base(dataPath)
+		id, _ := strconv.ParseInt(strings.TrimSuffix(name, path.Ext(name)), 10, 64)
+		return id
+	}
+	sorted := append([]string(nil), dataPaths...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return logID(sorted[i]) < logID(sorted[j])
+	})
+	return sorted
+}
+
+// segmentOfPath parses the IDs of the collection, the partition and the segment from the path of a segment file,
+// which is .../insert_log/{collection}/{partition}/{segment}/...
+func segmentOfPath(dataPath string) (collectionID, partitionID, segmentID UniqueID, err error) {
+	elems := strings.Split(dataPath, "/")
+	for idx := 0; idx+3 < len(elems); idx++ {
+		if elems[idx] != insertLogPathSegment {
+			continue
+		}
+		var ids [3]UniqueID
+		for i := range ids {
+			if ids[i], err = strconv.ParseInt(elems[idx+1+i], 10, 64); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			return ids[0], ids[1], ids[2], nil
+		}
+	}
+	return 0, 0, 0, errorcode.Errorf(errorcode.InvalidParams, "the path %s is not of the form .../%s/{collection}/{partition}/{segment}/...",
+		dataPath, insertLogPathSegment)
+}
+
+// parquetVectors converts the values of the vector column into the field data of @dataType. The float vectors are
+// fixed-length byte arrays of the little-endian floats or lists of the floats, the binary, float16 and bfloat16
+// vectors are byte arrays of the same length, none of the vectors may be null.
+func parquetVectors(column *parquet.Column, data *parquet.ColumnData, dataType schemapb.DataType, rows int) (storage.FieldData, error) {
+	numRows := []int64{int64(rows)}
+	if column.MaxRepetitionLevel > 0 {
+		if dataType != schemapb.DataType_FloatVector || column.Type != parquet.Float || column.MaxRepetitionLevel > 1 {
+			return nil, errorcode.Errorf(errorcode.InvalidParams, "the list column %s of %s is not %s",
+				column.Name(), column.Type.String(), dataType.String())
+		}
+		values := data.Values.([]float32)
+		dim, err := parquetListDim(column, data, rows)
+		if err != nil {
+			return nil, err
+		}
+		return &storage.FloatVectorFieldData{NumRows: numRows, Data: values, Dim: dim}, nil
+	}
+	if column.Type != parquet.FixedLenByteArray && column.Type != parquet.ByteArray {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "the column %s of %s can not be %s",
+			column.Name(), column.Type.String(), dataType.String())
+	}
+	values := data.Values.([][]byte)
+	if len(values) != rows {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "the column %s has %d null vectors", column.Name(), rows-len(values))
+	}
+	rowSize := column.TypeLength
+	if column.Type == parquet.ByteArray && rows > 0 {
+		rowSize = len(values[0])
+	}
+	vectors := make([]byte, 0, rows*rowSize)
+	for idx, value := range values {
+		if len(value) != rowSize {
+			return nil, errorcode.Errorf(errorcode.InvalidParams, "the vector %d of the column %s is of %d bytes, expect %d",
+				idx, column.Name(), len(value), rowSize)
+		}
+		vectors = append(vectors, value...)
+	}
+	switch dataType {
+	case schemapb.DataType_FloatVector:
+		if rowSize%4 != 0 {
+			return nil, errorcode.Errorf(errorcode.InvalidParams, "the float vectors of %d bytes are not whole floats", rowSize)
+		}
+		floats := make([]float32, len(vectors)/4)
+		for idx := range floats {
+			floats[idx] = math.Float32frombits(binary.LittleEndian.Uint32(vectors[idx*4:]))
+		}
+		return &storage.FloatVectorFieldData{NumRows: numRows, Data: floats, Dim: rowSize / 4}, nil
+	case schemapb.DataType_BinaryVector:
+		return &storage.BinaryVectorFieldData{NumRows: numRows, Data: vectors, Dim: rowSize * 8}, nil
+	case schemapb.DataType_Float16Vector, schemapb.DataType_BFloat16Vector:
+		if rowSize%halfFloatBytes != 0 {
+			return nil, errorcode.Errorf(errorcode.InvalidParams, "the %s of %d bytes are not whole elements", dataType.String(), rowSize)
+		}
+		if dataType == schemapb.DataType_Float16Vector {
+			return &storage.Float16VectorFieldData{NumRows: numRows, Data: vectors, Dim: rowSize / halfFloatBytes}, nil
+		}
+		return &storage.BFloat16VectorFieldData{NumRows: numRows, Data: vectors, Dim: rowSize / halfFloatBytes}, nil
+	}
+	return nil, errorcode.Errorf(errorcode.InvalidParams, "unsupported vector type %s", dataType.String())
+}
+
+// parquetListDim returns the length of the lists of the floats, which are all of the same length and have no nulls.
+func parquetListDim(column *parquet.Column, data *parquet.ColumnData, rows int) (int, error) {
+	var lengths []int
+	for idx, level := range data.DefinitionLevels {
+		if int(level) != column.MaxDefinitionLevel {
+			return 0, errorcode.Errorf(errorcode.InvalidParams, "the column %s has null or empty vectors", column.Name())
+		}
+		if data.RepetitionLevels[idx] == 0 {
+			lengths = append(lengths, 0)
+		} else if len(lengths) == 0 {
+			return 0, errorcode.Errorf(errorcode.InvalidParams, "the column %s starts in the middle of a vector", column.Name())
+		}
+		lengths[len(lengths)-1]++
+	}
+	if len(lengths) != rows {
+		return 0, errorcode.Errorf(errorcode.InvalidParams, "the column %s has %d vectors of %d rows", column.Name(), len(lengths), rows)
+	}
+	dim := 0
+	for idx, length := range lengths {
+		if idx == 0 {
+			dim = length
+		} else if length != dim {
+			return 0, errorcode.Errorf(errorcode.InvalidParams, "the vector %d of the column %s is of dim %d, expect %d",
+				idx, column.Name(), length, dim)
+		}
+	}
+	return dim, nil
+}
+
+// appendVectors appends the vectors of @src to @dst, which are of the same type and the same dim.
+func appendVectors(dst, src storage.FieldData) (storage.FieldData, error) {
+	if elementTypeOf(dst) != elementTypeOf(src) {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "the vectors are %s, which differ from %s",
+			elementTypeOf(src).String(), elementTypeOf(dst).String())
+	}
+	srcDim, _, _, err := vectorLayout(src)
+	if err != nil {
+		return nil, err
+	}
+	dstDim, _, _, err := vectorLayout(dst)
+	if err != nil {
+		return nil, err
+	}
+	if srcDim != dstDim {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "the vectors are of dim %d, which differs from %d", srcDim, dstDim)
+	}
+	switch data := dst.(type) {
+	case *storage.FloatVectorFieldData:
+		data.NumRows = append(data.NumRows, src.(*storage.FloatVectorFieldData).NumRows...)
+		data.Data = append(data.Data, src.(*storage.FloatVectorFieldData).Data...)
+	case *storage.BinaryVectorFieldData:
+		data.NumRows = append(data.NumRows, src.(*storage.BinaryVectorFieldData).NumRows...)
+		data.Data = append(data.Data, src.(*storage.BinaryVectorFieldData).Data...)
+	case *storage.Float16VectorFieldData:
+		data.NumRows = append(data.NumRows, src.(*storage.Float16VectorFieldData).NumRows...)
+		data.Data = append(data.Data, src.(*storage.Float16VectorFieldData).Data...)
+	case *storage.BFloat16VectorFieldData:
+		data.NumRows = append(data.NumRows, src.(*storage.BFloat16VectorFieldData).NumRows...)
+		data.Data = append(data.Data, src.(*storage.BFloat16VectorFieldData).Data...)
+	}
+	return dst, nil
+}
+
+// readParquetSummary reads the footer of the parquet file for the dry run, whose rows are exact. The dim is 0 if
+// it is unknown before the vectors are read, such as the dim of the lists of the floats.
+func readParquetSummary(objects kv.BaseKV, dataPath string, schema *schemapb.FieldSchema) (*binlogSummary, error) {
+	reader, err := newParquetSegmentReader(objects, schema)
+	if err != nil {
+		return nil, err
+	}
+	collectionID, partitionID, segmentID, err := segmentOfPath(dataPath)
+	if err != nil {
+		return nil, err
+	}
+	file, size, _, err := openParquetFile(objects, dataPath)
+	if err != nil {
+		return nil, err
+	}
+	vector, err := lookupParquetColumn(file, reader.columnNames...)
+	if err != nil {
+		return nil, err
+	}
+	if vector == nil {
+		return nil, errorcode.Errorf(errorcode.InvalidParams, "the parquet file %s has no column of the field %d",
+			dataPath, reader.fieldID)
+	}
+	head := &storage.BinlogHead{FirstEventType: storage.InsertEventType}
+	head.CollectionID, head.PartitionID, head.SegmentID = collectionID, partitionID, segmentID
+	head.FieldID, head.PayloadDataType = reader.fieldID, reader.dataType
+	summary := &binlogSummary{path: dataPath, size: size, head: head, rows: int(file.NumRows())}
+	if vector.Type == parquet.FixedLenByteArray {
+		switch reader.dataType {
+		case schemapb.DataType_FloatVector:
+			summary.dim = vector.TypeLength / 4
+		case schemapb.DataType_BinaryVector:
+			summary.dim = vector.TypeLength * 8
+		default:
+			summary.dim = vector.TypeLength / halfFloatBytes
+		}
+	}
+	return summary, nil
+}
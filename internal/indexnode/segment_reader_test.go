@@ -0,0 +1,531 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/kv"
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/storage/parquet"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/timerecord"
+)
+
+const (
+	parquetTestDim     = 8
+	parquetTestFieldID = 101
+)
+
+// saveParquetSegment saves the parquet files of segment 3 of partition 2 of collection 1, whose rows are split into
+// the files of @rowsOfFiles, each file has two row groups. The vector field 101 is written by @vectors of the rows
+// between start and end, along with the row IDs, an int64 field and a nullable string field. The files are named
+// 5.parquet, 10.parquet, ..., whose paths are returned in the order of the rows.
+func saveParquetSegment(t *testing.T, objects kv.BaseKV, vector parquet.Field, vectors func(start, end int) parquet.ColumnValues,
+	rowsOfFiles ...int) []string {
+	fields := []parquet.Field{
+		{Name: "0", Type: parquet.Int64},
+		{Name: "100", Type: parquet.Int64},
+		{Name: "102", Type: parquet.ByteArray, Optional: true},
+		vector,
+	}
+	var paths []string
+	start := 0
+	for idx, rows := range rowsOfFiles {
+		w := parquet.NewWriter(fields...)
+		w.Codec, w.PageRows = parquet.Snappy, 4
+		for _, groupRows := range []int{rows / 2, rows - rows/2} {
+			end := start + groupRows
+			var rowIDs, ages []int64
+			names := [][]byte{}
+			var nulls []bool
+			for row := start; row < end; row++ {
+				rowIDs = append(rowIDs, int64(row))
+				ages = append(ages, int64(row%90))
+				nulls = append(nulls, row%3 == 0)
+				if row%3 != 0 {
+					names = append(names, []byte(fmt.Sprintf("name-%d", row)))
+				}
+			}
+			err := w.WriteRowGroup(groupRows, parquet.ColumnValues{Values: rowIDs}, parquet.ColumnValues{Values: ages},
+				parquet.ColumnValues{Values: names, Nulls: nulls}, vectors(start, end))
+			assert.Nil(t, err)
+			start = end
+		}
+		path := fmt.Sprintf("insert_log/1/2/3/%d.parquet", (idx+1)*5)
+		assert.Nil(t, objects.Save(path, string(w.Close())))
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func parquetTestFloats(rows int) []float32 {
+	data := make([]float32, rows*parquetTestDim)
+	for idx := range data {
+		data[idx] = float32(idx) * 0.5
+	}
+	return data
+}
+
+func parquetTestBytes(rows int, rowSize int) []byte {
+	data := make([]byte, rows*rowSize)
+	for idx := range data {
+		data[idx] = byte(idx * 7)
+	}
+	return data
+}
+
+// fixedVectors returns the vectors of the rows of @rowSize bytes, as the fixed-length byte arrays.
+func fixedVectors(data []byte, rowSize int) func(start, end int) parquet.ColumnValues {
+	return func(start, end int) parquet.ColumnValues {
+		values := [][]byte{}
+		for row := start; row < end; row++ {
+			values = append(values, data[row*rowSize:(row+1)*rowSize])
+		}
+		return parquet.ColumnValues{Values: values}
+	}
+}
+
+func floatBytes(data []float32) []byte {
+	value := make([]byte, len(data)*4)
+	for idx, f := range data {
+		binary.LittleEndian.PutUint32(value[idx*4:], math.Float32bits(f))
+	}
+	return value
+}
+
+func newParquetTestTask(objects kv.BaseKV, paths []string, dataType schemapb.DataType, indexType, metricType string) *IndexBuildTask {
+	return &IndexBuildTask{
+		index: &mockStatisticsIndex{},
+		kv:    objects,
+		req: &indexpb.CreateIndexRequest{
+			IndexBuildID: 1,
+			Version:      1,
+			DataPaths:    paths,
+			TypeParams:   []*commonpb.KeyValuePair{{Key: dimKey, Value: fmt.Sprint(parquetTestDim)}},
+			IndexParams: []*commonpb.KeyValuePair{
+				{Key: indexTypeKey, Value: indexType}, {Key: metricTypeKey, Value: metricType}},
+			FieldSchema: &schemapb.FieldSchema{FieldID: parquetTestFieldID, Name: "vector", DataType: dataType},
+		},
+		stats:    newTaskStatistics(),
+		progress: newTaskProgress(1, 1),
+	}
+}
+
+func reversed(paths []string) []string {
+	result := make([]string, len(paths))
+	for idx, path := range paths {
+		result[len(paths)-1-idx] = path
+	}
+	return result
+}
+
+func TestSegmentFormatOf(t *testing.T) {
+	formatOf := func(dataFormat string, paths ...string) (string, error) {
+		return segmentFormatOf(&indexpb.CreateIndexRequest{DataFormat: dataFormat, DataPaths: paths})
+	}
+	format, err := formatOf("", "insert_log/1/2/3/100/1", "insert_log/1/2/3/100/2")
+	assert.Nil(t, err)
+	assert.Equal(t, segmentFormatBinlog, format)
+	format, err = formatOf("", "insert_log/1/2/3/1.parquet", "insert_log/1/2/3/2.PARQUET")
+	assert.Nil(t, err)
+	assert.Equal(t, segmentFormatParquet, format)
+	format, err = formatOf("")
+	assert.Nil(t, err)
+	assert.Equal(t, segmentFormatBinlog, format)
+
+	// the data format overrides the extensions
+	format, err = formatOf("Parquet", "insert_log/1/2/3/1")
+	assert.Nil(t, err)
+	assert.Equal(t, segmentFormatParquet, format)
+	format, err = formatOf("binlog", "insert_log/1/2/3/1.parquet")
+	assert.Nil(t, err)
+	assert.Equal(t, segmentFormatBinlog, format)
+
+	_, err = formatOf("orc", "insert_log/1/2/3/1")
+	assert.Equal(t, errorcode.InvalidParams, errorcode.CodeOf(err))
+	_, err = formatOf("", "insert_log/1/2/3/100/1", "insert_log/1/2/3/2.parquet")
+	assert.Equal(t, errorcode.InvalidParams, errorcode.CodeOf(err))
+	assert.True(t, strings.Contains(err.Error(), "insert_log/1/2/3/2.parquet"))
+}
+
+func TestSegmentOfPath(t *testing.T) {
+	collectionID, partitionID, segmentID, err := segmentOfPath("files/insert_log/1/2/3/5.parquet")
+	assert.Nil(t, err)
+	assert.Equal(t, []UniqueID{1, 2, 3}, []UniqueID{collectionID, partitionID, segmentID})
+	_, _, segmentID, err = segmentOfPath("insert_log/1/2/30/101/5.parquet")
+	assert.Nil(t, err)
+	assert.Equal(t, UniqueID(30), segmentID)
+
+	for _, path := range []string{"files/5.parquet", "insert_log/1/2/5.parquet", "insert_log/1/x/3/5.parquet"} {
+		_, _, _, err = segmentOfPath(path)
+		assert.Equal(t, errorcode.InvalidParams, errorcode.CodeOf(err), path)
+	}
+}
+
+func TestParquetSegmentReader(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("float vectors", func(t *testing.T) {
+		objects := &mockRangeKV{MemoryKV: memkv.NewMemoryKV()}
+		vectors := parquetTestFloats(30)
+		paths := saveParquetSegment(t, objects, parquet.Field{Name: "101", Type: parquet.FixedLenByteArray, TypeLength: parquetTestDim * 4},
+			fixedVectors(floatBytes(vectors), parquetTestDim*4), 13, 0, 17)
+		// the files are built in the order of their names
+		task := newParquetTestTask(objects, reversed(paths), schemapb.DataType_FloatVector, "FLAT", "L2")
+		collectionID, partitionID, segmentID, fieldID, err := task.loadAndBuild(ctx, nil, timerecord.NewTimeRecorder("parquet"))
+		assert.Nil(t, err)
+		assert.Equal(t, []UniqueID{1, 2, 3, parquetTestFieldID}, []UniqueID{collectionID, partitionID, segmentID, fieldID})
+		assert.Equal(t, vectors, task.index.(*mockStatisticsIndex).floatData)
+		assert.Equal(t, int64(parquetTestDim), task.vectorDim)
+
+		// only the footers, the row IDs and the vectors are loaded
+		assert.Equal(t, objects.loadedBytes, task.loadedBytes)
+		var unprojected, total int64
+		for _, path := range paths {
+			value, err := objects.Load(path)
+			assert.Nil(t, err)
+			file, err := parquet.Open(strings.NewReader(value), int64(len(value)))
+			assert.Nil(t, err)
+			for _, name := range []string{"100", "102"} {
+				column, err := file.LookupColumn(name)
+				assert.Nil(t, err)
+				unprojected += file.ColumnSize(column)
+			}
+			total += int64(len(value))
+		}
+		assert.True(t, unprojected > 0)
+		assert.True(t, task.loadedBytes <= total-unprojected, "loaded %d of %d bytes", task.loadedBytes, total)
+
+		// the pipelined build feeds the same vectors without the range reads
+		task = newParquetTestTask(memkv.NewMemoryKV(), nil, schemapb.DataType_FloatVector, "FLAT", "L2")
+		for _, path := range paths {
+			value, err := objects.Load(path)
+			assert.Nil(t, err)
+			assert.Nil(t, task.kv.Save(path, value))
+		}
+		task.req.DataPaths = reversed(paths)
+		oldBufferSize, oldChunkRows := Params.BuildPipelineBufferSize, Params.BuildChunkRows
+		defer func() { Params.BuildPipelineBufferSize, Params.BuildChunkRows = oldBufferSize, oldChunkRows }()
+		Params.BuildPipelineBufferSize, Params.BuildChunkRows = 2, 7
+		index := &mockIncrementalIndex{}
+		pipeline, err := task.buildPipelined(ctx, index)
+		assert.Nil(t, err)
+		assert.Equal(t, 30, pipeline.feeder.addedRows)
+		assert.Equal(t, vectors, index.floatData)
+		assert.Equal(t, UniqueID(3), pipeline.segmentID)
+		assert.Equal(t, total, task.loadedBytes)
+	})
+
+	t.Run("list float vectors", func(t *testing.T) {
+		objects := memkv.NewMemoryKV()
+		vectors := parquetTestFloats(20)
+		paths := saveParquetSegment(t, objects, parquet.Field{Name: "vector", Type: parquet.Float, List: true},
+			func(start, end int) parquet.ColumnValues {
+				var lengths []int
+				for row := start; row < end; row++ {
+					lengths = append(lengths, parquetTestDim)
+				}
+				return parquet.ColumnValues{Values: vectors[start*parquetTestDim : end*parquetTestDim], ListLengths: lengths}
+			}, 9, 11)
+		// the column is looked up by the name of the field if there is no column of the field ID
+		task := newParquetTestTask(objects, paths, schemapb.DataType_FloatVector, "FLAT", "IP")
+		_, _, _, _, err := task.loadAndBuild(ctx, nil, timerecord.NewTimeRecorder("parquet"))
+		assert.Nil(t, err)
+		assert.Equal(t, vectors, task.index.(*mockStatisticsIndex).floatData)
+	})
+
+	t.Run("binary vectors", func(t *testing.T) {
+		objects := memkv.NewMemoryKV()
+		const rowSize = parquetTestDim / 8 * 4
+		vectors := parquetTestBytes(25, rowSize)
+		paths := saveParquetSegment(t, objects, parquet.Field{Name: "101", Type: parquet.FixedLenByteArray, TypeLength: rowSize},
+			fixedVectors(vectors, rowSize), 10, 15)
+		task := newParquetTestTask(objects, paths, schemapb.DataType_BinaryVector, "BIN_FLAT", "JACCARD")
+		_, _, _, _, err := task.loadAndBuild(ctx, nil, timerecord.NewTimeRecorder("parquet"))
+		assert.Nil(t, err)
+		assert.Equal(t, vectors, task.index.(*mockStatisticsIndex).binaryData)
+		assert.Equal(t, int64(rowSize*8), task.vectorDim)
+
+		// the binary vectors can be the byte arrays of the same length
+		objects = memkv.NewMemoryKV()
+		paths = saveParquetSegment(t, objects, parquet.Field{Name: "101", Type: parquet.ByteArray}, fixedVectors(vectors, rowSize), 25)
+		reader, err := newParquetSegmentReader(objects, task.req.FieldSchema)
+		assert.Nil(t, err)
+		file, err := reader.load(paths[0])
+		assert.Nil(t, err)
+		decoded, err := reader.decode(file)
+		assert.Nil(t, err)
+		assert.Equal(t, &storage.BinaryVectorFieldData{NumRows: []int64{25}, Data: vectors, Dim: rowSize * 8}, decoded.data)
+	})
+
+	t.Run("half float vectors", func(t *testing.T) {
+		const rowSize = parquetTestDim * halfFloatBytes
+		vectors := parquetTestBytes(12, rowSize)
+		for _, dataType := range []schemapb.DataType{schemapb.DataType_Float16Vector, schemapb.DataType_BFloat16Vector} {
+			objects := memkv.NewMemoryKV()
+			paths := saveParquetSegment(t, objects, parquet.Field{Name: "101", Type: parquet.FixedLenByteArray, TypeLength: rowSize},
+				fixedVectors(vectors, rowSize), 5, 7)
+			reader, err := newParquetSegmentReader(objects, &schemapb.FieldSchema{FieldID: parquetTestFieldID, DataType: dataType})
+			assert.Nil(t, err)
+			var files []*segmentFile
+			for _, path := range reader.sortPaths(paths) {
+				file, err := reader.load(path)
+				assert.Nil(t, err)
+				files = append(files, file)
+			}
+			decoded, err := reader.decodeAll(files)
+			assert.Nil(t, err)
+			assert.Equal(t, dataType, elementTypeOf(decoded.data))
+			dim, _, _, err := vectorLayout(decoded.data)
+			assert.Nil(t, err)
+			assert.Equal(t, parquetTestDim, dim)
+			assert.Equal(t, int64(12), vectorRows(decoded.data))
+		}
+	})
+
+	t.Run("sort paths", func(t *testing.T) {
+		reader := &parquetSegmentReader{}
+		assert.Equal(t, []string{"a/2.parquet", "a/5.parquet", "a/10.parquet"},
+			reader.sortPaths([]string{"a/10.parquet", "a/2.parquet", "a/5.parquet"}))
+	})
+}
+
+func TestParquetSegmentReader_malformed(t *testing.T) {
+	schema := &schemapb.FieldSchema{FieldID: parquetTestFieldID, DataType: schemapb.DataType_FloatVector}
+	floatVector := parquet.Field{Name: "101", Type: parquet.FixedLenByteArray, TypeLength: parquetTestDim * 4}
+	vectors := fixedVectors(floatBytes(parquetTestFloats(10)), parquetTestDim*4)
+	// readFile loads and decodes the only file of the segment
+	readFile := func(objects kv.BaseKV, path string, schema *schemapb.FieldSchema) error {
+		reader, err := newParquetSegmentReader(objects, schema)
+		if err != nil {
+			return err
+		}
+		file, err := reader.load(path)
+		if err != nil {
+			return err
+		}
+		_, err = reader.decode(file)
+		return err
+	}
+	saveFile := func(objects kv.BaseKV, field parquet.Field, column parquet.ColumnValues, rows int) string {
+		w := parquet.NewWriter(field)
+		assert.Nil(t, w.WriteRowGroup(rows, column))
+		path := "insert_log/1/2/3/1.parquet"
+		assert.Nil(t, objects.Save(path, string(w.Close())))
+		return path
+	}
+	assertInvalid := func(err error, contains string) {
+		assert.Equal(t, errorcode.InvalidParams, classifyError(err))
+		if err != nil {
+			assert.True(t, strings.Contains(err.Error(), contains), err.Error())
+		}
+	}
+
+	t.Run("not parquet", func(t *testing.T) {
+		objects := memkv.NewMemoryKV()
+		assert.Nil(t, objects.Save("insert_log/1/2/3/1.parquet", "PAR1 not a parquet file PAR1"))
+		err := readFile(objects, "insert_log/1/2/3/1.parquet", schema)
+		assertInvalid(err, "malformed parquet file")
+
+		paths := saveParquetSegment(t, objects, floatVector, vectors, 10)
+		value, err := objects.Load(paths[0])
+		assert.Nil(t, err)
+		for _, size := range []int{0, 4, len(value) / 2, len(value) - 1} {
+			assert.Nil(t, objects.Save(paths[0], value[:size]))
+			assertInvalid(readFile(objects, paths[0], schema), "malformed parquet file")
+		}
+		// a column chunk beyond the end of the ranges read
+		rangeKV := &mockRangeKV{MemoryKV: memkv.NewMemoryKV()}
+		paths = saveParquetSegment(t, rangeKV, floatVector, vectors, 10)
+		value, err = rangeKV.Load(paths[0])
+		assert.Nil(t, err)
+		corrupted := []byte(value)
+		copy(corrupted[4:], make([]byte, 64))
+		assert.Nil(t, rangeKV.Save(paths[0], string(corrupted)))
+		assertInvalid(readFile(rangeKV, paths[0], schema), "")
+	})
+
+	t.Run("storage", func(t *testing.T) {
+		objects := &faultyLoadKV{MemoryKV: memkv.NewMemoryKV(), loadErr: errors.New("connection reset by peer")}
+		err := readFile(objects, "insert_log/1/2/3/1.parquet", schema)
+		assert.Equal(t, errorcode.StorageTransient, classifyError(err))
+	})
+
+	t.Run("request", func(t *testing.T) {
+		objects := memkv.NewMemoryKV()
+		paths := saveParquetSegment(t, objects, floatVector, vectors, 10)
+		assertInvalid(readFile(objects, paths[0], nil), "field schema")
+		assertInvalid(readFile(objects, paths[0], &schemapb.FieldSchema{FieldID: parquetTestFieldID, DataType: schemapb.DataType_Int64}),
+			"expect a vector field")
+		assertInvalid(readFile(objects, paths[0], &schemapb.FieldSchema{FieldID: 200, DataType: schemapb.DataType_FloatVector}),
+			"no column of the field 200")
+		// the float16 vectors of the float vectors are of the double dim
+		assert.Nil(t, readFile(objects, paths[0], &schemapb.FieldSchema{FieldID: parquetTestFieldID, DataType: schemapb.DataType_Float16Vector}))
+
+		value, err := objects.Load(paths[0])
+		assert.Nil(t, err)
+		assert.Nil(t, objects.Save("files/1.parquet", value))
+		assertInvalid(readFile(objects, "files/1.parquet", schema), "is not of the form")
+	})
+
+	t.Run("vectors", func(t *testing.T) {
+		objects := memkv.NewMemoryKV()
+		nullVector := floatVector
+		nullVector.Optional = true
+		column := vectors(0, 9)
+		column.Nulls = []bool{false, false, false, false, true, false, false, false, false, false}
+		path := saveFile(objects, nullVector, column, 10)
+		assertInvalid(readFile(objects, path, schema), "1 null vectors")
+
+		path = saveFile(objects, parquet.Field{Name: "101", Type: parquet.Int64}, parquet.ColumnValues{Values: []int64{1, 2}}, 2)
+		assertInvalid(readFile(objects, path, schema), "INT64")
+
+		path = saveFile(objects, parquet.Field{Name: "101", Type: parquet.FixedLenByteArray, TypeLength: 6},
+			fixedVectors(make([]byte, 12), 6)(0, 2), 2)
+		assertInvalid(readFile(objects, path, schema), "not whole floats")
+
+		path = saveFile(objects, parquet.Field{Name: "101", Type: parquet.ByteArray},
+			parquet.ColumnValues{Values: [][]byte{make([]byte, 32), make([]byte, 28)}}, 2)
+		assertInvalid(readFile(objects, path, schema), "the vector 1 of the column 101 is of 28 bytes, expect 32")
+
+		listVector := parquet.Field{Name: "101", Type: parquet.Float, List: true}
+		path = saveFile(objects, listVector, parquet.ColumnValues{Values: make([]float32, 15), ListLengths: []int{8, 7}}, 2)
+		assertInvalid(readFile(objects, path, schema), "the vector 1 of the column 101 is of dim 7, expect 8")
+		path = saveFile(objects, listVector, parquet.ColumnValues{Values: make([]float32, 8), ListLengths: []int{8, 0}}, 2)
+		assertInvalid(readFile(objects, path, schema), "null or empty")
+		path = saveFile(objects, parquet.Field{Name: "101", Type: parquet.Int32, List: true},
+			parquet.ColumnValues{Values: make([]int32, 8), ListLengths: []int{8}}, 1)
+		assertInvalid(readFile(objects, path, schema), "list column")
+		path = saveFile(objects, listVector, parquet.ColumnValues{Values: make([]float32, 8), ListLengths: []int{8}}, 1)
+		assertInvalid(readFile(objects, path, &schemapb.FieldSchema{FieldID: parquetTestFieldID, DataType: schemapb.DataType_BinaryVector}),
+			"list column")
+	})
+
+	t.Run("row IDs", func(t *testing.T) {
+		objects := memkv.NewMemoryKV()
+		w := parquet.NewWriter(parquet.Field{Name: "0", Type: parquet.Int64, Optional: true}, floatVector)
+		assert.Nil(t, w.WriteRowGroup(2, parquet.ColumnValues{Values: []int64{1}, Nulls: []bool{false, true}}, vectors(0, 2)))
+		assert.Nil(t, objects.Save("insert_log/1/2/3/1.parquet", string(w.Close())))
+		assertInvalid(readFile(objects, "insert_log/1/2/3/1.parquet", schema), "row IDs")
+	})
+
+	t.Run("segments", func(t *testing.T) {
+		objects := memkv.NewMemoryKV()
+		paths := saveParquetSegment(t, objects, floatVector, vectors, 5, 5)
+		value, err := objects.Load(paths[1])
+		assert.Nil(t, err)
+		assert.Nil(t, objects.Save("insert_log/1/2/4/10.parquet", value))
+		task := newParquetTestTask(objects, []string{paths[0], "insert_log/1/2/4/10.parquet"}, schemapb.DataType_FloatVector, "FLAT", "L2")
+		_, _, _, _, err = task.loadAndBuild(context.Background(), nil, timerecord.NewTimeRecorder("parquet"))
+		assertInvalid(err, "segment 4")
+		assert.Nil(t, task.index.(*mockStatisticsIndex).floatData)
+
+		// the vectors of the files of different dims
+		path := saveFile(objects, parquet.Field{Name: "101", Type: parquet.FixedLenByteArray, TypeLength: 16},
+			fixedVectors(make([]byte, 32), 16)(0, 2), 2)
+		task = newParquetTestTask(objects, []string{path, paths[1]}, schemapb.DataType_FloatVector, "FLAT", "L2")
+		_, _, _, _, err = task.loadAndBuild(context.Background(), nil, timerecord.NewTimeRecorder("parquet"))
+		assertInvalid(err, "dim")
+	})
+}
+
+func TestDryRun_parquet(t *testing.T) {
+	indexMeta := &indexpb.IndexMeta{IndexBuildID: 10, Version: 2, State: commonpb.IndexState_Unissued}
+	newRequest := func(paths []string) *indexpb.CreateIndexRequest {
+		return &indexpb.CreateIndexRequest{
+			IndexBuildID: 10,
+			Version:      2,
+			MetaPath:     "indexes/10",
+			DataPaths:    paths,
+			TypeParams:   []*commonpb.KeyValuePair{{Key: dimKey, Value: fmt.Sprint(parquetTestDim)}},
+			IndexParams: []*commonpb.KeyValuePair{
+				{Key: indexTypeKey, Value: "IVF_SQ8"},
+				{Key: "metric_type", Value: "L2"},
+				{Key: "nlist", Value: "100"},
+			},
+			FieldSchema: &schemapb.FieldSchema{FieldID: parquetTestFieldID, DataType: schemapb.DataType_FloatVector},
+		}
+	}
+	objects := &mockRangeKV{MemoryKV: memkv.NewMemoryKV()}
+	paths := saveParquetSegment(t, objects, parquet.Field{Name: "101", Type: parquet.FixedLenByteArray, TypeLength: parquetTestDim * 4},
+		fixedVectors(floatBytes(parquetTestFloats(30)), parquetTestDim*4), 13, 17)
+	var size int64
+	for _, path := range paths {
+		value, err := objects.Load(path)
+		assert.Nil(t, err)
+		size += int64(len(value))
+	}
+
+	d := newDryRunForTest(t, newRequest(paths), indexMeta)
+	d.objects = objects
+	resp := d.run()
+	assert.True(t, resp.Passed, failedChecks(resp))
+	assert.Equal(t, int64(2), resp.BinlogNum)
+	assert.Equal(t, size, resp.BinlogSize)
+	// the rows are read from the footers
+	assert.Equal(t, int64(30), resp.EstimatedRows)
+	assert.True(t, strings.HasSuffix(resp.IndexFilePrefix, "10/2/2/3"))
+	assert.True(t, objects.loadedBytes < size/2)
+
+	// the dim is checked against the params
+	req := newRequest(paths)
+	req.TypeParams = []*commonpb.KeyValuePair{{Key: dimKey, Value: "16"}}
+	d = newDryRunForTest(t, req, indexMeta)
+	d.objects = objects
+	resp = d.run()
+	assert.False(t, dryRunCheckOf(resp, dryRunCheckBinlogs).Passed)
+	assert.True(t, strings.Contains(failedChecks(resp), "mismatches the dim 16"))
+
+	// the lists of the floats are of the unknown dim
+	listPaths := saveParquetSegment(t, objects, parquet.Field{Name: "101", Type: parquet.Float, List: true},
+		func(start, end int) parquet.ColumnValues {
+			lengths := make([]int, end-start)
+			for idx := range lengths {
+				lengths[idx] = parquetTestDim
+			}
+			return parquet.ColumnValues{Values: make([]float32, (end-start)*parquetTestDim), ListLengths: lengths}
+		}, 4)
+	d = newDryRunForTest(t, newRequest(listPaths), indexMeta)
+	d.objects = objects
+	resp = d.run()
+	assert.True(t, resp.Passed, failedChecks(resp))
+	assert.Equal(t, int64(4), resp.EstimatedRows)
+
+	for _, req := range []*indexpb.CreateIndexRequest{
+		newRequest([]string{paths[0], "insert_log/1/2/3/100/1"}),
+		newRequest([]string{"insert_log/1/2/3/1.parquet"}),
+	} {
+		d = newDryRunForTest(t, req, indexMeta)
+		d.objects = objects
+		resp = d.run()
+		assert.False(t, dryRunCheckOf(resp, dryRunCheckBinlogs).Passed)
+	}
+	req = newRequest(paths)
+	req.FieldSchema = nil
+	d = newDryRunForTest(t, req, indexMeta)
+	d.objects = objects
+	resp = d.run()
+	assert.True(t, strings.Contains(failedChecks(resp), "field schema"))
+}
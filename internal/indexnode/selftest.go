@@ -0,0 +1,328 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/common"
+	"github.com/milvus-io/milvus/internal/kv"
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/etcdpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/indexparamcheck"
+	"github.com/milvus-io/milvus/internal/util/metricsinfo"
+	"github.com/milvus-io/milvus/internal/util/timerecord"
+)
+
+// the stages of the self-test, in the order they run
+const (
+	selfTestStageGenerate  = "generate"
+	selfTestStageBuild     = "build"
+	selfTestStageSerialize = "serialize"
+	selfTestStageUpload    = "upload"
+	selfTestStageCleanup   = "cleanup"
+)
+
+const (
+	// selfTestObjectPrefix is the prefix of the objects saved by the self-test under the index root path
+	selfTestObjectPrefix = ".selftest"
+
+	// the synthetic dataset is tiny so that the self-test finishes within seconds
+	selfTestDim     = 16
+	selfTestRows    = 256
+	selfTestNlist   = 4
+	selfTestFieldID = 100
+)
+
+// selfTestIndexTypes are the index types the self-test can build
+var selfTestIndexTypes = []string{indexparamcheck.IndexFaissIDMap, indexparamcheck.IndexFaissIvfFlat}
+
+func isSelfTestIndexType(indexType string) bool {
+	for _, t := range selfTestIndexTypes {
+		if t == indexType {
+			return true
+		}
+	}
+	return false
+}
+
+// selfTest builds a tiny synthetic index through the same pipeline as the builds, from saving and decoding the
+// binlogs to uploading the index files and verifying them against their manifest. All the objects it saves are
+// under prefix, which are removed once it finishes, and it never reads or writes the index meta.
+type selfTest struct {
+	storage   kv.BaseKV
+	prefix    string
+	indexType string
+	simd      *simdSwitcher
+	newIndex  func(typeParams, indexParams map[string]string) (Index, error)
+}
+
+func newSelfTest(storage kv.BaseKV, indexType string, simd *simdSwitcher) *selfTest {
+	return &selfTest{
+		storage:   storage,
+		prefix:    path.Join(Params.IndexRootPath, selfTestObjectPrefix, strconv.FormatInt(time.Now().UnixNano(), 10)),
+		indexType: indexType,
+		simd:      simd,
+		newIndex:  NewCIndex,
+	}
+}
+
+// run runs all the stages of the self-test until one of them fails, the objects saved are always cleaned up.
+func (st *selfTest) run(ctx context.Context) *metricsinfo.IndexNodeSelfTest {
+	start := time.Now()
+	result := &metricsinfo.IndexNodeSelfTest{Passed: true, IndexType: st.indexType, SimdType: Params.EffectiveSimdType}
+	fail := func(stage string, err error) {
+		if !result.Passed {
+			return
+		}
+		result.Passed = false
+		result.FailedStage = stage
+		result.Error = err.Error()
+	}
+	if st.simd != nil {
+		result.SimdType = st.simd.acquire(Params.simdTypeOf(st.indexType))
+		defer st.simd.release()
+	}
+
+	var (
+		dataPaths []string
+		task      *IndexBuildTask
+		blobs     []*Blob
+	)
+	stages := []struct {
+		name string
+		fn   func() error
+	}{
+		{selfTestStageGenerate, func() (err error) {
+			dataPaths, err = st.generate()
+			return err
+		}},
+		{selfTestStageBuild, func() (err error) {
+			task, err = st.build(ctx, dataPaths)
+			return err
+		}},
+		{selfTestStageSerialize, func() (err error) {
+			blobs, err = serializeIndexFiles(task.index, task.req)
+			return err
+		}},
+		{selfTestStageUpload, func() error {
+			return uploadIndexFiles(st.storage, st.prefix, task.req, blobs)
+		}},
+	}
+	for _, stage := range stages {
+		if err := ctx.Err(); err != nil {
+			fail(stage.name, err)
+			break
+		}
+		if err := stage.fn(); err != nil {
+			fail(stage.name, err)
+			break
+		}
+	}
+	if task != nil && task.index != nil {
+		if err := task.index.Delete(); err != nil {
+			log.Warn("IndexNode self-test failed to delete the index", zap.Error(err))
+		}
+	}
+	if err := st.storage.RemoveWithPrefix(st.prefix); err != nil {
+		fail(selfTestStageCleanup, err)
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result
+}
+
+// generate saves the binlog of the synthetic vectors.
+func (st *selfTest) generate() ([]string, error) {
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	vectors := make([]float32, selfTestRows*selfTestDim)
+	for idx := range vectors {
+		vectors[idx] = random.Float32()
+	}
+	timestamps := make([]int64, selfTestRows)
+	for idx := range timestamps {
+		timestamps[idx] = int64(idx)
+	}
+	insertCodec := storage.InsertCodec{
+		Schema: &etcdpb.CollectionMeta{
+			Schema: &schemapb.CollectionSchema{
+				Fields: []*schemapb.FieldSchema{
+					{FieldID: selfTestFieldID, Name: "vector", DataType: schemapb.DataType_FloatVector},
+				},
+			},
+		},
+	}
+	defer insertCodec.Close()
+	blobs, _, err := insertCodec.Serialize(0, 0, &storage.InsertData{
+		Data: map[UniqueID]storage.FieldData{
+			common.TimeStampField: &storage.Int64FieldData{NumRows: []int64{selfTestRows}, Data: timestamps},
+			selfTestFieldID:       &storage.FloatVectorFieldData{NumRows: []int64{selfTestRows}, Data: vectors, Dim: selfTestDim},
+		},
+		Infos: []storage.BlobInfo{{Length: selfTestRows}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(blobs))
+	for _, blob := range blobs {
+		dataPath := path.Join(st.prefix, "insert_log", blob.Key)
+		if err := st.storage.Save(dataPath, string(blob.Value)); err != nil {
+			return nil, fmt.Errorf("failed to save the binlog %s: %w", dataPath, err)
+		}
+		paths = append(paths, dataPath)
+	}
+	return paths, nil
+}
+
+// build loads, decodes and checks the binlogs, and builds the index with the engine as IndexBuildTask does.
+func (st *selfTest) build(ctx context.Context, dataPaths []string) (*IndexBuildTask, error) {
+	indexParams := map[string]string{indexTypeKey: st.indexType, metricTypeKey: "L2"}
+	if st.indexType == indexparamcheck.IndexFaissIvfFlat {
+		indexParams["nlist"] = strconv.Itoa(selfTestNlist)
+	}
+	typeParams := map[string]string{dimKey: strconv.Itoa(selfTestDim)}
+	index, err := st.newIndex(typeParams, engineConfigOf(st.indexType, indexParams))
+	if err != nil {
+		return nil, err
+	}
+	it := &IndexBuildTask{
+		BaseTask: BaseTask{ctx: ctx},
+		index:    index,
+		kv:       st.storage,
+		req: &indexpb.CreateIndexRequest{
+			IndexName:   selfTestObjectPrefix,
+			DataPaths:   dataPaths,
+			TypeParams:  keyValuePairsOf(typeParams),
+			IndexParams: keyValuePairsOf(indexParams),
+		},
+		stats:    newTaskStatistics(),
+		progress: newTaskProgress(0, 0),
+	}
+	if _, _, _, _, err := it.loadAndBuild(ctx, nil, timerecord.NewTimeRecorder("IndexNode self-test")); err != nil {
+		return it, err
+	}
+	return it, nil
+}
+
+// serializeIndexFiles serializes the synthetic index into the index files, which are encrypted as the ones of the
+// builds if the encryption is enabled.
+func serializeIndexFiles(index Index, req *indexpb.CreateIndexRequest) ([]*Blob, error) {
+	indexBlobs, err := index.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	if len(indexBlobs) == 0 {
+		return nil, errors.New("the engine serializes no index file")
+	}
+	_, indexParams, err := parseBuildParams(req)
+	if err != nil {
+		return nil, err
+	}
+	codec := storage.NewIndexFileBinlogCodec()
+	defer codec.Close()
+	blobs, err := codec.Serialize(0, 0, 0, 0, 0, selfTestFieldID, indexParams, req.IndexName, 0, indexBlobs)
+	if err != nil {
+		return nil, err
+	}
+	if Params.EncryptionKeyring != nil {
+		if _, err := encryptBlobs(Params.EncryptionKeyring, blobs); err != nil {
+			return nil, err
+		}
+	}
+	return blobs, nil
+}
+
+// uploadIndexFiles saves the synthetic index files along with their manifest under @prefix, and verifies the saved
+// files against it.
+func uploadIndexFiles(objects kv.BaseKV, prefix string, req *indexpb.CreateIndexRequest, blobs []*Blob) error {
+	typeParams, indexParams, err := parseBuildParams(req)
+	if err != nil {
+		return err
+	}
+	metadata := artifactObjectMetadata(currentArtifactVersion())
+	manifest := newIndexManifest(req, typeParams, indexParams, nil)
+	savePaths := make([]string, 0, len(blobs))
+	for _, blob := range blobs {
+		savePath := path.Join(prefix, "index_files", blob.Key)
+		if err := saveWithMetadata(objects, savePath, string(blob.Value), metadata); err != nil {
+			return fmt.Errorf("failed to save the index file %s: %w", savePath, err)
+		}
+		savePaths = append(savePaths, savePath)
+		manifest.addFile(blob.Key, int64(len(blob.Value)), checksumOf(blob.Value))
+	}
+	value, err := manifest.marshal()
+	if err != nil {
+		return err
+	}
+	manifestPath := path.Join(prefix, "index_files", manifestFileName)
+	if err := saveWithMetadata(objects, manifestPath, value, metadata); err != nil {
+		return fmt.Errorf("failed to save the manifest %s: %w", manifestPath, err)
+	}
+	_, mismatches, err := verifyManifest(objects, manifestPath, savePaths)
+	if err != nil {
+		return err
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("the saved index files do not agree with the manifest: %s", strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+// keyValuePairsOf returns the key value pairs of the params.
+func keyValuePairsOf(params map[string]string) []*commonpb.KeyValuePair {
+	pairs := make([]*commonpb.KeyValuePair, 0, len(params))
+	for key, value := range params {
+		pairs = append(pairs, &commonpb.KeyValuePair{Key: key, Value: value})
+	}
+	return pairs
+}
+
+// runSelfTest runs the self-test at startup, the node never reports ready if it fails.
+func (i *IndexNode) runSelfTest(st *selfTest) {
+	i.setStartupPhase(startupPhaseSelfTest)
+	ctx := i.loopCtx
+	if Params.SelfTestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, Params.SelfTestTimeout)
+		defer cancel()
+	}
+	result := st.run(ctx)
+	i.selfTest.Store(result)
+	fields := []zap.Field{zap.String("indexType", result.IndexType), zap.String("simdType", result.SimdType),
+		zap.Duration("duration", time.Duration(result.DurationMs)*time.Millisecond)}
+	if !result.Passed {
+		log.Error("IndexNode self-test failed, the node is not ready", append(fields,
+			zap.String("stage", result.FailedStage), zap.String("error", result.Error))...)
+		i.probe.update(probeSelfTest, fmt.Errorf("failed at the %s stage: %s", result.FailedStage, result.Error))
+		return
+	}
+	log.Info("IndexNode self-test passed", fields...)
+	i.probe.update(probeSelfTest, nil)
+}
+
+// selfTestResult returns the result of the self-test, nil if it has not run.
+func (i *IndexNode) selfTestResult() *metricsinfo.IndexNodeSelfTest {
+	result, _ := i.selfTest.Load().(*metricsinfo.IndexNodeSelfTest)
+	return result
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	memkv "github.com/milvus-io/milvus/internal/kv/mem"
+	"github.com/milvus-io/milvus/internal/util/indexparamcheck"
+)
+
+// recordingKV records the keys saved in it, truncates the objects named truncateName, and fails removing them if
+// removeErr is set.
+type recordingKV struct {
+	*memkv.MemoryKV
+	saved        []string
+	truncateName string
+	removeErr    error
+}
+
+func (kv *recordingKV) Save(key, value string) error {
+	kv.saved = append(kv.saved, key)
+	if path.Base(key) == kv.truncateName {
+		value = value[:len(value)/2]
+	}
+	return kv.MemoryKV.Save(key, value)
+}
+
+func (kv *recordingKV) RemoveWithPrefix(prefix string) error {
+	if kv.removeErr != nil {
+		return kv.removeErr
+	}
+	return kv.MemoryKV.RemoveWithPrefix(prefix)
+}
+
+func newTestSelfTest(storage *recordingKV, index Index) *selfTest {
+	st := newSelfTest(storage, indexparamcheck.IndexFaissIvfFlat, nil)
+	st.newIndex = func(typeParams, indexParams map[string]string) (Index, error) {
+		if index == nil {
+			return nil, errors.New("the engine is not loaded")
+		}
+		return index, nil
+	}
+	return st
+}
+
+func TestSelfTest(t *testing.T) {
+	ctx := context.Background()
+	storage := &recordingKV{MemoryKV: memkv.NewMemoryKV()}
+	index := &serializedIncrementalIndex{}
+	st := newTestSelfTest(storage, index)
+	st.simd = newSimdSwitcher("auto", "avx2", func(simdType string) string { return simdType })
+	result := st.run(ctx)
+	assert.True(t, result.Passed, result.Error)
+	assert.Equal(t, indexparamcheck.IndexFaissIvfFlat, result.IndexType)
+	assert.Equal(t, "avx2", result.SimdType)
+	assert.Empty(t, result.FailedStage)
+	assert.Equal(t, selfTestRows*selfTestDim, len(index.floatData))
+
+	// the binlogs, the index files and the manifest are all saved under the prefix and removed
+	assert.True(t, strings.HasPrefix(st.prefix, path.Join(Params.IndexRootPath, selfTestObjectPrefix)+"/"))
+	var foundManifest bool
+	for _, key := range storage.saved {
+		assert.True(t, strings.HasPrefix(key, st.prefix+"/"), key)
+		foundManifest = foundManifest || path.Base(key) == manifestFileName
+	}
+	assert.True(t, foundManifest)
+	assert.True(t, len(storage.saved) >= 3)
+	keys, _, err := storage.LoadWithPrefix("")
+	assert.Nil(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestSelfTest_failures(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	cases := []struct {
+		name      string
+		ctx       context.Context
+		newKV     func() *recordingKV
+		index     Index
+		stage     string
+		errSubstr string
+	}{
+		{"canceled", canceled, nil, &serializedIncrementalIndex{}, selfTestStageGenerate, "canceled"},
+		{"engine", context.Background(), nil, nil, selfTestStageBuild, "the engine is not loaded"},
+		{"serialize", context.Background(), nil, &mockIncrementalIndex{}, selfTestStageSerialize, "no index file"},
+		{"upload", context.Background(), func() *recordingKV {
+			return &recordingKV{MemoryKV: memkv.NewMemoryKV(), truncateName: "IVF"}
+		}, &serializedIncrementalIndex{}, selfTestStageUpload, "IVF: size"},
+		{"cleanup", context.Background(), func() *recordingKV {
+			return &recordingKV{MemoryKV: memkv.NewMemoryKV(), removeErr: errors.New("access denied")}
+		}, &serializedIncrementalIndex{}, selfTestStageCleanup, "access denied"},
+	}
+	for _, c := range cases {
+		storage := &recordingKV{MemoryKV: memkv.NewMemoryKV()}
+		if c.newKV != nil {
+			storage = c.newKV()
+		}
+		result := newTestSelfTest(storage, c.index).run(c.ctx)
+		assert.False(t, result.Passed, c.name)
+		assert.Equal(t, c.stage, result.FailedStage, c.name)
+		assert.Contains(t, result.Error, c.errSubstr, c.name)
+		if c.stage != selfTestStageCleanup {
+			// the objects saved before the failure are removed as well
+			keys, _, err := storage.LoadWithPrefix("")
+			assert.Nil(t, err)
+			assert.Empty(t, keys, c.name)
+		}
+	}
+}
+
+func TestIndexNode_runSelfTest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	in := &IndexNode{loopCtx: ctx, probe: newReadinessProbe()}
+	assert.Nil(t, in.selfTestResult())
+
+	in.runSelfTest(newTestSelfTest(&recordingKV{MemoryKV: memkv.NewMemoryKV()}, nil))
+	assert.Equal(t, startupPhaseSelfTest, in.startupPhase())
+	assert.False(t, in.selfTestResult().Passed)
+	_, err := in.probe.check(probeSelfTest)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), selfTestStageBuild)
+	assert.Contains(t, in.probe.result().Checks[probeSelfTest], "the engine is not loaded")
+
+	in.runSelfTest(newTestSelfTest(&recordingKV{MemoryKV: memkv.NewMemoryKV()}, &serializedIncrementalIndex{}))
+	assert.True(t, in.selfTestResult().Passed)
+	_, err = in.probe.check(probeSelfTest)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,210 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/sys/cpu"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/metrics"
+	"github.com/milvus-io/milvus/internal/util/indexparamcheck"
+)
+
+// acceptedSimdTypes lists the simd types accepted in the configuration.
+const acceptedSimdTypes = "auto, sse4_2, avx2, avx512"
+
+// simdTypeAliases maps the simd types accepted in the configuration to the ones of the engine.
+var simdTypeAliases = map[string]string{
+	"":       "auto",
+	"auto":   "auto",
+	"sse4_2": "sse",
+	// sse is the name used by the engine, accepted for the configurations written with it
+	"sse":    "sse",
+	"avx2":   "avx2",
+	"avx512": "avx512",
+}
+
+// normalizeSimdType returns the simd type of the engine for the configured one, which is case-insensitive.
+func normalizeSimdType(simdType string) (string, error) {
+	normalized, ok := simdTypeAliases[strings.ToLower(strings.TrimSpace(simdType))]
+	if !ok {
+		return "", fmt.Errorf("invalid simd type %q, accepted values: %s", simdType, acceptedSimdTypes)
+	}
+	return normalized, nil
+}
+
+// simdLevels are the simd types from the best to the worst.
+var simdLevels = []string{"avx512", "avx2", "sse"}
+
+// cpuFeatures are the instruction sets supported by the CPU which the engine makes use of.
+type cpuFeatures struct {
+	sse42  bool
+	avx2   bool
+	avx512 bool
+}
+
+// detectCPUFeatures detects the CPU features, it is a variable so that tests can mock it.
+var detectCPUFeatures = func() cpuFeatures {
+	return cpuFeatures{
+		sse42: cpu.X86.HasSSE42,
+		avx2:  cpu.X86.HasAVX2,
+		// the same as the engine requires for avx512
+		avx512: cpu.X86.HasAVX512F && cpu.X86.HasAVX512DQ && cpu.X86.HasAVX512BW,
+	}
+}
+
+func (f cpuFeatures) supports(simdType string) bool {
+	switch simdType {
+	case "avx512":
+		return f.avx512
+	case "avx2":
+		return f.avx2
+	case "sse":
+		return f.sse42
+	default:
+		return simdType == "auto"
+	}
+}
+
+// resolveSimdType returns the simd type supported by the CPU, the unsupported simd type is downgraded to
+// the best supported one, mismatch is returned along with it to describe the downgrade.
+func resolveSimdType(simdType string, features cpuFeatures) (resolved string, mismatch error) {
+	if features.supports(simdType) {
+		return simdType, nil
+	}
+	for _, level := range simdLevels {
+		if features.supports(level) {
+			return level, fmt.Errorf("simd type %s is not supported by the CPU, downgrade to %s", simdType, level)
+		}
+	}
+	return simdType, fmt.Errorf("simd type %s is not supported by the CPU, and no simd type is available", simdType)
+}
+
+// engineSimdTypes maps the instruction sets reported by the engine to the simd types.
+var engineSimdTypes = map[string]string{
+	"AVX512": "avx512",
+	"AVX2":   "avx2",
+	"SSE42":  "sse",
+	"SSE4_2": "sse",
+}
+
+// effectiveSimdType returns the simd type the engine actually uses once @simdType is set to it, which is the
+// instruction set @reported by the engine. The unknown report is inferred from @features as the engine chooses,
+// the best level allowed by the simd type, and unknownSimdType is returned if the CPU supports none of them.
+func effectiveSimdType(simdType string, reported string, features cpuFeatures) string {
+	if effective, ok := engineSimdTypes[strings.ToUpper(strings.TrimSpace(reported))]; ok {
+		return effective
+	}
+	for _, level := range simdLevels {
+		if (simdType == "auto" || simdType == level) && features.supports(level) {
+			return level
+		}
+	}
+	return unknownSimdType
+}
+
+// simdTypeMismatched returns whether the engine uses another simd type than the configured one, which is never
+// the case if auto is configured.
+func simdTypeMismatched(configured string, effective string) bool {
+	return configured != "auto" && configured != effective
+}
+
+// recordEffectiveSimdType logs the configured and the effective simd types of the engine at startup, the mismatch
+// of them is warned and raises IndexNodeSimdTypeMismatch.
+func recordEffectiveSimdType() {
+	// the requested simd type is already validated
+	configured, _ := normalizeSimdType(Params.SimdTypeRequested)
+	fields := []zap.Field{
+		zap.String("requested_simd_type", Params.SimdTypeRequested),
+		zap.String("simd_type", Params.SimdType),
+		zap.String("effective_simd_type", Params.EffectiveSimdType),
+	}
+	metrics.IndexNodeSimdTypeMismatch.Reset()
+	if simdTypeMismatched(configured, Params.EffectiveSimdType) {
+		metrics.IndexNodeSimdTypeMismatch.WithLabelValues(configured, Params.EffectiveSimdType).Set(1)
+		log.Warn("!!! the engine uses another simd type than the configured one, "+
+			"the index building may be slower than expected !!!", fields...)
+		return
+	}
+	log.Info("IndexNode engine simd type", fields...)
+}
+
+func isKnownIndexType(indexType string) bool {
+	if isDiskIndexType(indexType) {
+		return true
+	}
+	_, err := indexparamcheck.GetConfAdapterMgrInstance().GetAdapter(strings.ToUpper(indexType))
+	return err == nil
+}
+
+// simdSwitcher sets the simd type of the engine for each build. The simd type is global in the engine,
+// so a build waits until the builds with other simd types finish.
+type simdSwitcher struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	// defaultType is the simd type used by the builds without an override
+	defaultType string
+	// current is the simd type set to the engine, and effective is the instruction set it resolves to
+	current   string
+	effective string
+	active    int
+
+	setSimdType func(simdType string) string
+}
+
+func newSimdSwitcher(defaultType string, effective string, setSimdType func(simdType string) string) *simdSwitcher {
+	s := &simdSwitcher{
+		defaultType: defaultType,
+		current:     defaultType,
+		effective:   effective,
+		setSimdType: setSimdType,
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// acquire sets the simd type for a build and returns the effective one, an empty simd type means the default one.
+// release must be called once the build finishes.
+func (s *simdSwitcher) acquire(simdType string) string {
+	if simdType == "" {
+		simdType = s.defaultType
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.active > 0 && s.current != simdType {
+		s.cond.Wait()
+	}
+	if s.current != simdType {
+		s.effective = s.setSimdType(simdType)
+		s.current = simdType
+		log.Debug("IndexNode switch the simd type",
+			zap.String("simd_type", simdType),
+			zap.String("effective_simd_type", s.effective))
+	}
+	s.active++
+	return s.effective
+}
+
+func (s *simdSwitcher) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active--
+	if s.active == 0 {
+		s.cond.Broadcast()
+	}
+}
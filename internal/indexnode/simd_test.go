@@ -0,0 +1,201 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/metrics"
+)
+
+func TestIsKnownIndexType(t *testing.T) {
+	assert.True(t, isKnownIndexType("HNSW"))
+	assert.True(t, isKnownIndexType("ivf_pq"))
+	assert.True(t, isKnownIndexType("DISKANN"))
+	assert.False(t, isKnownIndexType("NOT_EXIST"))
+}
+
+func TestNormalizeSimdType(t *testing.T) {
+	accepted := map[string]string{
+		"":       "auto",
+		"auto":   "auto",
+		"sse4_2": "sse",
+		"sse":    "sse",
+		"avx2":   "avx2",
+		"avx512": "avx512",
+		// mixed case and spaces
+		"AUTO":     "auto",
+		"SSE4_2":   "sse",
+		"Avx2":     "avx2",
+		" AVX512 ": "avx512",
+	}
+	for simdType, expected := range accepted {
+		normalized, err := normalizeSimdType(simdType)
+		assert.Nil(t, err, simdType)
+		assert.Equal(t, expected, normalized, simdType)
+	}
+
+	for _, simdType := range []string{"avx", "sse4", "sse4.2", "avx-512", "neon", "auto,avx2"} {
+		_, err := normalizeSimdType(simdType)
+		assert.NotNil(t, err, simdType)
+		assert.Contains(t, err.Error(), acceptedSimdTypes)
+	}
+}
+
+func TestResolveSimdType(t *testing.T) {
+	all := cpuFeatures{sse42: true, avx2: true, avx512: true}
+	for _, simdType := range []string{"auto", "avx512", "avx2", "sse"} {
+		resolved, mismatch := resolveSimdType(simdType, all)
+		assert.Equal(t, simdType, resolved)
+		assert.Nil(t, mismatch)
+	}
+
+	noAVX512 := cpuFeatures{sse42: true, avx2: true}
+	resolved, mismatch := resolveSimdType("avx512", noAVX512)
+	assert.Equal(t, "avx2", resolved)
+	assert.NotNil(t, mismatch)
+	resolved, mismatch = resolveSimdType("auto", noAVX512)
+	assert.Equal(t, "auto", resolved)
+	assert.Nil(t, mismatch)
+
+	sseOnly := cpuFeatures{sse42: true}
+	resolved, mismatch = resolveSimdType("avx512", sseOnly)
+	assert.Equal(t, "sse", resolved)
+	assert.NotNil(t, mismatch)
+	resolved, _ = resolveSimdType("avx2", sseOnly)
+	assert.Equal(t, "sse", resolved)
+
+	_, mismatch = resolveSimdType("sse", cpuFeatures{})
+	assert.NotNil(t, mismatch)
+
+	// the detection should at least work on the machine running the tests
+	_ = detectCPUFeatures()
+}
+
+func TestEffectiveSimdType(t *testing.T) {
+	// the instruction set reported by the engine is the effective one
+	for reported, effective := range map[string]string{"AVX512": "avx512", "AVX2": "avx2", "SSE42": "sse", " sse4_2 ": "sse"} {
+		assert.Equal(t, effective, effectiveSimdType("auto", reported, cpuFeatures{}))
+	}
+
+	// the unknown report is inferred from the CPU features
+	all := cpuFeatures{sse42: true, avx2: true, avx512: true}
+	noAVX512 := cpuFeatures{sse42: true, avx2: true}
+	for _, c := range []struct {
+		simdType  string
+		features  cpuFeatures
+		effective string
+	}{
+		{"auto", all, "avx512"},
+		{"auto", noAVX512, "avx2"},
+		{"auto", cpuFeatures{sse42: true}, "sse"},
+		{"auto", cpuFeatures{}, unknownSimdType},
+		{"avx2", all, "avx2"},
+		{"sse", all, "sse"},
+		{"avx512", noAVX512, unknownSimdType},
+	} {
+		assert.Equal(t, c.effective, effectiveSimdType(c.simdType, "UNSUPPORTED", c.features), c)
+		assert.Equal(t, c.effective, effectiveSimdType(c.simdType, "", c.features), c)
+	}
+}
+
+func TestRecordEffectiveSimdType(t *testing.T) {
+	oldDetect := detectCPUFeatures
+	oldRequested, oldSimdType, oldEffective := Params.SimdTypeRequested, Params.SimdType, Params.EffectiveSimdType
+	defer func() {
+		detectCPUFeatures = oldDetect
+		Params.SimdTypeRequested, Params.SimdType, Params.EffectiveSimdType = oldRequested, oldSimdType, oldEffective
+		metrics.IndexNodeSimdTypeMismatch.Reset()
+	}()
+
+	all := cpuFeatures{sse42: true, avx2: true, avx512: true}
+	noAVX512 := cpuFeatures{sse42: true, avx2: true}
+	for _, c := range []struct {
+		requested string
+		features  cpuFeatures
+		// reported is the instruction set reported by the engine, which is inferred if it is unknown
+		reported  string
+		effective string
+		mismatch  bool
+	}{
+		{"auto", all, "AVX512", "avx512", false},
+		{"auto", noAVX512, "", "avx2", false},
+		{"AVX512", all, "AVX512", "avx512", false},
+		{"avx512", all, "AVX2", "avx2", true},
+		{"avx512", noAVX512, "", "avx2", true},
+		{"sse4_2", all, "", "sse", false},
+		{"avx2", cpuFeatures{sse42: true}, "", "sse", true},
+	} {
+		detectCPUFeatures = func() cpuFeatures { return c.features }
+		configured, err := normalizeSimdType(c.requested)
+		assert.Nil(t, err)
+		resolved, _ := resolveSimdType(configured, detectCPUFeatures())
+		Params.SimdTypeRequested, Params.SimdType = c.requested, resolved
+		Params.EffectiveSimdType = effectiveSimdType(resolved, c.reported, detectCPUFeatures())
+		assert.Equal(t, c.effective, Params.EffectiveSimdType, c)
+
+		recordEffectiveSimdType()
+		if c.mismatch {
+			assert.Equal(t, 1, testutil.CollectAndCount(metrics.IndexNodeSimdTypeMismatch), c)
+			assert.Equal(t, 1.0, testutil.ToFloat64(metrics.IndexNodeSimdTypeMismatch.WithLabelValues(configured, c.effective)), c)
+		} else {
+			assert.Equal(t, 0, testutil.CollectAndCount(metrics.IndexNodeSimdTypeMismatch), c)
+		}
+	}
+}
+
+func TestSimdSwitcher(t *testing.T) {
+	var mu sync.Mutex
+	setTypes := make([]string, 0)
+	setSimdType := func(simdType string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		setTypes = append(setTypes, simdType)
+		return strings.ToUpper(simdType)
+	}
+	s := newSimdSwitcher("auto", "AVX2", setSimdType)
+
+	// the default simd type is already set
+	assert.Equal(t, "AVX2", s.acquire(""))
+	assert.Equal(t, "AVX2", s.acquire("auto"))
+	assert.Equal(t, 0, len(setTypes))
+
+	// the build with another simd type waits for the running builds
+	acquired := make(chan string)
+	go func() {
+		acquired <- s.acquire("avx512")
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("the simd type is switched while the builds are running")
+	case <-time.After(100 * time.Millisecond):
+	}
+	s.release()
+	s.release()
+	assert.Equal(t, "AVX512", <-acquired)
+	assert.Equal(t, []string{"avx512"}, setTypes)
+
+	// the builds with the same simd type run at the same time
+	assert.Equal(t, "AVX512", s.acquire("avx512"))
+	s.release()
+	s.release()
+
+	assert.Equal(t, "AUTO", s.acquire(""))
+	s.release()
+	assert.Equal(t, []string{"avx512", "auto"}, setTypes)
+}
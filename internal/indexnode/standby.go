@@ -0,0 +1,75 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+// the modes of IndexNode, the standby node is a warm spare which is fully initialized and registered, but rejects
+// the build requests until it's activated
+const (
+	modeActive  = "active"
+	modeStandby = "standby"
+)
+
+// modeKey is the key of the extra info in the component states reporting the mode
+const modeKey = "mode"
+
+func (i *IndexNode) isStandby() bool {
+	i.modeMu.RLock()
+	defer i.modeMu.RUnlock()
+	return i.standby
+}
+
+func (i *IndexNode) mode() string {
+	if i.isStandby() {
+		return modeStandby
+	}
+	return modeActive
+}
+
+// Activate switches the standby IndexNode to active. The registration is updated before the switch and the requests
+// arriving meanwhile wait for it, so that no request is accepted while the node is registered as standby.
+func (i *IndexNode) Activate(ctx context.Context, req *indexpb.ActivateRequest) (*commonpb.Status, error) {
+	if !i.isHealthy() {
+		return i.notReadyStatus(), nil
+	}
+	i.modeMu.Lock()
+	defer i.modeMu.Unlock()
+	if !i.standby {
+		return &commonpb.Status{ErrorCode: commonpb.ErrorCode_Success}, nil
+	}
+	if i.session != nil {
+		i.sessionMu.Lock()
+		defer i.sessionMu.Unlock()
+		i.session.Standby = false
+		if err := i.session.UpdateRegistration(); err != nil {
+			i.session.Standby = true
+			log.Warn("IndexNode failed to update the registration on activation", zap.Error(err))
+			return &commonpb.Status{
+				ErrorCode: commonpb.ErrorCode_UnexpectedError,
+				Reason:    errorcode.Format(errorcode.EtcdUnavailable, "failed to update the registration: "+err.Error()),
+			}, nil
+		}
+	}
+	i.standby = false
+	log.Info("IndexNode is activated", zap.Int64("NodeID", Params.NodeID))
+	return &commonpb.Status{ErrorCode: commonpb.ErrorCode_Success}, nil
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+	"github.com/milvus-io/milvus/internal/util/metricsinfo"
+	"github.com/milvus-io/milvus/internal/util/sessionutil"
+	"github.com/milvus-io/milvus/internal/util/typeutil"
+)
+
+func TestIndexNode_Standby(t *testing.T) {
+	e, endpoints := startEmbedEtcd(t)
+	defer e.Close()
+
+	Params.Init()
+	oldEndpoints, oldMetaRootPath, oldPersistNodeID, oldStandby := Params.EtcdEndpoints, Params.MetaRootPath,
+		Params.PersistNodeID, Params.Standby
+	defer func() {
+		Params.EtcdEndpoints, Params.MetaRootPath, Params.PersistNodeID, Params.Standby = oldEndpoints,
+			oldMetaRootPath, oldPersistNodeID, oldStandby
+	}()
+	Params.EtcdEndpoints = endpoints
+	Params.MetaRootPath = fmt.Sprintf("standby-test-%d", time.Now().UnixNano())
+	Params.PersistNodeID = false
+	Params.Standby = true
+
+	ctx := context.Background()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	defer in.Stop()
+
+	// the node is not activated before it's serving
+	status, err := in.Activate(ctx, &indexpb.ActivateRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, status.ErrorCode)
+
+	err = in.Register()
+	assert.Nil(t, err)
+	in.probe.update(probeStorage, nil)
+	in.setStartupPhase(startupPhaseServing)
+	in.UpdateStateCode(internalpb.StateCode_Healthy)
+
+	registeredStandby := func() bool {
+		sessions, _, err := in.session.GetSessions(typeutil.IndexNodeRole)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(sessions))
+		for _, session := range sessions {
+			return session.Standby
+		}
+		return false
+	}
+	assertMode := func(mode string) {
+		states, err := in.GetComponentStates(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, mode, extraInfo(states.State, modeKey))
+
+		req, err := metricsinfo.ConstructRequestByMetricType(metricsinfo.SystemInfoMetrics)
+		assert.Nil(t, err)
+		resp, err := in.GetMetrics(ctx, req)
+		assert.Nil(t, err)
+		infos := metricsinfo.IndexNodeInfos{}
+		err = metricsinfo.UnmarshalComponentInfos(resp.Response, &infos)
+		assert.Nil(t, err)
+		assert.Equal(t, mode, infos.Mode)
+	}
+
+	assert.True(t, registeredStandby())
+	assertMode(modeStandby)
+	status, err = in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, status.ErrorCode)
+	assert.Equal(t, msgIndexNodeIsStandby(Params.NodeID), status.Reason)
+
+	// the concurrent requests are either rejected as standby, or accepted after the activation
+	var wg sync.WaitGroup
+	var activated sync.WaitGroup
+	activated.Add(1)
+	for idx := 0; idx < 8; idx++ {
+		wg.Add(1)
+		go func(buildID UniqueID) {
+			defer wg.Done()
+			status, err := in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: buildID})
+			assert.Nil(t, err)
+			if status.ErrorCode != commonpb.ErrorCode_Success {
+				assert.Equal(t, msgIndexNodeIsStandby(Params.NodeID), status.Reason)
+			}
+			activated.Wait()
+			status, err = in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: buildID + 100})
+			assert.Nil(t, err)
+			assert.Equal(t, commonpb.ErrorCode_Success, status.ErrorCode)
+		}(UniqueID(idx + 2))
+	}
+	status, err = in.Activate(ctx, &indexpb.ActivateRequest{})
+	activated.Done()
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, status.ErrorCode)
+	wg.Wait()
+
+	assert.False(t, registeredStandby())
+	assertMode(modeActive)
+
+	// activating the active node is a no-op
+	status, err = in.Activate(ctx, &indexpb.ActivateRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, status.ErrorCode)
+}
+
+func TestIndexNode_ActivateFailed(t *testing.T) {
+	in, clean := newHealthyIndexNode(t)
+	defer clean()
+	in.standby = true
+	// the session is not registered
+	in.session = &sessionutil.Session{}
+	status, err := in.Activate(context.Background(), &indexpb.ActivateRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_UnexpectedError, status.ErrorCode)
+	assert.True(t, in.isStandby())
+	assert.True(t, in.session.Standby)
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+)
+
+// startupPhase is the phase of the startup sequence of IndexNode, the phases run in the order of
+// params -> etcd -> storage -> self_test -> session -> serving, self_test is skipped unless the self-test is enabled.
+type startupPhase string
+
+const (
+	startupPhaseParams  startupPhase = "params"
+	startupPhaseEtcd    startupPhase = "etcd"
+	startupPhaseStorage startupPhase = "storage"
+	// the self-test never fails the startup, the node which fails it keeps not ready instead, see runSelfTest
+	startupPhaseSelfTest startupPhase = "self_test"
+	startupPhaseSession  startupPhase = "session"
+	// the node serves the requests once it reaches the serving phase
+	startupPhaseServing startupPhase = "serving"
+)
+
+// startupPhaseKey is the key of the extra info in the component states reporting the startup phase
+const startupPhaseKey = "startup_phase"
+
+func (i *IndexNode) setStartupPhase(phase startupPhase) {
+	i.phase.Store(phase)
+}
+
+func (i *IndexNode) startupPhase() startupPhase {
+	phase, ok := i.phase.Load().(startupPhase)
+	if !ok {
+		return startupPhaseParams
+	}
+	return phase
+}
+
+// runStartupPhase runs the phase of the startup sequence and logs its duration, the phase fails if it does not
+// finish within the timeout, 0 means unlimited. The timed out phase keeps running in the background until the
+// loop context is canceled by Stop, which follows the failed startup.
+func (i *IndexNode) runStartupPhase(phase startupPhase, timeout time.Duration, fn func() error) error {
+	i.setStartupPhase(phase)
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn()
+	}()
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+	var err error
+	select {
+	case err = <-errCh:
+	case <-timeoutCh:
+		err = fmt.Errorf("IndexNode startup phase %s timed out after %s", phase, timeout)
+	}
+	if err != nil {
+		log.Error("IndexNode startup phase failed", zap.String("phase", string(phase)),
+			zap.Duration("duration", time.Since(start)), zap.Error(err))
+		return err
+	}
+	log.Info("IndexNode startup phase finished", zap.String("phase", string(phase)),
+		zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+// notReadyStatus returns the status of the request rejected since the node is not healthy, telling the startup
+// phase if the node is still starting.
+func (i *IndexNode) notReadyStatus() *commonpb.Status {
+	reason := msgIndexNodeIsUnhealthy(Params.NodeID)
+	if phase := i.startupPhase(); phase != startupPhaseServing {
+		reason = msgIndexNodeIsStarting(Params.NodeID, phase)
+	}
+	return &commonpb.Status{
+		ErrorCode: commonpb.ErrorCode_UnexpectedError,
+		Reason:    reason,
+	}
+}
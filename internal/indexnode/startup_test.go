@@ -0,0 +1,129 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+	"github.com/milvus-io/milvus/internal/proto/milvuspb"
+	"github.com/milvus-io/milvus/internal/util/metricsinfo"
+)
+
+func TestRunStartupPhase(t *testing.T) {
+	in, err := NewIndexNode(context.Background())
+	assert.Nil(t, err)
+	defer in.Stop()
+	assert.Equal(t, startupPhaseParams, in.startupPhase())
+
+	err = in.runStartupPhase(startupPhaseEtcd, time.Second, func() error {
+		assert.Equal(t, startupPhaseEtcd, in.startupPhase())
+		return nil
+	})
+	assert.Nil(t, err)
+
+	err = in.runStartupPhase(startupPhaseStorage, 0, func() error {
+		return errors.New("storage unavailable")
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, startupPhaseStorage, in.startupPhase())
+
+	block := make(chan struct{})
+	defer close(block)
+	start := time.Now()
+	err = in.runStartupPhase(startupPhaseSession, 100*time.Millisecond, func() error {
+		<-block
+		return nil
+	})
+	assert.NotNil(t, err)
+	assert.True(t, time.Since(start) < 10*time.Second)
+	assert.Equal(t, startupPhaseSession, in.startupPhase())
+}
+
+func TestIndexNode_StartupGate(t *testing.T) {
+	ctx := context.Background()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	defer in.Stop()
+	metricsRequest, err := metricsinfo.ConstructRequestByMetricType(metricsinfo.SystemInfoMetrics)
+	assert.Nil(t, err)
+
+	assertNotReady := func(t *testing.T, phase startupPhase) {
+		status, err := in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_UnexpectedError, status.ErrorCode)
+		assert.Equal(t, msgIndexNodeIsStarting(Params.NodeID, phase), status.Reason)
+
+		dryRunResp, err := in.DryRunCreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_UnexpectedError, dryRunResp.Status.ErrorCode)
+		assert.Equal(t, msgIndexNodeIsStarting(Params.NodeID, phase), dryRunResp.Status.Reason)
+
+		metricsResp, err := in.GetMetrics(ctx, metricsRequest)
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_UnexpectedError, metricsResp.Status.ErrorCode)
+		assert.Equal(t, msgIndexNodeIsStarting(Params.NodeID, phase), metricsResp.Status.Reason)
+
+		states, err := in.GetComponentStates(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, states.Status.ErrorCode)
+		assert.NotEqual(t, internalpb.StateCode_Healthy, states.State.StateCode)
+		assert.Equal(t, string(phase), extraInfo(states.State, startupPhaseKey))
+
+		channelResp, err := in.GetStatisticsChannel(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, channelResp.Status.ErrorCode)
+	}
+
+	for _, phase := range []startupPhase{startupPhaseParams, startupPhaseEtcd, startupPhaseStorage, startupPhaseSession} {
+		t.Run(string(phase), func(t *testing.T) {
+			in.setStartupPhase(phase)
+			in.UpdateStateCode(internalpb.StateCode_Initializing)
+			assertNotReady(t, phase)
+		})
+	}
+
+	t.Run("serving", func(t *testing.T) {
+		in.probe.update(probeEtcdSession, nil)
+		in.probe.update(probeStorage, nil)
+		in.setStartupPhase(startupPhaseServing)
+		in.UpdateStateCode(internalpb.StateCode_Healthy)
+
+		// the scheduler is not started, so the task stays in the queue
+		status, err := in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, commonpb.ErrorCode_Success, status.ErrorCode)
+
+		states, err := in.GetComponentStates(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, "", extraInfo(states.State, startupPhaseKey))
+		_, err = time.Parse(time.RFC3339, extraInfo(states.State, createdTimeKey))
+		assert.Nil(t, err)
+		_, err = time.Parse(time.RFC3339, extraInfo(states.State, updatedTimeKey))
+		assert.Nil(t, err)
+		assert.NotEmpty(t, extraInfo(states.State, uptimeKey))
+		assert.NotEmpty(t, extraInfo(states.State, configRefreshesKey))
+
+		// the node stopped after serving is not ready, but not starting
+		in.UpdateStateCode(internalpb.StateCode_Abnormal)
+		metricsResp, err := in.GetMetrics(ctx, &milvuspb.GetMetricsRequest{Request: metricsRequest.Request})
+		assert.Nil(t, err)
+		assert.Equal(t, msgIndexNodeIsUnhealthy(Params.NodeID), metricsResp.Status.Reason)
+	})
+}
@@ -18,17 +18,21 @@ import (
 	"path"
 	"runtime"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"go.uber.org/zap"
 
 	"github.com/milvus-io/milvus/internal/kv"
-	etcdkv "github.com/milvus-io/milvus/internal/kv/etcd"
 	"github.com/milvus-io/milvus/internal/log"
 	"github.com/milvus-io/milvus/internal/proto/commonpb"
 	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
 	"github.com/milvus-io/milvus/internal/storage"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
 	"github.com/milvus-io/milvus/internal/util/funcutil"
+	"github.com/milvus-io/milvus/internal/util/requestid"
 	"github.com/milvus-io/milvus/internal/util/retry"
 	"github.com/milvus-io/milvus/internal/util/timerecord"
 	"github.com/milvus-io/milvus/internal/util/trace"
@@ -36,7 +40,13 @@ import (
 
 const (
 	paramsKeyToParse   = "params"
+	indexTypeKey       = "index_type"
+	dimKey             = "dim"
+	metricTypeKey      = "metric_type"
 	IndexBuildTaskName = "IndexBuildTask"
+
+	// insertLogPathSegment is the path element followed by the collection ID in the paths of insert binlogs
+	insertLogPathSegment = "insert_log"
 )
 
 type task interface {
@@ -75,7 +85,7 @@ func (bt *BaseTask) setID(id UniqueID) {
 func (bt *BaseTask) WaitToFinish() error {
 	select {
 	case <-bt.ctx.Done():
-		return errors.New("timeout")
+		return errorcode.New(errorcode.Timeout, "timeout")
 	case err := <-bt.done:
 		return err
 	}
@@ -89,16 +99,63 @@ type IndexBuildTask struct {
 	BaseTask
 	index     Index
 	kv        kv.BaseKV
-	etcdKV    *etcdkv.EtcdKV
+	etcdKV    *metricsEtcdKV
 	savePaths []string
 	req       *indexpb.CreateIndexRequest
 	nodeID    UniqueID
+	stats     *taskStatistics
+	startTime time.Time
+	// fileManifest is the files uploaded by disk index types
+	fileManifest []*indexpb.IndexFileInfo
+	// manifestPath is the manifest of all the files saved by the build
+	manifestPath string
+	// encryption is how the saved files are encrypted, nil if they are not
+	encryption *indexpb.IndexEncryption
+	simd       *simdSwitcher
+	// simdType is the effective simd type the index is built with, and configuredSimdType is the configured one
+	simdType           string
+	configuredSimdType string
+	// finalErr is the error the task finishes with, including the failure of updating the index meta
+	finalErr error
+	// lowPriority is whether the task is of the low-priority class of the task queue, such as a rebuild
+	lowPriority bool
+	// skipped is whether the segment has no rows, the task finishes without any index file
+	skipped bool
+	// checkpointFiles are the index files saved by the failed build, which are retained for resuming it
+	checkpointFiles []string
+	// cleaner removes the temporary resources of the task once it finishes
+	cleaner *taskCleaner
+	// progress is the stage of the task reported by the heartbeats, which is tracked by tracker while it runs
+	progress *taskProgress
+	tracker  *taskTracker
+	// events records the lifecycle events of the task
+	events *taskEventLog
+	// loadedBytes is the size of the binlogs loaded by the task
+	loadedBytes int64
+	// vectorDim is the dim of the decoded vectors, which is in bits for the binary vectors
+	vectorDim int64
+	// elementType is the data type of the decoded vectors, which is recorded in the index meta
+	elementType schemapb.DataType
+	// normalized is whether the vectors are L2-normalized before they are fed to the engine
+	normalized bool
+	// buildSeed is the seed the index is built with, nil if the build is not seeded
+	buildSeed *indexpb.IndexBuildSeed
+	// base is the index meta of the base build whose index is appended with the vectors, nil for a full build
+	base *indexpb.IndexMeta
+	// enqueueTime is when the task is enqueued
+	enqueueTime time.Time
 }
 
 func (it *IndexBuildTask) Ctx() context.Context {
 	return it.ctx
 }
 
+// logger returns @base attaching the request id carried by the context of the request creating the task, so that
+// the entries of the task share the id with the ones of the request.
+func (it *IndexBuildTask) logger(base *zap.Logger) *zap.Logger {
+	return requestid.Logger(it.ctx, base)
+}
+
 func (it *IndexBuildTask) ID() UniqueID {
 	return it.id
 }
@@ -111,33 +168,126 @@ func (bt *BaseTask) Name() string {
 	return IndexBuildTaskName
 }
 
+// indexType returns the index type in the index params of the request, or an empty string if it is not specified.
+func (it *IndexBuildTask) indexType() string {
+	for _, kvPair := range it.req.GetIndexParams() {
+		if kvPair.GetKey() == indexTypeKey {
+			return kvPair.GetValue()
+		}
+		if kvPair.GetKey() == paramsKeyToParse {
+			params, err := funcutil.ParseIndexParamsMap(kvPair.GetValue())
+			if err == nil && params[indexTypeKey] != "" {
+				return params[indexTypeKey]
+			}
+		}
+	}
+	return ""
+}
+
+// collectionID returns the collection of the binlogs to build index on, which is parsed from the data paths
+// in the form of ".../insert_log/{collectionID}/{partitionID}/{segmentID}/{fieldID}/{logID}", or 0 if unknown.
+func (it *IndexBuildTask) collectionID() UniqueID {
+	for _, dataPath := range it.req.GetDataPaths() {
+		elems := strings.Split(dataPath, "/")
+		for idx := 0; idx < len(elems)-1; idx++ {
+			if elems[idx] != insertLogPathSegment {
+				continue
+			}
+			if collectionID, err := strconv.ParseInt(elems[idx+1], 10, 64); err == nil {
+				return collectionID
+			}
+		}
+	}
+	return 0
+}
+
+// estimatedWork estimates the work of building the index by the row count of the segment multiplied by the
+// dimension, the row count is estimated by the number of binlogs, which are flushed in similar sizes, if the
+// coordinator does not know it.
+func (it *IndexBuildTask) estimatedWork() int64 {
+	rows := it.req.GetNumRows()
+	if rows <= 0 {
+		rows = int64(len(it.req.GetDataPaths()))
+	}
+	dim := int64(1)
+	if typeParams, indexParams, err := parseBuildParams(it.req); err == nil {
+		if value, err := strconv.ParseInt(dimOf(typeParams, indexParams), 10, 64); err == nil && value > 0 {
+			dim = value
+		}
+	}
+	return rows * dim
+}
+
+// parseBuildParams parses the type params and the index params of the request, the params encoded in the
+// value of the "params" key are expanded.
+func parseBuildParams(req *indexpb.CreateIndexRequest) (map[string]string, map[string]string, error) {
+	typeParams, err := parseKeyValueParams(req.GetTypeParams())
+	if err != nil {
+		return nil, nil, errorcode.Errorf(errorcode.InvalidParams, "%w in type params", err)
+	}
+	indexParams, err := parseKeyValueParams(req.GetIndexParams())
+	if err != nil {
+		return nil, nil, errorcode.Errorf(errorcode.InvalidParams, "%w in index params", err)
+	}
+	return typeParams, indexParams, nil
+}
+
+func parseKeyValueParams(pairs []*commonpb.KeyValuePair) (map[string]string, error) {
+	params := make(map[string]string)
+	for _, kvPair := range pairs {
+		key, value := kvPair.GetKey(), kvPair.GetValue()
+		_, ok := params[key]
+		if ok {
+			return nil, errors.New("duplicated key")
+		}
+		if key == paramsKeyToParse {
+			parsed, err := funcutil.ParseIndexParamsMap(value)
+			if err != nil {
+				return nil, err
+			}
+			for pk, pv := range parsed {
+				params[pk] = pv
+			}
+		} else {
+			params[key] = value
+		}
+	}
+	return params, nil
+}
+
 func (it *IndexBuildTask) OnEnqueue() error {
 	it.SetID(it.req.IndexBuildID)
-	log.Debug("IndexNode IndexBuilderTask Enqueue", zap.Int64("TaskID", it.ID()))
+	it.enqueueTime = time.Now()
+	it.logger(schedulerLog).Debug("IndexNode IndexBuilderTask Enqueue", zap.Int64("TaskID", it.ID()))
 	return nil
 }
 
-func (it *IndexBuildTask) checkIndexMeta(ctx context.Context, pre bool) error {
+func (it *IndexBuildTask) checkIndexMeta(ctx context.Context, pre bool) (err error) {
+	span := it.startStageSpan(ctx, spanMetaSave)
+	span.SetTag(tagPre, pre)
+	defer func() {
+		finishStageSpan(span, err)
+	}()
 	fn := func() error {
 		indexMeta := indexpb.IndexMeta{}
 		_, values, versions, err := it.etcdKV.LoadWithPrefix2(it.req.MetaPath)
 		if err != nil {
-			log.Error("IndexNode checkIndexMeta", zap.Any("load meta error with path", it.req.MetaPath),
+			it.logger(metaLog).Error("IndexNode checkIndexMeta", zap.Any("load meta error with path", it.req.MetaPath),
 				zap.Error(err), zap.Any("pre", pre))
-			return err
+			return etcdError(err)
 		}
 		if len(values) == 0 {
 			return fmt.Errorf("IndexNode checkIndexMeta the indexMeta is empty")
 		}
-		log.Debug("IndexNode checkIndexMeta load meta success", zap.Any("path", it.req.MetaPath), zap.Any("pre", pre))
+		it.logger(metaLog).Debug("IndexNode checkIndexMeta load meta success", zap.Any("path", it.req.MetaPath), zap.Any("pre", pre))
 		err = proto.Unmarshal([]byte(values[0]), &indexMeta)
 		if err != nil {
-			log.Error("IndexNode checkIndexMeta Unmarshal", zap.Error(err))
+			it.logger(metaLog).Error("IndexNode checkIndexMeta Unmarshal", zap.Error(err))
 			return err
 		}
-		log.Debug("IndexNode checkIndexMeta Unmarshal success", zap.Any("IndexMeta", indexMeta))
+		it.logger(metaLog).Debug("IndexNode checkIndexMeta Unmarshal success", zap.Any("IndexMeta", indexMeta))
 		if indexMeta.Version > it.req.Version || indexMeta.State == commonpb.IndexState_Finished {
-			log.Warn("IndexNode checkIndexMeta Notify build index this version is not the latest version", zap.Any("version", it.req.Version))
+			it.logger(metaLog).Warn("IndexNode checkIndexMeta Notify build index this version is not the latest version", zap.Any("version", it.req.Version))
 			return nil
 		}
 		if indexMeta.MarkDeleted {
@@ -148,281 +298,550 @@ func (it *IndexBuildTask) checkIndexMeta(ctx context.Context, pre bool) error {
 			}
 			err = it.etcdKV.CompareVersionAndSwap(it.req.MetaPath, versions[0], string(v))
 			if err != nil {
-				return err
+				return etcdError(err)
 			}
 			errMsg := fmt.Sprintf("the index has been deleted with indexBuildID %d", indexMeta.IndexBuildID)
-			log.Warn(errMsg)
-			return fmt.Errorf(errMsg)
+			it.logger(metaLog).Warn(errMsg)
+			return errorcode.New(errorcode.Cancelled, errMsg)
 		}
 		if pre {
 			return nil
 		}
 		indexMeta.IndexFilePaths = it.savePaths
+		indexMeta.FileManifest = it.fileManifest
 		indexMeta.State = commonpb.IndexState_Finished
+		indexMeta.ArtifactVersion = currentArtifactVersion()
+		indexMeta.ElementType = it.elementType
+		indexMeta.Normalized = it.normalized
+		indexMeta.ManifestPath = it.manifestPath
+		indexMeta.Encryption = it.encryption
+		indexMeta.BuildSeed = it.buildSeed
+		indexMeta.BaseIndexBuildID = it.base.GetIndexBuildID()
+		indexMeta.BaseVersion = it.base.GetVersion()
+		indexMeta.CheckpointFilePaths = nil
+		indexMeta.Skipped = it.skipped
 		if it.err != nil {
-			log.Error("IndexNode CreateIndex Failed", zap.Int64("IndexBuildID", indexMeta.IndexBuildID), zap.Any("err", err))
+			indexMeta.Skipped = false
+			indexMeta.ArtifactVersion = nil
+			indexMeta.ElementType = schemapb.DataType_None
+			indexMeta.Normalized = false
+			indexMeta.ManifestPath = ""
+			indexMeta.Encryption = nil
+			indexMeta.BuildSeed = nil
+			indexMeta.BaseIndexBuildID = 0
+			indexMeta.BaseVersion = 0
+			indexMeta.CheckpointFilePaths = it.checkpointFiles
+			it.logger(metaLog).Error("IndexNode CreateIndex Failed", zap.Int64("IndexBuildID", indexMeta.IndexBuildID), zap.Any("err", err))
 			indexMeta.State = commonpb.IndexState_Failed
-			indexMeta.FailReason = it.err.Error()
+			if isRetryableOnOtherNode(it.err) {
+				// leave the task to IndexCoord to assign it again
+				indexMeta.State = commonpb.IndexState_Unissued
+			}
+			indexMeta.FailReason = failureReason(it.err)
 		}
-		log.Debug("IndexNode", zap.Int64("indexBuildID", indexMeta.IndexBuildID), zap.Any("IndexState", indexMeta.State))
+		it.logger(metaLog).Debug("IndexNode", zap.Int64("indexBuildID", indexMeta.IndexBuildID), zap.Any("IndexState", indexMeta.State))
 		var metaValue []byte
 		metaValue, err = proto.Marshal(&indexMeta)
 		if err != nil {
-			log.Debug("IndexNode", zap.Int64("indexBuildID", indexMeta.IndexBuildID), zap.Any("IndexState", indexMeta.State),
+			it.logger(metaLog).Debug("IndexNode", zap.Int64("indexBuildID", indexMeta.IndexBuildID), zap.Any("IndexState", indexMeta.State),
 				zap.Any("proto.Marshal failed:", err))
 			return err
 		}
 		err = it.etcdKV.CompareVersionAndSwap(it.req.MetaPath, versions[0],
 			string(metaValue))
-		log.Debug("IndexNode checkIndexMeta CompareVersionAndSwap", zap.Error(err))
-		return err
+		it.logger(metaLog).Debug("IndexNode checkIndexMeta CompareVersionAndSwap", zap.Error(err))
+		return etcdError(err)
 	}
 
-	err := retry.Do(ctx, fn, retry.Attempts(3))
-	log.Debug("IndexNode checkIndexMeta final", zap.Error(err))
+	stage := taskStagePreExecute
+	if !pre {
+		stage = taskStagePostExecute
+	}
+	err = retry.Do(ctx, it.recordRetries(stage, fn), retry.Attempts(3))
+	it.logger(metaLog).Debug("IndexNode checkIndexMeta final", zap.Error(err))
 	return err
 
 }
 
 func (it *IndexBuildTask) PreExecute(ctx context.Context) error {
-	log.Debug("IndexNode IndexBuildTask preExecute...")
+	it.logger(log.L()).Debug("IndexNode IndexBuildTask preExecute...")
+	it.startTime = time.Now()
 	sp, ctx := trace.StartSpanFromContextWithOperationName(ctx, "CreateIndex-PreExecute")
 	defer sp.Finish()
+	it.progress = newTaskProgress(it.req.IndexBuildID, it.req.Version)
+	it.tracker.add(it.progress)
+	it.recordEvent(TaskEventAdmitted)
 	return it.checkIndexMeta(ctx, true)
 }
 
 func (it *IndexBuildTask) PostExecute(ctx context.Context) error {
-	log.Debug("IndexNode IndexBuildTask PostExecute...")
+	it.logger(log.L()).Debug("IndexNode IndexBuildTask PostExecute...")
 	sp, _ := trace.StartSpanFromContextWithOperationName(ctx, "CreateIndex-PostExecute")
 	defer sp.Finish()
 
-	return it.checkIndexMeta(ctx, false)
+	it.setStage(taskStagePostExecute)
+	defer it.tracker.remove(it.progress)
+	err := it.checkIndexMeta(ctx, false)
+	buildErr := it.err
+	if buildErr == nil {
+		buildErr = err
+	}
+	it.stats.recordTask(it.indexType(), it.simdType, time.Since(it.startTime), buildErr)
+	it.recordStageDurations(buildErr)
+	it.recordResult(buildErr)
+	it.finalErr = buildErr
+	return err
+}
+
+// persistFailure cleans up the index files saved by the build which fails to save some of its index files, or retains
+// them as checkpoints when resumable builds are enabled, and returns the error reporting the result of each file.
+func (it *IndexBuildTask) persistFailure(report *persistReport) error {
+	report.cleanupSaved(it.kv, Params.ResumableBuild)
+	if Params.ResumableBuild {
+		it.checkpointFiles = report.succeededFiles()
+	}
+	if report.cleanupErr == nil {
+		// the saved files are removed or retained, the cleaner retries the ones failed to remove
+		for _, file := range report.succeededFiles() {
+			it.cleaner.forget(resourceObject, file)
+		}
+	}
+	it.logger(storageLog).Error("IndexNode failed to save the index files",
+		append([]zap.Field{zap.Int64("indexBuildID", it.req.IndexBuildID), zap.Int64("version", it.req.Version)},
+			report.logFields()...)...)
+	return report.err()
+}
+
+// diskBuildError returns the error of building a disk index, the disk space failure found during the build
+// is preferred since it is usually the cause of the build error.
+func (it *IndexBuildTask) diskBuildError(diskDir *taskDiskDir, err error) error {
+	if diskDir == nil {
+		return err
+	}
+	if spaceErr := diskDir.spaceErr(); spaceErr != nil {
+		return spaceErr
+	}
+	return classifyDiskError(err)
+}
+
+// skipEmptySegment finishes the task of the segment without rows without any index file, which is marked skipped in
+// the index meta, instead of failing it, since there is nothing to index.
+func (it *IndexBuildTask) skipEmptySegment() error {
+	it.logger(engineLog).Info("IndexNode IndexBuildTask Execute skips the segment without rows")
+	it.skipped = true
+	it.savePaths = nil
+	return nil
 }
 
 func (it *IndexBuildTask) Execute(ctx context.Context) error {
-	log.Debug("IndexNode IndexBuildTask Execute ...")
-	sp, _ := trace.StartSpanFromContextWithOperationName(ctx, "CreateIndex-Execute")
+	it.cleaner = newTaskCleaner(it.req.IndexBuildID, it.req.Version, it.kv)
+	sampler := it.startMemorySampler()
+	err := it.cleaner.guard(func() error {
+		return it.execute(ctx)
+	})
+	if err != nil {
+		if sampler != nil {
+			sampler.stop()
+		}
+		return err
+	}
+	it.recordPeakMemory(sampler)
+	return nil
+}
+
+func (it *IndexBuildTask) execute(ctx context.Context) error {
+	it.logger(log.L()).Debug("IndexNode IndexBuildTask Execute ...")
+	sp, ctx := trace.StartSpanFromContextWithOperationName(ctx, "CreateIndex-Execute")
 	defer sp.Finish()
 	tr := timerecord.NewTimeRecorder(fmt.Sprintf("IndexBuildTask %d", it.req.IndexBuildID))
-	var err error
 
-	typeParams := make(map[string]string)
-	for _, kvPair := range it.req.GetTypeParams() {
-		key, value := kvPair.GetKey(), kvPair.GetValue()
-		_, ok := typeParams[key]
-		if ok {
-			return errors.New("duplicated key in type params")
-		}
-		if key == paramsKeyToParse {
-			params, err := funcutil.ParseIndexParamsMap(value)
-			if err != nil {
-				return err
-			}
-			for pk, pv := range params {
-				typeParams[pk] = pv
-			}
-		} else {
-			typeParams[key] = value
-		}
+	typeParams, indexParams, err := parseBuildParams(it.req)
+	if err != nil {
+		return err
 	}
 
-	indexParams := make(map[string]string)
-	for _, kvPair := range it.req.GetIndexParams() {
-		key, value := kvPair.GetKey(), kvPair.GetValue()
-		_, ok := indexParams[key]
-		if ok {
-			return errors.New("duplicated key in index params")
+	var diskDir *taskDiskDir
+	// the configured engine parameters are only known by the engine, they are not saved along with the index params
+	engineIndexParams := engineConfigOf(indexParams[indexTypeKey], indexParams)
+	// the vectors are normalized by IndexNode, which is saved along with the index params but unknown by the engine
+	delete(engineIndexParams, normalizeKey)
+	it.buildSeed, err = buildSeedOf(indexParams)
+	if err != nil {
+		return err
+	}
+	applyBuildSeed(engineIndexParams, it.buildSeed)
+	if it.buildSeed != nil && !it.buildSeed.Honored {
+		it.logger(engineLog).Warn("IndexNode IndexBuildTask Execute the build seed is ignored, the index files are not reproducible",
+			zap.String("indexType", indexParams[indexTypeKey]), zap.Int64("seed", it.buildSeed.Seed),
+			zap.String("reason", buildSeedIgnoredReason(indexParams[indexTypeKey])))
+	}
+	if isSerialSeededBuild(indexParams[indexTypeKey], it.buildSeed) {
+		it.logger(engineLog).Warn("IndexNode IndexBuildTask Execute the seeded build runs single-threaded",
+			zap.String("indexType", indexParams[indexTypeKey]), zap.Int64("seed", it.buildSeed.Seed))
+	}
+	it.recordEngineConfig(engineIndexParams)
+	manifestEngineParams := make(map[string]string, len(engineIndexParams))
+	for key, value := range engineIndexParams {
+		manifestEngineParams[key] = value
+	}
+	manifest := newIndexManifest(it.req, typeParams, indexParams, manifestEngineParams)
+	if it.buildSeed != nil {
+		manifest.BuildSeed = &manifestBuildSeed{Seed: it.buildSeed.Seed, Honored: it.buildSeed.Honored}
+	}
+	if isDiskIndexType(indexParams[indexTypeKey]) {
+		diskDir, err = newTaskDiskDir(it.req.IndexBuildID, it.req.Version, Params.TaskDiskQuota)
+		if err != nil {
+			it.logger(engineLog).Error("IndexNode IndexBuildTask Execute failed to create the directory of index files", zap.Error(err))
+			return err
 		}
-		if key == paramsKeyToParse {
-			params, err := funcutil.ParseIndexParamsMap(value)
-			if err != nil {
-				return err
-			}
-			for pk, pv := range params {
-				indexParams[pk] = pv
-			}
-		} else {
-			indexParams[key] = value
+		it.cleaner.register(resourceLocalPath, diskDir.path, false)
+		// the local directory is neither saved nor recorded as the engine config
+		engineIndexParams[indexFilesDirKey] = diskDir.path
+	}
+
+	if it.simd != nil {
+		it.configuredSimdType = Params.simdTypeOf(indexParams[indexTypeKey])
+		if it.configuredSimdType == "" {
+			it.configuredSimdType = it.simd.defaultType
 		}
+		it.simdType = it.simd.acquire(it.configuredSimdType)
+		defer it.simd.release()
+		it.logger(engineLog).Debug("IndexNode IndexBuildTask Execute", zap.Int64("IndexBuildID", it.req.IndexBuildID),
+			zap.String("configured_simd_type", it.configuredSimdType), zap.String("simd_type", it.simdType))
 	}
 
-	it.index, err = NewCIndex(typeParams, indexParams)
+	it.index, err = NewCIndex(typeParams, engineIndexParams)
 	if err != nil {
-		log.Error("IndexNode IndexBuildTask Execute NewCIndex failed", zap.Error(err))
+		it.logger(engineLog).Error("IndexNode IndexBuildTask Execute NewCIndex failed", zap.Error(err))
 		return err
 	}
 	defer func() {
 		err = it.index.Delete()
 		if err != nil {
-			log.Warn("IndexNode IndexBuildTask Execute CIndexDelete Failed", zap.Error(err))
+			it.logger(engineLog).Warn("IndexNode IndexBuildTask Execute CIndexDelete Failed", zap.Error(err))
 		}
 	}()
 
-	getKeyByPathNaive := func(path string) string {
-		// splitElements := strings.Split(path, "/")
-		// return splitElements[len(splitElements)-1]
-		return path
-	}
-	getValueByPath := func(path string) ([]byte, error) {
-		data, err := it.kv.Load(path)
-		if err != nil {
-			return nil, err
+	if it.req.GetBaseIndexBuildID() != 0 {
+		// the vectors are appended to the index of the base build, which is loaded before the binlogs
+		if err = checkAppendBuild(it.req); err != nil {
+			return err
 		}
-		return []byte(data), nil
-	}
-	getBlobByPath := func(path string) (*Blob, error) {
-		value, err := getValueByPath(path)
-		if err != nil {
-			return nil, err
+		if it.base, err = it.loadAppendBase(typeParams, indexParams); err != nil {
+			return err
 		}
-		return &Blob{
-			Key:   getKeyByPathNaive(path),
-			Value: value,
-		}, nil
+		if err = it.loadBaseIndex(it.base); err != nil {
+			it.logger(engineLog).Error("IndexNode IndexBuildTask Execute failed to load the index of the base build",
+				zap.Int64("baseIndexBuildID", it.base.IndexBuildID), zap.Error(err))
+			return err
+		}
+		manifest.BaseIndexBuildID, manifest.BaseVersion = it.base.IndexBuildID, it.base.Version
+		tr.Record("load base index done")
 	}
+
 	getStorageBlobs := func(blobs []*Blob) []*storage.Blob {
 		return blobs
 	}
 
-	toLoadDataPaths := it.req.GetDataPaths()
-	keys := make([]string, len(toLoadDataPaths))
-	blobs := make([]*Blob, len(toLoadDataPaths))
-
-	loadKey := func(idx int) error {
-		keys[idx] = getKeyByPathNaive(toLoadDataPaths[idx])
-		blob, err := getBlobByPath(toLoadDataPaths[idx])
+	var collectionID, partitionID, segmentID, fieldID UniqueID
+	if index, ok := it.incrementalIndex(indexParams[indexTypeKey]); ok {
+		// the binlogs are downloaded and decoded while the index is being built
+		span := it.startStageSpan(ctx, spanBuild)
+		span.SetTag(tagPipelined, true)
+		pipeline, err := it.buildPipelined(ctx, index)
+		if err == nil {
+			span.SetTag(tagFiles, len(pipeline.paths))
+			span.SetTag(tagBytes, pipeline.loadedBytes)
+			span.SetTag(tagRows, pipeline.feeder.addedRows)
+		}
+		finishStageSpan(span, err)
+		if errors.Is(err, errNoVectors) {
+			return it.skipEmptySegment()
+		}
+		if err != nil {
+			return err
+		}
+		collectionID, partitionID, segmentID, fieldID = pipeline.collectionID, pipeline.partitionID, pipeline.segmentID, pipeline.fieldID
+		tr.Record("pipelined build done")
+	} else {
+		collectionID, partitionID, segmentID, fieldID, err = it.loadAndBuild(ctx, diskDir, tr)
+		if errors.Is(err, errNoVectors) {
+			return it.skipEmptySegment()
+		}
 		if err != nil {
 			return err
 		}
+	}
 
-		blobs[idx] = blob
+	it.recordEngineStatistics()
 
-		return nil
+	it.setStage(taskStageSerialize)
+	serializeSpan := it.startStageSpan(ctx, spanSerialize)
+	indexBlobs, releaseIndexBlobs, err := serializeIndex(it.index)
+	if err != nil {
+		finishStageSpan(serializeSpan, err)
+		it.logger(engineLog).Error("IndexNode index Serialize failed", zap.Error(err))
+		return err
 	}
-	err = funcutil.ProcessFuncParallel(len(toLoadDataPaths), runtime.NumCPU(), loadKey, "loadKey")
+	defer releaseIndexBlobs()
+	tr.Record("serialize index done")
+
+	// the index files are streamed from the blobs of the engine while they are being uploaded, instead of being
+	// copied into the binlogs first
+	codec := storage.NewIndexFileBinlogCodec()
+	serializedIndexBlobs, err := codec.SerializeToStreams(
+		it.req.IndexBuildID,
+		it.req.Version,
+		collectionID,
+		partitionID,
+		segmentID,
+		fieldID,
+		indexParams,
+		it.req.IndexName,
+		it.req.IndexID,
+		getStorageBlobs(indexBlobs),
+	)
 	if err != nil {
+		finishStageSpan(serializeSpan, err)
 		return err
 	}
-	log.Debug("IndexNode load data success")
-	tr.Record("loadKey done")
+	var encryptionKey []byte
+	if Params.EncryptionKeyring != nil {
+		dataKey, err := Params.EncryptionKeyring.NewDataKey()
+		if err != nil {
+			finishStageSpan(serializeSpan, err)
+			return err
+		}
+		encryptionKey = dataKey.Key
+		it.encryption = indexEncryptionOf(dataKey)
+		manifest.Encryption = manifestEncryptionOf(dataKey)
+	}
+	var serializedBytes int64
+	for _, blob := range serializedIndexBlobs {
+		serializedBytes += streamObjectSize(blob.Size, encryptionKey)
+	}
+	serializeSpan.SetTag(tagFiles, len(serializedIndexBlobs))
+	serializeSpan.SetTag(tagBytes, serializedBytes)
+	finishStageSpan(serializeSpan, nil)
+	tr.Record("serialize index codec done")
 
-	storageBlobs := getStorageBlobs(blobs)
-	var insertCodec storage.InsertCodec
-	defer insertCodec.Close()
-	collectionID, partitionID, segmentID, insertData, err2 := insertCodec.DeserializeAll(storageBlobs)
-	if err2 != nil {
-		return err2
+	getSavePathByKey := func(key string) string {
+
+		return path.Join(Params.IndexRootPath, strconv.Itoa(int(it.req.IndexBuildID)), strconv.Itoa(int(it.req.Version)),
+			strconv.Itoa(int(partitionID)), strconv.Itoa(int(segmentID)), key)
 	}
-	if len(insertData.Data) != 1 {
-		return errors.New("we expect only one field in deserialized insert data")
+	objectMetadata := artifactObjectMetadata(currentArtifactVersion())
+
+	it.savePaths = make([]string, len(serializedIndexBlobs))
+	for idx, blob := range serializedIndexBlobs {
+		it.savePaths[idx] = getSavePathByKey(blob.Key)
 	}
-	tr.Record("deserialize storage blobs done")
+	checksums := make([]string, len(serializedIndexBlobs))
+	saveIndexFile := func(idx int) error {
+		blob := serializedIndexBlobs[idx]
+		savePath := it.savePaths[idx]
 
-	for fieldID, value := range insertData.Data {
-		// TODO: BinaryVectorFieldData
-		floatVectorFieldData, fOk := value.(*storage.FloatVectorFieldData)
-		if fOk {
-			err = it.index.BuildFloatVecIndexWithoutIds(floatVectorFieldData.Data)
+		saveIndexFileFn := func() error {
+			v, err := it.etcdKV.Load(it.req.MetaPath)
 			if err != nil {
-				log.Error("IndexNode BuildFloatVecIndexWithoutIds failed", zap.Error(err))
-				return err
+				it.logger(metaLog).Error("IndexNode load meta failed", zap.Any("path", it.req.MetaPath), zap.Error(err))
+				return etcdError(err)
 			}
-			tr.Record("build float vector index done")
-		}
-
-		binaryVectorFieldData, bOk := value.(*storage.BinaryVectorFieldData)
-		if bOk {
-			err = it.index.BuildBinaryVecIndexWithoutIds(binaryVectorFieldData.Data)
+			indexMeta := indexpb.IndexMeta{}
+			err = proto.Unmarshal([]byte(v), &indexMeta)
 			if err != nil {
-				log.Error("IndexNode BuildBinaryVecIndexWithoutIds failed", zap.Error(err))
+				it.logger(metaLog).Error("IndexNode Unmarshal indexMeta error ", zap.Error(err))
 				return err
 			}
-			tr.Record("build binary vector index done")
+			//metaLog.Debug("IndexNode Unmarshal indexMeta success ", zap.Any("meta", indexMeta))
+			if indexMeta.Version > it.req.Version {
+				it.logger(metaLog).Warn("IndexNode try saveIndexFile failed req.Version is low", zap.Any("req.Version", it.req.Version),
+					zap.Any("indexMeta.Version", indexMeta.Version))
+				return errorcode.New(errorcode.Cancelled, "This task has been reassigned ")
+			}
+			checksum, err := putStream(it.kv, savePath, blob, objectMetadata, encryptionKey)
+			if err != nil {
+				return storageError(err)
+			}
+			checksums[idx] = checksum
+			return nil
 		}
-
-		if !fOk && !bOk {
-			return errors.New("we expect FloatVectorFieldData or BinaryVectorFieldData")
+		err := retry.Do(ctx, it.recordRetries(taskStageSave, saveIndexFileFn), retry.Attempts(5))
+		it.logger(storageLog).Debug("IndexNode try saveIndexFile final", zap.Error(err), zap.Any("savePath", savePath))
+		if err == nil {
+			it.cleaner.register(resourceObject, savePath, true)
+			it.progress.advance()
+		}
+		return err
+	}
+	it.setStage(taskStageSave)
+	saveStart := time.Now()
+	uploadSpan := it.startStageSpan(ctx, spanUpload)
+	report := newPersistReport()
+	report.persistFiles(it.savePaths, saveIndexFile, "saveIndexFile")
+	if report.hasFailure() {
+		err = it.persistFailure(report)
+		finishStageSpan(uploadSpan, err)
+		return err
+	}
+	savedBytes := serializedBytes
+	savedFiles := len(it.savePaths)
+	if diskDir != nil {
+		it.fileManifest, err = diskDir.upload(ctx, it.kv, getSavePathByKey, objectMetadata, encryptionKey, report, it.cleaner)
+		if report.hasFailure() {
+			err = it.persistFailure(report)
+			finishStageSpan(uploadSpan, err)
+			return err
 		}
-
-		indexBlobs, err := it.index.Serialize()
 		if err != nil {
-			log.Error("IndexNode index Serialize failed", zap.Error(err))
+			finishStageSpan(uploadSpan, err)
+			it.logger(storageLog).Error("IndexNode upload index files failed", zap.Error(err))
 			return err
 		}
-		tr.Record("serialize index done")
-
-		codec := storage.NewIndexFileBinlogCodec()
-		serializedIndexBlobs, err := codec.Serialize(
-			it.req.IndexBuildID,
-			it.req.Version,
-			collectionID,
-			partitionID,
-			segmentID,
-			fieldID,
-			indexParams,
-			it.req.IndexName,
-			it.req.IndexID,
-			getStorageBlobs(indexBlobs),
-		)
+		for _, file := range it.fileManifest {
+			savedBytes += file.FileSize
+		}
+		savedFiles += len(it.fileManifest)
+	}
+	releaseIndexBlobs()
+	for idx, blob := range serializedIndexBlobs {
+		manifest.addFile(blob.Key, streamObjectSize(blob.Size, encryptionKey), checksums[idx])
+	}
+	manifestPath := getSavePathByKey(manifestFileName)
+	for _, file := range it.fileManifest {
+		manifest.addFile(strings.TrimPrefix(file.FilePath, path.Dir(manifestPath)+"/"), file.FileSize, file.Checksum)
+	}
+	if err := it.saveManifest(ctx, manifestPath, manifest, objectMetadata); err != nil {
+		finishStageSpan(uploadSpan, err)
+		it.logger(storageLog).Error("IndexNode verify the index files against the manifest failed", zap.Error(err))
+		return err
+	}
+	uploadSpan.SetTag(tagFiles, savedFiles)
+	uploadSpan.SetTag(tagBytes, savedBytes)
+	finishStageSpan(uploadSpan, nil)
+	it.stats.recordSave(savedBytes, time.Since(saveStart))
+	tr.Record("save index file done")
+	it.logger(log.L()).Debug("IndexNode CreateIndex finished")
+	tr.Elapse("all done")
+	return nil
+}
+
+// loadAndBuild loads all the binlogs and builds the index after all the data is decoded.
+func (it *IndexBuildTask) loadAndBuild(ctx context.Context, diskDir *taskDiskDir, tr *timerecord.TimeRecorder) (
+	collectionID, partitionID, segmentID, fieldID UniqueID, err error) {
+	reader, err := it.segmentReader()
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	it.setStage(taskStageLoad)
+	toLoadDataPaths := reader.sortPaths(it.req.GetDataPaths())
+	files := make([]*segmentFile, len(toLoadDataPaths))
+
+	loadKey := func(idx int) error {
+		start := time.Now()
+		file, err := reader.load(toLoadDataPaths[idx])
 		if err != nil {
 			return err
 		}
-		_ = codec.Close()
-		tr.Record("serialize index codec done")
-
-		getSavePathByKey := func(key string) string {
-
-			return path.Join(Params.IndexRootPath, strconv.Itoa(int(it.req.IndexBuildID)), strconv.Itoa(int(it.req.Version)),
-				strconv.Itoa(int(partitionID)), strconv.Itoa(int(segmentID)), key)
-		}
-		saveBlob := func(path string, value []byte) error {
-			return it.kv.Save(path, string(value))
-		}
-
-		it.savePaths = make([]string, len(serializedIndexBlobs))
-		saveIndexFile := func(idx int) error {
-			blob := serializedIndexBlobs[idx]
-			key, value := blob.Key, blob.Value
-
-			savePath := getSavePathByKey(key)
-
-			saveIndexFileFn := func() error {
-				v, err := it.etcdKV.Load(it.req.MetaPath)
-				if err != nil {
-					log.Error("IndexNode load meta failed", zap.Any("path", it.req.MetaPath), zap.Error(err))
-					return err
-				}
-				indexMeta := indexpb.IndexMeta{}
-				err = proto.Unmarshal([]byte(v), &indexMeta)
-				if err != nil {
-					log.Error("IndexNode Unmarshal indexMeta error ", zap.Error(err))
-					return err
-				}
-				//log.Debug("IndexNode Unmarshal indexMeta success ", zap.Any("meta", indexMeta))
-				if indexMeta.Version > it.req.Version {
-					log.Warn("IndexNode try saveIndexFile failed req.Version is low", zap.Any("req.Version", it.req.Version),
-						zap.Any("indexMeta.Version", indexMeta.Version))
-					return errors.New("This task has been reassigned ")
-				}
-				return saveBlob(savePath, value)
-			}
-			err := retry.Do(ctx, saveIndexFileFn, retry.Attempts(5))
-			log.Debug("IndexNode try saveIndexFile final", zap.Error(err), zap.Any("savePath", savePath))
-			if err != nil {
-				return err
-			}
+		sampledLogger(it.ctx, storageSampledLog).Debug(logSiteDownload, "IndexNode downloaded the binlog",
+			zap.Int64("indexBuildID", it.req.IndexBuildID), zap.String("path", toLoadDataPaths[idx]),
+			zap.Int64("size", file.size), zap.Duration("duration", time.Since(start)))
 
-			it.savePaths[idx] = savePath
+		files[idx] = file
+		it.progress.advance()
 
-			return nil
+		return nil
+	}
+	loadStart := time.Now()
+	downloadSpan := it.startStageSpan(ctx, spanDownload)
+	err = funcutil.ProcessFuncParallel(len(toLoadDataPaths), runtime.NumCPU(), loadKey, "loadKey")
+	if err != nil {
+		finishStageSpan(downloadSpan, err)
+		return 0, 0, 0, 0, err
+	}
+	var loadedBytes int64
+	for _, file := range files {
+		loadedBytes += file.size
+	}
+	downloadSpan.SetTag(tagFiles, len(files))
+	downloadSpan.SetTag(tagBytes, loadedBytes)
+	finishStageSpan(downloadSpan, nil)
+	it.loadedBytes = loadedBytes
+	it.stats.recordLoad(loadedBytes, time.Since(loadStart))
+	it.logger(storageLog).Debug("IndexNode load data success")
+	tr.Record("loadKey done")
+
+	decodeSpan := it.startStageSpan(ctx, spanDecode)
+	decoded, err := reader.decodeAll(files)
+	if err != nil {
+		finishStageSpan(decodeSpan, err)
+		return 0, 0, 0, 0, err
+	}
+	collectionID, partitionID, segmentID = decoded.collectionID, decoded.partitionID, decoded.segmentID
+	decodeSpan.SetTag(tagRows, vectorRows(decoded.data))
+	finishStageSpan(decodeSpan, nil)
+	tr.Record("deserialize storage blobs done")
+
+	buildSpan := it.startStageSpan(ctx, spanBuild)
+	buildSpan.SetTag(tagBytes, loadedBytes)
+	defer func() {
+		finishStageSpan(buildSpan, err)
+	}()
+	fieldID, value := decoded.fieldID, decoded.data
+	it.setStage(taskStageBuild)
+	var rows int
+	if rows, err = it.checkVectors(value); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	if rows == 0 {
+		// the engine is never called on no vectors, the segment without rows is skipped by the task instead
+		return 0, 0, 0, 0, errNoVectors
+	}
+	value = engineVectors(value)
+	stopWatch := func() {}
+	if diskDir != nil {
+		stopWatch = diskDir.watch(ctx)
+	}
+	floatVectorFieldData, fOk := value.(*storage.FloatVectorFieldData)
+	if fOk {
+		if it.normalized {
+			// the decoded vectors are owned by the task, which are normalized in place
+			normalizeVectors(floatVectorFieldData.Data, floatVectorFieldData.Dim)
+		}
+		if it.base != nil {
+			err = it.appendFloatVectors(floatVectorFieldData.Data)
+		} else {
+			err = it.index.BuildFloatVecIndexWithoutIds(floatVectorFieldData.Data)
 		}
-		err = funcutil.ProcessFuncParallel(len(serializedIndexBlobs), runtime.NumCPU(), saveIndexFile, "saveIndexFile")
 		if err != nil {
-			return err
+			stopWatch()
+			it.logger(engineLog).Error("IndexNode BuildFloatVecIndexWithoutIds failed", zap.Error(err))
+			return 0, 0, 0, 0, it.diskBuildError(diskDir, err)
 		}
-		tr.Record("save index file done")
+		tr.Record("build float vector index done")
 	}
-	log.Debug("IndexNode CreateIndex finished")
-	tr.Elapse("all done")
-	return nil
+
+	binaryVectorFieldData, bOk := value.(*storage.BinaryVectorFieldData)
+	if bOk {
+		err = it.index.BuildBinaryVecIndexWithoutIds(binaryVectorFieldData.Data)
+		if err != nil {
+			stopWatch()
+			it.logger(engineLog).Error("IndexNode BuildBinaryVecIndexWithoutIds failed", zap.Error(err))
+			return 0, 0, 0, 0, it.diskBuildError(diskDir, err)
+		}
+		tr.Record("build binary vector index done")
+	}
+	stopWatch()
+
+	if !fOk && !bOk {
+		return 0, 0, 0, 0, errorcode.New(errorcode.InvalidParams, "we expect FloatVectorFieldData or BinaryVectorFieldData")
+	}
+	if diskDir != nil {
+		if err = it.diskBuildError(diskDir, diskDir.checkSpace()); err != nil {
+			it.logger(engineLog).Error("IndexNode disk index build failed", zap.Error(err))
+			return 0, 0, 0, 0, err
+		}
+	}
+	return collectionID, partitionID, segmentID, fieldID, nil
 }
@@ -0,0 +1,384 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+	lumberjack "gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+// TaskEventPrefix is the prefix of the etcd keys of the task events, the events of a task are under
+// TaskEventPrefix/{indexBuildID}/ under the meta root path.
+const TaskEventPrefix = "indexnode-task-events"
+
+// the sinks of the task events
+const (
+	taskEventSinkNone = "none"
+	taskEventSinkFile = "file"
+	taskEventSinkEtcd = "etcd"
+)
+
+// the types of the lifecycle events of the index build tasks
+const (
+	TaskEventReceived  = "received"
+	TaskEventRejected  = "rejected"
+	TaskEventQueued    = "queued"
+	TaskEventAdmitted  = "admitted"
+	TaskEventStage     = "stage"
+	TaskEventRetry     = "retry"
+	TaskEventCompleted = "completed"
+	TaskEventFailed    = "failed"
+	TaskEventCancelled = "cancelled"
+)
+
+// TaskEvent is a lifecycle event of an index build task, which is appended to the sink of indexNode.taskEvents.
+type TaskEvent struct {
+	IndexBuildID UniqueID  `json:"index_build_id"`
+	Version      int64     `json:"version"`
+	NodeID       UniqueID  `json:"node_id"`
+	Alias        string    `json:"alias,omitempty"`
+	Type         string    `json:"type"`
+	Time         time.Time `json:"time"`
+	// Stage is the stage the task moves to, or retries in
+	Stage string `json:"stage,omitempty"`
+	// Attempt is the attempt of the retry, starting from 2
+	Attempt int `json:"attempt,omitempty"`
+	// Reason is why the task is rejected, retried, failed or cancelled
+	Reason string `json:"reason,omitempty"`
+}
+
+// taskEventSink is the append-only storage of the task events.
+type taskEventSink interface {
+	append(event *TaskEvent) error
+	close() error
+}
+
+// fileEventSink appends the events in json lines to a local file, which is rotated when it exceeds the max size.
+type fileEventSink struct {
+	mu     sync.Mutex
+	writer *lumberjack.Logger
+}
+
+func newFileEventSink(filename string, maxSizeMB int, maxBackups int) (*fileEventSink, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &fileEventSink{
+		writer: &lumberjack.Logger{
+			Filename:   filename,
+			MaxSize:    maxSizeMB,
+			MaxBackups: maxBackups,
+			LocalTime:  true,
+		},
+	}, nil
+}
+
+func (s *fileEventSink) append(event *TaskEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.writer.Write(append(line, '\n'))
+	return err
+}
+
+func (s *fileEventSink) close() error {
+	return s.writer.Close()
+}
+
+// etcdEventSink saves the events under TaskEventPrefix with the leases of the retention, a lease is shared by the
+// events within half of the retention and lasts 1.5 times of the retention, so that an event is kept at least
+// retention and at most 1.5 times of it.
+type etcdEventSink struct {
+	kv        heartbeatKV
+	retention time.Duration
+
+	mu         sync.Mutex
+	seq        uint64
+	leaseID    clientv3.LeaseID
+	leaseStart time.Time
+}
+
+func newEtcdEventSink(kv heartbeatKV, retention time.Duration) *etcdEventSink {
+	return &etcdEventSink{kv: kv, retention: retention}
+}
+
+func (s *etcdEventSink) lease(now time.Time) (clientv3.LeaseID, error) {
+	if s.leaseID != 0 && now.Sub(s.leaseStart) < s.retention/2 {
+		return s.leaseID, nil
+	}
+	ttlSeconds := int64((s.retention + s.retention/2) / time.Second)
+	if ttlSeconds < 1 {
+		ttlSeconds = 1
+	}
+	leaseID, err := s.kv.Grant(ttlSeconds)
+	if err != nil {
+		return 0, err
+	}
+	s.leaseID, s.leaseStart = leaseID, now
+	return leaseID, nil
+}
+
+func (s *etcdEventSink) append(event *TaskEvent) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	leaseID, err := s.lease(event.Time)
+	if err != nil {
+		return err
+	}
+	s.seq++
+	// the keys of a task are in the order of the events, the node and the sequence tell the events of the same
+	// nanosecond apart
+	key := path.Join(TaskEventPrefix, strconv.FormatInt(event.IndexBuildID, 10),
+		fmt.Sprintf("%020d-%d-%d", event.Time.UnixNano(), event.NodeID, s.seq))
+	return s.kv.SaveWithLease(key, string(value), leaseID)
+}
+
+func (s *etcdEventSink) close() error {
+	return nil
+}
+
+// taskEventLog records the lifecycle events of the tasks of IndexNode, a nil log records nothing.
+type taskEventLog struct {
+	sink taskEventSink
+}
+
+// newTaskEventLog returns the log of the sink of indexNode.taskEvents.sink, or nil if the sink is none, the etcd
+// sink is unavailable without @etcdKV.
+func newTaskEventLog(etcdKV heartbeatKV) (*taskEventLog, error) {
+	switch Params.TaskEventSink {
+	case taskEventSinkFile:
+		sink, err := newFileEventSink(Params.TaskEventFilePath, Params.TaskEventFileMaxSize, Params.TaskEventFileMaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		return &taskEventLog{sink: sink}, nil
+	case taskEventSinkEtcd:
+		if etcdKV == nil {
+			return nil, fmt.Errorf("the etcd sink of the task events requires etcd")
+		}
+		return &taskEventLog{sink: newEtcdEventSink(etcdKV, Params.TaskEventRetention)}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// record appends the event of @eventType of the task with the node and the time, a failure to append is logged
+// and never fails the task.
+func (l *taskEventLog) record(indexBuildID UniqueID, version int64, eventType string, fill ...func(event *TaskEvent)) {
+	if l == nil {
+		return
+	}
+	event := &TaskEvent{
+		IndexBuildID: indexBuildID,
+		Version:      version,
+		NodeID:       Params.NodeID,
+		Alias:        Params.Alias,
+		Type:         eventType,
+		Time:         time.Now(),
+	}
+	for _, f := range fill {
+		f(event)
+	}
+	if err := l.sink.append(event); err != nil {
+		log.Warn("IndexNode failed to record the task event", zap.Int64("indexBuildID", indexBuildID),
+			zap.String("type", eventType), zap.Error(err))
+	}
+}
+
+func (l *taskEventLog) close() {
+	if l == nil {
+		return
+	}
+	if err := l.sink.close(); err != nil {
+		log.Warn("IndexNode failed to close the task events", zap.Error(err))
+	}
+}
+
+func withStage(stage string) func(event *TaskEvent) {
+	return func(event *TaskEvent) {
+		event.Stage = stage
+	}
+}
+
+func withReason(reason string) func(event *TaskEvent) {
+	return func(event *TaskEvent) {
+		event.Reason = reason
+	}
+}
+
+func withAttempt(attempt int) func(event *TaskEvent) {
+	return func(event *TaskEvent) {
+		event.Attempt = attempt
+	}
+}
+
+func withTime(t time.Time) func(event *TaskEvent) {
+	return func(event *TaskEvent) {
+		if !t.IsZero() {
+			event.Time = t
+		}
+	}
+}
+
+// recordEvent records the event of the task.
+func (it *IndexBuildTask) recordEvent(eventType string, fill ...func(event *TaskEvent)) {
+	if it.events == nil {
+		return
+	}
+	it.events.record(it.req.IndexBuildID, it.req.Version, eventType, fill...)
+}
+
+// setStage moves the task to the stage and records the transition.
+func (it *IndexBuildTask) setStage(stage string) {
+	it.progress.setStage(stage)
+	it.recordEvent(TaskEventStage, withStage(stage))
+}
+
+// recordRetry records the retry of @stage after @err if @attempt is not the first one.
+func (it *IndexBuildTask) recordRetry(stage string, attempt int, err error) {
+	if attempt <= 1 {
+		return
+	}
+	reason := ""
+	if err != nil {
+		reason = failureReason(err)
+	}
+	it.recordEvent(TaskEventRetry, withStage(stage), withAttempt(attempt), withReason(reason))
+}
+
+// recordRetries wraps @fn retried in @stage to record the retries of it.
+func (it *IndexBuildTask) recordRetries(stage string, fn func() error) func() error {
+	attempt := 0
+	var lastErr error
+	return func() error {
+		attempt++
+		it.recordRetry(stage, attempt, lastErr)
+		lastErr = fn()
+		return lastErr
+	}
+}
+
+// recordResult records the event of the result of the task.
+func (it *IndexBuildTask) recordResult(err error) {
+	switch {
+	case err == nil:
+		it.recordEvent(TaskEventCompleted)
+	case classifyError(err) == errorcode.Cancelled:
+		it.recordEvent(TaskEventCancelled, withReason(failureReason(err)))
+	default:
+		it.recordEvent(TaskEventFailed, withReason(failureReason(err)))
+	}
+}
+
+// sortTaskEvents sorts the events in the order of the time, the events of the same time keep their order.
+func sortTaskEvents(events []*TaskEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+}
+
+// ReadTaskEventsFromFile reconstructs the timeline of the task of @indexBuildID from the file sink at @filename,
+// including the rotated backups of it.
+func ReadTaskEventsFromFile(filename string, indexBuildID UniqueID) ([]*TaskEvent, error) {
+	ext := filepath.Ext(filename)
+	// lumberjack names the backups as name-timestamp.ext
+	backups, err := filepath.Glob(strings.TrimSuffix(filename, ext) + "-*" + ext)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*TaskEvent, 0)
+	for _, file := range append(backups, filename) {
+		fileEvents, err := readTaskEventFile(file, indexBuildID)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, fileEvents...)
+	}
+	sortTaskEvents(events)
+	return events, nil
+}
+
+func readTaskEventFile(filename string, indexBuildID UniqueID) ([]*TaskEvent, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	events := make([]*TaskEvent, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		event := &TaskEvent{}
+		if err := json.Unmarshal([]byte(line), event); err != nil {
+			// the last line may be partially written by a crash
+			log.Warn("IndexNode skips the malformed task event", zap.String("file", filename), zap.Error(err))
+			continue
+		}
+		if event.IndexBuildID == indexBuildID {
+			events = append(events, event)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// taskEventLoader loads the task events from etcd, which is implemented by etcdkv.EtcdKV.
+type taskEventLoader interface {
+	LoadWithPrefix(key string) ([]string, []string, error)
+}
+
+// ReadTaskEventsFromEtcd reconstructs the timeline of the task of @indexBuildID from the etcd sink, @kv is rooted
+// at the meta root path.
+func ReadTaskEventsFromEtcd(kv taskEventLoader, indexBuildID UniqueID) ([]*TaskEvent, error) {
+	_, values, err := kv.LoadWithPrefix(path.Join(TaskEventPrefix, strconv.FormatInt(indexBuildID, 10)) + "/")
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*TaskEvent, 0, len(values))
+	for _, value := range values {
+		event := &TaskEvent{}
+		if err := json.Unmarshal([]byte(value), event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	sortTaskEvents(events)
+	return events, nil
+}
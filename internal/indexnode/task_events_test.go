@@ -0,0 +1,195 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	etcdkv "github.com/milvus-io/milvus/internal/kv/etcd"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+func eventTypes(events []*TaskEvent) []string {
+	types := make([]string, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestTaskEventLog_File(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "events", "task-events.log")
+	sink, err := newFileEventSink(filename, 1, 2)
+	assert.Nil(t, err)
+	events := &taskEventLog{sink: sink}
+
+	start := time.Now()
+	events.record(1, 1, TaskEventReceived)
+	events.record(2, 1, TaskEventReceived)
+	events.record(1, 1, TaskEventQueued, withTime(start))
+	events.record(1, 1, TaskEventFailed, withReason("[STORAGE_TRANSIENT] timeout"))
+	events.close()
+
+	// a rotated backup and a partially written line
+	backup := filepath.Join(filepath.Dir(filename), "task-events-2021-01-01T00-00-00.000.log")
+	err = ioutil.WriteFile(backup, []byte(`{"index_build_id":1,"type":"stage","time":"2021-01-01T00:00:00Z","stage":"load"}`+"\n"+
+		`{"index_build_id":1,"type":`), 0644)
+	assert.Nil(t, err)
+
+	timeline, err := ReadTaskEventsFromFile(filename, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{TaskEventStage, TaskEventQueued, TaskEventReceived, TaskEventFailed}, eventTypes(timeline))
+	assert.Equal(t, taskStageLoad, timeline[0].Stage)
+	assert.Equal(t, Params.NodeID, timeline[1].NodeID)
+	assert.True(t, timeline[1].Time.Equal(start))
+	assert.Equal(t, "[STORAGE_TRANSIENT] timeout", timeline[3].Reason)
+
+	timeline, err = ReadTaskEventsFromFile(filename, 3)
+	assert.Nil(t, err)
+	assert.Empty(t, timeline)
+
+	// no events are recorded yet
+	timeline, err = ReadTaskEventsFromFile(filepath.Join(t.TempDir(), "task-events.log"), 1)
+	assert.Nil(t, err)
+	assert.Empty(t, timeline)
+}
+
+func TestTaskEventLog_Etcd(t *testing.T) {
+	kv := newMockHeartbeatKV()
+	sink := newEtcdEventSink(kv, 10*time.Minute)
+	now := time.Now()
+	assert.Nil(t, sink.append(&TaskEvent{IndexBuildID: 1, Type: TaskEventReceived, Time: now}))
+	assert.Nil(t, sink.append(&TaskEvent{IndexBuildID: 1, Type: TaskEventQueued, Time: now}))
+	// the lease is shared within half of the retention
+	assert.Equal(t, []int64{900}, kv.ttls)
+	assert.Nil(t, sink.append(&TaskEvent{IndexBuildID: 1, Type: TaskEventAdmitted, Time: now.Add(6 * time.Minute)}))
+	assert.Equal(t, []int64{900, 900}, kv.ttls)
+	assert.Equal(t, 3, len(kv.values))
+
+	kv.grantErr = errors.New("etcd is down")
+	assert.NotNil(t, sink.append(&TaskEvent{IndexBuildID: 1, Type: TaskEventStage, Time: now.Add(time.Hour)}))
+
+	e, endpoints := startEmbedEtcd(t)
+	defer e.Close()
+	client, err := etcdkv.NewEtcdKV(endpoints, "/task-events")
+	assert.Nil(t, err)
+	defer client.Close()
+
+	events := &taskEventLog{sink: newEtcdEventSink(client, time.Minute)}
+	events.record(1, 2, TaskEventReceived)
+	events.record(1, 2, TaskEventQueued)
+	events.record(2, 1, TaskEventReceived)
+	events.record(1, 2, TaskEventCompleted)
+	events.close()
+
+	timeline, err := ReadTaskEventsFromEtcd(client, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{TaskEventReceived, TaskEventQueued, TaskEventCompleted}, eventTypes(timeline))
+	assert.Equal(t, int64(2), timeline[0].Version)
+	timeline, err = ReadTaskEventsFromEtcd(client, 3)
+	assert.Nil(t, err)
+	assert.Empty(t, timeline)
+}
+
+// memoryEventSink keeps the events in memory.
+type memoryEventSink struct {
+	events []*TaskEvent
+}
+
+func (s *memoryEventSink) append(event *TaskEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *memoryEventSink) close() error {
+	return nil
+}
+
+func TestIndexBuildTask_events(t *testing.T) {
+	// the tasks without the log record nothing
+	it := &IndexBuildTask{}
+	it.setStage(taskStageLoad)
+	it.recordResult(nil)
+	var nilLog *taskEventLog
+	nilLog.record(1, 1, TaskEventReceived)
+	nilLog.close()
+
+	sink := &memoryEventSink{}
+	it = &IndexBuildTask{
+		req:    &indexpb.CreateIndexRequest{IndexBuildID: 1, Version: 2},
+		events: &taskEventLog{sink: sink},
+	}
+	it.setStage(taskStageLoad)
+	attempts := 0
+	fn := it.recordRetries(taskStageSave, func() error {
+		attempts++
+		if attempts < 3 {
+			return errorcode.New(errorcode.StorageTransient, "timeout")
+		}
+		return nil
+	})
+	for i := 0; i < 3; i++ {
+		_ = fn()
+	}
+	it.recordResult(errorcode.New(errorcode.Cancelled, "reassigned"))
+	it.recordResult(errors.New("failed"))
+	it.recordResult(nil)
+
+	assert.Equal(t, []string{TaskEventStage, TaskEventRetry, TaskEventRetry, TaskEventCancelled, TaskEventFailed,
+		TaskEventCompleted}, eventTypes(sink.events))
+	assert.Equal(t, taskStageLoad, sink.events[0].Stage)
+	assert.Equal(t, 2, sink.events[1].Attempt)
+	assert.Equal(t, 3, sink.events[2].Attempt)
+	assert.Equal(t, taskStageSave, sink.events[2].Stage)
+	assert.Equal(t, "[STORAGE_TRANSIENT] timeout", sink.events[1].Reason)
+	assert.Equal(t, "[CANCELLED] reassigned", sink.events[3].Reason)
+	for _, event := range sink.events {
+		assert.Equal(t, UniqueID(1), event.IndexBuildID)
+		assert.Equal(t, int64(2), event.Version)
+	}
+}
+
+func TestNewTaskEventLog(t *testing.T) {
+	oldSink, oldPath := Params.TaskEventSink, Params.TaskEventFilePath
+	defer func() {
+		Params.TaskEventSink, Params.TaskEventFilePath = oldSink, oldPath
+	}()
+
+	Params.TaskEventSink = taskEventSinkNone
+	events, err := newTaskEventLog(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, events)
+
+	Params.TaskEventSink = taskEventSinkEtcd
+	_, err = newTaskEventLog(nil)
+	assert.NotNil(t, err)
+	events, err = newTaskEventLog(newMockHeartbeatKV())
+	assert.Nil(t, err)
+	assert.NotNil(t, events)
+
+	Params.TaskEventSink = taskEventSinkFile
+	Params.TaskEventFilePath = filepath.Join(t.TempDir(), "task-events.log")
+	events, err = newTaskEventLog(nil)
+	assert.Nil(t, err)
+	events.record(1, 1, TaskEventReceived)
+	events.close()
+	_, err = os.Stat(Params.TaskEventFilePath)
+	assert.Nil(t, err)
+}
@@ -15,42 +15,214 @@ import (
 	"container/list"
 	"context"
 	"errors"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/milvus-io/milvus/internal/kv"
-	"github.com/milvus-io/milvus/internal/log"
 	"github.com/milvus-io/milvus/internal/util/trace"
 	"github.com/opentracing/opentracing-go"
 	oplog "github.com/opentracing/opentracing-go/log"
 )
 
+var errTaskQueueFull = errors.New("IndexNode task queue is full")
+
+// drainCheckInterval is the interval of checking whether the tasks are drained on stopping.
+const drainCheckInterval = 10 * time.Millisecond
+
 // TaskQueue is a queue used to store tasks.
 type TaskQueue interface {
 	utChan() <-chan int
+	lpChan() <-chan int
 	utEmpty() bool
 	utFull() bool
+	utLen() int
+	utCap() int
+	lpLen() int
+	atLen() int
 	addUnissuedTask(t task) error
+	signalLowPriority()
+	removeLowPriorityTask(t task) bool
 	//FrontUnissuedTask() task
 	PopUnissuedTask() task
 	AddActiveTask(t task)
 	PopActiveTask(tID UniqueID) task
 	Enqueue(t task) error
+	EnqueueLowPriority(t task) error
+	setSchedulePolicy(policy string, maxDefer time.Duration)
+	schedulePolicy() (string, time.Duration)
 	//tryToRemoveUselessIndexBuildTask(indexID UniqueID) []UniqueID
 }
 
+// fairTaskList keeps the unissued tasks of each collection in FIFO order, and pops them across collections
+// in weighted round-robin, so that the tasks of one collection can not monopolize the scheduler.
+type fairTaskList struct {
+	tasks map[UniqueID]*list.List
+	// collections is the round-robin order of the collections which have unissued tasks
+	collections *list.List
+	// popped is the number of tasks popped from the front collection in its current turn
+	popped  int64
+	weights map[UniqueID]int64
+	length  int
+}
+
+func newFairTaskList(weights map[UniqueID]int64) *fairTaskList {
+	return &fairTaskList{
+		tasks:       make(map[UniqueID]*list.List),
+		collections: list.New(),
+		weights:     weights,
+	}
+}
+
+// weight returns how many tasks of the collection can be popped in a turn, 1 if it is not configured.
+func (l *fairTaskList) weight(collectionID UniqueID) int64 {
+	if weight, ok := l.weights[collectionID]; ok && weight > 0 {
+		return weight
+	}
+	return 1
+}
+
+func (l *fairTaskList) Len() int {
+	return l.length
+}
+
+func (l *fairTaskList) PushBack(t task) {
+	collectionID := taskCollectionID(t)
+	tasks, ok := l.tasks[collectionID]
+	if !ok {
+		tasks = list.New()
+		l.tasks[collectionID] = tasks
+		l.collections.PushBack(collectionID)
+	}
+	tasks.PushBack(t)
+	l.length++
+}
+
+// PopFront pops the first task of the collection in turn, the turn moves to the next collection
+// once the collection runs out of its tasks or its weight.
+func (l *fairTaskList) PopFront() task {
+	front := l.collections.Front()
+	if front == nil {
+		return nil
+	}
+	collectionID := front.Value.(UniqueID)
+	tasks := l.tasks[collectionID]
+	t := tasks.Remove(tasks.Front()).(task)
+	l.length--
+	l.popped++
+
+	if tasks.Len() == 0 {
+		delete(l.tasks, collectionID)
+		l.collections.Remove(front)
+		l.popped = 0
+	} else if l.popped >= l.weight(collectionID) {
+		l.collections.MoveToBack(front)
+		l.popped = 0
+	}
+	return t
+}
+
+// priorityTaskList pops the tasks of the highest priority first, the tasks of the same priority are kept in a list
+// created by newLevel, which orders them within the priority.
+type priorityTaskList struct {
+	levels map[int64]taskList
+	// priorities are the priorities of the levels in descending order
+	priorities []int64
+	newLevel   func() taskList
+	length     int
+}
+
+func newPriorityTaskList(newLevel func() taskList) *priorityTaskList {
+	return &priorityTaskList{
+		levels:   make(map[int64]taskList),
+		newLevel: newLevel,
+	}
+}
+
+func (l *priorityTaskList) Len() int {
+	return l.length
+}
+
+func (l *priorityTaskList) PushBack(t task) {
+	priority := taskPriority(t)
+	level, ok := l.levels[priority]
+	if !ok {
+		level = l.newLevel()
+		l.levels[priority] = level
+		idx := sort.Search(len(l.priorities), func(i int) bool { return l.priorities[i] < priority })
+		l.priorities = append(l.priorities, 0)
+		copy(l.priorities[idx+1:], l.priorities[idx:])
+		l.priorities[idx] = priority
+	}
+	level.PushBack(t)
+	l.length++
+}
+
+func (l *priorityTaskList) PopFront() task {
+	if len(l.priorities) == 0 {
+		return nil
+	}
+	priority := l.priorities[0]
+	level := l.levels[priority]
+	t := level.PopFront()
+	l.length--
+	if level.Len() == 0 {
+		delete(l.levels, priority)
+		l.priorities = l.priorities[1:]
+	}
+	return t
+}
+
+// taskPriority returns the priority of the task, tasks without a known priority are of the priority 0.
+func taskPriority(t task) int64 {
+	if it, ok := t.(*IndexBuildTask); ok {
+		return it.req.GetPriority()
+	}
+	return 0
+}
+
+// taskLowPriority returns whether the task is of the low-priority class, which is only scheduled when no other
+// task is waiting.
+func taskLowPriority(t task) bool {
+	if it, ok := t.(*IndexBuildTask); ok {
+		return it.lowPriority
+	}
+	return false
+}
+
+// taskCollectionID returns the collection of the task, tasks without a known collection share the collection 0.
+func taskCollectionID(t task) UniqueID {
+	if it, ok := t.(*IndexBuildTask); ok {
+		return it.collectionID()
+	}
+	return 0
+}
+
 // BaseTaskQueue is a basic instance of TaskQueue.
 type BaseTaskQueue struct {
-	unissuedTasks *list.List
-	activeTasks   map[UniqueID]task
-	utLock        sync.Mutex
-	atLock        sync.Mutex
+	unissuedTasks taskList
+	// policy is the policy of scheduling unissuedTasks, maxDefer is the max time a task is deferred by it
+	policy      string
+	maxDefer    time.Duration
+	weights     map[UniqueID]int64
+	activeTasks map[UniqueID]task
+	utLock      sync.Mutex
+	atLock      sync.Mutex
 
 	// maxTaskNum should keep still
 	maxTaskNum int64
 
+	// lowPriorityTasks are the unissued tasks of the low-priority class, which are popped in FIFO order once
+	// unissuedTasks are all popped
+	lowPriorityTasks list.List
+
 	utBufChan chan int // to block scheduler
+	// lpBufChan wakes the scheduler up for the low-priority tasks, the signals of which are coalesced, so the
+	// scheduler signals again after each round while any of them is left
+	lpBufChan chan int
 
 	sched *TaskScheduler
 }
@@ -59,6 +231,10 @@ func (queue *BaseTaskQueue) utChan() <-chan int {
 	return queue.utBufChan
 }
 
+func (queue *BaseTaskQueue) lpChan() <-chan int {
+	return queue.lpBufChan
+}
+
 func (queue *BaseTaskQueue) utEmpty() bool {
 	return queue.unissuedTasks.Len() == 0
 }
@@ -67,18 +243,72 @@ func (queue *BaseTaskQueue) utFull() bool {
 	return int64(queue.unissuedTasks.Len()) >= queue.maxTaskNum
 }
 
+func (queue *BaseTaskQueue) utLen() int {
+	queue.utLock.Lock()
+	defer queue.utLock.Unlock()
+	return queue.unissuedTasks.Len()
+}
+
+func (queue *BaseTaskQueue) utCap() int {
+	return int(queue.maxTaskNum)
+}
+
+func (queue *BaseTaskQueue) lpLen() int {
+	queue.utLock.Lock()
+	defer queue.utLock.Unlock()
+	return queue.lowPriorityTasks.Len()
+}
+
+func (queue *BaseTaskQueue) atLen() int {
+	queue.atLock.Lock()
+	defer queue.atLock.Unlock()
+	return len(queue.activeTasks)
+}
+
 func (queue *BaseTaskQueue) addUnissuedTask(t task) error {
 	queue.utLock.Lock()
 	defer queue.utLock.Unlock()
 
 	if queue.utFull() {
-		return errors.New("IndexNode task queue is full")
+		return errTaskQueueFull
 	}
 	queue.unissuedTasks.PushBack(t)
 	queue.utBufChan <- 1
 	return nil
 }
 
+// addLowPriorityTask adds a task of the low-priority class, which is not limited by maxTaskNum.
+func (queue *BaseTaskQueue) addLowPriorityTask(t task) {
+	queue.utLock.Lock()
+	defer queue.utLock.Unlock()
+
+	queue.lowPriorityTasks.PushBack(t)
+	queue.signalLowPriority()
+}
+
+// signalLowPriority wakes the scheduler up for the low-priority tasks, the signal pending already covers them.
+func (queue *BaseTaskQueue) signalLowPriority() {
+	select {
+	case queue.lpBufChan <- 1:
+	default:
+	}
+}
+
+// removeLowPriorityTask removes the unissued task of the low-priority class, it returns false if the task is
+// popped already.
+func (queue *BaseTaskQueue) removeLowPriorityTask(t task) bool {
+	queue.utLock.Lock()
+	defer queue.utLock.Unlock()
+
+	for e := queue.lowPriorityTasks.Front(); e != nil; e = e.Next() {
+		if e.Value.(task) == t {
+			queue.lowPriorityTasks.Remove(e)
+			return true
+		}
+	}
+	return false
+}
+
 //func (queue *BaseTaskQueue) FrontUnissuedTask() task {
 //	queue.utLock.Lock()
 //	defer queue.utLock.Unlock()
@@ -91,19 +321,20 @@ func (queue *BaseTaskQueue) addUnissuedTask(t task) error {
 //	return queue.unissuedTasks.Front().Value.(task)
 //}
 
-// PopUnissuedTask pops a task from tasks queue.
+// PopUnissuedTask pops a task from tasks queue in the order of the schedule policy, the tasks of the low-priority
+// class are popped only if no other task is waiting.
 func (queue *BaseTaskQueue) PopUnissuedTask() task {
 	queue.utLock.Lock()
 	defer queue.utLock.Unlock()
 
 	if queue.unissuedTasks.Len() <= 0 {
+		if front := queue.lowPriorityTasks.Front(); front != nil {
+			return queue.lowPriorityTasks.Remove(front).(task)
+		}
 		return nil
 	}
 
-	ft := queue.unissuedTasks.Front()
-	queue.unissuedTasks.Remove(ft)
-
-	return ft.Value.(task)
+	return queue.unissuedTasks.PopFront()
 }
 
 // AddActiveTask adds a task to activeTasks.
@@ -114,7 +345,7 @@ func (queue *BaseTaskQueue) AddActiveTask(t task) {
 	tID := t.ID()
 	_, ok := queue.activeTasks[tID]
 	if ok {
-		log.Debug("IndexNode task already in activate task list", zap.Any("TaskID", tID))
+		schedulerLog.Debug("IndexNode task already in activate task list", zap.Any("TaskID", tID))
 	}
 
 	queue.activeTasks[tID] = t
@@ -130,7 +361,7 @@ func (queue *BaseTaskQueue) PopActiveTask(tID UniqueID) task {
 		delete(queue.activeTasks, tID)
 		return t
 	}
-	log.Debug("IndexNode the task was not found in the active task list", zap.Any("TaskID", tID))
+	schedulerLog.Debug("IndexNode the task was not found in the active task list", zap.Any("TaskID", tID))
 	return nil
 }
 
@@ -155,6 +386,32 @@ func (queue *BaseTaskQueue) PopActiveTask(tID UniqueID) task {
 //	return indexBuildIDs
 //}
 
+// setSchedulePolicy switches the policy of scheduling the unissued tasks, which are kept in the queue.
+func (queue *BaseTaskQueue) setSchedulePolicy(policy string, maxDefer time.Duration) {
+	queue.utLock.Lock()
+	defer queue.utLock.Unlock()
+
+	if policy == queue.policy && maxDefer == queue.maxDefer {
+		return
+	}
+	unissuedTasks := newTaskList(policy, queue.weights, maxDefer)
+	for _, t := range drainTaskList(queue.unissuedTasks) {
+		unissuedTasks.PushBack(t)
+	}
+	schedulerLog.Info("IndexNode switches the schedule policy", zap.String("from", queue.policy), zap.String("to", policy),
+		zap.Duration("maxDefer", maxDefer), zap.Int("unissuedTasks", unissuedTasks.Len()))
+	queue.unissuedTasks = unissuedTasks
+	queue.policy = policy
+	queue.maxDefer = maxDefer
+}
+
+// schedulePolicy returns the policy of scheduling the unissued tasks and the max time a task is deferred.
+func (queue *BaseTaskQueue) schedulePolicy() (string, time.Duration) {
+	queue.utLock.Lock()
+	defer queue.utLock.Unlock()
+	return queue.policy, queue.maxDefer
+}
+
 // Enqueue adds a task to TaskQueue.
 func (queue *BaseTaskQueue) Enqueue(t task) error {
 	err := t.OnEnqueue()
@@ -164,19 +421,42 @@ func (queue *BaseTaskQueue) Enqueue(t task) error {
 	return queue.addUnissuedTask(t)
 }
 
+// EnqueueLowPriority adds a task of the low-priority class to TaskQueue.
+func (queue *BaseTaskQueue) EnqueueLowPriority(t task) error {
+	err := t.OnEnqueue()
+	if err != nil {
+		return err
+	}
+	queue.addLowPriorityTask(t)
+	return nil
+}
+
 // IndexBuildTaskQueue is a task queue used to store building index tasks.
 type IndexBuildTaskQueue struct {
 	BaseTaskQueue
 }
 
-// NewIndexBuildTaskQueue creates a new IndexBuildTaskQueue.
+// NewIndexBuildTaskQueue creates a new IndexBuildTaskQueue, the capacity of which is Params.MaxPendingTasks,
+// and the tasks are scheduled by Params.SchedulePolicy.
 func NewIndexBuildTaskQueue(sched *TaskScheduler) *IndexBuildTaskQueue {
+	maxTaskNum := Params.MaxPendingTasks
+	if maxTaskNum <= 0 {
+		maxTaskNum = defaultMaxPendingTasks
+	}
+	policy := Params.SchedulePolicy
+	if policy == "" {
+		policy = defaultSchedulePolicy
+	}
 	return &IndexBuildTaskQueue{
 		BaseTaskQueue: BaseTaskQueue{
-			unissuedTasks: list.New(),
+			unissuedTasks: newTaskList(policy, Params.CollectionWeights, Params.ScheduleMaxDefer),
+			policy:        policy,
+			maxDefer:      Params.ScheduleMaxDefer,
+			weights:       Params.CollectionWeights,
 			activeTasks:   make(map[UniqueID]task),
-			maxTaskNum:    1024,
-			utBufChan:     make(chan int, 1024),
+			maxTaskNum:    maxTaskNum,
+			utBufChan:     make(chan int, maxTaskNum),
+			lpBufChan:     make(chan int, 1),
 			sched:         sched,
 		},
 	}
@@ -191,6 +471,10 @@ type TaskScheduler struct {
 	wg            sync.WaitGroup
 	ctx           context.Context
 	cancel        context.CancelFunc
+
+	// started is set once the build loop starts, and building is set while the loop processes the tasks popped
+	started  int32
+	building int32
 }
 
 // NewTaskScheduler creates a new task scheduler of indexing tasks.
@@ -208,6 +492,16 @@ func NewTaskScheduler(ctx context.Context,
 	return s, nil
 }
 
+// estimateWaitTime estimates how long a new task waits before it is executed, based on the average build time.
+func (sched *TaskScheduler) estimateWaitTime(averageBuildTime time.Duration) time.Duration {
+	pending := sched.IndexBuildQueue.utLen() + sched.IndexBuildQueue.atLen()
+	parallel := sched.buildParallel
+	if parallel <= 0 {
+		parallel = 1
+	}
+	return averageBuildTime * time.Duration(pending) / time.Duration(parallel)
+}
+
 //func (sched *TaskScheduler) setParallelism(parallel int) {
 //	if parallel <= 0 {
 //		log.Debug("IndexNode can not set parallelism to less than zero!")
@@ -229,11 +523,16 @@ func (sched *TaskScheduler) scheduleIndexBuildTask() []task {
 }
 
 func (sched *TaskScheduler) processTask(t task, q TaskQueue) {
-	span, ctx := trace.StartSpanFromContext(t.Ctx(),
-		opentracing.Tags{
-			"Type": t.Name(),
-			"ID":   t.ID(),
-		})
+	tags := opentracing.Tags{
+		"Type": t.Name(),
+		"ID":   t.ID(),
+	}
+	if tagger, ok := t.(spanTagger); ok && Params.TracingEnabled {
+		for key, value := range tagger.spanTags() {
+			tags[key] = value
+		}
+	}
+	span, ctx := trace.StartSpanFromContext(t.Ctx(), tags)
 
 	defer span.Finish()
 	span.LogFields(oplog.Int64("scheduler process PreExecute", t.ID()))
@@ -267,25 +566,37 @@ func (sched *TaskScheduler) processTask(t task, q TaskQueue) {
 }
 
 func (sched *TaskScheduler) indexBuildLoop() {
-	log.Debug("IndexNode TaskScheduler start build loop ...")
+	schedulerLog.Debug("IndexNode TaskScheduler start build loop ...")
 	defer sched.wg.Done()
 	for {
 		select {
 		case <-sched.ctx.Done():
 			return
 		case <-sched.IndexBuildQueue.utChan():
-			if !sched.IndexBuildQueue.utEmpty() {
-				tasks := sched.scheduleIndexBuildTask()
-				var wg sync.WaitGroup
-				for _, t := range tasks {
-					wg.Add(1)
-					go func(group *sync.WaitGroup, t task) {
-						defer group.Done()
-						sched.processTask(t, sched.IndexBuildQueue)
-					}(&wg, t)
-				}
-				wg.Wait()
+		case <-sched.IndexBuildQueue.lpChan():
+		}
+		if !sched.IndexBuildQueue.utEmpty() || sched.IndexBuildQueue.lpLen() > 0 {
+			// set before popping, so that the draining never sees the tasks neither queued nor building
+			atomic.StoreInt32(&sched.building, 1)
+			tasks := sched.scheduleIndexBuildTask()
+			var wg sync.WaitGroup
+			for _, t := range tasks {
+				wg.Add(1)
+				go func(group *sync.WaitGroup, t task) {
+					defer group.Done()
+					sched.processTask(t, sched.IndexBuildQueue)
+					// the tasks of the low-priority class are waited by their submitters
+					if taskLowPriority(t) {
+						t.Notify(nil)
+					}
+				}(&wg, t)
 			}
+			wg.Wait()
+			atomic.StoreInt32(&sched.building, 0)
+		}
+		// the low-priority tasks left have no pending signal, when the unissued tasks drain before them
+		if sched.IndexBuildQueue.lpLen() > 0 {
+			sched.IndexBuildQueue.signalLowPriority()
 		}
 	}
 }
@@ -294,10 +605,33 @@ func (sched *TaskScheduler) indexBuildLoop() {
 func (sched *TaskScheduler) Start() error {
 
 	sched.wg.Add(1)
+	atomic.StoreInt32(&sched.started, 1)
 	go sched.indexBuildLoop()
 	return nil
 }
 
+// Drain waits up to @timeout for the tasks queued and the ones building to finish, the queued rebuilds are left to
+// the next start. It returns whether they are finished in time, it returns at once if the build loop never starts.
+func (sched *TaskScheduler) Drain(timeout time.Duration) bool {
+	if atomic.LoadInt32(&sched.started) == 0 {
+		return true
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+	for {
+		if sched.IndexBuildQueue.utEmpty() && atomic.LoadInt32(&sched.building) == 0 {
+			return true
+		}
+		select {
+		case <-deadline.C:
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
 // Close closes the task scheduler of indexing tasks.
 func (sched *TaskScheduler) Close() {
 	sched.cancel()
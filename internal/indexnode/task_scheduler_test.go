@@ -0,0 +1,334 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+)
+
+func newCollectionTask(ctx context.Context, buildID UniqueID, collectionID UniqueID) *IndexBuildTask {
+	return &IndexBuildTask{
+		BaseTask: BaseTask{
+			ctx:  ctx,
+			done: make(chan error),
+		},
+		req: &indexpb.CreateIndexRequest{
+			IndexBuildID: buildID,
+			DataPaths: []string{
+				fmt.Sprintf("by-dev/insert_log/%d/1/%d/100/1", collectionID, buildID),
+			},
+		},
+	}
+}
+
+func TestIndexBuildTask_collectionID(t *testing.T) {
+	it := newCollectionTask(context.Background(), 1, 10)
+	assert.Equal(t, UniqueID(10), it.collectionID())
+
+	it.req.DataPaths = []string{"by-dev/insert_log"}
+	assert.Equal(t, UniqueID(0), it.collectionID())
+
+	it.req.DataPaths = []string{"by-dev/insert_log/abc/1/1/100/1", "insert_log/11/1/1/100/1"}
+	assert.Equal(t, UniqueID(11), it.collectionID())
+
+	it.req.DataPaths = nil
+	assert.Equal(t, UniqueID(0), it.collectionID())
+}
+
+func TestFairTaskList(t *testing.T) {
+	ctx := context.Background()
+	l := newFairTaskList(map[UniqueID]int64{1: 2})
+	assert.Nil(t, l.PopFront())
+
+	// collection 1 is weighted 2, collection 2 and 3 are weighted 1
+	buildID := UniqueID(0)
+	for _, collectionID := range []UniqueID{1, 1, 1, 1, 1, 2, 2, 3} {
+		buildID++
+		l.PushBack(newCollectionTask(ctx, buildID, collectionID))
+	}
+	assert.Equal(t, 8, l.Len())
+
+	popped := make([]UniqueID, 0)
+	for l.Len() > 0 {
+		popped = append(popped, l.PopFront().(*IndexBuildTask).req.IndexBuildID)
+	}
+	assert.Equal(t, []UniqueID{1, 2, 6, 8, 3, 4, 7, 5}, popped)
+	assert.Nil(t, l.PopFront())
+}
+
+func TestPriorityFairTaskList(t *testing.T) {
+	ctx := context.Background()
+	l := newTaskList(schedulePolicyFair, nil, 0)
+	assert.Nil(t, l.PopFront())
+
+	// the tasks of priority 5 are of the collections in round-robin, ahead of the other priorities
+	buildID := UniqueID(0)
+	for _, task := range []struct {
+		collectionID UniqueID
+		priority     int64
+	}{{1, 0}, {1, 5}, {1, 5}, {2, 5}, {2, -1}, {3, 0}, {3, 5}} {
+		buildID++
+		it := newCollectionTask(ctx, buildID, task.collectionID)
+		it.req.Priority = task.priority
+		l.PushBack(it)
+	}
+	assert.Equal(t, 7, l.Len())
+
+	popped := make([]UniqueID, 0)
+	for l.Len() > 0 {
+		popped = append(popped, l.PopFront().(*IndexBuildTask).req.IndexBuildID)
+	}
+	assert.Equal(t, []UniqueID{2, 4, 7, 3, 1, 6, 5}, popped)
+	assert.Nil(t, l.PopFront())
+
+	// a task of a higher priority pushed later is still popped first
+	l.PushBack(newCollectionTask(ctx, 8, 1))
+	it := newCollectionTask(ctx, 9, 1)
+	it.req.Priority = 1
+	l.PushBack(it)
+	assert.Equal(t, UniqueID(9), l.PopFront().(*IndexBuildTask).req.IndexBuildID)
+	assert.Equal(t, UniqueID(8), l.PopFront().(*IndexBuildTask).req.IndexBuildID)
+	assert.Equal(t, 0, l.Len())
+}
+
+func TestPriorityTaskList(t *testing.T) {
+	ctx := context.Background()
+	l := newTaskList(schedulePolicyPriority, nil, 0)
+	assert.Nil(t, l.PopFront())
+
+	// the tasks of the same priority are in the order of arrival regardless of their collections
+	buildID := UniqueID(0)
+	for _, task := range []struct {
+		collectionID UniqueID
+		priority     int64
+	}{{1, 0}, {1, 5}, {1, 5}, {2, 5}, {2, -1}, {3, 0}, {3, 5}} {
+		buildID++
+		it := newCollectionTask(ctx, buildID, task.collectionID)
+		it.req.Priority = task.priority
+		l.PushBack(it)
+	}
+	assert.Equal(t, 7, l.Len())
+
+	popped := make([]UniqueID, 0)
+	for l.Len() > 0 {
+		popped = append(popped, l.PopFront().(*IndexBuildTask).req.IndexBuildID)
+	}
+	assert.Equal(t, []UniqueID{2, 3, 4, 7, 1, 6, 5}, popped)
+	assert.Nil(t, l.PopFront())
+}
+
+func TestTaskScheduler_FairScheduling(t *testing.T) {
+	ctx := context.Background()
+	sched, err := NewTaskScheduler(ctx, nil)
+	assert.Nil(t, err)
+	sched.buildParallel = 1
+
+	collectionA, collectionB := UniqueID(100), UniqueID(200)
+	buildID := UniqueID(0)
+	for i := 0; i < 100; i++ {
+		buildID++
+		err = sched.IndexBuildQueue.Enqueue(newCollectionTask(ctx, buildID, collectionA))
+		assert.Nil(t, err)
+	}
+	for i := 0; i < 2; i++ {
+		buildID++
+		err = sched.IndexBuildQueue.Enqueue(newCollectionTask(ctx, buildID, collectionB))
+		assert.Nil(t, err)
+	}
+
+	dispatched := make([]UniqueID, 0, buildID)
+	lastBuildIDs := make(map[UniqueID]UniqueID)
+	for !sched.IndexBuildQueue.utEmpty() {
+		<-sched.IndexBuildQueue.utChan()
+		tasks := sched.scheduleIndexBuildTask()
+		assert.Equal(t, 1, len(tasks))
+		it := tasks[0].(*IndexBuildTask)
+		collectionID := it.collectionID()
+		dispatched = append(dispatched, collectionID)
+
+		// FIFO within the collection
+		assert.Greater(t, it.ID(), lastBuildIDs[collectionID])
+		lastBuildIDs[collectionID] = it.ID()
+	}
+	assert.Equal(t, 102, len(dispatched))
+
+	// the tasks of collection B are dispatched within the first few dispatches
+	dispatchedB := 0
+	for _, collectionID := range dispatched[:4] {
+		if collectionID == collectionB {
+			dispatchedB++
+		}
+	}
+	assert.Equal(t, 2, dispatchedB)
+}
+
+func TestBaseTaskQueue_lowPriority(t *testing.T) {
+	ctx := context.Background()
+	sched, err := NewTaskScheduler(ctx, nil)
+	assert.Nil(t, err)
+	queue := sched.IndexBuildQueue
+
+	low := newCollectionTask(ctx, 1, 1)
+	low.lowPriority = true
+	queue.(*IndexBuildTaskQueue).addLowPriorityTask(low)
+	assert.Nil(t, queue.Enqueue(newCollectionTask(ctx, 2, 1)))
+	assert.Nil(t, queue.Enqueue(newCollectionTask(ctx, 3, 2)))
+	// the low-priority tasks are neither counted as unissued nor limited by the capacity
+	assert.Equal(t, 2, queue.utLen())
+	assert.Equal(t, 1, queue.lpLen())
+
+	popped := make([]UniqueID, 0)
+	for _, task := range sched.scheduleIndexBuildTask() {
+		popped = append(popped, task.(*IndexBuildTask).req.IndexBuildID)
+	}
+	assert.Equal(t, []UniqueID{2}, popped)
+	sched.buildParallel = 2
+	popped = popped[:0]
+	for _, task := range sched.scheduleIndexBuildTask() {
+		popped = append(popped, task.(*IndexBuildTask).req.IndexBuildID)
+		assert.Equal(t, task.(*IndexBuildTask).req.IndexBuildID == 1, taskLowPriority(task))
+	}
+	assert.Equal(t, []UniqueID{3, 1}, popped)
+	assert.Equal(t, 0, queue.lpLen())
+	assert.Nil(t, queue.PopUnissuedTask())
+}
+
+// blockingTask is the task blocking in Execute until released, and recording whether the loop context of IndexNode
+// is cancelled meanwhile.
+type blockingTask struct {
+	*IndexBuildTask
+	loopCtx   context.Context
+	executing chan struct{}
+	released  chan struct{}
+	finished  chan error
+}
+
+func newBlockingTask(loopCtx context.Context, buildID UniqueID) *blockingTask {
+	return &blockingTask{
+		IndexBuildTask: newCollectionTask(context.Background(), buildID, 1),
+		loopCtx:        loopCtx,
+		executing:      make(chan struct{}),
+		released:       make(chan struct{}),
+		finished:       make(chan error, 1),
+	}
+}
+
+func (bt *blockingTask) PreExecute(ctx context.Context) error {
+	return nil
+}
+
+func (bt *blockingTask) Execute(ctx context.Context) error {
+	close(bt.executing)
+	<-bt.released
+	bt.finished <- bt.loopCtx.Err()
+	return nil
+}
+
+func (bt *blockingTask) PostExecute(ctx context.Context) error {
+	return nil
+}
+
+func TestTaskScheduler_Drain(t *testing.T) {
+	sched, err := NewTaskScheduler(context.Background(), nil)
+	assert.Nil(t, err)
+	// nothing drains the queue before the build loop starts
+	assert.Nil(t, sched.IndexBuildQueue.Enqueue(newCollectionTask(context.Background(), 1, 1)))
+	assert.True(t, sched.Drain(time.Hour))
+	sched.IndexBuildQueue.PopUnissuedTask()
+
+	assert.Nil(t, sched.Start())
+	defer sched.Close()
+	building := newBlockingTask(context.Background(), 2)
+	assert.Nil(t, sched.IndexBuildQueue.Enqueue(building))
+	<-building.executing
+	assert.False(t, sched.Drain(50*time.Millisecond))
+	close(building.released)
+	assert.True(t, sched.Drain(5*time.Second))
+}
+
+func TestIndexNode_StopDrainsTasks(t *testing.T) {
+	in, err := NewIndexNode(context.Background())
+	assert.Nil(t, err)
+	assert.Nil(t, in.sched.Start())
+	building := newBlockingTask(in.loopCtx, 1)
+	assert.Nil(t, in.sched.IndexBuildQueue.Enqueue(building))
+	<-building.executing
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- in.Stop()
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("IndexNode stops before the in-flight task finishes")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(building.released)
+	// the task finishes with the loop context alive, which is cancelled after
+	assert.Nil(t, <-building.finished)
+	assert.Nil(t, <-stopped)
+	assert.NotNil(t, in.loopCtx.Err())
+}
+
+func TestTaskScheduler_lowPriorityWakeUp(t *testing.T) {
+	sched, err := NewTaskScheduler(context.Background(), nil)
+	assert.Nil(t, err)
+	assert.Nil(t, sched.Start())
+	defer sched.Close()
+	queue := sched.IndexBuildQueue
+
+	// the low-priority tasks queued while building wait for the unissued tasks, their signals are coalesced
+	building := newBlockingTask(context.Background(), 1)
+	assert.Nil(t, queue.Enqueue(building))
+	<-building.executing
+	rebuilds := make([]*blockingTask, 0)
+	for buildID := UniqueID(2); buildID < 5; buildID++ {
+		rebuild := newBlockingTask(context.Background(), buildID)
+		close(rebuild.released)
+		assert.Nil(t, queue.EnqueueLowPriority(rebuild))
+		rebuilds = append(rebuilds, rebuild)
+	}
+	next := newBlockingTask(context.Background(), 5)
+	close(next.released)
+	assert.Nil(t, queue.Enqueue(next))
+	close(building.released)
+	for _, rebuild := range rebuilds {
+		select {
+		case <-rebuild.finished:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("the low-priority task %d is never built", rebuild.ID())
+		}
+	}
+}
+
+func TestBaseTaskQueue_removeLowPriorityTask(t *testing.T) {
+	ctx := context.Background()
+	sched, err := NewTaskScheduler(ctx, nil)
+	assert.Nil(t, err)
+	queue := sched.IndexBuildQueue
+
+	first, second := newCollectionTask(ctx, 1, 1), newCollectionTask(ctx, 2, 1)
+	assert.Nil(t, queue.EnqueueLowPriority(first))
+	assert.Nil(t, queue.EnqueueLowPriority(second))
+	assert.True(t, queue.removeLowPriorityTask(second))
+	assert.False(t, queue.removeLowPriorityTask(second))
+	assert.Equal(t, first, queue.PopUnissuedTask())
+	assert.False(t, queue.removeLowPriorityTask(first))
+	assert.Equal(t, 0, queue.lpLen())
+}
@@ -0,0 +1,252 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/milvus-io/milvus/internal/metrics"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/metricsinfo"
+)
+
+const (
+	unknownIndexType = "unknown"
+	unknownSimdType  = "unknown"
+	// otherIndexType is the label of the index types not looking like one, which are taken from the requests as is
+	otherIndexType = "other"
+)
+
+var indexTypeLabelPattern = regexp.MustCompile(`^[A-Z0-9_]{1,32}$`)
+
+// indexTypeLabel returns the value of the index_type label of @indexType, so that the requests with the malformed
+// index types never grow the number of the series.
+func indexTypeLabel(indexType string) string {
+	if indexType == "" {
+		return unknownIndexType
+	}
+	indexType = strings.ToUpper(indexType)
+	if !indexTypeLabelPattern.MatchString(indexType) {
+		return otherIndexType
+	}
+	return indexType
+}
+
+// taskStatus returns the value of the status label of the task finished with @err.
+func taskStatus(err error) string {
+	switch {
+	case err == nil:
+		return metrics.IndexNodeTaskSucceeded
+	case classifyError(err) == errorcode.Cancelled:
+		return metrics.IndexNodeTaskCancelled
+	default:
+		return metrics.IndexNodeTaskFailed
+	}
+}
+
+// taskStatistics records the statistics of the index building tasks executed by IndexNode.
+type taskStatistics struct {
+	mu sync.Mutex
+
+	completedTaskNum  int64
+	failedTaskNum     int64
+	indexTypeBuildNum map[string]int64
+	simdTypeBuildNum  map[string]int64
+	buildDuration     time.Duration
+	// indexTypePeakMemory is the max peak memory of the builds of each index type
+	indexTypePeakMemory map[string]int64
+	// indexTypeEngineStats is the statistics reported by the engine of the last build of each index type
+	indexTypeEngineStats map[string]metricsinfo.IndexEngineStatistics
+	// indexTypeEngineConfig is the effective build configuration of the engine of the last build of each index type
+	indexTypeEngineConfig map[string]map[string]string
+
+	loadedBytes  int64
+	loadDuration time.Duration
+	savedBytes   int64
+	saveDuration time.Duration
+}
+
+func newTaskStatistics() *taskStatistics {
+	return &taskStatistics{
+		indexTypeBuildNum:     make(map[string]int64),
+		simdTypeBuildNum:      make(map[string]int64),
+		indexTypePeakMemory:   make(map[string]int64),
+		indexTypeEngineStats:  make(map[string]metricsinfo.IndexEngineStatistics),
+		indexTypeEngineConfig: make(map[string]map[string]string),
+	}
+}
+
+// recordTask records the result of a task, simdType is the effective simd type the index is built with.
+func (s *taskStatistics) recordTask(indexType string, simdType string, duration time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.failedTaskNum++
+		metrics.IndexNodeTaskCounter.WithLabelValues(metrics.IndexNodeTaskFailed).Inc()
+		return
+	}
+	if indexType == "" {
+		indexType = unknownIndexType
+	}
+	if simdType == "" {
+		simdType = unknownSimdType
+	}
+	metrics.IndexNodeTaskCounter.WithLabelValues(metrics.IndexNodeTaskSucceeded).Inc()
+	metrics.IndexNodeBuildDuration.WithLabelValues(indexType).Observe(duration.Seconds())
+	s.completedTaskNum++
+	s.indexTypeBuildNum[indexType]++
+	s.simdTypeBuildNum[simdType]++
+	s.buildDuration += duration
+}
+
+// averageBuildTime returns the average time of the completed tasks.
+func (s *taskStatistics) averageBuildTime() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.completedTaskNum == 0 {
+		return 0
+	}
+	return s.buildDuration / time.Duration(s.completedTaskNum)
+}
+
+// recordPeakMemory records the peak memory growth of a build in bytes.
+func (s *taskStatistics) recordPeakMemory(indexType string, peak int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if indexType == "" {
+		indexType = unknownIndexType
+	}
+	if peak > s.indexTypePeakMemory[indexType] {
+		s.indexTypePeakMemory[indexType] = peak
+	}
+}
+
+// recordEngineStatistics records the statistics reported by the engine of a build.
+func (s *taskStatistics) recordEngineStatistics(indexType string, stats metricsinfo.IndexEngineStatistics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if indexType == "" {
+		indexType = unknownIndexType
+	}
+	s.indexTypeEngineStats[indexType] = stats
+}
+
+// recordEngineConfig records the effective build configuration of the engine of a build.
+func (s *taskStatistics) recordEngineConfig(indexType string, config map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if indexType == "" {
+		indexType = unknownIndexType
+	}
+	s.indexTypeEngineConfig[indexType] = config
+}
+
+// recordStageDurations records the time spent in each of the stages of a task finished with @status, the stages
+// the task never entered are not observed but reported as 0 by the breakdown of the last build.
+func (s *taskStatistics) recordStageDurations(indexType string, status string, durations map[string]time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	indexType = indexTypeLabel(indexType)
+	for _, stage := range taskStages {
+		duration, ok := durations[stage]
+		if ok {
+			metrics.IndexNodeBuildStageDuration.WithLabelValues(indexType, stage, status).Observe(duration.Seconds())
+		}
+		metrics.IndexNodeLastBuildStageDuration.WithLabelValues(stage).Set(duration.Seconds())
+	}
+}
+
+func (s *taskStatistics) recordLoad(size int64, duration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedBytes += size
+	s.loadDuration += duration
+	metrics.IndexNodeStorageLatency.WithLabelValues(metrics.IndexNodeStorageLoad).Observe(duration.Seconds())
+}
+
+func (s *taskStatistics) recordSave(size int64, duration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.savedBytes += size
+	s.saveDuration += duration
+	metrics.IndexNodeStorageLatency.WithLabelValues(metrics.IndexNodeStorageSave).Observe(duration.Seconds())
+}
+
+func throughput(size int64, duration time.Duration) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return float64(size) / duration.Seconds()
+}
+
+// taskInfos returns the statistics in the format of metrics, the queue states are provided by the caller.
+func (s *taskStatistics) taskInfos(queuedTaskNum, activeTaskNum, maxPendingTaskNum int) metricsinfo.IndexNodeTaskInfos {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	indexTypeBuildNum := make(map[string]int64, len(s.indexTypeBuildNum))
+	for indexType, num := range s.indexTypeBuildNum {
+		indexTypeBuildNum[indexType] = num
+	}
+	simdTypeBuildNum := make(map[string]int64, len(s.simdTypeBuildNum))
+	for simdType, num := range s.simdTypeBuildNum {
+		simdTypeBuildNum[simdType] = num
+	}
+	indexTypePeakMemory := make(map[string]int64, len(s.indexTypePeakMemory))
+	for indexType, peak := range s.indexTypePeakMemory {
+		indexTypePeakMemory[indexType] = peak
+	}
+	var indexTypeEngineStats map[string]metricsinfo.IndexEngineStatistics
+	if len(s.indexTypeEngineStats) > 0 {
+		indexTypeEngineStats = make(map[string]metricsinfo.IndexEngineStatistics, len(s.indexTypeEngineStats))
+		for indexType, stats := range s.indexTypeEngineStats {
+			indexTypeEngineStats[indexType] = stats
+		}
+	}
+	var indexTypeEngineConfig map[string]map[string]string
+	if len(s.indexTypeEngineConfig) > 0 {
+		// the recorded configurations are never modified, so they are shared
+		indexTypeEngineConfig = make(map[string]map[string]string, len(s.indexTypeEngineConfig))
+		for indexType, config := range s.indexTypeEngineConfig {
+			indexTypeEngineConfig[indexType] = config
+		}
+	}
+	availableSlotNum := maxPendingTaskNum - queuedTaskNum
+	if availableSlotNum < 0 {
+		availableSlotNum = 0
+	}
+	return metricsinfo.IndexNodeTaskInfos{
+		QueuedTaskNum:     int64(queuedTaskNum),
+		ActiveTaskNum:     int64(activeTaskNum),
+		MaxPendingTaskNum: int64(maxPendingTaskNum),
+		AvailableSlotNum:  int64(availableSlotNum),
+		CompletedTaskNum:  s.completedTaskNum,
+		FailedTaskNum:     s.failedTaskNum,
+		IndexTypeBuildNum: indexTypeBuildNum,
+		SimdTypeBuildNum:  simdTypeBuildNum,
+		LoadedBytes:       s.loadedBytes,
+		LoadThroughput:    throughput(s.loadedBytes, s.loadDuration),
+		SavedBytes:        s.savedBytes,
+		SaveThroughput:    throughput(s.savedBytes, s.saveDuration),
+
+		IndexTypePeakMemory:       indexTypePeakMemory,
+		IndexTypeEngineStatistics: indexTypeEngineStats,
+		IndexTypeEngineConfig:     indexTypeEngineConfig,
+	}
+}
+
+// recordStageDurations records the time spent in each of the stages the task entered before it finished with @err.
+func (it *IndexBuildTask) recordStageDurations(err error) {
+	it.stats.recordStageDurations(it.indexType(), taskStatus(err), it.progress.stageDurations(time.Now()))
+}
@@ -0,0 +1,162 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/metrics"
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+)
+
+func TestTaskStatistics(t *testing.T) {
+	s := newTaskStatistics()
+	infos := s.taskInfos(2, 1, 10)
+	assert.Equal(t, int64(2), infos.QueuedTaskNum)
+	assert.Equal(t, int64(1), infos.ActiveTaskNum)
+	assert.Equal(t, int64(10), infos.MaxPendingTaskNum)
+	assert.Equal(t, int64(8), infos.AvailableSlotNum)
+	assert.Equal(t, float64(0), infos.LoadThroughput)
+	assert.Equal(t, time.Duration(0), s.averageBuildTime())
+
+	s.recordTask("IVF_FLAT", "AVX2", time.Second, nil)
+	s.recordTask("IVF_FLAT", "AVX512", 2*time.Second, nil)
+	s.recordTask("", "", 3*time.Second, nil)
+	s.recordTask("HNSW", "AVX512", time.Minute, errors.New("build failed"))
+	assert.Equal(t, 2*time.Second, s.averageBuildTime())
+	s.recordLoad(1024, time.Second)
+	s.recordSave(4096, 2*time.Second)
+
+	infos = s.taskInfos(12, 0, 10)
+	assert.Equal(t, int64(0), infos.AvailableSlotNum)
+	assert.Equal(t, int64(3), infos.CompletedTaskNum)
+	assert.Equal(t, int64(1), infos.FailedTaskNum)
+	assert.Equal(t, map[string]int64{"IVF_FLAT": 2, unknownIndexType: 1}, infos.IndexTypeBuildNum)
+	assert.Equal(t, map[string]int64{"AVX2": 1, "AVX512": 1, unknownSimdType: 1}, infos.SimdTypeBuildNum)
+	assert.Equal(t, int64(1024), infos.LoadedBytes)
+	assert.Equal(t, float64(1024), infos.LoadThroughput)
+	assert.Equal(t, int64(4096), infos.SavedBytes)
+	assert.Equal(t, float64(2048), infos.SaveThroughput)
+}
+
+func TestIndexBuildTask_indexType(t *testing.T) {
+	it := &IndexBuildTask{req: &indexpb.CreateIndexRequest{}}
+	assert.Equal(t, "", it.indexType())
+
+	it.req.IndexParams = []*commonpb.KeyValuePair{{Key: "metric_type", Value: "L2"}, {Key: indexTypeKey, Value: "IVF_PQ"}}
+	assert.Equal(t, "IVF_PQ", it.indexType())
+
+	it.req.IndexParams = []*commonpb.KeyValuePair{{Key: paramsKeyToParse, Value: `{"index_type": "HNSW", "M": "8"}`}}
+	assert.Equal(t, "HNSW", it.indexType())
+}
+
+func TestIndexTypeLabel(t *testing.T) {
+	assert.Equal(t, "IVF_FLAT", indexTypeLabel("IVF_FLAT"))
+	assert.Equal(t, "HNSW", indexTypeLabel("hnsw"))
+	assert.Equal(t, unknownIndexType, indexTypeLabel(""))
+	assert.Equal(t, otherIndexType, indexTypeLabel("IVF FLAT"))
+	assert.Equal(t, otherIndexType, indexTypeLabel("IVF_FLAT_IVF_FLAT_IVF_FLAT_IVF_FLAT"))
+}
+
+func TestTaskStatus(t *testing.T) {
+	assert.Equal(t, metrics.IndexNodeTaskSucceeded, taskStatus(nil))
+	assert.Equal(t, metrics.IndexNodeTaskFailed, taskStatus(errors.New("build failed")))
+	assert.Equal(t, metrics.IndexNodeTaskCancelled, taskStatus(errorcode.New(errorcode.Cancelled, "reassigned")))
+}
+
+// stageHistogram returns the histogram of the stage durations of the tasks of @indexType finished with @status.
+func stageHistogram(t *testing.T, indexType string, stage string, status string) *dto.Histogram {
+	m := &dto.Metric{}
+	assert.Nil(t, metrics.IndexNodeBuildStageDuration.WithLabelValues(indexType, stage, status).(prometheus.Histogram).Write(m))
+	return m.GetHistogram()
+}
+
+// bucketCount returns the number of the observations in the bucket of @upperBound.
+func bucketCount(h *dto.Histogram, upperBound float64) uint64 {
+	for _, bucket := range h.GetBucket() {
+		if bucket.GetUpperBound() == upperBound {
+			return bucket.GetCumulativeCount()
+		}
+	}
+	return 0
+}
+
+func lastBuildStageDuration(t *testing.T, stage string) float64 {
+	m := &dto.Metric{}
+	assert.Nil(t, metrics.IndexNodeLastBuildStageDuration.WithLabelValues(stage).Write(m))
+	return m.GetGauge().GetValue()
+}
+
+func TestIndexBuildTask_recordStageDurations(t *testing.T) {
+	newTask := func(indexType string) *IndexBuildTask {
+		return &IndexBuildTask{
+			req: &indexpb.CreateIndexRequest{
+				IndexBuildID: 1,
+				IndexParams:  []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: indexType}},
+			},
+			progress: newTaskProgress(1, 1),
+			stats:    newTaskStatistics(),
+		}
+	}
+
+	// the build stage spends at least 60ms on loading the binlogs by 4 workers
+	segment := newPipelineTestSegment(8, 10)
+	segment.loadLatency = func() time.Duration { return 30 * time.Millisecond }
+	it := newTask("stage_test_flat")
+	it.setStage(taskStageBuild)
+	assert.Nil(t, segment.pipeline(&mockIncrementalIndex{}, 10).run(context.Background()))
+	it.setStage(taskStageSerialize)
+	it.setStage(taskStageSave)
+	it.setStage(taskStagePostExecute)
+	it.recordStageDurations(nil)
+
+	build := stageHistogram(t, "STAGE_TEST_FLAT", taskStageBuild, metrics.IndexNodeTaskSucceeded)
+	assert.Equal(t, uint64(1), build.GetSampleCount())
+	assert.Equal(t, uint64(0), bucketCount(build, 0.04))
+	assert.True(t, build.GetSampleSum() >= 0.06)
+	assert.GreaterOrEqual(t, lastBuildStageDuration(t, taskStageBuild), 0.06)
+	for _, stage := range []string{taskStagePreExecute, taskStageSerialize, taskStageSave, taskStagePostExecute} {
+		h := stageHistogram(t, "STAGE_TEST_FLAT", stage, metrics.IndexNodeTaskSucceeded)
+		assert.Equal(t, uint64(1), h.GetSampleCount(), stage)
+		assert.Equal(t, uint64(1), bucketCount(h, 0.04), stage)
+	}
+	// the pipelined build never enters the load stage
+	assert.Equal(t, uint64(0), stageHistogram(t, "STAGE_TEST_FLAT", taskStageLoad, metrics.IndexNodeTaskSucceeded).GetSampleCount())
+	assert.Equal(t, float64(0), lastBuildStageDuration(t, taskStageLoad))
+
+	// the failed and cancelled tasks record the stages they entered
+	it = newTask("stage_test_flat")
+	it.setStage(taskStageLoad)
+	it.setStage(taskStagePostExecute)
+	it.recordStageDurations(errors.New("load failed"))
+	it = newTask("stage_test_flat")
+	it.recordStageDurations(errorcode.New(errorcode.Cancelled, "reassigned"))
+	for _, stage := range []string{taskStagePreExecute, taskStageLoad, taskStagePostExecute} {
+		assert.Equal(t, uint64(1), stageHistogram(t, "STAGE_TEST_FLAT", stage, metrics.IndexNodeTaskFailed).GetSampleCount(), stage)
+	}
+	assert.Equal(t, uint64(0), stageHistogram(t, "STAGE_TEST_FLAT", taskStageBuild, metrics.IndexNodeTaskFailed).GetSampleCount())
+	assert.Equal(t, uint64(1), stageHistogram(t, "STAGE_TEST_FLAT", taskStagePreExecute,
+		metrics.IndexNodeTaskCancelled).GetSampleCount())
+	assert.Equal(t, uint64(0), stageHistogram(t, "STAGE_TEST_FLAT", taskStagePostExecute,
+		metrics.IndexNodeTaskCancelled).GetSampleCount())
+	// the breakdown of the last build is of the cancelled one
+	assert.Equal(t, float64(0), lastBuildStageDuration(t, taskStageBuild))
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+
+	"github.com/milvus-io/milvus/internal/storage"
+)
+
+// the operations of the spans of the stages of the index build tasks, which are the children of the span of the
+// task, and the span of the task continues the trace context received on the build request
+const (
+	spanDownload  = "IndexNode-Download"
+	spanDecode    = "IndexNode-Decode"
+	spanBuild     = "IndexNode-Build"
+	spanSerialize = "IndexNode-Serialize"
+	spanUpload    = "IndexNode-Upload"
+	spanMetaSave  = "IndexNode-MetaSave"
+)
+
+// the tags of the spans of the index build tasks
+const (
+	tagBuildID      = "buildID"
+	tagCollectionID = "collectionID"
+	tagIndexType    = "indexType"
+	tagFiles        = "files"
+	tagBytes        = "bytes"
+	tagRows         = "rows"
+	tagPipelined    = "pipelined"
+	tagPre          = "pre"
+)
+
+// spanTagger is implemented by the tasks tagging the spans of their own.
+type spanTagger interface {
+	spanTags() opentracing.Tags
+}
+
+func (it *IndexBuildTask) spanTags() opentracing.Tags {
+	return opentracing.Tags{
+		tagBuildID:      it.req.IndexBuildID,
+		tagCollectionID: it.collectionID(),
+		tagIndexType:    it.indexType(),
+	}
+}
+
+// startStageSpan starts the span of a stage of the task as the child of the span in @ctx. It's a no-op span if
+// the tracing is disabled, which is cheap enough to start for each stage.
+func (it *IndexBuildTask) startStageSpan(ctx context.Context, operation string) opentracing.Span {
+	if !Params.TracingEnabled {
+		return opentracing.NoopTracer{}.StartSpan(operation)
+	}
+	opts := []opentracing.StartSpanOption{it.spanTags()}
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent.Context()))
+	}
+	return opentracing.StartSpan(operation, opts...)
+}
+
+// finishStageSpan finishes the span of a stage with the error the stage fails with.
+func finishStageSpan(span opentracing.Span, err error) {
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.SetTag("error.message", err.Error())
+	}
+	span.Finish()
+}
+
+// vectorRows returns the number of rows of the decoded vector field.
+func vectorRows(value storage.FieldData) int64 {
+	var numRows []int64
+	switch data := value.(type) {
+	case *storage.FloatVectorFieldData:
+		numRows = data.NumRows
+	case *storage.BinaryVectorFieldData:
+		numRows = data.NumRows
+	case *storage.Float16VectorFieldData:
+		numRows = data.NumRows
+	case *storage.BFloat16VectorFieldData:
+		numRows = data.NumRows
+	}
+	var rows int64
+	for _, num := range numRows {
+		rows += num
+	}
+	return rows
+}
@@ -0,0 +1,121 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/storage"
+)
+
+// tracedTask runs the stages of the index build task with the spans only.
+type tracedTask struct {
+	*IndexBuildTask
+}
+
+func (t *tracedTask) PreExecute(ctx context.Context) error {
+	finishStageSpan(t.startStageSpan(ctx, spanMetaSave), nil)
+	return nil
+}
+
+func (t *tracedTask) Execute(ctx context.Context) error {
+	finishStageSpan(t.startStageSpan(ctx, spanDownload), nil)
+	finishStageSpan(t.startStageSpan(ctx, spanBuild), errors.New("build failed"))
+	return nil
+}
+
+func (t *tracedTask) PostExecute(ctx context.Context) error {
+	return nil
+}
+
+func TestIndexBuildTask_Spans(t *testing.T) {
+	oldTracer, oldEnabled := opentracing.GlobalTracer(), Params.TracingEnabled
+	defer func() {
+		opentracing.SetGlobalTracer(oldTracer)
+		Params.TracingEnabled = oldEnabled
+	}()
+	tracer := mocktracer.New()
+	opentracing.SetGlobalTracer(tracer)
+
+	sched, err := NewTaskScheduler(context.Background(), nil)
+	assert.Nil(t, err)
+	newTask := func(ctx context.Context) *tracedTask {
+		return &tracedTask{&IndexBuildTask{
+			BaseTask: BaseTask{ctx: ctx, done: make(chan error)},
+			req: &indexpb.CreateIndexRequest{
+				IndexBuildID: 10,
+				DataPaths:    []string{"insert_log/1/2/3/100/1"},
+				IndexParams:  []*commonpb.KeyValuePair{{Key: indexTypeKey, Value: "IVF_FLAT"}},
+			},
+		}}
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		tracer.Reset()
+		Params.TracingEnabled = true
+		// the span of the build request, continued from the trace context received by the grpc server
+		requestSpan, ctx := opentracing.StartSpanFromContext(context.Background(), "IndexNode-CreateIndex")
+		sched.processTask(newTask(ctx), sched.IndexBuildQueue)
+		requestSpan.Finish()
+
+		spans := tracer.FinishedSpans()
+		byName := make(map[string]*mocktracer.MockSpan)
+		for _, span := range spans {
+			byName[span.OperationName] = span
+		}
+		assert.Len(t, byName, 5)
+		var taskSpan *mocktracer.MockSpan
+		for _, span := range spans {
+			if span.ParentID == requestSpan.(*mocktracer.MockSpan).SpanContext.SpanID {
+				taskSpan = span
+			}
+		}
+		assert.NotNil(t, taskSpan)
+		for _, span := range []*mocktracer.MockSpan{taskSpan, byName[spanMetaSave], byName[spanDownload], byName[spanBuild]} {
+			assert.Equal(t, requestSpan.(*mocktracer.MockSpan).SpanContext.TraceID, span.SpanContext.TraceID)
+			assert.Equal(t, int64(10), span.Tag(tagBuildID))
+			assert.Equal(t, int64(1), span.Tag(tagCollectionID))
+			assert.Equal(t, "IVF_FLAT", span.Tag(tagIndexType))
+		}
+		for _, name := range []string{spanMetaSave, spanDownload, spanBuild} {
+			assert.Equal(t, taskSpan.SpanContext.SpanID, byName[name].ParentID)
+		}
+		assert.Nil(t, byName[spanDownload].Tag("error"))
+		assert.Equal(t, true, byName[spanBuild].Tag("error"))
+		assert.Equal(t, "build failed", byName[spanBuild].Tag("error.message"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		tracer.Reset()
+		Params.TracingEnabled = false
+		it := newTask(context.Background())
+		span := it.startStageSpan(context.Background(), spanDownload)
+		_, ok := span.(*mocktracer.MockSpan)
+		assert.False(t, ok)
+		finishStageSpan(span, errors.New("no-op"))
+		assert.Empty(t, tracer.FinishedSpans())
+	})
+}
+
+func TestVectorRows(t *testing.T) {
+	assert.Equal(t, int64(30), vectorRows(&storage.FloatVectorFieldData{NumRows: []int64{10, 20}}))
+	assert.Equal(t, int64(8), vectorRows(&storage.BinaryVectorFieldData{NumRows: []int64{8}}))
+	assert.Equal(t, int64(0), vectorRows(&storage.Int64FieldData{NumRows: []int64{8}}))
+}
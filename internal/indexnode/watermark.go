@@ -0,0 +1,181 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/mem"
+	"go.uber.org/zap"
+
+	"github.com/milvus-io/milvus/internal/log"
+	"github.com/milvus-io/milvus/internal/metrics"
+)
+
+const watermarkCheckInterval = 5 * time.Second
+
+// the resources guarded by the watermarks
+const (
+	watermarkMemory = "memory"
+	watermarkDisk   = "disk"
+	// watermarkBenchmark pauses the admission while a build benchmark is running, which is not sampled by check
+	watermarkBenchmark = "benchmark"
+)
+
+// watermarks are the thresholds of pausing and resuming the admission of new tasks, the admission paused
+// by a resource is resumed only after the resource recovers past its low watermark.
+type watermarks struct {
+	// memory usage ratio of the node, 0 disables the memory watermark
+	memoryHigh float64
+	memoryLow  float64
+	// free bytes of the scratch path, 0 disables the disk watermark
+	diskMinFree    uint64
+	diskResumeFree uint64
+}
+
+func watermarksFromParams() watermarks {
+	return watermarks{
+		memoryHigh:     Params.MemoryHighWatermark,
+		memoryLow:      Params.MemoryLowWatermark,
+		diskMinFree:    uint64(Params.DiskMinFree),
+		diskResumeFree: uint64(Params.DiskResumeFree),
+	}
+}
+
+// memoryBudget returns the bytes of the @total memory the builds may use before the admission is paused by the
+// @high watermark, 0 if the memory watermark is disabled.
+func memoryBudget(total uint64, high float64) uint64 {
+	if high <= 0 {
+		return 0
+	}
+	return uint64(float64(total) * high)
+}
+
+// nodeMemoryUsage returns the used and the total memory of the node.
+func nodeMemoryUsage() (uint64, uint64, error) {
+	stats, err := mem.VirtualMemory()
+	if err != nil {
+		return 0, 0, err
+	}
+	return stats.Used, stats.Total, nil
+}
+
+// scratchFreeSpace returns the free space of the scratch path.
+func scratchFreeSpace() (uint64, error) {
+	usage, err := disk.Usage(Params.ScratchPath)
+	if err != nil {
+		return 0, err
+	}
+	return usage.Free, nil
+}
+
+// admissionGuard pauses the admission of new tasks when the memory or the disk of the node runs low,
+// the in-progress tasks are not affected.
+type admissionGuard struct {
+	watermarks  watermarks
+	memoryUsage func() (used uint64, total uint64, err error)
+	freeSpace   func() (uint64, error)
+
+	mu      sync.RWMutex
+	paused  map[string]string
+	reasons string
+}
+
+func newAdmissionGuard(w watermarks, memoryUsage func() (uint64, uint64, error), freeSpace func() (uint64, error)) *admissionGuard {
+	return &admissionGuard{
+		watermarks:  w,
+		memoryUsage: memoryUsage,
+		freeSpace:   freeSpace,
+		paused:      make(map[string]string),
+	}
+}
+
+// check samples the resources and pauses or resumes the admission, the state of a resource is kept
+// if it fails to sample.
+func (g *admissionGuard) check() {
+	if g.watermarks.memoryHigh > 0 {
+		used, total, err := g.memoryUsage()
+		if err != nil {
+			log.Warn("IndexNode failed to get the memory usage for the watermark", zap.Error(err))
+		} else if total > 0 {
+			ratio := float64(used) / float64(total)
+			g.update(watermarkMemory, ratio > g.watermarks.memoryHigh, ratio < g.watermarks.memoryLow,
+				fmt.Sprintf("memory usage %.2f exceeds the high watermark %.2f", ratio, g.watermarks.memoryHigh))
+		}
+	}
+	if g.watermarks.diskMinFree > 0 {
+		free, err := g.freeSpace()
+		if err != nil {
+			log.Warn("IndexNode failed to get the free space of scratch path for the watermark", zap.Error(err))
+		} else {
+			g.update(watermarkDisk, free < g.watermarks.diskMinFree, free > g.watermarks.diskResumeFree,
+				fmt.Sprintf("free space of scratch path %d bytes is less than %d bytes", free, g.watermarks.diskMinFree))
+		}
+	}
+}
+
+// update pauses the admission for the resource if @pause, or resumes it if @resume.
+func (g *admissionGuard) update(resource string, pause bool, resume bool, reason string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, paused := g.paused[resource]
+	switch {
+	case !paused && pause:
+		g.paused[resource] = reason
+		log.Warn("IndexNode pauses the admission of new tasks", zap.String("resource", resource), zap.String("reason", reason))
+		metrics.IndexNodeAdmissionPaused.WithLabelValues(resource).Set(1)
+	case paused && resume:
+		delete(g.paused, resource)
+		log.Info("IndexNode resumes the admission of new tasks", zap.String("resource", resource))
+		metrics.IndexNodeAdmissionPaused.WithLabelValues(resource).Set(0)
+	case paused:
+		// keep the latest reason
+		g.paused[resource] = reason
+	default:
+		return
+	}
+	reasons := make([]string, 0, len(g.paused))
+	for _, resource := range []string{watermarkMemory, watermarkDisk, watermarkBenchmark} {
+		if reason, ok := g.paused[resource]; ok {
+			reasons = append(reasons, reason)
+		}
+	}
+	g.reasons = strings.Join(reasons, "; ")
+}
+
+// admissible returns false along with the reasons if the admission is paused, a nil guard admits all tasks.
+func (g *admissionGuard) admissible() (bool, string) {
+	if g == nil {
+		return true, ""
+	}
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.paused) == 0, g.reasons
+}
+
+// watermarkCheckLoop checks the watermarks periodically.
+func (i *IndexNode) watermarkCheckLoop() {
+	ticker := time.NewTicker(watermarkCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-i.loopCtx.Done():
+			return
+		case <-ticker.C:
+			i.admission.check()
+		}
+	}
+}
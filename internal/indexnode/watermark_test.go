@@ -0,0 +1,164 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexnode
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+)
+
+// fakeResources is the memory and the disk driven by the tests.
+type fakeResources struct {
+	used, total uint64
+	free        uint64
+	err         error
+}
+
+func (r *fakeResources) memoryUsage() (uint64, uint64, error) {
+	return r.used, r.total, r.err
+}
+
+func (r *fakeResources) freeSpace() (uint64, error) {
+	return r.free, r.err
+}
+
+func (r *fakeResources) guard(w watermarks) *admissionGuard {
+	return newAdmissionGuard(w, r.memoryUsage, r.freeSpace)
+}
+
+func TestMemoryBudget(t *testing.T) {
+	assert.Equal(t, uint64(900), memoryBudget(1000, 0.9))
+	assert.Equal(t, uint64(0), memoryBudget(1000, 0))
+}
+
+func TestAdmissionGuard_memory(t *testing.T) {
+	resources := &fakeResources{used: 50, total: 100, free: 1000}
+	guard := resources.guard(watermarks{memoryHigh: 0.9, memoryLow: 0.8})
+	guard.check()
+	admissible, _ := guard.admissible()
+	assert.True(t, admissible)
+
+	resources.used = 95
+	guard.check()
+	admissible, reason := guard.admissible()
+	assert.False(t, admissible)
+	assert.Contains(t, reason, "memory")
+
+	// paused until the usage falls below the low watermark
+	resources.used = 85
+	guard.check()
+	admissible, _ = guard.admissible()
+	assert.False(t, admissible)
+	resources.used = 79
+	guard.check()
+	admissible, reason = guard.admissible()
+	assert.True(t, admissible)
+	assert.Equal(t, "", reason)
+
+	// the state is kept if it fails to sample
+	resources.used = 95
+	guard.check()
+	resources.err = errors.New("permission denied")
+	resources.used = 10
+	guard.check()
+	admissible, _ = guard.admissible()
+	assert.False(t, admissible)
+}
+
+func TestAdmissionGuard_disk(t *testing.T) {
+	resources := &fakeResources{used: 95, total: 100, free: 1000}
+	// the memory watermark is disabled
+	guard := resources.guard(watermarks{diskMinFree: 100, diskResumeFree: 200})
+	guard.check()
+	admissible, _ := guard.admissible()
+	assert.True(t, admissible)
+
+	resources.free = 99
+	guard.check()
+	admissible, reason := guard.admissible()
+	assert.False(t, admissible)
+	assert.Contains(t, reason, "free space")
+
+	resources.free = 150
+	guard.check()
+	admissible, _ = guard.admissible()
+	assert.False(t, admissible)
+	resources.free = 201
+	guard.check()
+	admissible, _ = guard.admissible()
+	assert.True(t, admissible)
+
+	t.Run("both", func(t *testing.T) {
+		resources := &fakeResources{used: 95, total: 100, free: 10}
+		guard := resources.guard(watermarks{memoryHigh: 0.9, memoryLow: 0.8, diskMinFree: 100, diskResumeFree: 200})
+		guard.check()
+		admissible, reason := guard.admissible()
+		assert.False(t, admissible)
+		assert.Contains(t, reason, "memory")
+		assert.Contains(t, reason, "free space")
+
+		// paused until both of the resources recover
+		resources.used = 10
+		guard.check()
+		admissible, reason = guard.admissible()
+		assert.False(t, admissible)
+		assert.NotContains(t, reason, "memory")
+		resources.free = 1000
+		guard.check()
+		admissible, _ = guard.admissible()
+		assert.True(t, admissible)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var guard *admissionGuard
+		admissible, _ := guard.admissible()
+		assert.True(t, admissible)
+	})
+}
+
+func TestIndexNode_AdmissionPaused(t *testing.T) {
+	ctx := context.Background()
+	in, err := NewIndexNode(ctx)
+	assert.Nil(t, err)
+	in.probe.update(probeEtcdSession, nil)
+	in.probe.update(probeStorage, nil)
+	in.UpdateStateCode(internalpb.StateCode_Healthy)
+	in.sched.IndexBuildQueue = NewIndexBuildTaskQueue(in.sched)
+
+	resources := &fakeResources{used: 95, total: 100}
+	in.admission = resources.guard(watermarks{memoryHigh: 0.9, memoryLow: 0.8})
+	in.admission.check()
+
+	status, err := in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_NodeBusy, status.ErrorCode)
+	assert.Contains(t, status.Reason, "busy")
+	assert.True(t, in.sched.IndexBuildQueue.utEmpty())
+	// no rebuilds while paused
+	assert.False(t, in.isIdle())
+
+	resources.used = 50
+	in.admission.check()
+	assert.True(t, in.isIdle())
+	status, err = in.CreateIndex(ctx, &indexpb.CreateIndexRequest{IndexBuildID: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, commonpb.ErrorCode_Success, status.ErrorCode)
+
+	assert.Nil(t, in.Stop())
+}
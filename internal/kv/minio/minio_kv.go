@@ -12,6 +12,7 @@
 package miniokv
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"sync"
@@ -161,6 +162,66 @@ func (kv *MinIOKV) FGetObjects(keys []string, localPath string) error {
 	return nil
 }
 
+// FPutObject uploads the local file @localPath to minio with @key and user @metadata, files larger than @partSize
+// are uploaded in multiple parts of @partSize, 0 means the part size is decided by minio client.
+func (kv *MinIOKV) FPutObject(key, localPath string, partSize uint64, metadata map[string]string) error {
+	_, err := kv.minioClient.FPutObject(kv.ctx, kv.bucketName, key, localPath,
+		minio.PutObjectOptions{PartSize: partSize, UserMetadata: metadata})
+	return err
+}
+
+// PutObject uploads @size bytes read from @reader to minio with @key and user @metadata, objects larger than
+// @partSize are uploaded in multiple parts of @partSize while they are being read, so only a part is buffered at a
+// time, 0 means the part size is decided by minio client.
+func (kv *MinIOKV) PutObject(key string, reader io.Reader, size int64, partSize uint64, metadata map[string]string) error {
+	_, err := kv.minioClient.PutObject(kv.ctx, kv.bucketName, key, reader, size,
+		minio.PutObjectOptions{PartSize: partSize, UserMetadata: metadata})
+	return err
+}
+
+// GetObjectMetadata returns the user metadata of the object with @key.
+func (kv *MinIOKV) GetObjectMetadata(key string) (map[string]string, error) {
+	info, err := kv.minioClient.StatObject(kv.ctx, kv.bucketName, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return info.UserMetadata, nil
+}
+
+// GetObjectSize returns the size of the object with @key.
+func (kv *MinIOKV) GetObjectSize(key string) (int64, error) {
+	info, err := kv.minioClient.StatObject(kv.ctx, kv.bucketName, key, minio.StatObjectOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return info.Size, nil
+}
+
+// LoadRange loads @length bytes of the object with @key from @offset, the bytes till the end of the object are
+// loaded if the object is shorter.
+func (kv *MinIOKV) LoadRange(key string, offset, length int64) ([]byte, error) {
+	if length <= 0 {
+		return nil, fmt.Errorf("invalid length %d of the range to load", length)
+	}
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetRange(offset, offset+length-1); err != nil {
+		return nil, err
+	}
+	object, err := kv.minioClient.GetObject(kv.ctx, kv.bucketName, key, opts)
+	if object != nil {
+		defer object.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	_, err = io.Copy(buf, object)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // MultiLoad loads objects with multi @keys.
 func (kv *MinIOKV) MultiLoad(keys []string) ([]string, error) {
 	var resultErr error
@@ -190,6 +251,14 @@ func (kv *MinIOKV) Save(key, value string) error {
 	return err
 }
 
+// SaveWithMetadata saves object with @key to Minio along with the user @metadata. Object value is @value.
+func (kv *MinIOKV) SaveWithMetadata(key, value string, metadata map[string]string) error {
+	reader := strings.NewReader(value)
+	_, err := kv.minioClient.PutObject(kv.ctx, kv.bucketName, key, reader, int64(len(value)),
+		minio.PutObjectOptions{UserMetadata: metadata})
+	return err
+}
+
 // Save MultiObject, the path is the key of @kvs. The object value is the value
 // of @kvs.
 func (kv *MinIOKV) MultiSave(kvs map[string]string) error {
@@ -231,6 +300,11 @@ func (kv *MinIOKV) Remove(key string) error {
 	return err
 }
 
+// RemoveIncompleteUpload aborts the incomplete multipart uploads of the object with @key.
+func (kv *MinIOKV) RemoveIncompleteUpload(key string) error {
+	return kv.minioClient.RemoveIncompleteUpload(kv.ctx, kv.bucketName, key)
+}
+
 // MultiRemove delete a objects with @keys.
 func (kv *MinIOKV) MultiRemove(keys []string) error {
 	var resultErr error
@@ -16,6 +16,7 @@ import (
 	"io/ioutil"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	miniokv "github.com/milvus-io/milvus/internal/kv/minio"
@@ -236,6 +237,145 @@ func TestMinIOKV_FGetObject(t *testing.T) {
 	defer os.Remove(path + name2)
 }
 
+func TestMinIOKV_FPutObject(t *testing.T) {
+	Params.Init()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bucketName := "fantastic-tech-test"
+	MinIOKV, err := newMinIOKVClient(ctx, bucketName)
+	assert.Nil(t, err)
+	defer MinIOKV.RemoveWithPrefix("")
+
+	file, err := ioutil.TempFile("", "fput_object")
+	assert.Nil(t, err)
+	defer os.Remove(file.Name())
+	// larger than the part size, uploaded in multiple parts
+	value := strings.Repeat("a", 6*1024*1024)
+	_, err = file.WriteString(value)
+	assert.Nil(t, err)
+	err = file.Close()
+	assert.Nil(t, err)
+
+	key := "fput_object/key_1"
+	err = MinIOKV.FPutObject(key, file.Name(), 5*1024*1024, map[string]string{"Version": "1"})
+	assert.Nil(t, err)
+	loaded, err := MinIOKV.Load(key)
+	assert.Nil(t, err)
+	assert.Equal(t, value, loaded)
+	metadata, err := MinIOKV.GetObjectMetadata(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", metadata["Version"])
+
+	err = MinIOKV.FPutObject("fput_object/key_2", file.Name()+".not_exist", 0, nil)
+	assert.NotNil(t, err)
+}
+
+func TestMinIOKV_PutObject(t *testing.T) {
+	Params.Init()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bucketName := "fantastic-tech-test"
+	MinIOKV, err := newMinIOKVClient(ctx, bucketName)
+	assert.Nil(t, err)
+	defer MinIOKV.RemoveWithPrefix("")
+
+	// larger than the part size, uploaded in multiple parts
+	value := strings.Repeat("a", 6*1024*1024)
+	key := "put_object/key_1"
+	err = MinIOKV.PutObject(key, strings.NewReader(value), int64(len(value)), 5*1024*1024, map[string]string{"Version": "1"})
+	assert.Nil(t, err)
+	loaded, err := MinIOKV.Load(key)
+	assert.Nil(t, err)
+	assert.Equal(t, value, loaded)
+	metadata, err := MinIOKV.GetObjectMetadata(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", metadata["Version"])
+
+	// the reader shorter than the size
+	err = MinIOKV.PutObject("put_object/key_2", strings.NewReader("a"), 2, 0, nil)
+	assert.NotNil(t, err)
+}
+
+func TestMinIOKV_RemoveIncompleteUpload(t *testing.T) {
+	Params.Init()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bucketName := "fantastic-tech-test"
+	MinIOKV, err := newMinIOKVClient(ctx, bucketName)
+	assert.Nil(t, err)
+	defer MinIOKV.RemoveWithPrefix("")
+
+	// no incomplete upload of the object
+	err = MinIOKV.RemoveIncompleteUpload("remove_incomplete_upload/key_1")
+	assert.Nil(t, err)
+}
+
+func TestMinIOKV_SaveWithMetadata(t *testing.T) {
+	Params.Init()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bucketName := "fantastic-tech-test"
+	MinIOKV, err := newMinIOKVClient(ctx, bucketName)
+	assert.Nil(t, err)
+	defer MinIOKV.RemoveWithPrefix("")
+
+	key := "save_with_metadata/key_1"
+	err = MinIOKV.SaveWithMetadata(key, "value", map[string]string{"Engine-Version": "1", "Schema-Version": "2"})
+	assert.Nil(t, err)
+	loaded, err := MinIOKV.Load(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "value", loaded)
+
+	metadata, err := MinIOKV.GetObjectMetadata(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", metadata["Engine-Version"])
+	assert.Equal(t, "2", metadata["Schema-Version"])
+
+	_, err = MinIOKV.GetObjectMetadata("save_with_metadata/not_exist")
+	assert.NotNil(t, err)
+}
+
+func TestMinIOKV_LoadRange(t *testing.T) {
+	Params.Init()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bucketName := "fantastic-tech-test"
+	MinIOKV, err := newMinIOKVClient(ctx, bucketName)
+	assert.Nil(t, err)
+	defer MinIOKV.RemoveWithPrefix("")
+
+	key := "load_range/key_1"
+	err = MinIOKV.Save(key, "0123456789")
+	assert.Nil(t, err)
+
+	size, err := MinIOKV.GetObjectSize(key)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), size)
+
+	loaded, err := MinIOKV.LoadRange(key, 2, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, "234", string(loaded))
+	// the bytes till the end are loaded
+	loaded, err = MinIOKV.LoadRange(key, 8, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, "89", string(loaded))
+
+	_, err = MinIOKV.LoadRange(key, 0, 0)
+	assert.NotNil(t, err)
+	_, err = MinIOKV.GetObjectSize("load_range/not_exist")
+	assert.NotNil(t, err)
+}
+
 func TestMinIOKV_FGetObjects(t *testing.T) {
 	Params.Init()
 	path := "/tmp/milvus/data"
@@ -14,6 +14,8 @@
 package log
 
 import (
+	"sort"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -46,8 +48,19 @@ type Config struct {
 	Format string `toml:"format" json:"format"`
 	// Disable automatic timestamps in output.
 	DisableTimestamp bool `toml:"disable-timestamp" json:"disable-timestamp"`
+	// Format of the timestamps, one of rfc3339, rfc3339nano, iso8601, epoch, epochmillis, epochnanos, or a layout of
+	// time.Format, empty for the default format.
+	TimestampFormat string `toml:"timestamp-format" json:"timestamp-format"`
+	// Key of the timestamp of each entry, default is time.
+	TimeKey string `toml:"time-key" json:"time-key"`
+	// Key of the level of each entry, default is level.
+	LevelKey string `toml:"level-key" json:"level-key"`
+	// Fields attached to every entry, e.g. the role and the id of the node.
+	Fields map[string]string `toml:"fields" json:"fields"`
 	// File log config.
 	File FileLogConfig `toml:"file" json:"file"`
+	// Stdout keeps writing to stdout besides the log file, if the file log is enabled.
+	Stdout bool `toml:"stdout" json:"stdout"`
 	// Development puts the logger in development mode, which changes the
 	// behavior of DPanicLevel and takes stacktraces more liberally.
 	Development bool `toml:"development" json:"development"`
@@ -80,6 +93,42 @@ func newZapTextEncoder(cfg *Config) zapcore.Encoder {
 	return NewTextEncoder(cfg)
 }
 
+// timeEncoder returns the encoder of the timestamps of @format, see Config.TimestampFormat.
+func timeEncoder(format string) zapcore.TimeEncoder {
+	switch strings.ToLower(format) {
+	case "":
+		return DefaultTimeEncoder
+	case "rfc3339":
+		return zapcore.RFC3339TimeEncoder
+	case "rfc3339nano":
+		return zapcore.RFC3339NanoTimeEncoder
+	case "iso8601":
+		return zapcore.ISO8601TimeEncoder
+	case "epoch":
+		return zapcore.EpochTimeEncoder
+	case "epochmillis":
+		return zapcore.EpochMillisTimeEncoder
+	case "epochnanos":
+		return zapcore.EpochNanosTimeEncoder
+	default:
+		return zapcore.TimeEncoderOfLayout(format)
+	}
+}
+
+// fields returns the fields attached to every entry, sorted by the keys.
+func (cfg *Config) fields() []zap.Field {
+	keys := make([]string, 0, len(cfg.Fields))
+	for key := range cfg.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fields := make([]zap.Field, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, zap.String(key, cfg.Fields[key]))
+	}
+	return fields
+}
+
 func (cfg *Config) buildOptions(errSink zapcore.WriteSyncer) []zap.Option {
 	opts := []zap.Option{zap.ErrorOutput(errSink)}
 
@@ -99,6 +148,10 @@ func (cfg *Config) buildOptions(errSink zapcore.WriteSyncer) []zap.Option {
 		opts = append(opts, zap.AddStacktrace(stackLevel))
 	}
 
+	if len(cfg.Fields) > 0 {
+		opts = append(opts, zap.Fields(cfg.fields()...))
+	}
+
 	if cfg.Sampling != nil {
 		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
 			return zapcore.NewSamplerWithOptions(core, time.Second, cfg.Sampling.Initial, cfg.Sampling.Thereafter, zapcore.SamplerHook(cfg.Sampling.Hook))
@@ -56,7 +56,15 @@ func InitLogger(cfg *Config, opts ...zap.Option) (*zap.Logger, *ZapProperties, e
 		if err != nil {
 			return nil, nil, err
 		}
+		// lumberjack serializes the writes and the rotation, so the output is safe for concurrent use
 		output = zapcore.AddSync(lg)
+		if cfg.Stdout {
+			stdOut, _, err := zap.Open([]string{"stdout"}...)
+			if err != nil {
+				return nil, nil, err
+			}
+			output = zapcore.NewMultiWriteSyncer(stdOut, output)
+		}
 	} else {
 		stdOut, _, err := zap.Open([]string{"stdout"}...)
 		if err != nil {
@@ -28,9 +28,12 @@ package log
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -75,6 +78,83 @@ func TestZapTextEncoder(t *testing.T) {
 	assert.Equal(t, `[INFO] ["this is a message from zap"]`+"\n", buffer.String())
 }
 
+func TestZapConsoleEncoder(t *testing.T) {
+	conf := &Config{Level: "debug", Format: "console", File: FileLogConfig{}, DisableTimestamp: true}
+
+	var buffer bytes.Buffer
+	logger, _, err := InitLoggerWithWriteSyncer(conf, zapcore.AddSync(&buffer), zap.WithCaller(false))
+	assert.Nil(t, err)
+
+	logger.Info("this is a message from zap", zap.Int("age", 42))
+	assert.Equal(t, "INFO\tthis is a message from zap\t{\"age\": 42}\n", buffer.String())
+}
+
+func TestZapJSONEncoderConventions(t *testing.T) {
+	conf := &Config{
+		Level:           "debug",
+		Format:          "json",
+		TimestampFormat: "rfc3339nano",
+		TimeKey:         "@timestamp",
+		LevelKey:        "severity",
+		Fields:          map[string]string{"role": "indexnode", "nodeID": "7", "alias": "in-1"},
+	}
+	var buffer bytes.Buffer
+	logger, _, err := InitLoggerWithWriteSyncer(conf, zapcore.AddSync(&buffer))
+	assert.Nil(t, err)
+
+	before := time.Now()
+	logger.With(zap.String("module", "storage")).Info("downloaded the binlog", zap.Int("size", 42))
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Equal(t, 1, len(lines))
+
+	entry := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "INFO", entry["severity"])
+	assert.Equal(t, "downloaded the binlog", entry["message"])
+	assert.Equal(t, "indexnode", entry["role"])
+	assert.Equal(t, "7", entry["nodeID"])
+	assert.Equal(t, "in-1", entry["alias"])
+	assert.Equal(t, "storage", entry["module"])
+	assert.Equal(t, float64(42), entry["size"])
+	assert.NotContains(t, entry, "time")
+	assert.NotContains(t, entry, "level")
+	timestamp, err := time.Parse(time.RFC3339Nano, entry["@timestamp"].(string))
+	assert.Nil(t, err)
+	assert.False(t, timestamp.Before(before.Truncate(time.Second)))
+
+	// the layouts of time.Format and the epoch formats
+	buffer.Reset()
+	conf = &Config{Level: "debug", Format: "json", TimestampFormat: "epochmillis"}
+	logger, _, err = InitLoggerWithWriteSyncer(conf, zapcore.AddSync(&buffer))
+	assert.Nil(t, err)
+	logger.Info("epoch")
+	entry = make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &entry))
+	assert.IsType(t, float64(0), entry["time"])
+
+	buffer.Reset()
+	conf = &Config{Level: "debug", TimestampFormat: "2006-01-02"}
+	logger, _, err = InitLoggerWithWriteSyncer(conf, zapcore.AddSync(&buffer))
+	assert.Nil(t, err)
+	logger.Info("layout")
+	assert.True(t, strings.HasPrefix(buffer.String(), "["+time.Now().Format("2006-01-02")+"]"), buffer.String())
+}
+
+func TestFileAndStdoutLog(t *testing.T) {
+	tmpDir, _ := ioutil.TempDir("/tmp", "file-stdout-log-test")
+	defer os.RemoveAll(tmpDir)
+
+	conf := &Config{Level: "info", File: FileLogConfig{Filename: tmpDir + "/test.log"}, Stdout: true}
+	logger, p, err := InitLogger(conf)
+	assert.Nil(t, err)
+	assert.NotNil(t, p.Syncer)
+
+	logger.Info("this is a message to the file and stdout")
+	content, err := ioutil.ReadFile(tmpDir + "/test.log")
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "this is a message to the file and stdout")
+}
+
 func TestInvalidFileConfig(t *testing.T) {
 	tmpDir, _ := ioutil.TempDir("/tmp", "invalid-log-test")
 	defer os.RemoveAll(tmpDir)
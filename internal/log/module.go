@@ -0,0 +1,148 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// moduleLevel is the log level of a module, which follows the global level unless it's overridden.
+type moduleLevel struct {
+	overridden int32
+	level      zap.AtomicLevel
+}
+
+func (m *moduleLevel) enabled(l zapcore.Level) bool {
+	if atomic.LoadInt32(&m.overridden) == 1 {
+		return m.level.Enabled(l)
+	}
+	return L().Core().Enabled(l)
+}
+
+var (
+	modulesMu sync.RWMutex
+	modules   = make(map[string]*moduleLevel)
+)
+
+// moduleCore writes the entries enabled by the level of the module to the core of the global logger at the time
+// of writing, so that the module loggers follow ReplaceGlobals and the level changes once they are constructed.
+type moduleCore struct {
+	module *moduleLevel
+	fields []zapcore.Field
+}
+
+func (c *moduleCore) Enabled(l zapcore.Level) bool {
+	return c.module.enabled(l)
+}
+
+func (c *moduleCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &moduleCore{
+		module: c.module,
+		fields: make([]zapcore.Field, 0, len(c.fields)+len(fields)),
+	}
+	clone.fields = append(clone.fields, c.fields...)
+	clone.fields = append(clone.fields, fields...)
+	return clone
+}
+
+func (c *moduleCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *moduleCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	core := L().Core()
+	if len(c.fields) > 0 {
+		core = core.With(c.fields)
+	}
+	return core.Write(ent, fields)
+}
+
+func (c *moduleCore) Sync() error {
+	return L().Core().Sync()
+}
+
+// Module returns the logger of the module, whose level follows the global level until it's changed by
+// SetModuleLevel.
+func Module(name string) *zap.Logger {
+	modulesMu.Lock()
+	module, ok := modules[name]
+	if !ok {
+		module = &moduleLevel{level: zap.NewAtomicLevel()}
+		modules[name] = module
+	}
+	modulesMu.Unlock()
+	return zap.New(&moduleCore{module: module}, zap.AddCaller()).Named(name)
+}
+
+func getModule(name string) (*moduleLevel, error) {
+	modulesMu.RLock()
+	defer modulesMu.RUnlock()
+	module, ok := modules[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown log module: %s", name)
+	}
+	return module, nil
+}
+
+// SetModuleLevel overrides the logging level of the module.
+func SetModuleLevel(name string, l zapcore.Level) error {
+	module, err := getModule(name)
+	if err != nil {
+		return err
+	}
+	module.level.SetLevel(l)
+	atomic.StoreInt32(&module.overridden, 1)
+	return nil
+}
+
+// ResetModuleLevel makes the logging level of the module follow the global level again.
+func ResetModuleLevel(name string) error {
+	module, err := getModule(name)
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt32(&module.overridden, 0)
+	return nil
+}
+
+// GetModuleLevel gets the logging level of the module, and whether it overrides the global level.
+func GetModuleLevel(name string) (zapcore.Level, bool, error) {
+	module, err := getModule(name)
+	if err != nil {
+		return zapcore.InfoLevel, false, err
+	}
+	if atomic.LoadInt32(&module.overridden) == 1 {
+		return module.level.Level(), true, nil
+	}
+	return GetLevel(), false, nil
+}
+
+// Modules returns the sorted names of the modules.
+func Modules() []string {
+	modulesMu.RLock()
+	defer modulesMu.RUnlock()
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,76 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestModuleLevel(t *testing.T) {
+	oldL, oldP := L(), _globalP.Load().(*ZapProperties)
+	defer ReplaceGlobals(oldL, oldP)
+
+	// the module logger is constructed before the global logger it writes to
+	moduleLogger := Module("test.module").With(zap.String("field", "value"))
+	otherLogger := Module("test.other")
+
+	buf := &bytes.Buffer{}
+	conf := &Config{Level: "info", DisableTimestamp: true}
+	logger, p, err := InitLoggerWithWriteSyncer(conf, zapcore.AddSync(buf))
+	assert.NoError(t, err)
+	ReplaceGlobals(logger, p)
+
+	moduleLogger.Debug("hidden by the global level")
+	assert.Empty(t, buf.String())
+
+	assert.NoError(t, SetModuleLevel("test.module", zap.DebugLevel))
+	level, overridden, err := GetModuleLevel("test.module")
+	assert.NoError(t, err)
+	assert.True(t, overridden)
+	assert.Equal(t, zap.DebugLevel, level)
+
+	moduleLogger.Debug("shown by the module level")
+	otherLogger.Debug("hidden by the global level")
+	assert.Contains(t, buf.String(), "shown by the module level")
+	assert.Contains(t, buf.String(), "test.module")
+	assert.Contains(t, buf.String(), "[field=value]")
+	assert.NotContains(t, buf.String(), "hidden")
+
+	buf.Reset()
+	assert.NoError(t, SetModuleLevel("test.module", zap.ErrorLevel))
+	moduleLogger.Warn("hidden by the module level")
+	otherLogger.Warn("shown by the global level")
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), "shown by the global level")
+
+	buf.Reset()
+	assert.NoError(t, ResetModuleLevel("test.module"))
+	level, overridden, err = GetModuleLevel("test.module")
+	assert.NoError(t, err)
+	assert.False(t, overridden)
+	assert.Equal(t, zap.InfoLevel, level)
+	SetLevel(zap.DebugLevel)
+	moduleLogger.Debug("shown by the global level")
+	assert.Contains(t, buf.String(), "shown by the global level")
+
+	assert.Contains(t, Modules(), "test.module")
+	assert.Contains(t, Modules(), "test.other")
+	assert.Error(t, SetModuleLevel("test.unknown", zap.DebugLevel))
+	assert.Error(t, ResetModuleLevel("test.unknown"))
+	_, _, err = GetModuleLevel("test.unknown")
+	assert.Error(t, err)
+}
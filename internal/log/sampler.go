@@ -0,0 +1,156 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package log
+
+import (
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Sampler samples the debug and info entries of the hot log sites, e.g. the lines logged per chunk, so that the
+// verbose levels stay affordable. Of the entries of a site in a summary window, the first `initial` ones are
+// written, and then every `thereafter`-th one, the warn and error entries are never sampled. Summarize writes how
+// many entries are suppressed and starts the next window.
+type Sampler struct {
+	logger *zap.Logger
+	// summary writes the summaries, annotated with the caller of Summarize
+	summary *zap.Logger
+	// the counts are shared by the samplers derived by With
+	*samplerCounts
+}
+
+// samplerCounts is the rates of the sampling and the counts of the sites in the current window.
+type samplerCounts struct {
+	mu         sync.Mutex
+	initial    uint64
+	thereafter uint64
+	sites      map[string]*samplerSite
+}
+
+// samplerSite is the counts of the entries of a site in the current window.
+type samplerSite struct {
+	seen       uint64
+	suppressed uint64
+}
+
+// NewSampler returns the Sampler of the entries of @logger, the sampling is disabled if @initial is not positive.
+func NewSampler(logger *zap.Logger, initial int, thereafter int) *Sampler {
+	s := &Sampler{
+		// the callers of Debug and Info are annotated instead of the sampler
+		logger:        logger.WithOptions(zap.AddCallerSkip(2)),
+		summary:       logger,
+		samplerCounts: &samplerCounts{sites: make(map[string]*samplerSite)},
+	}
+	s.SetRates(initial, thereafter)
+	return s
+}
+
+// With returns the sampler attaching @fields to the entries, which shares the rates and the counts of the sites
+// with the sampler.
+func (s *Sampler) With(fields ...zap.Field) *Sampler {
+	return &Sampler{
+		logger:        s.logger.With(fields...),
+		summary:       s.summary,
+		samplerCounts: s.samplerCounts,
+	}
+}
+
+// SetRates changes the rates of the sampling, the sampling is disabled if @initial is not positive, and only the
+// first @initial entries of a window are written if @thereafter is not positive.
+func (s *Sampler) SetRates(initial int, thereafter int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.initial, s.thereafter = 0, 0
+	if initial > 0 {
+		s.initial = uint64(initial)
+	}
+	if thereafter > 0 {
+		s.thereafter = uint64(thereafter)
+	}
+}
+
+// Debug logs the entry of @site at debug if it's sampled.
+func (s *Sampler) Debug(site string, msg string, fields ...zap.Field) {
+	s.log(zapcore.DebugLevel, site, msg, fields)
+}
+
+// Info logs the entry of @site at info if it's sampled.
+func (s *Sampler) Info(site string, msg string, fields ...zap.Field) {
+	s.log(zapcore.InfoLevel, site, msg, fields)
+}
+
+// Warn logs the entry of @site at warn, which is never sampled.
+func (s *Sampler) Warn(site string, msg string, fields ...zap.Field) {
+	s.log(zapcore.WarnLevel, site, msg, fields)
+}
+
+// Error logs the entry of @site at error, which is never sampled.
+func (s *Sampler) Error(site string, msg string, fields ...zap.Field) {
+	s.log(zapcore.ErrorLevel, site, msg, fields)
+}
+
+func (s *Sampler) log(level zapcore.Level, site string, msg string, fields []zap.Field) {
+	// the entries disabled by the level are neither written nor counted
+	ce := s.logger.Check(level, msg)
+	if ce == nil || (level < zapcore.WarnLevel && !s.sample(site)) {
+		return
+	}
+	ce.Write(fields...)
+}
+
+// sample counts the entry of @site, and returns whether to write it.
+func (s *Sampler) sample(site string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.initial == 0 {
+		return true
+	}
+	counts, ok := s.sites[site]
+	if !ok {
+		counts = &samplerSite{}
+		s.sites[site] = counts
+	}
+	counts.seen++
+	if counts.seen <= s.initial || (s.thereafter > 0 && (counts.seen-s.initial)%s.thereafter == 0) {
+		return true
+	}
+	counts.suppressed++
+	return false
+}
+
+// Summarize writes the numbers of the suppressed entries of the sites at info, and resets the counts of the sites,
+// it returns the total number of the suppressed entries.
+func (s *Sampler) Summarize() uint64 {
+	s.mu.Lock()
+	sites := s.sites
+	s.sites = make(map[string]*samplerSite)
+	s.mu.Unlock()
+
+	names := make([]string, 0, len(sites))
+	for name, counts := range sites {
+		if counts.suppressed > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var total uint64
+	for _, name := range names {
+		counts := sites[name]
+		total += counts.suppressed
+		s.summary.Info("log sampling suppressed messages", zap.String("site", name),
+			zap.Uint64("suppressed", counts.suppressed), zap.Uint64("seen", counts.seen))
+	}
+	return total
+}
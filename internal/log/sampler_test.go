@@ -0,0 +1,102 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package log
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSampler(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	sampler := NewSampler(zap.New(core, zap.AddCaller()), 2, 3)
+
+	for i := 0; i < 10; i++ {
+		sampler.Debug("download", "downloaded the chunk", zap.Int("chunk", i))
+	}
+	sampler.Info("decode", "decoded the chunk")
+	// the 1st, 2nd, 5th and 8th entries of the site
+	entries := logs.FilterMessage("downloaded the chunk").AllUntimed()
+	assert.Equal(t, 4, len(entries))
+	for i, chunk := range []int64{0, 1, 4, 7} {
+		assert.Equal(t, chunk, entries[i].ContextMap()["chunk"])
+	}
+	// the callers are annotated instead of the sampler
+	assert.True(t, strings.HasSuffix(entries[0].Caller.File, "sampler_test.go"), entries[0].Caller.File)
+	assert.Equal(t, 1, logs.FilterMessage("decoded the chunk").Len())
+
+	// the warn and error entries are never sampled or counted
+	for i := 0; i < 5; i++ {
+		sampler.Warn("download", "failed to download the chunk")
+		sampler.Error("download", "failed to decode the chunk")
+	}
+	assert.Equal(t, 5, logs.FilterMessage("failed to download the chunk").Len())
+	assert.Equal(t, 5, logs.FilterMessage("failed to decode the chunk").Len())
+
+	logs.TakeAll()
+	assert.Equal(t, uint64(6), sampler.Summarize())
+	summaries := logs.TakeAll()
+	assert.Equal(t, 1, len(summaries))
+	assert.Equal(t, zapcore.InfoLevel, summaries[0].Level)
+	assert.Equal(t, "download", summaries[0].ContextMap()["site"])
+	assert.Equal(t, uint64(6), summaries[0].ContextMap()["suppressed"])
+	assert.Equal(t, uint64(10), summaries[0].ContextMap()["seen"])
+
+	// the derived samplers share the counts
+	derived := sampler.With(zap.String("requestID", "r-1"))
+	for i := 0; i < 3; i++ {
+		sampler.Debug("chunk", "added the chunk")
+		derived.Debug("chunk", "added the chunk")
+	}
+	chunks := logs.FilterMessage("added the chunk").AllUntimed()
+	assert.Equal(t, 3, len(chunks))
+	assert.Equal(t, "r-1", chunks[1].ContextMap()["requestID"])
+	assert.Equal(t, uint64(3), sampler.Summarize())
+	logs.TakeAll()
+
+	// the next window starts over, and nothing is summarized without the suppressed entries
+	sampler.Debug("download", "downloaded the chunk")
+	assert.Equal(t, 1, logs.Len())
+	assert.Equal(t, uint64(0), sampler.Summarize())
+	assert.Equal(t, 1, logs.Len())
+}
+
+func TestSampler_Rates(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	sampler := NewSampler(zap.New(core), 0, 10)
+
+	// disabled
+	for i := 0; i < 5; i++ {
+		sampler.Info("chunk", "added the chunk")
+	}
+	assert.Equal(t, 5, logs.Len())
+	assert.Equal(t, uint64(0), sampler.Summarize())
+
+	// only the initial entries
+	logs.TakeAll()
+	sampler.SetRates(1, 0)
+	for i := 0; i < 5; i++ {
+		sampler.Info("chunk", "added the chunk")
+	}
+	assert.Equal(t, 1, logs.Len())
+
+	// the entries disabled by the level are not counted
+	for i := 0; i < 5; i++ {
+		sampler.Debug("chunk", "added the chunk")
+	}
+	assert.Equal(t, uint64(4), sampler.Summarize())
+}
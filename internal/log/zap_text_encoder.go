@@ -147,10 +147,16 @@ func NewTextEncoder(cfg *Config) zapcore.Encoder {
 		StacktraceKey:  "stack",
 		LineEnding:     zapcore.DefaultLineEnding,
 		EncodeLevel:    zapcore.CapitalLevelEncoder,
-		EncodeTime:     DefaultTimeEncoder,
+		EncodeTime:     timeEncoder(cfg.TimestampFormat),
 		EncodeDuration: zapcore.StringDurationEncoder,
 		EncodeCaller:   ShortCallerEncoder,
 	}
+	if cfg.TimeKey != "" {
+		cc.TimeKey = cfg.TimeKey
+	}
+	if cfg.LevelKey != "" {
+		cc.LevelKey = cfg.LevelKey
+	}
 	if cfg.DisableTimestamp {
 		cc.TimeKey = ""
 	}
@@ -164,6 +170,8 @@ func NewTextEncoder(cfg *Config) zapcore.Encoder {
 		}
 	case "json":
 		return zapcore.NewJSONEncoder(cc)
+	case "console":
+		return zapcore.NewConsoleEncoder(cc)
 	default:
 		panic(fmt.Sprintf("unsupport log format: %s", cfg.Format))
 	}
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/milvus-io/milvus/internal/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -15,6 +16,7 @@ const (
 	subSystemRootCoord = "rootcoord"
 	subSystemDataCoord = "dataCoord"
 	subSystemDataNode  = "dataNode"
+	subSystemIndexNode = "indexnode"
 	subSystemProxy     = "proxy"
 )
 
@@ -179,7 +181,7 @@ var (
 		})
 )
 
-//RegisterRootCoord register RootCoord metrics
+// RegisterRootCoord register RootCoord metrics
 func RegisterRootCoord() {
 	prometheus.MustRegister(RootCoordProxyLister)
 
@@ -522,7 +524,7 @@ var (
 		}, []string{"pchan"})
 )
 
-//RegisterProxy register Proxy metrics
+// RegisterProxy register Proxy metrics
 func RegisterProxy() {
 	prometheus.MustRegister(ProxyCreateCollectionCounter)
 	prometheus.MustRegister(ProxyDropCollectionCounter)
@@ -571,12 +573,12 @@ func RegisterProxy() {
 	prometheus.MustRegister(ProxyDmlChannelTimeTick)
 }
 
-//RegisterQueryCoord register QueryCoord metrics
+// RegisterQueryCoord register QueryCoord metrics
 func RegisterQueryCoord() {
 
 }
 
-//RegisterQueryNode register QueryNode metrics
+// RegisterQueryNode register QueryNode metrics
 func RegisterQueryNode() {
 
 }
@@ -593,7 +595,7 @@ var (
 	)
 )
 
-//RegisterDataCoord register DataCoord metrics
+// RegisterDataCoord register DataCoord metrics
 func RegisterDataCoord() {
 	prometheus.MustRegister(DataCoordDataNodeList)
 }
@@ -618,28 +620,301 @@ var (
 		}, []string{"type"})
 )
 
-//RegisterDataNode register DataNode metrics
+// RegisterDataNode register DataNode metrics
 func RegisterDataNode() {
 	prometheus.MustRegister(DataNodeFlushSegmentsCounter)
 	prometheus.MustRegister(DataNodeWatchDmChannelsCounter)
 }
 
-//RegisterIndexCoord register IndexCoord metrics
+// RegisterIndexCoord register IndexCoord metrics
 func RegisterIndexCoord() {
 
 }
 
-//RegisterIndexNode register IndexNode metrics
+var (
+	// IndexNodeBuildPeakMemory records the peak memory growth of the index builds
+	IndexNodeBuildPeakMemory = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "build_peak_memory_bytes",
+			Help:      "Peak memory growth of the index builds in bytes",
+			// 16MB to 64GB
+			Buckets: prometheus.ExponentialBuckets(16*1024*1024, 2, 13),
+		}, []string{"index_type"})
+
+	// IndexNodeAdmissionPaused is 1 if the admission of new tasks is paused by the watermark of the resource
+	IndexNodeAdmissionPaused = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "admission_paused",
+			Help:      "Whether the admission of new tasks is paused by the watermark of the resource",
+		}, []string{"resource"})
+
+	// IndexNodeTaskCounter counts the finished index build tasks by the status
+	IndexNodeTaskCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "task_total",
+			Help:      "Counter of the finished index build tasks",
+		}, []string{"status"})
+
+	// IndexNodeBuildDuration records the durations of the completed index build tasks
+	IndexNodeBuildDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "build_duration_seconds",
+			Help:      "Durations of the completed index build tasks in seconds",
+			// 1s to about 9h
+			Buckets: prometheus.ExponentialBuckets(1, 2, 16),
+		}, []string{"index_type"})
+
+	// IndexNodeStorageLatency records the latencies of loading the binlogs and saving the index files
+	IndexNodeStorageLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "storage_latency_seconds",
+			Help:      "Latencies of loading the binlogs and saving the index files of the tasks in seconds",
+			// 10ms to about 5min
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 16),
+		}, []string{"operation"})
+
+	// IndexNodeEtcdLatency records the latencies of the etcd operations of IndexNode, the keys are never the labels
+	IndexNodeEtcdLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "etcd_latency_seconds",
+			Help:      "Latencies of the etcd operations in seconds",
+			// 1ms to about 16s
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 15),
+		}, []string{"operation"})
+
+	// IndexNodeEtcdBytes counts the bytes of the values loaded from and saved to etcd by IndexNode
+	IndexNodeEtcdBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "etcd_bytes_total",
+			Help:      "Bytes of the values of the etcd operations",
+		}, []string{"operation"})
+
+	// IndexNodeEtcdErrors counts the failed etcd operations of IndexNode by the category of the error
+	IndexNodeEtcdErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "etcd_errors_total",
+			Help:      "Counter of the failed etcd operations",
+		}, []string{"operation", "category"})
+
+	// IndexNodeEngineThreadUtilization records the ratio of the cpu time of the builds to the wall time by all the
+	// build threads of the index engine, collected only if indexNode.engineStats.enabled
+	IndexNodeEngineThreadUtilization = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "engine_thread_utilization_ratio",
+			Help:      "Ratio of the cpu time of the index builds to the wall time by all the build threads of the engine",
+			Buckets:   prometheus.LinearBuckets(0.1, 0.1, 10),
+		}, []string{"index_type"})
+
+	// IndexNodeEnginePhaseDuration records the durations of the phases of the builds in the index engine, collected
+	// only if indexNode.engineStats.enabled
+	IndexNodeEnginePhaseDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "engine_phase_duration_seconds",
+			Help:      "Durations of the phases of the index builds in the engine in seconds",
+			// 10ms to about 11h
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 23),
+		}, []string{"index_type", "phase"})
+
+	// IndexNodeBuildStageDuration records the time spent in each of the stages of the finished index build tasks,
+	// the failed and cancelled tasks record the stages they entered
+	IndexNodeBuildStageDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "build_stage_duration_seconds",
+			Help:      "Time spent in each of the stages of the finished index build tasks in seconds",
+			// 10ms to about 11h
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 23),
+		}, []string{"index_type", "stage", "status"})
+
+	// IndexNodeLastBuildStageDuration is the time spent in each of the stages of the last finished index build task,
+	// it's 0 for the stages the task never entered
+	IndexNodeLastBuildStageDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "last_build_stage_duration_seconds",
+			Help:      "Time spent in each of the stages of the last finished index build task in seconds",
+		}, []string{"stage"})
+
+	// IndexNodeThrottledRequests counts the requests rejected by the rate limits of the methods
+	IndexNodeThrottledRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "throttled_requests_total",
+			Help:      "Number of the requests rejected by the rate limits",
+		}, []string{"method"})
+
+	// IndexNodeBenchmarkResult is the measures of the last build benchmark of each index type
+	IndexNodeBenchmarkResult = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "benchmark_result",
+			Help:      "Measures of the last build benchmark of each index type",
+		}, []string{"index_type", "measure"})
+
+	// IndexNodeSimdTypeMismatch is 1 if the engine uses another simd type than the configured one
+	IndexNodeSimdTypeMismatch = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "simd_type_mismatch",
+			Help:      "Whether the engine uses another simd type than the configured one",
+		}, []string{"configured_simd_type", "effective_simd_type"})
+)
+
+// the label values of IndexNode metrics
+const (
+	IndexNodeTaskSucceeded = "succeeded"
+	IndexNodeTaskFailed    = "failed"
+	IndexNodeTaskCancelled = "cancelled"
+
+	IndexNodeStorageLoad = "load"
+	IndexNodeStorageSave = "save"
+
+	IndexNodeQueueUnissued = "unissued"
+	IndexNodeQueueActive   = "active"
+
+	IndexNodeEtcdLoad             = "load"
+	IndexNodeEtcdLoadWithPrefix   = "load_with_prefix"
+	IndexNodeEtcdSave             = "save"
+	IndexNodeEtcdMultiSave        = "multi_save"
+	IndexNodeEtcdRemove           = "remove"
+	IndexNodeEtcdRemoveWithPrefix = "remove_with_prefix"
+	IndexNodeEtcdCompareAndSwap   = "compare_and_swap"
+
+	IndexNodeEtcdErrorTimeout   = "timeout"
+	IndexNodeEtcdErrorCompacted = "compacted"
+	IndexNodeEtcdErrorTooLarge  = "too_large"
+	IndexNodeEtcdErrorNotFound  = "not_found"
+	IndexNodeEtcdErrorConflict  = "conflict"
+	IndexNodeEtcdErrorOther     = "other"
+
+	IndexNodeEnginePhaseTrain = "train"
+	IndexNodeEnginePhaseAdd   = "add"
+	IndexNodeEnginePhaseBuild = "build"
+
+	IndexNodeBenchmarkBuildSeconds       = "build_seconds"
+	IndexNodeBenchmarkBuildRowsPerSecond = "build_rows_per_second"
+	IndexNodeBenchmarkUploadSeconds      = "upload_seconds"
+	IndexNodeBenchmarkUploadMBPerSecond  = "upload_mb_per_second"
+)
+
+func indexNodeCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		IndexNodeBuildPeakMemory,
+		IndexNodeAdmissionPaused,
+		IndexNodeTaskCounter,
+		IndexNodeBuildDuration,
+		IndexNodeStorageLatency,
+		IndexNodeEtcdLatency,
+		IndexNodeEtcdBytes,
+		IndexNodeEtcdErrors,
+		IndexNodeEngineThreadUtilization,
+		IndexNodeEnginePhaseDuration,
+		IndexNodeBuildStageDuration,
+		IndexNodeLastBuildStageDuration,
+		IndexNodeThrottledRequests,
+		IndexNodeBenchmarkResult,
+		IndexNodeSimdTypeMismatch,
+	}
+}
+
+// registerOnce registers the collector, it's fine if the collector is already registered.
+func registerOnce(r prometheus.Registerer, c prometheus.Collector) {
+	if err := r.Register(c); err != nil {
+		if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+			panic(err)
+		}
+	}
+}
+
+// RegisterIndexNode register IndexNode metrics
 func RegisterIndexNode() {
+	for _, c := range indexNodeCollectors() {
+		registerOnce(prometheus.DefaultRegisterer, c)
+	}
+}
+
+// NewIndexNodeQueueDepth returns the gauge of the depth of the queue of IndexNode reported by @depth.
+func NewIndexNodeQueueDepth(queue string, depth func() float64) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace:   milvusNamespace,
+			Subsystem:   subSystemIndexNode,
+			Name:        "queue_depth",
+			Help:        "Number of the tasks in the queue",
+			ConstLabels: prometheus.Labels{"queue": queue},
+		}, depth)
+}
+
+// NewIndexNodeMemoryUsage returns the gauge of the memory usage ratio of IndexNode reported by @usage, which is
+// the budget guarded by the memory watermark.
+func NewIndexNodeMemoryUsage(usage func() float64) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "memory_usage_ratio",
+			Help:      "Ratio of the used memory to the total memory of the node",
+		}, usage)
+}
+
+// NewIndexNodeSessionLeaseTTL returns the gauge of the remaining time to live of the lease of the etcd session of
+// IndexNode reported by @ttl.
+func NewIndexNodeSessionLeaseTTL(ttl func() float64) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: milvusNamespace,
+			Subsystem: subSystemIndexNode,
+			Name:      "session_lease_ttl_seconds",
+			Help:      "Remaining time to live of the lease of the etcd session in seconds, -1 if expired",
+		}, ttl)
+}
 
+// NewIndexNodeRegistry returns a registry of the metrics of the IndexNode, the Go runtime and the process metrics
+// are prefixed with milvus_indexnode_, and all of the metrics take the node_id label. Each IndexNode owns its
+// registry, so that multiple nodes can be created in one process.
+func NewIndexNodeRegistry(nodeID int64, collectors ...prometheus.Collector) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	labeled := prometheus.WrapRegistererWith(prometheus.Labels{"node_id": strconv.FormatInt(nodeID, 10)}, registry)
+	runtime := prometheus.WrapRegistererWithPrefix(milvusNamespace+"_"+subSystemIndexNode+"_", labeled)
+	registerOnce(runtime, prometheus.NewGoCollector())
+	registerOnce(runtime, prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+	for _, c := range append(indexNodeCollectors(), collectors...) {
+		registerOnce(labeled, c)
+	}
+	return registry
 }
 
-//RegisterMsgStreamCoord register MsgStreamCoord metrics
+// RegisterMsgStreamCoord register MsgStreamCoord metrics
 func RegisterMsgStreamCoord() {
 
 }
 
-//ServeHTTP serve prometheus http service
+// ServeHTTP serve prometheus http service
 func ServeHTTP() {
 	http.Handle("/metrics", promhttp.Handler())
 	go func() {
@@ -10,6 +10,8 @@ func TestRegisterMetrics(t *testing.T) {
 	RegisterDataNode()
 	RegisterDataCoord()
 	RegisterIndexNode()
+	// idempotent
+	RegisterIndexNode()
 	RegisterIndexCoord()
 	RegisterProxy()
 	RegisterQueryNode()
@@ -51,6 +51,8 @@ const (
 	ErrorCode_EmptyCollection       ErrorCode = 26
 	// internal error code.
 	ErrorCode_DDRequestRace ErrorCode = 1000
+	// the node rejects the request since it is busy, the request may be retried later or on another node
+	ErrorCode_NodeBusy ErrorCode = 1001
 )
 
 var ErrorCode_name = map[int32]string{
@@ -81,6 +83,7 @@ var ErrorCode_name = map[int32]string{
 	25:   "IndexNotExist",
 	26:   "EmptyCollection",
 	1000: "DDRequestRace",
+	1001: "NodeBusy",
 }
 
 var ErrorCode_value = map[string]int32{
@@ -111,6 +114,7 @@ var ErrorCode_value = map[string]int32{
 	"IndexNotExist":         25,
 	"EmptyCollection":       26,
 	"DDRequestRace":         1000,
+	"NodeBusy":              1001,
 }
 
 func (x ErrorCode) String() string {
@@ -404,11 +408,13 @@ func (DslType) EnumDescriptor() ([]byte, []int) {
 }
 
 type Status struct {
-	ErrorCode            ErrorCode `protobuf:"varint,1,opt,name=error_code,json=errorCode,proto3,enum=milvus.proto.common.ErrorCode" json:"error_code,omitempty"`
-	Reason               string    `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+	ErrorCode ErrorCode `protobuf:"varint,1,opt,name=error_code,json=errorCode,proto3,enum=milvus.proto.common.ErrorCode" json:"error_code,omitempty"`
+	Reason    string    `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	// the id of the request the status responds to, which is echoed by the servers tracking the requests
+	RequestId            string   `protobuf:"bytes,3,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Status) Reset()         { *m = Status{} }
@@ -450,6 +456,13 @@ func (m *Status) GetReason() string {
 	return ""
 }
 
+func (m *Status) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
 type KeyValuePair struct {
 	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
@@ -800,90 +813,92 @@ func init() {
 func init() { proto.RegisterFile("common.proto", fileDescriptor_555bd8c177793206) }
 
 var fileDescriptor_555bd8c177793206 = []byte{
-	// 1353 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0xd9, 0x6e, 0x1b, 0x37,
-	0x17, 0xf6, 0x68, 0x64, 0xcb, 0xa2, 0x65, 0x9b, 0xa6, 0x97, 0x38, 0xf9, 0x8d, 0x1f, 0x81, 0xae,
-	0x02, 0x03, 0xb1, 0xff, 0xbf, 0x41, 0xdb, 0xab, 0x5c, 0xd8, 0x1a, 0x2f, 0x42, 0xe2, 0xa5, 0x23,
-	0x27, 0x2d, 0x7a, 0xd1, 0x80, 0x9e, 0x39, 0x92, 0xd8, 0xcc, 0x90, 0x2a, 0x49, 0x39, 0xd6, 0x5b,
-	0xb4, 0x79, 0x8e, 0xb6, 0xe8, 0x92, 0x2e, 0xe8, 0x13, 0x74, 0xbf, 0xee, 0x23, 0xf4, 0x01, 0xba,
-	0x66, 0x2d, 0x0e, 0x67, 0x24, 0x4d, 0x80, 0xe4, 0xaa, 0x77, 0x3c, 0x1f, 0x0f, 0x3f, 0x7e, 0xfc,
-	0xce, 0x21, 0x67, 0x48, 0x2d, 0x52, 0x69, 0xaa, 0xe4, 0x46, 0x4f, 0x2b, 0xab, 0xd8, 0x62, 0x2a,
-	0x92, 0xb3, 0xbe, 0xc9, 0xa2, 0x8d, 0x6c, 0xaa, 0x7e, 0x87, 0x4c, 0xb5, 0x2c, 0xb7, 0x7d, 0xc3,
-	0xae, 0x13, 0x02, 0x5a, 0x2b, 0x7d, 0x27, 0x52, 0x31, 0xac, 0x7a, 0x97, 0xbd, 0x2b, 0x73, 0xaf,
-	0xfc, 0x77, 0xe3, 0x05, 0x6b, 0x36, 0x76, 0x30, 0xad, 0xa1, 0x62, 0x08, 0xab, 0x30, 0x1c, 0xb2,
-	0x15, 0x32, 0xa5, 0x81, 0x1b, 0x25, 0x57, 0x4b, 0x97, 0xbd, 0x2b, 0xd5, 0x30, 0x8f, 0xea, 0xaf,
-	0x91, 0xda, 0x0d, 0x18, 0xdc, 0xe6, 0x49, 0x1f, 0x8e, 0xb9, 0xd0, 0x8c, 0x12, 0xff, 0x2e, 0x0c,
-	0x1c, 0x7f, 0x35, 0xc4, 0x21, 0x5b, 0x22, 0x93, 0x67, 0x38, 0x9d, 0x2f, 0xcc, 0x82, 0xfa, 0x35,
-	0x32, 0x73, 0x03, 0x06, 0x01, 0xb7, 0xfc, 0x25, 0xcb, 0x18, 0x29, 0xc7, 0xdc, 0x72, 0xb7, 0xaa,
-	0x16, 0xba, 0x71, 0x7d, 0x8d, 0x94, 0xb7, 0x13, 0x75, 0x3a, 0xa6, 0xf4, 0xdc, 0x64, 0x4e, 0x79,
-	0x95, 0x54, 0xb6, 0xe2, 0x58, 0x83, 0x31, 0x6c, 0x8e, 0x94, 0x44, 0x2f, 0x67, 0x2b, 0x89, 0x1e,
-	0x92, 0xf5, 0x94, 0xb6, 0x8e, 0xcc, 0x0f, 0xdd, 0xb8, 0x7e, 0xdf, 0x23, 0x95, 0x03, 0xd3, 0xd9,
-	0xe6, 0x06, 0xd8, 0xeb, 0x64, 0x3a, 0x35, 0x9d, 0x3b, 0x76, 0xd0, 0x1b, 0x5a, 0xb3, 0xf6, 0x42,
-	0x6b, 0x0e, 0x4c, 0xe7, 0x64, 0xd0, 0x83, 0xb0, 0x92, 0x66, 0x03, 0x54, 0x92, 0x9a, 0x4e, 0x33,
-	0xc8, 0x99, 0xb3, 0x80, 0xad, 0x91, 0xaa, 0x15, 0x29, 0x18, 0xcb, 0xd3, 0xde, 0xaa, 0x7f, 0xd9,
-	0xbb, 0x52, 0x0e, 0xc7, 0x00, 0xbb, 0x44, 0xa6, 0x8d, 0xea, 0xeb, 0x08, 0x9a, 0xc1, 0x6a, 0xd9,
-	0x2d, 0x1b, 0xc5, 0xf5, 0xeb, 0xa4, 0x7a, 0x60, 0x3a, 0xfb, 0xc0, 0x63, 0xd0, 0xec, 0x7f, 0xa4,
-	0x7c, 0xca, 0x4d, 0xa6, 0x68, 0xe6, 0xe5, 0x8a, 0xf0, 0x04, 0xa1, 0xcb, 0xac, 0xbf, 0x43, 0x6a,
-	0xc1, 0xc1, 0xcd, 0x7f, 0xc1, 0x80, 0xd2, 0x4d, 0x97, 0xeb, 0xf8, 0x90, 0xa7, 0xc3, 0x8a, 0x8d,
-	0x81, 0xf5, 0x6f, 0xca, 0xa4, 0x3a, 0x6a, 0x0f, 0x36, 0x43, 0x2a, 0xad, 0x7e, 0x14, 0x81, 0x31,
-	0x74, 0x82, 0x2d, 0x92, 0xf9, 0x5b, 0x12, 0xce, 0x7b, 0x10, 0x59, 0x88, 0x5d, 0x0e, 0xf5, 0xd8,
-	0x02, 0x99, 0x6d, 0x28, 0x29, 0x21, 0xb2, 0xbb, 0x5c, 0x24, 0x10, 0xd3, 0x12, 0x5b, 0x22, 0xf4,
-	0x18, 0x74, 0x2a, 0x8c, 0x11, 0x4a, 0x06, 0x20, 0x05, 0xc4, 0xd4, 0x67, 0x17, 0xc8, 0x62, 0x43,
-	0x25, 0x09, 0x44, 0x56, 0x28, 0x79, 0xa8, 0xec, 0xce, 0xb9, 0x30, 0xd6, 0xd0, 0x32, 0xd2, 0x36,
-	0x93, 0x04, 0x3a, 0x3c, 0xd9, 0xd2, 0x9d, 0x7e, 0x0a, 0xd2, 0xd2, 0x49, 0xe4, 0xc8, 0xc1, 0x40,
-	0xa4, 0x20, 0x91, 0x89, 0x56, 0x0a, 0x68, 0x53, 0xc6, 0x70, 0x8e, 0xf5, 0xa1, 0xd3, 0xec, 0x22,
-	0x59, 0xce, 0xd1, 0xc2, 0x06, 0x3c, 0x05, 0x5a, 0x65, 0xf3, 0x64, 0x26, 0x9f, 0x3a, 0x39, 0x3a,
-	0xbe, 0x41, 0x49, 0x81, 0x21, 0x54, 0xf7, 0x42, 0x88, 0x94, 0x8e, 0xe9, 0x4c, 0x41, 0xc2, 0x6d,
-	0x88, 0xac, 0xd2, 0xcd, 0x80, 0xd6, 0x50, 0x70, 0x0e, 0xb6, 0x80, 0xeb, 0xa8, 0x1b, 0x82, 0xe9,
-	0x27, 0x96, 0xce, 0x32, 0x4a, 0x6a, 0xbb, 0x22, 0x81, 0x43, 0x65, 0x77, 0x55, 0x5f, 0xc6, 0x74,
-	0x8e, 0xcd, 0x11, 0x72, 0x00, 0x96, 0xe7, 0x0e, 0xcc, 0xe3, 0xb6, 0x0d, 0x1e, 0x75, 0x21, 0x07,
-	0x28, 0x5b, 0x21, 0xac, 0xc1, 0xa5, 0x54, 0xb6, 0xa1, 0x81, 0x5b, 0xd8, 0x55, 0x49, 0x0c, 0x9a,
-	0x2e, 0xa0, 0x9c, 0xe7, 0x70, 0x91, 0x00, 0x65, 0xe3, 0xec, 0x00, 0x12, 0x18, 0x65, 0x2f, 0x8e,
-	0xb3, 0x73, 0x1c, 0xb3, 0x97, 0x50, 0xfc, 0x76, 0x5f, 0x24, 0xb1, 0xb3, 0x24, 0x2b, 0xcb, 0x32,
-	0x6a, 0xcc, 0xc5, 0x1f, 0xde, 0x6c, 0xb6, 0x4e, 0xe8, 0x0a, 0x5b, 0x26, 0x0b, 0x39, 0x72, 0x00,
-	0x56, 0x8b, 0xc8, 0x99, 0x77, 0x01, 0xa5, 0x1e, 0xf5, 0xed, 0x51, 0xfb, 0x00, 0x52, 0xa5, 0x07,
-	0x74, 0x15, 0x0b, 0xea, 0x98, 0x86, 0x25, 0xa2, 0x17, 0x71, 0x87, 0x9d, 0xb4, 0x67, 0x07, 0x63,
-	0x7b, 0xe9, 0x25, 0xc6, 0xc8, 0x6c, 0x10, 0x84, 0xf0, 0x5e, 0x1f, 0x8c, 0x0d, 0x79, 0x04, 0xf4,
-	0xd7, 0xca, 0xfa, 0x5b, 0x84, 0xb8, 0xb5, 0xf8, 0x20, 0x01, 0x63, 0x64, 0x6e, 0x1c, 0x1d, 0x2a,
-	0x09, 0x74, 0x82, 0xd5, 0xc8, 0xf4, 0x2d, 0x29, 0x8c, 0xe9, 0x43, 0x4c, 0x3d, 0xf4, 0xad, 0x29,
-	0x8f, 0xb5, 0xea, 0xe0, 0x95, 0xa6, 0x25, 0x9c, 0xdd, 0x15, 0x52, 0x98, 0xae, 0xeb, 0x18, 0x42,
-	0xa6, 0x72, 0x03, 0xcb, 0xeb, 0x6d, 0x52, 0x6b, 0x41, 0x07, 0x9b, 0x23, 0xe3, 0x5e, 0x22, 0xb4,
-	0x18, 0x8f, 0xd9, 0x47, 0xb2, 0x3d, 0x6c, 0xde, 0x3d, 0xad, 0xee, 0x09, 0xd9, 0xa1, 0x25, 0x24,
-	0x6b, 0x01, 0x4f, 0x1c, 0xf1, 0x0c, 0xa9, 0xec, 0x26, 0x7d, 0xb7, 0x4b, 0xd9, 0xed, 0x89, 0x01,
-	0xa6, 0x4d, 0xae, 0x3f, 0x98, 0x76, 0x4f, 0x86, 0xbb, 0xf9, 0xb3, 0xa4, 0x7a, 0x4b, 0xc6, 0xd0,
-	0x16, 0x12, 0x62, 0x3a, 0xe1, 0xdc, 0x77, 0x55, 0x2a, 0xd8, 0x10, 0xe3, 0x21, 0x03, 0xad, 0x7a,
-	0x05, 0x0c, 0xd0, 0xc2, 0x7d, 0x6e, 0x0a, 0x50, 0x1b, 0x4b, 0x1a, 0x80, 0x89, 0xb4, 0x38, 0x2d,
-	0x2e, 0xef, 0xa0, 0xb5, 0xad, 0xae, 0xba, 0x37, 0xc6, 0x0c, 0xed, 0xe2, 0x4e, 0x7b, 0x60, 0x5b,
-	0x03, 0x63, 0x21, 0x6d, 0x28, 0xd9, 0x16, 0x1d, 0x43, 0x05, 0xee, 0x74, 0x53, 0xf1, 0xb8, 0xb0,
-	0xfc, 0x5d, 0x2c, 0x6a, 0x08, 0x09, 0x70, 0x53, 0x64, 0xbd, 0xeb, 0xfa, 0xcf, 0x49, 0xdd, 0x4a,
-	0x04, 0x37, 0x34, 0xc1, 0xa3, 0xa0, 0xca, 0x2c, 0x4c, 0xd1, 0xf7, 0xad, 0xc4, 0x82, 0xce, 0x62,
-	0xc9, 0x96, 0xc8, 0x7c, 0x96, 0x7f, 0xcc, 0xb5, 0x15, 0x8e, 0xe4, 0x5b, 0xcf, 0x55, 0x58, 0xab,
-	0xde, 0x18, 0xfb, 0x0e, 0xaf, 0x7b, 0x6d, 0x9f, 0x9b, 0x31, 0xf4, 0xbd, 0xc7, 0x56, 0xc8, 0xc2,
-	0xf0, 0x68, 0x63, 0xfc, 0x07, 0x8f, 0x2d, 0x92, 0x39, 0x3c, 0xda, 0x08, 0x33, 0xf4, 0x47, 0x07,
-	0xe2, 0x21, 0x0a, 0xe0, 0x4f, 0x8e, 0x21, 0x3f, 0x45, 0x01, 0xff, 0xd9, 0x6d, 0x86, 0x0c, 0x79,
-	0xa1, 0x0d, 0x7d, 0xe8, 0xa1, 0xd2, 0xe1, 0x66, 0x39, 0x4c, 0x1f, 0xb9, 0x44, 0x64, 0x1d, 0x25,
-	0x3e, 0x76, 0x89, 0x39, 0xe7, 0x08, 0x7d, 0xe2, 0xd0, 0x7d, 0x2e, 0x63, 0xd5, 0x6e, 0x8f, 0xd0,
-	0xa7, 0x1e, 0x5b, 0x25, 0x8b, 0xb8, 0x7c, 0x9b, 0x27, 0x5c, 0x46, 0xe3, 0xfc, 0x67, 0x1e, 0xa3,
-	0x43, 0x23, 0x5d, 0x23, 0xd3, 0x0f, 0x4b, 0xce, 0x94, 0x5c, 0x40, 0x86, 0x7d, 0x54, 0x62, 0x73,
-	0x99, 0xbb, 0x59, 0xfc, 0x71, 0x89, 0xcd, 0x90, 0xa9, 0xa6, 0x34, 0xa0, 0x2d, 0x7d, 0x1f, 0x9b,
-	0x6d, 0x2a, 0xbb, 0xae, 0xf4, 0x03, 0x6c, 0xe9, 0x49, 0xd7, 0x6c, 0xf4, 0xbe, 0x9b, 0xc8, 0x1e,
-	0x16, 0xfa, 0x9b, 0xef, 0x8e, 0x5a, 0x7c, 0x65, 0x7e, 0xf7, 0x71, 0xa7, 0x3d, 0xb0, 0xe3, 0x1b,
-	0x44, 0xff, 0xf0, 0xd9, 0x25, 0xb2, 0x3c, 0xc4, 0xdc, 0x9d, 0x1f, 0xdd, 0x9d, 0x3f, 0x7d, 0xb6,
-	0x46, 0x2e, 0xec, 0x81, 0x1d, 0xf7, 0x01, 0x2e, 0x12, 0xc6, 0x8a, 0xc8, 0xd0, 0xbf, 0x7c, 0xf6,
-	0x1f, 0xb2, 0xb2, 0x07, 0x76, 0xe4, 0x6f, 0x61, 0xf2, 0x6f, 0x9f, 0xcd, 0x92, 0xe9, 0x10, 0x1f,
-	0x05, 0x38, 0x03, 0xfa, 0xd0, 0xc7, 0x22, 0x0d, 0xc3, 0x5c, 0xce, 0x23, 0x1f, 0xad, 0x7b, 0x93,
-	0xdb, 0xa8, 0x1b, 0xa4, 0x8d, 0x2e, 0x97, 0x12, 0x12, 0x43, 0x1f, 0xfb, 0x6c, 0x99, 0xd0, 0x10,
-	0x52, 0x75, 0x06, 0x05, 0xf8, 0x09, 0x3e, 0xf6, 0xcc, 0x25, 0xbf, 0xd1, 0x07, 0x3d, 0x18, 0x4d,
-	0x3c, 0xf5, 0xd1, 0xea, 0x2c, 0xff, 0xf9, 0x99, 0x67, 0x3e, 0x5a, 0x9d, 0x3b, 0xdf, 0x94, 0x6d,
-	0x45, 0x7f, 0x29, 0xa3, 0xaa, 0x13, 0x91, 0xc2, 0x89, 0x88, 0xee, 0xd2, 0x4f, 0xaa, 0xa8, 0xca,
-	0x2d, 0x3a, 0x54, 0x31, 0xa0, 0x7c, 0x43, 0x3f, 0xad, 0xa2, 0xf5, 0x58, 0xba, 0xcc, 0xfa, 0xcf,
-	0x5c, 0x9c, 0xbf, 0x49, 0xcd, 0x80, 0x7e, 0x8e, 0x1f, 0x00, 0x92, 0xc7, 0x27, 0xad, 0x23, 0xfa,
-	0xa0, 0x8a, 0xc7, 0xd8, 0x4a, 0x12, 0x15, 0x71, 0x3b, 0x6a, 0xa0, 0x2f, 0xaa, 0xd8, 0x81, 0x85,
-	0xe7, 0x24, 0x37, 0xe6, 0xcb, 0x2a, 0x1e, 0x2f, 0xc7, 0x5d, 0xd9, 0x02, 0x7c, 0x66, 0xbe, 0x72,
-	0xac, 0xf8, 0x5f, 0x83, 0x4a, 0x4e, 0x2c, 0xfd, 0xba, 0xba, 0x5e, 0x27, 0x95, 0xc0, 0x24, 0xee,
-	0xd5, 0xa8, 0x10, 0x3f, 0x30, 0x09, 0x9d, 0xc0, 0x4b, 0xb6, 0xad, 0x54, 0xb2, 0x73, 0xde, 0xd3,
-	0xb7, 0xff, 0x4f, 0xbd, 0xed, 0x57, 0xdf, 0xbe, 0xd6, 0x11, 0xb6, 0xdb, 0x3f, 0xc5, 0xcf, 0xf2,
-	0x66, 0xf6, 0x9d, 0xbe, 0x2a, 0x54, 0x3e, 0xda, 0x14, 0xd2, 0x82, 0x96, 0x3c, 0xd9, 0x74, 0x9f,
-	0xee, 0xcd, 0xec, 0xd3, 0xdd, 0x3b, 0x3d, 0x9d, 0x72, 0xf1, 0xb5, 0x7f, 0x02, 0x00, 0x00, 0xff,
-	0xff, 0x70, 0xc4, 0x92, 0xd3, 0x0b, 0x0a, 0x00, 0x00,
+	// 1379 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0x4b, 0x73, 0x1b, 0x37,
+	0x12, 0x16, 0x39, 0x94, 0x28, 0x42, 0x94, 0x04, 0x41, 0x0f, 0xcb, 0x5e, 0xed, 0x96, 0x8b, 0x27,
+	0x97, 0xaa, 0x2c, 0xed, 0xae, 0x6b, 0x77, 0x4f, 0x3e, 0x48, 0x1c, 0x3d, 0x58, 0xb6, 0x1e, 0x3b,
+	0x94, 0x9d, 0x54, 0x0e, 0x71, 0x41, 0x33, 0x4d, 0x12, 0xf1, 0x0c, 0xc0, 0x00, 0x18, 0x59, 0xbc,
+	0xe4, 0x37, 0x24, 0xfe, 0x1d, 0x49, 0x2a, 0x0f, 0xe7, 0xf1, 0x13, 0xe2, 0xbc, 0xce, 0xf9, 0x09,
+	0xc9, 0x3d, 0x4f, 0x3f, 0x53, 0x8d, 0x19, 0x92, 0xe3, 0x2a, 0xfb, 0x94, 0x1b, 0xfa, 0x43, 0xe3,
+	0x43, 0xf7, 0xd7, 0x8d, 0x9e, 0x21, 0xf5, 0x50, 0x25, 0x89, 0x92, 0x1b, 0x7d, 0xad, 0xac, 0x62,
+	0x8b, 0x89, 0x88, 0xcf, 0x52, 0x93, 0x59, 0x1b, 0xd9, 0x56, 0xe3, 0x1d, 0x32, 0xd5, 0xb6, 0xdc,
+	0xa6, 0x86, 0x5d, 0x27, 0x04, 0xb4, 0x56, 0xfa, 0x4e, 0xa8, 0x22, 0x58, 0x2d, 0x5d, 0x2e, 0x5d,
+	0x99, 0xfb, 0xf7, 0x3f, 0x36, 0x5e, 0x72, 0x66, 0x63, 0x07, 0xdd, 0x9a, 0x2a, 0x82, 0xa0, 0x06,
+	0xc3, 0x25, 0x5b, 0x21, 0x53, 0x1a, 0xb8, 0x51, 0x72, 0xb5, 0x7c, 0xb9, 0x74, 0xa5, 0x16, 0xe4,
+	0x16, 0xfb, 0x3b, 0x21, 0x1a, 0xde, 0x4e, 0xc1, 0xd8, 0x3b, 0x22, 0x5a, 0xf5, 0xdc, 0x5e, 0x2d,
+	0x47, 0x5a, 0x51, 0xe3, 0xbf, 0xa4, 0x7e, 0x03, 0x06, 0xb7, 0x79, 0x9c, 0xc2, 0x31, 0x17, 0x9a,
+	0x51, 0xe2, 0xdd, 0x85, 0x81, 0xbb, 0xbe, 0x16, 0xe0, 0x92, 0x2d, 0x91, 0xc9, 0x33, 0xdc, 0xce,
+	0x79, 0x33, 0xa3, 0x71, 0x8d, 0xcc, 0xdc, 0x80, 0x81, 0xcf, 0x2d, 0x7f, 0xc5, 0x31, 0x46, 0x2a,
+	0x11, 0xb7, 0xdc, 0x9d, 0xaa, 0x07, 0x6e, 0xdd, 0x58, 0x23, 0x95, 0xed, 0x58, 0x9d, 0x8e, 0x29,
+	0x4b, 0x6e, 0x33, 0xa7, 0xbc, 0x4a, 0xaa, 0x5b, 0x51, 0xa4, 0xc1, 0x18, 0x36, 0x47, 0xca, 0xa2,
+	0x9f, 0xb3, 0x95, 0x45, 0x1f, 0xc9, 0xfa, 0x4a, 0x5b, 0x47, 0xe6, 0x05, 0x6e, 0xdd, 0xb8, 0x5f,
+	0x22, 0xd5, 0x03, 0xd3, 0xdd, 0xe6, 0x06, 0xd8, 0xff, 0xc8, 0x74, 0x62, 0xba, 0x77, 0xec, 0xa0,
+	0x3f, 0x54, 0x6e, 0xed, 0xa5, 0xca, 0x1d, 0x98, 0xee, 0xc9, 0xa0, 0x0f, 0x41, 0x35, 0xc9, 0x16,
+	0x18, 0x49, 0x62, 0xba, 0x2d, 0x3f, 0x67, 0xce, 0x0c, 0xb6, 0x46, 0x6a, 0x56, 0x24, 0x60, 0x2c,
+	0x4f, 0xfa, 0x4e, 0xb2, 0x4a, 0x30, 0x06, 0xd8, 0x25, 0x32, 0x6d, 0x54, 0xaa, 0x43, 0x68, 0xf9,
+	0xab, 0x15, 0x77, 0x6c, 0x64, 0x37, 0xae, 0x93, 0xda, 0x81, 0xe9, 0xee, 0x03, 0x8f, 0x40, 0xb3,
+	0x7f, 0x92, 0xca, 0x29, 0x37, 0x59, 0x44, 0x33, 0xaf, 0x8e, 0x08, 0x33, 0x08, 0x9c, 0x67, 0xe3,
+	0x4d, 0x52, 0xf7, 0x0f, 0x6e, 0xfe, 0x05, 0x06, 0x0c, 0xdd, 0xf4, 0xb8, 0x8e, 0x0e, 0x79, 0x32,
+	0xac, 0xd8, 0x18, 0x58, 0x7f, 0x58, 0x21, 0xb5, 0x51, 0xf7, 0xb0, 0x19, 0x52, 0x6d, 0xa7, 0x61,
+	0x08, 0xc6, 0xd0, 0x09, 0xb6, 0x48, 0xe6, 0x6f, 0x49, 0x38, 0xef, 0x43, 0x68, 0x21, 0x72, 0x3e,
+	0xb4, 0xc4, 0x16, 0xc8, 0x6c, 0x53, 0x49, 0x09, 0xa1, 0xdd, 0xe5, 0x22, 0x86, 0x88, 0x96, 0xd9,
+	0x12, 0xa1, 0xc7, 0xa0, 0x13, 0x61, 0x8c, 0x50, 0xd2, 0x07, 0x29, 0x20, 0xa2, 0x1e, 0xbb, 0x40,
+	0x16, 0x9b, 0x2a, 0x8e, 0x21, 0xb4, 0x42, 0xc9, 0x43, 0x65, 0x77, 0xce, 0x85, 0xb1, 0x86, 0x56,
+	0x90, 0xb6, 0x15, 0xc7, 0xd0, 0xe5, 0xf1, 0x96, 0xee, 0xa6, 0x09, 0x48, 0x4b, 0x27, 0x91, 0x23,
+	0x07, 0x7d, 0x91, 0x80, 0x44, 0x26, 0x5a, 0x2d, 0xa0, 0x2d, 0x19, 0xc1, 0x39, 0xd6, 0x87, 0x4e,
+	0xb3, 0x8b, 0x64, 0x39, 0x47, 0x0b, 0x17, 0xf0, 0x04, 0x68, 0x8d, 0xcd, 0x93, 0x99, 0x7c, 0xeb,
+	0xe4, 0xe8, 0xf8, 0x06, 0x25, 0x05, 0x86, 0x40, 0xdd, 0x0b, 0x20, 0x54, 0x3a, 0xa2, 0x33, 0x85,
+	0x10, 0x6e, 0x43, 0x68, 0x95, 0x6e, 0xf9, 0xb4, 0x8e, 0x01, 0xe7, 0x60, 0x1b, 0xb8, 0x0e, 0x7b,
+	0x01, 0x98, 0x34, 0xb6, 0x74, 0x96, 0x51, 0x52, 0xdf, 0x15, 0x31, 0x1c, 0x2a, 0xbb, 0xab, 0x52,
+	0x19, 0xd1, 0x39, 0x36, 0x47, 0xc8, 0x01, 0x58, 0x9e, 0x2b, 0x30, 0x8f, 0xd7, 0x36, 0x79, 0xd8,
+	0x83, 0x1c, 0xa0, 0x6c, 0x85, 0xb0, 0x26, 0x97, 0x52, 0xd9, 0xa6, 0x06, 0x6e, 0x61, 0x57, 0xc5,
+	0x11, 0x68, 0xba, 0x80, 0xe1, 0xbc, 0x80, 0x8b, 0x18, 0x28, 0x1b, 0x7b, 0xfb, 0x10, 0xc3, 0xc8,
+	0x7b, 0x71, 0xec, 0x9d, 0xe3, 0xe8, 0xbd, 0x84, 0xc1, 0x6f, 0xa7, 0x22, 0x8e, 0x9c, 0x24, 0x59,
+	0x59, 0x96, 0x31, 0xc6, 0x3c, 0xf8, 0xc3, 0x9b, 0xad, 0xf6, 0x09, 0x5d, 0x61, 0xcb, 0x64, 0x21,
+	0x47, 0x0e, 0xc0, 0x6a, 0x11, 0x3a, 0xf1, 0x2e, 0x60, 0xa8, 0x47, 0xa9, 0x3d, 0xea, 0x1c, 0x40,
+	0xa2, 0xf4, 0x80, 0xae, 0x62, 0x41, 0x1d, 0xd3, 0xb0, 0x44, 0xf4, 0x22, 0xde, 0xb0, 0x93, 0xf4,
+	0xed, 0x60, 0x2c, 0x2f, 0xbd, 0xc4, 0x18, 0x99, 0xf5, 0xfd, 0x20, 0x9b, 0x12, 0x01, 0x0f, 0x81,
+	0xfe, 0x58, 0x65, 0xb3, 0x64, 0xfa, 0x50, 0x45, 0xb0, 0x9d, 0x9a, 0x01, 0xfd, 0xa9, 0xba, 0xfe,
+	0x3a, 0x21, 0x8e, 0x0a, 0xc7, 0x17, 0x30, 0x46, 0xe6, 0xc6, 0xd6, 0xa1, 0x92, 0x40, 0x27, 0x58,
+	0x9d, 0x4c, 0xdf, 0x92, 0xc2, 0x98, 0x14, 0x22, 0x5a, 0x42, 0x19, 0x5b, 0xf2, 0x58, 0xab, 0x2e,
+	0xbe, 0x70, 0x5a, 0xc6, 0xdd, 0x5d, 0x21, 0x85, 0xe9, 0xb9, 0x06, 0x22, 0x64, 0x2a, 0xd7, 0xb3,
+	0xb2, 0xde, 0x21, 0xf5, 0x36, 0x74, 0xb1, 0x57, 0x32, 0xee, 0x25, 0x42, 0x8b, 0xf6, 0x98, 0x7d,
+	0x94, 0x45, 0x09, 0x7b, 0x79, 0x4f, 0xab, 0x7b, 0x42, 0x76, 0x69, 0x19, 0xc9, 0xda, 0xc0, 0x63,
+	0x47, 0x3c, 0x43, 0xaa, 0xbb, 0x71, 0xea, 0x6e, 0xa9, 0xb8, 0x3b, 0xd1, 0x40, 0xb7, 0xc9, 0xf5,
+	0x07, 0xd3, 0x6e, 0x82, 0xb8, 0x41, 0x30, 0x4b, 0x6a, 0xb7, 0x64, 0x04, 0x1d, 0x21, 0x21, 0xa2,
+	0x13, 0xae, 0x18, 0xae, 0x68, 0x05, 0x55, 0x22, 0x4c, 0xd2, 0xd7, 0xaa, 0x5f, 0xc0, 0x00, 0x15,
+	0xdd, 0xe7, 0xa6, 0x00, 0x75, 0xb0, 0xc2, 0x3e, 0x98, 0x50, 0x8b, 0xd3, 0xe2, 0xf1, 0x2e, 0x2a,
+	0xdd, 0xee, 0xa9, 0x7b, 0x63, 0xcc, 0xd0, 0x1e, 0xde, 0xb4, 0x07, 0xb6, 0x3d, 0x30, 0x16, 0x92,
+	0xa6, 0x92, 0x1d, 0xd1, 0x35, 0x54, 0xe0, 0x4d, 0x37, 0x15, 0x8f, 0x0a, 0xc7, 0xdf, 0xc2, 0x1a,
+	0x07, 0x10, 0x03, 0x37, 0x45, 0xd6, 0xbb, 0xae, 0x1d, 0x5d, 0xa8, 0x5b, 0xb1, 0xe0, 0x86, 0xc6,
+	0x98, 0x0a, 0x46, 0x99, 0x99, 0x09, 0xea, 0xbe, 0x15, 0x5b, 0xd0, 0x99, 0x2d, 0xd9, 0x12, 0x99,
+	0xcf, 0xfc, 0x8f, 0xb9, 0xb6, 0xc2, 0x91, 0x7c, 0x55, 0x72, 0x05, 0xd7, 0xaa, 0x3f, 0xc6, 0x1e,
+	0xe2, 0xeb, 0xaf, 0xef, 0x73, 0x33, 0x86, 0xbe, 0x2e, 0xb1, 0x15, 0xb2, 0x30, 0x4c, 0x6d, 0x8c,
+	0x7f, 0x53, 0x62, 0x8b, 0x64, 0x0e, 0x53, 0x1b, 0x61, 0x86, 0x7e, 0xeb, 0x40, 0x4c, 0xa2, 0x00,
+	0x7e, 0xe7, 0x18, 0xf2, 0x2c, 0x0a, 0xf8, 0xf7, 0xee, 0x32, 0x64, 0xc8, 0x0b, 0x6d, 0xe8, 0xa3,
+	0x12, 0x46, 0x3a, 0xbc, 0x2c, 0x87, 0xe9, 0x63, 0xe7, 0x88, 0xac, 0x23, 0xc7, 0x27, 0xce, 0x31,
+	0xe7, 0x1c, 0xa1, 0x4f, 0x1d, 0xba, 0xcf, 0x65, 0xa4, 0x3a, 0x9d, 0x11, 0xfa, 0xac, 0xc4, 0x56,
+	0xc9, 0x22, 0x1e, 0xdf, 0xe6, 0x31, 0x97, 0xe1, 0xd8, 0xff, 0x79, 0x89, 0xd1, 0xa1, 0x90, 0xae,
+	0x91, 0xe9, 0xfb, 0x65, 0x27, 0x4a, 0x1e, 0x40, 0x86, 0x7d, 0x50, 0x66, 0x73, 0x99, 0xba, 0x99,
+	0xfd, 0x61, 0x99, 0xcd, 0x90, 0xa9, 0x96, 0x34, 0xa0, 0x2d, 0x7d, 0x17, 0x9b, 0x6d, 0x2a, 0x7b,
+	0xbd, 0xf4, 0x3d, 0x6c, 0xe9, 0x49, 0xd7, 0x6c, 0xf4, 0xbe, 0xdb, 0xc8, 0xe6, 0x0c, 0xfd, 0xd9,
+	0x73, 0xa9, 0x16, 0x87, 0xce, 0x2f, 0x1e, 0xde, 0xb4, 0x07, 0x76, 0xfc, 0x82, 0xe8, 0xaf, 0x1e,
+	0xbb, 0x44, 0x96, 0x87, 0x98, 0x1b, 0x01, 0xa3, 0xb7, 0xf3, 0x9b, 0xc7, 0xd6, 0xc8, 0x85, 0x3d,
+	0xb0, 0xe3, 0x3e, 0xc0, 0x43, 0xc2, 0x58, 0x11, 0x1a, 0xfa, 0xbb, 0xc7, 0xfe, 0x46, 0x56, 0xf6,
+	0xc0, 0x8e, 0xf4, 0x2d, 0x6c, 0xfe, 0xe1, 0xe1, 0x33, 0x0e, 0x70, 0x46, 0xc0, 0x19, 0xd0, 0x47,
+	0x1e, 0x16, 0x69, 0x68, 0xe6, 0xe1, 0x3c, 0xf6, 0x50, 0xba, 0xd7, 0xb8, 0x0d, 0x7b, 0x7e, 0xd2,
+	0xec, 0x71, 0x29, 0x21, 0x36, 0xf4, 0x89, 0xc7, 0x96, 0x09, 0x0d, 0x20, 0x51, 0x67, 0x50, 0x80,
+	0x9f, 0xe2, 0xec, 0x67, 0xce, 0xf9, 0xff, 0x29, 0xe8, 0xc1, 0x68, 0xe3, 0x99, 0x87, 0x52, 0x67,
+	0xfe, 0x2f, 0xee, 0x3c, 0xf7, 0x50, 0xea, 0x5c, 0xf9, 0x96, 0xec, 0x28, 0xfa, 0x43, 0x05, 0xa3,
+	0x3a, 0x11, 0x09, 0x9c, 0x88, 0xf0, 0x2e, 0xfd, 0xa8, 0x86, 0x51, 0xb9, 0x43, 0x38, 0x70, 0x30,
+	0x7c, 0x43, 0x3f, 0xae, 0xa1, 0xf4, 0x58, 0xba, 0x4c, 0xfa, 0x4f, 0x9c, 0x9d, 0x8f, 0xa8, 0x96,
+	0x4f, 0x3f, 0xc5, 0xef, 0x01, 0xc9, 0xed, 0x93, 0xf6, 0x11, 0x7d, 0x50, 0xc3, 0x34, 0xb6, 0xe2,
+	0x58, 0x85, 0xdc, 0x8e, 0x1a, 0xe8, 0xb3, 0x1a, 0x76, 0x60, 0x61, 0x9c, 0xe4, 0xc2, 0x7c, 0x5e,
+	0xc3, 0xf4, 0x72, 0xdc, 0x95, 0xcd, 0xc7, 0x31, 0xf3, 0x85, 0x63, 0xc5, 0xdf, 0x1c, 0x8c, 0xe4,
+	0xc4, 0xd2, 0x2f, 0x6b, 0xeb, 0x0d, 0x52, 0xf5, 0x4d, 0xec, 0xa6, 0x46, 0x95, 0x78, 0xbe, 0x89,
+	0xe9, 0x04, 0x3e, 0xb2, 0x6d, 0xa5, 0xe2, 0x9d, 0xf3, 0xbe, 0xbe, 0xfd, 0x2f, 0x5a, 0xda, 0xfe,
+	0xcf, 0x1b, 0xd7, 0xba, 0xc2, 0xf6, 0xd2, 0x53, 0xfc, 0x4a, 0x6f, 0x66, 0x9f, 0xed, 0xab, 0x42,
+	0xe5, 0xab, 0x4d, 0x21, 0x2d, 0x68, 0xc9, 0xe3, 0x4d, 0xf7, 0x25, 0xdf, 0xcc, 0xbe, 0xe4, 0xfd,
+	0xd3, 0xd3, 0x29, 0x67, 0x5f, 0xfb, 0x33, 0x00, 0x00, 0xff, 0xff, 0x93, 0xee, 0xc1, 0x7a, 0x39,
+	0x0a, 0x00, 0x00,
 }
@@ -10,6 +10,7 @@ import (
 	commonpb "github.com/milvus-io/milvus/internal/proto/commonpb"
 	internalpb "github.com/milvus-io/milvus/internal/proto/internalpb"
 	milvuspb "github.com/milvus-io/milvus/internal/proto/milvuspb"
+	schemapb "github.com/milvus-io/milvus/internal/proto/schemapb"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -169,14 +170,16 @@ func (m *GetIndexStatesRequest) GetIndexBuildIDs() []int64 {
 }
 
 type IndexInfo struct {
-	State                commonpb.IndexState `protobuf:"varint,1,opt,name=state,proto3,enum=milvus.proto.common.IndexState" json:"state,omitempty"`
-	IndexBuildID         int64               `protobuf:"varint,2,opt,name=indexBuildID,proto3" json:"indexBuildID,omitempty"`
-	IndexID              int64               `protobuf:"varint,3,opt,name=indexID,proto3" json:"indexID,omitempty"`
-	IndexName            string              `protobuf:"bytes,4,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
-	Reason               string              `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
-	XXX_unrecognized     []byte              `json:"-"`
-	XXX_sizecache        int32               `json:"-"`
+	State        commonpb.IndexState `protobuf:"varint,1,opt,name=state,proto3,enum=milvus.proto.common.IndexState" json:"state,omitempty"`
+	IndexBuildID int64               `protobuf:"varint,2,opt,name=indexBuildID,proto3" json:"indexBuildID,omitempty"`
+	IndexID      int64               `protobuf:"varint,3,opt,name=indexID,proto3" json:"indexID,omitempty"`
+	IndexName    string              `protobuf:"bytes,4,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	Reason       string              `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	// the build is finished without any index file, see IndexMeta.skipped
+	Skipped              bool     `protobuf:"varint,6,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *IndexInfo) Reset()         { *m = IndexInfo{} }
@@ -239,6 +242,13 @@ func (m *IndexInfo) GetReason() string {
 	return ""
 }
 
+func (m *IndexInfo) GetSkipped() bool {
+	if m != nil {
+		return m.Skipped
+	}
+	return false
+}
+
 type GetIndexStatesResponse struct {
 	Status               *commonpb.Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	States               []*IndexInfo     `protobuf:"bytes,2,rep,name=states,proto3" json:"states,omitempty"`
@@ -287,17 +297,34 @@ func (m *GetIndexStatesResponse) GetStates() []*IndexInfo {
 }
 
 type CreateIndexRequest struct {
-	IndexBuildID         int64                    `protobuf:"varint,1,opt,name=indexBuildID,proto3" json:"indexBuildID,omitempty"`
-	IndexName            string                   `protobuf:"bytes,2,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
-	IndexID              int64                    `protobuf:"varint,3,opt,name=indexID,proto3" json:"indexID,omitempty"`
-	Version              int64                    `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
-	MetaPath             string                   `protobuf:"bytes,5,opt,name=meta_path,json=metaPath,proto3" json:"meta_path,omitempty"`
-	DataPaths            []string                 `protobuf:"bytes,6,rep,name=data_paths,json=dataPaths,proto3" json:"data_paths,omitempty"`
-	TypeParams           []*commonpb.KeyValuePair `protobuf:"bytes,7,rep,name=type_params,json=typeParams,proto3" json:"type_params,omitempty"`
-	IndexParams          []*commonpb.KeyValuePair `protobuf:"bytes,8,rep,name=index_params,json=indexParams,proto3" json:"index_params,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
-	XXX_unrecognized     []byte                   `json:"-"`
-	XXX_sizecache        int32                    `json:"-"`
+	IndexBuildID int64                    `protobuf:"varint,1,opt,name=indexBuildID,proto3" json:"indexBuildID,omitempty"`
+	IndexName    string                   `protobuf:"bytes,2,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	IndexID      int64                    `protobuf:"varint,3,opt,name=indexID,proto3" json:"indexID,omitempty"`
+	Version      int64                    `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	MetaPath     string                   `protobuf:"bytes,5,opt,name=meta_path,json=metaPath,proto3" json:"meta_path,omitempty"`
+	DataPaths    []string                 `protobuf:"bytes,6,rep,name=data_paths,json=dataPaths,proto3" json:"data_paths,omitempty"`
+	TypeParams   []*commonpb.KeyValuePair `protobuf:"bytes,7,rep,name=type_params,json=typeParams,proto3" json:"type_params,omitempty"`
+	IndexParams  []*commonpb.KeyValuePair `protobuf:"bytes,8,rep,name=index_params,json=indexParams,proto3" json:"index_params,omitempty"`
+	// validate the request without building the index, see DryRunCreateIndex
+	DryRun bool `protobuf:"varint,9,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// the capability tags the node must advertise to build the index, e.g. disk-index, gpu or high-mem
+	RequiredCapabilities []string `protobuf:"bytes,10,rep,name=required_capabilities,json=requiredCapabilities,proto3" json:"required_capabilities,omitempty"`
+	// the schema of the field to build the index on, whose data type decides how the vectors are decoded
+	FieldSchema *schemapb.FieldSchema `protobuf:"bytes,11,opt,name=field_schema,json=fieldSchema,proto3" json:"field_schema,omitempty"`
+	// the format of the files in data_paths: binlog or parquet, detected by the extension of the paths if empty
+	DataFormat string `protobuf:"bytes,12,opt,name=data_format,json=dataFormat,proto3" json:"data_format,omitempty"`
+	// the build whose index is appended with the vectors of data_paths instead of building the index from scratch,
+	// which are the rows of the segment flushed after the base build, 0 means a full build. The index types without
+	// append support, i.e. other than HNSW, are rejected with UNSUPPORTED, which is rebuilt from scratch instead.
+	BaseIndexBuildID int64 `protobuf:"varint,13,opt,name=base_index_buildID,json=baseIndexBuildID,proto3" json:"base_index_buildID,omitempty"`
+	// the tasks of a higher priority are scheduled first by the node, ahead of the fairness across collections,
+	// 0 by default
+	Priority int64 `protobuf:"varint,14,opt,name=priority,proto3" json:"priority,omitempty"`
+	// the number of rows of the segment, which the node estimates the work of the build with, 0 if unknown
+	NumRows              int64    `protobuf:"varint,15,opt,name=num_rows,json=numRows,proto3" json:"num_rows,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *CreateIndexRequest) Reset()         { *m = CreateIndexRequest{} }
@@ -381,293 +408,1310 @@ func (m *CreateIndexRequest) GetIndexParams() []*commonpb.KeyValuePair {
 	return nil
 }
 
-type BuildIndexRequest struct {
-	IndexBuildID         int64                    `protobuf:"varint,1,opt,name=indexBuildID,proto3" json:"indexBuildID,omitempty"`
-	IndexName            string                   `protobuf:"bytes,2,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
-	IndexID              int64                    `protobuf:"varint,3,opt,name=indexID,proto3" json:"indexID,omitempty"`
-	DataPaths            []string                 `protobuf:"bytes,5,rep,name=data_paths,json=dataPaths,proto3" json:"data_paths,omitempty"`
-	TypeParams           []*commonpb.KeyValuePair `protobuf:"bytes,6,rep,name=type_params,json=typeParams,proto3" json:"type_params,omitempty"`
-	IndexParams          []*commonpb.KeyValuePair `protobuf:"bytes,7,rep,name=index_params,json=indexParams,proto3" json:"index_params,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
-	XXX_unrecognized     []byte                   `json:"-"`
-	XXX_sizecache        int32                    `json:"-"`
+func (m *CreateIndexRequest) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
 }
 
-func (m *BuildIndexRequest) Reset()         { *m = BuildIndexRequest{} }
-func (m *BuildIndexRequest) String() string { return proto.CompactTextString(m) }
-func (*BuildIndexRequest) ProtoMessage()    {}
-func (*BuildIndexRequest) Descriptor() ([]byte, []int) {
+func (m *CreateIndexRequest) GetRequiredCapabilities() []string {
+	if m != nil {
+		return m.RequiredCapabilities
+	}
+	return nil
+}
+
+func (m *CreateIndexRequest) GetFieldSchema() *schemapb.FieldSchema {
+	if m != nil {
+		return m.FieldSchema
+	}
+	return nil
+}
+
+func (m *CreateIndexRequest) GetDataFormat() string {
+	if m != nil {
+		return m.DataFormat
+	}
+	return ""
+}
+
+func (m *CreateIndexRequest) GetBaseIndexBuildID() int64 {
+	if m != nil {
+		return m.BaseIndexBuildID
+	}
+	return 0
+}
+
+func (m *CreateIndexRequest) GetPriority() int64 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+func (m *CreateIndexRequest) GetNumRows() int64 {
+	if m != nil {
+		return m.NumRows
+	}
+	return 0
+}
+
+type DryRunCheck struct {
+	// the checked part of the build: params, meta, binlogs, resources or storage
+	Name   string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Passed bool   `protobuf:"varint,2,opt,name=passed,proto3" json:"passed,omitempty"`
+	// what is checked, or the problem found
+	Detail               string   `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DryRunCheck) Reset()         { *m = DryRunCheck{} }
+func (m *DryRunCheck) String() string { return proto.CompactTextString(m) }
+func (*DryRunCheck) ProtoMessage()    {}
+func (*DryRunCheck) Descriptor() ([]byte, []int) {
 	return fileDescriptor_f9e019eb3fda53c2, []int{6}
 }
 
-func (m *BuildIndexRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_BuildIndexRequest.Unmarshal(m, b)
+func (m *DryRunCheck) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DryRunCheck.Unmarshal(m, b)
 }
-func (m *BuildIndexRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_BuildIndexRequest.Marshal(b, m, deterministic)
+func (m *DryRunCheck) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DryRunCheck.Marshal(b, m, deterministic)
 }
-func (m *BuildIndexRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_BuildIndexRequest.Merge(m, src)
+func (m *DryRunCheck) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DryRunCheck.Merge(m, src)
 }
-func (m *BuildIndexRequest) XXX_Size() int {
-	return xxx_messageInfo_BuildIndexRequest.Size(m)
+func (m *DryRunCheck) XXX_Size() int {
+	return xxx_messageInfo_DryRunCheck.Size(m)
 }
-func (m *BuildIndexRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_BuildIndexRequest.DiscardUnknown(m)
+func (m *DryRunCheck) XXX_DiscardUnknown() {
+	xxx_messageInfo_DryRunCheck.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_BuildIndexRequest proto.InternalMessageInfo
+var xxx_messageInfo_DryRunCheck proto.InternalMessageInfo
 
-func (m *BuildIndexRequest) GetIndexBuildID() int64 {
+func (m *DryRunCheck) GetName() string {
 	if m != nil {
-		return m.IndexBuildID
+		return m.Name
 	}
-	return 0
+	return ""
 }
 
-func (m *BuildIndexRequest) GetIndexName() string {
+func (m *DryRunCheck) GetPassed() bool {
 	if m != nil {
-		return m.IndexName
+		return m.Passed
 	}
-	return ""
+	return false
 }
 
-func (m *BuildIndexRequest) GetIndexID() int64 {
+func (m *DryRunCheck) GetDetail() string {
 	if m != nil {
-		return m.IndexID
+		return m.Detail
 	}
-	return 0
+	return ""
 }
 
-func (m *BuildIndexRequest) GetDataPaths() []string {
+type DryRunCreateIndexResponse struct {
+	Status *commonpb.Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// passed is true if no problem is found by the checks
+	Passed    bool           `protobuf:"varint,2,opt,name=passed,proto3" json:"passed,omitempty"`
+	Checks    []*DryRunCheck `protobuf:"bytes,3,rep,name=checks,proto3" json:"checks,omitempty"`
+	IndexType string         `protobuf:"bytes,4,opt,name=index_type,json=indexType,proto3" json:"index_type,omitempty"`
+	// the simd type the index would be built with
+	SimdType   string `protobuf:"bytes,5,opt,name=simd_type,json=simdType,proto3" json:"simd_type,omitempty"`
+	Dim        int64  `protobuf:"varint,6,opt,name=dim,proto3" json:"dim,omitempty"`
+	BinlogNum  int64  `protobuf:"varint,7,opt,name=binlog_num,json=binlogNum,proto3" json:"binlog_num,omitempty"`
+	BinlogSize int64  `protobuf:"varint,8,opt,name=binlog_size,json=binlogSize,proto3" json:"binlog_size,omitempty"`
+	// estimated by the first batch of each binlog
+	EstimatedRows   int64 `protobuf:"varint,9,opt,name=estimated_rows,json=estimatedRows,proto3" json:"estimated_rows,omitempty"`
+	EstimatedMemory int64 `protobuf:"varint,10,opt,name=estimated_memory,json=estimatedMemory,proto3" json:"estimated_memory,omitempty"`
+	// whether the index would be built while the binlogs are being loaded
+	Pipelined bool `protobuf:"varint,11,opt,name=pipelined,proto3" json:"pipelined,omitempty"`
+	// the path the index files would be saved under
+	IndexFilePrefix      string   `protobuf:"bytes,12,opt,name=index_file_prefix,json=indexFilePrefix,proto3" json:"index_file_prefix,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DryRunCreateIndexResponse) Reset()         { *m = DryRunCreateIndexResponse{} }
+func (m *DryRunCreateIndexResponse) String() string { return proto.CompactTextString(m) }
+func (*DryRunCreateIndexResponse) ProtoMessage()    {}
+func (*DryRunCreateIndexResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{7}
+}
+
+func (m *DryRunCreateIndexResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DryRunCreateIndexResponse.Unmarshal(m, b)
+}
+func (m *DryRunCreateIndexResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DryRunCreateIndexResponse.Marshal(b, m, deterministic)
+}
+func (m *DryRunCreateIndexResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DryRunCreateIndexResponse.Merge(m, src)
+}
+func (m *DryRunCreateIndexResponse) XXX_Size() int {
+	return xxx_messageInfo_DryRunCreateIndexResponse.Size(m)
+}
+func (m *DryRunCreateIndexResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DryRunCreateIndexResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DryRunCreateIndexResponse proto.InternalMessageInfo
+
+func (m *DryRunCreateIndexResponse) GetStatus() *commonpb.Status {
 	if m != nil {
-		return m.DataPaths
+		return m.Status
 	}
 	return nil
 }
 
-func (m *BuildIndexRequest) GetTypeParams() []*commonpb.KeyValuePair {
+func (m *DryRunCreateIndexResponse) GetPassed() bool {
 	if m != nil {
-		return m.TypeParams
+		return m.Passed
+	}
+	return false
+}
+
+func (m *DryRunCreateIndexResponse) GetChecks() []*DryRunCheck {
+	if m != nil {
+		return m.Checks
 	}
 	return nil
 }
 
-func (m *BuildIndexRequest) GetIndexParams() []*commonpb.KeyValuePair {
+func (m *DryRunCreateIndexResponse) GetIndexType() string {
 	if m != nil {
-		return m.IndexParams
+		return m.IndexType
+	}
+	return ""
+}
+
+func (m *DryRunCreateIndexResponse) GetSimdType() string {
+	if m != nil {
+		return m.SimdType
+	}
+	return ""
+}
+
+func (m *DryRunCreateIndexResponse) GetDim() int64 {
+	if m != nil {
+		return m.Dim
+	}
+	return 0
+}
+
+func (m *DryRunCreateIndexResponse) GetBinlogNum() int64 {
+	if m != nil {
+		return m.BinlogNum
+	}
+	return 0
+}
+
+func (m *DryRunCreateIndexResponse) GetBinlogSize() int64 {
+	if m != nil {
+		return m.BinlogSize
+	}
+	return 0
+}
+
+func (m *DryRunCreateIndexResponse) GetEstimatedRows() int64 {
+	if m != nil {
+		return m.EstimatedRows
+	}
+	return 0
+}
+
+func (m *DryRunCreateIndexResponse) GetEstimatedMemory() int64 {
+	if m != nil {
+		return m.EstimatedMemory
+	}
+	return 0
+}
+
+func (m *DryRunCreateIndexResponse) GetPipelined() bool {
+	if m != nil {
+		return m.Pipelined
+	}
+	return false
+}
+
+func (m *DryRunCreateIndexResponse) GetIndexFilePrefix() string {
+	if m != nil {
+		return m.IndexFilePrefix
+	}
+	return ""
+}
+
+type ActivateRequest struct {
+	Base                 *commonpb.MsgBase `protobuf:"bytes,1,opt,name=base,proto3" json:"base,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *ActivateRequest) Reset()         { *m = ActivateRequest{} }
+func (m *ActivateRequest) String() string { return proto.CompactTextString(m) }
+func (*ActivateRequest) ProtoMessage()    {}
+func (*ActivateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{8}
+}
+
+func (m *ActivateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ActivateRequest.Unmarshal(m, b)
+}
+func (m *ActivateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ActivateRequest.Marshal(b, m, deterministic)
+}
+func (m *ActivateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ActivateRequest.Merge(m, src)
+}
+func (m *ActivateRequest) XXX_Size() int {
+	return xxx_messageInfo_ActivateRequest.Size(m)
+}
+func (m *ActivateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ActivateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ActivateRequest proto.InternalMessageInfo
+
+func (m *ActivateRequest) GetBase() *commonpb.MsgBase {
+	if m != nil {
+		return m.Base
 	}
 	return nil
 }
 
-type BuildIndexResponse struct {
-	Status               *commonpb.Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	IndexBuildID         int64            `protobuf:"varint,2,opt,name=indexBuildID,proto3" json:"indexBuildID,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
-	XXX_unrecognized     []byte           `json:"-"`
-	XXX_sizecache        int32            `json:"-"`
+type CaptureProfileRequest struct {
+	Base *commonpb.MsgBase `protobuf:"bytes,1,opt,name=base,proto3" json:"base,omitempty"`
+	// heap, cpu, or the name of the other runtime profiles, e.g. goroutine, allocs
+	ProfileType string `protobuf:"bytes,2,opt,name=profile_type,json=profileType,proto3" json:"profile_type,omitempty"`
+	// the duration of the cpu profile
+	DurationSeconds      int64    `protobuf:"varint,3,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *BuildIndexResponse) Reset()         { *m = BuildIndexResponse{} }
-func (m *BuildIndexResponse) String() string { return proto.CompactTextString(m) }
-func (*BuildIndexResponse) ProtoMessage()    {}
-func (*BuildIndexResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f9e019eb3fda53c2, []int{7}
+func (m *CaptureProfileRequest) Reset()         { *m = CaptureProfileRequest{} }
+func (m *CaptureProfileRequest) String() string { return proto.CompactTextString(m) }
+func (*CaptureProfileRequest) ProtoMessage()    {}
+func (*CaptureProfileRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{9}
 }
 
-func (m *BuildIndexResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_BuildIndexResponse.Unmarshal(m, b)
+func (m *CaptureProfileRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CaptureProfileRequest.Unmarshal(m, b)
 }
-func (m *BuildIndexResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_BuildIndexResponse.Marshal(b, m, deterministic)
+func (m *CaptureProfileRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CaptureProfileRequest.Marshal(b, m, deterministic)
 }
-func (m *BuildIndexResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_BuildIndexResponse.Merge(m, src)
+func (m *CaptureProfileRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CaptureProfileRequest.Merge(m, src)
 }
-func (m *BuildIndexResponse) XXX_Size() int {
-	return xxx_messageInfo_BuildIndexResponse.Size(m)
+func (m *CaptureProfileRequest) XXX_Size() int {
+	return xxx_messageInfo_CaptureProfileRequest.Size(m)
 }
-func (m *BuildIndexResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_BuildIndexResponse.DiscardUnknown(m)
+func (m *CaptureProfileRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CaptureProfileRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_BuildIndexResponse proto.InternalMessageInfo
+var xxx_messageInfo_CaptureProfileRequest proto.InternalMessageInfo
 
-func (m *BuildIndexResponse) GetStatus() *commonpb.Status {
+func (m *CaptureProfileRequest) GetBase() *commonpb.MsgBase {
 	if m != nil {
-		return m.Status
+		return m.Base
 	}
 	return nil
 }
 
-func (m *BuildIndexResponse) GetIndexBuildID() int64 {
+func (m *CaptureProfileRequest) GetProfileType() string {
 	if m != nil {
-		return m.IndexBuildID
+		return m.ProfileType
+	}
+	return ""
+}
+
+func (m *CaptureProfileRequest) GetDurationSeconds() int64 {
+	if m != nil {
+		return m.DurationSeconds
 	}
 	return 0
 }
 
-type GetIndexFilePathsRequest struct {
-	IndexBuildIDs        []int64  `protobuf:"varint,1,rep,packed,name=indexBuildIDs,proto3" json:"indexBuildIDs,omitempty"`
+type CaptureProfileResponse struct {
+	Status *commonpb.Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// the path of the profile in the bucket
+	Path                 string   `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Size                 int64    `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetIndexFilePathsRequest) Reset()         { *m = GetIndexFilePathsRequest{} }
-func (m *GetIndexFilePathsRequest) String() string { return proto.CompactTextString(m) }
-func (*GetIndexFilePathsRequest) ProtoMessage()    {}
-func (*GetIndexFilePathsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f9e019eb3fda53c2, []int{8}
+func (m *CaptureProfileResponse) Reset()         { *m = CaptureProfileResponse{} }
+func (m *CaptureProfileResponse) String() string { return proto.CompactTextString(m) }
+func (*CaptureProfileResponse) ProtoMessage()    {}
+func (*CaptureProfileResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{10}
 }
 
-func (m *GetIndexFilePathsRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetIndexFilePathsRequest.Unmarshal(m, b)
+func (m *CaptureProfileResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CaptureProfileResponse.Unmarshal(m, b)
 }
-func (m *GetIndexFilePathsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetIndexFilePathsRequest.Marshal(b, m, deterministic)
+func (m *CaptureProfileResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CaptureProfileResponse.Marshal(b, m, deterministic)
 }
-func (m *GetIndexFilePathsRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetIndexFilePathsRequest.Merge(m, src)
+func (m *CaptureProfileResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CaptureProfileResponse.Merge(m, src)
 }
-func (m *GetIndexFilePathsRequest) XXX_Size() int {
-	return xxx_messageInfo_GetIndexFilePathsRequest.Size(m)
+func (m *CaptureProfileResponse) XXX_Size() int {
+	return xxx_messageInfo_CaptureProfileResponse.Size(m)
 }
-func (m *GetIndexFilePathsRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetIndexFilePathsRequest.DiscardUnknown(m)
+func (m *CaptureProfileResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CaptureProfileResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetIndexFilePathsRequest proto.InternalMessageInfo
+var xxx_messageInfo_CaptureProfileResponse proto.InternalMessageInfo
 
-func (m *GetIndexFilePathsRequest) GetIndexBuildIDs() []int64 {
+func (m *CaptureProfileResponse) GetStatus() *commonpb.Status {
 	if m != nil {
-		return m.IndexBuildIDs
+		return m.Status
 	}
 	return nil
 }
 
-type IndexFilePathInfo struct {
-	Status               *commonpb.Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	IndexBuildID         int64            `protobuf:"varint,2,opt,name=indexBuildID,proto3" json:"indexBuildID,omitempty"`
-	IndexFilePaths       []string         `protobuf:"bytes,3,rep,name=index_file_paths,json=indexFilePaths,proto3" json:"index_file_paths,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
-	XXX_unrecognized     []byte           `json:"-"`
-	XXX_sizecache        int32            `json:"-"`
+func (m *CaptureProfileResponse) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *CaptureProfileResponse) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+type VerifyIndexManifestRequest struct {
+	Base                 *commonpb.MsgBase `protobuf:"bytes,1,opt,name=base,proto3" json:"base,omitempty"`
+	IndexBuildID         int64             `protobuf:"varint,2,opt,name=indexBuildID,proto3" json:"indexBuildID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *VerifyIndexManifestRequest) Reset()         { *m = VerifyIndexManifestRequest{} }
+func (m *VerifyIndexManifestRequest) String() string { return proto.CompactTextString(m) }
+func (*VerifyIndexManifestRequest) ProtoMessage()    {}
+func (*VerifyIndexManifestRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{11}
+}
+
+func (m *VerifyIndexManifestRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyIndexManifestRequest.Unmarshal(m, b)
+}
+func (m *VerifyIndexManifestRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyIndexManifestRequest.Marshal(b, m, deterministic)
+}
+func (m *VerifyIndexManifestRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyIndexManifestRequest.Merge(m, src)
+}
+func (m *VerifyIndexManifestRequest) XXX_Size() int {
+	return xxx_messageInfo_VerifyIndexManifestRequest.Size(m)
+}
+func (m *VerifyIndexManifestRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyIndexManifestRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyIndexManifestRequest proto.InternalMessageInfo
+
+func (m *VerifyIndexManifestRequest) GetBase() *commonpb.MsgBase {
+	if m != nil {
+		return m.Base
+	}
+	return nil
+}
+
+func (m *VerifyIndexManifestRequest) GetIndexBuildID() int64 {
+	if m != nil {
+		return m.IndexBuildID
+	}
+	return 0
+}
+
+type VerifyIndexManifestResponse struct {
+	Status        *commonpb.Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	ManifestPath  string           `protobuf:"bytes,2,opt,name=manifest_path,json=manifestPath,proto3" json:"manifest_path,omitempty"`
+	FormatVersion int64            `protobuf:"varint,3,opt,name=format_version,json=formatVersion,proto3" json:"format_version,omitempty"`
+	// whether the manifest agrees with the index meta and the index files in the bucket
+	Passed bool `protobuf:"varint,4,opt,name=passed,proto3" json:"passed,omitempty"`
+	// the files missing or mismatched with the manifest
+	Mismatches           []string `protobuf:"bytes,5,rep,name=mismatches,proto3" json:"mismatches,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifyIndexManifestResponse) Reset()         { *m = VerifyIndexManifestResponse{} }
+func (m *VerifyIndexManifestResponse) String() string { return proto.CompactTextString(m) }
+func (*VerifyIndexManifestResponse) ProtoMessage()    {}
+func (*VerifyIndexManifestResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{12}
+}
+
+func (m *VerifyIndexManifestResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyIndexManifestResponse.Unmarshal(m, b)
+}
+func (m *VerifyIndexManifestResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyIndexManifestResponse.Marshal(b, m, deterministic)
+}
+func (m *VerifyIndexManifestResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyIndexManifestResponse.Merge(m, src)
+}
+func (m *VerifyIndexManifestResponse) XXX_Size() int {
+	return xxx_messageInfo_VerifyIndexManifestResponse.Size(m)
+}
+func (m *VerifyIndexManifestResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyIndexManifestResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyIndexManifestResponse proto.InternalMessageInfo
+
+func (m *VerifyIndexManifestResponse) GetStatus() *commonpb.Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *VerifyIndexManifestResponse) GetManifestPath() string {
+	if m != nil {
+		return m.ManifestPath
+	}
+	return ""
+}
+
+func (m *VerifyIndexManifestResponse) GetFormatVersion() int64 {
+	if m != nil {
+		return m.FormatVersion
+	}
+	return 0
+}
+
+func (m *VerifyIndexManifestResponse) GetPassed() bool {
+	if m != nil {
+		return m.Passed
+	}
+	return false
+}
+
+func (m *VerifyIndexManifestResponse) GetMismatches() []string {
+	if m != nil {
+		return m.Mismatches
+	}
+	return nil
+}
+
+type RunBuildBenchmarkRequest struct {
+	Base      *commonpb.MsgBase `protobuf:"bytes,1,opt,name=base,proto3" json:"base,omitempty"`
+	Rows      int64             `protobuf:"varint,2,opt,name=rows,proto3" json:"rows,omitempty"`
+	Dim       int64             `protobuf:"varint,3,opt,name=dim,proto3" json:"dim,omitempty"`
+	IndexType string            `protobuf:"bytes,4,opt,name=index_type,json=indexType,proto3" json:"index_type,omitempty"`
+	// the index params besides the index type, e.g. metric_type and nlist
+	Params []*commonpb.KeyValuePair `protobuf:"bytes,5,rep,name=params,proto3" json:"params,omitempty"`
+	// whether the index is serialized and uploaded to a scratch prefix, which is removed once the benchmark finishes
+	Upload bool `protobuf:"varint,6,opt,name=upload,proto3" json:"upload,omitempty"`
+	// run the benchmark even if the node has tasks in progress
+	Force                bool     `protobuf:"varint,7,opt,name=force,proto3" json:"force,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RunBuildBenchmarkRequest) Reset()         { *m = RunBuildBenchmarkRequest{} }
+func (m *RunBuildBenchmarkRequest) String() string { return proto.CompactTextString(m) }
+func (*RunBuildBenchmarkRequest) ProtoMessage()    {}
+func (*RunBuildBenchmarkRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{13}
+}
+
+func (m *RunBuildBenchmarkRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunBuildBenchmarkRequest.Unmarshal(m, b)
+}
+func (m *RunBuildBenchmarkRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunBuildBenchmarkRequest.Marshal(b, m, deterministic)
+}
+func (m *RunBuildBenchmarkRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunBuildBenchmarkRequest.Merge(m, src)
+}
+func (m *RunBuildBenchmarkRequest) XXX_Size() int {
+	return xxx_messageInfo_RunBuildBenchmarkRequest.Size(m)
+}
+func (m *RunBuildBenchmarkRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunBuildBenchmarkRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunBuildBenchmarkRequest proto.InternalMessageInfo
+
+func (m *RunBuildBenchmarkRequest) GetBase() *commonpb.MsgBase {
+	if m != nil {
+		return m.Base
+	}
+	return nil
+}
+
+func (m *RunBuildBenchmarkRequest) GetRows() int64 {
+	if m != nil {
+		return m.Rows
+	}
+	return 0
+}
+
+func (m *RunBuildBenchmarkRequest) GetDim() int64 {
+	if m != nil {
+		return m.Dim
+	}
+	return 0
+}
+
+func (m *RunBuildBenchmarkRequest) GetIndexType() string {
+	if m != nil {
+		return m.IndexType
+	}
+	return ""
+}
+
+func (m *RunBuildBenchmarkRequest) GetParams() []*commonpb.KeyValuePair {
+	if m != nil {
+		return m.Params
+	}
+	return nil
+}
+
+func (m *RunBuildBenchmarkRequest) GetUpload() bool {
+	if m != nil {
+		return m.Upload
+	}
+	return false
+}
+
+func (m *RunBuildBenchmarkRequest) GetForce() bool {
+	if m != nil {
+		return m.Force
+	}
+	return false
+}
+
+type RunBuildBenchmarkResponse struct {
+	Status    *commonpb.Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	IndexType string           `protobuf:"bytes,2,opt,name=index_type,json=indexType,proto3" json:"index_type,omitempty"`
+	// the effective simd type the index is built with
+	SimdType           string  `protobuf:"bytes,3,opt,name=simd_type,json=simdType,proto3" json:"simd_type,omitempty"`
+	Rows               int64   `protobuf:"varint,4,opt,name=rows,proto3" json:"rows,omitempty"`
+	Dim                int64   `protobuf:"varint,5,opt,name=dim,proto3" json:"dim,omitempty"`
+	BuildDurationMs    int64   `protobuf:"varint,6,opt,name=build_duration_ms,json=buildDurationMs,proto3" json:"build_duration_ms,omitempty"`
+	BuildRowsPerSecond float64 `protobuf:"fixed64,7,opt,name=build_rows_per_second,json=buildRowsPerSecond,proto3" json:"build_rows_per_second,omitempty"`
+	// the measures of the serialization and the upload, which are set only if the upload is requested
+	SerializeDurationMs  int64    `protobuf:"varint,8,opt,name=serialize_duration_ms,json=serializeDurationMs,proto3" json:"serialize_duration_ms,omitempty"`
+	UploadBytes          int64    `protobuf:"varint,9,opt,name=upload_bytes,json=uploadBytes,proto3" json:"upload_bytes,omitempty"`
+	UploadDurationMs     int64    `protobuf:"varint,10,opt,name=upload_duration_ms,json=uploadDurationMs,proto3" json:"upload_duration_ms,omitempty"`
+	UploadMbPerSecond    float64  `protobuf:"fixed64,11,opt,name=upload_mb_per_second,json=uploadMbPerSecond,proto3" json:"upload_mb_per_second,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RunBuildBenchmarkResponse) Reset()         { *m = RunBuildBenchmarkResponse{} }
+func (m *RunBuildBenchmarkResponse) String() string { return proto.CompactTextString(m) }
+func (*RunBuildBenchmarkResponse) ProtoMessage()    {}
+func (*RunBuildBenchmarkResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{14}
+}
+
+func (m *RunBuildBenchmarkResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunBuildBenchmarkResponse.Unmarshal(m, b)
+}
+func (m *RunBuildBenchmarkResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunBuildBenchmarkResponse.Marshal(b, m, deterministic)
+}
+func (m *RunBuildBenchmarkResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunBuildBenchmarkResponse.Merge(m, src)
+}
+func (m *RunBuildBenchmarkResponse) XXX_Size() int {
+	return xxx_messageInfo_RunBuildBenchmarkResponse.Size(m)
+}
+func (m *RunBuildBenchmarkResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunBuildBenchmarkResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunBuildBenchmarkResponse proto.InternalMessageInfo
+
+func (m *RunBuildBenchmarkResponse) GetStatus() *commonpb.Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *RunBuildBenchmarkResponse) GetIndexType() string {
+	if m != nil {
+		return m.IndexType
+	}
+	return ""
+}
+
+func (m *RunBuildBenchmarkResponse) GetSimdType() string {
+	if m != nil {
+		return m.SimdType
+	}
+	return ""
+}
+
+func (m *RunBuildBenchmarkResponse) GetRows() int64 {
+	if m != nil {
+		return m.Rows
+	}
+	return 0
+}
+
+func (m *RunBuildBenchmarkResponse) GetDim() int64 {
+	if m != nil {
+		return m.Dim
+	}
+	return 0
+}
+
+func (m *RunBuildBenchmarkResponse) GetBuildDurationMs() int64 {
+	if m != nil {
+		return m.BuildDurationMs
+	}
+	return 0
+}
+
+func (m *RunBuildBenchmarkResponse) GetBuildRowsPerSecond() float64 {
+	if m != nil {
+		return m.BuildRowsPerSecond
+	}
+	return 0
+}
+
+func (m *RunBuildBenchmarkResponse) GetSerializeDurationMs() int64 {
+	if m != nil {
+		return m.SerializeDurationMs
+	}
+	return 0
+}
+
+func (m *RunBuildBenchmarkResponse) GetUploadBytes() int64 {
+	if m != nil {
+		return m.UploadBytes
+	}
+	return 0
+}
+
+func (m *RunBuildBenchmarkResponse) GetUploadDurationMs() int64 {
+	if m != nil {
+		return m.UploadDurationMs
+	}
+	return 0
+}
+
+func (m *RunBuildBenchmarkResponse) GetUploadMbPerSecond() float64 {
+	if m != nil {
+		return m.UploadMbPerSecond
+	}
+	return 0
+}
+
+type RebuildIncompatibleIndexesRequest struct {
+	Base                 *commonpb.MsgBase `protobuf:"bytes,1,opt,name=base,proto3" json:"base,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *RebuildIncompatibleIndexesRequest) Reset()         { *m = RebuildIncompatibleIndexesRequest{} }
+func (m *RebuildIncompatibleIndexesRequest) String() string { return proto.CompactTextString(m) }
+func (*RebuildIncompatibleIndexesRequest) ProtoMessage()    {}
+func (*RebuildIncompatibleIndexesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{15}
+}
+
+func (m *RebuildIncompatibleIndexesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RebuildIncompatibleIndexesRequest.Unmarshal(m, b)
+}
+func (m *RebuildIncompatibleIndexesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RebuildIncompatibleIndexesRequest.Marshal(b, m, deterministic)
+}
+func (m *RebuildIncompatibleIndexesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RebuildIncompatibleIndexesRequest.Merge(m, src)
+}
+func (m *RebuildIncompatibleIndexesRequest) XXX_Size() int {
+	return xxx_messageInfo_RebuildIncompatibleIndexesRequest.Size(m)
+}
+func (m *RebuildIncompatibleIndexesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RebuildIncompatibleIndexesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RebuildIncompatibleIndexesRequest proto.InternalMessageInfo
+
+func (m *RebuildIncompatibleIndexesRequest) GetBase() *commonpb.MsgBase {
+	if m != nil {
+		return m.Base
+	}
+	return nil
+}
+
+type BuildIndexRequest struct {
+	IndexBuildID int64                    `protobuf:"varint,1,opt,name=indexBuildID,proto3" json:"indexBuildID,omitempty"`
+	IndexName    string                   `protobuf:"bytes,2,opt,name=index_name,json=indexName,proto3" json:"index_name,omitempty"`
+	IndexID      int64                    `protobuf:"varint,3,opt,name=indexID,proto3" json:"indexID,omitempty"`
+	DataPaths    []string                 `protobuf:"bytes,5,rep,name=data_paths,json=dataPaths,proto3" json:"data_paths,omitempty"`
+	TypeParams   []*commonpb.KeyValuePair `protobuf:"bytes,6,rep,name=type_params,json=typeParams,proto3" json:"type_params,omitempty"`
+	IndexParams  []*commonpb.KeyValuePair `protobuf:"bytes,7,rep,name=index_params,json=indexParams,proto3" json:"index_params,omitempty"`
+	// the schema of the field to build the index on
+	FieldSchema *schemapb.FieldSchema `protobuf:"bytes,8,opt,name=field_schema,json=fieldSchema,proto3" json:"field_schema,omitempty"`
+	// the priority of the build, see CreateIndexRequest.priority
+	Priority int64 `protobuf:"varint,9,opt,name=priority,proto3" json:"priority,omitempty"`
+	// the number of rows of the segment, see CreateIndexRequest.num_rows
+	NumRows              int64    `protobuf:"varint,10,opt,name=num_rows,json=numRows,proto3" json:"num_rows,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BuildIndexRequest) Reset()         { *m = BuildIndexRequest{} }
+func (m *BuildIndexRequest) String() string { return proto.CompactTextString(m) }
+func (*BuildIndexRequest) ProtoMessage()    {}
+func (*BuildIndexRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{16}
+}
+
+func (m *BuildIndexRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BuildIndexRequest.Unmarshal(m, b)
+}
+func (m *BuildIndexRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BuildIndexRequest.Marshal(b, m, deterministic)
+}
+func (m *BuildIndexRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BuildIndexRequest.Merge(m, src)
+}
+func (m *BuildIndexRequest) XXX_Size() int {
+	return xxx_messageInfo_BuildIndexRequest.Size(m)
+}
+func (m *BuildIndexRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BuildIndexRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BuildIndexRequest proto.InternalMessageInfo
+
+func (m *BuildIndexRequest) GetIndexBuildID() int64 {
+	if m != nil {
+		return m.IndexBuildID
+	}
+	return 0
+}
+
+func (m *BuildIndexRequest) GetIndexName() string {
+	if m != nil {
+		return m.IndexName
+	}
+	return ""
+}
+
+func (m *BuildIndexRequest) GetIndexID() int64 {
+	if m != nil {
+		return m.IndexID
+	}
+	return 0
+}
+
+func (m *BuildIndexRequest) GetDataPaths() []string {
+	if m != nil {
+		return m.DataPaths
+	}
+	return nil
+}
+
+func (m *BuildIndexRequest) GetTypeParams() []*commonpb.KeyValuePair {
+	if m != nil {
+		return m.TypeParams
+	}
+	return nil
+}
+
+func (m *BuildIndexRequest) GetIndexParams() []*commonpb.KeyValuePair {
+	if m != nil {
+		return m.IndexParams
+	}
+	return nil
+}
+
+func (m *BuildIndexRequest) GetFieldSchema() *schemapb.FieldSchema {
+	if m != nil {
+		return m.FieldSchema
+	}
+	return nil
+}
+
+func (m *BuildIndexRequest) GetPriority() int64 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+func (m *BuildIndexRequest) GetNumRows() int64 {
+	if m != nil {
+		return m.NumRows
+	}
+	return 0
+}
+
+type BuildIndexResponse struct {
+	Status               *commonpb.Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	IndexBuildID         int64            `protobuf:"varint,2,opt,name=indexBuildID,proto3" json:"indexBuildID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *BuildIndexResponse) Reset()         { *m = BuildIndexResponse{} }
+func (m *BuildIndexResponse) String() string { return proto.CompactTextString(m) }
+func (*BuildIndexResponse) ProtoMessage()    {}
+func (*BuildIndexResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{17}
+}
+
+func (m *BuildIndexResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BuildIndexResponse.Unmarshal(m, b)
+}
+func (m *BuildIndexResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BuildIndexResponse.Marshal(b, m, deterministic)
+}
+func (m *BuildIndexResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BuildIndexResponse.Merge(m, src)
+}
+func (m *BuildIndexResponse) XXX_Size() int {
+	return xxx_messageInfo_BuildIndexResponse.Size(m)
+}
+func (m *BuildIndexResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BuildIndexResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BuildIndexResponse proto.InternalMessageInfo
+
+func (m *BuildIndexResponse) GetStatus() *commonpb.Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *BuildIndexResponse) GetIndexBuildID() int64 {
+	if m != nil {
+		return m.IndexBuildID
+	}
+	return 0
+}
+
+type GetIndexFilePathsRequest struct {
+	IndexBuildIDs        []int64  `protobuf:"varint,1,rep,packed,name=indexBuildIDs,proto3" json:"indexBuildIDs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetIndexFilePathsRequest) Reset()         { *m = GetIndexFilePathsRequest{} }
+func (m *GetIndexFilePathsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetIndexFilePathsRequest) ProtoMessage()    {}
+func (*GetIndexFilePathsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{18}
+}
+
+func (m *GetIndexFilePathsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetIndexFilePathsRequest.Unmarshal(m, b)
+}
+func (m *GetIndexFilePathsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetIndexFilePathsRequest.Marshal(b, m, deterministic)
+}
+func (m *GetIndexFilePathsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetIndexFilePathsRequest.Merge(m, src)
+}
+func (m *GetIndexFilePathsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetIndexFilePathsRequest.Size(m)
+}
+func (m *GetIndexFilePathsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetIndexFilePathsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetIndexFilePathsRequest proto.InternalMessageInfo
+
+func (m *GetIndexFilePathsRequest) GetIndexBuildIDs() []int64 {
+	if m != nil {
+		return m.IndexBuildIDs
+	}
+	return nil
+}
+
+type IndexFilePathInfo struct {
+	Status               *commonpb.Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	IndexBuildID         int64            `protobuf:"varint,2,opt,name=indexBuildID,proto3" json:"indexBuildID,omitempty"`
+	IndexFilePaths       []string         `protobuf:"bytes,3,rep,name=index_file_paths,json=indexFilePaths,proto3" json:"index_file_paths,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *IndexFilePathInfo) Reset()         { *m = IndexFilePathInfo{} }
+func (m *IndexFilePathInfo) String() string { return proto.CompactTextString(m) }
+func (*IndexFilePathInfo) ProtoMessage()    {}
+func (*IndexFilePathInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{19}
+}
+
+func (m *IndexFilePathInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IndexFilePathInfo.Unmarshal(m, b)
+}
+func (m *IndexFilePathInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IndexFilePathInfo.Marshal(b, m, deterministic)
+}
+func (m *IndexFilePathInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IndexFilePathInfo.Merge(m, src)
+}
+func (m *IndexFilePathInfo) XXX_Size() int {
+	return xxx_messageInfo_IndexFilePathInfo.Size(m)
+}
+func (m *IndexFilePathInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_IndexFilePathInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IndexFilePathInfo proto.InternalMessageInfo
+
+func (m *IndexFilePathInfo) GetStatus() *commonpb.Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *IndexFilePathInfo) GetIndexBuildID() int64 {
+	if m != nil {
+		return m.IndexBuildID
+	}
+	return 0
+}
+
+func (m *IndexFilePathInfo) GetIndexFilePaths() []string {
+	if m != nil {
+		return m.IndexFilePaths
+	}
+	return nil
+}
+
+type GetIndexFilePathsResponse struct {
+	Status               *commonpb.Status     `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	FilePaths            []*IndexFilePathInfo `protobuf:"bytes,2,rep,name=file_paths,json=filePaths,proto3" json:"file_paths,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *GetIndexFilePathsResponse) Reset()         { *m = GetIndexFilePathsResponse{} }
+func (m *GetIndexFilePathsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetIndexFilePathsResponse) ProtoMessage()    {}
+func (*GetIndexFilePathsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{20}
+}
+
+func (m *GetIndexFilePathsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetIndexFilePathsResponse.Unmarshal(m, b)
+}
+func (m *GetIndexFilePathsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetIndexFilePathsResponse.Marshal(b, m, deterministic)
+}
+func (m *GetIndexFilePathsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetIndexFilePathsResponse.Merge(m, src)
+}
+func (m *GetIndexFilePathsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetIndexFilePathsResponse.Size(m)
+}
+func (m *GetIndexFilePathsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetIndexFilePathsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetIndexFilePathsResponse proto.InternalMessageInfo
+
+func (m *GetIndexFilePathsResponse) GetStatus() *commonpb.Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *GetIndexFilePathsResponse) GetFilePaths() []*IndexFilePathInfo {
+	if m != nil {
+		return m.FilePaths
+	}
+	return nil
+}
+
+type IndexFileInfo struct {
+	FilePath string `protobuf:"bytes,1,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	FileSize int64  `protobuf:"varint,2,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`
+	// the crc32c of the file in hex, see the manifest of the index files
+	Checksum             string   `protobuf:"bytes,3,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IndexFileInfo) Reset()         { *m = IndexFileInfo{} }
+func (m *IndexFileInfo) String() string { return proto.CompactTextString(m) }
+func (*IndexFileInfo) ProtoMessage()    {}
+func (*IndexFileInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{21}
+}
+
+func (m *IndexFileInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IndexFileInfo.Unmarshal(m, b)
+}
+func (m *IndexFileInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IndexFileInfo.Marshal(b, m, deterministic)
+}
+func (m *IndexFileInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IndexFileInfo.Merge(m, src)
+}
+func (m *IndexFileInfo) XXX_Size() int {
+	return xxx_messageInfo_IndexFileInfo.Size(m)
+}
+func (m *IndexFileInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_IndexFileInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IndexFileInfo proto.InternalMessageInfo
+
+func (m *IndexFileInfo) GetFilePath() string {
+	if m != nil {
+		return m.FilePath
+	}
+	return ""
+}
+
+func (m *IndexFileInfo) GetFileSize() int64 {
+	if m != nil {
+		return m.FileSize
+	}
+	return 0
+}
+
+func (m *IndexFileInfo) GetChecksum() string {
+	if m != nil {
+		return m.Checksum
+	}
+	return ""
+}
+
+// IndexArtifactVersion is the format version of the index files, zero means the index is built before versioning.
+type IndexArtifactVersion struct {
+	EngineVersion        int64    `protobuf:"varint,1,opt,name=engine_version,json=engineVersion,proto3" json:"engine_version,omitempty"`
+	SchemaVersion        int64    `protobuf:"varint,2,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IndexArtifactVersion) Reset()         { *m = IndexArtifactVersion{} }
+func (m *IndexArtifactVersion) String() string { return proto.CompactTextString(m) }
+func (*IndexArtifactVersion) ProtoMessage()    {}
+func (*IndexArtifactVersion) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{22}
+}
+
+func (m *IndexArtifactVersion) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IndexArtifactVersion.Unmarshal(m, b)
+}
+func (m *IndexArtifactVersion) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IndexArtifactVersion.Marshal(b, m, deterministic)
+}
+func (m *IndexArtifactVersion) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IndexArtifactVersion.Merge(m, src)
+}
+func (m *IndexArtifactVersion) XXX_Size() int {
+	return xxx_messageInfo_IndexArtifactVersion.Size(m)
+}
+func (m *IndexArtifactVersion) XXX_DiscardUnknown() {
+	xxx_messageInfo_IndexArtifactVersion.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IndexArtifactVersion proto.InternalMessageInfo
+
+func (m *IndexArtifactVersion) GetEngineVersion() int64 {
+	if m != nil {
+		return m.EngineVersion
+	}
+	return 0
+}
+
+func (m *IndexArtifactVersion) GetSchemaVersion() int64 {
+	if m != nil {
+		return m.SchemaVersion
+	}
+	return 0
+}
+
+// IndexEncryption is how the index files are encrypted before they are uploaded, the data key encrypting them is
+// wrapped by the key-encryption key of key_id.
+type IndexEncryption struct {
+	Algorithm            string   `protobuf:"bytes,1,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	KeyId                string   `protobuf:"bytes,2,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	WrappedKey           []byte   `protobuf:"bytes,3,opt,name=wrapped_key,json=wrappedKey,proto3" json:"wrapped_key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *IndexFilePathInfo) Reset()         { *m = IndexFilePathInfo{} }
-func (m *IndexFilePathInfo) String() string { return proto.CompactTextString(m) }
-func (*IndexFilePathInfo) ProtoMessage()    {}
-func (*IndexFilePathInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f9e019eb3fda53c2, []int{9}
+func (m *IndexEncryption) Reset()         { *m = IndexEncryption{} }
+func (m *IndexEncryption) String() string { return proto.CompactTextString(m) }
+func (*IndexEncryption) ProtoMessage()    {}
+func (*IndexEncryption) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{23}
 }
 
-func (m *IndexFilePathInfo) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_IndexFilePathInfo.Unmarshal(m, b)
+func (m *IndexEncryption) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IndexEncryption.Unmarshal(m, b)
 }
-func (m *IndexFilePathInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_IndexFilePathInfo.Marshal(b, m, deterministic)
+func (m *IndexEncryption) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IndexEncryption.Marshal(b, m, deterministic)
 }
-func (m *IndexFilePathInfo) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_IndexFilePathInfo.Merge(m, src)
+func (m *IndexEncryption) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IndexEncryption.Merge(m, src)
 }
-func (m *IndexFilePathInfo) XXX_Size() int {
-	return xxx_messageInfo_IndexFilePathInfo.Size(m)
+func (m *IndexEncryption) XXX_Size() int {
+	return xxx_messageInfo_IndexEncryption.Size(m)
 }
-func (m *IndexFilePathInfo) XXX_DiscardUnknown() {
-	xxx_messageInfo_IndexFilePathInfo.DiscardUnknown(m)
+func (m *IndexEncryption) XXX_DiscardUnknown() {
+	xxx_messageInfo_IndexEncryption.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_IndexFilePathInfo proto.InternalMessageInfo
+var xxx_messageInfo_IndexEncryption proto.InternalMessageInfo
 
-func (m *IndexFilePathInfo) GetStatus() *commonpb.Status {
+func (m *IndexEncryption) GetAlgorithm() string {
 	if m != nil {
-		return m.Status
+		return m.Algorithm
 	}
-	return nil
+	return ""
 }
 
-func (m *IndexFilePathInfo) GetIndexBuildID() int64 {
+func (m *IndexEncryption) GetKeyId() string {
 	if m != nil {
-		return m.IndexBuildID
+		return m.KeyId
 	}
-	return 0
+	return ""
 }
 
-func (m *IndexFilePathInfo) GetIndexFilePaths() []string {
+func (m *IndexEncryption) GetWrappedKey() []byte {
 	if m != nil {
-		return m.IndexFilePaths
+		return m.WrappedKey
 	}
 	return nil
 }
 
-type GetIndexFilePathsResponse struct {
-	Status               *commonpb.Status     `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	FilePaths            []*IndexFilePathInfo `protobuf:"bytes,2,rep,name=file_paths,json=filePaths,proto3" json:"file_paths,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+// IndexBuildSeed is the seed of the randomized initialization of the build, e.g. the k-means of IVF or the levels of
+// HNSW, honored is false if the engine can't seed the index type, whose index files differ from build to build.
+type IndexBuildSeed struct {
+	Seed                 int64    `protobuf:"varint,1,opt,name=seed,proto3" json:"seed,omitempty"`
+	Honored              bool     `protobuf:"varint,2,opt,name=honored,proto3" json:"honored,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetIndexFilePathsResponse) Reset()         { *m = GetIndexFilePathsResponse{} }
-func (m *GetIndexFilePathsResponse) String() string { return proto.CompactTextString(m) }
-func (*GetIndexFilePathsResponse) ProtoMessage()    {}
-func (*GetIndexFilePathsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f9e019eb3fda53c2, []int{10}
+func (m *IndexBuildSeed) Reset()         { *m = IndexBuildSeed{} }
+func (m *IndexBuildSeed) String() string { return proto.CompactTextString(m) }
+func (*IndexBuildSeed) ProtoMessage()    {}
+func (*IndexBuildSeed) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9e019eb3fda53c2, []int{24}
 }
 
-func (m *GetIndexFilePathsResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetIndexFilePathsResponse.Unmarshal(m, b)
+func (m *IndexBuildSeed) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IndexBuildSeed.Unmarshal(m, b)
 }
-func (m *GetIndexFilePathsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetIndexFilePathsResponse.Marshal(b, m, deterministic)
+func (m *IndexBuildSeed) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IndexBuildSeed.Marshal(b, m, deterministic)
 }
-func (m *GetIndexFilePathsResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetIndexFilePathsResponse.Merge(m, src)
+func (m *IndexBuildSeed) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IndexBuildSeed.Merge(m, src)
 }
-func (m *GetIndexFilePathsResponse) XXX_Size() int {
-	return xxx_messageInfo_GetIndexFilePathsResponse.Size(m)
+func (m *IndexBuildSeed) XXX_Size() int {
+	return xxx_messageInfo_IndexBuildSeed.Size(m)
 }
-func (m *GetIndexFilePathsResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetIndexFilePathsResponse.DiscardUnknown(m)
+func (m *IndexBuildSeed) XXX_DiscardUnknown() {
+	xxx_messageInfo_IndexBuildSeed.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetIndexFilePathsResponse proto.InternalMessageInfo
+var xxx_messageInfo_IndexBuildSeed proto.InternalMessageInfo
 
-func (m *GetIndexFilePathsResponse) GetStatus() *commonpb.Status {
+func (m *IndexBuildSeed) GetSeed() int64 {
 	if m != nil {
-		return m.Status
+		return m.Seed
 	}
-	return nil
+	return 0
 }
 
-func (m *GetIndexFilePathsResponse) GetFilePaths() []*IndexFilePathInfo {
+func (m *IndexBuildSeed) GetHonored() bool {
 	if m != nil {
-		return m.FilePaths
+		return m.Honored
 	}
-	return nil
+	return false
 }
 
 type IndexMeta struct {
-	IndexBuildID         int64               `protobuf:"varint,1,opt,name=indexBuildID,proto3" json:"indexBuildID,omitempty"`
-	State                commonpb.IndexState `protobuf:"varint,2,opt,name=state,proto3,enum=milvus.proto.common.IndexState" json:"state,omitempty"`
-	FailReason           string              `protobuf:"bytes,3,opt,name=fail_reason,json=failReason,proto3" json:"fail_reason,omitempty"`
-	Req                  *BuildIndexRequest  `protobuf:"bytes,4,opt,name=req,proto3" json:"req,omitempty"`
-	IndexFilePaths       []string            `protobuf:"bytes,5,rep,name=index_file_paths,json=indexFilePaths,proto3" json:"index_file_paths,omitempty"`
-	MarkDeleted          bool                `protobuf:"varint,6,opt,name=mark_deleted,json=markDeleted,proto3" json:"mark_deleted,omitempty"`
-	NodeID               int64               `protobuf:"varint,7,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
-	Version              int64               `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`
-	Recycled             bool                `protobuf:"varint,9,opt,name=recycled,proto3" json:"recycled,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
-	XXX_unrecognized     []byte              `json:"-"`
-	XXX_sizecache        int32               `json:"-"`
+	IndexBuildID   int64               `protobuf:"varint,1,opt,name=indexBuildID,proto3" json:"indexBuildID,omitempty"`
+	State          commonpb.IndexState `protobuf:"varint,2,opt,name=state,proto3,enum=milvus.proto.common.IndexState" json:"state,omitempty"`
+	FailReason     string              `protobuf:"bytes,3,opt,name=fail_reason,json=failReason,proto3" json:"fail_reason,omitempty"`
+	Req            *BuildIndexRequest  `protobuf:"bytes,4,opt,name=req,proto3" json:"req,omitempty"`
+	IndexFilePaths []string            `protobuf:"bytes,5,rep,name=index_file_paths,json=indexFilePaths,proto3" json:"index_file_paths,omitempty"`
+	MarkDeleted    bool                `protobuf:"varint,6,opt,name=mark_deleted,json=markDeleted,proto3" json:"mark_deleted,omitempty"`
+	NodeID         int64               `protobuf:"varint,7,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	Version        int64               `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`
+	Recycled       bool                `protobuf:"varint,9,opt,name=recycled,proto3" json:"recycled,omitempty"`
+	// the files written to local disk and uploaded by disk-resident index types
+	FileManifest    []*IndexFileInfo      `protobuf:"bytes,10,rep,name=file_manifest,json=fileManifest,proto3" json:"file_manifest,omitempty"`
+	ArtifactVersion *IndexArtifactVersion `protobuf:"bytes,11,opt,name=artifact_version,json=artifactVersion,proto3" json:"artifact_version,omitempty"`
+	// the index files saved by the failed build, retained as checkpoints when resumable builds are enabled
+	CheckpointFilePaths []string `protobuf:"bytes,12,rep,name=checkpoint_file_paths,json=checkpointFilePaths,proto3" json:"checkpoint_file_paths,omitempty"`
+	// the element type of the vectors the index is built from, e.g. FloatVector or Float16Vector
+	ElementType schemapb.DataType `protobuf:"varint,13,opt,name=element_type,json=elementType,proto3,enum=milvus.proto.schema.DataType" json:"element_type,omitempty"`
+	// whether the vectors are L2-normalized before the index is built on them, see the normalize index param
+	Normalized bool `protobuf:"varint,14,opt,name=normalized,proto3" json:"normalized,omitempty"`
+	// the manifest listing the index files along with their sizes and checksums, saved under the prefix of them
+	ManifestPath string `protobuf:"bytes,15,opt,name=manifest_path,json=manifestPath,proto3" json:"manifest_path,omitempty"`
+	// the encryption of the index files, nil if they are not encrypted
+	Encryption *IndexEncryption `protobuf:"bytes,16,opt,name=encryption,proto3" json:"encryption,omitempty"`
+	// the seed the index is built with, nil if the build is not seeded, see the build_seed index param
+	BuildSeed *IndexBuildSeed `protobuf:"bytes,17,opt,name=build_seed,json=buildSeed,proto3" json:"build_seed,omitempty"`
+	// the build and the version of the index the index is appended to, 0 if the index is built from scratch
+	BaseIndexBuildID int64 `protobuf:"varint,18,opt,name=base_index_buildID,json=baseIndexBuildID,proto3" json:"base_index_buildID,omitempty"`
+	BaseVersion      int64 `protobuf:"varint,19,opt,name=base_version,json=baseVersion,proto3" json:"base_version,omitempty"`
+	// the segment has no rows, the build is finished without any index file since there is nothing to index
+	Skipped              bool     `protobuf:"varint,20,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *IndexMeta) Reset()         { *m = IndexMeta{} }
 func (m *IndexMeta) String() string { return proto.CompactTextString(m) }
 func (*IndexMeta) ProtoMessage()    {}
 func (*IndexMeta) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f9e019eb3fda53c2, []int{11}
+	return fileDescriptor_f9e019eb3fda53c2, []int{25}
 }
 
 func (m *IndexMeta) XXX_Unmarshal(b []byte) error {
@@ -751,6 +1795,83 @@ func (m *IndexMeta) GetRecycled() bool {
 	return false
 }
 
+func (m *IndexMeta) GetFileManifest() []*IndexFileInfo {
+	if m != nil {
+		return m.FileManifest
+	}
+	return nil
+}
+
+func (m *IndexMeta) GetArtifactVersion() *IndexArtifactVersion {
+	if m != nil {
+		return m.ArtifactVersion
+	}
+	return nil
+}
+
+func (m *IndexMeta) GetCheckpointFilePaths() []string {
+	if m != nil {
+		return m.CheckpointFilePaths
+	}
+	return nil
+}
+
+func (m *IndexMeta) GetElementType() schemapb.DataType {
+	if m != nil {
+		return m.ElementType
+	}
+	return schemapb.DataType_None
+}
+
+func (m *IndexMeta) GetNormalized() bool {
+	if m != nil {
+		return m.Normalized
+	}
+	return false
+}
+
+func (m *IndexMeta) GetManifestPath() string {
+	if m != nil {
+		return m.ManifestPath
+	}
+	return ""
+}
+
+func (m *IndexMeta) GetEncryption() *IndexEncryption {
+	if m != nil {
+		return m.Encryption
+	}
+	return nil
+}
+
+func (m *IndexMeta) GetBuildSeed() *IndexBuildSeed {
+	if m != nil {
+		return m.BuildSeed
+	}
+	return nil
+}
+
+func (m *IndexMeta) GetBaseIndexBuildID() int64 {
+	if m != nil {
+		return m.BaseIndexBuildID
+	}
+	return 0
+}
+
+func (m *IndexMeta) GetBaseVersion() int64 {
+	if m != nil {
+		return m.BaseVersion
+	}
+	return 0
+}
+
+func (m *IndexMeta) GetSkipped() bool {
+	if m != nil {
+		return m.Skipped
+	}
+	return false
+}
+
 type DropIndexRequest struct {
 	IndexID              int64    `protobuf:"varint,1,opt,name=indexID,proto3" json:"indexID,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -762,7 +1883,7 @@ func (m *DropIndexRequest) Reset()         { *m = DropIndexRequest{} }
 func (m *DropIndexRequest) String() string { return proto.CompactTextString(m) }
 func (*DropIndexRequest) ProtoMessage()    {}
 func (*DropIndexRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f9e019eb3fda53c2, []int{12}
+	return fileDescriptor_f9e019eb3fda53c2, []int{26}
 }
 
 func (m *DropIndexRequest) XXX_Unmarshal(b []byte) error {
@@ -797,11 +1918,25 @@ func init() {
 	proto.RegisterType((*IndexInfo)(nil), "milvus.proto.index.IndexInfo")
 	proto.RegisterType((*GetIndexStatesResponse)(nil), "milvus.proto.index.GetIndexStatesResponse")
 	proto.RegisterType((*CreateIndexRequest)(nil), "milvus.proto.index.CreateIndexRequest")
+	proto.RegisterType((*DryRunCheck)(nil), "milvus.proto.index.DryRunCheck")
+	proto.RegisterType((*DryRunCreateIndexResponse)(nil), "milvus.proto.index.DryRunCreateIndexResponse")
+	proto.RegisterType((*ActivateRequest)(nil), "milvus.proto.index.ActivateRequest")
+	proto.RegisterType((*CaptureProfileRequest)(nil), "milvus.proto.index.CaptureProfileRequest")
+	proto.RegisterType((*CaptureProfileResponse)(nil), "milvus.proto.index.CaptureProfileResponse")
+	proto.RegisterType((*VerifyIndexManifestRequest)(nil), "milvus.proto.index.VerifyIndexManifestRequest")
+	proto.RegisterType((*VerifyIndexManifestResponse)(nil), "milvus.proto.index.VerifyIndexManifestResponse")
+	proto.RegisterType((*RunBuildBenchmarkRequest)(nil), "milvus.proto.index.RunBuildBenchmarkRequest")
+	proto.RegisterType((*RunBuildBenchmarkResponse)(nil), "milvus.proto.index.RunBuildBenchmarkResponse")
+	proto.RegisterType((*RebuildIncompatibleIndexesRequest)(nil), "milvus.proto.index.RebuildIncompatibleIndexesRequest")
 	proto.RegisterType((*BuildIndexRequest)(nil), "milvus.proto.index.BuildIndexRequest")
 	proto.RegisterType((*BuildIndexResponse)(nil), "milvus.proto.index.BuildIndexResponse")
 	proto.RegisterType((*GetIndexFilePathsRequest)(nil), "milvus.proto.index.GetIndexFilePathsRequest")
 	proto.RegisterType((*IndexFilePathInfo)(nil), "milvus.proto.index.IndexFilePathInfo")
 	proto.RegisterType((*GetIndexFilePathsResponse)(nil), "milvus.proto.index.GetIndexFilePathsResponse")
+	proto.RegisterType((*IndexFileInfo)(nil), "milvus.proto.index.IndexFileInfo")
+	proto.RegisterType((*IndexArtifactVersion)(nil), "milvus.proto.index.IndexArtifactVersion")
+	proto.RegisterType((*IndexEncryption)(nil), "milvus.proto.index.IndexEncryption")
+	proto.RegisterType((*IndexBuildSeed)(nil), "milvus.proto.index.IndexBuildSeed")
 	proto.RegisterType((*IndexMeta)(nil), "milvus.proto.index.IndexMeta")
 	proto.RegisterType((*DropIndexRequest)(nil), "milvus.proto.index.DropIndexRequest")
 }
@@ -809,68 +1944,145 @@ func init() {
 func init() { proto.RegisterFile("index_coord.proto", fileDescriptor_f9e019eb3fda53c2) }
 
 var fileDescriptor_f9e019eb3fda53c2 = []byte{
-	// 975 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x56, 0x5f, 0x6f, 0x1b, 0x45,
-	0x10, 0xcf, 0xf9, 0x12, 0x3b, 0x1e, 0x87, 0xa8, 0x59, 0x4a, 0x75, 0xb8, 0x54, 0x75, 0x8e, 0x52,
-	0x0c, 0x6a, 0x9d, 0xca, 0xa5, 0xf0, 0x84, 0x04, 0x89, 0x45, 0x64, 0xa1, 0x54, 0xd1, 0x36, 0xe2,
-	0x01, 0x09, 0x59, 0x1b, 0xdf, 0x24, 0x59, 0xf5, 0xfe, 0xe5, 0x76, 0x5d, 0x91, 0x77, 0xde, 0x79,
-	0x2b, 0xe2, 0x93, 0xf0, 0x39, 0xfa, 0xcc, 0x97, 0x41, 0xbb, 0xb7, 0x77, 0xb9, 0x3b, 0x9f, 0x53,
-	0x87, 0x50, 0x78, 0xe1, 0xed, 0x66, 0xf6, 0x37, 0x33, 0x3b, 0xbf, 0x9d, 0xfd, 0xdd, 0xc2, 0x16,
-	0x0f, 0x3d, 0xfc, 0x79, 0x32, 0x8d, 0xa2, 0xc4, 0x1b, 0xc4, 0x49, 0x24, 0x23, 0x42, 0x02, 0xee,
-	0xbf, 0x9a, 0x89, 0xd4, 0x1a, 0xe8, 0xf5, 0xee, 0xc6, 0x34, 0x0a, 0x82, 0x28, 0x4c, 0x7d, 0xdd,
-	0x4d, 0x1e, 0x4a, 0x4c, 0x42, 0xe6, 0x1b, 0x7b, 0xa3, 0x18, 0xe1, 0xfe, 0x66, 0xc1, 0xfb, 0x14,
-	0x4f, 0xb9, 0x90, 0x98, 0x3c, 0x8f, 0x3c, 0xa4, 0x78, 0x3e, 0x43, 0x21, 0xc9, 0x13, 0x58, 0x3d,
-	0x66, 0x02, 0x1d, 0xab, 0x67, 0xf5, 0x3b, 0xc3, 0x8f, 0x06, 0xa5, 0x32, 0x26, 0xff, 0x81, 0x38,
-	0xdd, 0x65, 0x02, 0xa9, 0x46, 0x92, 0x2f, 0xa1, 0xc5, 0x3c, 0x2f, 0x41, 0x21, 0x9c, 0xc6, 0x15,
-	0x41, 0xdf, 0xa6, 0x18, 0x9a, 0x81, 0xc9, 0x1d, 0x68, 0x86, 0x91, 0x87, 0xe3, 0x91, 0x63, 0xf7,
-	0xac, 0xbe, 0x4d, 0x8d, 0xe5, 0xfe, 0x6a, 0xc1, 0xed, 0xf2, 0xce, 0x44, 0x1c, 0x85, 0x02, 0xc9,
-	0x53, 0x68, 0x0a, 0xc9, 0xe4, 0x4c, 0x98, 0xcd, 0xdd, 0xad, 0xad, 0xf3, 0x42, 0x43, 0xa8, 0x81,
-	0x92, 0x5d, 0xe8, 0xf0, 0x90, 0xcb, 0x49, 0xcc, 0x12, 0x16, 0x64, 0x3b, 0xdc, 0x1e, 0x54, 0xd8,
-	0x33, 0x44, 0x8d, 0x43, 0x2e, 0x0f, 0x35, 0x90, 0x02, 0xcf, 0xbf, 0xdd, 0xaf, 0xe1, 0x83, 0x7d,
-	0x94, 0x63, 0xc5, 0xb1, 0xca, 0x8e, 0x22, 0x23, 0xeb, 0x01, 0xbc, 0xa7, 0x99, 0xdf, 0x9d, 0x71,
-	0xdf, 0x1b, 0x8f, 0xd4, 0xc6, 0xec, 0xbe, 0x4d, 0xcb, 0x4e, 0xf7, 0x0f, 0x0b, 0xda, 0x3a, 0x78,
-	0x1c, 0x9e, 0x44, 0xe4, 0x19, 0xac, 0xa9, 0xad, 0xa5, 0x0c, 0x6f, 0x0e, 0xef, 0xd7, 0x36, 0x71,
-	0x59, 0x8b, 0xa6, 0x68, 0xe2, 0xc2, 0x46, 0x31, 0xab, 0x6e, 0xc4, 0xa6, 0x25, 0x1f, 0x71, 0xa0,
-	0xa5, 0xed, 0x9c, 0xd2, 0xcc, 0x24, 0xf7, 0x00, 0xd2, 0x11, 0x0a, 0x59, 0x80, 0xce, 0x6a, 0xcf,
-	0xea, 0xb7, 0x69, 0x5b, 0x7b, 0x9e, 0xb3, 0x00, 0xd5, 0x51, 0x24, 0xc8, 0x44, 0x14, 0x3a, 0x6b,
-	0x7a, 0xc9, 0x58, 0xee, 0x2f, 0x16, 0xdc, 0xa9, 0x76, 0x7e, 0x93, 0xc3, 0x78, 0x96, 0x06, 0xa1,
-	0x3a, 0x07, 0xbb, 0xdf, 0x19, 0xde, 0x1b, 0xcc, 0x4f, 0xf1, 0x20, 0xa7, 0x8a, 0x1a, 0xb0, 0xfb,
-	0xa6, 0x01, 0x64, 0x2f, 0x41, 0x26, 0x51, 0xaf, 0x65, 0xec, 0x57, 0x29, 0xb1, 0x6a, 0x28, 0x29,
-	0x37, 0xde, 0xa8, 0x36, 0xbe, 0x98, 0x31, 0x07, 0x5a, 0xaf, 0x30, 0x11, 0x3c, 0x0a, 0x35, 0x5d,
-	0x36, 0xcd, 0x4c, 0x72, 0x17, 0xda, 0x01, 0x4a, 0x36, 0x89, 0x99, 0x3c, 0x33, 0x7c, 0xad, 0x2b,
-	0xc7, 0x21, 0x93, 0x67, 0xaa, 0x9e, 0xc7, 0xcc, 0xa2, 0x70, 0x9a, 0x3d, 0x5b, 0xd5, 0x53, 0x1e,
-	0xb5, 0xaa, 0xa7, 0x51, 0x5e, 0xc4, 0x98, 0x4d, 0x63, 0x4b, 0xb3, 0xb0, 0x5d, 0x4b, 0xdd, 0xf7,
-	0x78, 0xf1, 0x03, 0xf3, 0x67, 0x78, 0xc8, 0x78, 0x42, 0x41, 0x45, 0xa5, 0xd3, 0x48, 0x46, 0xa6,
-	0xed, 0x2c, 0xc9, 0xfa, 0xb2, 0x49, 0x3a, 0x3a, 0xcc, 0xcc, 0xf4, 0xef, 0x0d, 0xd8, 0x4a, 0x49,
-	0xfa, 0xd7, 0x28, 0x2d, 0x73, 0xb3, 0xf6, 0x16, 0x6e, 0x9a, 0xff, 0x04, 0x37, 0xad, 0xbf, 0xc5,
-	0x4d, 0x00, 0xa4, 0x48, 0xcd, 0x4d, 0x26, 0x7e, 0x89, 0x6b, 0xeb, 0x7e, 0x03, 0x4e, 0x76, 0xc9,
-	0xbe, 0xe3, 0x3e, 0x6a, 0x36, 0xae, 0xa7, 0x30, 0xaf, 0x2d, 0xd8, 0x2a, 0xc5, 0x6b, 0xa5, 0x79,
-	0x57, 0x1b, 0x26, 0x7d, 0xb8, 0x95, 0xb2, 0x7c, 0xc2, 0x7d, 0x34, 0xc7, 0x69, 0xeb, 0xe3, 0xdc,
-	0xe4, 0xa5, 0x2e, 0xd4, 0xc6, 0x3e, 0xac, 0xe9, 0xed, 0x26, 0x8c, 0x8e, 0x00, 0x0a, 0x65, 0x53,
-	0x1d, 0xf9, 0x64, 0xa1, 0x8e, 0x14, 0x09, 0xa1, 0xed, 0x93, 0x7c, 0x63, 0x7f, 0x36, 0x8c, 0x26,
-	0x1f, 0xa0, 0x64, 0x4b, 0x8d, 0x7d, 0xae, 0xdb, 0x8d, 0x6b, 0xe9, 0xf6, 0x7d, 0xe8, 0x9c, 0x30,
-	0xee, 0x4f, 0x8c, 0xbe, 0xda, 0xfa, 0xba, 0x80, 0x72, 0x51, 0xed, 0x21, 0x5f, 0x81, 0x9d, 0xe0,
-	0xb9, 0x16, 0x99, 0x05, 0x8d, 0xcc, 0x5d, 0x53, 0xaa, 0x22, 0x6a, 0x4f, 0x61, 0xad, 0xee, 0x14,
-	0xc8, 0x36, 0x6c, 0x04, 0x2c, 0x79, 0x39, 0xf1, 0xd0, 0x47, 0x89, 0x9e, 0xd3, 0xec, 0x59, 0xfd,
-	0x75, 0xda, 0x51, 0xbe, 0x51, 0xea, 0x2a, 0xfc, 0x8c, 0x5b, 0xc5, 0x9f, 0x71, 0x51, 0x06, 0xd7,
-	0xcb, 0x32, 0xd8, 0x85, 0xf5, 0x04, 0xa7, 0x17, 0x53, 0x1f, 0x3d, 0xa7, 0xad, 0x13, 0xe6, 0xb6,
-	0xfb, 0x08, 0x6e, 0x8d, 0x92, 0x28, 0x2e, 0x49, 0x4b, 0x41, 0x17, 0xac, 0x92, 0x2e, 0x0c, 0xdf,
-	0x34, 0x01, 0x34, 0x74, 0x4f, 0xbd, 0x6f, 0x48, 0x0c, 0x64, 0x1f, 0xe5, 0x5e, 0x14, 0xc4, 0x51,
-	0x88, 0xa1, 0x4c, 0xff, 0x3b, 0xe4, 0xc9, 0x82, 0x5f, 0xf6, 0x3c, 0xd4, 0x14, 0xec, 0x3e, 0x5c,
-	0x10, 0x51, 0x81, 0xbb, 0x2b, 0x24, 0xd0, 0x15, 0x8f, 0x78, 0x80, 0x47, 0x7c, 0xfa, 0x72, 0xef,
-	0x8c, 0x85, 0x21, 0xfa, 0x57, 0x55, 0xac, 0x40, 0xb3, 0x8a, 0x1f, 0x97, 0x23, 0x8c, 0xf1, 0x42,
-	0x26, 0x3c, 0x3c, 0xcd, 0x86, 0xde, 0x5d, 0x21, 0xe7, 0x70, 0x7b, 0x1f, 0x75, 0x75, 0x2e, 0x24,
-	0x9f, 0x8a, 0xac, 0xe0, 0x70, 0x71, 0xc1, 0x39, 0xf0, 0x35, 0x4b, 0xfe, 0x04, 0x70, 0x39, 0x45,
-	0x64, 0xb9, 0x29, 0x9b, 0x27, 0xb0, 0x0a, 0xcb, 0xd3, 0x73, 0xd8, 0x2c, 0x3f, 0x13, 0xc8, 0x67,
-	0x75, 0xb1, 0xb5, 0x8f, 0xa8, 0xee, 0xe7, 0xcb, 0x40, 0xf3, 0x52, 0x09, 0x6c, 0xcd, 0x09, 0x0a,
-	0x79, 0x74, 0x55, 0x8a, 0xaa, 0xa6, 0x76, 0x1f, 0x2f, 0x89, 0xce, 0x6b, 0x1e, 0x42, 0x3b, 0x1f,
-	0x67, 0xf2, 0xa0, 0x2e, 0xba, 0x3a, 0xed, 0xdd, 0xab, 0xa4, 0xcc, 0x5d, 0x21, 0x13, 0x80, 0x7d,
-	0x94, 0x07, 0x28, 0x13, 0x3e, 0x15, 0xe4, 0x61, 0xed, 0x21, 0x5e, 0x02, 0xb2, 0xa4, 0x9f, 0xbe,
-	0x15, 0x97, 0x6d, 0x79, 0xf8, 0x7a, 0xd5, 0xe8, 0x9b, 0x7a, 0x41, 0xff, 0x7f, 0xa5, 0xde, 0xc1,
-	0x95, 0x3a, 0x82, 0x4e, 0xe1, 0x4d, 0x4a, 0x6a, 0x2f, 0xcb, 0xfc, 0xa3, 0xf5, 0xbf, 0x1e, 0x8c,
-	0xdd, 0x2f, 0x7e, 0x1c, 0x9e, 0x72, 0x79, 0x36, 0x3b, 0x56, 0xa5, 0x77, 0x52, 0xe4, 0x63, 0x1e,
-	0x99, 0xaf, 0x9d, 0x8c, 0xa1, 0x1d, 0x9d, 0x69, 0x47, 0xb7, 0x11, 0x1f, 0x1f, 0x37, 0xb5, 0xf9,
-	0xf4, 0xaf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x75, 0x9d, 0x20, 0xf1, 0x89, 0x0e, 0x00, 0x00,
+	// 2202 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x39, 0x4b, 0x73, 0xdb, 0xc8,
+	0xd1, 0xa6, 0xa9, 0x07, 0xd9, 0xa4, 0x5e, 0x63, 0x69, 0x3f, 0x98, 0xde, 0xfd, 0x2c, 0x63, 0xd7,
+	0x8e, 0xec, 0xb2, 0xa5, 0x8d, 0x1c, 0x67, 0x2b, 0x87, 0xa4, 0xd6, 0x92, 0x62, 0x97, 0x6a, 0x4b,
+	0x2e, 0x05, 0x72, 0x7c, 0x48, 0x55, 0x0a, 0x35, 0x24, 0x9a, 0xd2, 0x94, 0xf0, 0xf2, 0x00, 0xb4,
+	0x4d, 0x9f, 0x73, 0xcf, 0x29, 0xc9, 0x39, 0x97, 0xfc, 0x95, 0x1c, 0x72, 0xda, 0x4b, 0x7e, 0x44,
+	0x7e, 0x42, 0x4e, 0xa9, 0xe9, 0x19, 0x80, 0x00, 0x09, 0x4a, 0xb4, 0x94, 0x4d, 0x2e, 0xb9, 0x71,
+	0xba, 0x7b, 0xba, 0x7b, 0xfa, 0x8d, 0x26, 0xac, 0x89, 0xd0, 0xc3, 0x0f, 0x6e, 0x2f, 0x8a, 0xa4,
+	0xb7, 0x1d, 0xcb, 0x28, 0x8d, 0x18, 0x0b, 0x84, 0xff, 0x6e, 0x90, 0xe8, 0xd3, 0x36, 0xe1, 0x3b,
+	0xed, 0x5e, 0x14, 0x04, 0x51, 0xa8, 0x61, 0x9d, 0x65, 0x11, 0xa6, 0x28, 0x43, 0xee, 0x9b, 0x73,
+	0xbb, 0x78, 0xa3, 0xd3, 0x4e, 0x7a, 0x67, 0x18, 0x70, 0x7d, 0xb2, 0xff, 0x54, 0x83, 0x5b, 0x0e,
+	0x9e, 0x8a, 0x24, 0x45, 0xf9, 0x2a, 0xf2, 0xd0, 0xc1, 0xb7, 0x03, 0x4c, 0x52, 0xf6, 0x35, 0xcc,
+	0x75, 0x79, 0x82, 0x56, 0x6d, 0xb3, 0xb6, 0xd5, 0xda, 0xfd, 0x7c, 0xbb, 0x24, 0xd4, 0x48, 0x3b,
+	0x4a, 0x4e, 0xf7, 0x78, 0x82, 0x0e, 0x51, 0xb2, 0x9f, 0xc2, 0x22, 0xf7, 0x3c, 0x89, 0x49, 0x62,
+	0xdd, 0xbc, 0xe0, 0xd2, 0x73, 0x4d, 0xe3, 0x64, 0xc4, 0xec, 0x33, 0x58, 0x08, 0x23, 0x0f, 0x0f,
+	0x0f, 0xac, 0xfa, 0x66, 0x6d, 0xab, 0xee, 0x98, 0x93, 0xfd, 0xfb, 0x1a, 0xac, 0x97, 0x35, 0x4b,
+	0xe2, 0x28, 0x4c, 0x90, 0x3d, 0x85, 0x85, 0x24, 0xe5, 0xe9, 0x20, 0x31, 0xca, 0xdd, 0xa9, 0x94,
+	0x73, 0x42, 0x24, 0x8e, 0x21, 0x65, 0x7b, 0xd0, 0x12, 0xa1, 0x48, 0xdd, 0x98, 0x4b, 0x1e, 0x64,
+	0x1a, 0xde, 0xdb, 0x1e, 0xb3, 0xa5, 0x31, 0xdb, 0x61, 0x28, 0xd2, 0x63, 0x22, 0x74, 0x40, 0xe4,
+	0xbf, 0xed, 0x9f, 0xc3, 0xc6, 0x4b, 0x4c, 0x0f, 0x95, 0xc5, 0x15, 0x77, 0x4c, 0x32, 0x63, 0x7d,
+	0x05, 0x4b, 0xe4, 0x87, 0xbd, 0x81, 0xf0, 0xbd, 0xc3, 0x03, 0xa5, 0x58, 0x7d, 0xab, 0xee, 0x94,
+	0x81, 0xf6, 0xf7, 0x35, 0x68, 0xd2, 0xe5, 0xc3, 0xb0, 0x1f, 0xb1, 0x67, 0x30, 0xaf, 0x54, 0xd3,
+	0x16, 0x5e, 0xde, 0xbd, 0x5b, 0xf9, 0x88, 0x91, 0x2c, 0x47, 0x53, 0x33, 0x1b, 0xda, 0x45, 0xae,
+	0xf4, 0x90, 0xba, 0x53, 0x82, 0x31, 0x0b, 0x16, 0xe9, 0x9c, 0x9b, 0x34, 0x3b, 0xb2, 0x2f, 0x00,
+	0x74, 0x40, 0x85, 0x3c, 0x40, 0x6b, 0x6e, 0xb3, 0xb6, 0xd5, 0x74, 0x9a, 0x04, 0x79, 0xc5, 0x03,
+	0x54, 0xae, 0x90, 0xc8, 0x93, 0x28, 0xb4, 0xe6, 0x09, 0x65, 0x4e, 0x8a, 0x61, 0x72, 0x2e, 0xe2,
+	0x18, 0x3d, 0x6b, 0x61, 0xb3, 0xb6, 0xd5, 0x70, 0xb2, 0xa3, 0xfd, 0xbb, 0x1a, 0x7c, 0x36, 0x6e,
+	0x93, 0xeb, 0xb8, 0xe9, 0x99, 0xbe, 0x84, 0xca, 0x43, 0xf5, 0xad, 0xd6, 0xee, 0x17, 0xdb, 0x93,
+	0xd1, 0xbe, 0x9d, 0x1b, 0xd1, 0x31, 0xc4, 0xf6, 0x3f, 0xe6, 0x80, 0xed, 0x4b, 0xe4, 0x29, 0x12,
+	0x2e, 0xf3, 0xcb, 0xb8, 0xb1, 0x6a, 0x15, 0xc6, 0x2a, 0x9b, 0xe4, 0xe6, 0xb8, 0x49, 0xa6, 0xdb,
+	0xd2, 0x82, 0xc5, 0x77, 0x28, 0x13, 0x11, 0x85, 0x64, 0xc8, 0xba, 0x93, 0x1d, 0xd9, 0x1d, 0x68,
+	0x06, 0x98, 0x72, 0x37, 0xe6, 0xe9, 0x99, 0xb1, 0x64, 0x43, 0x01, 0x8e, 0x79, 0x7a, 0xa6, 0xe4,
+	0x79, 0xdc, 0x20, 0x13, 0x6b, 0x61, 0xb3, 0xae, 0xe4, 0x79, 0x5c, 0x63, 0x29, 0x4e, 0xd3, 0x61,
+	0x8c, 0x59, 0x9c, 0x2e, 0x6e, 0xd6, 0x27, 0xe3, 0xd4, 0x98, 0xee, 0x3b, 0x1c, 0xbe, 0xe1, 0xfe,
+	0x00, 0x8f, 0xb9, 0x90, 0x0e, 0xa8, 0x5b, 0x3a, 0x4e, 0xd9, 0x81, 0x79, 0x76, 0xc6, 0xa4, 0x31,
+	0x2b, 0x93, 0x16, 0x5d, 0x33, 0x5c, 0xfe, 0x0f, 0x16, 0x3d, 0x39, 0x74, 0xe5, 0x20, 0xb4, 0x9a,
+	0xe4, 0xf4, 0x05, 0x4f, 0x0e, 0x9d, 0x41, 0xc8, 0x9e, 0xc2, 0x86, 0xc4, 0xb7, 0x03, 0x21, 0xd1,
+	0x73, 0x7b, 0x3c, 0xe6, 0x5d, 0xe1, 0x8b, 0x54, 0x60, 0x62, 0x01, 0x3d, 0x66, 0x3d, 0x43, 0xee,
+	0x17, 0x70, 0x6c, 0x1f, 0xda, 0x7d, 0x81, 0xbe, 0xe7, 0xea, 0xea, 0x63, 0xb5, 0x28, 0x26, 0x36,
+	0xcb, 0x3a, 0x69, 0xdc, 0xf6, 0x0b, 0x45, 0x78, 0x42, 0xbf, 0x9d, 0x56, 0x7f, 0x74, 0x60, 0x77,
+	0xa1, 0x45, 0xb6, 0xeb, 0x47, 0x32, 0xe0, 0xa9, 0xd5, 0x26, 0xd3, 0x92, 0x39, 0x5f, 0x10, 0x84,
+	0x3d, 0x06, 0xa6, 0x6a, 0x91, 0xab, 0x9f, 0xdf, 0x35, 0x6e, 0x5f, 0x22, 0xf7, 0xac, 0x2a, 0xcc,
+	0x61, 0xd1, 0xf5, 0x1d, 0x68, 0xc4, 0x52, 0x44, 0x52, 0xa4, 0x43, 0x6b, 0x99, 0x68, 0xf2, 0x33,
+	0xbb, 0x0d, 0x8d, 0x70, 0x10, 0xb8, 0x32, 0x7a, 0x9f, 0x58, 0x2b, 0xda, 0xbd, 0xe1, 0x20, 0x70,
+	0xa2, 0xf7, 0x89, 0xfd, 0x2b, 0x68, 0x1d, 0x90, 0x25, 0xf6, 0xcf, 0xb0, 0x77, 0xce, 0x18, 0xcc,
+	0x51, 0xe8, 0xd4, 0x48, 0x9b, 0xb9, 0xd0, 0x24, 0x52, 0xcc, 0x93, 0x04, 0x3d, 0x0a, 0xa8, 0x86,
+	0x63, 0x4e, 0x0a, 0xee, 0x61, 0xca, 0x85, 0x4f, 0xc1, 0xd4, 0x74, 0xcc, 0xc9, 0xfe, 0x5b, 0x1d,
+	0x6e, 0x1b, 0x9e, 0xc5, 0x28, 0xbe, 0x4e, 0x26, 0x4d, 0x53, 0xe1, 0x1b, 0x58, 0xe8, 0x29, 0xbd,
+	0x13, 0xab, 0x4e, 0x61, 0x71, 0xb7, 0x2a, 0xc3, 0x0a, 0xef, 0x73, 0x0c, 0xf9, 0x28, 0x51, 0x54,
+	0xa4, 0x95, 0x6a, 0xc7, 0xeb, 0x61, 0x8c, 0x2a, 0xe8, 0x13, 0x11, 0x78, 0x1a, 0x6b, 0x82, 0x5e,
+	0x01, 0x08, 0xb9, 0x0a, 0x75, 0x4f, 0x04, 0x54, 0x3c, 0xea, 0x8e, 0xfa, 0xa9, 0xb8, 0x75, 0x45,
+	0xe8, 0x47, 0xa7, 0x6e, 0x38, 0x08, 0xac, 0x45, 0x42, 0x34, 0x35, 0xe4, 0xd5, 0x20, 0x50, 0x9e,
+	0x36, 0xe8, 0x44, 0x7c, 0x44, 0xab, 0x41, 0x78, 0x73, 0xe3, 0x44, 0x7c, 0x44, 0x76, 0x1f, 0x96,
+	0x31, 0x49, 0x45, 0xc0, 0x53, 0xf4, 0xb4, 0x97, 0x9a, 0x44, 0xb3, 0x94, 0x43, 0x95, 0xaf, 0xd8,
+	0x43, 0x58, 0x1d, 0x91, 0x05, 0x18, 0x44, 0x72, 0x68, 0x01, 0x11, 0xae, 0xe4, 0xf0, 0x23, 0x02,
+	0xb3, 0xcf, 0xa1, 0x19, 0x8b, 0x18, 0x7d, 0x11, 0xa2, 0x47, 0xe1, 0xd9, 0x70, 0x46, 0x00, 0xf6,
+	0x28, 0x6b, 0xc5, 0x7d, 0xe1, 0xa3, 0x1b, 0x4b, 0xec, 0x8b, 0x0f, 0x26, 0x00, 0x57, 0x08, 0xf1,
+	0x42, 0xf8, 0x78, 0x4c, 0x60, 0x7b, 0x1f, 0x56, 0x9e, 0xf7, 0x52, 0xf1, 0x4e, 0x95, 0xed, 0xab,
+	0xb6, 0x53, 0xd5, 0x98, 0x37, 0xf6, 0x79, 0x9c, 0x0e, 0x24, 0x1e, 0xcb, 0x48, 0x49, 0xbd, 0x7a,
+	0x6b, 0xbe, 0x07, 0xed, 0x58, 0xf3, 0xd0, 0xee, 0xd1, 0x55, 0xae, 0x65, 0x60, 0xe4, 0xa1, 0x87,
+	0xb0, 0xea, 0x0d, 0x24, 0x4f, 0x45, 0x14, 0xba, 0x09, 0xf6, 0xa2, 0xd0, 0x4b, 0x4c, 0xc1, 0x5b,
+	0xc9, 0xe0, 0x27, 0x1a, 0x6c, 0x0f, 0xe0, 0xb3, 0x71, 0xc5, 0xae, 0x13, 0xa8, 0x0c, 0xe6, 0xa8,
+	0x50, 0x6a, 0xa5, 0xe8, 0xb7, 0x82, 0x91, 0xdf, 0xb5, 0x06, 0xf4, 0xdb, 0x96, 0xd0, 0x79, 0x83,
+	0x52, 0xf4, 0x87, 0x94, 0x1c, 0x47, 0x3c, 0x14, 0x7d, 0x4c, 0xd2, 0xab, 0x1b, 0x65, 0x86, 0x4e,
+	0x6a, 0xff, 0xbd, 0x06, 0x77, 0x2a, 0x85, 0x5e, 0xe7, 0xc1, 0x5f, 0xc2, 0x52, 0x60, 0x18, 0xb9,
+	0x85, 0x97, 0xb7, 0x33, 0x20, 0xb5, 0x89, 0xfb, 0xb0, 0xac, 0xab, 0x9c, 0x9b, 0x35, 0x19, 0x6d,
+	0x8b, 0x25, 0x0d, 0x7d, 0xa3, 0x81, 0x85, 0x2c, 0x9f, 0x2b, 0x65, 0xf9, 0xff, 0x03, 0x04, 0x22,
+	0x09, 0x78, 0xda, 0x3b, 0xc3, 0xc4, 0x9a, 0xa7, 0xc2, 0x5c, 0x80, 0xd8, 0xff, 0xac, 0x81, 0xe5,
+	0x0c, 0x42, 0x7a, 0xe7, 0x1e, 0x86, 0xbd, 0xb3, 0x80, 0xcb, 0xf3, 0xab, 0xdb, 0x92, 0xc1, 0x1c,
+	0xe5, 0xa0, 0xb6, 0x21, 0xfd, 0xce, 0x72, 0xbe, 0x5e, 0xca, 0xf9, 0x8b, 0x2a, 0xc8, 0xcf, 0xd4,
+	0x5b, 0xa8, 0x61, 0xcd, 0xcf, 0xda, 0xb0, 0xcc, 0x05, 0x65, 0x86, 0x41, 0xec, 0x47, 0x3c, 0x9b,
+	0x4f, 0xcc, 0x89, 0xad, 0xc3, 0x7c, 0x3f, 0x92, 0x3d, 0xa4, 0x02, 0xd3, 0x70, 0xf4, 0xc1, 0xfe,
+	0x6b, 0x1d, 0x6e, 0x57, 0x3c, 0xfe, 0x3a, 0x3e, 0x2d, 0x3f, 0xed, 0xe6, 0x85, 0xc5, 0xb1, 0x3e,
+	0x56, 0x1c, 0x33, 0xe3, 0xcd, 0x4d, 0x1a, 0x6f, 0x7e, 0x64, 0xbc, 0x47, 0xb0, 0x46, 0xfd, 0xcc,
+	0xcd, 0xd3, 0x34, 0x48, 0x4c, 0x41, 0x5d, 0x21, 0xc4, 0x81, 0x81, 0x1f, 0x25, 0xec, 0xc7, 0xb0,
+	0xa1, 0x69, 0x15, 0x2f, 0x37, 0x46, 0x69, 0x52, 0x9a, 0xcc, 0x50, 0x73, 0x18, 0x21, 0x55, 0x7d,
+	0x3c, 0x46, 0xa9, 0xb3, 0x9a, 0xed, 0xc2, 0x46, 0x82, 0x52, 0x70, 0x5f, 0x7c, 0xc4, 0x92, 0x08,
+	0x5d, 0x7a, 0x6f, 0xe5, 0xc8, 0x82, 0x98, 0x7b, 0xd0, 0xd6, 0x76, 0x76, 0xbb, 0xc3, 0x14, 0xb3,
+	0x0a, 0xdc, 0xd2, 0xb0, 0x3d, 0x05, 0x52, 0x0d, 0xd9, 0x90, 0x14, 0x79, 0xea, 0x0a, 0xbc, 0xaa,
+	0x31, 0x05, 0x86, 0x3b, 0xb0, 0x6e, 0xa8, 0x83, 0x6e, 0x51, 0xed, 0x16, 0xa9, 0xbd, 0xa6, 0x71,
+	0x47, 0xdd, 0x5c, 0x6b, 0xfb, 0xd7, 0x70, 0xcf, 0x41, 0xdd, 0xe6, 0xc3, 0x5e, 0x14, 0xc4, 0x3c,
+	0x15, 0x5d, 0x5f, 0x77, 0x4f, 0x4c, 0xae, 0x1c, 0xce, 0xf6, 0x1f, 0xea, 0xb0, 0xa6, 0x4b, 0xc0,
+	0x7f, 0x6c, 0x9a, 0x2c, 0x8f, 0x85, 0xf3, 0x97, 0x8c, 0x85, 0x0b, 0xff, 0x8e, 0xb1, 0x70, 0xf1,
+	0x4a, 0x63, 0xe1, 0xf8, 0x20, 0xd7, 0xb8, 0xca, 0x20, 0x57, 0x9c, 0xbc, 0x9a, 0x17, 0x4c, 0x5e,
+	0x50, 0x9e, 0xbc, 0x02, 0x60, 0x45, 0xb7, 0x5c, 0x27, 0x61, 0x67, 0xa9, 0xfe, 0xdf, 0x82, 0x95,
+	0x7d, 0xdb, 0x50, 0x77, 0x57, 0x9e, 0xf8, 0xb4, 0x4f, 0xbe, 0x3f, 0xd6, 0x60, 0xad, 0x74, 0x9f,
+	0x3e, 0xfd, 0x7e, 0x28, 0x85, 0xd9, 0x16, 0xac, 0x16, 0x87, 0x14, 0x0a, 0xa5, 0x3a, 0x85, 0xd2,
+	0xb2, 0x28, 0xbd, 0x42, 0x29, 0x76, 0xbb, 0xe2, 0x6d, 0xd7, 0xb1, 0xe8, 0x01, 0x40, 0x41, 0xac,
+	0xfe, 0x7c, 0xbb, 0x3f, 0xf5, 0xf3, 0xad, 0x68, 0x10, 0xa7, 0xd9, 0xcf, 0x15, 0x43, 0x58, 0xca,
+	0xf1, 0x64, 0xac, 0x3b, 0xd0, 0xcc, 0xd9, 0x9a, 0x19, 0xbb, 0x91, 0x91, 0xe7, 0x48, 0x1a, 0x16,
+	0xb4, 0x45, 0x08, 0x49, 0x23, 0x62, 0x07, 0x1a, 0x7a, 0x74, 0x1d, 0x04, 0x59, 0xcd, 0xcd, 0xce,
+	0xb6, 0x07, 0xeb, 0x24, 0xe6, 0xb9, 0x4c, 0x45, 0x9f, 0xf7, 0xf2, 0x7e, 0xaa, 0xc6, 0xca, 0xf0,
+	0x54, 0x84, 0x98, 0xb7, 0xdd, 0x9a, 0x19, 0x2b, 0x09, 0x5a, 0x20, 0xd3, 0x21, 0x9e, 0x93, 0x69,
+	0xe1, 0x4b, 0x1a, 0x6a, 0xc8, 0xec, 0x53, 0x58, 0x21, 0x29, 0xbf, 0x0c, 0x7b, 0x72, 0x18, 0xab,
+	0x22, 0xa7, 0xa6, 0x4c, 0xee, 0x9f, 0xaa, 0x48, 0x3f, 0x0b, 0xcc, 0x73, 0x46, 0x00, 0xb6, 0x01,
+	0x0b, 0xe7, 0x38, 0x74, 0x85, 0x67, 0x4a, 0xc7, 0xfc, 0x39, 0x0e, 0x0f, 0x3d, 0x35, 0x0d, 0xbf,
+	0x97, 0x5c, 0x7d, 0x70, 0xbb, 0xe7, 0x38, 0xa4, 0xc7, 0xb4, 0x1d, 0x30, 0xa0, 0xef, 0x70, 0x68,
+	0xff, 0x02, 0x96, 0x47, 0x5f, 0x36, 0x27, 0x88, 0x1e, 0x4d, 0x50, 0x88, 0x9e, 0x51, 0x9f, 0x7e,
+	0xab, 0xea, 0x73, 0x16, 0x85, 0x91, 0xcc, 0xbf, 0x09, 0xb2, 0xa3, 0xfd, 0x97, 0x45, 0xb3, 0x9a,
+	0x38, 0xc2, 0x94, 0xcf, 0x54, 0xe8, 0xf2, 0xf5, 0xc5, 0xcd, 0x4f, 0x5a, 0x5f, 0xdc, 0x85, 0x56,
+	0x9f, 0x0b, 0xdf, 0x35, 0x6b, 0x06, 0xed, 0x16, 0x50, 0x20, 0x87, 0x20, 0xec, 0x1b, 0xa8, 0x4b,
+	0x7c, 0x4b, 0xbd, 0x70, 0x4a, 0xf8, 0x4c, 0x14, 0x66, 0x47, 0xdd, 0xa8, 0x8c, 0xfd, 0xf9, 0xaa,
+	0xd8, 0x57, 0x6d, 0x4b, 0x35, 0x7c, 0xd7, 0x43, 0x1f, 0xd3, 0x7c, 0xa5, 0xd1, 0x52, 0xb0, 0x03,
+	0x0d, 0x2a, 0xec, 0xa4, 0x16, 0x8b, 0x3b, 0xa9, 0xe2, 0x37, 0x7f, 0xa3, 0xfc, 0xcd, 0xdf, 0x81,
+	0x86, 0xc4, 0xde, 0xb0, 0xe7, 0xa3, 0x67, 0x3e, 0x97, 0xf3, 0x33, 0x7b, 0x01, 0x4b, 0xa4, 0x54,
+	0x36, 0xe0, 0x59, 0x50, 0x55, 0x79, 0xc7, 0x92, 0x83, 0x12, 0xa3, 0xad, 0xee, 0x65, 0x53, 0x27,
+	0x3b, 0x81, 0x55, 0x6e, 0xe2, 0x35, 0x8f, 0x3b, 0xfd, 0x1d, 0xbd, 0x35, 0x95, 0xd5, 0x58, 0x80,
+	0x3b, 0x2b, 0x7c, 0x2c, 0xe2, 0x77, 0x61, 0x83, 0xb2, 0x22, 0x8e, 0x44, 0x98, 0x16, 0x8d, 0xd7,
+	0x26, 0xe3, 0xdd, 0x1a, 0x21, 0x47, 0x16, 0xfc, 0x16, 0xda, 0xe8, 0x63, 0x80, 0x61, 0xaa, 0x27,
+	0x9a, 0x25, 0x8a, 0x81, 0x2f, 0x2a, 0x7b, 0xc0, 0x01, 0x4f, 0xb9, 0x1a, 0x73, 0x9c, 0x96, 0xb9,
+	0xa2, 0x0e, 0x6a, 0x3e, 0x0d, 0xd5, 0x20, 0xab, 0x26, 0x0a, 0x8f, 0x3e, 0xbe, 0x1b, 0x4e, 0x01,
+	0x32, 0x39, 0x23, 0xaf, 0x54, 0xcc, 0xc8, 0xfb, 0x00, 0x98, 0x67, 0x96, 0xb5, 0x4a, 0x96, 0xf8,
+	0x72, 0xaa, 0x25, 0x46, 0x49, 0xe8, 0x14, 0xae, 0xb1, 0xe7, 0x00, 0x7a, 0x56, 0xa2, 0x74, 0x59,
+	0x23, 0x26, 0xf6, 0x54, 0x26, 0x79, 0x82, 0x39, 0xcd, 0x6e, 0xf6, 0x73, 0xca, 0xd6, 0x81, 0x4d,
+	0xd9, 0x3a, 0xdc, 0x83, 0x36, 0x51, 0x67, 0x1e, 0xbc, 0xa5, 0xa7, 0x26, 0x05, 0xcb, 0x7c, 0x52,
+	0xd8, 0xb7, 0xad, 0x97, 0xf7, 0x6d, 0x8f, 0x61, 0xf5, 0x40, 0x46, 0x71, 0x69, 0x2e, 0x29, 0x0c,
+	0x15, 0xb5, 0xd2, 0x50, 0xb1, 0xfb, 0xfd, 0x02, 0x00, 0x91, 0xee, 0x47, 0x91, 0xf4, 0x58, 0x0c,
+	0xec, 0x25, 0xa6, 0xfb, 0x51, 0x10, 0x47, 0x21, 0x86, 0xa9, 0xde, 0xd7, 0xb1, 0xaf, 0xa7, 0x2c,
+	0x41, 0x27, 0x49, 0x8d, 0xc0, 0xce, 0x83, 0x29, 0x37, 0xc6, 0xc8, 0xed, 0x1b, 0x2c, 0x20, 0x89,
+	0xaf, 0x45, 0x80, 0xaf, 0x45, 0xef, 0x7c, 0xff, 0x8c, 0x87, 0x21, 0xfa, 0x17, 0x49, 0x1c, 0x23,
+	0xcd, 0x24, 0x8e, 0x79, 0xd5, 0x1c, 0x4e, 0x52, 0x29, 0xc2, 0xd3, 0xac, 0x6b, 0xd9, 0x37, 0xd8,
+	0x5b, 0x58, 0x7f, 0x89, 0x24, 0x5d, 0x24, 0xa9, 0xe8, 0x25, 0x99, 0xc0, 0xdd, 0xe9, 0x02, 0x27,
+	0x88, 0x3f, 0x51, 0xe4, 0x6f, 0x01, 0x46, 0x05, 0x89, 0xcd, 0x56, 0xb0, 0x3a, 0x0f, 0x2e, 0x23,
+	0xcb, 0xd9, 0x0b, 0x58, 0x2e, 0xaf, 0x57, 0xd9, 0xc3, 0xaa, 0xbb, 0x95, 0x6b, 0xe9, 0xce, 0xa3,
+	0x59, 0x48, 0x73, 0x51, 0x12, 0xd6, 0x26, 0x26, 0x02, 0xf6, 0xf8, 0x22, 0x16, 0xe3, 0x43, 0x51,
+	0xe7, 0xc9, 0x8c, 0xd4, 0xb9, 0xcc, 0x63, 0x68, 0xe6, 0xe1, 0xcc, 0xbe, 0xaa, 0xde, 0x44, 0x95,
+	0xa3, 0xbd, 0x73, 0xd1, 0x2c, 0x62, 0xdf, 0x60, 0x2e, 0xc0, 0x4b, 0x4c, 0x8f, 0x30, 0x95, 0xa2,
+	0x97, 0xb0, 0x07, 0x95, 0x4e, 0x1c, 0x11, 0x64, 0x4c, 0x7f, 0x74, 0x29, 0x5d, 0xa6, 0xf2, 0xee,
+	0x9f, 0x9b, 0xa6, 0x55, 0xaa, 0xff, 0x24, 0xfe, 0x97, 0x52, 0x3f, 0x40, 0x4a, 0xbd, 0x86, 0x56,
+	0x61, 0x0b, 0xca, 0x2a, 0x93, 0x65, 0x72, 0xd9, 0x7f, 0x59, 0x60, 0xf8, 0xb0, 0x36, 0xb1, 0x61,
+	0x9d, 0x99, 0xf7, 0x93, 0x0b, 0x96, 0xa4, 0x93, 0x0b, 0x5b, 0xfb, 0x06, 0x7b, 0x05, 0x8d, 0x6c,
+	0x05, 0xc8, 0x2a, 0x5b, 0xd2, 0xd8, 0x82, 0xf0, 0x32, 0xed, 0x05, 0x2c, 0x97, 0x77, 0x6e, 0xd5,
+	0x75, 0xa0, 0x72, 0x61, 0xd8, 0x79, 0x34, 0x0b, 0x69, 0xae, 0xfa, 0x07, 0xb8, 0x55, 0xb1, 0xf2,
+	0x62, 0xdb, 0x55, 0x4c, 0xa6, 0x2f, 0xe4, 0x3a, 0x3b, 0x33, 0xd3, 0x17, 0x2b, 0xd0, 0xc4, 0x5a,
+	0xa6, 0xba, 0x02, 0x4d, 0x5b, 0x5d, 0x75, 0x9e, 0xcc, 0x48, 0x5d, 0x90, 0xd9, 0x99, 0xbe, 0x41,
+	0x60, 0xcf, 0x2a, 0xd9, 0x5d, 0xb6, 0x71, 0xf8, 0x6f, 0xd7, 0xa8, 0xbd, 0x9f, 0xfc, 0x66, 0xf7,
+	0x54, 0xa4, 0x67, 0x83, 0xae, 0x12, 0xbd, 0xa3, 0x29, 0x9f, 0x88, 0xc8, 0xfc, 0xda, 0xc9, 0x92,
+	0x75, 0x87, 0x38, 0xed, 0xd0, 0xab, 0xe2, 0x6e, 0x77, 0x81, 0x8e, 0x4f, 0xff, 0x15, 0x00, 0x00,
+	0xff, 0xff, 0xa4, 0x72, 0xb3, 0x13, 0x74, 0x1e, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1215,6 +2427,19 @@ type IndexNodeClient interface {
 	GetTimeTickChannel(ctx context.Context, in *internalpb.GetTimeTickChannelRequest, opts ...grpc.CallOption) (*milvuspb.StringResponse, error)
 	GetStatisticsChannel(ctx context.Context, in *internalpb.GetStatisticsChannelRequest, opts ...grpc.CallOption) (*milvuspb.StringResponse, error)
 	CreateIndex(ctx context.Context, in *CreateIndexRequest, opts ...grpc.CallOption) (*commonpb.Status, error)
+	// DryRunCreateIndex validates the build request without building and saving the index
+	DryRunCreateIndex(ctx context.Context, in *CreateIndexRequest, opts ...grpc.CallOption) (*DryRunCreateIndexResponse, error)
+	// Activate switches the standby IndexNode to active, so that it accepts the build requests
+	Activate(ctx context.Context, in *ActivateRequest, opts ...grpc.CallOption) (*commonpb.Status, error)
+	// CaptureProfile captures a profile of IndexNode and uploads it to the object storage
+	CaptureProfile(ctx context.Context, in *CaptureProfileRequest, opts ...grpc.CallOption) (*CaptureProfileResponse, error)
+	// VerifyIndexManifest re-checks the index files of a build against the manifest saved along with them
+	VerifyIndexManifest(ctx context.Context, in *VerifyIndexManifestRequest, opts ...grpc.CallOption) (*VerifyIndexManifestResponse, error)
+	// RunBuildBenchmark builds an index of the synthetic vectors to measure the build throughput of IndexNode
+	RunBuildBenchmark(ctx context.Context, in *RunBuildBenchmarkRequest, opts ...grpc.CallOption) (*RunBuildBenchmarkResponse, error)
+	// RebuildIncompatibleIndexes starts to rebuild the indexes built by IndexNode which can not be loaded by the
+	// current engine, the progress is returned by GetMetrics of index_rebuild_progress
+	RebuildIncompatibleIndexes(ctx context.Context, in *RebuildIncompatibleIndexesRequest, opts ...grpc.CallOption) (*commonpb.Status, error)
 	// https://wiki.lfaidata.foundation/display/MIL/MEP+8+--+Add+metrics+for+proxy
 	GetMetrics(ctx context.Context, in *milvuspb.GetMetricsRequest, opts ...grpc.CallOption) (*milvuspb.GetMetricsResponse, error)
 }
@@ -1263,6 +2488,60 @@ func (c *indexNodeClient) CreateIndex(ctx context.Context, in *CreateIndexReques
 	return out, nil
 }
 
+func (c *indexNodeClient) DryRunCreateIndex(ctx context.Context, in *CreateIndexRequest, opts ...grpc.CallOption) (*DryRunCreateIndexResponse, error) {
+	out := new(DryRunCreateIndexResponse)
+	err := c.cc.Invoke(ctx, "/milvus.proto.index.IndexNode/DryRunCreateIndex", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexNodeClient) Activate(ctx context.Context, in *ActivateRequest, opts ...grpc.CallOption) (*commonpb.Status, error) {
+	out := new(commonpb.Status)
+	err := c.cc.Invoke(ctx, "/milvus.proto.index.IndexNode/Activate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexNodeClient) CaptureProfile(ctx context.Context, in *CaptureProfileRequest, opts ...grpc.CallOption) (*CaptureProfileResponse, error) {
+	out := new(CaptureProfileResponse)
+	err := c.cc.Invoke(ctx, "/milvus.proto.index.IndexNode/CaptureProfile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexNodeClient) VerifyIndexManifest(ctx context.Context, in *VerifyIndexManifestRequest, opts ...grpc.CallOption) (*VerifyIndexManifestResponse, error) {
+	out := new(VerifyIndexManifestResponse)
+	err := c.cc.Invoke(ctx, "/milvus.proto.index.IndexNode/VerifyIndexManifest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexNodeClient) RunBuildBenchmark(ctx context.Context, in *RunBuildBenchmarkRequest, opts ...grpc.CallOption) (*RunBuildBenchmarkResponse, error) {
+	out := new(RunBuildBenchmarkResponse)
+	err := c.cc.Invoke(ctx, "/milvus.proto.index.IndexNode/RunBuildBenchmark", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexNodeClient) RebuildIncompatibleIndexes(ctx context.Context, in *RebuildIncompatibleIndexesRequest, opts ...grpc.CallOption) (*commonpb.Status, error) {
+	out := new(commonpb.Status)
+	err := c.cc.Invoke(ctx, "/milvus.proto.index.IndexNode/RebuildIncompatibleIndexes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *indexNodeClient) GetMetrics(ctx context.Context, in *milvuspb.GetMetricsRequest, opts ...grpc.CallOption) (*milvuspb.GetMetricsResponse, error) {
 	out := new(milvuspb.GetMetricsResponse)
 	err := c.cc.Invoke(ctx, "/milvus.proto.index.IndexNode/GetMetrics", in, out, opts...)
@@ -1278,6 +2557,19 @@ type IndexNodeServer interface {
 	GetTimeTickChannel(context.Context, *internalpb.GetTimeTickChannelRequest) (*milvuspb.StringResponse, error)
 	GetStatisticsChannel(context.Context, *internalpb.GetStatisticsChannelRequest) (*milvuspb.StringResponse, error)
 	CreateIndex(context.Context, *CreateIndexRequest) (*commonpb.Status, error)
+	// DryRunCreateIndex validates the build request without building and saving the index
+	DryRunCreateIndex(context.Context, *CreateIndexRequest) (*DryRunCreateIndexResponse, error)
+	// Activate switches the standby IndexNode to active, so that it accepts the build requests
+	Activate(context.Context, *ActivateRequest) (*commonpb.Status, error)
+	// CaptureProfile captures a profile of IndexNode and uploads it to the object storage
+	CaptureProfile(context.Context, *CaptureProfileRequest) (*CaptureProfileResponse, error)
+	// VerifyIndexManifest re-checks the index files of a build against the manifest saved along with them
+	VerifyIndexManifest(context.Context, *VerifyIndexManifestRequest) (*VerifyIndexManifestResponse, error)
+	// RunBuildBenchmark builds an index of the synthetic vectors to measure the build throughput of IndexNode
+	RunBuildBenchmark(context.Context, *RunBuildBenchmarkRequest) (*RunBuildBenchmarkResponse, error)
+	// RebuildIncompatibleIndexes starts to rebuild the indexes built by IndexNode which can not be loaded by the
+	// current engine, the progress is returned by GetMetrics of index_rebuild_progress
+	RebuildIncompatibleIndexes(context.Context, *RebuildIncompatibleIndexesRequest) (*commonpb.Status, error)
 	// https://wiki.lfaidata.foundation/display/MIL/MEP+8+--+Add+metrics+for+proxy
 	GetMetrics(context.Context, *milvuspb.GetMetricsRequest) (*milvuspb.GetMetricsResponse, error)
 }
@@ -1298,6 +2590,24 @@ func (*UnimplementedIndexNodeServer) GetStatisticsChannel(ctx context.Context, r
 func (*UnimplementedIndexNodeServer) CreateIndex(ctx context.Context, req *CreateIndexRequest) (*commonpb.Status, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateIndex not implemented")
 }
+func (*UnimplementedIndexNodeServer) DryRunCreateIndex(ctx context.Context, req *CreateIndexRequest) (*DryRunCreateIndexResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DryRunCreateIndex not implemented")
+}
+func (*UnimplementedIndexNodeServer) Activate(ctx context.Context, req *ActivateRequest) (*commonpb.Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Activate not implemented")
+}
+func (*UnimplementedIndexNodeServer) CaptureProfile(ctx context.Context, req *CaptureProfileRequest) (*CaptureProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CaptureProfile not implemented")
+}
+func (*UnimplementedIndexNodeServer) VerifyIndexManifest(ctx context.Context, req *VerifyIndexManifestRequest) (*VerifyIndexManifestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyIndexManifest not implemented")
+}
+func (*UnimplementedIndexNodeServer) RunBuildBenchmark(ctx context.Context, req *RunBuildBenchmarkRequest) (*RunBuildBenchmarkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunBuildBenchmark not implemented")
+}
+func (*UnimplementedIndexNodeServer) RebuildIncompatibleIndexes(ctx context.Context, req *RebuildIncompatibleIndexesRequest) (*commonpb.Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RebuildIncompatibleIndexes not implemented")
+}
 func (*UnimplementedIndexNodeServer) GetMetrics(ctx context.Context, req *milvuspb.GetMetricsRequest) (*milvuspb.GetMetricsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMetrics not implemented")
 }
@@ -1378,6 +2688,114 @@ func _IndexNode_CreateIndex_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _IndexNode_DryRunCreateIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexNodeServer).DryRunCreateIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/milvus.proto.index.IndexNode/DryRunCreateIndex",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexNodeServer).DryRunCreateIndex(ctx, req.(*CreateIndexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexNode_Activate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActivateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexNodeServer).Activate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/milvus.proto.index.IndexNode/Activate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexNodeServer).Activate(ctx, req.(*ActivateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexNode_CaptureProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CaptureProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexNodeServer).CaptureProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/milvus.proto.index.IndexNode/CaptureProfile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexNodeServer).CaptureProfile(ctx, req.(*CaptureProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexNode_VerifyIndexManifest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyIndexManifestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexNodeServer).VerifyIndexManifest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/milvus.proto.index.IndexNode/VerifyIndexManifest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexNodeServer).VerifyIndexManifest(ctx, req.(*VerifyIndexManifestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexNode_RunBuildBenchmark_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunBuildBenchmarkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexNodeServer).RunBuildBenchmark(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/milvus.proto.index.IndexNode/RunBuildBenchmark",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexNodeServer).RunBuildBenchmark(ctx, req.(*RunBuildBenchmarkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexNode_RebuildIncompatibleIndexes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RebuildIncompatibleIndexesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexNodeServer).RebuildIncompatibleIndexes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/milvus.proto.index.IndexNode/RebuildIncompatibleIndexes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexNodeServer).RebuildIncompatibleIndexes(ctx, req.(*RebuildIncompatibleIndexesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _IndexNode_GetMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(milvuspb.GetMetricsRequest)
 	if err := dec(in); err != nil {
@@ -1416,6 +2834,30 @@ var _IndexNode_serviceDesc = grpc.ServiceDesc{
 			MethodName: "CreateIndex",
 			Handler:    _IndexNode_CreateIndex_Handler,
 		},
+		{
+			MethodName: "DryRunCreateIndex",
+			Handler:    _IndexNode_DryRunCreateIndex_Handler,
+		},
+		{
+			MethodName: "Activate",
+			Handler:    _IndexNode_Activate_Handler,
+		},
+		{
+			MethodName: "CaptureProfile",
+			Handler:    _IndexNode_CaptureProfile_Handler,
+		},
+		{
+			MethodName: "VerifyIndexManifest",
+			Handler:    _IndexNode_VerifyIndexManifest_Handler,
+		},
+		{
+			MethodName: "RunBuildBenchmark",
+			Handler:    _IndexNode_RunBuildBenchmark_Handler,
+		},
+		{
+			MethodName: "RebuildIncompatibleIndexes",
+			Handler:    _IndexNode_RebuildIncompatibleIndexes_Handler,
+		},
 		{
 			MethodName: "GetMetrics",
 			Handler:    _IndexNode_GetMetrics_Handler,
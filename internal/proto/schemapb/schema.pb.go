@@ -37,6 +37,9 @@ const (
 	DataType_String       DataType = 20
 	DataType_BinaryVector DataType = 100
 	DataType_FloatVector  DataType = 101
+	// the half-precision float vectors, each element takes 2 bytes
+	DataType_Float16Vector  DataType = 102
+	DataType_BFloat16Vector DataType = 103
 )
 
 var DataType_name = map[int32]string{
@@ -51,20 +54,24 @@ var DataType_name = map[int32]string{
 	20:  "String",
 	100: "BinaryVector",
 	101: "FloatVector",
+	102: "Float16Vector",
+	103: "BFloat16Vector",
 }
 
 var DataType_value = map[string]int32{
-	"None":         0,
-	"Bool":         1,
-	"Int8":         2,
-	"Int16":        3,
-	"Int32":        4,
-	"Int64":        5,
-	"Float":        10,
-	"Double":       11,
-	"String":       20,
-	"BinaryVector": 100,
-	"FloatVector":  101,
+	"None":           0,
+	"Bool":           1,
+	"Int8":           2,
+	"Int16":          3,
+	"Int32":          4,
+	"Int64":          5,
+	"Float":          10,
+	"Double":         11,
+	"String":         20,
+	"BinaryVector":   100,
+	"FloatVector":    101,
+	"Float16Vector":  102,
+	"BFloat16Vector": 103,
 }
 
 func (x DataType) String() string {
@@ -1038,66 +1045,67 @@ func init() {
 func init() { proto.RegisterFile("schema.proto", fileDescriptor_1c5fb4d8cc22d66a) }
 
 var fileDescriptor_1c5fb4d8cc22d66a = []byte{
-	// 963 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0xdd, 0x8e, 0xdb, 0x44,
-	0x14, 0x8e, 0xe3, 0xfc, 0xd8, 0xc7, 0xa1, 0x58, 0xd3, 0x0a, 0x19, 0xa4, 0x76, 0xd3, 0x08, 0xa4,
-	0xa8, 0x12, 0xbb, 0xea, 0x2e, 0x94, 0x52, 0x51, 0x01, 0x69, 0xb4, 0x4a, 0xb4, 0xa8, 0x5a, 0xbc,
-	0xa8, 0x17, 0xdc, 0x44, 0x93, 0x78, 0xba, 0x3b, 0x5a, 0x7b, 0x26, 0x78, 0x26, 0x15, 0x79, 0x00,
-	0xae, 0xb9, 0xe1, 0x8a, 0x77, 0xe3, 0x02, 0xf1, 0x1c, 0x48, 0x68, 0xce, 0x4c, 0x12, 0xd3, 0x78,
-	0x57, 0x7b, 0x77, 0x66, 0x7c, 0xbe, 0x6f, 0xce, 0xf9, 0xce, 0x8f, 0xa1, 0xa7, 0x16, 0x57, 0xac,
-	0xa0, 0x87, 0xcb, 0x52, 0x6a, 0x49, 0xee, 0x17, 0x3c, 0x7f, 0xb7, 0x52, 0xf6, 0x74, 0x68, 0x3f,
-	0x7d, 0xd2, 0x5b, 0xc8, 0xa2, 0x90, 0xc2, 0x5e, 0x0e, 0xfe, 0x6e, 0x42, 0x74, 0xca, 0x59, 0x9e,
-	0x5d, 0xe0, 0x57, 0x92, 0x40, 0xf7, 0xad, 0x39, 0x4e, 0xc7, 0x89, 0xd7, 0xf7, 0x86, 0x7e, 0xba,
-	0x39, 0x12, 0x02, 0x2d, 0x41, 0x0b, 0x96, 0x34, 0xfb, 0xde, 0x30, 0x4c, 0xd1, 0x26, 0x9f, 0xc2,
-	0x3d, 0xae, 0x66, 0xcb, 0x92, 0x17, 0xb4, 0x5c, 0xcf, 0xae, 0xd9, 0x3a, 0xf1, 0xfb, 0xde, 0x30,
-	0x48, 0x7b, 0x5c, 0x9d, 0xdb, 0xcb, 0x33, 0xb6, 0x26, 0x7d, 0x88, 0x32, 0xa6, 0x16, 0x25, 0x5f,
-	0x6a, 0x2e, 0x45, 0xd2, 0x42, 0x82, 0xea, 0x15, 0x79, 0x01, 0x61, 0x46, 0x35, 0x9d, 0xe9, 0xf5,
-	0x92, 0x25, 0xed, 0xbe, 0x37, 0xbc, 0x77, 0xfc, 0xf0, 0xb0, 0x26, 0xf8, 0xc3, 0x31, 0xd5, 0xf4,
-	0xa7, 0xf5, 0x92, 0xa5, 0x41, 0xe6, 0x2c, 0x32, 0x82, 0xc8, 0xc0, 0x66, 0x4b, 0x5a, 0xd2, 0x42,
-	0x25, 0x9d, 0xbe, 0x3f, 0x8c, 0x8e, 0x1f, 0xff, 0x1f, 0xed, 0x52, 0x3e, 0x63, 0xeb, 0x37, 0x34,
-	0x5f, 0xb1, 0x73, 0xca, 0xcb, 0x14, 0x0c, 0xea, 0x1c, 0x41, 0x64, 0x0c, 0x3d, 0x2e, 0x32, 0xf6,
-	0xeb, 0x86, 0xa4, 0x7b, 0x57, 0x92, 0x08, 0x61, 0x8e, 0xe5, 0x23, 0xe8, 0xd0, 0x95, 0x96, 0xd3,
-	0x71, 0x12, 0xa0, 0x0a, 0xee, 0x34, 0xf8, 0xd3, 0x83, 0xf8, 0x95, 0xcc, 0x73, 0xb6, 0x30, 0xc9,
-	0x3a, 0xa1, 0x37, 0x72, 0x7a, 0x15, 0x39, 0xdf, 0x13, 0xaa, 0xb9, 0x2f, 0xd4, 0xee, 0x09, 0xbf,
-	0xfa, 0x04, 0x79, 0x0e, 0x1d, 0xac, 0x93, 0x4a, 0x5a, 0x18, 0x7a, 0xbf, 0x56, 0xbd, 0x4a, 0xa1,
-	0x53, 0xe7, 0x3f, 0x38, 0x80, 0x70, 0x24, 0x65, 0xfe, 0x7d, 0x59, 0xd2, 0xb5, 0x09, 0xca, 0xe8,
-	0x9a, 0x78, 0x7d, 0x7f, 0x18, 0xa4, 0x68, 0x0f, 0x1e, 0x41, 0x30, 0x15, 0x7a, 0xff, 0x7b, 0xdb,
-	0x7d, 0x3f, 0x80, 0xf0, 0x07, 0x29, 0x2e, 0xf7, 0x1d, 0x7c, 0xe7, 0xd0, 0x07, 0x38, 0xcd, 0x25,
-	0xad, 0xa1, 0x68, 0x3a, 0x8f, 0xc7, 0x10, 0x8d, 0xe5, 0x6a, 0x9e, 0xb3, 0x7d, 0x17, 0x6f, 0x47,
-	0x32, 0x5a, 0x6b, 0xa6, 0xf6, 0x3d, 0x7a, 0x3b, 0x92, 0x0b, 0x5d, 0xf2, 0xba, 0x48, 0x42, 0xe7,
-	0xf2, 0x97, 0x0f, 0xd1, 0xc5, 0x82, 0xe6, 0xb4, 0x44, 0x25, 0xc8, 0x4b, 0x08, 0xe7, 0x52, 0xe6,
-	0x33, 0xe7, 0xe8, 0x0d, 0xa3, 0xe3, 0x47, 0xb5, 0xc2, 0x6d, 0x15, 0x9a, 0x34, 0xd2, 0xc0, 0x40,
-	0x4c, 0x1f, 0x92, 0x17, 0x10, 0x70, 0xa1, 0x2d, 0xba, 0x89, 0xe8, 0xfa, 0xa6, 0xdd, 0xc8, 0x37,
-	0x69, 0xa4, 0x5d, 0x2e, 0x34, 0x62, 0x5f, 0x42, 0x98, 0x4b, 0x71, 0x69, 0xc1, 0xfe, 0x2d, 0x4f,
-	0x6f, 0xb5, 0x35, 0x4f, 0x1b, 0x08, 0xc2, 0xbf, 0x03, 0x78, 0x6b, 0x34, 0xb5, 0xf8, 0x16, 0xe2,
-	0x0f, 0xea, 0x6b, 0xbe, 0x95, 0x7e, 0xd2, 0x48, 0x43, 0x04, 0x21, 0xc3, 0x2b, 0x88, 0x32, 0xd4,
-	0xdc, 0x52, 0xb4, 0x91, 0xa2, 0xbe, 0x6d, 0x2a, 0xb5, 0x99, 0x34, 0x52, 0xb0, 0xb0, 0x0d, 0x89,
-	0x42, 0xcd, 0x2d, 0x49, 0xe7, 0x16, 0x92, 0x4a, 0x6d, 0x0c, 0x89, 0x85, 0x6d, 0x72, 0x99, 0x9b,
-	0xd2, 0x5a, 0x8e, 0xee, 0x2d, 0xb9, 0xec, 0x3a, 0xc0, 0xe4, 0x82, 0x20, 0xc3, 0x30, 0xea, 0xd8,
-	0x5a, 0x0f, 0xfe, 0xf0, 0x20, 0x7a, 0xc3, 0x16, 0x5a, 0xba, 0xfa, 0xc6, 0xe0, 0x67, 0xbc, 0x70,
-	0x8b, 0xcc, 0x98, 0x66, 0xd0, 0xad, 0x6e, 0xef, 0xd0, 0xcd, 0x95, 0xed, 0x0e, 0xca, 0x45, 0x08,
-	0xb3, 0xe4, 0xe4, 0x33, 0xf8, 0x60, 0xce, 0x85, 0x59, 0x79, 0x8e, 0xc6, 0x14, 0xb0, 0x37, 0x69,
-	0xa4, 0x3d, 0x7b, 0x6d, 0xdd, 0xb6, 0x61, 0xfd, 0xeb, 0x41, 0x88, 0x01, 0x61, 0xba, 0x4f, 0xa1,
-	0x85, 0x6b, 0xce, 0xbb, 0xcb, 0x9a, 0x43, 0x57, 0xf2, 0x10, 0x00, 0xa7, 0x75, 0x56, 0x59, 0xc0,
-	0x21, 0xde, 0xbc, 0x36, 0x6b, 0xe3, 0x1b, 0xe8, 0x2a, 0xec, 0x6a, 0xe5, 0x3a, 0xe9, 0x86, 0x0a,
-	0xec, 0x3a, 0xdf, 0x74, 0xa2, 0x83, 0x18, 0xb4, 0xcd, 0x42, 0xb9, 0x3e, 0xaa, 0x47, 0x57, 0x74,
-	0x35, 0x68, 0x07, 0x21, 0x1f, 0x43, 0x60, 0x43, 0xe3, 0x19, 0xf6, 0xd0, 0xf6, 0x87, 0x91, 0x8d,
-	0xba, 0xd0, 0x46, 0x73, 0xf0, 0x9b, 0x07, 0xfe, 0x74, 0xac, 0xc8, 0x57, 0xd0, 0x31, 0xf3, 0xc2,
-	0xb3, 0x5b, 0x67, 0xad, 0xda, 0xf0, 0x6d, 0x2e, 0xf4, 0x34, 0x23, 0x5f, 0x43, 0x47, 0xe9, 0xd2,
-	0x00, 0x9b, 0x77, 0xee, 0xb0, 0xb6, 0xd2, 0xe5, 0x34, 0x1b, 0x01, 0x04, 0x3c, 0x9b, 0xd9, 0x38,
-	0xfe, 0xf1, 0x20, 0xbe, 0x60, 0xb4, 0x5c, 0x5c, 0xa5, 0x4c, 0xad, 0x72, 0x3b, 0x07, 0x07, 0x10,
-	0x89, 0x55, 0x31, 0xfb, 0x65, 0xc5, 0x4a, 0xce, 0x94, 0xeb, 0x15, 0x10, 0xab, 0xe2, 0x47, 0x7b,
-	0x43, 0xee, 0x43, 0x5b, 0xcb, 0xe5, 0xec, 0x1a, 0xdf, 0xf6, 0xd3, 0x96, 0x96, 0xcb, 0x33, 0xf2,
-	0x2d, 0x44, 0x76, 0x7f, 0x6e, 0x06, 0xd8, 0xbf, 0x31, 0x9f, 0x6d, 0xe5, 0x53, 0x5b, 0x44, 0x6c,
-	0x59, 0xb3, 0xc8, 0xd5, 0x42, 0x96, 0xcc, 0x2e, 0xec, 0x66, 0xea, 0x4e, 0xe4, 0x09, 0xf8, 0x3c,
-	0x53, 0x6e, 0x1c, 0x93, 0xfa, 0x75, 0x32, 0x56, 0xa9, 0x71, 0x22, 0x0f, 0x30, 0xb2, 0x6b, 0xfb,
-	0xcf, 0xf3, 0x53, 0x7b, 0x78, 0xf2, 0xbb, 0x07, 0xc1, 0xa6, 0x7f, 0x48, 0x00, 0xad, 0xd7, 0x52,
-	0xb0, 0xb8, 0x61, 0x2c, 0xb3, 0xc5, 0x62, 0xcf, 0x58, 0x53, 0xa1, 0x9f, 0xc7, 0x4d, 0x12, 0x42,
-	0x7b, 0x2a, 0xf4, 0xd3, 0x67, 0xb1, 0xef, 0xcc, 0x93, 0xe3, 0xb8, 0xe5, 0xcc, 0x67, 0x5f, 0xc4,
-	0x6d, 0x63, 0xe2, 0x14, 0xc4, 0x40, 0x00, 0x3a, 0x76, 0x0f, 0xc4, 0x91, 0xb1, 0xad, 0xd8, 0xf1,
-	0x03, 0x12, 0x43, 0x6f, 0x54, 0x69, 0xfa, 0x38, 0x23, 0x1f, 0x42, 0x74, 0xba, 0x1b, 0x96, 0x98,
-	0x8d, 0xbe, 0xfc, 0xf9, 0xe4, 0x92, 0xeb, 0xab, 0xd5, 0xdc, 0xfc, 0x42, 0x8f, 0x6c, 0x4a, 0x9f,
-	0x73, 0xe9, 0xac, 0x23, 0x2e, 0x34, 0x2b, 0x05, 0xcd, 0x8f, 0x30, 0xcb, 0x23, 0x9b, 0xe5, 0x72,
-	0x3e, 0xef, 0xe0, 0xf9, 0xe4, 0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x88, 0x3c, 0xbb, 0x94, 0xd4,
-	0x08, 0x00, 0x00,
+	// 980 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0xef, 0x6e, 0xe3, 0x44,
+	0x10, 0xcf, 0xc6, 0x71, 0x62, 0x8f, 0x73, 0xc5, 0xec, 0x9d, 0x90, 0x41, 0xba, 0xab, 0x2f, 0x02,
+	0x29, 0x3a, 0x89, 0x56, 0x6d, 0xa1, 0x1c, 0x27, 0x4e, 0x40, 0x2e, 0xaa, 0x12, 0x15, 0x9d, 0x8a,
+	0x8b, 0xee, 0x03, 0x5f, 0x22, 0x27, 0xde, 0xb6, 0xab, 0xda, 0xbb, 0xc1, 0xbb, 0x3e, 0x91, 0x07,
+	0xe0, 0x0d, 0xf8, 0xc4, 0x83, 0xf0, 0x36, 0x7c, 0x40, 0x3c, 0x07, 0x12, 0xda, 0x3f, 0x49, 0x5c,
+	0x92, 0x46, 0xfd, 0x36, 0x3b, 0x9e, 0xdf, 0x6f, 0x67, 0x7e, 0x33, 0x3b, 0x86, 0xae, 0x98, 0xdd,
+	0x90, 0x22, 0x3d, 0x98, 0x97, 0x5c, 0x72, 0xfc, 0xb8, 0xa0, 0xf9, 0xfb, 0x4a, 0x98, 0xd3, 0x81,
+	0xf9, 0xf4, 0x49, 0x77, 0xc6, 0x8b, 0x82, 0x33, 0xe3, 0xec, 0xfd, 0xdd, 0x84, 0xe0, 0x8c, 0x92,
+	0x3c, 0xbb, 0xd4, 0x5f, 0x71, 0x04, 0x9d, 0x2b, 0x75, 0x1c, 0x0f, 0x23, 0x14, 0xa3, 0xbe, 0x93,
+	0x2c, 0x8f, 0x18, 0x43, 0x8b, 0xa5, 0x05, 0x89, 0x9a, 0x31, 0xea, 0xfb, 0x89, 0xb6, 0xf1, 0xa7,
+	0xb0, 0x47, 0xc5, 0x64, 0x5e, 0xd2, 0x22, 0x2d, 0x17, 0x93, 0x5b, 0xb2, 0x88, 0x9c, 0x18, 0xf5,
+	0xbd, 0xa4, 0x4b, 0xc5, 0x85, 0x71, 0x9e, 0x93, 0x05, 0x8e, 0x21, 0xc8, 0x88, 0x98, 0x95, 0x74,
+	0x2e, 0x29, 0x67, 0x51, 0x4b, 0x13, 0xd4, 0x5d, 0xf8, 0x15, 0xf8, 0x59, 0x2a, 0xd3, 0x89, 0x5c,
+	0xcc, 0x49, 0xe4, 0xc6, 0xa8, 0xbf, 0x77, 0xfc, 0xf4, 0x60, 0x4b, 0xf2, 0x07, 0xc3, 0x54, 0xa6,
+	0x3f, 0x2d, 0xe6, 0x24, 0xf1, 0x32, 0x6b, 0xe1, 0x01, 0x04, 0x0a, 0x36, 0x99, 0xa7, 0x65, 0x5a,
+	0x88, 0xa8, 0x1d, 0x3b, 0xfd, 0xe0, 0xf8, 0xf9, 0x5d, 0xb4, 0x2d, 0xf9, 0x9c, 0x2c, 0xde, 0xa5,
+	0x79, 0x45, 0x2e, 0x52, 0x5a, 0x26, 0xa0, 0x50, 0x17, 0x1a, 0x84, 0x87, 0xd0, 0xa5, 0x2c, 0x23,
+	0xbf, 0x2e, 0x49, 0x3a, 0x0f, 0x25, 0x09, 0x34, 0xcc, 0xb2, 0x7c, 0x04, 0xed, 0xb4, 0x92, 0x7c,
+	0x3c, 0x8c, 0x3c, 0xad, 0x82, 0x3d, 0xf5, 0xfe, 0x40, 0x10, 0xbe, 0xe1, 0x79, 0x4e, 0x66, 0xaa,
+	0x58, 0x2b, 0xf4, 0x52, 0x4e, 0x54, 0x93, 0xf3, 0x7f, 0x42, 0x35, 0x37, 0x85, 0x5a, 0x5f, 0xe1,
+	0xd4, 0xaf, 0xc0, 0x2f, 0xa1, 0xad, 0xfb, 0x24, 0xa2, 0x96, 0x4e, 0x3d, 0xde, 0xaa, 0x5e, 0xad,
+	0xd1, 0x89, 0x8d, 0xef, 0xed, 0x83, 0x3f, 0xe0, 0x3c, 0xff, 0xbe, 0x2c, 0xd3, 0x85, 0x4a, 0x4a,
+	0xe9, 0x1a, 0xa1, 0xd8, 0xe9, 0x7b, 0x89, 0xb6, 0x7b, 0xcf, 0xc0, 0x1b, 0x33, 0xb9, 0xf9, 0xdd,
+	0xb5, 0xdf, 0xf7, 0xc1, 0xff, 0x81, 0xb3, 0xeb, 0xcd, 0x00, 0xc7, 0x06, 0xc4, 0x00, 0x67, 0x39,
+	0x4f, 0xb7, 0x50, 0x34, 0x6d, 0xc4, 0x73, 0x08, 0x86, 0xbc, 0x9a, 0xe6, 0x64, 0x33, 0x04, 0xad,
+	0x49, 0x06, 0x0b, 0x49, 0xc4, 0x66, 0x44, 0x77, 0x4d, 0x72, 0x29, 0x4b, 0xba, 0x2d, 0x13, 0xdf,
+	0x86, 0xfc, 0xe5, 0x40, 0x70, 0x39, 0x4b, 0xf3, 0xb4, 0xd4, 0x4a, 0xe0, 0xd7, 0xe0, 0x4f, 0x39,
+	0xcf, 0x27, 0x36, 0x10, 0xf5, 0x83, 0xe3, 0x67, 0x5b, 0x85, 0x5b, 0x29, 0x34, 0x6a, 0x24, 0x9e,
+	0x82, 0xa8, 0x39, 0xc4, 0xaf, 0xc0, 0xa3, 0x4c, 0x1a, 0x74, 0x53, 0xa3, 0xb7, 0x0f, 0xed, 0x52,
+	0xbe, 0x51, 0x23, 0xe9, 0x50, 0x26, 0x35, 0xf6, 0x35, 0xf8, 0x39, 0x67, 0xd7, 0x06, 0xec, 0xec,
+	0xb8, 0x7a, 0xa5, 0xad, 0xba, 0x5a, 0x41, 0x34, 0xfc, 0x3b, 0x80, 0x2b, 0xa5, 0xa9, 0xc1, 0xb7,
+	0x34, 0x7e, 0x7f, 0x7b, 0xcf, 0x57, 0xd2, 0x8f, 0x1a, 0x89, 0xaf, 0x41, 0x9a, 0xe1, 0x0d, 0x04,
+	0x99, 0xd6, 0xdc, 0x50, 0xb8, 0x31, 0xba, 0x77, 0x6c, 0x6a, 0xbd, 0x19, 0x35, 0x12, 0x30, 0xb0,
+	0x25, 0x89, 0xd0, 0x9a, 0x1b, 0x92, 0xf6, 0x0e, 0x92, 0x5a, 0x6f, 0x14, 0x89, 0x81, 0x2d, 0x6b,
+	0x99, 0xaa, 0xd6, 0x1a, 0x8e, 0xce, 0x8e, 0x5a, 0xd6, 0x13, 0xa0, 0x6a, 0xd1, 0x20, 0xc5, 0x30,
+	0x68, 0x9b, 0x5e, 0xf7, 0x7e, 0x47, 0x10, 0xbc, 0x23, 0x33, 0xc9, 0x6d, 0x7f, 0x43, 0x70, 0x32,
+	0x5a, 0xd8, 0x45, 0xa6, 0x4c, 0xf5, 0xd0, 0x8d, 0x6e, 0xef, 0x75, 0x58, 0xd4, 0xdc, 0x71, 0xdb,
+	0x1d, 0xe5, 0x02, 0x0d, 0x33, 0xe4, 0xf8, 0x33, 0x78, 0x34, 0xa5, 0x4c, 0xad, 0x3c, 0x4b, 0xa3,
+	0x1a, 0xd8, 0x1d, 0x35, 0x92, 0xae, 0x71, 0x9b, 0xb0, 0x55, 0x5a, 0xff, 0x22, 0xf0, 0x75, 0x42,
+	0xba, 0xdc, 0x23, 0x68, 0xe9, 0x35, 0x87, 0x1e, 0xb2, 0xe6, 0x74, 0x28, 0x7e, 0x0a, 0xa0, 0x5f,
+	0xeb, 0xa4, 0xb6, 0x80, 0x7d, 0xed, 0x79, 0xab, 0xd6, 0xc6, 0x37, 0xd0, 0x11, 0x7a, 0xaa, 0x45,
+	0xe4, 0xec, 0xea, 0xc0, 0x7a, 0xf2, 0xd5, 0x24, 0x5a, 0x88, 0x42, 0x9b, 0x2a, 0x44, 0xd4, 0xda,
+	0x81, 0xae, 0xe9, 0xaa, 0xd0, 0x16, 0x82, 0x3f, 0x06, 0xcf, 0xa4, 0x46, 0xb3, 0xc8, 0xad, 0xff,
+	0x30, 0xb2, 0x41, 0x07, 0x5c, 0x6d, 0xf6, 0x7e, 0x43, 0xe0, 0x8c, 0x87, 0x02, 0x7f, 0x05, 0x6d,
+	0xf5, 0x5e, 0x68, 0x16, 0xa1, 0x07, 0x0e, 0xbc, 0x4b, 0x99, 0x1c, 0x67, 0xf8, 0x6b, 0x68, 0x0b,
+	0x59, 0x2a, 0x60, 0xf3, 0xc1, 0x13, 0xe6, 0x0a, 0x59, 0x8e, 0xb3, 0x01, 0x80, 0x47, 0xb3, 0x89,
+	0xc9, 0xe3, 0x1f, 0x04, 0xe1, 0x25, 0x49, 0xcb, 0xd9, 0x4d, 0x42, 0x44, 0x95, 0x9b, 0x77, 0xb0,
+	0x0f, 0x01, 0xab, 0x8a, 0xc9, 0x2f, 0x15, 0x29, 0x29, 0x11, 0x76, 0x56, 0x80, 0x55, 0xc5, 0x8f,
+	0xc6, 0x83, 0x1f, 0x83, 0x2b, 0xf9, 0x7c, 0x72, 0xab, 0xef, 0x76, 0x92, 0x96, 0xe4, 0xf3, 0x73,
+	0xfc, 0x2d, 0x04, 0x66, 0x7f, 0x2e, 0x1f, 0xb0, 0x73, 0x6f, 0x3d, 0xab, 0xce, 0x27, 0xa6, 0x89,
+	0x7a, 0x64, 0xd5, 0x22, 0x17, 0x33, 0x5e, 0x12, 0xb3, 0xb0, 0x9b, 0x89, 0x3d, 0xe1, 0x17, 0xe0,
+	0xd0, 0x4c, 0xd8, 0xe7, 0x18, 0x6d, 0x5f, 0x27, 0x43, 0x91, 0xa8, 0x20, 0xfc, 0x44, 0x67, 0x76,
+	0x6b, 0xfe, 0x79, 0x4e, 0x62, 0x0e, 0x2f, 0xfe, 0x44, 0xe0, 0x2d, 0xe7, 0x07, 0x7b, 0xd0, 0x7a,
+	0xcb, 0x19, 0x09, 0x1b, 0xca, 0x52, 0x5b, 0x2c, 0x44, 0xca, 0x1a, 0x33, 0xf9, 0x32, 0x6c, 0x62,
+	0x1f, 0xdc, 0x31, 0x93, 0x47, 0xa7, 0xa1, 0x63, 0xcd, 0x93, 0xe3, 0xb0, 0x65, 0xcd, 0xd3, 0x2f,
+	0x42, 0x57, 0x99, 0xfa, 0x15, 0x84, 0x80, 0x01, 0xda, 0x66, 0x0f, 0x84, 0x81, 0xb2, 0x8d, 0xd8,
+	0xe1, 0x13, 0x1c, 0x42, 0x77, 0x50, 0x1b, 0xfa, 0x30, 0xc3, 0x1f, 0x40, 0x70, 0xb6, 0x7e, 0x2c,
+	0x21, 0xc1, 0x1f, 0xc2, 0x23, 0xed, 0x38, 0x3a, 0xb5, 0xae, 0x2b, 0x8c, 0x61, 0x6f, 0x70, 0xd7,
+	0x77, 0x3d, 0xf8, 0xf2, 0xe7, 0x93, 0x6b, 0x2a, 0x6f, 0xaa, 0xa9, 0xfa, 0xd3, 0x1e, 0x9a, 0xca,
+	0x3f, 0xa7, 0xdc, 0x5a, 0x87, 0x94, 0x49, 0x52, 0xb2, 0x34, 0x3f, 0xd4, 0x62, 0x1c, 0x1a, 0x31,
+	0xe6, 0xd3, 0x69, 0x5b, 0x9f, 0x4f, 0xfe, 0x0b, 0x00, 0x00, 0xff, 0xff, 0xff, 0x4e, 0xcc, 0xa3,
+	0xfb, 0x08, 0x00, 0x00,
 }
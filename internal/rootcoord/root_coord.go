@@ -116,7 +116,7 @@ type Core struct {
 	CallGetFlushedSegmentsService func(ctx context.Context, collID, partID typeutil.UniqueID) ([]typeutil.UniqueID, error)
 
 	//call index builder's client to build index, return build id
-	CallBuildIndexService func(ctx context.Context, binlog []string, field *schemapb.FieldSchema, idxInfo *etcdpb.IndexInfo) (typeutil.UniqueID, error)
+	CallBuildIndexService func(ctx context.Context, binlog []string, numRows int64, field *schemapb.FieldSchema, idxInfo *etcdpb.IndexInfo) (typeutil.UniqueID, error)
 	CallDropIndexService  func(ctx context.Context, indexID typeutil.UniqueID) error
 
 	NewProxyClient func(sess *sessionutil.Session) (types.Proxy, error)
@@ -711,7 +711,7 @@ func (c *Core) SetIndexCoord(s types.IndexCoord) error {
 		}
 	}()
 
-	c.CallBuildIndexService = func(ctx context.Context, binlog []string, field *schemapb.FieldSchema, idxInfo *etcdpb.IndexInfo) (retID typeutil.UniqueID, retErr error) {
+	c.CallBuildIndexService = func(ctx context.Context, binlog []string, numRows int64, field *schemapb.FieldSchema, idxInfo *etcdpb.IndexInfo) (retID typeutil.UniqueID, retErr error) {
 		defer func() {
 			if err := recover(); err != nil {
 				retErr = fmt.Errorf("build index panic, msg = %v", err)
@@ -724,6 +724,8 @@ func (c *Core) SetIndexCoord(s types.IndexCoord) error {
 			IndexParams: idxInfo.IndexParams,
 			IndexID:     idxInfo.IndexID,
 			IndexName:   idxInfo.IndexName,
+			FieldSchema: field,
+			NumRows:     numRows,
 		})
 		if err != nil {
 			return retID, err
@@ -846,7 +848,7 @@ func (c *Core) BuildIndex(ctx context.Context, segID typeutil.UniqueID, field *s
 		if err != nil {
 			return 0, err
 		}
-		bldID, err = c.CallBuildIndexService(ctx, binlogs, field, idxInfo)
+		bldID, err = c.CallBuildIndexService(ctx, binlogs, rows, field, idxInfo)
 		if err != nil {
 			return 0, err
 		}
@@ -15,6 +15,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"strconv"
 
 	"errors"
@@ -97,3 +98,56 @@ func NewBinlogReader(data []byte) (*BinlogReader, error) {
 	}
 	return reader, nil
 }
+
+// BinlogHead is the descriptor of a binlog file along with the header of its first event.
+type BinlogHead struct {
+	DescriptorEventDataFixPart
+	// FirstEventType is the type code of the first event
+	FirstEventType EventTypeCode
+	// FirstEventEnd is the position where the first event ends, the first event can be read by
+	// NewBinlogReader from the head of the file in this length.
+	FirstEventEnd int64
+}
+
+// ReadBinlogHead reads the descriptor and the header of the first event from the head of a binlog file,
+// io.ErrUnexpectedEOF is returned if @head is too short to contain them.
+func ReadBinlogHead(head []byte) (*BinlogHead, error) {
+	buffer := bytes.NewBuffer(head)
+	var magicNumber int32
+	if err := binary.Read(buffer, binary.LittleEndian, &magicNumber); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if magicNumber != MagicNumber {
+		return nil, fmt.Errorf("parse magic number failed, expected: %s, actual: %s", strconv.Itoa(int(MagicNumber)), strconv.Itoa(int(magicNumber)))
+	}
+	descriptor, err := ReadDescriptorEvent(buffer)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	position := int(descriptor.descriptorEventHeader.NextPosition)
+	if position < 0 {
+		return nil, fmt.Errorf("invalid position of the first event: %d", position)
+	}
+	if position > len(head) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	header, err := readEventHeader(bytes.NewReader(head[position:]))
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if header.NextPosition <= int32(position) {
+		return nil, fmt.Errorf("invalid end position of the first event: %d", header.NextPosition)
+	}
+	return &BinlogHead{
+		DescriptorEventDataFixPart: descriptor.DescriptorEventDataFixPart,
+		FirstEventType:             header.TypeCode,
+		FirstEventEnd:              int64(header.NextPosition),
+	}, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -16,6 +16,7 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"io"
 	"testing"
 	"time"
 	"unsafe"
@@ -1326,3 +1327,42 @@ func TestWriterListError(t *testing.T) {
 	err = insertWriter.Close()
 	assert.NotNil(t, err)
 }
+
+func TestReadBinlogHead(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	err := binary.Write(buffer, binary.LittleEndian, int32(MagicNumber))
+	assert.Nil(t, err)
+	descriptor := newDescriptorEvent()
+	descriptor.CollectionID = 10
+	descriptor.FieldID = 40
+	descriptor.PayloadDataType = schemapb.DataType_FloatVector
+	err = descriptor.Write(buffer)
+	assert.Nil(t, err)
+	firstEventPos := int32(buffer.Len())
+	header := newEventHeader(InsertEventType)
+	header.EventLength = 100
+	header.NextPosition = firstEventPos + header.EventLength
+	err = header.Write(buffer)
+	assert.Nil(t, err)
+	data := buffer.Bytes()
+
+	head, err := ReadBinlogHead(data)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), head.CollectionID)
+	assert.Equal(t, int64(40), head.FieldID)
+	assert.Equal(t, schemapb.DataType_FloatVector, head.PayloadDataType)
+	assert.Equal(t, InsertEventType, head.FirstEventType)
+	assert.Equal(t, int64(firstEventPos+100), head.FirstEventEnd)
+
+	// too short to read the header of the first event
+	_, err = ReadBinlogHead(data[:firstEventPos+2])
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	_, err = ReadBinlogHead(data[:firstEventPos])
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	_, err = ReadBinlogHead(data[:2])
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	_, err = ReadBinlogHead([]byte{0, 0, 0, 0})
+	assert.NotNil(t, err)
+	assert.NotEqual(t, io.ErrUnexpectedEOF, err)
+}
@@ -87,6 +87,16 @@ CPayloadWriter NewPayloadWriter(int columnType) {
       p->dimension = wrapper::EMPTY_DIMENSION;
       break;
     }
+    case ColumnType::VECTOR_FLOAT16 : {
+      p->columnType = ColumnType::VECTOR_FLOAT16;
+      p->dimension = wrapper::EMPTY_DIMENSION;
+      break;
+    }
+    case ColumnType::VECTOR_BFLOAT16 : {
+      p->columnType = ColumnType::VECTOR_BFLOAT16;
+      p->dimension = wrapper::EMPTY_DIMENSION;
+      break;
+    }
     default: {
       delete p;
       return nullptr;
@@ -286,6 +296,60 @@ CStatus AddFloatVectorToPayload(CPayloadWriter payloadWriter, float *values, int
   return st;
 }
 
+extern "C"
+CStatus AddHalfFloatVectorToPayload(CPayloadWriter payloadWriter, uint8_t *values, int dimension, int length) {
+  CStatus st;
+  st.error_code = static_cast<int>(ErrorCode::SUCCESS);
+  st.error_msg = nullptr;
+  if (length <= 0) return st;
+
+  auto p = reinterpret_cast<wrapper::PayloadWriter *>(payloadWriter);
+  if (p->columnType != ColumnType::VECTOR_FLOAT16 && p->columnType != ColumnType::VECTOR_BFLOAT16) {
+    st.error_code = static_cast<int>(ErrorCode::UNEXPECTED_ERROR);
+    st.error_msg = ErrorMsg("incorrect data type");
+    return st;
+  }
+  if (p->dimension == wrapper::EMPTY_DIMENSION) {
+    if (dimension <= 0) {
+      st.error_code = static_cast<int>(ErrorCode::UNEXPECTED_ERROR);
+      st.error_msg = ErrorMsg("incorrect dimension value");
+      return st;
+    }
+    if (p->builder != nullptr) {
+      st.error_code = static_cast<int>(ErrorCode::UNEXPECTED_ERROR);
+      st.error_msg = ErrorMsg("incorrect data type");
+      return st;
+    }
+    p->builder = std::make_shared<arrow::FixedSizeBinaryBuilder>(
+        arrow::fixed_size_binary(dimension * sizeof(uint16_t)));
+    p->schema = arrow::schema({arrow::field("val", arrow::fixed_size_binary(dimension * sizeof(uint16_t)))});
+    p->dimension = dimension;
+  } else if (p->dimension != dimension) {
+    st.error_code = static_cast<int>(ErrorCode::UNEXPECTED_ERROR);
+    st.error_msg = ErrorMsg("dimension changed");
+    return st;
+  }
+  auto builder = std::dynamic_pointer_cast<arrow::FixedSizeBinaryBuilder>(p->builder);
+  if (builder == nullptr) {
+    st.error_code = static_cast<int>(ErrorCode::UNEXPECTED_ERROR);
+    st.error_msg = ErrorMsg("incorrect data type");
+    return st;
+  }
+  if (p->output != nullptr) {
+    st.error_code = static_cast<int>(ErrorCode::UNEXPECTED_ERROR);
+    st.error_msg = ErrorMsg("payload has finished");
+    return st;
+  }
+  auto ast = builder->AppendValues(values, length);
+  if (!ast.ok()) {
+    st.error_code = static_cast<int>(ErrorCode::UNEXPECTED_ERROR);
+    st.error_msg = ErrorMsg(ast.message());
+    return st;
+  }
+  p->rows += length;
+  return st;
+}
+
 extern "C"
 CStatus FinishPayloadWriter(CPayloadWriter payloadWriter) {
   CStatus st;
@@ -377,7 +441,9 @@ CPayloadReader NewPayloadReader(int columnType, uint8_t *buffer, int64_t buf_siz
     case ColumnType::DOUBLE :
     case ColumnType::STRING :
     case ColumnType::VECTOR_BINARY :
-    case ColumnType::VECTOR_FLOAT : {
+    case ColumnType::VECTOR_FLOAT :
+    case ColumnType::VECTOR_FLOAT16 :
+    case ColumnType::VECTOR_BFLOAT16 : {
       break;
     }
     default: {
@@ -518,6 +584,24 @@ CStatus GetFloatVectorFromPayload(CPayloadReader payloadReader, float **values,
   return st;
 }
 
+extern "C"
+CStatus GetHalfFloatVectorFromPayload(CPayloadReader payloadReader, uint8_t **values, int *dimension, int *length) {
+  CStatus st;
+  st.error_code = static_cast<int>(ErrorCode::SUCCESS);
+  st.error_msg = nullptr;
+  auto p = reinterpret_cast<wrapper::PayloadReader *>(payloadReader);
+  auto array = std::dynamic_pointer_cast<arrow::FixedSizeBinaryArray>(p->array);
+  if (array == nullptr) {
+    st.error_code = static_cast<int>(ErrorCode::UNEXPECTED_ERROR);
+    st.error_msg = ErrorMsg("Incorrect data type");
+    return st;
+  }
+  *dimension = array->byte_width() / sizeof(uint16_t);
+  *length = array->length();
+  *values = (uint8_t *) array->raw_values();
+  return st;
+}
+
 extern "C"
 int GetPayloadLengthFromReader(CPayloadReader payloadReader) {
   auto p = reinterpret_cast<wrapper::PayloadReader *>(payloadReader);
@@ -41,6 +41,8 @@ CStatus AddDoubleToPayload(CPayloadWriter payloadWriter, double *values, int len
 CStatus AddOneStringToPayload(CPayloadWriter payloadWriter, char *cstr, int str_size);
 CStatus AddBinaryVectorToPayload(CPayloadWriter payloadWriter, uint8_t *values, int dimension, int length);
 CStatus AddFloatVectorToPayload(CPayloadWriter payloadWriter, float *values, int dimension, int length);
+// adds the float16 or bfloat16 vectors, each element takes 2 bytes
+CStatus AddHalfFloatVectorToPayload(CPayloadWriter payloadWriter, uint8_t *values, int dimension, int length);
 
 CStatus FinishPayloadWriter(CPayloadWriter payloadWriter);
 CBuffer GetPayloadBufferFromWriter(CPayloadWriter payloadWriter);
@@ -60,6 +62,7 @@ CStatus GetDoubleFromPayload(CPayloadReader payloadReader, double **values, int
 CStatus GetOneStringFromPayload(CPayloadReader payloadReader, int idx, char **cstr, int *str_size);
 CStatus GetBinaryVectorFromPayload(CPayloadReader payloadReader, uint8_t **values, int *dimension, int *length);
 CStatus GetFloatVectorFromPayload(CPayloadReader payloadReader, float **values, int *dimension, int *length);
+CStatus GetHalfFloatVectorFromPayload(CPayloadReader payloadReader, uint8_t **values, int *dimension, int *length);
 
 int GetPayloadLengthFromReader(CPayloadReader payloadReader);
 CStatus ReleasePayloadReader(CPayloadReader payloadReader);
@@ -281,6 +281,51 @@ TEST(wrapper, float_vector) {
   ASSERT_EQ(st.error_code, ErrorCode::SUCCESS);
 }
 
+TEST(wrapper, half_float_vector) {
+  for (auto columnType : {ColumnType::VECTOR_FLOAT16, ColumnType::VECTOR_BFLOAT16}) {
+    auto payload = NewPayloadWriter(columnType);
+    uint8_t data[] = {1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16};
+
+    auto st = AddHalfFloatVectorToPayload(payload, data, 2, 4);
+    ASSERT_EQ(st.error_code, ErrorCode::SUCCESS);
+    st = FinishPayloadWriter(payload);
+    ASSERT_EQ(st.error_code, ErrorCode::SUCCESS);
+    auto cb = GetPayloadBufferFromWriter(payload);
+    ASSERT_GT(cb.length, 0);
+    ASSERT_NE(cb.data, nullptr);
+    auto nums = GetPayloadLengthFromWriter(payload);
+    ASSERT_EQ(nums, 4);
+
+    auto reader = NewPayloadReader(columnType, (uint8_t *) cb.data, cb.length);
+    uint8_t *values;
+    int length;
+    int dim;
+
+    st = GetHalfFloatVectorFromPayload(reader, &values, &dim, &length);
+    ASSERT_EQ(st.error_code, ErrorCode::SUCCESS);
+    ASSERT_NE(values, nullptr);
+    ASSERT_EQ(dim, 2);
+    ASSERT_EQ(length, 4);
+    for (int i = 0; i < 16; i++) {
+      ASSERT_EQ(values[i], data[i]);
+    }
+
+    st = ReleasePayloadWriter(payload);
+    ASSERT_EQ(st.error_code, ErrorCode::SUCCESS);
+    st = ReleasePayloadReader(reader);
+    ASSERT_EQ(st.error_code, ErrorCode::SUCCESS);
+  }
+
+  // the float vectors are never added as the half-precision ones
+  auto payload = NewPayloadWriter(ColumnType::VECTOR_FLOAT);
+  uint8_t data[] = {1, 2, 3, 4};
+  auto st = AddHalfFloatVectorToPayload(payload, data, 2, 1);
+  ASSERT_NE(st.error_code, ErrorCode::SUCCESS);
+  free((void *) st.error_msg);
+  st = ReleasePayloadWriter(payload);
+  ASSERT_EQ(st.error_code, ErrorCode::SUCCESS);
+}
+
 TEST(wrapper, int8_2) {
   auto payload = NewPayloadWriter(ColumnType::INT8);
   int8_t data[] = {-1, 1, -100, 100};
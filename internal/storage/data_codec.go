@@ -128,6 +128,19 @@ type FloatVectorFieldData struct {
 	Dim     int
 }
 
+// Float16VectorFieldData and BFloat16VectorFieldData are the half-precision float vectors, each element takes
+// 2 bytes in little endian
+type Float16VectorFieldData struct {
+	NumRows []int64
+	Data    []byte
+	Dim     int
+}
+type BFloat16VectorFieldData struct {
+	NumRows []int64
+	Data    []byte
+	Dim     int
+}
+
 // system filed id:
 // 0: unique row id
 // 1: timestamp
@@ -219,6 +232,10 @@ func (insertCodec *InsertCodec) Serialize(partitionID UniqueID, segmentID Unique
 			err = eventWriter.AddBinaryVectorToPayload(singleData.(*BinaryVectorFieldData).Data, singleData.(*BinaryVectorFieldData).Dim)
 		case schemapb.DataType_FloatVector:
 			err = eventWriter.AddFloatVectorToPayload(singleData.(*FloatVectorFieldData).Data, singleData.(*FloatVectorFieldData).Dim)
+		case schemapb.DataType_Float16Vector:
+			err = eventWriter.AddFloat16VectorToPayload(singleData.(*Float16VectorFieldData).Data, singleData.(*Float16VectorFieldData).Dim)
+		case schemapb.DataType_BFloat16Vector:
+			err = eventWriter.AddBFloat16VectorToPayload(singleData.(*BFloat16VectorFieldData).Data, singleData.(*BFloat16VectorFieldData).Dim)
 		default:
 			return nil, nil, fmt.Errorf("undefined data type %d", field.DataType)
 		}
@@ -478,6 +495,42 @@ func (insertCodec *InsertCodec) DeserializeAll(blobs []*Blob) (
 				totalLength += length
 				floatVectorFieldData.NumRows = append(floatVectorFieldData.NumRows, int64(length))
 				resultData.Data[fieldID] = floatVectorFieldData
+			case schemapb.DataType_Float16Vector:
+				if resultData.Data[fieldID] == nil {
+					resultData.Data[fieldID] = &Float16VectorFieldData{}
+				}
+				float16VectorFieldData := resultData.Data[fieldID].(*Float16VectorFieldData)
+				var singleData []byte
+				singleData, float16VectorFieldData.Dim, err = eventReader.GetFloat16VectorFromPayload()
+				if err != nil {
+					return InvalidUniqueID, InvalidUniqueID, InvalidUniqueID, nil, err
+				}
+				float16VectorFieldData.Data = append(float16VectorFieldData.Data, singleData...)
+				length, err := eventReader.GetPayloadLengthFromReader()
+				if err != nil {
+					return InvalidUniqueID, InvalidUniqueID, InvalidUniqueID, nil, err
+				}
+				totalLength += length
+				float16VectorFieldData.NumRows = append(float16VectorFieldData.NumRows, int64(length))
+				resultData.Data[fieldID] = float16VectorFieldData
+			case schemapb.DataType_BFloat16Vector:
+				if resultData.Data[fieldID] == nil {
+					resultData.Data[fieldID] = &BFloat16VectorFieldData{}
+				}
+				bfloat16VectorFieldData := resultData.Data[fieldID].(*BFloat16VectorFieldData)
+				var singleData []byte
+				singleData, bfloat16VectorFieldData.Dim, err = eventReader.GetBFloat16VectorFromPayload()
+				if err != nil {
+					return InvalidUniqueID, InvalidUniqueID, InvalidUniqueID, nil, err
+				}
+				bfloat16VectorFieldData.Data = append(bfloat16VectorFieldData.Data, singleData...)
+				length, err := eventReader.GetPayloadLengthFromReader()
+				if err != nil {
+					return InvalidUniqueID, InvalidUniqueID, InvalidUniqueID, nil, err
+				}
+				totalLength += length
+				bfloat16VectorFieldData.NumRows = append(bfloat16VectorFieldData.NumRows, int64(length))
+				resultData.Data[fieldID] = bfloat16VectorFieldData
 			default:
 				return InvalidUniqueID, InvalidUniqueID, InvalidUniqueID, nil, fmt.Errorf("undefined data type %d", dataType)
 			}
@@ -41,6 +41,9 @@ const (
 	StringField       = 107
 	BinaryVectorField = 108
 	FloatVectorField  = 109
+
+	Float16VectorField  = 110
+	BFloat16VectorField = 111
 )
 
 func TestInsertCodec(t *testing.T) {
@@ -138,6 +141,20 @@ func TestInsertCodec(t *testing.T) {
 					Description:  "float_vector",
 					DataType:     schemapb.DataType_FloatVector,
 				},
+				{
+					FieldID:      Float16VectorField,
+					Name:         "field_float16_vector",
+					IsPrimaryKey: false,
+					Description:  "float16_vector",
+					DataType:     schemapb.DataType_Float16Vector,
+				},
+				{
+					FieldID:      BFloat16VectorField,
+					Name:         "field_bfloat16_vector",
+					IsPrimaryKey: false,
+					Description:  "bfloat16_vector",
+					DataType:     schemapb.DataType_BFloat16Vector,
+				},
 			},
 		},
 	}
@@ -194,6 +211,16 @@ func TestInsertCodec(t *testing.T) {
 				Data:    []float32{4, 5, 6, 7, 4, 5, 6, 7},
 				Dim:     4,
 			},
+			Float16VectorField: &Float16VectorFieldData{
+				NumRows: []int64{2},
+				Data:    []byte{4, 5, 6, 7},
+				Dim:     1,
+			},
+			BFloat16VectorField: &BFloat16VectorFieldData{
+				NumRows: []int64{2},
+				Data:    []byte{4, 5, 6, 7},
+				Dim:     1,
+			},
 		},
 	}
 
@@ -249,6 +276,16 @@ func TestInsertCodec(t *testing.T) {
 				Data:    []float32{0, 1, 2, 3, 0, 1, 2, 3},
 				Dim:     4,
 			},
+			Float16VectorField: &Float16VectorFieldData{
+				NumRows: []int64{2},
+				Data:    []byte{0, 1, 2, 3},
+				Dim:     1,
+			},
+			BFloat16VectorField: &BFloat16VectorFieldData{
+				NumRows: []int64{2},
+				Data:    []byte{0, 1, 2, 3},
+				Dim:     1,
+			},
 		},
 	}
 	Blobs1, _, err := insertCodec.Serialize(PartitionID, SegmentID, insertData1)
@@ -293,6 +330,10 @@ func TestInsertCodec(t *testing.T) {
 	assert.Equal(t, []string{"1", "2", "3", "4"}, resultData.Data[StringField].(*StringFieldData).Data)
 	assert.Equal(t, []byte{0, 255, 0, 255}, resultData.Data[BinaryVectorField].(*BinaryVectorFieldData).Data)
 	assert.Equal(t, []float32{0, 1, 2, 3, 0, 1, 2, 3, 4, 5, 6, 7, 4, 5, 6, 7}, resultData.Data[FloatVectorField].(*FloatVectorFieldData).Data)
+	assert.Equal(t, []int64{2, 2}, resultData.Data[Float16VectorField].(*Float16VectorFieldData).NumRows)
+	assert.Equal(t, []byte{0, 1, 2, 3, 4, 5, 6, 7}, resultData.Data[Float16VectorField].(*Float16VectorFieldData).Data)
+	assert.Equal(t, 1, resultData.Data[Float16VectorField].(*Float16VectorFieldData).Dim)
+	assert.Equal(t, []byte{0, 1, 2, 3, 4, 5, 6, 7}, resultData.Data[BFloat16VectorField].(*BFloat16VectorFieldData).Data)
 	assert.Nil(t, insertCodec.Close())
 	log.Debug("Data", zap.Any("Data", resultData.Data))
 	log.Debug("Infos", zap.Any("Infos", resultData.Infos))
@@ -87,6 +87,12 @@ func (ds *DataSorter) Swap(i, j int) {
 			for i := 0; i < dim; i++ {
 				data[i], data[i+dim] = data[i+dim], data[i]
 			}
+		case schemapb.DataType_Float16Vector:
+			fieldData := singleData.(*Float16VectorFieldData)
+			swapRows(fieldData.Data, fieldData.Dim*2, i, j)
+		case schemapb.DataType_BFloat16Vector:
+			fieldData := singleData.(*BFloat16VectorFieldData)
+			swapRows(fieldData.Data, fieldData.Dim*2, i, j)
 		default:
 			errMsg := "undefined data type " + string(field.DataType)
 			panic(errMsg)
@@ -94,6 +100,13 @@ func (ds *DataSorter) Swap(i, j int) {
 	}
 }
 
+// swapRows swaps the i-th and j-th rows of @rowSize bytes in @data
+func swapRows(data []byte, rowSize int, i, j int) {
+	for k := 0; k < rowSize; k++ {
+		data[i*rowSize+k], data[j*rowSize+k] = data[j*rowSize+k], data[i*rowSize+k]
+	}
+}
+
 // Less returns whether i-th entry is less than j-th entry, using ID field comparison result
 func (ds *DataSorter) Less(i, j int) bool {
 	idField := ds.getRowIDFieldData()
@@ -115,6 +115,13 @@ func TestDataSorter(t *testing.T) {
 					Description:  "description_11",
 					DataType:     schemapb.DataType_FloatVector,
 				},
+				{
+					FieldID:      110,
+					Name:         "field_float16_vector",
+					IsPrimaryKey: false,
+					Description:  "description_12",
+					DataType:     schemapb.DataType_Float16Vector,
+				},
 			},
 		},
 	}
@@ -172,6 +179,11 @@ func TestDataSorter(t *testing.T) {
 				Data:    []float32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
 				Dim:     8,
 			},
+			110: &Float16VectorFieldData{
+				NumRows: []int64{2},
+				Data:    []byte{0, 1, 2, 3, 4, 5, 6, 7},
+				Dim:     2,
+			},
 		},
 	}
 
@@ -233,6 +245,7 @@ func TestDataSorter(t *testing.T) {
 	assert.Equal(t, []string{"4", "3"}, dataSorter.InsertData.Data[107].(*StringFieldData).Data)
 	assert.Equal(t, []byte{255, 0}, dataSorter.InsertData.Data[108].(*BinaryVectorFieldData).Data)
 	assert.Equal(t, []float32{8, 9, 10, 11, 12, 13, 14, 15, 0, 1, 2, 3, 4, 5, 6, 7}, dataSorter.InsertData.Data[109].(*FloatVectorFieldData).Data)
+	assert.Equal(t, []byte{4, 5, 6, 7, 0, 1, 2, 3}, dataSorter.InsertData.Data[110].(*Float16VectorFieldData).Data)
 }
 
 func TestDataSorter_Len(t *testing.T) {
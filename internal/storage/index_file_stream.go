@@ -0,0 +1,132 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/milvus-io/milvus/internal/storage/parquet"
+)
+
+// indexFilePayloadField is the field of the payload of the index file binlogs, as the payload writer writes the
+// strings.
+var indexFilePayloadField = parquet.Field{Name: "val", Type: parquet.ByteArray, Optional: true, UTF8: true}
+
+// StreamBlob is a blob whose value is read from a stream of the known size instead of being held in memory.
+type StreamBlob struct {
+	Key  string
+	Size int64
+
+	head    []byte
+	payload *parquet.ByteArrayStream
+}
+
+// NewReader returns a reader of the value from the start.
+func (blob *StreamBlob) NewReader() io.Reader {
+	return io.MultiReader(bytes.NewReader(blob.head), blob.payload.NewReader())
+}
+
+// indexFileRows splits @value into the rows of the index file binlog, which refer to @value.
+func indexFileRows(value []byte) [][]byte {
+	rows := make([][]byte, 0, (len(value)+maxLengthPerRowOfIndexFile-1)/maxLengthPerRowOfIndexFile)
+	for start := 0; start < len(value); start += maxLengthPerRowOfIndexFile {
+		end := start + maxLengthPerRowOfIndexFile
+		if end > len(value) {
+			end = len(value)
+		}
+		rows = append(rows, value[start:end])
+	}
+	return rows
+}
+
+// stream returns the binlog of a single index file event of @value, whose payload is streamed from @value.
+func (writer *IndexFileBinlogWriter) stream(key string, ts Timestamp, value []byte) (*StreamBlob, error) {
+	payload, err := parquet.NewByteArrayStream(indexFilePayloadField, indexFileRows(value))
+	if err != nil {
+		return nil, err
+	}
+
+	head := new(bytes.Buffer)
+	if err := binary.Write(head, binary.LittleEndian, MagicNumber); err != nil {
+		return nil, err
+	}
+	writer.SetEventTimeStamp(ts, ts)
+	if err := writer.descriptorEvent.Write(head); err != nil {
+		return nil, err
+	}
+	offset := int64(head.Len())
+
+	header := newEventHeader(IndexFileEventType)
+	data := newIndexFileEventData()
+	data.SetEventTimestamp(ts, ts)
+	eventLength := int64(header.GetMemoryUsageInBytes()) + int64(data.GetEventDataFixPartSize()) + payload.Size()
+	if offset+eventLength > math.MaxInt32 {
+		return nil, fmt.Errorf("the index file %s of %d bytes exceeds the max size of a binlog", key, len(value))
+	}
+	header.EventLength = int32(eventLength)
+	header.NextPosition = int32(offset + eventLength)
+	if err := header.Write(head); err != nil {
+		return nil, err
+	}
+	if err := data.WriteEventData(head); err != nil {
+		return nil, err
+	}
+	return &StreamBlob{
+		Key:     key,
+		Size:    int64(head.Len()) + payload.Size(),
+		head:    head.Bytes(),
+		payload: payload,
+	}, nil
+}
+
+// SerializeToStreams serializes the index files as Serialize does, but the binlogs are streamed from the values of
+// @datas instead of being copied into the buffers, so the values must not be modified until the streams are read.
+// The payloads are written in Go, which are read by the payload reader like the ones of the payload writer.
+func (codec *IndexFileBinlogCodec) SerializeToStreams(
+	indexBuildID UniqueID,
+	version int64,
+	collectionID UniqueID,
+	partitionID UniqueID,
+	segmentID UniqueID,
+	fieldID UniqueID,
+	indexParams map[string]string,
+	indexName string,
+	indexID UniqueID,
+	datas []*Blob,
+) ([]*StreamBlob, error) {
+	ts := Timestamp(time.Now().UnixNano())
+
+	blobs := make([]*StreamBlob, 0, len(datas)+1)
+	for _, data := range datas {
+		writer := NewIndexFileBinlogWriter(indexBuildID, version, collectionID, partitionID, segmentID, fieldID, indexName, indexID, data.Key)
+		blob, err := writer.stream(data.Key, ts, data.Value)
+		if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, blob)
+	}
+
+	// save index params
+	params, _ := json.Marshal(indexParams)
+	writer := NewIndexFileBinlogWriter(indexBuildID, version, collectionID, partitionID, segmentID, fieldID, indexName, indexID, "indexParams")
+	blob, err := writer.stream("indexParams", ts, params)
+	if err != nil {
+		return nil, err
+	}
+	return append(blobs, blob), nil
+}
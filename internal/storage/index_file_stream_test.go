@@ -0,0 +1,120 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/milvus-io/milvus/internal/proto/schemapb"
+	"github.com/milvus-io/milvus/internal/storage/parquet"
+	"github.com/milvus-io/milvus/internal/util/funcutil"
+)
+
+func newIndexFileStreamTestDatas() []*Blob {
+	return []*Blob{
+		{Key: "ivf1", Value: []byte{1, 2, 3}},
+		{Key: "empty", Value: []byte{}},
+		{Key: "large", Value: []byte(funcutil.RandomString(2*maxLengthPerRowOfIndexFile + 1))},
+	}
+}
+
+func readStreamBlobs(t *testing.T, streams []*StreamBlob) []*Blob {
+	blobs := make([]*Blob, len(streams))
+	for i, stream := range streams {
+		value, err := ioutil.ReadAll(stream.NewReader())
+		assert.Nil(t, err)
+		assert.Equal(t, stream.Size, int64(len(value)))
+		blobs[i] = &Blob{Key: stream.Key, Value: value}
+	}
+	return blobs
+}
+
+func TestIndexFileBinlogCodec_SerializeToStreams(t *testing.T) {
+	datas := newIndexFileStreamTestDatas()
+	indexParams := map[string]string{"index_type": "IVF_FLAT"}
+	codec := NewIndexFileBinlogCodec()
+	streams, err := codec.SerializeToStreams(1, 2, 3, 4, 5, 6, indexParams, "index", 7, datas)
+	assert.Nil(t, err)
+	assert.Equal(t, len(datas)+1, len(streams))
+
+	params, err := json.Marshal(indexParams)
+	assert.Nil(t, err)
+	expected := append(datas, &Blob{Key: "indexParams", Value: params})
+	for i, blob := range readStreamBlobs(t, streams) {
+		assert.Equal(t, expected[i].Key, blob.Key)
+
+		reader, err := NewBinlogReader(blob.Value)
+		assert.Nil(t, err)
+		assert.Equal(t, schemapb.DataType_String, reader.PayloadDataType)
+		assert.Equal(t, int64(3), reader.CollectionID)
+		assert.Equal(t, int64(6), reader.FieldID)
+		extra := make(map[string]interface{})
+		assert.Nil(t, json.Unmarshal(reader.ExtraBytes, &extra))
+		assert.Equal(t, expected[i].Key, extra["key"])
+		assert.Equal(t, "1", extra["indexBuildID"])
+
+		offset := int32(len(blob.Value) - reader.buffer.Len())
+		header, err := readEventHeader(reader.buffer)
+		assert.Nil(t, err)
+		assert.Equal(t, IndexFileEventType, header.TypeCode)
+		assert.Equal(t, int32(len(blob.Value)), header.NextPosition)
+		assert.Equal(t, int32(len(blob.Value))-offset, header.EventLength)
+		data, err := readIndexFileEventDataFixPart(reader.buffer)
+		assert.Nil(t, err)
+		assert.NotZero(t, data.StartTimestamp)
+
+		// the payload is read as the strings of the index file
+		payload := reader.buffer.Bytes()
+		f, err := parquet.Open(bytes.NewReader(payload), int64(len(payload)))
+		assert.Nil(t, err)
+		column, err := f.LookupColumn("val")
+		assert.Nil(t, err)
+		values, err := f.ReadColumn(column)
+		assert.Nil(t, err)
+		content := []byte{}
+		for _, row := range values.Values.([][]byte) {
+			assert.LessOrEqual(t, len(row), maxLengthPerRowOfIndexFile)
+			content = append(content, row...)
+		}
+		assert.Equal(t, expected[i].Value, content)
+	}
+}
+
+func TestIndexFileBinlogCodec_DeserializeStreams(t *testing.T) {
+	datas := newIndexFileStreamTestDatas()
+	indexParams := map[string]string{"index_type": "IVF_FLAT"}
+	codec := NewIndexFileBinlogCodec()
+	streams, err := codec.SerializeToStreams(1, 2, 3, 4, 5, 6, indexParams, "index", 7, datas)
+	assert.Nil(t, err)
+
+	// the streams are read by the payload reader as the binlogs serialized by the payload writer
+	indexBuildID, version, collectionID, _, _, _, params, indexName, indexID, blobs, err := codec.DeserializeImpl(readStreamBlobs(t, streams))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), indexBuildID)
+	assert.Equal(t, int64(2), version)
+	assert.Equal(t, int64(3), collectionID)
+	assert.Equal(t, indexParams, params)
+	assert.Equal(t, "index", indexName)
+	assert.Equal(t, int64(7), indexID)
+	assert.Equal(t, len(datas), len(blobs))
+	for i, blob := range blobs {
+		assert.Equal(t, datas[i].Key, blob.Key)
+		assert.Equal(t, len(datas[i].Value), len(blob.Value))
+		assert.Equal(t, datas[i].Value, append([]byte{}, blob.Value...))
+	}
+	assert.Nil(t, codec.Close())
+}
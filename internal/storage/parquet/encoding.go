@@ -0,0 +1,460 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/bits"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+var errTruncated = errors.New("the page is truncated")
+
+// bitWidth returns the bits to encode the values up to @max.
+func bitWidth(max int) int {
+	return bits.Len(uint(max))
+}
+
+// decodeHybrid decodes @n values of @width bits encoded by the RLE/bit-packing hybrid encoding in @data.
+func decodeHybrid(data []byte, width int, n int) ([]int32, error) {
+	if width < 0 || width > 32 {
+		return nil, fmt.Errorf("invalid bit width %d", width)
+	}
+	// the capacity is bounded by the data, since the runs may take much less bytes than the values
+	capacity := n
+	if capacity > len(data)*8 {
+		capacity = len(data) * 8
+	}
+	values := make([]int32, 0, capacity)
+	byteWidth := (width + 7) / 8
+	pos := 0
+	for len(values) < n {
+		header, size := binary.Uvarint(data[pos:])
+		if size <= 0 {
+			return nil, errTruncated
+		}
+		pos += size
+		if header&1 == 0 {
+			// a run of the same value
+			count := header >> 1
+			if pos+byteWidth > len(data) {
+				return nil, errTruncated
+			}
+			var value uint32
+			for i := 0; i < byteWidth; i++ {
+				value |= uint32(data[pos+i]) << (8 * i)
+			}
+			pos += byteWidth
+			if width < 32 && value>>uint(width) != 0 {
+				return nil, fmt.Errorf("the value %d of the run exceeds the bit width %d", value, width)
+			}
+			if count == 0 {
+				return nil, errors.New("invalid run of no values")
+			}
+			if count > uint64(n-len(values)) {
+				count = uint64(n - len(values))
+			}
+			for i := uint64(0); i < count; i++ {
+				values = append(values, int32(value))
+			}
+			continue
+		}
+		// the groups of 8 bit-packed values, the last group may be padded
+		groups := header >> 1
+		if groups == 0 || groups > uint64(len(data)) {
+			return nil, fmt.Errorf("invalid bit-packed groups %d", groups)
+		}
+		count := int(groups) * 8
+		if count > n-len(values) {
+			count = n - len(values)
+		}
+		need := (count*width + 7) / 8
+		if pos+need > len(data) {
+			return nil, errTruncated
+		}
+		for i := 0; i < count; i++ {
+			var value uint64
+			bit := i * width
+			for b := 0; b < width; b++ {
+				offset := bit + b
+				value |= uint64(data[pos+offset/8]>>(uint(offset)%8)&1) << uint(b)
+			}
+			values = append(values, int32(value))
+		}
+		consumed := int(groups) * width
+		if pos+consumed > len(data) {
+			consumed = len(data) - pos
+		}
+		pos += consumed
+	}
+	return values, nil
+}
+
+// encodeHybrid encodes @values of @width bits by the RLE/bit-packing hybrid encoding, the runs of 8 values or
+// more are run-length encoded and the rest are bit-packed.
+func encodeHybrid(values []int32, width int) []byte {
+	var out []byte
+	putUvarint := func(value uint64) {
+		var buf [binary.MaxVarintLen64]byte
+		n := binary.PutUvarint(buf[:], value)
+		out = append(out, buf[:n]...)
+	}
+	byteWidth := (width + 7) / 8
+	var packed []int32
+	flushPacked := func() {
+		if len(packed) == 0 {
+			return
+		}
+		groups := (len(packed) + 7) / 8
+		putUvarint(uint64(groups)<<1 | 1)
+		buf := make([]byte, groups*width)
+		for i, value := range packed {
+			for b := 0; b < width; b++ {
+				if uint32(value)>>uint(b)&1 != 0 {
+					offset := i*width + b
+					buf[offset/8] |= 1 << (uint(offset) % 8)
+				}
+			}
+		}
+		out = append(out, buf...)
+		packed = packed[:0]
+	}
+	for i := 0; i < len(values); {
+		run := 1
+		for i+run < len(values) && values[i+run] == values[i] {
+			run++
+		}
+		// the packed values are flushed in whole groups, the rest of a group is packed along with the run
+		if run >= 8 && len(packed)%8 == 0 {
+			flushPacked()
+			putUvarint(uint64(run) << 1)
+			for b := 0; b < byteWidth; b++ {
+				out = append(out, byte(uint32(values[i])>>(8*uint(b))))
+			}
+			i += run
+			continue
+		}
+		packed = append(packed, values[i])
+		i++
+	}
+	flushPacked()
+	return out
+}
+
+// decodeLevels decodes @n levels up to @max encoded by the RLE encoding with the length prefix in @data, and
+// returns them with the bytes they take.
+func decodeLevels(data []byte, max int, n int) ([]int32, int, error) {
+	if len(data) < 4 {
+		return nil, 0, errTruncated
+	}
+	length := int(binary.LittleEndian.Uint32(data))
+	if length > len(data)-4 {
+		return nil, 0, errTruncated
+	}
+	levels, err := decodeLevelsOf(data[4:4+length], max, n)
+	return levels, 4 + length, err
+}
+
+// decodeLevelsOf decodes @n levels up to @max encoded by the RLE encoding without the length prefix in @data.
+func decodeLevelsOf(data []byte, max int, n int) ([]int32, error) {
+	levels, err := decodeHybrid(data, bitWidth(max), n)
+	if err != nil {
+		return nil, err
+	}
+	for _, level := range levels {
+		if int(level) > max {
+			return nil, fmt.Errorf("the level %d exceeds the max level %d", level, max)
+		}
+	}
+	return levels, nil
+}
+
+// decodePlain decodes @n values of @typ encoded by the plain encoding in @data.
+func decodePlain(typ Type, typeLength int, data []byte, n int) (interface{}, error) {
+	// the byte arrays take their 4-byte lengths at least
+	fixed := map[Type]int{Int32: 4, Int64: 8, Float: 4, Double: 8, ByteArray: 4, FixedLenByteArray: typeLength}
+	if size, ok := fixed[typ]; ok && n*size > len(data) {
+		return nil, errTruncated
+	}
+	switch typ {
+	case Boolean:
+		if (n+7)/8 > len(data) {
+			return nil, errTruncated
+		}
+		values := make([]bool, n)
+		for i := range values {
+			values[i] = data[i/8]>>(uint(i)%8)&1 != 0
+		}
+		return values, nil
+	case Int32:
+		values := make([]int32, n)
+		for i := range values {
+			values[i] = int32(binary.LittleEndian.Uint32(data[i*4:]))
+		}
+		return values, nil
+	case Int64:
+		values := make([]int64, n)
+		for i := range values {
+			values[i] = int64(binary.LittleEndian.Uint64(data[i*8:]))
+		}
+		return values, nil
+	case Float:
+		values := make([]float32, n)
+		for i := range values {
+			values[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+		}
+		return values, nil
+	case Double:
+		values := make([]float64, n)
+		for i := range values {
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[i*8:]))
+		}
+		return values, nil
+	case ByteArray:
+		values := make([][]byte, n)
+		pos := 0
+		for i := range values {
+			if pos+4 > len(data) {
+				return nil, errTruncated
+			}
+			length := int(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+			if length > len(data)-pos {
+				return nil, errTruncated
+			}
+			values[i] = data[pos : pos+length]
+			pos += length
+		}
+		return values, nil
+	case FixedLenByteArray:
+		if typeLength <= 0 {
+			return nil, fmt.Errorf("invalid type length %d of %s", typeLength, typ.String())
+		}
+		values := make([][]byte, n)
+		for i := range values {
+			values[i] = data[i*typeLength : (i+1)*typeLength]
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("the values of %s are not supported", typ.String())
+}
+
+// encodePlain encodes @values by the plain encoding.
+func encodePlain(values interface{}) []byte {
+	var buf bytes.Buffer
+	switch v := values.(type) {
+	case []bool:
+		packed := make([]byte, (len(v)+7)/8)
+		for i, value := range v {
+			if value {
+				packed[i/8] |= 1 << (uint(i) % 8)
+			}
+		}
+		buf.Write(packed)
+	case [][]byte:
+		for _, value := range v {
+			_ = binary.Write(&buf, binary.LittleEndian, uint32(len(value)))
+			buf.Write(value)
+		}
+	default:
+		_ = binary.Write(&buf, binary.LittleEndian, values)
+	}
+	return buf.Bytes()
+}
+
+// encodeFixed encodes the FIXED_LEN_BYTE_ARRAY @values by the plain encoding, which has no length prefix.
+func encodeFixed(values [][]byte) []byte {
+	var buf bytes.Buffer
+	for _, value := range values {
+		buf.Write(value)
+	}
+	return buf.Bytes()
+}
+
+// valuesLen returns the number of @values.
+func valuesLen(values interface{}) int {
+	switch v := values.(type) {
+	case []bool:
+		return len(v)
+	case []int32:
+		return len(v)
+	case []int64:
+		return len(v)
+	case []float32:
+		return len(v)
+	case []float64:
+		return len(v)
+	case [][]byte:
+		return len(v)
+	}
+	return 0
+}
+
+// sliceValues returns @values[start:end].
+func sliceValues(values interface{}, start, end int) interface{} {
+	switch v := values.(type) {
+	case []bool:
+		return v[start:end]
+	case []int32:
+		return v[start:end]
+	case []int64:
+		return v[start:end]
+	case []float32:
+		return v[start:end]
+	case []float64:
+		return v[start:end]
+	case [][]byte:
+		return v[start:end]
+	}
+	return nil
+}
+
+// appendValues appends @src to @dst, which are of the same type, nil @dst is taken as empty.
+func appendValues(dst, src interface{}) interface{} {
+	if dst == nil {
+		return src
+	}
+	switch v := dst.(type) {
+	case []bool:
+		return append(v, src.([]bool)...)
+	case []int32:
+		return append(v, src.([]int32)...)
+	case []int64:
+		return append(v, src.([]int64)...)
+	case []float32:
+		return append(v, src.([]float32)...)
+	case []float64:
+		return append(v, src.([]float64)...)
+	case [][]byte:
+		return append(v, src.([][]byte)...)
+	}
+	return dst
+}
+
+// gatherValues returns the values of the dictionary referred by @indices.
+func gatherValues(dictionary interface{}, indices []int32) (interface{}, error) {
+	size := valuesLen(dictionary)
+	for _, index := range indices {
+		if index < 0 || int(index) >= size {
+			return nil, fmt.Errorf("the dictionary index %d is out of the %d values", index, size)
+		}
+	}
+	switch v := dictionary.(type) {
+	case []int32:
+		values := make([]int32, len(indices))
+		for i, index := range indices {
+			values[i] = v[index]
+		}
+		return values, nil
+	case []int64:
+		values := make([]int64, len(indices))
+		for i, index := range indices {
+			values[i] = v[index]
+		}
+		return values, nil
+	case []float32:
+		values := make([]float32, len(indices))
+		for i, index := range indices {
+			values[i] = v[index]
+		}
+		return values, nil
+	case []float64:
+		values := make([]float64, len(indices))
+		for i, index := range indices {
+			values[i] = v[index]
+		}
+		return values, nil
+	case [][]byte:
+		values := make([][]byte, len(indices))
+		for i, index := range indices {
+			values[i] = v[index]
+		}
+		return values, nil
+	}
+	return nil, errors.New("the dictionary of the booleans is not supported")
+}
+
+// decompress decompresses the page of @uncompressedSize bytes compressed by @codec.
+func decompress(codec Codec, data []byte, uncompressedSize int) ([]byte, error) {
+	var out []byte
+	var err error
+	switch codec {
+	case Uncompressed:
+		out = data
+	case Snappy:
+		var size int
+		if size, err = snappy.DecodedLen(data); err == nil && size != uncompressedSize {
+			return nil, fmt.Errorf("the page is %d bytes after decompressed, expect %d", size, uncompressedSize)
+		}
+		if err == nil {
+			out, err = snappy.Decode(nil, data)
+		}
+	case Gzip:
+		var reader *gzip.Reader
+		if reader, err = gzip.NewReader(bytes.NewReader(data)); err == nil {
+			// the page is read one byte beyond its size to detect the larger pages
+			out, err = ioutil.ReadAll(io.LimitReader(reader, int64(uncompressedSize)+1))
+		}
+	case Zstd:
+		var decoder *zstd.Decoder
+		if decoder, err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1)); err == nil {
+			out, err = decoder.DecodeAll(data, make([]byte, 0, uncompressedSize))
+			decoder.Close()
+		}
+	default:
+		return nil, fmt.Errorf("the compression codec %d is not supported", codec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress the page: %w", err)
+	}
+	if len(out) != uncompressedSize {
+		return nil, fmt.Errorf("the page is %d bytes after decompressed, expect %d", len(out), uncompressedSize)
+	}
+	return out, nil
+}
+
+// compress compresses the page by @codec.
+func compress(codec Codec, data []byte) ([]byte, error) {
+	switch codec {
+	case Uncompressed:
+		return data, nil
+	case Snappy:
+		return snappy.Encode(nil, data), nil
+	case Gzip:
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(data); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case Zstd:
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer encoder.Close()
+		return encoder.EncodeAll(data, nil), nil
+	}
+	return nil, fmt.Errorf("the compression codec %d is not supported", codec)
+}
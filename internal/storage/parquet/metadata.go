@@ -0,0 +1,591 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package parquet
+
+import "fmt"
+
+// Type is the physical type of the values of a column.
+type Type int32
+
+const (
+	Boolean           Type = 0
+	Int32             Type = 1
+	Int64             Type = 2
+	Int96             Type = 3
+	Float             Type = 4
+	Double            Type = 5
+	ByteArray         Type = 6
+	FixedLenByteArray Type = 7
+)
+
+var typeNames = map[Type]string{
+	Boolean:           "BOOLEAN",
+	Int32:             "INT32",
+	Int64:             "INT64",
+	Int96:             "INT96",
+	Float:             "FLOAT",
+	Double:            "DOUBLE",
+	ByteArray:         "BYTE_ARRAY",
+	FixedLenByteArray: "FIXED_LEN_BYTE_ARRAY",
+}
+
+func (t Type) String() string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("Type(%d)", int32(t))
+}
+
+// Repetition is whether a field of the schema is required, optional or repeated.
+type Repetition int32
+
+const (
+	Required Repetition = 0
+	Optional Repetition = 1
+	Repeated Repetition = 2
+)
+
+// Codec is the compression codec of the pages of a column chunk.
+type Codec int32
+
+const (
+	Uncompressed Codec = 0
+	Snappy       Codec = 1
+	Gzip         Codec = 2
+	Zstd         Codec = 6
+)
+
+// the encodings of the values and the levels
+const (
+	encodingPlain           = 0
+	encodingPlainDictionary = 2
+	encodingRLE             = 3
+	encodingRLEDictionary   = 8
+)
+
+// the types of the pages
+const (
+	pageData       = 0
+	pageDictionary = 2
+	pageDataV2     = 3
+)
+
+// the converted types of the schema elements
+const (
+	// convertedTypeUTF8 annotates the BYTE_ARRAY leaves as strings
+	convertedTypeUTF8 = 0
+	// convertedTypeList annotates the groups as lists
+	convertedTypeList = 3
+)
+
+// schemaElement is a node of the schema, which is flattened in depth-first order in the metadata.
+type schemaElement struct {
+	// typ is set on the leaves only
+	typ           *Type
+	typeLength    int32
+	repetition    Repetition
+	name          string
+	numChildren   int32
+	convertedType *int32
+}
+
+type columnMetaData struct {
+	typ                  Type
+	encodings            []int32
+	pathInSchema         []string
+	codec                Codec
+	numValues            int64
+	totalUncompressed    int64
+	totalCompressed      int64
+	dataPageOffset       int64
+	dictionaryPageOffset int64
+	hasDictionary        bool
+}
+
+type columnChunk struct {
+	filePath   string
+	fileOffset int64
+	meta       *columnMetaData
+}
+
+type rowGroup struct {
+	columns       []*columnChunk
+	totalByteSize int64
+	numRows       int64
+}
+
+type keyValue struct {
+	key   string
+	value string
+}
+
+type fileMetaData struct {
+	version          int32
+	schema           []*schemaElement
+	numRows          int64
+	rowGroups        []*rowGroup
+	keyValueMetadata []*keyValue
+	createdBy        string
+}
+
+type dataPageHeader struct {
+	numValues               int32
+	encoding                int32
+	definitionLevelEncoding int32
+	repetitionLevelEncoding int32
+}
+
+type dictionaryPageHeader struct {
+	numValues int32
+	encoding  int32
+}
+
+type dataPageHeaderV2 struct {
+	numValues                  int32
+	numNulls                   int32
+	numRows                    int32
+	encoding                   int32
+	definitionLevelsByteLength int32
+	repetitionLevelsByteLength int32
+	isCompressed               bool
+}
+
+type pageHeader struct {
+	typ                  int32
+	uncompressedPageSize int32
+	compressedPageSize   int32
+	dataPage             *dataPageHeader
+	dictionaryPage       *dictionaryPageHeader
+	dataPageV2           *dataPageHeaderV2
+}
+
+// readListOf reads the list field of @id of the elements of @elemType by calling @elem on each of them.
+func (r *thriftReader) readListOf(id int16, fieldType byte, elemType byte, elem func() error) error {
+	if err := expect(id, fieldType, thriftList); err != nil {
+		return err
+	}
+	actual, size, err := r.readList()
+	if err != nil {
+		return err
+	}
+	if actual != elemType {
+		return fmt.Errorf("the thrift list of type %d is expected to be of type %d", actual, elemType)
+	}
+	for i := 0; i < size; i++ {
+		if err := elem(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expect returns an error if the field of @id is not of @expected type.
+func expect(id int16, actual byte, expected byte) error {
+	if actual != expected {
+		return fmt.Errorf("the thrift field %d of type %d is expected to be of type %d", id, actual, expected)
+	}
+	return nil
+}
+
+func (r *thriftReader) readI32Field(id int16, fieldType byte, value *int32) error {
+	if err := expect(id, fieldType, thriftI32); err != nil {
+		return err
+	}
+	v, err := r.readI32()
+	*value = v
+	return err
+}
+
+func (r *thriftReader) readI64Field(id int16, fieldType byte, value *int64) error {
+	if err := expect(id, fieldType, thriftI64); err != nil {
+		return err
+	}
+	v, err := r.readI64()
+	*value = v
+	return err
+}
+
+func (r *thriftReader) readStringField(id int16, fieldType byte, value *string) error {
+	if err := expect(id, fieldType, thriftBinary); err != nil {
+		return err
+	}
+	v, err := r.readString()
+	*value = v
+	return err
+}
+
+func (r *thriftReader) readSchemaElement() (*schemaElement, error) {
+	element := &schemaElement{}
+	err := r.readStruct(func(id int16, fieldType byte) error {
+		switch id {
+		case 1:
+			var typ int32
+			if err := r.readI32Field(id, fieldType, &typ); err != nil {
+				return err
+			}
+			t := Type(typ)
+			element.typ = &t
+			return nil
+		case 2:
+			return r.readI32Field(id, fieldType, &element.typeLength)
+		case 3:
+			var repetition int32
+			err := r.readI32Field(id, fieldType, &repetition)
+			element.repetition = Repetition(repetition)
+			return err
+		case 4:
+			return r.readStringField(id, fieldType, &element.name)
+		case 5:
+			return r.readI32Field(id, fieldType, &element.numChildren)
+		case 6:
+			var convertedType int32
+			if err := r.readI32Field(id, fieldType, &convertedType); err != nil {
+				return err
+			}
+			element.convertedType = &convertedType
+			return nil
+		}
+		return r.skip(fieldType)
+	})
+	return element, err
+}
+
+func (r *thriftReader) readColumnMetaData() (*columnMetaData, error) {
+	meta := &columnMetaData{}
+	err := r.readStruct(func(id int16, fieldType byte) error {
+		switch id {
+		case 1:
+			var typ int32
+			err := r.readI32Field(id, fieldType, &typ)
+			meta.typ = Type(typ)
+			return err
+		case 2:
+			return r.readListOf(id, fieldType, thriftI32, func() error {
+				encoding, err := r.readI32()
+				meta.encodings = append(meta.encodings, encoding)
+				return err
+			})
+		case 3:
+			return r.readListOf(id, fieldType, thriftBinary, func() error {
+				name, err := r.readString()
+				meta.pathInSchema = append(meta.pathInSchema, name)
+				return err
+			})
+		case 4:
+			var codec int32
+			err := r.readI32Field(id, fieldType, &codec)
+			meta.codec = Codec(codec)
+			return err
+		case 5:
+			return r.readI64Field(id, fieldType, &meta.numValues)
+		case 6:
+			return r.readI64Field(id, fieldType, &meta.totalUncompressed)
+		case 7:
+			return r.readI64Field(id, fieldType, &meta.totalCompressed)
+		case 9:
+			return r.readI64Field(id, fieldType, &meta.dataPageOffset)
+		case 11:
+			meta.hasDictionary = true
+			return r.readI64Field(id, fieldType, &meta.dictionaryPageOffset)
+		}
+		return r.skip(fieldType)
+	})
+	return meta, err
+}
+
+func (r *thriftReader) readColumnChunk() (*columnChunk, error) {
+	chunk := &columnChunk{}
+	err := r.readStruct(func(id int16, fieldType byte) error {
+		switch id {
+		case 1:
+			return r.readStringField(id, fieldType, &chunk.filePath)
+		case 2:
+			return r.readI64Field(id, fieldType, &chunk.fileOffset)
+		case 3:
+			if err := expect(id, fieldType, thriftStruct); err != nil {
+				return err
+			}
+			meta, err := r.readColumnMetaData()
+			chunk.meta = meta
+			return err
+		}
+		return r.skip(fieldType)
+	})
+	return chunk, err
+}
+
+func (r *thriftReader) readRowGroup() (*rowGroup, error) {
+	group := &rowGroup{}
+	err := r.readStruct(func(id int16, fieldType byte) error {
+		switch id {
+		case 1:
+			return r.readListOf(id, fieldType, thriftStruct, func() error {
+				chunk, err := r.readColumnChunk()
+				group.columns = append(group.columns, chunk)
+				return err
+			})
+		case 2:
+			return r.readI64Field(id, fieldType, &group.totalByteSize)
+		case 3:
+			return r.readI64Field(id, fieldType, &group.numRows)
+		}
+		return r.skip(fieldType)
+	})
+	return group, err
+}
+
+func (r *thriftReader) readKeyValue() (*keyValue, error) {
+	kv := &keyValue{}
+	err := r.readStruct(func(id int16, fieldType byte) error {
+		switch id {
+		case 1:
+			return r.readStringField(id, fieldType, &kv.key)
+		case 2:
+			return r.readStringField(id, fieldType, &kv.value)
+		}
+		return r.skip(fieldType)
+	})
+	return kv, err
+}
+
+func readFileMetaData(data []byte) (*fileMetaData, error) {
+	r := newThriftReader(data)
+	meta := &fileMetaData{}
+	err := r.readStruct(func(id int16, fieldType byte) error {
+		switch id {
+		case 1:
+			return r.readI32Field(id, fieldType, &meta.version)
+		case 2:
+			return r.readListOf(id, fieldType, thriftStruct, func() error {
+				element, err := r.readSchemaElement()
+				meta.schema = append(meta.schema, element)
+				return err
+			})
+		case 3:
+			return r.readI64Field(id, fieldType, &meta.numRows)
+		case 4:
+			return r.readListOf(id, fieldType, thriftStruct, func() error {
+				group, err := r.readRowGroup()
+				meta.rowGroups = append(meta.rowGroups, group)
+				return err
+			})
+		case 5:
+			return r.readListOf(id, fieldType, thriftStruct, func() error {
+				kv, err := r.readKeyValue()
+				meta.keyValueMetadata = append(meta.keyValueMetadata, kv)
+				return err
+			})
+		case 6:
+			return r.readStringField(id, fieldType, &meta.createdBy)
+		}
+		return r.skip(fieldType)
+	})
+	return meta, err
+}
+
+// readPageHeader reads the header of a page at the start of @data, and returns it with its size.
+func readPageHeader(data []byte) (*pageHeader, int, error) {
+	r := newThriftReader(data)
+	header := &pageHeader{}
+	err := r.readStruct(func(id int16, fieldType byte) error {
+		switch id {
+		case 1:
+			return r.readI32Field(id, fieldType, &header.typ)
+		case 2:
+			return r.readI32Field(id, fieldType, &header.uncompressedPageSize)
+		case 3:
+			return r.readI32Field(id, fieldType, &header.compressedPageSize)
+		case 5:
+			if err := expect(id, fieldType, thriftStruct); err != nil {
+				return err
+			}
+			header.dataPage = &dataPageHeader{}
+			return r.readStruct(func(id int16, fieldType byte) error {
+				switch id {
+				case 1:
+					return r.readI32Field(id, fieldType, &header.dataPage.numValues)
+				case 2:
+					return r.readI32Field(id, fieldType, &header.dataPage.encoding)
+				case 3:
+					return r.readI32Field(id, fieldType, &header.dataPage.definitionLevelEncoding)
+				case 4:
+					return r.readI32Field(id, fieldType, &header.dataPage.repetitionLevelEncoding)
+				}
+				return r.skip(fieldType)
+			})
+		case 7:
+			if err := expect(id, fieldType, thriftStruct); err != nil {
+				return err
+			}
+			header.dictionaryPage = &dictionaryPageHeader{}
+			return r.readStruct(func(id int16, fieldType byte) error {
+				switch id {
+				case 1:
+					return r.readI32Field(id, fieldType, &header.dictionaryPage.numValues)
+				case 2:
+					return r.readI32Field(id, fieldType, &header.dictionaryPage.encoding)
+				}
+				return r.skip(fieldType)
+			})
+		case 8:
+			if err := expect(id, fieldType, thriftStruct); err != nil {
+				return err
+			}
+			// the levels and the values are compressed unless is_compressed is false
+			header.dataPageV2 = &dataPageHeaderV2{isCompressed: true}
+			return r.readStruct(func(id int16, fieldType byte) error {
+				v2 := header.dataPageV2
+				switch id {
+				case 1:
+					return r.readI32Field(id, fieldType, &v2.numValues)
+				case 2:
+					return r.readI32Field(id, fieldType, &v2.numNulls)
+				case 3:
+					return r.readI32Field(id, fieldType, &v2.numRows)
+				case 4:
+					return r.readI32Field(id, fieldType, &v2.encoding)
+				case 5:
+					return r.readI32Field(id, fieldType, &v2.definitionLevelsByteLength)
+				case 6:
+					return r.readI32Field(id, fieldType, &v2.repetitionLevelsByteLength)
+				case 7:
+					if fieldType != thriftTrue && fieldType != thriftFalse {
+						return fmt.Errorf("the thrift field %d of type %d is expected to be a bool", id, fieldType)
+					}
+					v2.isCompressed = fieldType == thriftTrue
+					return nil
+				}
+				return r.skip(fieldType)
+			})
+		}
+		return r.skip(fieldType)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return header, r.pos, nil
+}
+
+func (w *thriftWriter) writeFileMetaData(meta *fileMetaData) {
+	w.beginStruct()
+	w.writeI32Field(1, meta.version)
+	w.writeField(2, thriftList)
+	w.writeList(thriftStruct, len(meta.schema))
+	for _, element := range meta.schema {
+		w.beginStruct()
+		if element.typ != nil {
+			w.writeI32Field(1, int32(*element.typ))
+		}
+		if element.typeLength > 0 {
+			w.writeI32Field(2, element.typeLength)
+		}
+		// the root of the schema has no repetition
+		if element != meta.schema[0] {
+			w.writeI32Field(3, int32(element.repetition))
+		}
+		w.writeStringField(4, element.name)
+		if element.typ == nil {
+			w.writeI32Field(5, element.numChildren)
+		}
+		if element.convertedType != nil {
+			w.writeI32Field(6, *element.convertedType)
+		}
+		w.endStruct()
+	}
+	w.writeI64Field(3, meta.numRows)
+	w.writeField(4, thriftList)
+	w.writeList(thriftStruct, len(meta.rowGroups))
+	for _, group := range meta.rowGroups {
+		w.beginStruct()
+		w.writeField(1, thriftList)
+		w.writeList(thriftStruct, len(group.columns))
+		for _, chunk := range group.columns {
+			w.beginStruct()
+			w.writeI64Field(2, chunk.fileOffset)
+			w.writeField(3, thriftStruct)
+			w.beginStruct()
+			m := chunk.meta
+			w.writeI32Field(1, int32(m.typ))
+			w.writeField(2, thriftList)
+			w.writeList(thriftI32, len(m.encodings))
+			for _, encoding := range m.encodings {
+				w.writeVarint(int64(encoding))
+			}
+			w.writeField(3, thriftList)
+			w.writeList(thriftBinary, len(m.pathInSchema))
+			for _, name := range m.pathInSchema {
+				w.writeBinary([]byte(name))
+			}
+			w.writeI32Field(4, int32(m.codec))
+			w.writeI64Field(5, m.numValues)
+			w.writeI64Field(6, m.totalUncompressed)
+			w.writeI64Field(7, m.totalCompressed)
+			w.writeI64Field(9, m.dataPageOffset)
+			if m.hasDictionary {
+				w.writeI64Field(11, m.dictionaryPageOffset)
+			}
+			w.endStruct()
+			w.endStruct()
+		}
+		w.writeI64Field(2, group.totalByteSize)
+		w.writeI64Field(3, group.numRows)
+		w.endStruct()
+	}
+	if len(meta.keyValueMetadata) > 0 {
+		w.writeField(5, thriftList)
+		w.writeList(thriftStruct, len(meta.keyValueMetadata))
+		for _, kv := range meta.keyValueMetadata {
+			w.beginStruct()
+			w.writeStringField(1, kv.key)
+			w.writeStringField(2, kv.value)
+			w.endStruct()
+		}
+	}
+	w.writeStringField(6, meta.createdBy)
+	w.endStruct()
+}
+
+func (w *thriftWriter) writePageHeader(header *pageHeader) {
+	w.beginStruct()
+	w.writeI32Field(1, header.typ)
+	w.writeI32Field(2, header.uncompressedPageSize)
+	w.writeI32Field(3, header.compressedPageSize)
+	if page := header.dataPage; page != nil {
+		w.writeField(5, thriftStruct)
+		w.beginStruct()
+		w.writeI32Field(1, page.numValues)
+		w.writeI32Field(2, page.encoding)
+		w.writeI32Field(3, page.definitionLevelEncoding)
+		w.writeI32Field(4, page.repetitionLevelEncoding)
+		w.endStruct()
+	}
+	if page := header.dictionaryPage; page != nil {
+		w.writeField(7, thriftStruct)
+		w.beginStruct()
+		w.writeI32Field(1, page.numValues)
+		w.writeI32Field(2, page.encoding)
+		w.endStruct()
+	}
+	if page := header.dataPageV2; page != nil {
+		w.writeField(8, thriftStruct)
+		w.beginStruct()
+		w.writeI32Field(1, page.numValues)
+		w.writeI32Field(2, page.numNulls)
+		w.writeI32Field(3, page.numRows)
+		w.writeI32Field(4, page.encoding)
+		w.writeI32Field(5, page.definitionLevelsByteLength)
+		w.writeI32Field(6, page.repetitionLevelsByteLength)
+		w.writeBoolField(7, page.isCompressed)
+		w.endStruct()
+	}
+	w.endStruct()
+}
@@ -0,0 +1,501 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+// Package parquet reads the columns of the parquet files, which persist the segments along with the binlogs. The
+// file is read through an io.ReaderAt, the footer is read when the file is opened and a column chunk is read only when
+// its column is, so that the object storage serving the ranges of the objects downloads the projected columns only.
+//
+// The reader supports the flat columns and the nested ones such as the lists, whose values are returned with their
+// definition and repetition levels. The pages are the data pages of version 1 and 2 and the dictionary pages, the
+// values are encoded by the plain or the dictionary encoding, and compressed by snappy, gzip, zstd or not at all.
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	magic = "PAR1"
+	// footerTailSize is the bytes of the footer length and the magic at the end of the file
+	footerTailSize = 8
+	// maxFooterSize bounds the metadata of a file
+	maxFooterSize = 64 << 20
+)
+
+// ErrMalformed is returned for the files which are not valid parquet files.
+var ErrMalformed = errors.New("malformed parquet file")
+
+func malformed(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrMalformed, fmt.Sprintf(format, args...))
+}
+
+// Column is a leaf column of the schema.
+type Column struct {
+	// Path is the names of the fields from the top-level field to the leaf
+	Path []string
+	Type Type
+	// TypeLength is the bytes of each value of the FIXED_LEN_BYTE_ARRAY columns
+	TypeLength         int
+	MaxDefinitionLevel int
+	MaxRepetitionLevel int
+	// index is the index of the column chunks of the column in the row groups
+	index int
+}
+
+// Name returns the name of the top-level field of the column.
+func (c *Column) Name() string {
+	return c.Path[0]
+}
+
+// ColumnData is the values and the levels of a column read from all the row groups.
+type ColumnData struct {
+	// Values are the values which are not null, of []bool, []int32, []int64, []float32, []float64 or [][]byte by
+	// the type of the column, the byte arrays refer to the pages read
+	Values interface{}
+	// DefinitionLevels are the definition levels of the values and the nulls, nil if the column has no optional or
+	// repeated fields
+	DefinitionLevels []int32
+	// RepetitionLevels are the repetition levels of the values and the nulls, nil if the column is not repeated,
+	// the level is 0 for the first value of each row
+	RepetitionLevels []int32
+}
+
+// File is a parquet file opened for reading its columns.
+type File struct {
+	r       io.ReaderAt
+	size    int64
+	meta    *fileMetaData
+	columns []*Column
+}
+
+// Open reads the footer of the parquet file of @size bytes read by @r.
+func Open(r io.ReaderAt, size int64) (*File, error) {
+	if size < int64(len(magic)+footerTailSize) {
+		return nil, malformed("the file of %d bytes is too small", size)
+	}
+	tail := make([]byte, footerTailSize)
+	if err := readFull(r, tail, size-footerTailSize); err != nil {
+		return nil, err
+	}
+	if string(tail[4:]) != magic {
+		return nil, malformed("no magic at the end of the file")
+	}
+	footerSize := int64(binary.LittleEndian.Uint32(tail))
+	if footerSize > maxFooterSize || footerSize > size-int64(len(magic)+footerTailSize) {
+		return nil, malformed("invalid footer size %d of the file of %d bytes", footerSize, size)
+	}
+	footer := make([]byte, footerSize)
+	if err := readFull(r, footer, size-footerTailSize-footerSize); err != nil {
+		return nil, err
+	}
+	meta, err := readFileMetaData(footer)
+	if err != nil {
+		return nil, malformed("failed to read the footer: %s", err.Error())
+	}
+	f := &File{r: r, size: size, meta: meta}
+	if err := f.initColumns(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// readFull reads len(@buf) bytes at @offset, io.ReaderAt may return io.EOF along with the bytes ending the file.
+func readFull(r io.ReaderAt, buf []byte, offset int64) error {
+	n, err := r.ReadAt(buf, offset)
+	if err == io.EOF && n == len(buf) {
+		return nil
+	}
+	return err
+}
+
+// initColumns flattens the schema into the leaf columns, and validates the column chunks of the row groups.
+func (f *File) initColumns() error {
+	schema := f.meta.schema
+	if len(schema) == 0 {
+		return malformed("no schema")
+	}
+	pos := 1
+	var walk func(path []string, def, rep int) error
+	walk = func(path []string, def, rep int) error {
+		if pos >= len(schema) {
+			return malformed("the schema has less fields than its groups claim")
+		}
+		element := schema[pos]
+		pos++
+		switch element.repetition {
+		case Required:
+		case Optional:
+			def++
+		case Repeated:
+			def++
+			rep++
+		default:
+			return malformed("invalid repetition %d of the field %s", element.repetition, element.name)
+		}
+		path = append(path[:len(path):len(path)], element.name)
+		if element.typ == nil {
+			if element.numChildren <= 0 {
+				return malformed("the group %s has no fields", element.name)
+			}
+			for i := int32(0); i < element.numChildren; i++ {
+				if err := walk(path, def, rep); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if *element.typ == FixedLenByteArray && element.typeLength <= 0 {
+			return malformed("invalid type length %d of the field %s", element.typeLength, element.name)
+		}
+		f.columns = append(f.columns, &Column{
+			Path:               path,
+			Type:               *element.typ,
+			TypeLength:         int(element.typeLength),
+			MaxDefinitionLevel: def,
+			MaxRepetitionLevel: rep,
+			index:              len(f.columns),
+		})
+		return nil
+	}
+	for i := int32(0); i < schema[0].numChildren; i++ {
+		if err := walk(nil, 0, 0); err != nil {
+			return err
+		}
+	}
+	if pos != len(schema) {
+		return malformed("the schema has %d fields, but its groups claim %d", len(schema), pos)
+	}
+
+	var rows int64
+	for idx, group := range f.meta.rowGroups {
+		if len(group.columns) != len(f.columns) {
+			return malformed("the row group %d has %d column chunks, expect %d", idx, len(group.columns), len(f.columns))
+		}
+		for i, chunk := range group.columns {
+			column := f.columns[i]
+			if chunk.meta == nil {
+				return malformed("the column chunk %s of the row group %d has no metadata", strings.Join(column.Path, "."), idx)
+			}
+			if chunk.filePath != "" {
+				return fmt.Errorf("the column chunk %s of the row group %d is in another file %s, which is not supported",
+					strings.Join(column.Path, "."), idx, chunk.filePath)
+			}
+			if strings.Join(chunk.meta.pathInSchema, ".") != strings.Join(column.Path, ".") || chunk.meta.typ != column.Type {
+				return malformed("the column chunk %s of %s of the row group %d mismatches the column %s of %s",
+					strings.Join(chunk.meta.pathInSchema, "."), chunk.meta.typ.String(), idx,
+					strings.Join(column.Path, "."), column.Type.String())
+			}
+			start, length := chunkRange(chunk.meta)
+			if start < int64(len(magic)) || length <= 0 || start+length > f.size-footerTailSize {
+				return malformed("the column chunk %s of the row group %d at [%d, %d) is out of the file",
+					strings.Join(column.Path, "."), idx, start, start+length)
+			}
+		}
+		if group.numRows < 0 {
+			return malformed("invalid rows %d of the row group %d", group.numRows, idx)
+		}
+		rows += group.numRows
+	}
+	if rows != f.meta.numRows {
+		return malformed("the row groups have %d rows, but the file claims %d", rows, f.meta.numRows)
+	}
+	return nil
+}
+
+// chunkRange returns the offset and the length of the column chunk in the file.
+func chunkRange(meta *columnMetaData) (int64, int64) {
+	start := meta.dataPageOffset
+	if meta.hasDictionary && meta.dictionaryPageOffset > 0 && meta.dictionaryPageOffset < start {
+		start = meta.dictionaryPageOffset
+	}
+	return start, meta.totalCompressed
+}
+
+// NumRows returns the rows of the file.
+func (f *File) NumRows() int64 {
+	return f.meta.numRows
+}
+
+// Columns returns the leaf columns of the schema.
+func (f *File) Columns() []*Column {
+	return f.columns
+}
+
+// LookupColumn returns the leaf column of the top-level field @name, the field is expected to have a single leaf
+// column, such as a primitive field or a list of the primitive values.
+func (f *File) LookupColumn(name string) (*Column, error) {
+	var found *Column
+	for _, column := range f.columns {
+		if column.Name() != name {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("the field %s has more than one leaf column", name)
+		}
+		found = column
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no field %s in the parquet file", name)
+	}
+	return found, nil
+}
+
+// Metadata returns the key-value metadata of the file.
+func (f *File) Metadata() map[string]string {
+	metadata := make(map[string]string, len(f.meta.keyValueMetadata))
+	for _, kv := range f.meta.keyValueMetadata {
+		metadata[kv.key] = kv.value
+	}
+	return metadata
+}
+
+// ColumnSize returns the bytes of the column chunks of @column, which are read by ReadColumn.
+func (f *File) ColumnSize(column *Column) int64 {
+	var size int64
+	for _, group := range f.meta.rowGroups {
+		_, length := chunkRange(group.columns[column.index].meta)
+		size += length
+	}
+	return size
+}
+
+// ReadColumn reads the column chunks of @column of all the row groups.
+func (f *File) ReadColumn(column *Column) (*ColumnData, error) {
+	data := &ColumnData{}
+	for idx, group := range f.meta.rowGroups {
+		if err := f.readChunk(column, group.columns[column.index].meta, data); err != nil {
+			return nil, fmt.Errorf("failed to read the column %s of the row group %d: %w", strings.Join(column.Path, "."), idx, err)
+		}
+	}
+	if data.Values == nil {
+		data.Values = emptyValues(column.Type)
+	}
+	return data, nil
+}
+
+func emptyValues(typ Type) interface{} {
+	switch typ {
+	case Boolean:
+		return []bool{}
+	case Int32:
+		return []int32{}
+	case Int64:
+		return []int64{}
+	case Float:
+		return []float32{}
+	case Double:
+		return []float64{}
+	}
+	return [][]byte{}
+}
+
+// readChunk reads the pages of a column chunk, and appends the values and the levels of them to @data.
+func (f *File) readChunk(column *Column, meta *columnMetaData, data *ColumnData) error {
+	start, length := chunkRange(meta)
+	chunk := make([]byte, length)
+	if err := readFull(f.r, chunk, start); err != nil {
+		return err
+	}
+	var dictionary interface{}
+	var read int64
+	pos := 0
+	for read < meta.numValues {
+		if pos >= len(chunk) {
+			return malformed("the column chunk has %d values, expect %d", read, meta.numValues)
+		}
+		header, size, err := readPageHeader(chunk[pos:])
+		if err != nil {
+			return malformed("failed to read the page header: %s", err.Error())
+		}
+		pos += size
+		if header.compressedPageSize < 0 || int(header.compressedPageSize) > len(chunk)-pos || header.uncompressedPageSize < 0 {
+			return malformed("the page of %d bytes is out of the column chunk", header.compressedPageSize)
+		}
+		page := chunk[pos : pos+int(header.compressedPageSize)]
+		pos += int(header.compressedPageSize)
+
+		switch header.typ {
+		case pageDictionary:
+			if header.dictionaryPage == nil {
+				return malformed("no header of the dictionary page")
+			}
+			if dictionary != nil {
+				return malformed("more than one dictionary page")
+			}
+			if encoding := header.dictionaryPage.encoding; encoding != encodingPlain && encoding != encodingPlainDictionary {
+				return fmt.Errorf("the encoding %d of the dictionary page is not supported", encoding)
+			}
+			values, err := decompress(meta.codec, page, int(header.uncompressedPageSize))
+			if err != nil {
+				return err
+			}
+			if dictionary, err = decodePlain(column.Type, column.TypeLength, values, int(header.dictionaryPage.numValues)); err != nil {
+				return malformed("failed to decode the dictionary page: %s", err.Error())
+			}
+		case pageData, pageDataV2:
+			n, err := readDataPage(column, meta.codec, header, page, dictionary, data)
+			if err != nil {
+				return err
+			}
+			read += int64(n)
+		}
+		// the index pages are skipped
+	}
+	return nil
+}
+
+// readDataPage decodes the levels and the values of a data page, and returns the number of them.
+func readDataPage(column *Column, codec Codec, header *pageHeader, page []byte, dictionary interface{}, data *ColumnData) (int, error) {
+	var n, encoding int
+	var repetitionLevels, definitionLevels []int32
+	var values []byte
+	if header.typ == pageData {
+		if header.dataPage == nil {
+			return 0, malformed("no header of the data page")
+		}
+		n, encoding = int(header.dataPage.numValues), int(header.dataPage.encoding)
+		uncompressed, err := decompress(codec, page, int(header.uncompressedPageSize))
+		if err != nil {
+			return 0, err
+		}
+		pos := 0
+		if column.MaxRepetitionLevel > 0 {
+			if header.dataPage.repetitionLevelEncoding != encodingRLE {
+				return 0, fmt.Errorf("the encoding %d of the repetition levels is not supported", header.dataPage.repetitionLevelEncoding)
+			}
+			levels, size, err := decodeLevels(uncompressed[pos:], column.MaxRepetitionLevel, n)
+			if err != nil {
+				return 0, malformed("failed to decode the repetition levels: %s", err.Error())
+			}
+			repetitionLevels, pos = levels, pos+size
+		}
+		if column.MaxDefinitionLevel > 0 {
+			if header.dataPage.definitionLevelEncoding != encodingRLE {
+				return 0, fmt.Errorf("the encoding %d of the definition levels is not supported", header.dataPage.definitionLevelEncoding)
+			}
+			levels, size, err := decodeLevels(uncompressed[pos:], column.MaxDefinitionLevel, n)
+			if err != nil {
+				return 0, malformed("failed to decode the definition levels: %s", err.Error())
+			}
+			definitionLevels, pos = levels, pos+size
+		}
+		values = uncompressed[pos:]
+	} else {
+		v2 := header.dataPageV2
+		if v2 == nil {
+			return 0, malformed("no header of the data page")
+		}
+		n, encoding = int(v2.numValues), int(v2.encoding)
+		repLength, defLength := int(v2.repetitionLevelsByteLength), int(v2.definitionLevelsByteLength)
+		if repLength < 0 || defLength < 0 || repLength+defLength > len(page) {
+			return 0, malformed("the levels of %d bytes are out of the page", repLength+defLength)
+		}
+		var err error
+		if column.MaxRepetitionLevel > 0 {
+			if repetitionLevels, err = decodeLevelsOf(page[:repLength], column.MaxRepetitionLevel, n); err != nil {
+				return 0, malformed("failed to decode the repetition levels: %s", err.Error())
+			}
+		}
+		if column.MaxDefinitionLevel > 0 {
+			if definitionLevels, err = decodeLevelsOf(page[repLength:repLength+defLength], column.MaxDefinitionLevel, n); err != nil {
+				return 0, malformed("failed to decode the definition levels: %s", err.Error())
+			}
+		}
+		// the levels are never compressed
+		values = page[repLength+defLength:]
+		if v2.isCompressed {
+			if values, err = decompress(codec, values, int(header.uncompressedPageSize)-repLength-defLength); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if n < 0 {
+		return 0, malformed("invalid values %d of the data page", n)
+	}
+
+	present := n
+	if column.MaxDefinitionLevel > 0 {
+		present = 0
+		for _, level := range definitionLevels {
+			if int(level) == column.MaxDefinitionLevel {
+				present++
+			}
+		}
+	}
+	decoded, err := decodeValues(column, encoding, values, present, dictionary)
+	if err != nil {
+		return 0, err
+	}
+	data.Values = appendValues(data.Values, decoded)
+	if column.MaxDefinitionLevel > 0 {
+		data.DefinitionLevels = append(data.DefinitionLevels, definitionLevels...)
+	}
+	if column.MaxRepetitionLevel > 0 {
+		data.RepetitionLevels = append(data.RepetitionLevels, repetitionLevels...)
+	}
+	return n, nil
+}
+
+// decodeValues decodes @n values of the page encoded by @encoding.
+func decodeValues(column *Column, encoding int, data []byte, n int, dictionary interface{}) (interface{}, error) {
+	switch encoding {
+	case encodingPlain:
+		values, err := decodePlain(column.Type, column.TypeLength, data, n)
+		if err != nil {
+			return nil, malformed("failed to decode the values: %s", err.Error())
+		}
+		return values, nil
+	case encodingPlainDictionary, encodingRLEDictionary:
+		if dictionary == nil {
+			return nil, malformed("the values are encoded by the dictionary, but the column chunk has no dictionary page")
+		}
+		if n == 0 {
+			return sliceValues(dictionary, 0, 0), nil
+		}
+		if len(data) == 0 {
+			return nil, malformed("no bit width of the dictionary indices")
+		}
+		indices, err := decodeHybrid(data[1:], int(data[0]), n)
+		if err != nil {
+			return nil, malformed("failed to decode the dictionary indices: %s", err.Error())
+		}
+		values, err := gatherValues(dictionary, indices)
+		if err != nil {
+			return nil, malformed("%s", err.Error())
+		}
+		return values, nil
+	case encodingRLE:
+		if column.Type != Boolean {
+			return nil, fmt.Errorf("the RLE encoding of %s is not supported", column.Type.String())
+		}
+		if len(data) < 4 {
+			return nil, malformed("the booleans are truncated")
+		}
+		length := int(binary.LittleEndian.Uint32(data))
+		if length > len(data)-4 {
+			return nil, malformed("the booleans are truncated")
+		}
+		bits, err := decodeHybrid(data[4:4+length], 1, n)
+		if err != nil {
+			return nil, malformed("failed to decode the booleans: %s", err.Error())
+		}
+		values := make([]bool, len(bits))
+		for i, bit := range bits {
+			values[i] = bit != 0
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("the encoding %d of the values is not supported", encoding)
+}
@@ -0,0 +1,295 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rangeRecorder records the ranges read from the file.
+type rangeRecorder struct {
+	*bytes.Reader
+	ranges [][2]int64
+}
+
+func (r *rangeRecorder) ReadAt(p []byte, off int64) (int, error) {
+	r.ranges = append(r.ranges, [2]int64{off, int64(len(p))})
+	return r.Reader.ReadAt(p, off)
+}
+
+func openBytes(t *testing.T, data []byte) *File {
+	f, err := Open(bytes.NewReader(data), int64(len(data)))
+	assert.Nil(t, err)
+	return f
+}
+
+var testFields = []Field{
+	{Name: "id", Type: Int64},
+	{Name: "flag", Type: Boolean, Optional: true},
+	{Name: "age", Type: Int32, Optional: true},
+	{Name: "score", Type: Double},
+	{Name: "name", Type: ByteArray, Optional: true},
+	{Name: "vector", Type: Float, List: true},
+	{Name: "binary", Type: FixedLenByteArray, TypeLength: 2},
+	{Name: "tags", Type: Int64, Optional: true, List: true},
+}
+
+// testRowGroup returns the columns of the rows starting at @start, every third row of the optional fields is null
+// and every fourth list of the tags is empty.
+func testRowGroup(start, rows int) []ColumnValues {
+	columns := make([]ColumnValues, len(testFields))
+	ids, scores, flags, ages, names := []int64{}, []float64{}, []bool{}, []int32{}, [][]byte{}
+	vectors, binaries, tags := []float32{}, [][]byte{}, []int64{}
+	nulls, vectorLengths, tagLengths := make([]bool, rows), make([]int, rows), make([]int, rows)
+	for i := 0; i < rows; i++ {
+		row := start + i
+		ids = append(ids, int64(row))
+		scores = append(scores, float64(row%5)/2)
+		nulls[i] = row%3 == 0
+		if !nulls[i] {
+			flags = append(flags, row%2 == 0)
+			ages = append(ages, int32(row%7))
+			names = append(names, []byte(fmt.Sprintf("name-%d", row%4)))
+		}
+		vectorLengths[i] = 4
+		for d := 0; d < 4; d++ {
+			vectors = append(vectors, float32(row*4+d))
+		}
+		binaries = append(binaries, []byte{byte(row), byte(row >> 8)})
+		if !nulls[i] && row%4 != 0 {
+			tagLengths[i] = row%3 + 1
+			for j := 0; j < tagLengths[i]; j++ {
+				tags = append(tags, int64(row*10+j))
+			}
+		}
+	}
+	columns[0] = ColumnValues{Values: ids}
+	columns[1] = ColumnValues{Values: flags, Nulls: nulls}
+	columns[2] = ColumnValues{Values: ages, Nulls: nulls}
+	columns[3] = ColumnValues{Values: scores}
+	columns[4] = ColumnValues{Values: names, Nulls: nulls}
+	columns[5] = ColumnValues{Values: vectors, ListLengths: vectorLengths}
+	columns[6] = ColumnValues{Values: binaries}
+	columns[7] = ColumnValues{Values: tags, Nulls: nulls, ListLengths: tagLengths}
+	return columns
+}
+
+func writeTestFile(t *testing.T, configure func(w *Writer), groups ...int) []byte {
+	w := NewWriter(testFields...)
+	w.Metadata = map[string]string{"segmentID": "3"}
+	if configure != nil {
+		configure(w)
+	}
+	start := 0
+	for _, rows := range groups {
+		assert.Nil(t, w.WriteRowGroup(rows, testRowGroup(start, rows)...))
+		start += rows
+	}
+	return w.Close()
+}
+
+func TestReadColumn(t *testing.T) {
+	configs := map[string]func(w *Writer){
+		"plain":      nil,
+		"snappy":     func(w *Writer) { w.Codec = Snappy },
+		"gzip":       func(w *Writer) { w.Codec = Gzip },
+		"zstd":       func(w *Writer) { w.Codec = Zstd },
+		"dictionary": func(w *Writer) { w.Dictionary = true },
+		"pages":      func(w *Writer) { w.PageRows = 7 },
+		"v2":         func(w *Writer) { w.DataPageV2 = true; w.Codec = Snappy; w.PageRows = 16 },
+		"all":        func(w *Writer) { w.DataPageV2 = true; w.Codec = Zstd; w.Dictionary = true; w.PageRows = 5 },
+	}
+	groups := []int{50, 0, 23}
+	expected := testRowGroup(0, 73)
+	for name, configure := range configs {
+		t.Run(name, func(t *testing.T) {
+			f := openBytes(t, writeTestFile(t, configure, groups...))
+			assert.Equal(t, int64(73), f.NumRows())
+			assert.Equal(t, map[string]string{"segmentID": "3"}, f.Metadata())
+			assert.Equal(t, len(testFields), len(f.Columns()))
+
+			for idx, field := range testFields {
+				column, err := f.LookupColumn(field.Name)
+				assert.Nil(t, err)
+				assert.Equal(t, field.Type, column.Type)
+				maxDef, maxRep := field.maxLevels()
+				assert.Equal(t, maxDef, column.MaxDefinitionLevel, field.Name)
+				assert.Equal(t, maxRep, column.MaxRepetitionLevel, field.Name)
+				data, err := f.ReadColumn(column)
+				assert.Nil(t, err, field.Name)
+				assert.Equal(t, expected[idx].Values, data.Values, field.Name)
+
+				defs, reps, _, err := levelsOf(&testFields[idx], 73, expected[idx])
+				assert.Nil(t, err)
+				if maxDef > 0 {
+					assert.Equal(t, defs, data.DefinitionLevels, field.Name)
+				} else {
+					assert.Nil(t, data.DefinitionLevels)
+				}
+				if maxRep > 0 {
+					assert.Equal(t, reps, data.RepetitionLevels, field.Name)
+				} else {
+					assert.Nil(t, data.RepetitionLevels)
+				}
+			}
+		})
+	}
+
+	t.Run("list path", func(t *testing.T) {
+		f := openBytes(t, writeTestFile(t, nil, 3))
+		column, err := f.LookupColumn("vector")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"vector", "list", "element"}, column.Path)
+		column, err = f.LookupColumn("binary")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, column.TypeLength)
+		_, err = f.LookupColumn("missing")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestReadColumn_projection(t *testing.T) {
+	data := writeTestFile(t, func(w *Writer) { w.Codec = Snappy }, 40, 40)
+	recorder := &rangeRecorder{Reader: bytes.NewReader(data)}
+	f, err := Open(recorder, int64(len(data)))
+	assert.Nil(t, err)
+	// the tail and the footer
+	assert.Equal(t, 2, len(recorder.ranges))
+
+	recorder.ranges = nil
+	column, err := f.LookupColumn("id")
+	assert.Nil(t, err)
+	_, err = f.ReadColumn(column)
+	assert.Nil(t, err)
+	// the column chunk of each row group is read only
+	assert.Equal(t, 2, len(recorder.ranges))
+	var read int64
+	for _, r := range recorder.ranges {
+		read += r[1]
+	}
+	assert.Equal(t, f.ColumnSize(column), read)
+	assert.True(t, read < int64(len(data))/4)
+}
+
+func TestHybrid(t *testing.T) {
+	// the example of the bit-packed values in the parquet format
+	values, err := decodeHybrid([]byte{0x03, 0x88, 0xc6, 0xfa}, 3, 8)
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{0, 1, 2, 3, 4, 5, 6, 7}, values)
+	// a run of 300 fives
+	values, err = decodeHybrid([]byte{0xd8, 0x04, 0x05}, 3, 300)
+	assert.Nil(t, err)
+	assert.Equal(t, 300, len(values))
+	assert.Equal(t, int32(5), values[299])
+
+	for _, width := range []int{0, 1, 3, 8, 9, 17, 32} {
+		values := make([]int32, 0, 1000)
+		for i := 0; i < 1000; i++ {
+			value := int32(i * 7919)
+			if i%100 < 30 {
+				// the runs
+				value = int32(i / 100)
+			}
+			if width < 32 {
+				value &= 1<<uint(width) - 1
+			}
+			values = append(values, value)
+		}
+		for _, n := range []int{1, 7, 8, 9, 999, 1000} {
+			decoded, err := decodeHybrid(encodeHybrid(values[:n], width), width, n)
+			assert.Nil(t, err, width)
+			assert.Equal(t, values[:n], decoded, width)
+		}
+	}
+
+	_, err = decodeHybrid([]byte{0x03, 0x88}, 3, 8)
+	assert.NotNil(t, err)
+	_, err = decodeHybrid([]byte{0x00, 0x05}, 3, 8)
+	assert.NotNil(t, err)
+	_, err = decodeHybrid([]byte{0x10, 0x09}, 3, 8)
+	assert.NotNil(t, err)
+	_, err = decodeLevelsOf(encodeHybrid([]int32{0, 1, 3}, 2), 2, 3)
+	assert.NotNil(t, err)
+}
+
+func TestOpen_malformed(t *testing.T) {
+	data := writeTestFile(t, func(w *Writer) { w.Codec = Snappy; w.PageRows = 10 }, 30)
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerStart := len(data) - 8 - footerSize
+
+	open := func(data []byte) error {
+		_, err := Open(bytes.NewReader(data), int64(len(data)))
+		return err
+	}
+	for name, corrupt := range map[string]func([]byte) []byte{
+		"empty":     func([]byte) []byte { return nil },
+		"magic":     func(d []byte) []byte { d[len(d)-1] = 'X'; return d },
+		"truncated": func(d []byte) []byte { return d[:len(d)-10] },
+		"footer size": func(d []byte) []byte {
+			binary.LittleEndian.PutUint32(d[len(d)-8:], uint32(len(d)))
+			return d
+		},
+		"footer": func(d []byte) []byte {
+			for i := footerStart; i < footerStart+footerSize/2; i++ {
+				d[i] = 0xff
+			}
+			return d
+		},
+		"not parquet": func([]byte) []byte { return []byte("this is a binlog, not a parquet file") },
+	} {
+		err := open(corrupt(append([]byte{}, data...)))
+		assert.NotNil(t, err, name)
+		assert.True(t, errors.Is(err, ErrMalformed), name)
+	}
+
+	// the corrupted page header is found when the column is read
+	corrupted := append([]byte{}, data...)
+	for i := 4; i < 12; i++ {
+		corrupted[i] = 0xff
+	}
+	f := openBytes(t, corrupted)
+	_, err := f.ReadColumn(f.Columns()[0])
+	assert.True(t, errors.Is(err, ErrMalformed))
+
+	// the metadata mismatching the pages
+	for name, corrupt := range map[string]func(meta *columnMetaData){
+		"truncated chunk": func(meta *columnMetaData) { meta.totalCompressed -= 5 },
+		"more values":     func(meta *columnMetaData) { meta.numValues++ },
+		"codec":           func(meta *columnMetaData) { meta.codec = Snappy },
+		"unknown codec":   func(meta *columnMetaData) { meta.codec = 4 },
+		"type":            func(meta *columnMetaData) { meta.typ = Int32 },
+		"out of file":     func(meta *columnMetaData) { meta.dataPageOffset = 1 << 20 },
+	} {
+		w := NewWriter(Field{Name: "id", Type: Int64})
+		assert.Nil(t, w.WriteRowGroup(3, ColumnValues{Values: []int64{1, 2, 3}}))
+		corrupt(w.rowGroups[0].columns[0].meta)
+		data := w.Close()
+		f, err := Open(bytes.NewReader(data), int64(len(data)))
+		if err == nil {
+			_, err = f.ReadColumn(f.Columns()[0])
+		}
+		assert.NotNil(t, err, name)
+	}
+
+	// the writer rejects the columns mismatching the fields
+	w := NewWriter(Field{Name: "id", Type: Int64}, Field{Name: "tags", Type: Int64, List: true})
+	assert.NotNil(t, w.WriteRowGroup(1, ColumnValues{Values: []int64{1}}))
+	assert.NotNil(t, w.WriteRowGroup(2, ColumnValues{Values: []int64{1}}, ColumnValues{Values: []int64{1}, ListLengths: []int{1, 0}}))
+	assert.NotNil(t, w.WriteRowGroup(1, ColumnValues{Values: []int64{1}, Nulls: []bool{true}},
+		ColumnValues{Values: []int64{}, ListLengths: []int{0}}))
+}
@@ -0,0 +1,129 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ByteArrayStream is the parquet file of a single BYTE_ARRAY field, whose values are read from the memory they are
+// in as the file is read instead of being copied into the file, so the file of the large values is never held in
+// memory. Each value is written as a data page of version 1 in the plain encoding without compression, which makes
+// the size of the file known before it is read.
+type ByteArrayStream struct {
+	// parts are the pieces of the file in order, the values are among them as they are
+	parts [][]byte
+	size  int64
+}
+
+// NewByteArrayStream returns the file of @field whose rows are @values, none of the rows is null.
+func NewByteArrayStream(field Field, values [][]byte) (*ByteArrayStream, error) {
+	if field.Type != ByteArray || field.List {
+		return nil, fmt.Errorf("the field %s of %s is not supported by the stream", field.Name, field.Type.String())
+	}
+	s := &ByteArrayStream{}
+	s.append([]byte(magic))
+
+	// the levels of the optional field, each page defines its only value
+	var levels []byte
+	if field.Optional {
+		levels = withLength(encodeHybrid([]int32{1}, 1))
+	}
+	meta := &columnMetaData{
+		typ:            ByteArray,
+		encodings:      []int32{encodingPlain, encodingRLE},
+		pathInSchema:   []string{field.Name},
+		codec:          Uncompressed,
+		numValues:      int64(len(values)),
+		dataPageOffset: s.size,
+	}
+	writePage := func(numValues int32, body ...[]byte) error {
+		size := int64(0)
+		for _, b := range body {
+			size += int64(len(b))
+		}
+		if size > math.MaxInt32 {
+			return fmt.Errorf("the page of %d bytes exceeds the max page size", size)
+		}
+		header := &pageHeader{
+			typ:                  pageData,
+			uncompressedPageSize: int32(size),
+			compressedPageSize:   int32(size),
+			dataPage: &dataPageHeader{
+				numValues:               numValues,
+				encoding:                encodingPlain,
+				definitionLevelEncoding: encodingRLE,
+				repetitionLevelEncoding: encodingRLE,
+			},
+		}
+		var thrift thriftWriter
+		thrift.writePageHeader(header)
+		s.append(thrift.data)
+		for _, b := range body {
+			s.append(b)
+		}
+		meta.totalUncompressed += int64(len(thrift.data)) + size
+		return nil
+	}
+	for _, value := range values {
+		length := make([]byte, 4)
+		binary.LittleEndian.PutUint32(length, uint32(len(value)))
+		if err := writePage(1, levels, length, value); err != nil {
+			return nil, fmt.Errorf("failed to write the field %s: %w", field.Name, err)
+		}
+	}
+	if len(values) == 0 {
+		// the column chunk of no rows still has a page of no values, as Writer writes
+		var empty []byte
+		if field.Optional {
+			empty = withLength(nil)
+		}
+		if err := writePage(0, empty); err != nil {
+			return nil, err
+		}
+	}
+	meta.totalCompressed = meta.totalUncompressed
+
+	group := &rowGroup{
+		columns:       []*columnChunk{{fileOffset: meta.dataPageOffset, meta: meta}},
+		totalByteSize: meta.totalUncompressed,
+		numRows:       int64(len(values)),
+	}
+	s.append(footerOf([]Field{field}, nil, int64(len(values)), []*rowGroup{group}))
+	return s, nil
+}
+
+func (s *ByteArrayStream) append(part []byte) {
+	if len(part) == 0 {
+		return
+	}
+	s.parts = append(s.parts, part)
+	s.size += int64(len(part))
+}
+
+// Size returns the bytes of the file.
+func (s *ByteArrayStream) Size() int64 {
+	return s.size
+}
+
+// NewReader returns a reader of the file from the start, the values must not be modified until the file is read.
+func (s *ByteArrayStream) NewReader() io.Reader {
+	readers := make([]io.Reader, len(s.parts))
+	for i, part := range s.parts {
+		readers[i] = bytes.NewReader(part)
+	}
+	return io.MultiReader(readers...)
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package parquet
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteArrayStream(t *testing.T) {
+	large := make([]byte, 1<<20)
+	for i := range large {
+		large[i] = byte(i)
+	}
+	for _, field := range []Field{
+		{Name: "val", Type: ByteArray, Optional: true, UTF8: true},
+		{Name: "val", Type: ByteArray},
+	} {
+		for _, values := range [][][]byte{{[]byte("index"), {}, large}, {}} {
+			s, err := NewByteArrayStream(field, values)
+			assert.Nil(t, err)
+			data, err := ioutil.ReadAll(s.NewReader())
+			assert.Nil(t, err)
+			assert.Equal(t, s.Size(), int64(len(data)))
+
+			// the stream is the file written by Writer with a page for each row
+			w := NewWriter(field)
+			w.PageRows = 1
+			assert.Nil(t, w.WriteRowGroup(len(values), ColumnValues{Values: values}))
+			assert.Equal(t, w.Close(), data)
+
+			f := openBytes(t, data)
+			assert.Equal(t, int64(len(values)), f.NumRows())
+			column, err := f.LookupColumn("val")
+			assert.Nil(t, err)
+			read, err := f.ReadColumn(column)
+			assert.Nil(t, err)
+			assert.Equal(t, len(values), len(read.Values.([][]byte)))
+			for i, value := range values {
+				assert.Equal(t, len(value), len(read.Values.([][]byte)[i]))
+				assert.Equal(t, value, append([]byte{}, read.Values.([][]byte)[i]...))
+			}
+
+			// each reader reads the file from the start
+			again, err := ioutil.ReadAll(s.NewReader())
+			assert.Nil(t, err)
+			assert.Equal(t, data, again)
+		}
+	}
+
+	// the values are read from the memory they are in
+	value := []byte("before")
+	s, err := NewByteArrayStream(Field{Name: "val", Type: ByteArray}, [][]byte{value})
+	assert.Nil(t, err)
+	copy(value, "after!")
+	data, err := ioutil.ReadAll(s.NewReader())
+	assert.Nil(t, err)
+	f := openBytes(t, data)
+	column, err := f.LookupColumn("val")
+	assert.Nil(t, err)
+	read, err := f.ReadColumn(column)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("after!"), read.Values.([][]byte)[0])
+
+	for _, field := range []Field{
+		{Name: "val", Type: Int64},
+		{Name: "val", Type: ByteArray, List: true},
+	} {
+		_, err := NewByteArrayStream(field, nil)
+		assert.NotNil(t, err)
+	}
+}
@@ -0,0 +1,351 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// the types of the thrift compact protocol
+const (
+	thriftStop      = 0
+	thriftTrue      = 1
+	thriftFalse     = 2
+	thriftByte      = 3
+	thriftI16       = 4
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftDouble    = 7
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftSet       = 10
+	thriftMap       = 11
+	thriftStruct    = 12
+	thriftMaxDepth  = 64
+	thriftMaxLength = 1 << 30
+)
+
+var errThriftTruncated = errors.New("the thrift struct is truncated")
+
+// thriftReader decodes the structs of the thrift compact protocol, which encodes the metadata of the parquet files.
+type thriftReader struct {
+	data []byte
+	pos  int
+	// lastFieldIDs is the last field id of each struct being decoded
+	lastFieldIDs []int16
+}
+
+func newThriftReader(data []byte) *thriftReader {
+	return &thriftReader{data: data}
+}
+
+func (r *thriftReader) readByte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, errThriftTruncated
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *thriftReader) readUvarint() (uint64, error) {
+	value, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, errThriftTruncated
+	}
+	r.pos += n
+	return value, nil
+}
+
+func (r *thriftReader) readVarint() (int64, error) {
+	value, err := r.readUvarint()
+	if err != nil {
+		return 0, err
+	}
+	// zigzag
+	return int64(value>>1) ^ -int64(value&1), nil
+}
+
+func (r *thriftReader) readI32() (int32, error) {
+	value, err := r.readVarint()
+	if err != nil {
+		return 0, err
+	}
+	if value < math.MinInt32 || value > math.MaxInt32 {
+		return 0, fmt.Errorf("the thrift i32 %d is out of range", value)
+	}
+	return int32(value), nil
+}
+
+func (r *thriftReader) readI64() (int64, error) {
+	return r.readVarint()
+}
+
+func (r *thriftReader) readBinary() ([]byte, error) {
+	length, err := r.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(len(r.data)-r.pos) {
+		return nil, errThriftTruncated
+	}
+	value := r.data[r.pos : r.pos+int(length)]
+	r.pos += int(length)
+	return value, nil
+}
+
+func (r *thriftReader) readString() (string, error) {
+	value, err := r.readBinary()
+	return string(value), err
+}
+
+// beginStruct starts decoding a struct, whose field ids are relative to the last field of it.
+func (r *thriftReader) beginStruct() error {
+	if len(r.lastFieldIDs) >= thriftMaxDepth {
+		return errors.New("the thrift structs are nested too deeply")
+	}
+	r.lastFieldIDs = append(r.lastFieldIDs, 0)
+	return nil
+}
+
+func (r *thriftReader) endStruct() {
+	r.lastFieldIDs = r.lastFieldIDs[:len(r.lastFieldIDs)-1]
+}
+
+// readField reads the header of the next field of the struct, it returns thriftStop at the end of the struct. The
+// value of a bool field is encoded in its type, which is either thriftTrue or thriftFalse.
+func (r *thriftReader) readField() (int16, byte, error) {
+	header, err := r.readByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	fieldType := header & 0x0f
+	if fieldType == thriftStop {
+		return 0, thriftStop, nil
+	}
+	last := &r.lastFieldIDs[len(r.lastFieldIDs)-1]
+	if delta := int16(header >> 4); delta != 0 {
+		*last += delta
+	} else {
+		id, err := r.readVarint()
+		if err != nil {
+			return 0, 0, err
+		}
+		*last = int16(id)
+	}
+	return *last, fieldType, nil
+}
+
+// readList reads the header of a list or a set, and returns the type and the number of its elements.
+func (r *thriftReader) readList() (byte, int, error) {
+	header, err := r.readByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	size := uint64(header >> 4)
+	if size == 15 {
+		if size, err = r.readUvarint(); err != nil {
+			return 0, 0, err
+		}
+	}
+	// every element takes a byte at least
+	if size > uint64(len(r.data)-r.pos) {
+		return 0, 0, errThriftTruncated
+	}
+	return header & 0x0f, int(size), nil
+}
+
+// readBool reads a bool element of a list, the bool fields are read by readField.
+func (r *thriftReader) readBool() (bool, error) {
+	b, err := r.readByte()
+	return b == thriftTrue, err
+}
+
+// skip skips a value of @fieldType.
+func (r *thriftReader) skip(fieldType byte) error {
+	switch fieldType {
+	case thriftTrue, thriftFalse:
+		return nil
+	case thriftByte:
+		_, err := r.readByte()
+		return err
+	case thriftI16, thriftI32, thriftI64:
+		_, err := r.readVarint()
+		return err
+	case thriftDouble:
+		if len(r.data)-r.pos < 8 {
+			return errThriftTruncated
+		}
+		r.pos += 8
+		return nil
+	case thriftBinary:
+		_, err := r.readBinary()
+		return err
+	case thriftList, thriftSet:
+		elemType, size, err := r.readList()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < size; i++ {
+			if elemType == thriftTrue || elemType == thriftFalse {
+				// the bool elements take a byte each
+				if _, err := r.readByte(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := r.skip(elemType); err != nil {
+				return err
+			}
+		}
+		return nil
+	case thriftMap:
+		size, err := r.readUvarint()
+		if err != nil {
+			return err
+		}
+		if size == 0 {
+			return nil
+		}
+		if size > uint64(len(r.data)-r.pos) {
+			return errThriftTruncated
+		}
+		types, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < size; i++ {
+			if err := r.skip(types >> 4); err != nil {
+				return err
+			}
+			if err := r.skip(types & 0x0f); err != nil {
+				return err
+			}
+		}
+		return nil
+	case thriftStruct:
+		if err := r.beginStruct(); err != nil {
+			return err
+		}
+		defer r.endStruct()
+		for {
+			_, fieldType, err := r.readField()
+			if err != nil {
+				return err
+			}
+			if fieldType == thriftStop {
+				return nil
+			}
+			if err := r.skip(fieldType); err != nil {
+				return err
+			}
+		}
+	}
+	return fmt.Errorf("unknown thrift type %d", fieldType)
+}
+
+// readStruct decodes a struct by calling @field on each of its fields, @field skips the fields it does not know.
+func (r *thriftReader) readStruct(field func(id int16, fieldType byte) error) error {
+	if err := r.beginStruct(); err != nil {
+		return err
+	}
+	defer r.endStruct()
+	for {
+		id, fieldType, err := r.readField()
+		if err != nil {
+			return err
+		}
+		if fieldType == thriftStop {
+			return nil
+		}
+		if err := field(id, fieldType); err != nil {
+			return err
+		}
+	}
+}
+
+// thriftWriter encodes the structs of the thrift compact protocol.
+type thriftWriter struct {
+	data         []byte
+	lastFieldIDs []int16
+}
+
+func (w *thriftWriter) writeUvarint(value uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], value)
+	w.data = append(w.data, buf[:n]...)
+}
+
+func (w *thriftWriter) writeVarint(value int64) {
+	w.writeUvarint(uint64(value<<1) ^ uint64(value>>63))
+}
+
+func (w *thriftWriter) beginStruct() {
+	w.lastFieldIDs = append(w.lastFieldIDs, 0)
+}
+
+// endStruct writes the stop of the struct.
+func (w *thriftWriter) endStruct() {
+	w.lastFieldIDs = w.lastFieldIDs[:len(w.lastFieldIDs)-1]
+	w.data = append(w.data, thriftStop)
+}
+
+func (w *thriftWriter) writeField(id int16, fieldType byte) {
+	last := &w.lastFieldIDs[len(w.lastFieldIDs)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.data = append(w.data, byte(delta)<<4|fieldType)
+	} else {
+		w.data = append(w.data, fieldType)
+		w.writeVarint(int64(id))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) writeI32Field(id int16, value int32) {
+	w.writeField(id, thriftI32)
+	w.writeVarint(int64(value))
+}
+
+func (w *thriftWriter) writeI64Field(id int16, value int64) {
+	w.writeField(id, thriftI64)
+	w.writeVarint(value)
+}
+
+func (w *thriftWriter) writeBoolField(id int16, value bool) {
+	if value {
+		w.writeField(id, thriftTrue)
+	} else {
+		w.writeField(id, thriftFalse)
+	}
+}
+
+func (w *thriftWriter) writeBinary(value []byte) {
+	w.writeUvarint(uint64(len(value)))
+	w.data = append(w.data, value...)
+}
+
+func (w *thriftWriter) writeStringField(id int16, value string) {
+	w.writeField(id, thriftBinary)
+	w.writeBinary([]byte(value))
+}
+
+func (w *thriftWriter) writeList(elemType byte, size int) {
+	if size < 15 {
+		w.data = append(w.data, byte(size)<<4|elemType)
+		return
+	}
+	w.data = append(w.data, 0xf0|elemType)
+	w.writeUvarint(uint64(size))
+}
@@ -0,0 +1,403 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Field is a top-level field of the files written by Writer.
+type Field struct {
+	Name string
+	Type Type
+	// TypeLength is the bytes of each value of the FIXED_LEN_BYTE_ARRAY fields
+	TypeLength int
+	// Optional is whether the rows of the field can be null
+	Optional bool
+	// List is whether each row of the field is a list of the values of Type, which is written as the 3-level list
+	List bool
+	// UTF8 is whether the values of the BYTE_ARRAY field are annotated as the strings, which arrow reads as strings
+	// instead of binaries
+	UTF8 bool
+}
+
+// maxLevels returns the max definition and repetition levels of the leaf column of the field.
+func (field *Field) maxLevels() (int, int) {
+	def, rep := 0, 0
+	if field.Optional {
+		def++
+	}
+	if field.List {
+		def++
+		rep++
+	}
+	return def, rep
+}
+
+// ColumnValues are the rows of a field in a row group.
+type ColumnValues struct {
+	// Values are the values of the rows which are not null, or the elements of all the lists of the list fields.
+	// They are of []bool, []int32, []int64, []float32, []float64 or [][]byte by the type of the field.
+	Values interface{}
+	// Nulls marks the null rows of the optional fields, nil if no row is null
+	Nulls []bool
+	// ListLengths are the elements of each row of the list fields, the null rows have none
+	ListLengths []int
+}
+
+// Writer writes the parquet files of the fields, which are written in the encodings the package reads. It is used
+// to write the fixtures of the tests and the tools, it keeps the whole file in memory.
+type Writer struct {
+	// Codec compresses the pages
+	Codec Codec
+	// Dictionary encodes the values of the fields other than the booleans with a dictionary
+	Dictionary bool
+	// DataPageV2 writes the data pages of version 2 instead of version 1
+	DataPageV2 bool
+	// PageRows is the max rows of a data page, 0 writes a data page for each column chunk
+	PageRows int
+	// Metadata is the key-value metadata of the file
+	Metadata map[string]string
+
+	fields    []Field
+	data      []byte
+	rowGroups []*rowGroup
+	numRows   int64
+}
+
+// NewWriter returns a writer of the files of @fields.
+func NewWriter(fields ...Field) *Writer {
+	return &Writer{fields: fields, data: []byte(magic)}
+}
+
+// WriteRowGroup writes a row group of @rows, whose columns are of the fields in order.
+func (w *Writer) WriteRowGroup(rows int, columns ...ColumnValues) error {
+	if len(columns) != len(w.fields) {
+		return fmt.Errorf("%d columns are written, expect %d", len(columns), len(w.fields))
+	}
+	group := &rowGroup{numRows: int64(rows)}
+	start := len(w.data)
+	for idx := range w.fields {
+		chunk, err := w.writeChunk(&w.fields[idx], rows, columns[idx])
+		if err != nil {
+			return fmt.Errorf("failed to write the field %s: %w", w.fields[idx].Name, err)
+		}
+		group.columns = append(group.columns, chunk)
+	}
+	group.totalByteSize = int64(len(w.data) - start)
+	w.rowGroups = append(w.rowGroups, group)
+	w.numRows += int64(rows)
+	return nil
+}
+
+// levelsOf returns the definition and the repetition levels of the rows, and the number of the values of each row.
+func levelsOf(field *Field, rows int, column ColumnValues) ([]int32, []int32, []int, error) {
+	maxDef, _ := field.maxLevels()
+	if column.Nulls != nil && (!field.Optional || len(column.Nulls) != rows) {
+		return nil, nil, nil, fmt.Errorf("invalid nulls of %d rows", len(column.Nulls))
+	}
+	if field.List && len(column.ListLengths) != rows {
+		return nil, nil, nil, fmt.Errorf("%d list lengths are written, expect %d", len(column.ListLengths), rows)
+	}
+	var defs, reps []int32
+	values := make([]int, rows)
+	for row := 0; row < rows; row++ {
+		null := column.Nulls != nil && column.Nulls[row]
+		switch {
+		case null:
+			defs, reps = append(defs, 0), append(reps, 0)
+		case !field.List:
+			defs, reps = append(defs, int32(maxDef)), append(reps, 0)
+			values[row] = 1
+		case column.ListLengths[row] == 0:
+			// the empty list defines all the levels but the repeated one
+			defs, reps = append(defs, int32(maxDef-1)), append(reps, 0)
+		default:
+			for i := 0; i < column.ListLengths[row]; i++ {
+				defs, reps = append(defs, int32(maxDef)), append(reps, int32(minInt(i, 1)))
+			}
+			values[row] = column.ListLengths[row]
+		}
+	}
+	var total int
+	for _, n := range values {
+		total += n
+	}
+	if total != valuesLen(column.Values) {
+		return nil, nil, nil, fmt.Errorf("%d values are written, expect %d", valuesLen(column.Values), total)
+	}
+	return defs, reps, values, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// dictionaryOf returns the distinct values in the order they appear, and the index of each value in them.
+func dictionaryOf(values interface{}) (interface{}, []int32) {
+	n := valuesLen(values)
+	indices := make([]int32, n)
+	positions := make(map[interface{}]int32)
+	var order []int
+	for i := 0; i < n; i++ {
+		var key interface{}
+		switch v := values.(type) {
+		case []int32:
+			key = v[i]
+		case []int64:
+			key = v[i]
+		case []float32:
+			key = math.Float32bits(v[i])
+		case []float64:
+			key = math.Float64bits(v[i])
+		case [][]byte:
+			key = string(v[i])
+		}
+		position, ok := positions[key]
+		if !ok {
+			position = int32(len(order))
+			positions[key] = position
+			order = append(order, i)
+		}
+		indices[i] = position
+	}
+	dictionary := sliceValues(values, 0, 0)
+	for _, i := range order {
+		dictionary = appendValues(dictionary, sliceValues(values, i, i+1))
+	}
+	return dictionary, indices
+}
+
+func (w *Writer) encodeValues(field *Field, values interface{}) []byte {
+	if field.Type == FixedLenByteArray {
+		return encodeFixed(values.([][]byte))
+	}
+	return encodePlain(values)
+}
+
+// writePage writes a page of @body, whose header is completed with the sizes of the body.
+func (w *Writer) writePage(header *pageHeader, levels []byte, body []byte) (int64, int64, error) {
+	compressed := body
+	if w.Codec != Uncompressed && (header.dataPageV2 == nil || header.dataPageV2.isCompressed) {
+		var err error
+		if compressed, err = compress(w.Codec, body); err != nil {
+			return 0, 0, err
+		}
+	}
+	header.uncompressedPageSize = int32(len(levels) + len(body))
+	header.compressedPageSize = int32(len(levels) + len(compressed))
+	var thrift thriftWriter
+	thrift.writePageHeader(header)
+	w.data = append(w.data, thrift.data...)
+	w.data = append(w.data, levels...)
+	w.data = append(w.data, compressed...)
+	return int64(len(thrift.data)) + int64(header.uncompressedPageSize), int64(len(thrift.data)) + int64(header.compressedPageSize), nil
+}
+
+// withLength prefixes the levels with their length, as the levels of the data pages of version 1.
+func withLength(levels []byte) []byte {
+	prefixed := make([]byte, 4, 4+len(levels))
+	binary.LittleEndian.PutUint32(prefixed, uint32(len(levels)))
+	return append(prefixed, levels...)
+}
+
+func (w *Writer) writeChunk(field *Field, rows int, column ColumnValues) (*columnChunk, error) {
+	if field.Type == Int96 || (field.Type == FixedLenByteArray && field.TypeLength <= 0) {
+		return nil, fmt.Errorf("the field of %s is not supported", field.Type.String())
+	}
+	defs, reps, rowValues, err := levelsOf(field, rows, column)
+	if err != nil {
+		return nil, err
+	}
+	if column.Values == nil {
+		column.Values = emptyValues(field.Type)
+	}
+	maxDef, maxRep := field.maxLevels()
+	meta := &columnMetaData{
+		typ:          field.Type,
+		pathInSchema: []string{field.Name},
+		codec:        w.Codec,
+		numValues:    int64(len(defs)),
+	}
+	if field.List {
+		meta.pathInSchema = []string{field.Name, "list", "element"}
+	}
+
+	values := column.Values
+	var indices []int32
+	var indexWidth int
+	encoding := encodingPlain
+	if w.Dictionary && field.Type != Boolean {
+		var dictionary interface{}
+		dictionary, indices = dictionaryOf(values)
+		indexWidth = bitWidth(valuesLen(dictionary) - 1)
+		meta.hasDictionary = true
+		meta.dictionaryPageOffset = int64(len(w.data))
+		header := &pageHeader{typ: pageDictionary, dictionaryPage: &dictionaryPageHeader{
+			numValues: int32(valuesLen(dictionary)),
+			encoding:  encodingPlain,
+		}}
+		uncompressed, compressed, err := w.writePage(header, nil, w.encodeValues(field, dictionary))
+		if err != nil {
+			return nil, err
+		}
+		meta.totalUncompressed += uncompressed
+		meta.totalCompressed += compressed
+		encoding = encodingRLEDictionary
+	}
+	meta.encodings = []int32{int32(encoding), encodingRLE}
+	if encoding != encodingPlain {
+		meta.encodings = append(meta.encodings, encodingPlain)
+	}
+	meta.dataPageOffset = int64(len(w.data))
+
+	pageRows := w.PageRows
+	if pageRows <= 0 {
+		pageRows = rows
+	}
+	// the pages are split at the row boundaries, the levels and the values of each page are sliced out of all
+	levelStart, valueStart := 0, 0
+	for row := 0; row < rows || (row == 0 && rows == 0); row += pageRows {
+		end := minInt(row+pageRows, rows)
+		levelEnd, valueEnd := levelStart, valueStart
+		for r := row; r < end; r++ {
+			valueEnd += rowValues[r]
+			levelEnd += maxInt(rowValues[r], 1)
+		}
+		var body []byte
+		n := valueEnd - valueStart
+		if indices != nil {
+			body = append([]byte{byte(indexWidth)}, encodeHybrid(indices[valueStart:valueEnd], indexWidth)...)
+		} else {
+			body = w.encodeValues(field, sliceValues(values, valueStart, valueEnd))
+		}
+		var repLevels, defLevels []byte
+		if maxRep > 0 {
+			repLevels = encodeHybrid(reps[levelStart:levelEnd], bitWidth(maxRep))
+		}
+		if maxDef > 0 {
+			defLevels = encodeHybrid(defs[levelStart:levelEnd], bitWidth(maxDef))
+		}
+
+		header := &pageHeader{}
+		var levels []byte
+		if w.DataPageV2 {
+			header.typ = pageDataV2
+			header.dataPageV2 = &dataPageHeaderV2{
+				numValues:                  int32(levelEnd - levelStart),
+				numNulls:                   int32(levelEnd - levelStart - n),
+				numRows:                    int32(end - row),
+				encoding:                   int32(encoding),
+				definitionLevelsByteLength: int32(len(defLevels)),
+				repetitionLevelsByteLength: int32(len(repLevels)),
+				isCompressed:               w.Codec != Uncompressed,
+			}
+			levels = append(repLevels, defLevels...)
+		} else {
+			header.typ = pageData
+			header.dataPage = &dataPageHeader{
+				numValues:               int32(levelEnd - levelStart),
+				encoding:                int32(encoding),
+				definitionLevelEncoding: encodingRLE,
+				repetitionLevelEncoding: encodingRLE,
+			}
+			var prefixed []byte
+			if maxRep > 0 {
+				prefixed = append(prefixed, withLength(repLevels)...)
+			}
+			if maxDef > 0 {
+				prefixed = append(prefixed, withLength(defLevels)...)
+			}
+			// the levels of version 1 are compressed along with the values
+			body = append(prefixed, body...)
+		}
+		uncompressed, compressed, err := w.writePage(header, levels, body)
+		if err != nil {
+			return nil, err
+		}
+		meta.totalUncompressed += uncompressed
+		meta.totalCompressed += compressed
+		levelStart, valueStart = levelEnd, valueEnd
+		if rows == 0 {
+			break
+		}
+	}
+	return &columnChunk{fileOffset: meta.dataPageOffset, meta: meta}, nil
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Close writes the footer and returns the file.
+func (w *Writer) Close() []byte {
+	return append(w.data, footerOf(w.fields, w.Metadata, w.numRows, w.rowGroups)...)
+}
+
+// footerOf returns the footer of the file of @fields, which ends the file after the row groups.
+func footerOf(fields []Field, metadata map[string]string, numRows int64, rowGroups []*rowGroup) []byte {
+	meta := &fileMetaData{
+		version:   1,
+		numRows:   numRows,
+		rowGroups: rowGroups,
+		createdBy: "milvus",
+	}
+	meta.schema = append(meta.schema, &schemaElement{name: "schema", numChildren: int32(len(fields))})
+	for idx := range fields {
+		field := &fields[idx]
+		repetition := Required
+		if field.Optional {
+			repetition = Optional
+		}
+		typ := field.Type
+		leaf := &schemaElement{typ: &typ, typeLength: int32(field.TypeLength), repetition: repetition, name: field.Name}
+		if field.UTF8 {
+			utf8 := int32(convertedTypeUTF8)
+			leaf.convertedType = &utf8
+		}
+		if !field.List {
+			meta.schema = append(meta.schema, leaf)
+			continue
+		}
+		list := int32(convertedTypeList)
+		leaf.repetition, leaf.name = Required, "element"
+		meta.schema = append(meta.schema,
+			&schemaElement{repetition: repetition, name: field.Name, numChildren: 1, convertedType: &list},
+			&schemaElement{repetition: Repeated, name: "list", numChildren: 1},
+			leaf)
+	}
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		meta.keyValueMetadata = append(meta.keyValueMetadata, &keyValue{key: key, value: metadata[key]})
+	}
+
+	var thrift thriftWriter
+	thrift.writeFileMetaData(meta)
+	footer := thrift.data
+	size := make([]byte, 4)
+	binary.LittleEndian.PutUint32(size, uint32(len(thrift.data)))
+	footer = append(footer, size...)
+	return append(footer, magic...)
+}
@@ -39,6 +39,8 @@ type PayloadWriterInterface interface {
 	AddOneStringToPayload(msgs string) error
 	AddBinaryVectorToPayload(binVec []byte, dim int) error
 	AddFloatVectorToPayload(binVec []float32, dim int) error
+	AddFloat16VectorToPayload(vec []byte, dim int) error
+	AddBFloat16VectorToPayload(vec []byte, dim int) error
 	FinishPayloadWriter() error
 	GetPayloadBufferFromWriter() ([]byte, error)
 	GetPayloadLengthFromWriter() (int, error)
@@ -58,6 +60,8 @@ type PayloadReaderInterface interface {
 	GetOneStringFromPayload(idx int) (string, error)
 	GetBinaryVectorFromPayload() ([]byte, int, error)
 	GetFloatVectorFromPayload() ([]float32, int, error)
+	GetFloat16VectorFromPayload() ([]byte, int, error)
+	GetBFloat16VectorFromPayload() ([]byte, int, error)
 	GetPayloadLengthFromReader() (int, error)
 	ReleasePayloadReader() error
 	Close() error
@@ -150,6 +154,18 @@ func (w *PayloadWriter) AddDataToPayload(msgs interface{}, dim ...int) error {
 				return errors.New("incorrect data type")
 			}
 			return w.AddFloatVectorToPayload(val, dim[0])
+		case schemapb.DataType_Float16Vector:
+			val, ok := msgs.([]byte)
+			if !ok {
+				return errors.New("incorrect data type")
+			}
+			return w.AddFloat16VectorToPayload(val, dim[0])
+		case schemapb.DataType_BFloat16Vector:
+			val, ok := msgs.([]byte)
+			if !ok {
+				return errors.New("incorrect data type")
+			}
+			return w.AddBFloat16VectorToPayload(val, dim[0])
 		default:
 			return errors.New("incorrect datatype")
 		}
@@ -366,6 +382,48 @@ func (w *PayloadWriter) AddFloatVectorToPayload(floatVec []float32, dim int) err
 	return nil
 }
 
+// AddFloat16VectorToPayload adds the float16 vectors, each element takes 2 bytes
+func (w *PayloadWriter) AddFloat16VectorToPayload(vec []byte, dim int) error {
+	if w.colType != schemapb.DataType_Float16Vector {
+		return errors.New("incorrect data type")
+	}
+	return w.addHalfFloatVectorToPayload(vec, dim)
+}
+
+// AddBFloat16VectorToPayload adds the bfloat16 vectors, each element takes 2 bytes
+func (w *PayloadWriter) AddBFloat16VectorToPayload(vec []byte, dim int) error {
+	if w.colType != schemapb.DataType_BFloat16Vector {
+		return errors.New("incorrect data type")
+	}
+	return w.addHalfFloatVectorToPayload(vec, dim)
+}
+
+func (w *PayloadWriter) addHalfFloatVectorToPayload(vec []byte, dim int) error {
+	length := len(vec)
+	if length <= 0 {
+		return errors.New("can't add empty vec into payload")
+	}
+	if dim <= 0 {
+		return errors.New("dimension should be greater than 0")
+	}
+	if length%(dim*2) != 0 {
+		return errors.New("the bytes of the vectors are not whole rows")
+	}
+
+	cVec := (*C.uint8_t)(&vec[0])
+	cDim := C.int(dim)
+	cLength := C.int(length / (dim * 2))
+
+	st := C.AddHalfFloatVectorToPayload(w.payloadWriterPtr, cVec, cDim, cLength)
+	errCode := commonpb.ErrorCode(st.error_code)
+	if errCode != commonpb.ErrorCode_Success {
+		msg := C.GoString(st.error_msg)
+		defer C.free(unsafe.Pointer(st.error_msg))
+		return errors.New(msg)
+	}
+	return nil
+}
+
 func (w *PayloadWriter) FinishPayloadWriter() error {
 	st := C.FinishPayloadWriter(w.payloadWriterPtr)
 	errCode := commonpb.ErrorCode(st.error_code)
@@ -463,6 +521,10 @@ func (r *PayloadReader) GetDataFromPayload(idx ...int) (interface{}, int, error)
 			return r.GetBinaryVectorFromPayload()
 		case schemapb.DataType_FloatVector:
 			return r.GetFloatVectorFromPayload()
+		case schemapb.DataType_Float16Vector:
+			return r.GetFloat16VectorFromPayload()
+		case schemapb.DataType_BFloat16Vector:
+			return r.GetBFloat16VectorFromPayload()
 		default:
 			return nil, 0, errors.New("unknown type")
 		}
@@ -687,6 +749,41 @@ func (r *PayloadReader) GetFloatVectorFromPayload() ([]float32, int, error) {
 	return slice, int(cDim), nil
 }
 
+// GetFloat16VectorFromPayload returns the float16 vectors, each element takes 2 bytes
+func (r *PayloadReader) GetFloat16VectorFromPayload() ([]byte, int, error) {
+	if r.colType != schemapb.DataType_Float16Vector {
+		return nil, 0, errors.New("incorrect data type")
+	}
+	return r.getHalfFloatVectorFromPayload()
+}
+
+// GetBFloat16VectorFromPayload returns the bfloat16 vectors, each element takes 2 bytes
+func (r *PayloadReader) GetBFloat16VectorFromPayload() ([]byte, int, error) {
+	if r.colType != schemapb.DataType_BFloat16Vector {
+		return nil, 0, errors.New("incorrect data type")
+	}
+	return r.getHalfFloatVectorFromPayload()
+}
+
+// ,dimension, error
+func (r *PayloadReader) getHalfFloatVectorFromPayload() ([]byte, int, error) {
+	var cMsg *C.uint8_t
+	var cDim C.int
+	var cLen C.int
+
+	st := C.GetHalfFloatVectorFromPayload(r.payloadReaderPtr, &cMsg, &cDim, &cLen)
+	errCode := commonpb.ErrorCode(st.error_code)
+	if errCode != commonpb.ErrorCode_Success {
+		msg := C.GoString(st.error_msg)
+		defer C.free(unsafe.Pointer(st.error_msg))
+		return nil, 0, errors.New(msg)
+	}
+	length := cDim * 2 * cLen
+
+	slice := (*[1 << 28]byte)(unsafe.Pointer(cMsg))[:length:length]
+	return slice, int(cDim), nil
+}
+
 func (r *PayloadReader) GetPayloadLengthFromReader() (int, error) {
 	length := C.GetPayloadLengthFromReader(r.payloadReaderPtr)
 	return int(length), nil
@@ -435,6 +435,56 @@ func TestPayload_ReaderandWriter(t *testing.T) {
 		defer r.ReleasePayloadReader()
 	})
 
+	t.Run("TestHalfFloatVector", func(t *testing.T) {
+		for _, dataType := range []schemapb.DataType{schemapb.DataType_Float16Vector, schemapb.DataType_BFloat16Vector} {
+			w, err := NewPayloadWriter(dataType)
+			require.Nil(t, err)
+			require.NotNil(t, w)
+
+			if dataType == schemapb.DataType_Float16Vector {
+				err = w.AddFloat16VectorToPayload([]byte{1, 2, 3, 4}, 2)
+				assert.Nil(t, err)
+				assert.NotNil(t, w.AddBFloat16VectorToPayload([]byte{1, 2, 3, 4}, 2))
+			} else {
+				err = w.AddBFloat16VectorToPayload([]byte{1, 2, 3, 4}, 2)
+				assert.Nil(t, err)
+				assert.NotNil(t, w.AddFloat16VectorToPayload([]byte{1, 2, 3, 4}, 2))
+			}
+			err = w.AddDataToPayload([]byte{5, 6, 7, 8}, 2)
+			assert.Nil(t, err)
+			// the bytes are not whole rows
+			assert.NotNil(t, w.AddDataToPayload([]byte{1, 2, 3}, 2))
+			err = w.FinishPayloadWriter()
+			assert.Nil(t, err)
+
+			length, err := w.GetPayloadLengthFromWriter()
+			assert.Nil(t, err)
+			assert.Equal(t, 2, length)
+
+			buffer, err := w.GetPayloadBufferFromWriter()
+			assert.Nil(t, err)
+
+			r, err := NewPayloadReader(dataType, buffer)
+			require.Nil(t, err)
+			length, err = r.GetPayloadLengthFromReader()
+			assert.Nil(t, err)
+			assert.Equal(t, 2, length)
+
+			ivecs, dim, err := r.GetDataFromPayload()
+			assert.Nil(t, err)
+			assert.Equal(t, 2, dim)
+			assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8}, ivecs.([]byte))
+			if dataType == schemapb.DataType_Float16Vector {
+				_, _, err = r.GetBFloat16VectorFromPayload()
+			} else {
+				_, _, err = r.GetFloat16VectorFromPayload()
+			}
+			assert.NotNil(t, err)
+			r.ReleasePayloadReader()
+			w.ReleasePayloadWriter()
+		}
+	})
+
 	t.Run("TestAddDataToPayload", func(t *testing.T) {
 		w, err := NewPayloadWriter(schemapb.DataType_Bool)
 		w.colType = 999
@@ -311,6 +311,22 @@ func printPayloadValues(colType schemapb.DataType, reader PayloadReaderInterface
 			}
 			fmt.Println()
 		}
+	case schemapb.DataType_Float16Vector, schemapb.DataType_BFloat16Vector:
+		data, dim, err := reader.GetDataFromPayload()
+		if err != nil {
+			return err
+		}
+		val := data.([]byte)
+		rowSize := dim * 2
+		length := len(val) / rowSize
+		for i := 0; i < length; i++ {
+			fmt.Printf("\t\t%d :", i)
+			for j := 0; j < rowSize; j += 2 {
+				idx := i*rowSize + j
+				fmt.Printf(" %02x%02x", val[idx+1], val[idx])
+			}
+			fmt.Println()
+		}
 	default:
 		return errors.New("undefined data type")
 	}
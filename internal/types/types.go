@@ -116,6 +116,21 @@ type IndexNode interface {
 	// CreateIndex receives request from IndexCoordinator to build an index.
 	// Index building is asynchronous, so when an index building request comes, IndexNode records the task and returns.
 	CreateIndex(ctx context.Context, req *indexpb.CreateIndexRequest) (*commonpb.Status, error)
+	// DryRunCreateIndex validates the build request without building the index, and reports what would happen
+	// and the problems found.
+	DryRunCreateIndex(ctx context.Context, req *indexpb.CreateIndexRequest) (*indexpb.DryRunCreateIndexResponse, error)
+	// Activate switches the standby IndexNode to active, so that it starts to accept the build requests.
+	Activate(ctx context.Context, req *indexpb.ActivateRequest) (*commonpb.Status, error)
+	// CaptureProfile captures a heap or cpu profile of IndexNode, and uploads it to the object storage.
+	CaptureProfile(ctx context.Context, req *indexpb.CaptureProfileRequest) (*indexpb.CaptureProfileResponse, error)
+	// VerifyIndexManifest re-checks the index files of a build against the manifest saved along with them.
+	VerifyIndexManifest(ctx context.Context, req *indexpb.VerifyIndexManifestRequest) (*indexpb.VerifyIndexManifestResponse, error)
+	// RunBuildBenchmark builds an index of the synthetic vectors to measure the build throughput of IndexNode,
+	// the index meta is never touched.
+	RunBuildBenchmark(ctx context.Context, req *indexpb.RunBuildBenchmarkRequest) (*indexpb.RunBuildBenchmarkResponse, error)
+	// RebuildIncompatibleIndexes starts to rebuild the indexes built by IndexNode which can not be loaded by the
+	// current engine, the rebuilds run in background in low priority.
+	RebuildIncompatibleIndexes(ctx context.Context, req *indexpb.RebuildIncompatibleIndexesRequest) (*commonpb.Status, error)
 	// GetMetrics gets the metrics about IndexNode.
 	GetMetrics(ctx context.Context, req *milvuspb.GetMetricsRequest) (*milvuspb.GetMetricsResponse, error)
 }
@@ -0,0 +1,169 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+// Package accesslog provides the grpc server interceptors logging the incoming requests, with the method, the peer,
+// the ids of the request, the status and the duration. The payloads are never logged. The request id carried by
+// the context by requestid.UnaryServerInterceptor is logged as well, which is chained before the access log.
+package accesslog
+
+import (
+	"context"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/requestid"
+)
+
+// Config is the configuration of the access log.
+type Config struct {
+	// Level is the level of the access log of the succeeded requests, the failed ones are logged at warn at least
+	Level zapcore.Level
+	// SampleRates logs 1 of every N succeeded calls of the methods, keyed by the method name without the service,
+	// e.g. "GetComponentStates", the failed calls are always logged, the methods not listed are not sampled
+	SampleRates map[string]int
+}
+
+// Interceptor logs the incoming requests of a grpc server.
+type Interceptor struct {
+	logger      *zap.Logger
+	level       zapcore.Level
+	sampleRates map[string]uint64
+	counters    sync.Map // method name -> *uint64
+}
+
+// NewInterceptor returns the Interceptor writing the access log to @logger.
+func NewInterceptor(logger *zap.Logger, cfg Config) *Interceptor {
+	sampleRates := make(map[string]uint64)
+	for method, rate := range cfg.SampleRates {
+		if rate > 1 {
+			sampleRates[method] = uint64(rate)
+		}
+	}
+	return &Interceptor{
+		logger:      logger,
+		level:       cfg.Level,
+		sampleRates: sampleRates,
+	}
+}
+
+// UnaryServerInterceptor returns the unary interceptor logging each call.
+func (i *Interceptor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		i.log(ctx, info.FullMethod, req, resp, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns the stream interceptor logging each stream when it ends.
+func (i *Interceptor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		i.log(ss.Context(), info.FullMethod, nil, nil, err, time.Since(start))
+		return err
+	}
+}
+
+func (i *Interceptor) log(ctx context.Context, fullMethod string, req interface{}, resp interface{}, err error, duration time.Duration) {
+	code := status.Code(err)
+	respStatus := ResponseStatus(resp)
+	failed := code != codes.OK || (respStatus != nil && respStatus.ErrorCode != commonpb.ErrorCode_Success)
+
+	level := i.level
+	if failed && level < zapcore.WarnLevel {
+		level = zapcore.WarnLevel
+	}
+	if !i.logger.Core().Enabled(level) || !i.sample(path.Base(fullMethod), failed) {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("method", fullMethod),
+		zap.String("peer", peerAddress(ctx)),
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		fields = append(fields, zap.String(requestid.FieldKey, id))
+	}
+	fields = append(fields, RequestFields(req)...)
+	fields = append(fields, zap.String("code", code.String()))
+	if respStatus != nil {
+		fields = append(fields, zap.String("errorCode", respStatus.ErrorCode.String()))
+		if failureCode, _ := errorcode.Parse(respStatus.Reason); failureCode != "" {
+			fields = append(fields, zap.String("failureCode", string(failureCode)))
+		}
+	}
+	fields = append(fields, zap.Duration("duration", duration))
+	if ce := i.logger.Check(level, "grpc access"); ce != nil {
+		ce.Write(fields...)
+	}
+}
+
+// sample returns whether to log the call of @method, the first call of every N is logged, and the failed calls are
+// always logged without being counted.
+func (i *Interceptor) sample(method string, failed bool) bool {
+	rate, ok := i.sampleRates[method]
+	if !ok || failed {
+		return true
+	}
+	counter, _ := i.counters.LoadOrStore(method, new(uint64))
+	return (atomic.AddUint64(counter.(*uint64), 1)-1)%rate == 0
+}
+
+func peerAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
+
+// RequestFields returns the fields of the ids carried by @req, i.e. the build id and the msg id and source id of
+// the base, the other fields of the request are never extracted.
+func RequestFields(req interface{}) []zap.Field {
+	var fields []zap.Field
+	if r, ok := req.(interface{ GetIndexBuildID() int64 }); ok {
+		fields = append(fields, zap.Int64("buildID", r.GetIndexBuildID()))
+	}
+	if r, ok := req.(interface{ GetIndexBuildIDs() []int64 }); ok {
+		fields = append(fields, zap.Int64s("buildIDs", r.GetIndexBuildIDs()))
+	}
+	if r, ok := req.(interface{ GetBase() *commonpb.MsgBase }); ok {
+		if base := r.GetBase(); base != nil {
+			fields = append(fields, zap.Int64("msgID", base.MsgID), zap.Int64("sourceID", base.SourceID))
+		}
+	}
+	return fields
+}
+
+// ResponseStatus returns the status of @resp, which is either a status or carries one, or nil if there is none.
+func ResponseStatus(resp interface{}) *commonpb.Status {
+	switch r := resp.(type) {
+	case *commonpb.Status:
+		return r
+	case interface{ GetStatus() *commonpb.Status }:
+		return r.GetStatus()
+	default:
+		return nil
+	}
+}
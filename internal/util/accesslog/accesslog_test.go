@@ -0,0 +1,184 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package accesslog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/indexpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+	"github.com/milvus-io/milvus/internal/util/errorcode"
+	"github.com/milvus-io/milvus/internal/util/requestid"
+)
+
+const (
+	createIndexMethod = "/milvus.proto.index.IndexNode/CreateIndex"
+	statesMethod      = "/milvus.proto.index.IndexNode/GetComponentStates"
+)
+
+func newObservedInterceptor(cfg Config) (*Interceptor, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return NewInterceptor(zap.New(core), cfg), logs
+}
+
+func callUnary(i *Interceptor, ctx context.Context, method string, req interface{}, resp interface{}, err error) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return resp, err
+	}
+	_, _ = i.UnaryServerInterceptor()(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+}
+
+func TestInterceptor_Unary(t *testing.T) {
+	interceptor, logs := newObservedInterceptor(Config{Level: zapcore.InfoLevel})
+	addr := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 53100}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	req := &indexpb.CreateIndexRequest{
+		IndexBuildID: 101,
+		IndexName:    "secret-index-name",
+		DataPaths:    []string{"insert_log/1/2/3/101/0"},
+	}
+
+	callUnary(interceptor, ctx, createIndexMethod, req, &commonpb.Status{ErrorCode: commonpb.ErrorCode_Success}, nil)
+	entries := logs.TakeAll()
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, createIndexMethod, fields["method"])
+	assert.Equal(t, "10.0.0.1:53100", fields["peer"])
+	assert.Equal(t, int64(101), fields["buildID"])
+	assert.Equal(t, codes.OK.String(), fields["code"])
+	assert.Equal(t, commonpb.ErrorCode_Success.String(), fields["errorCode"])
+	assert.Contains(t, fields, "duration")
+	assert.NotContains(t, fields, requestid.FieldKey)
+	// the payloads are never logged
+	for _, value := range fields {
+		assert.NotContains(t, fmt.Sprint(value), "secret-index-name")
+		assert.NotContains(t, fmt.Sprint(value), "insert_log")
+	}
+
+	// the failures are logged at warn with the codes
+	callUnary(interceptor, ctx, createIndexMethod, req, &commonpb.Status{
+		ErrorCode: commonpb.ErrorCode_UnexpectedError,
+		Reason:    errorcode.Format(errorcode.Busy, "the task queue is full"),
+	}, nil)
+	callUnary(interceptor, ctx, createIndexMethod, req, nil, status.Error(codes.Unavailable, "closing"))
+	entries = logs.TakeAll()
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+	assert.Equal(t, string(errorcode.Busy), entries[0].ContextMap()["failureCode"])
+	assert.NotContains(t, entries[0].ContextMap(), "reason")
+	assert.Equal(t, zapcore.WarnLevel, entries[1].Level)
+	assert.Equal(t, codes.Unavailable.String(), entries[1].ContextMap()["code"])
+
+	// the request id carried by the context
+	callUnary(interceptor, requestid.NewContext(ctx, "r-1"), createIndexMethod, req, &commonpb.Status{}, nil)
+	assert.Equal(t, "r-1", logs.TakeAll()[0].ContextMap()[requestid.FieldKey])
+
+	// the succeeded calls below the level of the logger are dropped
+	core, logs := observer.New(zapcore.WarnLevel)
+	interceptor = NewInterceptor(zap.New(core), Config{Level: zapcore.InfoLevel})
+	callUnary(interceptor, ctx, createIndexMethod, req, &commonpb.Status{ErrorCode: commonpb.ErrorCode_Success}, nil)
+	callUnary(interceptor, ctx, createIndexMethod, req, nil, errors.New("failed"))
+	assert.Equal(t, 1, logs.Len())
+}
+
+func TestInterceptor_Sampling(t *testing.T) {
+	interceptor, logs := newObservedInterceptor(Config{
+		Level:       zapcore.InfoLevel,
+		SampleRates: map[string]int{"GetComponentStates": 10, "CreateIndex": 1},
+	})
+	ctx := context.Background()
+	healthy := &internalpb.ComponentStates{Status: &commonpb.Status{ErrorCode: commonpb.ErrorCode_Success}}
+	for n := 0; n < 25; n++ {
+		callUnary(interceptor, ctx, statesMethod, &internalpb.GetComponentStatesRequest{}, healthy, nil)
+	}
+	// the 1st, 11th and 21st calls
+	assert.Equal(t, 3, logs.FilterField(zap.String("method", statesMethod)).Len())
+
+	// the failures are always logged and not counted
+	unhealthy := &internalpb.ComponentStates{Status: &commonpb.Status{ErrorCode: commonpb.ErrorCode_UnexpectedError}}
+	for n := 0; n < 3; n++ {
+		callUnary(interceptor, ctx, statesMethod, &internalpb.GetComponentStatesRequest{}, unhealthy, nil)
+	}
+	assert.Equal(t, 6, logs.FilterField(zap.String("method", statesMethod)).Len())
+	// the 26th call
+	callUnary(interceptor, ctx, statesMethod, &internalpb.GetComponentStatesRequest{}, healthy, nil)
+	assert.Equal(t, 6, logs.FilterField(zap.String("method", statesMethod)).Len())
+
+	// a rate of 1 logs every call
+	for n := 0; n < 3; n++ {
+		callUnary(interceptor, ctx, createIndexMethod, &indexpb.CreateIndexRequest{}, &commonpb.Status{}, nil)
+	}
+	assert.Equal(t, 3, logs.FilterField(zap.String("method", createIndexMethod)).Len())
+}
+
+type mockServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *mockServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestInterceptor_Stream(t *testing.T) {
+	interceptor, logs := newObservedInterceptor(Config{Level: zapcore.DebugLevel})
+	stream := &mockServerStream{ctx: context.Background()}
+	info := &grpc.StreamServerInfo{FullMethod: "/milvus.proto.index.IndexNode/Watch"}
+	err := interceptor.StreamServerInterceptor()(nil, stream, info, func(srv interface{}, stream grpc.ServerStream) error {
+		return status.Error(codes.Canceled, "cancelled")
+	})
+	assert.NotNil(t, err)
+	entries := logs.TakeAll()
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+	assert.Equal(t, codes.Canceled.String(), entries[0].ContextMap()["code"])
+	assert.Equal(t, "", entries[0].ContextMap()["peer"])
+}
+
+func TestRequestFields(t *testing.T) {
+	fields := RequestFields(&indexpb.CreateIndexRequest{IndexBuildID: 7})
+	assert.Equal(t, []zap.Field{zap.Int64("buildID", 7)}, fields)
+
+	fields = RequestFields(&indexpb.GetIndexStatesRequest{IndexBuildIDs: []int64{1, 2}})
+	assert.Equal(t, []zap.Field{zap.Int64s("buildIDs", []int64{1, 2})}, fields)
+
+	fields = RequestFields(&indexpb.CaptureProfileRequest{Base: &commonpb.MsgBase{MsgID: 3, SourceID: 4}, ProfileType: "heap"})
+	assert.Equal(t, []zap.Field{zap.Int64("msgID", 3), zap.Int64("sourceID", 4)}, fields)
+
+	assert.Empty(t, RequestFields(&indexpb.CaptureProfileRequest{}))
+	assert.Empty(t, RequestFields(nil))
+	assert.Empty(t, RequestFields("payload"))
+}
+
+func TestResponseStatus(t *testing.T) {
+	s := &commonpb.Status{ErrorCode: commonpb.ErrorCode_Success}
+	assert.Equal(t, s, ResponseStatus(s))
+	assert.Equal(t, s, ResponseStatus(&internalpb.ComponentStates{Status: s}))
+	assert.Nil(t, ResponseStatus(&internalpb.ComponentStates{}))
+	assert.Nil(t, ResponseStatus(nil))
+	assert.Nil(t, ResponseStatus("response"))
+}
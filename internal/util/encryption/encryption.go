@@ -0,0 +1,395 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+// Package encryption encrypts the files on the client side before they are uploaded to the object storage. The
+// files are encrypted by a random data key with AES-256-GCM in segments, so that a file of any size is encrypted and
+// decrypted as a stream, and the data key is wrapped by a key-encryption key (KEK) of the keyring. The keyring wraps
+// the data keys with the newest KEK and unwraps them with any of its KEKs, which rotates the KEKs without
+// re-encrypting the files.
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Algorithm is the algorithm of the encrypted files, which is recorded along with the wrapped data key.
+const Algorithm = "AES-256-GCM-STREAM"
+
+const (
+	// KeySize is the size of the KEKs and the data keys
+	KeySize = 32
+	// SegmentSize is the size of the plaintext of a segment, each segment is sealed on its own
+	SegmentSize = 64 << 10
+
+	magic         = "MVEF"
+	formatVersion = 1
+	noncePrefix   = 7
+	// headerSize is the size of the header of the encrypted files: magic, version, segment size and nonce prefix
+	headerSize = len(magic) + 1 + 4 + noncePrefix
+	tagSize    = 16
+	nonceSize  = 12
+	// maxSegments is where the counter of the segments in the nonce overflows
+	maxSegments = 1 << 32
+)
+
+// ErrDecrypt is returned if the data is not encrypted by the key, or it's corrupted or truncated.
+var ErrDecrypt = errors.New("failed to decrypt, the data is corrupted or not encrypted by the key")
+
+// KEK is a key-encryption key, which is never printed.
+type KEK struct {
+	ID  string
+	Key []byte
+}
+
+// String returns the id of the KEK only.
+func (k KEK) String() string {
+	return k.ID
+}
+
+// GoString returns the id of the KEK only for %#v.
+func (k KEK) GoString() string {
+	return fmt.Sprintf("KEK(%q)", k.ID)
+}
+
+// ParseKeys parses the KEKs in the form of "id:base64,...", separated by commas or new lines, the last one is the
+// newest.
+func ParseKeys(value string) ([]KEK, error) {
+	keks := make([]KEK, 0)
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, errors.New("invalid key, expect id:base64")
+		}
+		id := strings.TrimSpace(parts[0])
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %w", id, err)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("invalid key %s of %d bytes, expect %d bytes", id, len(key), KeySize)
+		}
+		keks = append(keks, KEK{ID: id, Key: key})
+	}
+	return keks, nil
+}
+
+// LoadKeys returns the KEKs of @keys, or the ones read from @file if @keys is empty, see ParseKeys.
+func LoadKeys(keys, file string) ([]KEK, error) {
+	keys, file = strings.TrimSpace(keys), strings.TrimSpace(file)
+	if file == "" {
+		return ParseKeys(keys)
+	}
+	if keys != "" {
+		return nil, errors.New("both the keys and the keys file are set")
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the keys file %s: %w", file, err)
+	}
+	return ParseKeys(string(content))
+}
+
+// Keyring wraps the data keys with the newest KEK, and unwraps them with any of the KEKs.
+type Keyring struct {
+	keys   map[string]cipher.AEAD
+	active string
+}
+
+// NewKeyring returns the keyring of @keks, the last one is the newest.
+func NewKeyring(keks []KEK) (*Keyring, error) {
+	if len(keks) == 0 {
+		return nil, errors.New("no key-encryption key")
+	}
+	k := &Keyring{keys: make(map[string]cipher.AEAD, len(keks))}
+	for _, kek := range keks {
+		if _, ok := k.keys[kek.ID]; ok {
+			return nil, fmt.Errorf("duplicated key %s", kek.ID)
+		}
+		aead, err := newAEAD(kek.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %w", kek.ID, err)
+		}
+		k.keys[kek.ID] = aead
+		k.active = kek.ID
+	}
+	return k, nil
+}
+
+// ActiveKeyID returns the id of the KEK wrapping the new data keys.
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// DataKey is the key encrypting the files, along with the KEK wrapping it.
+type DataKey struct {
+	Key        []byte
+	KeyID      string
+	WrappedKey []byte
+}
+
+// NewDataKey returns a random data key wrapped by the newest KEK.
+func (k *Keyring) NewDataKey() (*DataKey, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &DataKey{
+		Key:        key,
+		KeyID:      k.active,
+		WrappedKey: k.keys[k.active].Seal(nonce, nonce, key, []byte(k.active)),
+	}, nil
+}
+
+// UnwrapDataKey returns the data key wrapped by the KEK of @keyID.
+func (k *Keyring) UnwrapDataKey(keyID string, wrappedKey []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key-encryption key %s", keyID)
+	}
+	if len(wrappedKey) < nonceSize {
+		return nil, ErrDecrypt
+	}
+	key, err := aead.Open(nil, wrappedKey[:nonceSize], wrappedKey[nonceSize:], []byte(keyID))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid key of %d bytes, expect %d bytes", len(key), KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptedSize returns the size of the encrypted file of @plainSize bytes.
+func EncryptedSize(plainSize int64) int64 {
+	segments := (plainSize + SegmentSize - 1) / SegmentSize
+	if segments == 0 {
+		segments = 1
+	}
+	return int64(headerSize) + plainSize + segments*tagSize
+}
+
+// segmentNonce returns the nonce of the segment, the last segment is marked so that the truncation is detected.
+func segmentNonce(prefix []byte, counter uint64, last bool) []byte {
+	nonce := make([]byte, nonceSize)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefix:], uint32(counter))
+	if last {
+		nonce[nonceSize-1] = 1
+	}
+	return nonce
+}
+
+type encryptReader struct {
+	aead    cipher.AEAD
+	src     io.Reader
+	header  []byte
+	counter uint64
+	// plain is the next segment along with a byte ahead, which tells whether the segment is the last one
+	plain   []byte
+	pending int
+	sealed  []byte
+	out     []byte
+	eof     bool
+	done    bool
+}
+
+// NewEncryptReader returns the reader of the encrypted @plaintext by @key.
+func NewEncryptReader(key []byte, plaintext io.Reader) (io.Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	header[len(magic)] = formatVersion
+	binary.BigEndian.PutUint32(header[len(magic)+1:], SegmentSize)
+	if _, err := rand.Read(header[headerSize-noncePrefix:]); err != nil {
+		return nil, err
+	}
+	return &encryptReader{
+		aead:   aead,
+		src:    plaintext,
+		header: header,
+		plain:  make([]byte, SegmentSize+1),
+		sealed: make([]byte, 0, SegmentSize+tagSize),
+		out:    header,
+	}, nil
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.seal(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *encryptReader) seal() error {
+	if !r.eof {
+		n, err := io.ReadFull(r.src, r.plain[r.pending:])
+		r.pending += n
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			r.eof = true
+		} else if err != nil {
+			return err
+		}
+	}
+	if r.counter >= maxSegments {
+		return errors.New("the file is too large to encrypt")
+	}
+	size, last := r.pending, r.eof
+	if size > SegmentSize {
+		size = SegmentSize
+	}
+	nonce := segmentNonce(r.header[headerSize-noncePrefix:], r.counter, last)
+	r.out = r.aead.Seal(r.sealed[:0], nonce, r.plain[:size], r.header)
+	r.pending = copy(r.plain, r.plain[size:r.pending])
+	r.counter++
+	r.done = last
+	return nil
+}
+
+type decryptReader struct {
+	aead    cipher.AEAD
+	src     io.Reader
+	header  []byte
+	counter uint64
+	// sealed is the next segment along with a byte ahead, which tells whether the segment is the last one
+	sealed  []byte
+	pending int
+	plain   []byte
+	out     []byte
+	eof     bool
+	done    bool
+}
+
+// NewDecryptReader returns the reader of the plaintext of @ciphertext encrypted by @key, the reader fails with
+// ErrDecrypt once a segment fails to authenticate, the plaintext read so far is authenticated.
+func NewDecryptReader(key []byte, ciphertext io.Reader) (io.Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(ciphertext, header); err != nil {
+		return nil, ErrDecrypt
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, errors.New("the data is not encrypted")
+	}
+	if header[len(magic)] != formatVersion {
+		return nil, fmt.Errorf("unsupported version %d of the encrypted data", header[len(magic)])
+	}
+	if segmentSize := binary.BigEndian.Uint32(header[len(magic)+1:]); segmentSize != SegmentSize {
+		return nil, fmt.Errorf("unsupported segment size %d of the encrypted data", segmentSize)
+	}
+	return &decryptReader{
+		aead:   aead,
+		src:    ciphertext,
+		header: header,
+		sealed: make([]byte, SegmentSize+tagSize+1),
+		plain:  make([]byte, 0, SegmentSize),
+	}, nil
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *decryptReader) open() error {
+	if !r.eof {
+		n, err := io.ReadFull(r.src, r.sealed[r.pending:])
+		r.pending += n
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			r.eof = true
+		} else if err != nil {
+			return err
+		}
+	}
+	size, last := r.pending, r.eof
+	if size > SegmentSize+tagSize {
+		size = SegmentSize + tagSize
+	}
+	nonce := segmentNonce(r.header[headerSize-noncePrefix:], r.counter, last)
+	plain, err := r.aead.Open(r.plain[:0], nonce, r.sealed[:size], r.header)
+	if err != nil {
+		return ErrDecrypt
+	}
+	r.out = plain
+	r.pending = copy(r.sealed, r.sealed[size:r.pending])
+	r.counter++
+	r.done = last
+	return nil
+}
+
+// Encrypt returns @plaintext encrypted by @key.
+func Encrypt(key []byte, plaintext []byte) ([]byte, error) {
+	reader, err := NewEncryptReader(key, bytes.NewReader(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := bytes.NewBuffer(make([]byte, 0, EncryptedSize(int64(len(plaintext)))))
+	if _, err := io.Copy(ciphertext, reader); err != nil {
+		return nil, err
+	}
+	return ciphertext.Bytes(), nil
+}
+
+// Decrypt returns the plaintext of @ciphertext encrypted by @key.
+func Decrypt(key []byte, ciphertext []byte) ([]byte, error) {
+	reader, err := NewDecryptReader(key, bytes.NewReader(ciphertext))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(reader)
+}
@@ -0,0 +1,171 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newKey(t *testing.T) []byte {
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	assert.Nil(t, err)
+	return key
+}
+
+func TestEncrypt(t *testing.T) {
+	key := newKey(t)
+	for _, size := range []int{0, 1, SegmentSize - 1, SegmentSize, SegmentSize + 1, 3*SegmentSize + 5} {
+		plaintext := make([]byte, size)
+		_, err := rand.Read(plaintext)
+		assert.Nil(t, err)
+
+		ciphertext, err := Encrypt(key, plaintext)
+		assert.Nil(t, err)
+		assert.Equal(t, EncryptedSize(int64(size)), int64(len(ciphertext)), size)
+		if size > 0 {
+			assert.False(t, bytes.Contains(ciphertext, plaintext), size)
+		}
+		decrypted, err := Decrypt(key, ciphertext)
+		assert.Nil(t, err, size)
+		assert.True(t, bytes.Equal(plaintext, decrypted), size)
+
+		// the streams are read in any sizes
+		reader, err := NewEncryptReader(key, iotest.OneByteReader(bytes.NewReader(plaintext)))
+		assert.Nil(t, err)
+		streamed, err := ioutil.ReadAll(iotest.HalfReader(reader))
+		assert.Nil(t, err)
+		assert.Equal(t, len(ciphertext), len(streamed))
+		reader, err = NewDecryptReader(key, iotest.OneByteReader(bytes.NewReader(streamed)))
+		assert.Nil(t, err)
+		decrypted, err = ioutil.ReadAll(reader)
+		assert.Nil(t, err)
+		assert.True(t, bytes.Equal(plaintext, decrypted), size)
+
+		// the same plaintext is encrypted differently
+		again, err := Encrypt(key, plaintext)
+		assert.Nil(t, err)
+		assert.NotEqual(t, ciphertext, again)
+	}
+}
+
+func TestDecrypt_errors(t *testing.T) {
+	key := newKey(t)
+	plaintext := make([]byte, 2*SegmentSize+10)
+	ciphertext, err := Encrypt(key, plaintext)
+	assert.Nil(t, err)
+
+	_, err = Decrypt(newKey(t), ciphertext)
+	assert.Equal(t, ErrDecrypt, err)
+
+	tampered := append([]byte{}, ciphertext...)
+	tampered[len(tampered)/2] ^= 1
+	_, err = Decrypt(key, tampered)
+	assert.Equal(t, ErrDecrypt, err)
+
+	// the truncation at the boundary of the segments is detected as well
+	segment := SegmentSize + tagSize
+	for _, size := range []int{headerSize, headerSize + segment, headerSize + 2*segment, len(ciphertext) - 1} {
+		_, err = Decrypt(key, ciphertext[:size])
+		assert.Equal(t, ErrDecrypt, err, size)
+	}
+	_, err = Decrypt(key, ciphertext[:headerSize-1])
+	assert.Equal(t, ErrDecrypt, err)
+
+	_, err = Decrypt(key, plaintext)
+	assert.NotNil(t, err)
+	unsupported := append([]byte{}, ciphertext...)
+	unsupported[len(magic)] = formatVersion + 1
+	_, err = Decrypt(key, unsupported)
+	assert.NotNil(t, err)
+
+	_, err = Encrypt(key[:16], plaintext)
+	assert.NotNil(t, err)
+}
+
+func TestKeyring(t *testing.T) {
+	old, current := KEK{ID: "old", Key: newKey(t)}, KEK{ID: "current", Key: newKey(t)}
+	keyring, err := NewKeyring([]KEK{old})
+	assert.Nil(t, err)
+	oldDataKey, err := keyring.NewDataKey()
+	assert.Nil(t, err)
+	assert.Equal(t, "old", oldDataKey.KeyID)
+
+	// the data keys are wrapped by the newest key, and unwrapped by all the keys after the rotation
+	keyring, err = NewKeyring([]KEK{old, current})
+	assert.Nil(t, err)
+	assert.Equal(t, "current", keyring.ActiveKeyID())
+	dataKey, err := keyring.NewDataKey()
+	assert.Nil(t, err)
+	assert.Equal(t, "current", dataKey.KeyID)
+	assert.Equal(t, KeySize, len(dataKey.Key))
+	for _, k := range []*DataKey{oldDataKey, dataKey} {
+		key, err := keyring.UnwrapDataKey(k.KeyID, k.WrappedKey)
+		assert.Nil(t, err)
+		assert.Equal(t, k.Key, key)
+	}
+
+	_, err = keyring.UnwrapDataKey("old", dataKey.WrappedKey)
+	assert.Equal(t, ErrDecrypt, err)
+	_, err = keyring.UnwrapDataKey("retired", dataKey.WrappedKey)
+	assert.NotNil(t, err)
+	_, err = keyring.UnwrapDataKey("current", dataKey.WrappedKey[:8])
+	assert.Equal(t, ErrDecrypt, err)
+
+	_, err = NewKeyring(nil)
+	assert.NotNil(t, err)
+	_, err = NewKeyring([]KEK{old, old})
+	assert.NotNil(t, err)
+	_, err = NewKeyring([]KEK{{ID: "short", Key: make([]byte, 16)}})
+	assert.NotNil(t, err)
+}
+
+func TestLoadKeys(t *testing.T) {
+	key1, key2 := newKey(t), newKey(t)
+	value := fmt.Sprintf("k1:%s, k2:%s", base64.StdEncoding.EncodeToString(key1), base64.StdEncoding.EncodeToString(key2))
+	keks, err := LoadKeys(value, "")
+	assert.Nil(t, err)
+	assert.Equal(t, []KEK{{ID: "k1", Key: key1}, {ID: "k2", Key: key2}}, keks)
+	// the keys are never printed
+	assert.Equal(t, "[k1 k2]", fmt.Sprint(keks))
+	assert.NotContains(t, fmt.Sprintf("%#v", keks[0]), base64.StdEncoding.EncodeToString(key1))
+
+	file := filepath.Join(t.TempDir(), "keys")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(fmt.Sprintf("k1:%s\nk2:%s\n",
+		base64.StdEncoding.EncodeToString(key1), base64.StdEncoding.EncodeToString(key2))), 0600))
+	fileKeks, err := LoadKeys("", file)
+	assert.Nil(t, err)
+	assert.Equal(t, keks, fileKeks)
+
+	keks, err = LoadKeys("", "")
+	assert.Nil(t, err)
+	assert.Empty(t, keks)
+
+	for _, invalid := range []string{"k1", ":" + base64.StdEncoding.EncodeToString(key1), "k1:not base64", "k1:" +
+		base64.StdEncoding.EncodeToString(key1[:16])} {
+		_, err := LoadKeys(invalid, "")
+		assert.NotNil(t, err, invalid)
+	}
+	_, err = LoadKeys(value, file)
+	assert.NotNil(t, err)
+	_, err = LoadKeys("", filepath.Join(t.TempDir(), "missing"))
+	assert.NotNil(t, err)
+}
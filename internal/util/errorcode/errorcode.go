@@ -0,0 +1,131 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+// Package errorcode defines the machine readable codes of the failures of IndexNode. A code is attached to the
+// reason of a status in the stable format "[CODE] message", so that the receivers can classify the failures
+// without matching the human messages.
+package errorcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Code is the machine readable code of a failure.
+type Code string
+
+// the codes of the failures of IndexNode, the values are stable and must not be changed
+const (
+	// StorageAuth means the object storage rejects the credentials of IndexNode
+	StorageAuth Code = "STORAGE_AUTH"
+	// StorageTransient means the object storage fails temporarily, the request may succeed if retried
+	StorageTransient Code = "STORAGE_TRANSIENT"
+	// EtcdUnavailable means etcd can not be reached or fails to serve the request
+	EtcdUnavailable Code = "ETCD_UNAVAILABLE"
+	// InvalidParams means the request or the data it refers to is invalid, retrying does not help
+	InvalidParams Code = "INVALID_PARAMS"
+	// OOM means IndexNode runs out of memory
+	OOM Code = "OOM"
+	// Timeout means the request exceeds its deadline
+	Timeout Code = "TIMEOUT"
+	// Cancelled means the request is cancelled
+	Cancelled Code = "CANCELLED"
+	// CapabilityMismatch means IndexNode lacks the capabilities required by the request
+	CapabilityMismatch Code = "CAPABILITY_MISMATCH"
+	// Busy means IndexNode can not take more tasks for now, the request should be retried later or elsewhere
+	Busy Code = "BUSY"
+	// NotReady means IndexNode is starting, unhealthy or standby
+	NotReady Code = "NOT_READY"
+	// Unsupported means the request asks for what IndexNode does not support for it, e.g. appending to the index
+	// of a type without append support, retrying does not help
+	Unsupported Code = "UNSUPPORTED"
+	// Internal is the code of the failures not classified by the other codes
+	Internal Code = "INTERNAL"
+)
+
+// Format returns the reason of @msg with @code attached.
+func Format(code Code, msg string) string {
+	return "[" + string(code) + "] " + msg
+}
+
+// Parse returns the code and the message of the reason returned by Format, the code is empty if the reason has
+// no code attached, and the message is the whole reason then.
+func Parse(reason string) (Code, string) {
+	if !strings.HasPrefix(reason, "[") {
+		return "", reason
+	}
+	end := strings.Index(reason, "] ")
+	if end < 0 {
+		return "", reason
+	}
+	code := reason[1:end]
+	if !validCode(code) {
+		return "", reason
+	}
+	return Code(code), reason[end+2:]
+}
+
+// validCode returns whether @code is made of the upper case letters and underscores, the receivers accept the
+// codes unknown to them, so that new codes can be added.
+func validCode(code string) bool {
+	if code == "" {
+		return false
+	}
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// Error is an error with the code of the failure, the code is not a part of the message.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New returns an error of @msg with @code.
+func New(code Code, msg string) error {
+	return &Error{Code: code, Err: errors.New(msg)}
+}
+
+// Errorf returns an error with @code, formatted as fmt.Errorf.
+func Errorf(code Code, format string, args ...interface{}) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap attaches @code to @err, the code of @err is kept if it has been attached a code, since the code attached
+// closer to the failure is more specific.
+func Wrap(code Code, err error) error {
+	if err == nil || CodeOf(err) != "" {
+		return err
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// CodeOf returns the code attached to @err, or empty if there is none.
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
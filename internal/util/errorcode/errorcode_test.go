@@ -0,0 +1,59 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package errorcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatAndParse(t *testing.T) {
+	reason := Format(StorageAuth, "access denied")
+	assert.Equal(t, "[STORAGE_AUTH] access denied", reason)
+	code, msg := Parse(reason)
+	assert.Equal(t, StorageAuth, code)
+	assert.Equal(t, "access denied", msg)
+
+	// the codes unknown to the receiver are parsed as well
+	code, msg = Parse("[NEW_CODE] something new")
+	assert.Equal(t, Code("NEW_CODE"), code)
+	assert.Equal(t, "something new", msg)
+
+	for _, reason := range []string{"", "free-form reason", "[not a code] reason", "[] reason", "[BUSY]no space"} {
+		code, msg := Parse(reason)
+		assert.Equal(t, Code(""), code, reason)
+		assert.Equal(t, reason, msg)
+	}
+}
+
+func TestError(t *testing.T) {
+	base := errors.New("connection reset")
+	err := Wrap(StorageTransient, base)
+	assert.Equal(t, "connection reset", err.Error())
+	assert.Equal(t, StorageTransient, CodeOf(err))
+	assert.True(t, errors.Is(err, base))
+
+	// the code attached closer to the failure is kept
+	wrapped := fmt.Errorf("failed to load the binlog: %w", err)
+	assert.Equal(t, StorageTransient, CodeOf(Wrap(Internal, wrapped)))
+
+	assert.Nil(t, Wrap(Internal, nil))
+	assert.Equal(t, Code(""), CodeOf(base))
+	assert.Equal(t, Code(""), CodeOf(nil))
+	assert.Equal(t, Busy, CodeOf(New(Busy, "queue is full")))
+	err = Errorf(InvalidParams, "invalid dim %d", -1)
+	assert.Equal(t, InvalidParams, CodeOf(err))
+	assert.Equal(t, "invalid dim -1", err.Error())
+}
@@ -20,6 +20,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/milvus-io/milvus/internal/log"
@@ -69,6 +70,22 @@ func GetLocalIP() string {
 	return ipv4.LocalIP()
 }
 
+// unixSocketScheme is the scheme of the addresses of the unix sockets, which grpc dials natively.
+const unixSocketScheme = "unix:"
+
+// UnixSocketAddress returns the address of the unix socket of @path to dial by grpc, e.g. unix:///tmp/indexnode.sock.
+func UnixSocketAddress(path string) string {
+	if strings.HasPrefix(path, "/") {
+		return unixSocketScheme + "//" + path
+	}
+	return unixSocketScheme + path
+}
+
+// IsUnixSocketAddress returns whether @address is the address of a unix socket instead of host:port.
+func IsUnixSocketAddress(address string) bool {
+	return strings.HasPrefix(address, unixSocketScheme)
+}
+
 // WaitForComponentStates wait for component's state to be one of the specific states
 func WaitForComponentStates(ctx context.Context, service types.Component, serviceName string, states []internalpb.StateCode, attempts uint, sleep time.Duration) error {
 	checkFunc := func() error {
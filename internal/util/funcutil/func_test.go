@@ -114,6 +114,15 @@ func Test_GetLocalIP(t *testing.T) {
 	assert.NotZero(t, len(ip))
 }
 
+func Test_UnixSocketAddress(t *testing.T) {
+	assert.Equal(t, "unix:///tmp/indexnode.sock", UnixSocketAddress("/tmp/indexnode.sock"))
+	assert.Equal(t, "unix:indexnode.sock", UnixSocketAddress("indexnode.sock"))
+	assert.True(t, IsUnixSocketAddress(UnixSocketAddress("/tmp/indexnode.sock")))
+	assert.True(t, IsUnixSocketAddress(UnixSocketAddress("indexnode.sock")))
+	assert.False(t, IsUnixSocketAddress("127.0.0.1:21121"))
+	assert.False(t, IsUnixSocketAddress("localhost:21121"))
+}
+
 func Test_WaitForComponentInitOrHealthy(t *testing.T) {
 	mc := &MockComponent{
 		compState: nil,
@@ -0,0 +1,142 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+// Package grpcretry retries the failed unary calls of the grpc clients with the exponential backoff, e.g. while the
+// server restarts. Only the methods declared idempotent are retried, since a call failed with Unavailable may have
+// been served before the connection broke, and the retries never outlive the deadline of the caller.
+package grpcretry
+
+import (
+	"context"
+	"math/rand"
+	"path"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/milvus-io/milvus/internal/log"
+)
+
+const (
+	// DefaultMaxRetry is the default number of the retries after the first attempt of a call.
+	DefaultMaxRetry = 5
+
+	// DefaultBackoff is the default backoff before the first retry, which doubles per retry.
+	DefaultBackoff = 200 * time.Millisecond
+
+	// DefaultMaxBackoff is the default longest backoff between the retries.
+	DefaultMaxBackoff = 3 * time.Second
+)
+
+// Policy is the retry policy of a method.
+type Policy struct {
+	// Idempotent is whether the method is safe to call again, the other methods are never retried.
+	Idempotent bool
+	// MaxRetry overrides Config.MaxRetry for the method if it's not nil, 0 disables the retries.
+	MaxRetry *uint
+}
+
+// Config configures the retries of the calls.
+type Config struct {
+	// MaxRetry is the number of the retries after the first attempt, 0 disables the retries.
+	MaxRetry uint
+	// Backoff is the backoff before the first retry, which doubles per retry up to MaxBackoff. The backoffs are
+	// jittered by up to a half, so that the clients retrying together spread out.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Policies is the policies of the methods by the name, e.g. GetIndexStates, the methods missing are not retried.
+	Policies map[string]Policy
+}
+
+// Budget returns the retry policy of @maxRetry retries of an idempotent method.
+func Budget(maxRetry uint) Policy {
+	return Policy{Idempotent: true, MaxRetry: &maxRetry}
+}
+
+// retryable returns whether a call failed with @err may succeed if it's called again.
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Aborted, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// maxRetry returns the number of the retries of @method.
+func (c *Config) maxRetry(method string) uint {
+	policy, ok := c.Policies[path.Base(method)]
+	if !ok || !policy.Idempotent {
+		return 0
+	}
+	if policy.MaxRetry != nil {
+		return *policy.MaxRetry
+	}
+	return c.MaxRetry
+}
+
+// backoff returns the jittered backoff before the retry @n, starting from 0.
+func (c *Config) backoff(n uint) time.Duration {
+	maxBackoff := c.MaxBackoff
+	if maxBackoff < c.Backoff {
+		maxBackoff = c.Backoff
+	}
+	backoff := c.Backoff
+	for i := uint(0); i < n && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	if backoff <= 1 {
+		return backoff
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)))
+}
+
+// sleep waits for @backoff, it returns false without waiting if @ctx is done before the backoff elapses.
+func sleep(ctx context.Context, backoff time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+		return false
+	}
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// UnaryClientInterceptor returns the interceptor retrying the unary calls failed with Unavailable, Aborted or
+// ResourceExhausted according to @cfg. The error of the last attempt is returned once the retries run out, or the
+// deadline of the caller is too close to wait for the next attempt.
+func UnaryClientInterceptor(cfg Config) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		maxRetry := cfg.maxRetry(method)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		for n := uint(0); n < maxRetry && err != nil && retryable(err); n++ {
+			backoff := cfg.backoff(n)
+			if !sleep(ctx, backoff) {
+				break
+			}
+			log.Debug("grpc client retries the call", zap.String("method", method), zap.Uint("retry", n+1),
+				zap.Duration("backoff", backoff), zap.Error(err))
+			err = invoker(ctx, method, req, reply, cc, opts...)
+		}
+		return err
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package grpcretry
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// flakyServer fails the first failures calls with code, and succeeds then.
+type flakyServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	failures int32
+	code     codes.Code
+	calls    int32
+}
+
+func (s *flakyServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if atomic.AddInt32(&s.calls, 1) <= s.failures {
+		return nil, status.Error(s.code, "flaky")
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+// check calls the flaky server failing @failures times with @code through the interceptor of @cfg, it returns the
+// number of the calls served and the error of the call.
+func check(t *testing.T, ctx context.Context, cfg Config, failures int32, code codes.Code) (int32, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	server := grpc.NewServer()
+	flaky := &flakyServer{failures: failures, code: code}
+	grpc_health_v1.RegisterHealthServer(server, flaky)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	defer server.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure(), grpc.WithUnaryInterceptor(UnaryClientInterceptor(cfg)))
+	assert.Nil(t, err)
+	defer conn.Close()
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	return atomic.LoadInt32(&flaky.calls), err
+}
+
+func newConfig(policy Policy) Config {
+	return Config{
+		MaxRetry:   3,
+		Backoff:    time.Millisecond,
+		MaxBackoff: 4 * time.Millisecond,
+		Policies:   map[string]Policy{"Check": policy},
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	ctx := context.Background()
+	idempotent := Policy{Idempotent: true}
+
+	calls, err := check(t, ctx, newConfig(idempotent), 3, codes.Unavailable)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(4), calls)
+	calls, err = check(t, ctx, newConfig(idempotent), 2, codes.Aborted)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), calls)
+
+	// the retries run out
+	calls, err = check(t, ctx, newConfig(idempotent), 4, codes.Unavailable)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(4), calls)
+
+	// the budget of the method overrides the one of the config
+	calls, err = check(t, ctx, newConfig(Budget(1)), 2, codes.Unavailable)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(2), calls)
+	calls, err = check(t, ctx, newConfig(Budget(5)), 5, codes.ResourceExhausted)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(6), calls)
+
+	// the non-idempotent methods, the ones without a policy and the errors of the requests are not retried
+	calls, err = check(t, ctx, newConfig(Policy{MaxRetry: Budget(3).MaxRetry}), 1, codes.Unavailable)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(1), calls)
+	calls, err = check(t, ctx, Config{MaxRetry: 3, Backoff: time.Millisecond}, 1, codes.Unavailable)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(1), calls)
+	calls, err = check(t, ctx, newConfig(idempotent), 1, codes.InvalidArgument)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestUnaryClientInterceptor_deadline(t *testing.T) {
+	cfg := newConfig(Policy{Idempotent: true})
+	cfg.Backoff, cfg.MaxBackoff = time.Minute, time.Minute
+
+	// the call returns the error at once if the deadline is too close to wait for the backoff
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	calls, err := check(t, ctx, cfg, 1, codes.Unavailable)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(1), calls)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	// the backoff is interrupted once the caller cancels
+	cfg.Backoff, cfg.MaxBackoff = 200*time.Millisecond, 200*time.Millisecond
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	start = time.Now()
+	calls, err = check(t, ctx, cfg, 1, codes.Unavailable)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(1), calls)
+	assert.Less(t, int64(time.Since(start)), int64(150*time.Millisecond))
+}
+
+func TestConfig_backoff(t *testing.T) {
+	cfg := Config{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for n, expected := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		expected *= time.Millisecond
+		for i := 0; i < 10; i++ {
+			backoff := cfg.backoff(uint(n))
+			assert.True(t, backoff >= expected/2 && backoff < expected, backoff)
+		}
+	}
+	// the longest backoff is never shorter than the initial one
+	cfg.MaxBackoff = 0
+	assert.True(t, cfg.backoff(3) < cfg.Backoff)
+	cfg.Backoff = 0
+	assert.Equal(t, time.Duration(0), cfg.backoff(3))
+}
@@ -14,4 +14,5 @@ package healthz
 const (
 	ContentTypeHeader = "Content-Type"
 	ContentTypeText   = "text/plain"
+	ContentTypeJSON   = "application/json"
 )
@@ -0,0 +1,111 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexparamcheck
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/milvus-io/milvus/internal/util/funcutil"
+)
+
+type VectorType = string
+
+const (
+	FloatVector  VectorType = "FloatVector"
+	BinaryVector VectorType = "BinaryVector"
+)
+
+// MetricCompatibility is the metric types supported by an index type on a vector type.
+type MetricCompatibility struct {
+	IndexType   IndexType
+	VectorType  VectorType
+	MetricTypes []string
+}
+
+// metricCompatibilities is the table of the supported combinations, a new index type is supported by adding its rows.
+var metricCompatibilities = []MetricCompatibility{
+	{IndexType: IndexFaissIDMap, VectorType: FloatVector, MetricTypes: METRICS},
+	{IndexType: IndexFaissIvfFlat, VectorType: FloatVector, MetricTypes: METRICS},
+	{IndexType: IndexFaissIvfPQ, VectorType: FloatVector, MetricTypes: METRICS},
+	{IndexType: IndexFaissIvfSQ8, VectorType: FloatVector, MetricTypes: METRICS},
+	{IndexType: IndexFaissIvfSQ8H, VectorType: FloatVector, MetricTypes: METRICS},
+	{IndexType: IndexNSG, VectorType: FloatVector, MetricTypes: METRICS},
+	{IndexType: IndexHNSW, VectorType: FloatVector, MetricTypes: METRICS},
+	{IndexType: IndexRHNSWFlat, VectorType: FloatVector, MetricTypes: METRICS},
+	{IndexType: IndexRHNSWPQ, VectorType: FloatVector, MetricTypes: METRICS},
+	{IndexType: IndexRHNSWSQ, VectorType: FloatVector, MetricTypes: METRICS},
+	{IndexType: IndexANNOY, VectorType: FloatVector, MetricTypes: METRICS},
+	{IndexType: IndexNGTPANNG, VectorType: FloatVector, MetricTypes: METRICS},
+	{IndexType: IndexNGTONNG, VectorType: FloatVector, MetricTypes: METRICS},
+	{IndexType: IndexFaissBinIDMap, VectorType: BinaryVector, MetricTypes: BinIDMapMetrics},
+	{IndexType: IndexFaissBinIvfFlat, VectorType: BinaryVector, MetricTypes: BinIvfMetrics},
+}
+
+// MetricCompatibilities returns the table of the metric types supported by each index type on each vector type.
+func MetricCompatibilities() []MetricCompatibility {
+	table := make([]MetricCompatibility, 0, len(metricCompatibilities))
+	for _, c := range metricCompatibilities {
+		table = append(table, MetricCompatibility{
+			IndexType:   c.IndexType,
+			VectorType:  c.VectorType,
+			MetricTypes: append([]string(nil), c.MetricTypes...),
+		})
+	}
+	return table
+}
+
+// AllowedMetricTypes returns the metric types supported by @indexType on @vectorType, the vector types the index type
+// supports are all taken if @vectorType is empty.
+func AllowedMetricTypes(indexType IndexType, vectorType VectorType) []string {
+	var allowed []string
+	for _, c := range metricCompatibilities {
+		if !strings.EqualFold(c.IndexType, indexType) || (vectorType != "" && c.VectorType != vectorType) {
+			continue
+		}
+		for _, metricType := range c.MetricTypes {
+			if !funcutil.SliceContain(allowed, metricType) {
+				allowed = append(allowed, metricType)
+			}
+		}
+	}
+	return allowed
+}
+
+// CheckMetricCompatibility returns an error naming the allowed metric types if @metricType is not supported by
+// @indexType on @vectorType, or if @indexType does not support @vectorType. The index types not in the table and
+// the empty metric type are left to the engine.
+func CheckMetricCompatibility(indexType IndexType, metricType string, vectorType VectorType) error {
+	known := false
+	for _, c := range metricCompatibilities {
+		if strings.EqualFold(c.IndexType, indexType) {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return nil
+	}
+	allowed := AllowedMetricTypes(indexType, vectorType)
+	if len(allowed) == 0 {
+		return fmt.Errorf("index type %s does not support %s", indexType, vectorType)
+	}
+	if metricType == "" || funcutil.SliceContain(allowed, strings.ToUpper(metricType)) {
+		return nil
+	}
+	if vectorType == "" {
+		return fmt.Errorf("metric type %s is not supported by index type %s, allowed metric types: %s",
+			metricType, indexType, strings.Join(allowed, ","))
+	}
+	return fmt.Errorf("metric type %s is not supported by index type %s on %s, allowed metric types: %s",
+		metricType, indexType, vectorType, strings.Join(allowed, ","))
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package indexparamcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckMetricCompatibility(t *testing.T) {
+	assert.Nil(t, CheckMetricCompatibility(IndexHNSW, L2, FloatVector))
+	assert.Nil(t, CheckMetricCompatibility("hnsw", "ip", ""))
+	assert.Nil(t, CheckMetricCompatibility(IndexFaissBinIDMap, SUBSTRUCTURE, BinaryVector))
+
+	err := CheckMetricCompatibility(IndexHNSW, SUBSTRUCTURE, FloatVector)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "allowed metric types: L2,IP")
+	err = CheckMetricCompatibility(IndexFaissBinIvfFlat, SUBSTRUCTURE, "")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "allowed metric types: HAMMING,JACCARD,TANIMOTO")
+	assert.NotNil(t, CheckMetricCompatibility(IndexHNSW, L2, BinaryVector))
+	assert.NotNil(t, CheckMetricCompatibility(IndexFaissBinIDMap, "", FloatVector))
+
+	// left to the engine
+	assert.Nil(t, CheckMetricCompatibility(IndexHNSW, "", FloatVector))
+	assert.Nil(t, CheckMetricCompatibility("DISKANN", SUBSTRUCTURE, FloatVector))
+}
+
+func TestMetricCompatibilities(t *testing.T) {
+	table := MetricCompatibilities()
+	assert.Equal(t, len(metricCompatibilities), len(table))
+	for _, c := range table {
+		_, err := GetConfAdapterMgrInstance().GetAdapter(c.IndexType)
+		assert.Nil(t, err, c.IndexType)
+		assert.NotEmpty(t, c.MetricTypes)
+	}
+	// the table returned is a copy
+	table[0].MetricTypes[0] = "NOT_EXIST"
+	assert.NotEqual(t, "NOT_EXIST", metricCompatibilities[0].MetricTypes[0])
+
+	assert.Equal(t, []string{L2, IP}, AllowedMetricTypes(IndexHNSW, ""))
+	assert.Empty(t, AllowedMetricTypes(IndexHNSW, BinaryVector))
+}
@@ -21,6 +21,19 @@ import (
 const (
 	MetricTypeKey     = "metric_type"
 	SystemInfoMetrics = "system_info"
+
+	// IndexCompatibilityMetrics checks whether the built indexes can be loaded by the current engine,
+	// the build ids to check are given by BuildIDsKey, all indexes are checked without it
+	IndexCompatibilityMetrics = "index_compatibility"
+	BuildIDsKey               = "build_ids"
+
+	// IndexRebuildProgressMetrics returns the progress of rebuilding the indexes which can not be loaded by the
+	// current engine, which is started by RebuildIncompatibleIndexes of IndexNode
+	IndexRebuildProgressMetrics = "index_rebuild_progress"
+
+	// IndexMetricCompatibilityMetrics returns the metric types supported by each index type on each vector type,
+	// so that the requests can be validated before they are sent to index node
+	IndexMetricCompatibilityMetrics = "index_metric_compatibility"
 )
 
 // ParseMetricType returns the metric type of req
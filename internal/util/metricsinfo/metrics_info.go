@@ -117,13 +117,162 @@ type ProxyInfos struct {
 type IndexNodeConfiguration struct {
 	MinioBucketName string `json:"minio_bucket_name"`
 
-	SimdType string `json:"simd_type"`
+	// SimdType is the one set to the engine, which may be downgraded from RequestedSimdType if the CPU does not
+	// support it, and EffectiveSimdType is the one the engine actually uses
+	SimdType          string `json:"simd_type"`
+	RequestedSimdType string `json:"requested_simd_type"`
+	EffectiveSimdType string `json:"effective_simd_type"`
+	// SimdTypeOverrides is the simd type configured for each index type, overriding SimdType
+	SimdTypeOverrides map[string]string `json:"simd_type_overrides"`
+
+	BuildParallel int `json:"build_parallel"`
+	// MemoryBudget is the bytes of the memory the builds may use before the admission of new tasks is paused by
+	// MemoryHighWatermark, 0 if the memory watermark is disabled
+	MemoryBudget        uint64  `json:"memory_budget"`
+	MemoryHighWatermark float64 `json:"memory_high_watermark"`
+	MemoryLowWatermark  float64 `json:"memory_low_watermark"`
+	// DiskMinFree and DiskResumeFree are the free bytes of the scratch path pausing and resuming the admission
+	DiskMinFree    int64 `json:"disk_min_free"`
+	DiskResumeFree int64 `json:"disk_resume_free"`
+
+	// Capabilities are the capability tags advertised by the node
+	Capabilities []string `json:"capabilities"`
+}
+
+// IndexNodeTaskInfos records the statistics of the index building tasks of index node.
+type IndexNodeTaskInfos struct {
+	QueuedTaskNum    int64 `json:"queued_task_num"`
+	ActiveTaskNum    int64 `json:"active_task_num"`
+	CompletedTaskNum int64 `json:"completed_task_num"`
+	FailedTaskNum    int64 `json:"failed_task_num"`
+
+	// AvailableSlotNum is the number of tasks which can still be queued before MaxPendingTaskNum is reached
+	MaxPendingTaskNum int64 `json:"max_pending_task_num"`
+	AvailableSlotNum  int64 `json:"available_slot_num"`
+
+	// IndexTypeBuildNum records the number of finished builds of each index type
+	IndexTypeBuildNum map[string]int64 `json:"index_type_build_num"`
+	// SimdTypeBuildNum records the number of finished builds of each effective simd type
+	SimdTypeBuildNum map[string]int64 `json:"simd_type_build_num"`
+
+	// throughputs are in bytes per second
+	LoadedBytes    int64   `json:"loaded_bytes"`
+	LoadThroughput float64 `json:"load_throughput"`
+	SavedBytes     int64   `json:"saved_bytes"`
+	SaveThroughput float64 `json:"save_throughput"`
+
+	// IndexTypePeakMemory records the max peak memory in bytes of the builds of each index type
+	IndexTypePeakMemory map[string]int64 `json:"index_type_peak_memory"`
+
+	// SuspectBuildIDs are the in-progress tasks staying in a stage longer than indexNode.taskHeartbeat.stallTimeout
+	SuspectTaskNum  int64   `json:"suspect_task_num"`
+	SuspectBuildIDs []int64 `json:"suspect_build_ids"`
+
+	// IndexTypeEngineStatistics records the statistics reported by the index engine of the last build of each
+	// index type, which are collected only if indexNode.engineStats.enabled
+	IndexTypeEngineStatistics map[string]IndexEngineStatistics `json:"index_type_engine_statistics,omitempty"`
+	// IndexTypeEngineConfig records the effective build configuration of the index engine of the last build of each
+	// index type, which is the request parameters merged over knowhere.engine.*
+	IndexTypeEngineConfig map[string]map[string]string `json:"index_type_engine_config,omitempty"`
+}
+
+// IndexEngineStatistics records the statistics of a build reported by the index engine, the statistics not exposed
+// by the engine are absent, the durations are in milliseconds.
+type IndexEngineStatistics struct {
+	Threads   *int64   `json:"threads,omitempty"`
+	TrainMs   *float64 `json:"train_ms,omitempty"`
+	AddMs     *float64 `json:"add_ms,omitempty"`
+	BuildMs   *float64 `json:"build_ms,omitempty"`
+	CPUMs     *float64 `json:"cpu_ms,omitempty"`
+	Rows      *int64   `json:"rows,omitempty"`
+	Dim       *int64   `json:"dim,omitempty"`
+	IndexSize *int64   `json:"index_size,omitempty"`
+	// ThreadUtilization is the ratio of CPUMs to the wall time of the build by all the Threads
+	ThreadUtilization *float64 `json:"thread_utilization,omitempty"`
+	// Seed is the seed the index is built with, absent if the build is not seeded, and SeedHonored is false if
+	// the engine can't seed the index type
+	Seed        *int64 `json:"seed,omitempty"`
+	SeedHonored *bool  `json:"seed_honored,omitempty"`
+	// SimdType is the simd type configured for the build, and EffectiveSimdType is the one the engine actually
+	// builds the index with
+	SimdType          string `json:"simd_type,omitempty"`
+	EffectiveSimdType string `json:"effective_simd_type,omitempty"`
 }
 
 // IndexNodeInfos implements ComponentInfos
 type IndexNodeInfos struct {
 	BaseComponentInfos
 	SystemConfigurations IndexNodeConfiguration `json:"system_configurations"`
+	TaskInfos            IndexNodeTaskInfos     `json:"task_infos"`
+	// Mode is active, or standby if the node rejects the build requests until activated
+	Mode string `json:"mode"`
+	// UptimeSeconds is the seconds since CreatedTime
+	UptimeSeconds int64 `json:"uptime_seconds"`
+	// ConfigRefreshes is the number of the configuration refreshes at runtime, the last of which is at UpdatedTime
+	ConfigRefreshes int64 `json:"config_refreshes"`
+	// SelfTest is the result of the self-test at startup, nil if it's disabled
+	SelfTest *IndexNodeSelfTest `json:"self_test,omitempty"`
+}
+
+// IndexNodeSelfTest records the result of the synthetic build index node runs at startup.
+type IndexNodeSelfTest struct {
+	Passed    bool   `json:"passed"`
+	IndexType string `json:"index_type"`
+	// SimdType is the effective simd type the synthetic index is built with
+	SimdType   string `json:"simd_type"`
+	DurationMs int64  `json:"duration_ms"`
+	// FailedStage is the stage the self-test fails at, and Error is why it fails
+	FailedStage string `json:"failed_stage,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// IndexArtifactVersion records the format version of the index files, zero means the index is built before versioning.
+type IndexArtifactVersion struct {
+	EngineVersion int64 `json:"engine_version"`
+	SchemaVersion int64 `json:"schema_version"`
+}
+
+// IndexBuildCompatibility records whether the index of a build can be loaded by the current engine.
+type IndexBuildCompatibility struct {
+	IndexBuildID    int64                `json:"index_build_id"`
+	ArtifactVersion IndexArtifactVersion `json:"artifact_version"`
+	Compatible      bool                 `json:"compatible"`
+	Reason          string               `json:"reason,omitempty"`
+}
+
+// IndexCompatibilityInfos records the compatibility of the built indexes with the current engine.
+type IndexCompatibilityInfos struct {
+	CurrentVersion IndexArtifactVersion      `json:"current_version"`
+	Builds         []IndexBuildCompatibility `json:"builds"`
+}
+
+// IndexMetricCompatibility records the metric types supported by an index type on a vector type.
+type IndexMetricCompatibility struct {
+	IndexType   string   `json:"index_type"`
+	VectorType  string   `json:"vector_type"`
+	MetricTypes []string `json:"metric_types"`
+}
+
+// IndexMetricCompatibilityInfos records the combinations of the index types, the vector types and the metric types
+// supported by index node, the index types absent are not validated by index node.
+type IndexMetricCompatibilityInfos struct {
+	Compatibilities []IndexMetricCompatibility `json:"compatibilities"`
+}
+
+// IndexRebuildProgress records the progress of rebuilding the indexes incompatible with the current engine.
+type IndexRebuildProgress struct {
+	Running    bool   `json:"running"`
+	StartTime  string `json:"start_time"`
+	FinishTime string `json:"finish_time"`
+
+	ScannedNum    int64 `json:"scanned_num"`
+	CandidateNum  int64 `json:"candidate_num"`
+	PendingNum    int64 `json:"pending_num"`
+	RebuildingNum int64 `json:"rebuilding_num"`
+
+	RebuiltBuildIDs []int64          `json:"rebuilt_build_ids"`
+	FailedBuilds    map[int64]string `json:"failed_builds"`
+	Error           string           `json:"error,omitempty"`
 }
 
 // IndexCoordConfiguration records the configuration of index coordinator.
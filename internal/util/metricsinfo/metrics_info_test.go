@@ -138,6 +138,7 @@ func TestQueryCoordInfos_Codec(t *testing.T) {
 }
 
 func TestIndexNodeInfos_Codec(t *testing.T) {
+	threads, buildMs := int64(8), float64(1500)
 	infos1 := IndexNodeInfos{
 		BaseComponentInfos: BaseComponentInfos{
 			HasError:    false,
@@ -163,7 +164,34 @@ func TestIndexNodeInfos_Codec(t *testing.T) {
 		SystemConfigurations: IndexNodeConfiguration{
 			MinioBucketName: "a-bucket",
 
-			SimdType: "auto",
+			SimdType:          "AVX2",
+			RequestedSimdType: "avx512",
+			SimdTypeOverrides: map[string]string{"HNSW": "avx512"},
+
+			BuildParallel: 1,
+		},
+		TaskInfos: IndexNodeTaskInfos{
+			QueuedTaskNum:       3,
+			ActiveTaskNum:       1,
+			CompletedTaskNum:    10,
+			FailedTaskNum:       2,
+			MaxPendingTaskNum:   1024,
+			AvailableSlotNum:    1021,
+			IndexTypeBuildNum:   map[string]int64{"IVF_FLAT": 8, "HNSW": 4},
+			SimdTypeBuildNum:    map[string]int64{"AVX2": 8, "AVX512": 4},
+			LoadedBytes:         1024 * 1024,
+			LoadThroughput:      512 * 1024,
+			SavedBytes:          2048 * 1024,
+			SaveThroughput:      256 * 1024,
+			IndexTypePeakMemory: map[string]int64{"IVF_FLAT": 1024 * 1024},
+			SuspectTaskNum:      1,
+			SuspectBuildIDs:     []int64{7},
+			IndexTypeEngineStatistics: map[string]IndexEngineStatistics{
+				"IVF_FLAT": {Threads: &threads, BuildMs: &buildMs},
+			},
+			IndexTypeEngineConfig: map[string]map[string]string{
+				"IVF_FLAT": {"index_type": "IVF_FLAT", "nlist": "1024"},
+			},
 		},
 	}
 	s, err := MarshalComponentInfos(infos1)
@@ -176,6 +204,56 @@ func TestIndexNodeInfos_Codec(t *testing.T) {
 	assert.Equal(t, infos1, infos2)
 }
 
+func TestIndexCompatibilityInfos_Codec(t *testing.T) {
+	infos1 := IndexCompatibilityInfos{
+		CurrentVersion: IndexArtifactVersion{EngineVersion: 1, SchemaVersion: 1},
+		Builds: []IndexBuildCompatibility{
+			{IndexBuildID: 1, ArtifactVersion: IndexArtifactVersion{EngineVersion: 1, SchemaVersion: 1}, Compatible: true},
+			{IndexBuildID: 2, ArtifactVersion: IndexArtifactVersion{EngineVersion: 2, SchemaVersion: 1}, Reason: "newer engine"},
+		},
+	}
+	s, err := MarshalComponentInfos(infos1)
+	assert.Equal(t, nil, err)
+	var infos2 IndexCompatibilityInfos
+	err = UnmarshalComponentInfos(s, &infos2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, infos1, infos2)
+}
+
+func TestIndexMetricCompatibilityInfos_Codec(t *testing.T) {
+	infos1 := IndexMetricCompatibilityInfos{
+		Compatibilities: []IndexMetricCompatibility{
+			{IndexType: "HNSW", VectorType: "FloatVector", MetricTypes: []string{"L2", "IP"}},
+			{IndexType: "BIN_FLAT", VectorType: "BinaryVector", MetricTypes: []string{"HAMMING", "SUBSTRUCTURE"}},
+		},
+	}
+	s, err := MarshalComponentInfos(infos1)
+	assert.Equal(t, nil, err)
+	var infos2 IndexMetricCompatibilityInfos
+	err = UnmarshalComponentInfos(s, &infos2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, infos1, infos2)
+}
+
+func TestIndexRebuildProgress_Codec(t *testing.T) {
+	progress1 := IndexRebuildProgress{
+		Running:         true,
+		StartTime:       time.Now().String(),
+		ScannedNum:      100,
+		CandidateNum:    3,
+		PendingNum:      1,
+		RebuildingNum:   1,
+		RebuiltBuildIDs: []int64{1},
+		FailedBuilds:    map[int64]string{2: "build failed"},
+	}
+	s, err := MarshalComponentInfos(progress1)
+	assert.Equal(t, nil, err)
+	var progress2 IndexRebuildProgress
+	err = UnmarshalComponentInfos(s, &progress2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, progress1, progress2)
+}
+
 func TestIndexCoordInfos_Codec(t *testing.T) {
 	infos1 := IndexCoordInfos{
 		BaseComponentInfos: BaseComponentInfos{
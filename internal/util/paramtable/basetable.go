@@ -438,6 +438,24 @@ func (gp *BaseTable) InitLogCfg() {
 	gp.Log.File.MaxSize = gp.ParseInt("log.file.maxSize")
 	gp.Log.File.MaxBackups = gp.ParseInt("log.file.maxBackups")
 	gp.Log.File.MaxDays = gp.ParseInt("log.file.maxAge")
+	gp.Log.Stdout = gp.ParseBool("log.stdout", false)
+	gp.Log.TimestampFormat, gp.Log.TimeKey, gp.Log.LevelKey = gp.loadLogConventions()
+}
+
+// loadLogConventions loads the format of the timestamps and the keys of the timestamp and the level of the log
+// entries, which are empty for the defaults of the log package.
+func (gp *BaseTable) loadLogConventions() (timestampFormat string, timeKey string, levelKey string) {
+	var err error
+	if timestampFormat, err = gp.LoadWithDefault("log.timestampFormat", ""); err != nil {
+		panic(err)
+	}
+	if timeKey, err = gp.LoadWithDefault("log.timeKey", ""); err != nil {
+		panic(err)
+	}
+	if levelKey, err = gp.LoadWithDefault("log.levelKey", ""); err != nil {
+		panic(err)
+	}
+	return strings.TrimSpace(timestampFormat), strings.TrimSpace(timeKey), strings.TrimSpace(levelKey)
 }
 
 func (gp *BaseTable) SetLogConfig(f func(log.Config)) {
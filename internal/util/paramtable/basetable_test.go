@@ -242,3 +242,24 @@ func Test_SetLogger(t *testing.T) {
 		assert.Equal(t, "datanode-0.log", baseParams.Log.File.Filename)
 	})
 }
+
+func Test_InitLogCfg(t *testing.T) {
+	keys := []string{"log.timestampFormat", "log.timeKey", "log.levelKey"}
+	olds := make([]string, len(keys))
+	for i, key := range keys {
+		olds[i], _ = baseParams.LoadWithDefault(key, "")
+	}
+	defer func() {
+		for i, key := range keys {
+			baseParams.Save(key, olds[i])
+		}
+		baseParams.InitLogCfg()
+	}()
+	baseParams.Save("log.timestampFormat", " rfc3339 ")
+	baseParams.Save("log.timeKey", "@timestamp")
+	baseParams.Save("log.levelKey", "severity")
+	baseParams.InitLogCfg()
+	assert.Equal(t, "rfc3339", baseParams.Log.TimestampFormat)
+	assert.Equal(t, "@timestamp", baseParams.Log.TimeKey)
+	assert.Equal(t, "severity", baseParams.Log.LevelKey)
+}
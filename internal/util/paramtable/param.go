@@ -140,4 +140,5 @@ func (p *BaseParamTable) initLogCfg() {
 	if err != nil {
 		panic(err)
 	}
+	p.LogConfig.TimestampFormat, p.LogConfig.TimeKey, p.LogConfig.LevelKey = p.loadLogConventions()
 }
@@ -0,0 +1,186 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+// Package ratelimit limits the rates of the calls of the grpc methods by the token buckets. The calls exceeding the
+// limits are rejected with ResourceExhausted, and the time after which a call is allowed again is hinted in the
+// trailer of the response. Only the methods declared limitable are limited, the others are always called.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RetryAfterKey is the key of the trailer of the rejected calls, which is the milliseconds after which a call is
+// allowed again.
+const RetryAfterKey = "retry-after-ms"
+
+// Limit is the limit of the calls of a method.
+type Limit struct {
+	// Rate is the number of the calls allowed per second.
+	Rate float64
+	// Burst is the number of the calls allowed at once.
+	Burst int
+}
+
+// String returns the limit in the form of "rate:burst".
+func (l Limit) String() string {
+	return strconv.FormatFloat(l.Rate, 'f', -1, 64) + ":" + strconv.Itoa(l.Burst)
+}
+
+// ParseLimits parses the limits in the form of "method:rate[:burst],...", the burst defaults to the rate rounded up.
+func ParseLimits(s string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		fields := strings.Split(item, ":")
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("invalid rate limit %q, expect method:rate[:burst]", item)
+		}
+		method := strings.TrimSpace(fields[0])
+		r, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err != nil || r <= 0 || math.IsInf(r, 0) {
+			return nil, fmt.Errorf("invalid rate of the rate limit %q, expect a positive number", item)
+		}
+		burst := int(math.Ceil(r))
+		if len(fields) == 3 {
+			burst, err = strconv.Atoi(strings.TrimSpace(fields[2]))
+			if err != nil || burst <= 0 {
+				return nil, fmt.Errorf("invalid burst of the rate limit %q, expect a positive integer", item)
+			}
+		}
+		limits[method] = Limit{Rate: r, Burst: burst}
+	}
+	return limits, nil
+}
+
+// FormatLimits formats @limits in the form parsed by ParseLimits.
+func FormatLimits(limits map[string]Limit) string {
+	items := make([]string, 0, len(limits))
+	for method, limit := range limits {
+		items = append(items, method+":"+limit.String())
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// Limiter limits the rates of the calls of the limitable methods.
+type Limiter struct {
+	limitable map[string]bool
+	// onThrottle is called with the method name once a call is rejected
+	onThrottle func(method string)
+
+	mu       sync.RWMutex
+	limiters map[string]*rate.Limiter
+}
+
+// NewLimiter returns the Limiter of @methods, which are the method names without the service, e.g. "CreateIndex".
+// The methods are not limited until SetLimits.
+func NewLimiter(methods []string, onThrottle func(method string)) *Limiter {
+	limitable := make(map[string]bool)
+	for _, method := range methods {
+		limitable[method] = true
+	}
+	return &Limiter{
+		limitable:  limitable,
+		onThrottle: onThrottle,
+		limiters:   make(map[string]*rate.Limiter),
+	}
+}
+
+// SetLimits replaces the limits by @limits, the methods missing are not limited any more. The buckets of the methods
+// still limited are kept, so that the calls allowed at the moment are not reset. It returns the error without
+// changing the limits if any of the methods is not limitable.
+func (l *Limiter) SetLimits(limits map[string]Limit) error {
+	for method := range limits {
+		if !l.limitable[method] {
+			return fmt.Errorf("method %s is not rate limited", method)
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limiters := make(map[string]*rate.Limiter, len(limits))
+	for method, limit := range limits {
+		if limiter, ok := l.limiters[method]; ok {
+			limiter.SetLimit(rate.Limit(limit.Rate))
+			limiter.SetBurst(limit.Burst)
+			limiters[method] = limiter
+			continue
+		}
+		limiters[method] = rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)
+	}
+	l.limiters = limiters
+	return nil
+}
+
+// Limits returns the limits of the methods limited.
+func (l *Limiter) Limits() map[string]Limit {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	limits := make(map[string]Limit, len(l.limiters))
+	for method, limiter := range l.limiters {
+		limits[method] = Limit{Rate: float64(limiter.Limit()), Burst: limiter.Burst()}
+	}
+	return limits
+}
+
+// allow returns whether a call of @method is allowed, or the time after which a call is allowed again.
+func (l *Limiter) allow(method string) (bool, time.Duration) {
+	l.mu.RLock()
+	limiter, ok := l.limiters[method]
+	l.mu.RUnlock()
+	if !ok {
+		return true, 0
+	}
+	now := time.Now()
+	reservation := limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay == 0 {
+		return true, 0
+	}
+	reservation.CancelAt(now)
+	return false, delay
+}
+
+// UnaryServerInterceptor returns the interceptor rejecting the unary calls exceeding the limits.
+func (l *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		method := path.Base(info.FullMethod)
+		allowed, retryAfter := l.allow(method)
+		if allowed {
+			return handler(ctx, req)
+		}
+		if l.onThrottle != nil {
+			l.onThrottle(method)
+		}
+		millis := int64(math.Ceil(float64(retryAfter) / float64(time.Millisecond)))
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(RetryAfterKey, strconv.FormatInt(millis, 10)))
+		return nil, status.Errorf(codes.ResourceExhausted, "the rate limit of %s is exceeded, retry after %dms",
+			method, millis)
+	}
+}
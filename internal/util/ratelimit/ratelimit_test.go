@@ -0,0 +1,149 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits(" CreateIndex:10:20, GetMetrics:0.5,,DryRunCreateIndex:2.5 ")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]Limit{
+		"CreateIndex":       {Rate: 10, Burst: 20},
+		"GetMetrics":        {Rate: 0.5, Burst: 1},
+		"DryRunCreateIndex": {Rate: 2.5, Burst: 3},
+	}, limits)
+	assert.Equal(t, "CreateIndex:10:20,DryRunCreateIndex:2.5:3,GetMetrics:0.5:1", FormatLimits(limits))
+
+	formatted, err := ParseLimits(FormatLimits(limits))
+	assert.Nil(t, err)
+	assert.Equal(t, limits, formatted)
+
+	limits, err = ParseLimits("")
+	assert.Nil(t, err)
+	assert.Empty(t, limits)
+	assert.Equal(t, "", FormatLimits(limits))
+
+	for _, s := range []string{"CreateIndex", "CreateIndex:1:2:3", "CreateIndex:a", "CreateIndex:0", "CreateIndex:-1",
+		"CreateIndex:+Inf", "CreateIndex:1:0", "CreateIndex:1:1.5"} {
+		_, err = ParseLimits(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestLimiter_SetLimits(t *testing.T) {
+	limiter := NewLimiter([]string{"CreateIndex", "GetMetrics"}, nil)
+	assert.Empty(t, limiter.Limits())
+
+	limits := map[string]Limit{"CreateIndex": {Rate: 1, Burst: 2}}
+	assert.Nil(t, limiter.SetLimits(limits))
+	assert.Equal(t, limits, limiter.Limits())
+
+	// the limits are kept if any of the methods is not limitable
+	assert.NotNil(t, limiter.SetLimits(map[string]Limit{"GetMetrics": {Rate: 1, Burst: 1}, "Activate": {Rate: 1, Burst: 1}}))
+	assert.Equal(t, limits, limiter.Limits())
+
+	assert.Nil(t, limiter.SetLimits(nil))
+	assert.Empty(t, limiter.Limits())
+}
+
+// healthServer counts the calls served.
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	calls int
+}
+
+func (s *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	s.calls++
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func TestLimiter_UnaryServerInterceptor(t *testing.T) {
+	var throttled []string
+	limiter := NewLimiter([]string{"Check"}, func(method string) {
+		throttled = append(throttled, method)
+	})
+	assert.Nil(t, limiter.SetLimits(map[string]Limit{"Check": {Rate: 0.1, Burst: 2}}))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	server := grpc.NewServer(grpc.UnaryInterceptor(limiter.UnaryServerInterceptor()))
+	health := &healthServer{}
+	grpc_health_v1.RegisterHealthServer(server, health)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	defer server.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	assert.Nil(t, err)
+	defer conn.Close()
+	client := grpc_health_v1.NewHealthClient(conn)
+	check := func() (metadata.MD, error) {
+		var trailer metadata.MD
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpc.Trailer(&trailer))
+		return trailer, err
+	}
+
+	// the burst is allowed at once
+	for i := 0; i < 2; i++ {
+		_, err = check()
+		assert.Nil(t, err)
+	}
+	trailer, err := check()
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, 2, health.calls)
+	assert.Equal(t, []string{"Check"}, throttled)
+	values := trailer.Get(RetryAfterKey)
+	assert.Equal(t, 1, len(values))
+	retryAfter, err := strconv.Atoi(values[0])
+	assert.Nil(t, err)
+	assert.True(t, retryAfter > 0 && retryAfter <= 10000, retryAfter)
+
+	// the rejected calls don't take the tokens, which are refilled at the new rate once the limits are raised
+	assert.Nil(t, limiter.SetLimits(map[string]Limit{"Check": {Rate: 1000, Burst: 1}}))
+	assert.Eventually(t, func() bool {
+		_, err := check()
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// the calls are never rejected once the method is not limited
+	assert.Nil(t, limiter.SetLimits(nil))
+	for i := 0; i < 10; i++ {
+		_, err = check()
+		assert.Nil(t, err)
+	}
+}
+
+func TestLimiter_unlimitedMethods(t *testing.T) {
+	limiter := NewLimiter([]string{"CreateIndex"}, nil)
+	assert.Nil(t, limiter.SetLimits(map[string]Limit{"CreateIndex": {Rate: 0.1, Burst: 1}}))
+	allowed, _ := limiter.allow("CreateIndex")
+	assert.True(t, allowed)
+	allowed, retryAfter := limiter.allow("CreateIndex")
+	assert.False(t, allowed)
+	assert.True(t, retryAfter > 0)
+	allowed, _ = limiter.allow("GetComponentStates")
+	assert.True(t, allowed)
+}
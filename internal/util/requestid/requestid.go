@@ -0,0 +1,132 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+// Package requestid propagates the id of a request from the caller to the server in the grpc metadata, so that the
+// logs of both sides of the request share the id. The server carries the id in the context of the request, and
+// echoes it in the header and in the status of the response.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+)
+
+// MetadataKey is the key of the request id in the grpc metadata.
+const MetadataKey = "x-request-id"
+
+// FieldKey is the key of the request id in the log entries.
+const FieldKey = "requestID"
+
+// maxLength is the longest request id taken from the metadata, the longer ones are replaced by a generated one
+const maxLength = 128
+
+type requestIDKey struct{}
+
+// New generates a random request id.
+func New() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// NewContext returns the context carrying @id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// FromContext returns the request id carried by @ctx, or empty if there is none.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Logger returns @logger attaching the request id of @ctx to the entries, or @logger itself if there is none.
+func Logger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	if id := FromContext(ctx); id != "" {
+		return logger.With(zap.String(FieldKey, id))
+	}
+	return logger
+}
+
+// fromIncoming returns the request id of the incoming metadata of @ctx, or empty if there is none or it's invalid.
+func fromIncoming(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(MetadataKey)
+	if len(values) == 0 {
+		return ""
+	}
+	id := strings.TrimSpace(values[0])
+	if len(id) > maxLength {
+		return ""
+	}
+	return id
+}
+
+// UnaryServerInterceptor returns the interceptor carrying the request id of the incoming metadata, or a generated
+// one if there is none, in the context of the handler, and echoing it in the header and the status of the response.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		id := fromIncoming(ctx)
+		if id == "" {
+			id = New()
+		}
+		ctx = NewContext(ctx, id)
+		// fails only outside of a grpc server, e.g. in the tests calling the interceptor directly
+		_ = grpc.SetHeader(ctx, metadata.Pairs(MetadataKey, id))
+		resp, err := handler(ctx, req)
+		echo(resp, id)
+		return resp, err
+	}
+}
+
+// echo sets @id to the status of @resp, which is either a status or carries one.
+func echo(resp interface{}, id string) {
+	switch r := resp.(type) {
+	case *commonpb.Status:
+		if r != nil {
+			r.RequestId = id
+		}
+	case interface{ GetStatus() *commonpb.Status }:
+		if status := r.GetStatus(); status != nil {
+			status.RequestId = id
+		}
+	}
+}
+
+// UnaryClientInterceptor returns the interceptor sending the request id carried by the context of the call in the
+// outgoing metadata, a request id is generated for the call without one.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		id := FromContext(ctx)
+		if id == "" {
+			id = New()
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, MetadataKey, id)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/milvus-io/milvus/internal/proto/commonpb"
+	"github.com/milvus-io/milvus/internal/proto/internalpb"
+)
+
+func TestNew(t *testing.T) {
+	id := New()
+	assert.Equal(t, 32, len(id))
+	assert.NotEqual(t, id, New())
+}
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, "", FromContext(nil))
+	assert.Equal(t, "", FromContext(context.Background()))
+	ctx := NewContext(context.Background(), "r-1")
+	assert.Equal(t, "r-1", FromContext(ctx))
+	child, cancel := context.WithCancel(ctx)
+	defer cancel()
+	assert.Equal(t, "r-1", FromContext(child))
+}
+
+func TestLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	Logger(context.Background(), logger).Info("without")
+	Logger(NewContext(context.Background(), "r-1"), logger).Info("with")
+	entries := logs.TakeAll()
+	assert.Equal(t, 2, len(entries))
+	assert.NotContains(t, entries[0].ContextMap(), FieldKey)
+	assert.Equal(t, "r-1", entries[1].ContextMap()[FieldKey])
+}
+
+func callServer(ctx context.Context, resp interface{}) (string, interface{}) {
+	var id string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		id = FromContext(ctx)
+		return resp, nil
+	}
+	resp, _ = UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test/Call"}, handler)
+	return id, resp
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "r-1"))
+	id, resp := callServer(ctx, &commonpb.Status{})
+	assert.Equal(t, "r-1", id)
+	assert.Equal(t, "r-1", resp.(*commonpb.Status).RequestId)
+
+	id, resp = callServer(ctx, &internalpb.ComponentStates{Status: &commonpb.Status{}})
+	assert.Equal(t, "r-1", id)
+	assert.Equal(t, "r-1", resp.(*internalpb.ComponentStates).Status.RequestId)
+
+	// the responses without a status are passed through
+	id, resp = callServer(ctx, &internalpb.ComponentStates{})
+	assert.Equal(t, "r-1", id)
+	assert.Nil(t, resp.(*internalpb.ComponentStates).Status)
+	id, _ = callServer(ctx, nil)
+	assert.Equal(t, "r-1", id)
+
+	// an id is generated for the requests without one or with an invalid one
+	id, resp = callServer(context.Background(), &commonpb.Status{})
+	assert.Equal(t, 32, len(id))
+	assert.Equal(t, id, resp.(*commonpb.Status).RequestId)
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, strings.Repeat("r", maxLength+1)))
+	id, _ = callServer(ctx, &commonpb.Status{})
+	assert.Equal(t, 32, len(id))
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("other", "r-1"))
+	id, _ = callServer(ctx, &commonpb.Status{})
+	assert.Equal(t, 32, len(id))
+}
+
+func callClient(ctx context.Context) []string {
+	var ids []string
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		ids = md.Get(MetadataKey)
+		return nil
+	}
+	_ = UnaryClientInterceptor()(ctx, "/test/Call", nil, nil, nil, invoker)
+	return ids
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	assert.Equal(t, []string{"r-1"}, callClient(NewContext(context.Background(), "r-1")))
+
+	ids := callClient(context.Background())
+	assert.Equal(t, 1, len(ids))
+	assert.Equal(t, 32, len(ids[0]))
+}
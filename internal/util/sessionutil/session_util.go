@@ -34,6 +34,8 @@ const (
 // Session is a struct to store service's session, including ServerID, ServerName,
 // Address.
 // Exclusive indicates that this server can only start one.
+// Alias, Version, CreatedTime, Labels, Capabilities and Standby are the optional metadata, which are set before Init()
+// and can be updated by UpdateRegistration().
 type Session struct {
 	ctx        context.Context
 	ServerID   int64  `json:"ServerID,omitempty"`
@@ -41,6 +43,15 @@ type Session struct {
 	Address    string `json:"Address,omitempty"`
 	Exclusive  bool   `json:"Exclusive,omitempty"`
 
+	Alias       string            `json:"Alias,omitempty"`
+	Version     string            `json:"Version,omitempty"`
+	CreatedTime string            `json:"CreatedTime,omitempty"`
+	Labels      map[string]string `json:"Labels,omitempty"`
+	// Capabilities are the capability tags for the coordinator to place the tasks
+	Capabilities []string `json:"Capabilities,omitempty"`
+	// Standby indicates the server is a warm spare which does not accept the tasks until activated
+	Standby bool `json:"Standby,omitempty"`
+
 	etcdCli  *clientv3.Client
 	leaseID  clientv3.LeaseID
 	cancel   context.CancelFunc
@@ -87,10 +98,26 @@ func NewSession(ctx context.Context, metaRoot string, etcdEndpoints []string) *S
 // Address, Exclusive. ServerID is obtained in getServerID.
 // Finally it will process keepAliveResponse to keep alive with etcd.
 func (s *Session) Init(serverName, address string, exclusive bool) <-chan bool {
+	return s.InitWithServerID(serverName, address, exclusive, 0)
+}
+
+// InitWithServerID is the same as Init, except that the session tries to register with @serverID if it is
+// positive, which is usually the ServerID of a previous session. A new ServerID is obtained in getServerID
+// if another server has registered with @serverID.
+func (s *Session) InitWithServerID(serverName, address string, exclusive bool, serverID int64) <-chan bool {
 	s.ServerName = serverName
 	s.Address = address
 	s.Exclusive = exclusive
 	s.checkIDExist()
+	if serverID > 0 {
+		s.ServerID = serverID
+		ch, err := s.registerServiceOnce(s.ctx)
+		if err == nil {
+			return s.processKeepAliveResponse(ch)
+		}
+		log.Warn("Session failed to register with the previous ServerID, get a new one",
+			zap.Int64("ServerID", serverID), zap.Error(err))
+	}
 	serverID, err := s.getServerID()
 	if err != nil {
 		panic(err)
@@ -166,6 +193,11 @@ func (s *Session) getServerIDWithKey(key string, retryTimes uint) (int64, error)
 //	 ServerName string `json:"ServerName,omitempty"`
 //	 Address    string `json:"Address,omitempty"`
 //   Exclusive  bool   `json:"Exclusive,omitempty"`
+//   Alias       string            `json:"Alias,omitempty"`
+//   Version     string            `json:"Version,omitempty"`
+//   CreatedTime string            `json:"CreatedTime,omitempty"`
+//   Labels      map[string]string `json:"Labels,omitempty"`
+//   Capabilities []string         `json:"Capabilities,omitempty"`
 // }
 // Exclusive means whether this service can exist two at the same time, if so,
 // it is false. Otherwise, set it to true.
@@ -173,50 +205,59 @@ func (s *Session) registerService() (<-chan *clientv3.LeaseKeepAliveResponse, er
 	var ch <-chan *clientv3.LeaseKeepAliveResponse
 	log.Debug("Session Register Begin")
 	registerFn := func() error {
-		resp, err := s.etcdCli.Grant(s.ctx, DefaultTTL)
-		if err != nil {
-			log.Error("register service", zap.Error(err))
-			return err
-		}
-		s.leaseID = resp.ID
+		var err error
+		ch, err = s.registerServiceOnce(s.ctx)
+		return err
+	}
+	err := retry.Do(s.ctx, registerFn, retry.Attempts(DefaultRetryTimes), retry.Sleep(500*time.Millisecond))
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
 
-		sessionJSON, err := json.Marshal(s)
-		if err != nil {
-			return err
-		}
+// registerServiceOnce is the same as registerService without retrying, it fails if the key of the session
+// has been registered. The lease is granted and the key is put within ctx, the lease is kept alive until the
+// session is done.
+func (s *Session) registerServiceOnce(ctx context.Context) (<-chan *clientv3.LeaseKeepAliveResponse, error) {
+	resp, err := s.etcdCli.Grant(ctx, DefaultTTL)
+	if err != nil {
+		log.Error("register service", zap.Error(err))
+		return nil, err
+	}
+	s.leaseID = resp.ID
 
-		key := s.ServerName
-		if !s.Exclusive {
-			key = key + "-" + strconv.FormatInt(s.ServerID, 10)
-		}
-		txnResp, err := s.etcdCli.Txn(s.ctx).If(
-			clientv3.Compare(
-				clientv3.Version(path.Join(s.metaRoot, DefaultServiceRoot, key)),
-				"=",
-				0)).
-			Then(clientv3.OpPut(path.Join(s.metaRoot, DefaultServiceRoot, key), string(sessionJSON), clientv3.WithLease(resp.ID))).Commit()
+	sessionJSON, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
 
-		if err != nil {
-			log.Warn("compare and swap error, maybe the key has ben registered", zap.Error(err))
-			return err
-		}
+	key := s.ServerName
+	if !s.Exclusive {
+		key = key + "-" + strconv.FormatInt(s.ServerID, 10)
+	}
+	txnResp, err := s.etcdCli.Txn(ctx).If(
+		clientv3.Compare(
+			clientv3.Version(path.Join(s.metaRoot, DefaultServiceRoot, key)),
+			"=",
+			0)).
+		Then(clientv3.OpPut(path.Join(s.metaRoot, DefaultServiceRoot, key), string(sessionJSON), clientv3.WithLease(resp.ID))).Commit()
 
-		if !txnResp.Succeeded {
-			return fmt.Errorf("function CompareAndSwap error for compare is false for key: %s", key)
-		}
+	if err != nil {
+		log.Warn("compare and swap error, maybe the key has ben registered", zap.Error(err))
+		return nil, err
+	}
 
-		ch, err = s.etcdCli.KeepAlive(s.ctx, resp.ID)
-		if err != nil {
-			fmt.Printf("keep alive error %s\n", err)
-			return err
-		}
-		log.Debug("Session Register End", zap.Int64("ServerID", s.ServerID))
-		return nil
+	if !txnResp.Succeeded {
+		return nil, fmt.Errorf("function CompareAndSwap error for compare is false for key: %s", key)
 	}
-	err := retry.Do(s.ctx, registerFn, retry.Attempts(DefaultRetryTimes), retry.Sleep(500*time.Millisecond))
+
+	ch, err := s.etcdCli.KeepAlive(s.ctx, resp.ID)
 	if err != nil {
+		fmt.Printf("keep alive error %s\n", err)
 		return nil, err
 	}
+	log.Debug("Session Register End", zap.Int64("ServerID", s.ServerID))
 	return ch, nil
 }
 
@@ -336,6 +377,90 @@ func (s *Session) WatchServices(prefix string, revision int64) (eventChannel <-c
 	return eventCh
 }
 
+// GetLeaseTTL returns the lease ID of the session and the remaining time to live of the lease in seconds,
+// the TTL is -1 if the lease has expired.
+func (s *Session) GetLeaseTTL(ctx context.Context) (clientv3.LeaseID, int64, error) {
+	if s.etcdCli == nil {
+		return 0, 0, errors.New("session is not connected to etcd")
+	}
+	resp, err := s.etcdCli.TimeToLive(ctx, s.leaseID)
+	if err != nil {
+		return s.leaseID, 0, err
+	}
+	return s.leaseID, resp.TTL, nil
+}
+
+// Revoke revokes the lease of the session, so that the registered key is deleted immediately instead of
+// lingering until the lease expires. It gives up after timeout if etcd is unreachable.
+func (s *Session) Revoke(timeout time.Duration) error {
+	if s.etcdCli == nil {
+		return errors.New("session is not connected to etcd")
+	}
+	if s.leaseID == 0 {
+		return errors.New("session is not registered")
+	}
+	// the context of the session may have been canceled on shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	_, err := s.etcdCli.Revoke(ctx, s.leaseID)
+	return err
+}
+
+// Reregister registers the session again with the same ServerID after the keepalive of its lease fails, e.g. etcd
+// has been unreachable longer than the TTL. The previous lease is revoked if it's still alive, so that the key
+// is not left registered by it. It gives up after timeout if etcd is unreachable.
+func (s *Session) Reregister(timeout time.Duration) (<-chan bool, error) {
+	if s.etcdCli == nil {
+		return nil, errors.New("session is not connected to etcd")
+	}
+	if s.ServerID == 0 {
+		return nil, errors.New("session is not registered")
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, timeout)
+	defer cancel()
+	if s.leaseID != 0 {
+		// the lease may have expired, which is as good as revoked
+		_, _ = s.etcdCli.Revoke(ctx, s.leaseID)
+	}
+	ch, err := s.registerServiceOnce(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.processKeepAliveResponse(ch), nil
+}
+
+// UpdateRegistration saves the current metadata of the session to the registered key, keeping its lease. It fails
+// if the key is gone, so that an expired session is never brought back.
+func (s *Session) UpdateRegistration() error {
+	if s.etcdCli == nil {
+		return errors.New("session is not connected to etcd")
+	}
+	if s.leaseID == 0 {
+		return errors.New("session is not registered")
+	}
+	sessionJSON, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	key := s.ServerName
+	if !s.Exclusive {
+		key = key + "-" + strconv.FormatInt(s.ServerID, 10)
+	}
+	txnResp, err := s.etcdCli.Txn(s.ctx).If(
+		clientv3.Compare(
+			clientv3.Version(path.Join(s.metaRoot, DefaultServiceRoot, key)),
+			">",
+			0)).
+		Then(clientv3.OpPut(path.Join(s.metaRoot, DefaultServiceRoot, key), string(sessionJSON), clientv3.WithLease(s.leaseID))).Commit()
+	if err != nil {
+		return err
+	}
+	if !txnResp.Succeeded {
+		return fmt.Errorf("session key %s is not registered", key)
+	}
+	return nil
+}
+
 // LivenessCheck performs liveness check with provided context and channel
 // ctx controls the liveness check loop
 // ch is the liveness signal channel, ch is closed only when the session is expired
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"path"
 	"strconv"
 	"strings"
 	"sync"
@@ -88,6 +89,10 @@ func TestInit(t *testing.T) {
 	sessions, _, err := s.GetSessions("inittest")
 	assert.Nil(t, err)
 	assert.Contains(t, sessions, "inittest-"+strconv.FormatInt(s.ServerID, 10))
+	leaseID, ttl, err := s.GetLeaseTTL(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, s.leaseID, leaseID)
+	assert.True(t, ttl > 0 && ttl <= DefaultTTL)
 }
 
 func TestUpdateSessions(t *testing.T) {
@@ -201,3 +206,202 @@ func TestSessionLivenessCheck(t *testing.T) {
 
 	assert.False(t, flag)
 }
+
+func TestSessionMetadata(t *testing.T) {
+	ctx := context.Background()
+	Params.Init()
+
+	endpoints, err := Params.Load("_EtcdEndpoints")
+	if err != nil {
+		panic(err)
+	}
+	metaRoot := fmt.Sprintf("%d/%s", rand.Int(), DefaultServiceRoot)
+
+	etcdEndpoints := strings.Split(endpoints, ",")
+	etcdKV, err := etcdkv.NewEtcdKV(etcdEndpoints, metaRoot)
+	assert.NoError(t, err)
+	err = etcdKV.RemoveWithPrefix("")
+	assert.NoError(t, err)
+
+	defer etcdKV.Close()
+	defer etcdKV.RemoveWithPrefix("")
+
+	s := NewSession(ctx, metaRoot, etcdEndpoints)
+	s.Alias = "metadatatest-1"
+	s.Version = "v2.0.0"
+	s.CreatedTime = time.Now().Format(time.RFC3339)
+	s.Labels = map[string]string{"zone": "zone-a", "rack": "r1"}
+	s.Capabilities = []string{"disk-index", "gpu"}
+	s.Init("metadatatest", "testAddr", false)
+
+	sessions, _, err := s.GetSessions("metadatatest")
+	assert.Nil(t, err)
+	session, ok := sessions["metadatatest-"+strconv.FormatInt(s.ServerID, 10)]
+	assert.True(t, ok)
+	assert.Equal(t, s.ServerID, session.ServerID)
+	assert.Equal(t, "testAddr", session.Address)
+	assert.Equal(t, s.Alias, session.Alias)
+	assert.Equal(t, s.Version, session.Version)
+	assert.Equal(t, s.CreatedTime, session.CreatedTime)
+	assert.Equal(t, s.Labels, session.Labels)
+	assert.Equal(t, s.Capabilities, session.Capabilities)
+
+	// the payload registered before the metadata is added
+	err = etcdKV.Save(path.Join(DefaultServiceRoot, "metadatatest-0"),
+		`{"ServerID":0,"ServerName":"metadatatest","Address":"oldAddr"}`)
+	assert.Nil(t, err)
+	sessions, _, err = s.GetSessions("metadatatest")
+	assert.Nil(t, err)
+	session, ok = sessions["metadatatest-0"]
+	assert.True(t, ok)
+	assert.Equal(t, "metadatatest", session.ServerName)
+	assert.Equal(t, "oldAddr", session.Address)
+	assert.Equal(t, "", session.Alias)
+	assert.Equal(t, "", session.Version)
+	assert.Equal(t, "", session.CreatedTime)
+	assert.Nil(t, session.Labels)
+	assert.Nil(t, session.Capabilities)
+}
+
+func TestSessionRevoke(t *testing.T) {
+	ctx := context.Background()
+	Params.Init()
+
+	endpoints, err := Params.Load("_EtcdEndpoints")
+	if err != nil {
+		panic(err)
+	}
+	metaRoot := fmt.Sprintf("%d/%s", rand.Int(), DefaultServiceRoot)
+
+	etcdEndpoints := strings.Split(endpoints, ",")
+	etcdKV, err := etcdkv.NewEtcdKV(etcdEndpoints, metaRoot)
+	assert.NoError(t, err)
+	err = etcdKV.RemoveWithPrefix("")
+	assert.NoError(t, err)
+
+	defer etcdKV.Close()
+	defer etcdKV.RemoveWithPrefix("")
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	s := NewSession(sessionCtx, metaRoot, etcdEndpoints)
+	err = s.Revoke(time.Second)
+	assert.NotNil(t, err)
+
+	s.Init("revoketest", "testAddr", false)
+	sessions, _, err := s.GetSessions("revoketest")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(sessions))
+
+	// revoke after the context of the session is canceled, as on shutdown
+	cancel()
+	err = s.Revoke(time.Second)
+	assert.Nil(t, err)
+	keys, _, err := etcdKV.LoadWithPrefix(path.Join(DefaultServiceRoot, "revoketest"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(keys))
+}
+
+func TestSessionUpdateRegistration(t *testing.T) {
+	ctx := context.Background()
+	Params.Init()
+
+	endpoints, err := Params.Load("_EtcdEndpoints")
+	if err != nil {
+		panic(err)
+	}
+	metaRoot := fmt.Sprintf("%d/%s", rand.Int(), DefaultServiceRoot)
+
+	etcdEndpoints := strings.Split(endpoints, ",")
+	etcdKV, err := etcdkv.NewEtcdKV(etcdEndpoints, metaRoot)
+	assert.NoError(t, err)
+	err = etcdKV.RemoveWithPrefix("")
+	assert.NoError(t, err)
+
+	defer etcdKV.Close()
+	defer etcdKV.RemoveWithPrefix("")
+
+	s := NewSession(ctx, metaRoot, etcdEndpoints)
+	err = s.UpdateRegistration()
+	assert.NotNil(t, err)
+
+	s.Standby = true
+	s.Init("updatetest", "testAddr", false)
+	sessions, _, err := s.GetSessions("updatetest")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(sessions))
+	for _, session := range sessions {
+		assert.True(t, session.Standby)
+	}
+
+	s.Standby = false
+	err = s.UpdateRegistration()
+	assert.Nil(t, err)
+	sessions, _, err = s.GetSessions("updatetest")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(sessions))
+	for _, session := range sessions {
+		assert.False(t, session.Standby)
+	}
+	_, ttl, err := s.GetLeaseTTL(ctx)
+	assert.Nil(t, err)
+	assert.True(t, ttl > 0)
+
+	// the revoked session is not brought back
+	err = s.Revoke(time.Second)
+	assert.Nil(t, err)
+	err = s.UpdateRegistration()
+	assert.NotNil(t, err)
+	sessions, _, err = s.GetSessions("updatetest")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(sessions))
+}
+
+func TestSessionReregister(t *testing.T) {
+	ctx := context.Background()
+	Params.Init()
+
+	endpoints, err := Params.Load("_EtcdEndpoints")
+	if err != nil {
+		panic(err)
+	}
+	metaRoot := fmt.Sprintf("%d/%s", rand.Int(), DefaultServiceRoot)
+
+	etcdEndpoints := strings.Split(endpoints, ",")
+	etcdKV, err := etcdkv.NewEtcdKV(etcdEndpoints, metaRoot)
+	assert.NoError(t, err)
+	err = etcdKV.RemoveWithPrefix("")
+	assert.NoError(t, err)
+
+	defer etcdKV.Close()
+	defer etcdKV.RemoveWithPrefix("")
+
+	s := NewSession(ctx, metaRoot, etcdEndpoints)
+	_, err = s.Reregister(time.Second)
+	assert.NotNil(t, err)
+
+	s.Init("reregistertest", "testAddr", false)
+	serverID := s.ServerID
+	// the lease is lost
+	assert.Nil(t, s.Revoke(time.Second))
+	sessions, _, err := s.GetSessions("reregistertest")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(sessions))
+
+	liveCh, err := s.Reregister(time.Second)
+	assert.Nil(t, err)
+	assert.NotNil(t, liveCh)
+	assert.Equal(t, serverID, s.ServerID)
+	sessions, _, err = s.GetSessions("reregistertest")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(sessions))
+	for _, session := range sessions {
+		assert.Equal(t, serverID, session.ServerID)
+	}
+
+	// the key still registered by the previous lease is taken over
+	_, err = s.Reregister(time.Second)
+	assert.Nil(t, err)
+	sessions, _, err = s.GetSessions("reregistertest")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(sessions))
+}
@@ -0,0 +1,170 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+// Package tokenauth authenticates the grpc calls by a token shared by the server and its clients. The clients attach
+// the token to the metadata of the calls, and the server rejects the calls without it with Unauthenticated. The
+// token is masked whenever it's printed, logged or marshaled.
+package tokenauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/milvus-io/milvus/internal/log"
+)
+
+// MetadataKey is the key of the token in the grpc metadata, in the form of "Bearer <token>".
+const MetadataKey = "authorization"
+
+const scheme = "Bearer "
+
+const masked = "******"
+
+// Token is the shared token, which is masked when it's formatted or marshaled.
+type Token string
+
+// String returns the masked token.
+func (t Token) String() string {
+	if t == "" {
+		return ""
+	}
+	return masked
+}
+
+// GoString returns the masked token for %#v.
+func (t Token) GoString() string {
+	return fmt.Sprintf("%q", t.String())
+}
+
+// MarshalText returns the masked token, e.g. by the json encoding of the configurations.
+func (t Token) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// LoadToken returns @token, or the token read from @file if @token is empty. The empty token disables the
+// authentication.
+func LoadToken(token, file string) (Token, error) {
+	token, file = strings.TrimSpace(token), strings.TrimSpace(file)
+	if file == "" {
+		return Token(token), nil
+	}
+	if token != "" {
+		return "", errors.New("both the token and the token file are set")
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the token file %s: %w", file, err)
+	}
+	token = strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("the token file %s is empty", file)
+	}
+	return Token(token), nil
+}
+
+// equal compares the tokens in constant time, the digests keep the length of the token from leaking as well.
+func equal(a, b string) bool {
+	da, db := sha256.Sum256([]byte(a)), sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(da[:], db[:]) == 1
+}
+
+// authenticate returns the error of Unauthenticated if the incoming metadata of @ctx doesn't carry @token.
+func authenticate(ctx context.Context, token Token) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get(MetadataKey) {
+		if strings.HasPrefix(value, scheme) && equal(strings.TrimPrefix(value, scheme), string(token)) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid token")
+}
+
+// authenticator authenticates the calls of the methods not exempt.
+type authenticator struct {
+	token  Token
+	exempt map[string]bool
+}
+
+func newAuthenticator(token Token, exemptMethods []string) *authenticator {
+	exempt := make(map[string]bool)
+	for _, method := range exemptMethods {
+		exempt[method] = true
+	}
+	return &authenticator{token: token, exempt: exempt}
+}
+
+func (a *authenticator) authenticate(ctx context.Context, fullMethod string) error {
+	if a.exempt[path.Base(fullMethod)] {
+		return nil
+	}
+	err := authenticate(ctx, a.token)
+	if err != nil {
+		log.Debug("reject the unauthenticated grpc call", zap.String("method", fullMethod))
+	}
+	return err
+}
+
+// UnaryServerInterceptor returns the interceptor rejecting the unary calls without @token, except the ones of
+// @exemptMethods, which are the method names without the service, e.g. "GetComponentStates".
+func UnaryServerInterceptor(token Token, exemptMethods []string) grpc.UnaryServerInterceptor {
+	a := newAuthenticator(token, exemptMethods)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		if err := a.authenticate(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the interceptor rejecting the streams without @token, see UnaryServerInterceptor.
+func StreamServerInterceptor(token Token, exemptMethods []string) grpc.StreamServerInterceptor {
+	a := newAuthenticator(token, exemptMethods)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := a.authenticate(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// outgoingContext returns @ctx attaching @token to the outgoing metadata.
+func outgoingContext(ctx context.Context, token Token) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, MetadataKey, scheme+string(token))
+}
+
+// UnaryClientInterceptor returns the interceptor attaching @token to the unary calls.
+func UnaryClientInterceptor(token Token) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingContext(ctx, token), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor returns the interceptor attaching @token to the streams.
+func StreamClientInterceptor(token Token) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingContext(ctx, token), desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (C) 2019-2020 Zilliz. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under the License.
+
+package tokenauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestToken(t *testing.T) {
+	token := Token("secret")
+	config := struct {
+		Token Token
+		File  string
+	}{Token: token, File: "/etc/milvus/token"}
+	for _, dump := range []string{
+		token.String(),
+		fmt.Sprint(token),
+		fmt.Sprintf("%s %v %+v %#v", token, token, config, config),
+	} {
+		assert.NotContains(t, dump, "secret")
+		assert.Contains(t, dump, masked)
+	}
+	marshaled, err := json.Marshal(config)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(marshaled), "secret")
+	assert.Equal(t, "", Token("").String())
+
+	var buf bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf),
+		zapcore.DebugLevel)
+	zap.New(core).Info("dump", zap.Stringer("token", token), zap.Any("config", config), zap.Reflect("token", token))
+	assert.NotContains(t, buf.String(), "secret")
+}
+
+func TestLoadToken(t *testing.T) {
+	token, err := LoadToken("", "")
+	assert.Nil(t, err)
+	assert.Equal(t, Token(""), token)
+	token, err = LoadToken(" secret ", "")
+	assert.Nil(t, err)
+	assert.Equal(t, Token("secret"), token)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "token")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("from-file\n"), 0600))
+	token, err = LoadToken("", file)
+	assert.Nil(t, err)
+	assert.Equal(t, Token("from-file"), token)
+
+	_, err = LoadToken("secret", file)
+	assert.NotNil(t, err)
+	_, err = LoadToken("", filepath.Join(dir, "missing"))
+	assert.NotNil(t, err)
+	empty := filepath.Join(dir, "empty")
+	assert.Nil(t, ioutil.WriteFile(empty, []byte(" \n"), 0600))
+	_, err = LoadToken("", empty)
+	assert.NotNil(t, err)
+}
+
+// incoming returns the context of an incoming call carrying @values of the token.
+func incoming(values ...string) context.Context {
+	md := metadata.MD{}
+	for _, value := range values {
+		md.Append(MetadataKey, value)
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func callServer(ctx context.Context, method string) error {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	_, err := UnaryServerInterceptor("secret", []string{"GetComponentStates"})(ctx, nil,
+		&grpc.UnaryServerInfo{FullMethod: "/milvus.proto.index.IndexNode/" + method}, handler)
+	return err
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	assert.Nil(t, callServer(incoming("Bearer secret"), "CreateIndex"))
+	assert.Nil(t, callServer(incoming("Bearer other", "Bearer secret"), "CreateIndex"))
+
+	for _, ctx := range []context.Context{
+		context.Background(),
+		incoming(),
+		incoming("Bearer other"),
+		incoming("Bearer secret2"),
+		incoming("secret"),
+		incoming("Bearer "),
+	} {
+		err := callServer(ctx, "CreateIndex")
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.NotContains(t, err.Error(), "secret")
+	}
+
+	// the exempt methods are called without the token
+	assert.Nil(t, callServer(context.Background(), "GetComponentStates"))
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := StreamServerInterceptor("secret", nil)
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		return nil
+	}
+	info := &grpc.StreamServerInfo{FullMethod: "/test/Stream"}
+	assert.Nil(t, interceptor(nil, &serverStream{ctx: incoming("Bearer secret")}, info, handler))
+	err := interceptor(nil, &serverStream{ctx: context.Background()}, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestClientInterceptors(t *testing.T) {
+	var values []string
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		values = md.Get(MetadataKey)
+		return nil
+	}
+	assert.Nil(t, UnaryClientInterceptor("secret")(context.Background(), "/test/Call", nil, nil, nil, invoker))
+	assert.Equal(t, []string{"Bearer secret"}, values)
+
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		values = md.Get(MetadataKey)
+		return nil, nil
+	}
+	_, err := StreamClientInterceptor("other")(context.Background(), nil, nil, "/test/Stream", streamer)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Bearer other"}, values)
+
+	// the server accepts the token attached by the client
+	md, _ := metadata.FromOutgoingContext(outgoingContext(context.Background(), "secret"))
+	assert.Nil(t, callServer(metadata.NewIncomingContext(context.Background(), md), "CreateIndex"))
+}
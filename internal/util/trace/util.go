@@ -58,6 +58,44 @@ func InitTracing(serviceName string) io.Closer {
 	return tracingCloser
 }
 
+// InitTracingWithSampler init global trace from env like InitTracing, except that the spans are sampled by
+// @samplingRatio, and reported to @endpoint if it's not empty, which is the address of the jaeger agent, or the
+// url of the jaeger collector if it starts with http.
+func InitTracingWithSampler(serviceName string, endpoint string, samplingRatio float64) io.Closer {
+	tracingCloserMtx.Lock()
+	defer tracingCloserMtx.Unlock()
+
+	if tracingCloser != nil {
+		return tracingCloser
+	}
+
+	cfg := initFromEnv(serviceName)
+	if cfg == nil {
+		cfg = &config.Configuration{ServiceName: serviceName}
+	}
+	cfg.Sampler = &config.SamplerConfig{
+		Type:  jaeger.SamplerTypeProbabilistic,
+		Param: samplingRatio,
+	}
+	if cfg.Reporter == nil {
+		cfg.Reporter = &config.ReporterConfig{}
+	}
+	if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+		cfg.Reporter.CollectorEndpoint = endpoint
+	} else if endpoint != "" {
+		cfg.Reporter.LocalAgentHostPort = endpoint
+	}
+	tracer, closer, err := cfg.NewTracer()
+	if err != nil {
+		log.Error(err)
+		return nil
+	}
+	tracingCloser = closer
+	opentracing.SetGlobalTracer(tracer)
+
+	return tracingCloser
+}
+
 func initFromEnv(serviceName string) *config.Configuration {
 	cfg, err := config.FromEnv()
 	if err != nil {
@@ -40,6 +40,11 @@ func TestInit(t *testing.T) {
 	assert.NotNil(t, cfg)
 }
 
+func TestInitTracingWithSampler(t *testing.T) {
+	// the global tracer is initialized once
+	assert.Equal(t, InitTracing("test"), InitTracingWithSampler("test", "localhost:6831", 0.5))
+}
+
 func TestTracing(t *testing.T) {
 	// context normally can be propagated through func params
 	ctx := context.Background()